@@ -0,0 +1,129 @@
+// This file handles generating alt text for images that have none.
+// The alt text is written by a large language model that looks at the image;
+// it is cached by the hash of the image, so every image is described only once.
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/openai/openai-go"
+)
+
+// DefaultAltTextCache is the cache file of the generated alt text if none is configured.
+const DefaultAltTextCache = ".alt-text-cache.json"
+
+// maxAltTextLength is the length of the alt text asked for (screen readers prefer short texts).
+const maxAltTextLength = 125
+
+// describeFunc describes an image in the given language (e.g. "german").
+type describeFunc func(ctx context.Context, image []byte, language string) (string, error)
+
+// AltTextGenerator writes alt text into the images of the posts that have none.
+type AltTextGenerator struct {
+	fs       FileSystem   // File system of the images
+	cache    *llmCache    // Alt text by image hash and language
+	describe describeFunc // Asks the model (replaceable in tests)
+}
+
+// NewAltTextGenerator creates an AltTextGenerator and reads its cache.
+// A missing cache file is an empty cache.
+func NewAltTextGenerator(fsys FileSystem, cachePath string, describe describeFunc) (*AltTextGenerator, error) {
+	cache, err := loadLLMCache(fsys, cachePath)
+	if err != nil {
+		return nil, fmt.Errorf("alt text: %w", err)
+	}
+	return &AltTextGenerator{fs: fsys, cache: cache, describe: describe}, nil
+}
+
+// Apply adds alt text to the images in the content that have none or just
+// their file name (Logseq uses the file name when an image is pasted).
+// The images are read through the processor's asset pattern before they are copied,
+// images in code blocks and code spans are left alone.
+// Images that can't be described keep their reference and a warning is printed.
+func (g *AltTextGenerator) Apply(ctx context.Context, processor *ImageProcessor, content, language string) string {
+	return replaceOutsideCode(content, func(text string) string {
+		return processor.assetRegex.ReplaceAllStringFunc(text, func(match string) string {
+			parts := processor.assetRegex.FindStringSubmatch(match)
+			if !missingAltText(parts[1]) || isVideoFile(parts[3]) || isTrackFile(parts[3]) || ctx.Err() != nil {
+				return match
+			}
+
+			// Missing images are reported when they are copied
+			image, err := readFile(g.fs, processor.sourcePath(parts[2]+parts[3]))
+			if err != nil {
+				return match
+			}
+			alt, err := g.AltText(ctx, image, language)
+			if err != nil {
+				fmt.Printf("Warning: no alt text for %s: %v\n", parts[3], err)
+				return match
+			}
+			return "![" + alt + match[len("!["+parts[1]):]
+		})
+	})
+}
+
+// AltText returns the alt text of an image, from the cache or from the model.
+func (g *AltTextGenerator) AltText(ctx context.Context, image []byte, language string) (string, error) {
+	key := llmCacheKey(image, language)
+	if alt, ok := g.cache.get(key); ok {
+		return alt, nil
+	}
+
+	alt, err := g.describe(ctx, image, language)
+	if err != nil {
+		return "", err
+	}
+	alt = cleanAltText(alt)
+	if alt == "" {
+		return "", fmt.Errorf("empty answer")
+	}
+	g.cache.set(key, alt)
+	return alt, nil
+}
+
+// Save writes the cache file if new alt text was generated.
+func (g *AltTextGenerator) Save() error {
+	if err := g.cache.save(); err != nil {
+		return fmt.Errorf("alt text: %w", err)
+	}
+	return nil
+}
+
+// missingAltText reports whether an image needs alt text: it has none or just a file name.
+func missingAltText(alt string) bool {
+	alt = strings.TrimSpace(alt)
+	return alt == "" || (!strings.Contains(alt, " ") && path.Ext(alt) != "")
+}
+
+// cleanAltText makes the model's answer usable as alt text: a single line
+// without quotes and without brackets, which would end the markdown image.
+func cleanAltText(alt string) string {
+	alt = strings.Join(strings.Fields(alt), " ")
+	alt = strings.Trim(alt, `"'“”„`)
+	return strings.NewReplacer("[", "(", "]", ")").Replace(alt)
+}
+
+// describeImage asks the model for the alt text of an image.
+func (c *llmClient) describeImage(ctx context.Context, image []byte, language string) (string, error) {
+	mimeType := http.DetectContentType(image)
+	if !strings.HasPrefix(mimeType, "image/") {
+		return "", fmt.Errorf("not an image (%s)", mimeType)
+	}
+
+	instructions := fmt.Sprintf(`You write alt text for the images of a blog, for readers using a screen reader.
+Describe the image in one concise sentence of at most %d characters, in %s.
+Do not start with "Image of" or "Picture of". Return ONLY the alt text, without quotes.`, maxAltTextLength, language)
+
+	return c.complete(ctx, instructions, []openai.ChatCompletionContentPartUnionParam{
+		openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{
+			URL:    "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(image),
+			Detail: "low",
+		}),
+	})
+}
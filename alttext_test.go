@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+// TestAltTextGenerator_Apply tests adding alt text to the images that need it
+func TestAltTextGenerator_Apply(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	fsys.WriteFile(filepath.Join("graph", "assets", "beach.jpg"), []byte("beach"))
+	fsys.WriteFile(filepath.Join("graph", "assets", "copy.jpg"), []byte("beach"))
+	fsys.WriteFile(filepath.Join("graph", "assets", "boat.jpg"), []byte("boat"))
+
+	calls := 0
+	g, err := NewAltTextGenerator(fsys, "cache.json", func(ctx context.Context, image []byte, language string) (string, error) {
+		calls++
+		return fmt.Sprintf("\"A [%s] photo\"\n", image), nil
+	})
+	if err != nil {
+		t.Fatalf("NewAltTextGenerator() error = %v", err)
+	}
+	processor := NewImageProcessor(NewCopyManager(fsys), filepath.Join("graph", "pages"), "out")
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"Empty alt text", "![](../assets/beach.jpg)", "![A (beach) photo](../assets/beach.jpg)"},
+		{"File name as alt text", "![image.jpg](../assets/boat.jpg){:height 100}", "![A (boat) photo](../assets/boat.jpg){:height 100}"},
+		{"Alt text is kept", "![The boat](../assets/boat.jpg)", "![The boat](../assets/boat.jpg)"},
+		{"Same image, other file", "![](../assets/copy.jpg)", "![A (beach) photo](../assets/copy.jpg)"},
+		{"Missing image", "![](../assets/gone.jpg)", "![](../assets/gone.jpg)"},
+		{"Video", "![](../assets/clip.mp4)", "![](../assets/clip.mp4)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := g.Apply(context.Background(), processor, tt.content, "german"); got != tt.want {
+				t.Errorf("Apply() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if calls != 2 {
+		t.Errorf("The model was asked %d times, want 2 (once per image)", calls)
+	}
+
+	// The cache is written and read again
+	if err := g.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	cached, err := NewAltTextGenerator(fsys, "cache.json", func(ctx context.Context, image []byte, language string) (string, error) {
+		return "", fmt.Errorf("not cached")
+	})
+	if err != nil {
+		t.Fatalf("NewAltTextGenerator() error = %v", err)
+	}
+	if alt, err := cached.AltText(context.Background(), []byte("boat"), "german"); err != nil || alt != "A (boat) photo" {
+		t.Errorf("AltText() from the cache = %q, %v", alt, err)
+	}
+	if _, err := cached.AltText(context.Background(), []byte("boat"), "english"); err == nil {
+		t.Errorf("AltText() in another language came from the cache")
+	}
+}
+
+// TestMissingAltText tests which alt texts are replaced
+func TestMissingAltText(t *testing.T) {
+	tests := []struct {
+		alt  string
+		want bool
+	}{
+		{"", true},
+		{"  ", true},
+		{"image_1769019502364_0.png", true},
+		{"Sunset at the beach", false},
+		{"Dr. Smith", false},
+	}
+
+	for _, tt := range tests {
+		if got := missingAltText(tt.alt); got != tt.want {
+			t.Errorf("missingAltText(%q) = %v, want %v", tt.alt, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// This file handles packaging the generated bundles into a single archive.
+// With -archive, the conversion writes into memory like a dry run and the
+// files of the output directory are written to a tar.gz, tar or zip file,
+// with the same directory layout, instead of loose files.
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Archive formats, by the extension of the archive file
+const (
+	ArchiveTarGz = "tar.gz"
+	ArchiveTar   = "tar"
+	ArchiveZip   = "zip"
+)
+
+// archiveFormat returns the format of an archive file name.
+func archiveFormat(name string) (string, error) {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return ArchiveTarGz, nil
+	case strings.HasSuffix(lower, ".tar"):
+		return ArchiveTar, nil
+	case strings.HasSuffix(lower, ".zip"):
+		return ArchiveZip, nil
+	}
+	return "", fmt.Errorf("unknown archive format of %s (use .tar.gz, .tgz, .tar or .zip)", name)
+}
+
+// writeArchive writes the files into an archive. The archive is written to a
+// temporary file first, so a failed export doesn't leave half an archive.
+// Files get the permissions mode (0644 if 0) and the time modTime.
+func writeArchive(name string, files []RemoteFile, mode os.FileMode, modTime time.Time) error {
+	format, err := archiveFormat(name)
+	if err != nil {
+		return err
+	}
+	if mode == 0 {
+		mode = 0644
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+"-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	switch format {
+	case ArchiveZip:
+		err = writeZip(tmp, files, mode, modTime)
+	case ArchiveTarGz:
+		gz := gzip.NewWriter(tmp)
+		err = writeTar(gz, files, mode, modTime)
+		if closeErr := gz.Close(); err == nil {
+			err = closeErr
+		}
+	default:
+		err = writeTar(tmp, files, mode, modTime)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+// archiveDirs returns the directories of the files, parents first, so
+// extracting the archive creates them with the usual permissions.
+func archiveDirs(files []RemoteFile) []string {
+	var dirs []string
+	seen := make(map[string]bool)
+	var add func(string)
+	add = func(dir string) {
+		if dir == "." || seen[dir] {
+			return
+		}
+		add(path.Dir(dir))
+		seen[dir] = true
+		dirs = append(dirs, dir)
+	}
+	for _, file := range files {
+		add(path.Dir(file.Name))
+	}
+	return dirs
+}
+
+// writeTar writes the files as a tar archive.
+func writeTar(w io.Writer, files []RemoteFile, mode os.FileMode, modTime time.Time) error {
+	tw := tar.NewWriter(w)
+	for _, dir := range archiveDirs(files) {
+		header := &tar.Header{Typeflag: tar.TypeDir, Name: dir + "/", Mode: 0755, ModTime: modTime, Format: tar.FormatPAX}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+	}
+	for _, file := range files {
+		header := &tar.Header{Typeflag: tar.TypeReg, Name: file.Name, Mode: int64(mode.Perm()), Size: int64(len(file.Data)), ModTime: modTime, Format: tar.FormatPAX}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tw.Write(file.Data); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+// writeZip writes the files as a zip archive.
+func writeZip(w io.Writer, files []RemoteFile, mode os.FileMode, modTime time.Time) error {
+	zw := zip.NewWriter(w)
+	for _, dir := range archiveDirs(files) {
+		header := &zip.FileHeader{Name: dir + "/", Modified: modTime}
+		header.SetMode(os.ModeDir | 0755)
+		if _, err := zw.CreateHeader(header); err != nil {
+			return err
+		}
+	}
+	for _, file := range files {
+		header := &zip.FileHeader{Name: file.Name, Method: zip.Deflate, Modified: modTime}
+		header.SetMode(mode.Perm())
+		fw, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(file.Data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
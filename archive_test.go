@@ -0,0 +1,120 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestArchiveFormat tests choosing the archive format by the file name
+func TestArchiveFormat(t *testing.T) {
+	tests := map[string]string{
+		"posts.tar.gz": ArchiveTarGz,
+		"posts.TGZ":    ArchiveTarGz,
+		"posts.tar":    ArchiveTar,
+		"posts.zip":    ArchiveZip,
+		"posts.rar":    "",
+	}
+	for name, want := range tests {
+		got, err := archiveFormat(name)
+		if got != want || (err != nil) != (want == "") {
+			t.Errorf("archiveFormat(%q) = %q, %v, want %q", name, got, err, want)
+		}
+	}
+}
+
+// TestWriteArchive tests packaging the bundles with their directories in every format
+func TestWriteArchive(t *testing.T) {
+	files := []RemoteFile{
+		{Name: "2026-01-17_Ibiza/index.de.md", Data: []byte("+++\ntitle = \"Ibiza\"\n+++\n")},
+		{Name: "2026-01-17_Ibiza/2024/hafen.jpg", Data: []byte("jpg")},
+		{Name: "recipes/2026-01-18_Paella/index.de.md", Data: []byte("Paella")},
+	}
+	modTime := time.Date(2026, 1, 17, 12, 0, 0, 0, time.UTC)
+	wantEntries := []string{
+		"2026-01-17_Ibiza/", "2026-01-17_Ibiza/2024/", "recipes/", "recipes/2026-01-18_Paella/",
+		"2026-01-17_Ibiza/index.de.md", "2026-01-17_Ibiza/2024/hafen.jpg", "recipes/2026-01-18_Paella/index.de.md",
+	}
+
+	for _, name := range []string{"posts.tar.gz", "posts.tar", "posts.zip"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := writeArchive(path, files, 0640, modTime); err != nil {
+				t.Fatalf("writeArchive() error = %v", err)
+			}
+
+			var entries []string
+			contents := make(map[string]string)
+			if name == "posts.zip" {
+				r, err := zip.OpenReader(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer r.Close()
+				for _, f := range r.File {
+					entries = append(entries, f.Name)
+					if f.Mode().IsRegular() {
+						if f.Mode().Perm() != 0640 {
+							t.Errorf("%s has permissions %o, want 640", f.Name, f.Mode().Perm())
+						}
+						rc, _ := f.Open()
+						data, _ := io.ReadAll(rc)
+						rc.Close()
+						contents[f.Name] = string(data)
+					}
+				}
+			} else {
+				f, err := os.Open(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer f.Close()
+				var r io.Reader = f
+				if name == "posts.tar.gz" {
+					if r, err = gzip.NewReader(f); err != nil {
+						t.Fatal(err)
+					}
+				}
+				tr := tar.NewReader(r)
+				for {
+					header, err := tr.Next()
+					if err == io.EOF {
+						break
+					}
+					if err != nil {
+						t.Fatal(err)
+					}
+					entries = append(entries, header.Name)
+					if header.Typeflag == tar.TypeReg {
+						if header.Mode != 0640 || !header.ModTime.Equal(modTime) {
+							t.Errorf("%s has mode %o and time %v", header.Name, header.Mode, header.ModTime)
+						}
+						data, _ := io.ReadAll(tr)
+						contents[header.Name] = string(data)
+					}
+				}
+			}
+
+			if !reflect.DeepEqual(entries, wantEntries) {
+				t.Errorf("entries = %v, want %v", entries, wantEntries)
+			}
+			for _, file := range files {
+				if contents[file.Name] != string(file.Data) {
+					t.Errorf("%s = %q, want %q", file.Name, contents[file.Name], file.Data)
+				}
+			}
+
+			// Only the archive is left, no temporary file
+			dirEntries, _ := os.ReadDir(filepath.Dir(path))
+			if len(dirEntries) != 1 {
+				t.Errorf("directory has %d entries, want only the archive", len(dirEntries))
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+// This file handles the table of the media written into the bundles, which
+// the migration report lists per post with size and processing, so a 50 MB
+// video that accidentally lands in the site is obvious before publishing.
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"text/tabwriter"
+)
+
+// largeAssetSize is the size from which assets are marked in the report.
+const largeAssetSize = 10 * 1000 * 1000
+
+// BundleAsset is a media file written into a bundle.
+type BundleAsset struct {
+	Post       string // Title of the post
+	Name       string // Path in the bundle, e.g. "boot.mp4"
+	Type       string // "image", "video", "track" or "file"
+	Size       int64  // Size in bytes
+	Processing string // "copied", "transcoded", "resized" or "placeholder"
+}
+
+// assetType returns the type of an asset for the report.
+func assetType(name string) string {
+	switch {
+	case isVideoFile(name):
+		return "video"
+	case isTrackFile(name):
+		return "track"
+	case isImageFile(name):
+		return "image"
+	}
+	return "file"
+}
+
+// recordAsset adds a file written into the bundle to the assets of the processor.
+// Files that were not written (missing sources, failed copies) are left out.
+func (p *ImageProcessor) recordAsset(dst, processing string) {
+	info, err := p.fs.Stat(dst)
+	if err != nil {
+		return
+	}
+	name, err := filepath.Rel(p.outputDir, dst)
+	if err != nil {
+		name = filepath.Base(dst)
+	}
+	name = filepath.ToSlash(name)
+	for _, asset := range p.assets {
+		if asset.Name == name {
+			return
+		}
+	}
+	p.assets = append(p.assets, BundleAsset{Name: name, Type: assetType(name), Size: info.Size(), Processing: processing})
+}
+
+// Assets returns the files the processor wrote into the bundle, in the order they were written.
+func (p *ImageProcessor) Assets() []BundleAsset {
+	return slices.Clone(p.assets)
+}
+
+// assetsSize returns the total size of assets.
+func assetsSize(assets []BundleAsset) int64 {
+	var size int64
+	for _, asset := range assets {
+		size += asset.Size
+	}
+	return size
+}
+
+// writeAssets writes the table of the assets of the converted posts, files of
+// largeAssetSize and more are marked with "!".
+func writeAssets(w io.Writer, assets []BundleAsset) {
+	if len(assets) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "Assets")
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "  Post\tFile\tType\tSize\tProcessing")
+	large := false
+	for _, asset := range assets {
+		size := formatSize(asset.Size)
+		if asset.Size >= largeAssetSize {
+			size += " !"
+			large = true
+		}
+		fmt.Fprintf(table, "  %s\t%s\t%s\t%s\t%s\n", asset.Post, asset.Name, asset.Type, size, asset.Processing)
+	}
+	table.Flush()
+	if large {
+		fmt.Fprintf(w, "  ! %s or larger\n", formatSize(largeAssetSize))
+	}
+}
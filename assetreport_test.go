@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestProcessContent_Assets tests recording the files written into the bundle
+func TestProcessContent_Assets(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	fsys.WriteFile(filepath.Join("graph", "assets", "hafen.jpg"), []byte("hafen"))
+	fsys.WriteFile(filepath.Join("graph", "assets", "boot.mp4"), []byte("video of the boat"))
+	fsys.WriteFile(filepath.Join("graph", "assets", "header.png"), []byte("header"))
+	fsys.MkdirAll("out", 0755)
+
+	processor := NewImageProcessor(NewCopyManager(fsys), filepath.Join("graph", "journals"), "out")
+	processor.ProcessContent(context.Background(), "![](../assets/hafen.jpg)\n![](../assets/boot.mp4)\n![again](../assets/hafen.jpg)\n![](../assets/missing.png)")
+	processor.ProcessHeaderImage(context.Background(), "../assets/header.png")
+
+	want := []BundleAsset{
+		{Name: "hafen.jpg", Type: "image", Size: 5, Processing: "copied"},
+		{Name: "boot.mp4", Type: "video", Size: 17, Processing: "copied"},
+		{Name: "featured.png", Type: "image", Size: 6, Processing: "copied"},
+	}
+	got := processor.Assets()
+	if len(got) != len(want) {
+		t.Fatalf("Assets() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Assets()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+// TestWriteAssets tests the table of the assets in the migration report
+func TestWriteAssets(t *testing.T) {
+	var report strings.Builder
+	writeAssets(&report, nil)
+	if report.Len() != 0 {
+		t.Errorf("Table without assets: %q", report.String())
+	}
+
+	writeAssets(&report, []BundleAsset{
+		{Post: "Segeln", Name: "boot.mp4", Type: "video", Size: 52_400_000, Processing: "copied"},
+		{Post: "Segeln", Name: "og-image.jpeg", Type: "image", Size: 81_000, Processing: "resized"},
+	})
+	want := "Assets\n" +
+		"  Post    File           Type   Size       Processing\n" +
+		"  Segeln  boot.mp4       video  52.4 MB !  copied\n" +
+		"  Segeln  og-image.jpeg  image  81.0 kB    resized\n" +
+		"  ! 10.0 MB or larger\n"
+	if report.String() != want {
+		t.Errorf("writeAssets() = %q, want %q", report.String(), want)
+	}
+}
@@ -0,0 +1,53 @@
+// This file handles mapping the Logseq author values to the published author names.
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// resolveAuthors replaces the author of each post with the display name, email
+// and profile URL of the author registry. Authors missing from the registry are
+// kept as they are, or reported as an error in strict mode.
+func resolveAuthors(posts []*BlogPost, config AuthorsConfig) error {
+	if len(config.Registry) == 0 && !config.Strict {
+		return nil
+	}
+
+	// Registry keys are matched case-insensitively
+	registry := make(map[string]AuthorConfig, len(config.Registry))
+	for key, author := range config.Registry {
+		registry[authorKey(key)] = author
+	}
+
+	var unknown []string
+	for _, post := range posts {
+		if post.Meta.Author == "" {
+			continue
+		}
+		author, ok := registry[authorKey(post.Meta.Author)]
+		if !ok {
+			unknown = append(unknown, fmt.Sprintf("'%s' in '%s'", post.Meta.Author, post.Meta.Title))
+			continue
+		}
+		if author.Name != "" {
+			post.Meta.Author = author.Name
+		}
+		post.Meta.AuthorEmail = author.Email
+		post.Meta.AuthorURL = author.URL
+	}
+
+	if config.Strict && len(unknown) > 0 {
+		return fmt.Errorf("unknown authors: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// authorKey normalizes an author value for the registry lookup:
+// "[[Ben]]" and "ben" both become "ben".
+func authorKey(value string) string {
+	value = strings.TrimSpace(value)
+	value = strings.TrimPrefix(value, "[[")
+	value = strings.TrimSuffix(value, "]]")
+	return strings.ToLower(strings.TrimSpace(value))
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestResolveAuthors tests mapping Logseq authors through the author registry
+func TestResolveAuthors(t *testing.T) {
+	registry := map[string]AuthorConfig{
+		"Ben":  {Name: "Benjamin Baumgartner", Email: "ben@example.com", URL: "https://example.com/ben"},
+		"anon": {Name: "Anonymous"},
+	}
+
+	tests := []struct {
+		name      string
+		author    string
+		strict    bool
+		want      BlogMeta
+		wantError string
+	}{
+		{
+			name:   "Known author",
+			author: "ben",
+			want:   BlogMeta{Author: "Benjamin Baumgartner", AuthorEmail: "ben@example.com", AuthorURL: "https://example.com/ben"},
+		},
+		{
+			name:   "Page reference",
+			author: "[[Ben]]",
+			want:   BlogMeta{Author: "Benjamin Baumgartner", AuthorEmail: "ben@example.com", AuthorURL: "https://example.com/ben"},
+		},
+		{
+			name:   "Anonymized author",
+			author: "anon",
+			want:   BlogMeta{Author: "Anonymous"},
+		},
+		{
+			name:   "Unknown author is kept",
+			author: "alice",
+			want:   BlogMeta{Author: "alice"},
+		},
+		{
+			name:      "Unknown author in strict mode",
+			author:    "alice",
+			strict:    true,
+			wantError: "unknown authors: 'alice' in 'Post'",
+		},
+		{
+			name:   "No author in strict mode",
+			author: "",
+			strict: true,
+			want:   BlogMeta{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post := &BlogPost{Meta: BlogMeta{Title: "Post", Author: tt.author}}
+			err := resolveAuthors([]*BlogPost{post}, AuthorsConfig{Strict: tt.strict, Registry: registry})
+
+			if tt.wantError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+					t.Fatalf("resolveAuthors() error = %v, want %q", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveAuthors() error = %v", err)
+			}
+
+			tt.want.Title = "Post"
+			if post.Meta.Author != tt.want.Author || post.Meta.AuthorEmail != tt.want.AuthorEmail || post.Meta.AuthorURL != tt.want.AuthorURL {
+				t.Errorf("resolveAuthors() = %+v, want %+v", post.Meta, tt.want)
+			}
+		})
+	}
+}
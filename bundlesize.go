@@ -0,0 +1,80 @@
+// This file handles the size budget of the bundles. Photos and videos straight
+// from the phone add up quickly; a bundle over the budget is reported with its
+// largest assets before it blows up the git repository or the CDN bill.
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Actions for bundles over the size budget (OutputConfig.BundleSizeAction).
+const (
+	BundleSizeWarn = "warn"
+	BundleSizeFail = "fail"
+)
+
+// maxListedAssets is the number of largest assets listed for an oversized bundle.
+const maxListedAssets = 3
+
+// byteSizeRegex matches a size like "25MB", "500 kB" or "1.5GB".
+var byteSizeRegex = regexp.MustCompile(`(?i)^(\d+(?:\.\d+)?)\s*(B|kB|MB|GB)?$`)
+
+// parseByteSize parses a size with a decimal unit (like formatSize writes it): "25MB" -> 25000000.
+// A number without unit is in bytes.
+func parseByteSize(size string) (int64, error) {
+	parts := byteSizeRegex.FindStringSubmatch(strings.TrimSpace(size))
+	if parts == nil {
+		return 0, fmt.Errorf("invalid size %q (use a number with B, kB, MB or GB like \"25MB\")", size)
+	}
+	value, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil {
+		return 0, err
+	}
+	units := map[string]float64{"": 1, "b": 1, "kb": 1e3, "mb": 1e6, "gb": 1e9}
+	return int64(value * units[strings.ToLower(parts[2])]), nil
+}
+
+// checkBundleSize checks the assets of a post against the size budget. Over the
+// budget it returns the problem with the largest assets: "52.4 MB, over the
+// budget of 25.0 MB (largest: boot.mp4 48.1 MB, hafen.jpg 3.2 MB)".
+func checkBundleSize(assets []BundleAsset, budget int64) (string, bool) {
+	total := assetsSize(assets)
+	if budget <= 0 || total <= budget {
+		return "", true
+	}
+
+	largest := slices.Clone(assets)
+	slices.SortStableFunc(largest, func(a, b BundleAsset) int { return cmp.Compare(b.Size, a.Size) })
+	var names []string
+	for _, asset := range largest[:min(len(largest), maxListedAssets)] {
+		names = append(names, asset.Name+" "+formatSize(asset.Size))
+	}
+	return fmt.Sprintf("%s, over the budget of %s (largest: %s)", formatSize(total), formatSize(budget), strings.Join(names, ", ")), false
+}
+
+// checkBundleBudget applies the size budget of the configuration to the assets
+// of a post: it warns or, with the "fail" action, returns an error.
+func (c *Converter) checkBundleBudget(post *BlogPost, assets []BundleAsset) error {
+	if c.config.Output.MaxBundleSize == "" {
+		return nil
+	}
+	budget, err := parseByteSize(c.config.Output.MaxBundleSize)
+	if err != nil {
+		return err
+	}
+	problem, ok := checkBundleSize(assets, budget)
+	if ok {
+		return nil
+	}
+	if c.config.Output.BundleSizeAction == BundleSizeFail {
+		return fmt.Errorf("bundle of '%s' is %s", post.Meta.Title, problem)
+	}
+	fmt.Printf("Warning: Bundle of '%s' is %s\n", post.Meta.Title, problem)
+	c.stats.Oversized = append(c.stats.Oversized, post.Meta.Title+": "+problem)
+	return nil
+}
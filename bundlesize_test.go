@@ -0,0 +1,64 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParseByteSize tests parsing the size budget
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		size    string
+		want    int64
+		wantErr bool
+	}{
+		{"25MB", 25_000_000, false},
+		{"500 kB", 500_000, false},
+		{"1.5gb", 1_500_000_000, false},
+		{"1024", 1024, false},
+		{"25 MiB", 0, true},
+		{"big", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.size, func(t *testing.T) {
+			got, err := parseByteSize(tt.size)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("parseByteSize(%q) = %d, %v, want %d", tt.size, got, err, tt.want)
+			}
+		})
+	}
+}
+
+// TestCheckBundleBudget tests warning about and failing on oversized bundles
+func TestCheckBundleBudget(t *testing.T) {
+	post := &BlogPost{Meta: BlogMeta{Title: "Segeln"}}
+	assets := []BundleAsset{
+		{Name: "hafen.jpg", Size: 3_200_000},
+		{Name: "boot.mp4", Size: 48_100_000},
+		{Name: "karte.png", Size: 900_000},
+		{Name: "og-image.jpeg", Size: 81_000},
+	}
+
+	config := DefaultConfig()
+	config.Output.MaxBundleSize = "60MB"
+	c := NewConverter(config, OSFileSystem{})
+	if err := c.checkBundleBudget(post, assets); err != nil || len(c.stats.Oversized) != 0 {
+		t.Fatalf("Bundle within the budget: %v, %v", err, c.stats.Oversized)
+	}
+
+	want := "52.3 MB, over the budget of 25.0 MB (largest: boot.mp4 48.1 MB, hafen.jpg 3.2 MB, karte.png 900.0 kB)"
+	config.Output.MaxBundleSize = "25MB"
+	if err := c.checkBundleBudget(post, assets); err != nil {
+		t.Fatalf("Warning failed the conversion: %v", err)
+	}
+	if len(c.stats.Oversized) != 1 || c.stats.Oversized[0] != "Segeln: "+want {
+		t.Errorf("Oversized = %q, want %q", c.stats.Oversized, "Segeln: "+want)
+	}
+
+	config.Output.BundleSizeAction = BundleSizeFail
+	err := c.checkBundleBudget(post, assets)
+	if err == nil || !strings.Contains(err.Error(), "bundle of 'Segeln' is "+want) {
+		t.Errorf("checkBundleBudget() = %v, want an error with %q", err, want)
+	}
+}
@@ -0,0 +1,34 @@
+// This file handles inferring categories from the journal bullets a post is nested under.
+// In a journal like
+//
+//   - [[Sailing]]
+//   - type:: blog
+//     title:: Renan
+//
+// the post "Renan" is about sailing, so "Sailing" becomes a category (or tag) of the post.
+package main
+
+import "fmt"
+
+// Taxonomies the ancestor page references can be written to.
+const (
+	AncestorsCategories = "categories" // Hugo's categories taxonomy
+	AncestorsTags       = "tags"       // Added to the tags of the post
+)
+
+// applyAncestors adds the pages referenced by the ancestor bullets of a post
+// to its categories or tags. Ignored pages (e.g. "Blog") are left out (case-insensitive).
+func applyAncestors(post *BlogPost, config CategoriesConfig) error {
+	pages := removeTags(post.Ancestors, config.Ignore)
+	switch config.FromAncestors {
+	case "":
+	case AncestorsCategories:
+		post.Meta.Categories = addTags(post.Meta.Categories, pages)
+	case AncestorsTags:
+		post.Meta.Tags = addTags(post.Meta.Tags, pages)
+	default:
+		return fmt.Errorf("unknown taxonomy %q for ancestor pages (use %q or %q)",
+			config.FromAncestors, AncestorsCategories, AncestorsTags)
+	}
+	return nil
+}
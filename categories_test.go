@@ -0,0 +1,55 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestApplyAncestors tests adding the ancestor pages of a post to its categories or tags
+func TestApplyAncestors(t *testing.T) {
+	tests := []struct {
+		name           string
+		config         CategoriesConfig
+		tags           []string
+		wantCategories []string
+		wantTags       []string
+	}{
+		{
+			name:     "Not configured",
+			tags:     []string{"boats"},
+			wantTags: []string{"boats"},
+		},
+		{
+			name:           "Categories without ignored pages",
+			config:         CategoriesConfig{FromAncestors: AncestorsCategories, Ignore: []string{"blog"}},
+			tags:           []string{"boats"},
+			wantCategories: []string{"Sailing", "Boats"},
+			wantTags:       []string{"boats"},
+		},
+		{
+			name:     "Tags without duplicates",
+			config:   CategoriesConfig{FromAncestors: AncestorsTags},
+			tags:     []string{"boats"},
+			wantTags: []string{"boats", "Sailing", "Blog"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post := &BlogPost{Meta: BlogMeta{Tags: tt.tags}, Ancestors: []string{"Sailing", "Boats", "Blog"}}
+			if err := applyAncestors(post, tt.config); err != nil {
+				t.Fatalf("applyAncestors() error = %v", err)
+			}
+			if !reflect.DeepEqual(post.Meta.Categories, tt.wantCategories) {
+				t.Errorf("Categories = %q, want %q", post.Meta.Categories, tt.wantCategories)
+			}
+			if !reflect.DeepEqual(post.Meta.Tags, tt.wantTags) {
+				t.Errorf("Tags = %q, want %q", post.Meta.Tags, tt.wantTags)
+			}
+		})
+	}
+
+	if err := applyAncestors(&BlogPost{}, CategoriesConfig{FromAncestors: "sections"}); err == nil {
+		t.Error("applyAncestors() with an unknown taxonomy error = nil, want an error")
+	}
+}
@@ -0,0 +1,217 @@
+// This file implements the "check" subcommand.
+// It scans generated Hugo bundles for broken references so problems can be
+// caught in CI before the site is published.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"logseq-to-hugo-converter/internal/indexfile"
+)
+
+// CheckIssue describes a problem found in a generated bundle.
+type CheckIssue struct {
+	File    string // Path of the index file
+	Line    int    // Line number (1-based)
+	Message string // Description of the problem
+}
+
+// String formats the issue like a compiler error: "file:line: message".
+func (i CheckIssue) String() string {
+	return fmt.Sprintf("%s:%d: %s", i.File, i.Line, i.Message)
+}
+
+var (
+	// checkImageRegex matches markdown images: ![alt](target)
+	checkImageRegex = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)[^)]*\)`)
+
+	// checkShortcodeSrcRegex matches the src attribute of shortcodes like {{< video src="a.mp4" >}}
+	checkShortcodeSrcRegex = regexp.MustCompile(`\{\{<\s*\w+[^>]*\ssrc="([^"]+)"[^>]*>\}\}`)
+
+	// checkRelrefRegex matches Hugo relref/ref shortcodes: {{< relref "slug" >}}
+	checkRelrefRegex = regexp.MustCompile(`\{\{<\s*(?:rel)?ref\s+"([^"]+)"\s*>\}\}`)
+)
+
+// runCheck runs the check subcommand and returns the process exit code.
+// Usage: go run . check [-config converter.toml] <output_directory>
+func runCheck(args []string) int {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	configPath := flags.String("config", "", "path to a converter.toml configuration file (for the index file names)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() < 1 {
+		fmt.Println("Usage: go run . check [-config converter.toml] <output_directory>")
+		return 2
+	}
+
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 2
+	}
+	issues, bundles, err := checkBundles(flags.Arg(0), config)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 2
+	}
+
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+	fmt.Printf("Checked %d bundles: %d issues\n", bundles, len(issues))
+
+	if len(issues) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// checkBundles checks all bundles (directories with index files) below contentDir.
+// It returns the issues found and the number of checked bundles.
+func checkBundles(contentDir string, config *Config) ([]CheckIssue, int, error) {
+	indexFiles, err := findIndexFiles(contentDir, config)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Collect the bundle names for resolving internal links
+	bundles := make(map[string]bool)
+	for _, file := range indexFiles {
+		bundles[filepath.Base(filepath.Dir(file))] = true
+	}
+
+	var issues []CheckIssue
+	for _, file := range indexFiles {
+		fileIssues, err := checkIndexFile(file, bundles)
+		if err != nil {
+			return nil, 0, err
+		}
+		issues = append(issues, fileIssues...)
+	}
+
+	return issues, len(bundles), nil
+}
+
+// hugoFilenames are the names Hugo gives the index files of its bundles,
+// they are index files whatever the configured names are.
+var hugoFilenames = []*indexfile.Name{defaultFilename, indexfile.MustParse(MonolingualFilename)}
+
+// indexFilenames returns the templates of the index file names of all content
+// types, and the names of Hugo's index files.
+func indexFilenames(config *Config) ([]*indexfile.Name, error) {
+	types, err := newContentTypes(config)
+	if err != nil {
+		return nil, err
+	}
+	var names []*indexfile.Name
+	for _, contentType := range types {
+		if !slices.Contains(names, contentType.filename) {
+			names = append(names, contentType.filename)
+		}
+	}
+	for _, name := range hugoFilenames {
+		if !slices.ContainsFunc(names, func(other *indexfile.Name) bool { return other.String() == name.String() }) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// indexFileLanguage reports whether path is an index file with one of the
+// names and returns its language key ("" for names without a language).
+func indexFileLanguage(path string, names []*indexfile.Name) (string, bool) {
+	for _, name := range names {
+		if key, ok := name.Match(filepath.Base(path), filepath.Base(filepath.Dir(path))); ok {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// findIndexFiles returns all index files below dir in lexical order,
+// the files named like the index file names of the configuration.
+func findIndexFiles(dir string, config *Config) ([]string, error) {
+	names, err := indexFilenames(config)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	err = filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if _, ok := indexFileLanguage(path, names); ok && !entry.IsDir() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scanning %s: %w", dir, err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// checkIndexFile checks the media references and internal links of one index file.
+func checkIndexFile(path string, bundles map[string]bool) ([]CheckIssue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	bundleDir := filepath.Dir(path)
+	var issues []CheckIssue
+	report := func(line int, format string, args ...interface{}) {
+		issues = append(issues, CheckIssue{File: path, Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for i, line := range strings.Split(string(data), "\n") {
+		lineNumber := i + 1
+
+		for _, match := range checkImageRegex.FindAllStringSubmatch(line, -1) {
+			if strings.TrimSpace(match[1]) == "" {
+				report(lineNumber, "image %s has empty alt text", match[2])
+			}
+			if !bundleFileExists(bundleDir, match[2]) {
+				report(lineNumber, "image %s not found in bundle", match[2])
+			}
+		}
+
+		for _, match := range checkShortcodeSrcRegex.FindAllStringSubmatch(line, -1) {
+			if !bundleFileExists(bundleDir, match[1]) {
+				report(lineNumber, "media %s not found in bundle", match[1])
+			}
+		}
+
+		for _, match := range checkRelrefRegex.FindAllStringSubmatch(line, -1) {
+			target := filepath.Base(strings.TrimSuffix(match[1], "/"))
+			if !bundles[target] {
+				report(lineNumber, "link to %s: bundle does not exist", match[1])
+			}
+		}
+	}
+
+	return issues, nil
+}
+
+// bundleFileExists checks whether a local reference points at a file in the bundle.
+// External (http, https, //) and site-absolute (/...) references are not checked.
+func bundleFileExists(bundleDir, target string) bool {
+	if strings.Contains(target, "://") || strings.HasPrefix(target, "/") {
+		return true
+	}
+	if decoded, err := url.PathUnescape(target); err == nil {
+		target = decoded
+	}
+	_, err := os.Stat(filepath.Join(bundleDir, target))
+	return err == nil
+}
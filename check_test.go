@@ -0,0 +1,93 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestCheckBundles tests finding missing media, broken links and empty alt text
+func TestCheckBundles(t *testing.T) {
+	contentDir := t.TempDir()
+
+	writeFile := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	writeFile(filepath.Join(contentDir, "2025-01-21_A", "photo.jpg"), "jpg")
+	writeFile(filepath.Join(contentDir, "2025-01-21_A", "index.de.md"), "+++\ntitle = \"A\"\n+++\n\n"+
+		"![ok](photo.jpg)\n"+
+		"![](photo.jpg)\n"+
+		"![gone](missing.png)\n"+
+		"{{< video src=\"clip.mp4\" >}}\n"+
+		"[B]({{< relref \"2025-01-22_B\" >}}) [C]({{< relref \"2025-01-23_C\" >}})\n"+
+		"![remote](https://example.com/a.png)\n")
+	writeFile(filepath.Join(contentDir, "2025-01-22_B", "index.de.md"), "+++\ntitle = \"B\"\n+++\n\nText\n")
+
+	issues, bundles, err := checkBundles(contentDir, DefaultConfig())
+	if err != nil {
+		t.Fatalf("checkBundles() error = %v", err)
+	}
+
+	if bundles != 2 {
+		t.Errorf("checkBundles() checked %d bundles, want 2", bundles)
+	}
+
+	want := []string{
+		"index.de.md:6: image photo.jpg has empty alt text",
+		"index.de.md:7: image missing.png not found in bundle",
+		"index.de.md:8: media clip.mp4 not found in bundle",
+		"index.de.md:9: link to 2025-01-23_C: bundle does not exist",
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("checkBundles() found %d issues, want %d: %v", len(issues), len(want), issues)
+	}
+	for i, issue := range issues {
+		if !strings.HasSuffix(issue.String(), want[i]) {
+			t.Errorf("issue %d = %q, want suffix %q", i, issue.String(), want[i])
+		}
+	}
+}
+
+// TestFindIndexFiles tests finding the index files named by the configured template
+func TestFindIndexFiles(t *testing.T) {
+	contentDir := t.TempDir()
+	for _, name := range []string{
+		"2025-01-21_A/2025-01-21_A.de.md",
+		"2025-01-21_A/2025-01-21_A.en.md",
+		"2025-01-21_A/notes.md",
+		"2025-01-22_B/index.md",
+		"2025-01-22_B/2025-01-21_A.de.md",
+	} {
+		path := filepath.Join(contentDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("+++\n+++\n"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	config := DefaultConfig()
+	config.Output.Filename = "{{.Slug}}.{{.Lang}}.md"
+	files, err := findIndexFiles(contentDir, config)
+	if err != nil {
+		t.Fatalf("findIndexFiles() error = %v", err)
+	}
+	var got []string
+	for _, file := range files {
+		rel, _ := filepath.Rel(contentDir, file)
+		got = append(got, filepath.ToSlash(rel))
+	}
+	want := []string{"2025-01-21_A/2025-01-21_A.de.md", "2025-01-21_A/2025-01-21_A.en.md", "2025-01-22_B/index.md"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("findIndexFiles() = %v, want %v", got, want)
+	}
+}
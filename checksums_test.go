@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// updateChecksums rewrites the checksum manifests of the golden bundles from
+// the output of the conversion: go test -run TestConvertLogseqToHugo -update-checksums
+var updateChecksums = flag.Bool("update-checksums", false, "rewrite the checksum manifests of the golden bundles")
+
+// checksumManifest is the name of the manifest in a golden bundle, in the
+// format of sha256sum ("<hash>  <file>"), so it can also be checked with sha256sum -c.
+const checksumManifest = "SHA256SUMS"
+
+// fileChecksum returns the SHA-256 hash of a file as hex.
+func fileChecksum(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// readChecksumManifest reads a manifest: file name -> hash.
+func readChecksumManifest(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	checksums := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		hash, name, ok := strings.Cut(text, "  ")
+		if !ok || len(hash) != sha256.Size*2 {
+			return nil, fmt.Errorf("%s:%d: expected \"<sha256>  <file>\"", path, line)
+		}
+		checksums[strings.TrimPrefix(name, "*")] = hash
+	}
+	return checksums, scanner.Err()
+}
+
+// writeChecksumManifest writes the manifest of the files of dir (without the
+// index files, which are compared as text) to manifestPath.
+func writeChecksumManifest(manifestPath, dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var builder strings.Builder
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasSuffix(entry.Name(), ".md") || entry.Name() == checksumManifest {
+			continue
+		}
+		hash, err := fileChecksum(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&builder, "%s  %s\n", hash, entry.Name())
+	}
+	return os.WriteFile(manifestPath, []byte(builder.String()), 0644)
+}
+
+// verifyChecksums compares the assets written to outputDir with the manifest
+// of a golden bundle and returns the names of the files in the manifest, sorted.
+// With -update-checksums the manifest is rewritten from outputDir first.
+func verifyChecksums(t *testing.T, goldenDir, outputDir string) []string {
+	t.Helper()
+	manifestPath := filepath.Join(goldenDir, checksumManifest)
+	if *updateChecksums {
+		if err := writeChecksumManifest(manifestPath, outputDir); err != nil {
+			t.Fatalf("Failed to write %s: %v", manifestPath, err)
+		}
+	}
+
+	checksums, err := readChecksumManifest(manifestPath)
+	if err != nil {
+		t.Fatalf("Failed to read checksum manifest (run with -update-checksums to create it): %v", err)
+	}
+
+	names := make([]string, 0, len(checksums))
+	for name, want := range checksums {
+		names = append(names, name)
+		got, err := fileChecksum(filepath.Join(outputDir, name))
+		if err != nil {
+			t.Errorf("Expected file %s not written: %v", name, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("File %s differs from the golden bundle: sha256 %s, want %s", name, got, want)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TestChecksumManifest tests writing and reading a checksum manifest
+func TestChecksumManifest(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"photo.png":   "png",
+		"clip.mp4":    "mp4",
+		"index.de.md": "+++\n+++\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	manifestPath := filepath.Join(t.TempDir(), checksumManifest)
+	if err := writeChecksumManifest(manifestPath, dir); err != nil {
+		t.Fatalf("writeChecksumManifest() error = %v", err)
+	}
+	checksums, err := readChecksumManifest(manifestPath)
+	if err != nil {
+		t.Fatalf("readChecksumManifest() error = %v", err)
+	}
+
+	// Index files are compared as text, so they are not in the manifest
+	want := map[string]string{
+		"clip.mp4":  "862c4ec62defaadafbf7638961214d286015c38ed4ccc7b10d52bdf434e5bee1",
+		"photo.png": "8f8cbb7dcf46e0bc7d53265749a6c17d116093a6ba95e442764060c76fd4a86c",
+	}
+	if !reflect.DeepEqual(checksums, want) {
+		t.Errorf("readChecksumManifest() = %v, want %v", checksums, want)
+	}
+
+	// Broken lines are reported
+	if err := os.WriteFile(manifestPath, []byte("abc photo.png\n"), 0644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+	if _, err := readChecksumManifest(manifestPath); err == nil {
+		t.Error("readChecksumManifest() with a broken line should fail")
+	}
+}
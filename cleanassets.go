@@ -0,0 +1,303 @@
+// This file implements the "clean-assets" subcommand.
+// Bundles keep the images of earlier conversions when a post no longer uses
+// them. The subcommand removes the files no index file of their bundle
+// references and reports references to images and media missing in the bundle.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// BundleAssets is the result of the asset audit of one bundle.
+type BundleAssets struct {
+	Dir     string       // Bundle directory
+	Orphans []string     // Files no index file references, relative to Dir
+	Missing []CheckIssue // References to files that aren't in the bundle
+}
+
+var (
+	// assetLinkRegex matches the target of markdown images and links: ![alt](target), [text](target)
+	assetLinkRegex = regexp.MustCompile(`\]\(([^)\s]+)[^)]*\)`)
+
+	// assetAttrRegex matches the quoted attribute values of shortcodes and HTML:
+	// {{< video src="a.mp4" >}}, <img src="a.png">, <video poster='a.jpg'> (also
+	// custom attributes of a configured video shortcode)
+	assetAttrRegex = regexp.MustCompile(`\s[\w:-]+=(?:"([^"]+)"|'([^']+)')`)
+
+	// assetFrontMatterRegex matches the quoted values of the front matter,
+	// e.g. images = ["og-image.jpeg"]
+	assetFrontMatterRegex = regexp.MustCompile(`"([^"]+)"`)
+)
+
+// runCleanAssets runs the clean-assets subcommand and returns the process exit code.
+// Usage: go run . clean-assets [-config converter.toml] [-dry-run] <output_directory>
+func runCleanAssets(args []string) int {
+	flags := flag.NewFlagSet("clean-assets", flag.ContinueOnError)
+	configPath := flags.String("config", "", "path to a converter.toml configuration file (for the files the converter writes into the bundles)")
+	dryRun := flags.Bool("dry-run", false, "print the orphaned assets without removing them")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() < 1 {
+		fmt.Println("Usage: go run . clean-assets [-config converter.toml] [-dry-run] <output_directory>")
+		return 2
+	}
+	outputDir := flags.Arg(0)
+
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 2
+	}
+	bundles, err := auditBundleAssets(outputDir, config)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 2
+	}
+
+	orphans, missing := 0, 0
+	for _, bundle := range bundles {
+		for _, issue := range bundle.Missing {
+			fmt.Println(issue)
+		}
+		missing += len(bundle.Missing)
+
+		for _, orphan := range bundle.Orphans {
+			path := filepath.Join(bundle.Dir, orphan)
+			rel, err := filepath.Rel(outputDir, path)
+			if err != nil {
+				rel = path
+			}
+			if *dryRun {
+				fmt.Printf("Would remove %s\n", filepath.ToSlash(rel))
+				orphans++
+				continue
+			}
+			if err := removeAsset(bundle.Dir, orphan); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return 1
+			}
+			fmt.Printf("Removed %s\n", filepath.ToSlash(rel))
+			orphans++
+		}
+	}
+
+	if *dryRun {
+		fmt.Printf("Checked %d bundles: %d orphaned assets would be removed, %d missing\n", len(bundles), orphans, missing)
+	} else {
+		fmt.Printf("Checked %d bundles: %d orphaned assets removed, %d missing\n", len(bundles), orphans, missing)
+	}
+
+	if missing > 0 {
+		return 1
+	}
+	return 0
+}
+
+// auditBundleAssets compares the files of every bundle below contentDir with
+// the references of its index files, in the order of the bundle directories.
+// The files the converter writes into the bundles (the header images, the files
+// recorded in .generated.json, the snippet files and the publish log) are no orphans.
+func auditBundleAssets(contentDir string, config *Config) ([]BundleAssets, error) {
+	indexFiles, err := findIndexFiles(contentDir, config)
+	if err != nil {
+		return nil, err
+	}
+
+	// All index files of a bundle (one per language) share its assets
+	byDir := make(map[string][]string)
+	var dirs []string
+	for _, file := range indexFiles {
+		dir := filepath.Dir(file)
+		if _, ok := byDir[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+		byDir[dir] = append(byDir[dir], file)
+	}
+	sort.Strings(dirs)
+
+	var bundles []BundleAssets
+	for _, dir := range dirs {
+		bundle := BundleAssets{Dir: dir}
+		referenced := make(map[string]bool)
+		for _, file := range byDir[dir] {
+			missing, err := collectAssetReferences(file, referenced)
+			if err != nil {
+				return nil, err
+			}
+			bundle.Missing = append(bundle.Missing, missing...)
+		}
+
+		assets, err := bundleAssetFiles(dir, byDir)
+		if err != nil {
+			return nil, err
+		}
+		generated, err := readGenerated(OSFileSystem{}, dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, asset := range assets {
+			_, written := generated[asset]
+			if !referenced[asset] && !written && !isHeaderImage(asset) && !isConverterFile(asset, config) {
+				bundle.Orphans = append(bundle.Orphans, asset)
+			}
+		}
+		bundles = append(bundles, bundle)
+	}
+	return bundles, nil
+}
+
+// collectAssetReferences adds the bundle files an index file references to
+// referenced (as slash-separated paths relative to the bundle) and returns the
+// images and media it references that aren't in the bundle.
+// Quoted front matter values only count if they name a file of the bundle.
+func collectAssetReferences(path string, referenced map[string]bool) ([]CheckIssue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	bundleDir := filepath.Dir(path)
+
+	// Line numbers count from the start of the file
+	content, offset := string(data), 0
+	if frontMatter, rest, ok := splitFrontMatter(string(data)); ok {
+		for _, match := range assetFrontMatterRegex.FindAllStringSubmatch(frontMatter, -1) {
+			if asset, ok := bundleAssetPath(match[1]); ok && bundleFileExists(bundleDir, asset) {
+				referenced[asset] = true
+			}
+		}
+		content, offset = rest, strings.Count(string(data[:len(data)-len(rest)]), "\n")
+	}
+
+	var missing []CheckIssue
+	report := func(line int, format string, args ...interface{}) {
+		missing = append(missing, CheckIssue{File: path, Line: offset + line, Message: fmt.Sprintf(format, args...)})
+	}
+	for i, line := range strings.Split(content, "\n") {
+		for _, match := range assetLinkRegex.FindAllStringSubmatch(line, -1) {
+			if asset, ok := bundleAssetPath(match[1]); ok && bundleFileExists(bundleDir, asset) {
+				referenced[asset] = true
+			}
+		}
+		for _, match := range assetAttrRegex.FindAllStringSubmatch(line, -1) {
+			if asset, ok := bundleAssetPath(match[1] + match[2]); ok && bundleFileExists(bundleDir, asset) {
+				referenced[asset] = true
+			}
+		}
+
+		// Only images and media are reported, links may point anywhere
+		for _, match := range checkImageRegex.FindAllStringSubmatch(line, -1) {
+			if asset, ok := bundleAssetPath(match[2]); ok && !bundleFileExists(bundleDir, asset) {
+				report(i+1, "image %s not found in bundle", match[2])
+			}
+		}
+		for _, match := range checkShortcodeSrcRegex.FindAllStringSubmatch(line, -1) {
+			if asset, ok := bundleAssetPath(match[1]); ok && !bundleFileExists(bundleDir, asset) {
+				report(i+1, "media %s not found in bundle", match[1])
+			}
+		}
+	}
+	return missing, nil
+}
+
+// bundleAssetPath returns the slash-separated path of a reference relative to
+// the bundle ("./a%20b.jpg#x" -> "a b.jpg"). External and site-absolute
+// references, links to other posts and references outside of the bundle are
+// not bundle assets.
+func bundleAssetPath(target string) (string, bool) {
+	if strings.Contains(target, "://") || strings.HasPrefix(target, "/") || strings.HasPrefix(target, "{{") ||
+		strings.HasPrefix(target, "#") || strings.HasPrefix(target, "mailto:") {
+		return "", false
+	}
+	if i := strings.IndexAny(target, "?#"); i >= 0 {
+		target = target[:i]
+	}
+	if decoded, err := url.PathUnescape(target); err == nil {
+		target = decoded
+	}
+	target = filepath.ToSlash(filepath.Clean(filepath.FromSlash(target)))
+	if target == "." || target == ".." || strings.HasPrefix(target, "../") {
+		return "", false
+	}
+	return target, true
+}
+
+// bundleAssetFiles returns the files of a bundle that can be assets, as
+// slash-separated paths relative to dir. Markdown files, hidden files (like the
+// state of the translation tool) and nested bundles aren't assets.
+func bundleAssetFiles(dir string, bundles map[string][]string) ([]string, error) {
+	var assets []string
+	err := filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := entry.Name()
+		if entry.IsDir() {
+			if path != dir && (strings.HasPrefix(name, ".") || bundles[path] != nil) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".md") {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		assets = append(assets, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scanning %s: %w", dir, err)
+	}
+	return assets, nil
+}
+
+// isHeaderImage reports whether an asset is the featured image of the bundle,
+// which Hugo finds by its name ("featured.jpeg") instead of a reference, or
+// its social preview variant ("og-image.jpeg"), which is only referenced
+// with social previews switched on.
+func isHeaderImage(asset string) bool {
+	return asset == "og-image.jpeg" || (!strings.Contains(asset, "/") && strings.TrimSuffix(asset, filepath.Ext(asset)) == "featured")
+}
+
+// isConverterFile reports whether an asset is a file the converter writes into
+// the bundle besides the index files: the snippet file in every language
+// ("social.txt", "social.de.txt") and the publish log.
+func isConverterFile(asset string, config *Config) bool {
+	for _, file := range []string{config.Snippets.File, config.PublishLog.File} {
+		if file == "" {
+			continue
+		}
+		ext := filepath.Ext(file)
+		lang, ok := strings.CutPrefix(asset, strings.TrimSuffix(file, ext)+".")
+		lang, hasExt := strings.CutSuffix(lang, ext)
+		if asset == file || (ok && hasExt && lang != "" && !strings.ContainsAny(lang, "./")) {
+			return true
+		}
+	}
+	return false
+}
+
+// removeAsset removes an asset of a bundle and the directories it leaves empty.
+func removeAsset(bundleDir, asset string) error {
+	path := filepath.Join(bundleDir, filepath.FromSlash(asset))
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	for dir := filepath.Dir(path); dir != bundleDir && isInsideDir(bundleDir, dir); dir = filepath.Dir(dir) {
+		// Fails for directories that aren't empty
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	return nil
+}
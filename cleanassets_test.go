@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestAuditBundleAssets tests finding orphaned assets and missing images per bundle
+func TestAuditBundleAssets(t *testing.T) {
+	contentDir := t.TempDir()
+
+	writeFile := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	bundleA := filepath.Join(contentDir, "2025-01-21_A")
+	for _, name := range []string{"photo.jpg", "old photo.jpg", "map.pdf", "clip.mp4", "og-image.jpeg", "featured.jpeg", "2024/beach.jpg", "2024/old.jpg", ".translations.toml"} {
+		writeFile(filepath.Join(bundleA, filepath.FromSlash(name)), "data")
+	}
+	writeFile(filepath.Join(bundleA, "index.de.md"), "+++\ntitle = \"A\"\nimages = [\"og-image.jpeg\"]\n+++\n\n"+
+		"![Foto](photo.jpg)\n"+
+		"![Strand](2024/beach.jpg)\n"+
+		"![Weg](missing.png)\n"+
+		"[Karte](map.pdf) [B]({{< relref \"2025-01-22_B\" >}})\n"+
+		"![remote](https://example.com/a.png)\n")
+	writeFile(filepath.Join(bundleA, "index.en.md"), "+++\ntitle = \"A\"\n+++\n\n"+
+		"{{< video src=\"clip.mp4\" >}}\n"+
+		"{{< video src=\"gone.mp4\" >}}\n")
+
+	bundleB := filepath.Join(contentDir, "2025-01-22_B")
+	writeFile(filepath.Join(bundleB, "index.md"), "+++\ntitle = \"B\"\n+++\n\n![A](../2025-01-21_A/old%20photo.jpg)\n")
+	// The snippet files are written by the converter, unrelated text files are orphans
+	for _, name := range []string{"unused.png", "social.txt", "social.de.txt", "social.pt-br.txt", "social.old.de.txt", "notes.txt"} {
+		writeFile(filepath.Join(bundleB, name), "data")
+	}
+
+	bundles, err := auditBundleAssets(contentDir, DefaultConfig())
+	if err != nil {
+		t.Fatalf("auditBundleAssets() error = %v", err)
+	}
+	if len(bundles) != 2 {
+		t.Fatalf("auditBundleAssets() found %d bundles, want 2", len(bundles))
+	}
+
+	// References into other bundles don't keep their files
+	if want := []string{"2024/old.jpg", "old photo.jpg"}; !reflect.DeepEqual(bundles[0].Orphans, want) {
+		t.Errorf("orphans of A = %v, want %v", bundles[0].Orphans, want)
+	}
+	if want := []string{"notes.txt", "social.old.de.txt", "unused.png"}; !reflect.DeepEqual(bundles[1].Orphans, want) {
+		t.Errorf("orphans of B = %v, want %v", bundles[1].Orphans, want)
+	}
+
+	var missing []string
+	for _, issue := range bundles[0].Missing {
+		missing = append(missing, issue.String())
+	}
+	want := []string{
+		filepath.Join(bundleA, "index.de.md") + ":8: image missing.png not found in bundle",
+		filepath.Join(bundleA, "index.en.md") + ":6: media gone.mp4 not found in bundle",
+	}
+	if !reflect.DeepEqual(missing, want) {
+		t.Errorf("missing of A = %v, want %v", missing, want)
+	}
+	if len(bundles[1].Missing) != 0 {
+		t.Errorf("missing of B = %v, want none", bundles[1].Missing)
+	}
+
+	// Removing an asset removes the directories it leaves empty, not the bundle
+	if err := removeAsset(bundleA, "2024/old.jpg"); err != nil {
+		t.Fatalf("removeAsset() error = %v", err)
+	}
+	if err := removeAsset(bundleA, "2024/beach.jpg"); err != nil {
+		t.Fatalf("removeAsset() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(bundleA, "2024")); !os.IsNotExist(err) {
+		t.Errorf("empty directory 2024 was not removed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(bundleA, "index.de.md")); err != nil {
+		t.Errorf("bundle was removed: %v", err)
+	}
+}
+
+// TestAuditBundleAssets_ConverterOutput tests that the files a conversion writes into the bundle are no orphans
+func TestAuditBundleAssets_ConverterOutput(t *testing.T) {
+	outputDir := t.TempDir()
+	config := DefaultConfig()
+	config.Header.FeaturedSize = "160x90"
+	config.Header.OpenGraphSize = "120x63"
+	if _, err := NewConverter(config, OSFileSystem{}).ConvertFile(context.Background(), "examples/journals/2026_01_17.md", outputDir); err != nil {
+		t.Fatalf("ConvertFile() error = %v", err)
+	}
+
+	bundles, err := auditBundleAssets(outputDir, config)
+	if err != nil {
+		t.Fatalf("auditBundleAssets() error = %v", err)
+	}
+	if len(bundles) != 1 {
+		t.Fatalf("auditBundleAssets() found %d bundles, want 1", len(bundles))
+	}
+	for _, name := range []string{"featured.jpeg", "og-image.jpeg"} {
+		if _, err := os.Stat(filepath.Join(bundles[0].Dir, name)); err != nil {
+			t.Errorf("%s was not written: %v", name, err)
+		}
+	}
+	if len(bundles[0].Orphans) != 0 {
+		t.Errorf("orphans = %v, want none", bundles[0].Orphans)
+	}
+}
+
+// TestBundleAssetPath tests resolving references relative to the bundle
+func TestBundleAssetPath(t *testing.T) {
+	tests := []struct {
+		target string
+		want   string
+		ok     bool
+	}{
+		{"photo.jpg", "photo.jpg", true},
+		{"./2024/a%20b.jpg?w=100", "2024/a b.jpg", true},
+		{"https://example.com/a.png", "", false},
+		{"/images/a.png", "", false},
+		{"#heading", "", false},
+		{"{{< relref \"b\" >}}", "", false},
+		{"../other/a.png", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := bundleAssetPath(tt.target)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("bundleAssetPath(%q) = %q, %v, want %q, %v", tt.target, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -2,7 +2,7 @@
 //
 // Usage:
 //
-//	go run translate.go <input_file.md>
+//	go run translate.go [flags] <input_file.md>
 //	go run translate.go 2025-09-13_SKS/index.de.md
 //
 // The program will:
@@ -10,35 +10,94 @@
 // 2. Detect the source language from the filename (e.g., index.de.md → German)
 // 3. Translate to all other supported languages (English, Spanish, French, Italian, German)
 // 4. Write translated files in the same directory as the input file
+// 5. Verify that numbers, dates and the glossary terms are consistent in all translations
 //
 // Requirements:
-// - OPENAI_API_KEY environment variable must be set
-// - Input file must be in format: index.<lang>.md (e.g., index.de.md, index.en.md)
-// - Input file must have TOML frontmatter (+++...+++)
+//   - OPENAI_API_KEY environment variable must be set
+//   - Input file must be in format: index.<lang>.md (e.g., index.de.md, index.en.md),
+//     or named like the -filename template (the converter's output.filename)
+//   - Input file must have TOML frontmatter (+++...+++)
 package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
 	"time"
+
+	"logseq-to-hugo-converter/internal/indexfile"
+	"logseq-to-hugo-converter/internal/lang"
 )
 
 func main() {
 	// Check command-line arguments
-	if len(os.Args) < 2 {
-		fmt.Println("Usage: go run translate.go <input_file.md>")
+	glossaryPath := flag.String("glossary", "", "TOML file with the key terms and their translations")
+	verifyOnly := flag.Bool("verify", false, "only verify the existing translations, without translating")
+	localize := flag.Bool("localize-numbers", false, "convert decimal and thousands separators to the target language")
+	shortcodesPath := flag.String("shortcodes", "", "TOML file with the shortcode attributes to translate")
+	timeout := flag.Duration("timeout", 10*time.Minute, "time limit of the whole run, finished languages are kept")
+	force := flag.Bool("force", false, "translate all languages again, even the finished ones")
+	rateLimitsPath := flag.String("rate-limits", "", "TOML file with the requests and tokens per minute of the providers")
+	stallTimeout := flag.Duration("stall-timeout", defaultStallTimeout, "retry a request if the model sends nothing for so long (0 = never)")
+	providerName := flag.String("provider", openAIProvider, "provider of the translations: "+strings.Join(providers, ", ")+" (mock writes pseudo-translations without an API key)")
+	configPath := flag.String("config", "", "converter configuration (TOML) with the reading speed of the readingtime param (reading.words_per_minute)")
+	filenameTemplate := flag.String("filename", indexfile.Default, "template of the index file names, like output.filename of the converter (e.g. \"{{.Slug}}.{{.Lang}}.md\")")
+	flag.Parse()
+	if flag.NArg() < 1 {
+		fmt.Println("Usage: go run translate.go [flags] <input_file.md>")
 		fmt.Println()
 		fmt.Println("Example:")
 		fmt.Println("  go run translate.go 2025-09-13_SKS/index.de.md")
 		fmt.Println()
 		fmt.Println("Requirements:")
-		fmt.Println("  - OPENAI_API_KEY environment variable must be set")
-		fmt.Println("  - Input file must be in format: index.<lang>.md")
+		fmt.Println("  - OPENAI_API_KEY environment variable must be set (except for -provider mock)")
+		fmt.Println("  - Input file must be in format: index.<lang>.md (or named like the -filename template)")
+		fmt.Println()
+		fmt.Println("Flags:")
+		flag.CommandLine.SetOutput(os.Stdout)
+		flag.PrintDefaults()
 		os.Exit(1)
 	}
 
-	inputPath := os.Args[1]
+	inputPath := flag.Arg(0)
+	filename, err := indexfile.Parse(*filenameTemplate)
+	if err != nil {
+		fmt.Printf("Error: -filename: %v\n", err)
+		os.Exit(1)
+	}
+	if !filename.UsesLanguage() {
+		fmt.Printf("Error: -filename: %q has no language, the translations would overwrite the post\n", *filenameTemplate)
+		os.Exit(1)
+	}
+
+	// Load the glossary before spending time on the translations
+	var glossary *Glossary
+	if *glossaryPath != "" {
+		var err error
+		if glossary, err = LoadGlossary(*glossaryPath); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	shortcodeRules, err := LoadShortcodeRules(*shortcodesPath)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	rateLimits, err := LoadRateLimits(*rateLimitsPath)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	wordsPerMinute, err := LoadWordsPerMinute(*configPath)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
 
 	// Verify file exists
 	if _, err := os.Stat(inputPath); os.IsNotExist(err) {
@@ -48,7 +107,7 @@ func main() {
 
 	// Parse the input file
 	fmt.Printf("📖 Parsing %s...\n", FormatOutputPath(inputPath))
-	markdownFile, err := ParseMarkdownFile(inputPath)
+	markdownFile, err := ParseMarkdownFile(inputPath, filename)
 	if err != nil {
 		fmt.Printf("Error parsing file: %v\n", err)
 		os.Exit(1)
@@ -57,35 +116,108 @@ func main() {
 	sourceLangName := getLanguageName(markdownFile.SourceLang)
 	fmt.Printf("✓ Detected source language: %s\n\n", sourceLangName)
 
-	// Get target languages (all languages except source)
-	targetLanguages := GetTargetLanguages(markdownFile.SourceLang)
+	// Posts can opt out of machine translation in their front matter
+	translate, err := markdownFile.Frontmatter.Translate()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if !translate {
+		fmt.Println("Not translated: the post has translate = false in its front matter.")
+		os.Exit(0)
+	}
+
+	// Get target languages (all languages except source, or the languages of the front matter)
+	targetLanguages, err := markdownFile.Frontmatter.FilterLanguages(GetTargetLanguages(markdownFile.SourceLang))
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
 
 	if len(targetLanguages) == 0 {
 		fmt.Println("No target languages to translate to.")
 		os.Exit(0)
 	}
 
-	fmt.Printf("🌍 Translating from %s to %d languages...\n", sourceLangName, len(targetLanguages))
+	// Create writer
+	writer := NewTranslationWriter(inputPath, filename)
+
+	// Only check the translations written before
+	if *verifyOnly {
+		translations, err := loadTranslations(writer, targetLanguages)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("🔎 Verifying %d translations...\n", len(translations))
+		discrepancies := VerifyTranslations(markdownFile, translations, glossary, *localize)
+		printDiscrepancies(discrepancies, len(translations))
+		if len(discrepancies) > 0 {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// Languages finished by an earlier (interrupted) run of the same source are skipped
+	state, err := LoadTranslationState(inputPath)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	var pending []Language
+	for _, targetLang := range targetLanguages {
+		if *force || !state.Done(targetLang.Code, writer.GetOutputPath(targetLang.Code)) {
+			pending = append(pending, targetLang)
+		}
+	}
+	resumed := len(targetLanguages) - len(pending)
+	if len(pending) == 0 {
+		fmt.Printf("✓ All %d translations are up to date (use -force to translate again)\n", len(targetLanguages))
+		os.Exit(0)
+	}
+
+	if resumed > 0 {
+		fmt.Printf("🌍 Resuming: translating from %s to %d missing languages (%d done before)...\n", sourceLangName, len(pending), resumed)
+	} else {
+		fmt.Printf("🌍 Translating from %s to %d languages...\n", sourceLangName, len(pending))
+	}
 
 	// Create translator
-	translator, err := NewTranslator()
+	provider, err := NewProvider(*providerName)
 	if err != nil {
 		fmt.Printf("Error initializing translator: %v\n", err)
-		fmt.Println("\nMake sure OPENAI_API_KEY environment variable is set:")
-		fmt.Println("  export OPENAI_API_KEY='sk-...'")
+		if *providerName == openAIProvider {
+			fmt.Println("\nMake sure OPENAI_API_KEY environment variable is set:")
+			fmt.Println("  export OPENAI_API_KEY='sk-...'")
+		}
 		os.Exit(1)
 	}
+	translator := NewTranslator(provider)
+	translator.localizeNumbers = *localize
+	translator.shortcodeRules = shortcodeRules
+	translator.limiter = NewRateLimiter(rateLimits[provider.Name()])
+	translator.stallTimeout = *stallTimeout
+	translator.wordsPerMinute = wordsPerMinute
 
-	// Create writer
-	writer := NewTranslationWriter(inputPath)
-
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	// Create context with timeout, Ctrl+C aborts the run early
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
 	defer cancel()
 
-	// Translate to each target language
-	successCount := 0
-	for _, targetLang := range targetLanguages {
+	// Translate to each missing language
+	successCount := resumed
+	translations := make(map[string]*MarkdownFile)
+	for _, targetLang := range pending {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			fmt.Printf("\n  ⏱ Time limit of %s reached, run again to translate the missing languages\n", *timeout)
+			break
+		}
+		if ctx.Err() != nil {
+			fmt.Println("\n  ⏹ Aborted, run again to translate the missing languages")
+			break
+		}
+
 		translatedFile, err := translator.TranslateMarkdownFile(ctx, markdownFile, targetLang)
 		if err != nil {
 			fmt.Printf("  ✗ Failed to translate to %s: %v\n", targetLang.Name, err)
@@ -100,27 +232,35 @@ func main() {
 		}
 
 		fmt.Printf("  ✓ Created: %s\n", FormatOutputPath(outputPath))
+		if err := state.MarkDone(targetLang.Code, time.Now()); err != nil {
+			fmt.Printf("  ⚠ %v\n", err)
+		}
+		translations[targetLang.Code] = translatedFile
 		successCount++
 	}
 
 	fmt.Printf("\n✅ Successfully translated to %d/%d languages\n", successCount, len(targetLanguages))
 
+	// Numbers and terms the model changed are reported, the translations are kept
+	if len(translations) > 0 {
+		fmt.Println()
+		printDiscrepancies(VerifyTranslations(markdownFile, translations, glossary, *localize), len(translations))
+	}
+
 	if successCount < len(targetLanguages) {
 		os.Exit(1)
 	}
 }
 
-// getLanguageName returns the full language name for a language code.
+// getLanguageName returns the full language name for a language code
+// ("pt-br" -> "Portuguese (BR)").
 func getLanguageName(code string) string {
-	names := map[string]string{
-		"en": "English",
-		"de": "German",
-		"es": "Spanish",
-		"fr": "French",
-		"it": "Italian",
+	tag, ok := lang.Parse(code)
+	if !ok {
+		return code
 	}
-	if name, ok := names[code]; ok {
-		return name
+	if tag.Region != "" {
+		return tag.Name() + " (" + tag.Region + ")"
 	}
-	return code
+	return tag.Name()
 }
@@ -3,36 +3,51 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
+	"net/http"
 	"strings"
 	"time"
 
 	"github.com/openai/openai-go"
-	"github.com/openai/openai-go/option"
+
+	"logseq-to-hugo-converter/internal/words"
 )
 
-// Translator handles translation using OpenAI GPT-4-turbo.
+// Translator handles translation using a model of a provider (OpenAI GPT-4-turbo by default).
 type Translator struct {
-	client *openai.Client
+	provider        Provider
+	localizeNumbers bool                     // Numbers are copied by the model and localized afterwards
+	shortcodeRules  map[string]ShortcodeRule // Attributes of shortcodes to translate
+	limiter         *RateLimiter             // Request budget of the provider account, shared by all translations
+	stallTimeout    time.Duration            // A request is canceled and retried if the model sends nothing for so long (0 = never)
+	wordsPerMinute  int                      // Reading speed of the readingtime param
 }
 
-// NewTranslator creates a new Translator with OpenAI client.
-func NewTranslator() (*Translator, error) {
-	apiKey := os.Getenv("OPENAI_API_KEY")
-	if apiKey == "" {
-		return nil, fmt.Errorf("OPENAI_API_KEY environment variable not set")
-	}
+// defaultStallTimeout is how long a model may send nothing before its request is retried.
+const defaultStallTimeout = time.Minute
 
-	client := openai.NewClient(option.WithAPIKey(apiKey))
+// errStalled is the cause of requests canceled because the model stopped sending.
+var errStalled = errors.New("generation stalled")
 
+// NewTranslator creates a new Translator with a provider.
+func NewTranslator(provider Provider) *Translator {
 	return &Translator{
-		client: &client,
-	}, nil
+		provider:       provider,
+		shortcodeRules: defaultShortcodeRules,
+		limiter:        NewRateLimiter(defaultRateLimits[provider.Name()]),
+		stallTimeout:   defaultStallTimeout,
+		wordsPerMinute: words.DefaultPerMinute,
+	}
 }
 
-// TranslateText translates text to the target language using GPT-4-turbo.
+// TranslateText translates text to the target language using the model of the provider.
 func (t *Translator) TranslateText(ctx context.Context, text, sourceLang, targetLang string) (string, error) {
+	return t.translateText(ctx, text, sourceLang, targetLang, nil)
+}
+
+// translateText translates text and reports the characters received so far to progress (if set).
+func (t *Translator) translateText(ctx context.Context, text, sourceLang, targetLang string, progress func(received int)) (string, error) {
 	systemPrompt := fmt.Sprintf(`You are a professional translator. Translate the following text from %s to %s.
 
 IMPORTANT RULES:
@@ -41,8 +56,12 @@ IMPORTANT RULES:
 3. Maintain the same tone and style as the original
 4. Do NOT add any explanations, notes, or comments
 5. Return ONLY the translated text, nothing else
-6. Keep all HTML tags and shortcodes unchanged (e.g., {{< video src="..." >}})
+6. Keep all HTML tags, shortcodes and placeholders like ⟦SC1⟧ unchanged (e.g., {{< video src="..." >}})
 7. Do not translate file paths or URLs`, sourceLang, targetLang)
+	if t.localizeNumbers {
+		// The number formats are converted by localizeNumbers, not by the model
+		systemPrompt += "\n8. Copy all numbers and units exactly as written, do not convert number formats or units"
+	}
 
 	// Create chat completion with retry logic
 	var translation string
@@ -50,48 +69,94 @@ IMPORTANT RULES:
 	maxRetries := 3
 
 	for attempt := 0; attempt < maxRetries; attempt++ {
-		completion, apiErr := t.client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
-			Model: openai.ChatModelGPT4Turbo,
-			Messages: []openai.ChatCompletionMessageParamUnion{
-				openai.SystemMessage(systemPrompt),
-				openai.UserMessage(text),
-			},
-			Temperature: openai.Float(0.3), // Lower temperature for more deterministic translations
-		})
+		// The response is about as long as the text
+		used, waitErr := t.limiter.Wait(ctx, estimateTokens(systemPrompt)+2*estimateTokens(text))
+		if waitErr != nil {
+			return "", waitErr
+		}
+
+		completion, tokens, apiErr := t.complete(ctx, systemPrompt, text, targetLang, progress)
+		if errors.Is(apiErr, errNoTranslation) {
+			return "", apiErr
+		}
+		// Interrupted or out of time, retrying can't help
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
 
 		if apiErr != nil {
 			err = apiErr
+			// Too many requests stop all translations, not only this one
+			var openaiErr *openai.Error
+			if errors.As(apiErr, &openaiErr) && openaiErr.StatusCode == http.StatusTooManyRequests {
+				t.limiter.Pause(retryAfter(openaiErr.Response))
+			}
 			if attempt < maxRetries-1 {
 				// Wait before retrying
 				time.Sleep(time.Second * time.Duration(attempt+1))
 				continue
 			}
-			return "", fmt.Errorf("OpenAI API call failed after %d attempts: %w", maxRetries, err)
-		}
-
-		if len(completion.Choices) == 0 {
-			return "", fmt.Errorf("no translation returned from API")
+			return "", fmt.Errorf("%s API call failed after %d attempts: %w", t.provider.Name(), maxRetries, err)
 		}
 
-		translation = completion.Choices[0].Message.Content
+		used(tokens)
+		translation = completion
 		break
 	}
 
 	return translation, nil
 }
 
+// complete requests a translation from the provider. A watchdog cancels the
+// request if the model sends nothing for the stall timeout, instead of waiting
+// for the time limit of the whole run.
+func (t *Translator) complete(ctx context.Context, systemPrompt, text, targetLang string, progress func(received int)) (string, int, error) {
+	if progress == nil {
+		progress = func(int) {}
+	}
+	if t.stallTimeout <= 0 {
+		return t.provider.Complete(ctx, systemPrompt, text, targetLang, progress)
+	}
+
+	requestCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	watchdog := time.AfterFunc(t.stallTimeout, func() { cancel(errStalled) })
+	defer watchdog.Stop()
+
+	completion, tokens, err := t.provider.Complete(requestCtx, systemPrompt, text, targetLang, func(received int) {
+		watchdog.Reset(t.stallTimeout)
+		progress(received)
+	})
+	if err != nil && ctx.Err() == nil && errors.Is(context.Cause(requestCtx), errStalled) {
+		return "", 0, fmt.Errorf("%w: nothing received for %s", errStalled, t.stallTimeout)
+	}
+	return completion, tokens, err
+}
+
 // TranslateFrontmatter translates only the title field of the frontmatter.
 // The summary will be extracted from the first paragraph of translated content.
 func (t *Translator) TranslateFrontmatter(ctx context.Context, fm *Frontmatter, sourceLang, targetLang string) (*Frontmatter, error) {
 	translated := *fm // Copy the frontmatter
 
+	// Copy the params so the source frontmatter is not modified
+	translated.Params = copyParams(fm.Params)
+
 	// Translate title
 	if fm.Title != "" {
 		translatedTitle, err := t.TranslateText(ctx, fm.Title, sourceLang, targetLang)
 		if err != nil {
 			return nil, fmt.Errorf("translating title: %w", err)
 		}
-		translated.Title = translatedTitle
+		translated.Title = t.localize(translatedTitle, fm.Title, sourceLang, targetLang)
+	}
+
+	// Translate the meta description (only written if it was generated or set)
+	if fm.Description != "" {
+		translatedDescription, err := t.TranslateText(ctx, fm.Description, sourceLang, targetLang)
+		if err != nil {
+			return nil, fmt.Errorf("translating description: %w", err)
+		}
+		translated.Description = t.localize(translatedDescription, fm.Description, sourceLang, targetLang)
 	}
 
 	// Note: Summary will be set from the first paragraph of translated content
@@ -100,6 +165,15 @@ func (t *Translator) TranslateFrontmatter(ctx context.Context, fm *Frontmatter,
 	return &translated, nil
 }
 
+// localize converts the number formats of a translation to the target language,
+// if enabled. Only the numbers copied from the source are converted.
+func (t *Translator) localize(translated, source, sourceLang, targetLang string) string {
+	if !t.localizeNumbers {
+		return translated
+	}
+	return localizeNumbers(translated, sourceLang, targetLang, numberTokens(source))
+}
+
 // extractFirstParagraph extracts the first paragraph from markdown content.
 // A paragraph is defined as text before the first blank line or heading.
 func extractFirstParagraph(content string) string {
@@ -139,14 +213,40 @@ func extractFirstParagraph(content string) string {
 func (t *Translator) TranslateMarkdownFile(ctx context.Context, mf *MarkdownFile, targetLang Language) (*MarkdownFile, error) {
 	fmt.Printf("  → Translating to %s...", targetLang.Name)
 
-	// Translate content first
-	translatedContent, err := t.TranslateText(ctx, mf.Content, mf.SourceLang, targetLang.Code)
+	// Translate content first, with the shortcodes replaced by placeholders.
+	// The progress is estimated from the length of the source.
+	content, shortcodes := protectShortcodes(mf.Content, t.shortcodeRules)
+	percent := -1
+	translatedContent, err := t.translateText(ctx, content, mf.SourceLang, targetLang.Code, func(received int) {
+		if p := min(received*100/max(len(content), 1), 99); p != percent {
+			percent = p
+			fmt.Printf("\r  → Translating to %s... %d%%", targetLang.Name, percent)
+		}
+	})
 	if err != nil {
 		return nil, fmt.Errorf("translating content: %w", err)
 	}
+	if percent >= 0 {
+		fmt.Printf("\r  → Translating to %s... 100%%", targetLang.Name)
+	}
+
+	translatedContent = t.localize(translatedContent, mf.Content, mf.SourceLang, targetLang.Code)
+
+	// Only the text attributes of the shortcodes are translated (captions, not sources)
+	err = translateShortcodes(shortcodes, func(text string) (string, error) {
+		translated, err := t.TranslateText(ctx, text, mf.SourceLang, targetLang.Code)
+		return t.localize(translated, text, mf.SourceLang, targetLang.Code), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	translatedContent, missing := restoreShortcodes(translatedContent, shortcodes)
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("translation lost the shortcodes %s", strings.Join(missing, ", "))
+	}
 
 	// Add translation disclaimer at the end
-	disclaimer := getTranslationDisclaimer(targetLang.Code, mf.SourceLang)
+	disclaimer := getTranslationDisclaimer(targetLang.Code, mf.SourceLang, mf.Filename)
 	translatedContent = translatedContent + "\n\n" + disclaimer
 
 	// Translate frontmatter (only title, not summary)
@@ -159,6 +259,12 @@ func (t *Translator) TranslateMarkdownFile(ctx context.Context, mf *MarkdownFile
 	// Note: Escaping is handled by SerializeToMarkdown when writing to file
 	translatedFM.Summary = extractFirstParagraph(translatedContent)
 
+	// Recompute word count and reading time for the translated content
+	updateReadingParams(translatedFM, translatedContent, t.wordsPerMinute)
+
+	// Social previews show the translated title and summary
+	updateSocialParams(translatedFM)
+
 	fmt.Println(" ✓")
 
 	return &MarkdownFile{
@@ -169,8 +275,8 @@ func (t *Translator) TranslateMarkdownFile(ctx context.Context, mf *MarkdownFile
 }
 
 // getTranslationDisclaimer returns a translated disclaimer with link to original.
-func getTranslationDisclaimer(targetLang, sourceLang string) string {
-	originalLink := fmt.Sprintf("index.%s.md", sourceLang)
+func getTranslationDisclaimer(targetLang, sourceLang, sourceFile string) string {
+	originalLink := originalPostLink(sourceLang, sourceFile)
 
 	disclaimers := map[string]string{
 		"en": fmt.Sprintf("---\n\n*This blog post has been automatically translated by a Large Language Model. See the [original blog post](%s)*", originalLink),
@@ -187,3 +293,21 @@ func getTranslationDisclaimer(targetLang, sourceLang string) string {
 	// Fallback to English if language not found
 	return disclaimers["en"]
 }
+
+// originalPostLink returns the link to the source language version of the post.
+// Hugo's relref resolves the path of the source file without its language
+// ("index.de.md" -> "index.md", "2025-01-20_Post.de.md" -> "2025-01-20_Post.md")
+// in the bundle of the translation to the page in the given language, so the
+// URL follows the site's language scheme (e.g. "/de/posts/sks/" or "/posts/sks/"
+// for the default language).
+func originalPostLink(sourceLang, sourceFile string) string {
+	lang := strings.ToLower(sourceLang)
+	path := "index.md"
+	if sourceFile != "" {
+		path = sourceFile
+		if stem := strings.TrimSuffix(sourceFile, ".md"); strings.HasSuffix(strings.ToLower(stem), "."+lang) {
+			path = stem[:len(stem)-len(lang)-1] + ".md"
+		}
+	}
+	return fmt.Sprintf(`{{< relref path=%q lang=%q >}}`, path, lang)
+}
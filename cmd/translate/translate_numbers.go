@@ -0,0 +1,115 @@
+// Package main provides the localization of number formats in translations.
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"logseq-to-hugo-converter/internal/lang"
+)
+
+// numberFormat holds the separators of numbers in a language.
+type numberFormat struct {
+	decimal string // Decimal separator ("3,5")
+	group   string // Thousands separator ("1.500")
+}
+
+// numberFormats are the number formats of the supported languages.
+// French groups thousands with a narrow no-break space.
+var numberFormats = map[string]numberFormat{
+	"en": {decimal: ".", group: ","},
+	"de": {decimal: ",", group: "."},
+	"es": {decimal: ",", group: "."},
+	"fr": {decimal: ",", group: "\u202f"},
+	"it": {decimal: ",", group: "."},
+}
+
+// numberTokenRegex matches a number with its separators ("1.500,25", "3,5", "2026").
+var numberTokenRegex = regexp.MustCompile(`\d(?:[\d.,\x{a0}\x{202f}]*\d)?`)
+
+// localizeNumber converts a number from one format to another ("3,5" -> "3.5").
+// Numbers that aren't valid in the source format (like dates "17.01.2026"
+// or versions "1.2.3") and numbers without separators are left as they are.
+func localizeNumber(token string, from, to numberFormat) (string, bool) {
+	integer, fraction, hasFraction := strings.Cut(token, from.decimal)
+	if hasFraction && (fraction == "" || strings.Trim(fraction, "0123456789") != "") {
+		return token, false
+	}
+
+	groups := strings.Split(integer, from.group)
+	if len(groups) > 1 {
+		if len(groups[0]) == 0 || len(groups[0]) > 3 {
+			return token, false
+		}
+		for _, group := range groups[1:] {
+			if len(group) != 3 {
+				return token, false
+			}
+		}
+	}
+	for _, group := range groups {
+		if strings.Trim(group, "0123456789") != "" {
+			return token, false
+		}
+	}
+	if len(groups) == 1 && !hasFraction {
+		return token, false
+	}
+
+	localized := strings.Join(groups, to.group)
+	if hasFraction {
+		localized += to.decimal + fraction
+	}
+	return localized, true
+}
+
+// localizeNumbers converts the numbers of a text from the format of one language
+// to another. If only is set, only the numbers in it are converted (the numbers
+// the translation copied from the source). Code, shortcodes and link targets
+// are left as they are, like the units of the numbers.
+func localizeNumbers(text, sourceLang, targetLang string, only map[string]bool) string {
+	from, fromOK := numberFormatOf(sourceLang)
+	to, toOK := numberFormatOf(targetLang)
+	if !fromOK || !toOK || from == to {
+		return text
+	}
+
+	replace := func(part string) string {
+		return numberTokenRegex.ReplaceAllStringFunc(part, func(token string) string {
+			if only != nil && !only[token] {
+				return token
+			}
+			localized, _ := localizeNumber(token, from, to)
+			return localized
+		})
+	}
+
+	var builder strings.Builder
+	last := 0
+	for _, span := range verifyIgnoreRegex.FindAllStringIndex(text, -1) {
+		builder.WriteString(replace(text[last:span[0]]))
+		builder.WriteString(text[span[0]:span[1]])
+		last = span[1]
+	}
+	builder.WriteString(replace(text[last:]))
+	return builder.String()
+}
+
+// numberTokens returns the numbers of a text.
+func numberTokens(text string) map[string]bool {
+	tokens := make(map[string]bool)
+	for _, token := range numberTokenRegex.FindAllString(verifyIgnoreRegex.ReplaceAllString(text, " "), -1) {
+		tokens[token] = true
+	}
+	return tokens
+}
+
+// numberFormatOf returns the number format of a language code ("de-ch" is "de").
+func numberFormatOf(code string) (numberFormat, bool) {
+	tag, ok := lang.Parse(code)
+	if !ok {
+		return numberFormat{}, false
+	}
+	format, ok := numberFormats[tag.Base]
+	return format, ok
+}
@@ -5,9 +5,14 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/BurntSushi/toml"
+
+	"logseq-to-hugo-converter/internal/indexfile"
+	"logseq-to-hugo-converter/internal/lang"
 )
 
 // MarkdownFile represents a parsed Hugo markdown file.
@@ -15,20 +20,34 @@ type MarkdownFile struct {
 	Frontmatter Frontmatter
 	Content     string
 	SourceLang  string // e.g., "de", "en"
+	Filename    string // Name of the index file, e.g. "index.de.md" (empty for "index.<lang>.md")
 }
 
 // Frontmatter represents the TOML frontmatter of a Hugo file.
 type Frontmatter struct {
-	Date    string            `toml:"date"`
-	LastMod string            `toml:"lastmod"`
-	Draft   bool              `toml:"draft"`
-	Title   string            `toml:"title"`
-	Summary string            `toml:"summary"`
-	Params  map[string]string `toml:"params"`
+	Date        string                 `toml:"date"`
+	LastMod     string                 `toml:"lastmod"`
+	Draft       bool                   `toml:"draft"`
+	Title       string                 `toml:"title"`
+	Summary     string                 `toml:"summary"`
+	Description string                 `toml:"description"`
+	Expiry      string                 `toml:"expiryDate"`
+	Layout      string                 `toml:"layout"`
+	TOC         *bool                  `toml:"toc"`
+	Tags        []string               `toml:"tags"`
+	Categories  []string               `toml:"categories"`
+	Images      []string               `toml:"images"`
+	Aliases     []string               `toml:"aliases"`
+	Params      map[string]interface{} `toml:"params"`
+
+	// ParamOrder keeps the order of the params keys in the parsed file,
+	// so serializing an unchanged file gives the same bytes.
+	// Keys of nested tables are dotted (e.g. "social.title").
+	ParamOrder []string `toml:"-"`
 }
 
-// ParseMarkdownFile reads and parses a Hugo markdown file.
-func ParseMarkdownFile(filePath string) (*MarkdownFile, error) {
+// ParseMarkdownFile reads and parses a Hugo markdown file, an index file named like filename.
+func ParseMarkdownFile(filePath string, filename *indexfile.Name) (*MarkdownFile, error) {
 	// Read the file
 	data, err := os.ReadFile(filePath)
 	if err != nil {
@@ -54,12 +73,20 @@ func ParseMarkdownFile(filePath string) (*MarkdownFile, error) {
 
 	// Parse TOML frontmatter
 	var fm Frontmatter
-	if err := toml.Unmarshal([]byte(frontmatterStr), &fm); err != nil {
+	meta, err := toml.Decode(frontmatterStr, &fm)
+	if err != nil {
 		return nil, fmt.Errorf("parsing frontmatter: %w", err)
 	}
 
+	// Remember the order of the params keys (Keys returns them in file order)
+	for _, key := range meta.Keys() {
+		if len(key) >= 2 && key[0] == "params" {
+			fm.ParamOrder = append(fm.ParamOrder, strings.Join(key[1:], "."))
+		}
+	}
+
 	// Detect source language from filename
-	sourceLang := detectLanguage(filePath)
+	sourceLang := detectLanguage(filePath, filename)
 	if sourceLang == "" {
 		return nil, fmt.Errorf("could not detect language from filename: %s", filePath)
 	}
@@ -68,36 +95,20 @@ func ParseMarkdownFile(filePath string) (*MarkdownFile, error) {
 		Frontmatter: fm,
 		Content:     markdownContent,
 		SourceLang:  sourceLang,
+		Filename:    filepath.Base(filePath),
 	}, nil
 }
 
-// detectLanguage extracts the language code from a filename like "index.de.md"
-func detectLanguage(filePath string) string {
-	// Supported language codes
-	supportedLangs := map[string]bool{
-		"en": true,
-		"de": true,
-		"es": true,
-		"fr": true,
-		"it": true,
-	}
-
-	// Extract just the filename
-	parts := strings.Split(filePath, "/")
-	filename := parts[len(parts)-1]
+// detectLanguage extracts the language key from the name of an index file
+// with the template filename: "index.de.md" or "index.pt-br.md" with the
+// default template (the source can be any language the converter writes)
+func detectLanguage(filePath string, filename *indexfile.Name) string {
+	language, _ := filename.Match(filepath.Base(filePath), filepath.Base(filepath.Dir(filePath)))
 
-	// Look for pattern: index.XX.md
-	if strings.HasPrefix(filename, "index.") && strings.HasSuffix(filename, ".md") {
-		// Extract the language code (e.g., "de" from "index.de.md")
-		langPart := strings.TrimPrefix(filename, "index.")
-		langPart = strings.TrimSuffix(langPart, ".md")
-
-		// Validate that it's a supported language
-		if supportedLangs[langPart] {
-			return langPart
-		}
+	// Validate that it's a known language code (not a name like "deutsch")
+	if tag, ok := lang.Parse(language); ok && tag.Key() == language {
+		return language
 	}
-
 	return ""
 }
 
@@ -114,13 +125,34 @@ func (mf *MarkdownFile) SerializeToMarkdown() string {
 	buf.WriteString(fmt.Sprintf("draft = %t\n", mf.Frontmatter.Draft))
 	buf.WriteString(fmt.Sprintf("title = \"%s\"\n", escapeTomlString(mf.Frontmatter.Title)))
 	buf.WriteString(fmt.Sprintf("summary = \"%s\"\n", escapeTomlString(mf.Frontmatter.Summary)))
+	if mf.Frontmatter.Description != "" {
+		buf.WriteString(fmt.Sprintf("description = \"%s\"\n", escapeTomlString(mf.Frontmatter.Description)))
+	}
+	if mf.Frontmatter.Expiry != "" {
+		buf.WriteString(fmt.Sprintf("expiryDate = \"%s\"\n", escapeTomlString(mf.Frontmatter.Expiry)))
+	}
+	if mf.Frontmatter.Layout != "" {
+		buf.WriteString(fmt.Sprintf("layout = \"%s\"\n", escapeTomlString(mf.Frontmatter.Layout)))
+	}
+	if mf.Frontmatter.TOC != nil {
+		buf.WriteString(fmt.Sprintf("toc = %t\n", *mf.Frontmatter.TOC))
+	}
+	if len(mf.Frontmatter.Tags) > 0 {
+		buf.WriteString(fmt.Sprintf("tags = %s\n", tomlValue(mf.Frontmatter.Tags)))
+	}
+	if len(mf.Frontmatter.Categories) > 0 {
+		buf.WriteString(fmt.Sprintf("categories = %s\n", tomlValue(mf.Frontmatter.Categories)))
+	}
+	if len(mf.Frontmatter.Images) > 0 {
+		buf.WriteString(fmt.Sprintf("images = %s\n", tomlValue(mf.Frontmatter.Images)))
+	}
+	if len(mf.Frontmatter.Aliases) > 0 {
+		buf.WriteString(fmt.Sprintf("aliases = %s\n", tomlValue(mf.Frontmatter.Aliases)))
+	}
 
-	// Write params section
+	// Write params section (and nested tables like [params.social]) in a stable order
 	if len(mf.Frontmatter.Params) > 0 {
-		buf.WriteString("[params]\n")
-		for key, value := range mf.Frontmatter.Params {
-			buf.WriteString(fmt.Sprintf("  %s = \"%s\"\n", key, escapeTomlString(value)))
-		}
+		mf.Frontmatter.writeTable(&buf, "params", mf.Frontmatter.Params, "")
 	}
 
 	buf.WriteString("+++\n\n")
@@ -132,18 +164,132 @@ func (mf *MarkdownFile) SerializeToMarkdown() string {
 	return buf.String()
 }
 
-// escapeTomlString escapes special characters for TOML string values.
-// TOML requires double quotes and backslashes to be escaped.
+// writeTable writes a params table: its values first, then its nested tables.
+// prefix is the dotted path of the table below params (e.g. "social.").
+func (fm *Frontmatter) writeTable(buf *bytes.Buffer, name string, values map[string]interface{}, prefix string) {
+	buf.WriteString("[" + name + "]\n")
+	keys := fm.orderedKeys(values, prefix)
+	for _, key := range keys {
+		if _, isTable := values[key].(map[string]interface{}); !isTable {
+			buf.WriteString(fmt.Sprintf("  %s = %s\n", tomlKey(key), tomlValue(values[key])))
+		}
+	}
+	for _, key := range keys {
+		if table, isTable := values[key].(map[string]interface{}); isTable {
+			fm.writeTable(buf, name+"."+key, table, prefix+key+".")
+		}
+	}
+}
+
+// paramKeys returns the keys of the params in the order they are written.
+func (fm *Frontmatter) paramKeys() []string {
+	return fm.orderedKeys(fm.Params, "")
+}
+
+// orderedKeys returns the keys of a params table in the order they are written:
+// keys from the parsed file keep their order, new keys follow sorted by name.
+func (fm *Frontmatter) orderedKeys(values map[string]interface{}, prefix string) []string {
+	keys := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, path := range fm.ParamOrder {
+		key, ok := strings.CutPrefix(path, prefix)
+		if !ok || strings.Contains(key, ".") {
+			continue
+		}
+		if _, ok := values[key]; ok && !seen[key] {
+			keys = append(keys, key)
+			seen[key] = true
+		}
+	}
+
+	var added []string
+	for key := range values {
+		if !seen[key] {
+			added = append(added, key)
+		}
+	}
+	sort.Strings(added)
+
+	return append(keys, added...)
+}
+
+// escapeTomlString escapes special characters for TOML string values (same as writer.go).
+// TOML requires double quotes, backslashes and control characters
+// (like line breaks in a translated title) to be escaped.
 func escapeTomlString(s string) string {
 	// First, escape backslashes (must be done first!)
 	s = strings.ReplaceAll(s, `\`, `\\`)
 	// Then, escape double quotes
 	s = strings.ReplaceAll(s, `"`, `\"`)
+	// Control characters are only allowed as escape sequences (tabs are fine as they are)
+	if strings.IndexFunc(s, isTomlControl) >= 0 {
+		var builder strings.Builder
+		for _, r := range s {
+			switch {
+			case r == '\n':
+				builder.WriteString(`\n`)
+			case r == '\r':
+				builder.WriteString(`\r`)
+			case isTomlControl(r):
+				fmt.Fprintf(&builder, `\u%04X`, r)
+			default:
+				builder.WriteRune(r)
+			}
+		}
+		s = builder.String()
+	}
 	return s
 }
 
-// GetTargetLanguages returns all supported languages except the source language.
+// tomlKey formats a params key, quoting keys that aren't allowed bare in TOML (same as writer.go).
+func tomlKey(key string) string {
+	for _, r := range key {
+		if !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return `"` + escapeTomlString(key) + `"`
+		}
+	}
+	return key
+}
+
+// isTomlControl reports whether r is a control character that must be escaped in a TOML string.
+func isTomlControl(r rune) bool {
+	return r != '\t' && (r < 0x20 || r == 0x7f)
+}
+
+// tomlValue formats a front matter value as TOML (same as writer.go).
+// Strings are quoted and escaped, slices become arrays.
+func tomlValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return `"` + escapeTomlString(v) + `"`
+	case bool:
+		return fmt.Sprintf("%t", v)
+	case int, int64:
+		return fmt.Sprintf("%d", v)
+	case float64:
+		return fmt.Sprintf("%g", v)
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = tomlValue(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case []string:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = tomlValue(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	default:
+		return tomlValue(fmt.Sprint(v))
+	}
+}
+
+// GetTargetLanguages returns all supported languages except the source language
+// (regional variants count as their language, "de-ch" isn't translated to "de").
 func GetTargetLanguages(sourceLang string) []Language {
+	source, _ := lang.Parse(sourceLang)
+
 	allLanguages := []Language{
 		{Code: "en", Name: "English"},
 		{Code: "de", Name: "German"},
@@ -153,15 +299,61 @@ func GetTargetLanguages(sourceLang string) []Language {
 	}
 
 	var targets []Language
-	for _, lang := range allLanguages {
-		if lang.Code != sourceLang {
-			targets = append(targets, lang)
+	for _, language := range allLanguages {
+		if language.Code != source.Base {
+			targets = append(targets, language)
 		}
 	}
 
 	return targets
 }
 
+// Translate reports whether the post may be machine translated. Posts like
+// poems or wordplay opt out with "translate = false" in their [params].
+func (fm *Frontmatter) Translate() (bool, error) {
+	value, ok := fm.Params["translate"]
+	if !ok {
+		return true, nil
+	}
+	translate, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("params.translate must be true or false, not %v", value)
+	}
+	return translate, nil
+}
+
+// FilterLanguages returns the target languages the post may be translated to:
+// only those of "translate_languages = ["en", "fr"]" in its [params], all if
+// the param isn't set. Regional variants match their language ("pt-br" is "pt").
+func (fm *Frontmatter) FilterLanguages(targets []Language) ([]Language, error) {
+	value, ok := fm.Params["translate_languages"]
+	if !ok {
+		return targets, nil
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("params.translate_languages must be a list of language codes, not %v", value)
+	}
+	var keys []string
+	for _, item := range items {
+		code, _ := item.(string)
+		tag, ok := lang.Parse(code)
+		if !ok {
+			return nil, fmt.Errorf("params.translate_languages: %v is not a known language", item)
+		}
+		keys = append(keys, tag.Key())
+	}
+
+	var filtered []Language
+	for _, target := range targets {
+		tag, _ := lang.Parse(target.Code)
+		if _, ok := tag.Match(keys); ok {
+			filtered = append(filtered, target)
+		}
+	}
+	return filtered, nil
+}
+
 // Language represents a target language for translation.
 type Language struct {
 	Code string // e.g., "de", "en"
@@ -0,0 +1,147 @@
+// Package main provides the models the translations are requested from.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+// mockProvider is the name of the offline provider for tests and previews.
+const mockProvider = "mock"
+
+// providers are the names of the providers, for the usage message.
+var providers = []string{openAIProvider, mockProvider}
+
+// errNoTranslation is returned by a provider whose response has no translation.
+var errNoTranslation = errors.New("no translation returned from API")
+
+// Provider translates a text with a model.
+type Provider interface {
+	// Name returns the name of the provider ("openai").
+	Name() string
+	// Complete returns the model's answer to the system prompt and text,
+	// and how many tokens were used. While the answer is generated, progress
+	// is called with the number of bytes received so far.
+	Complete(ctx context.Context, systemPrompt, text, targetLang string, progress func(received int)) (string, int, error)
+}
+
+// NewProvider creates a provider by its name.
+func NewProvider(name string) (Provider, error) {
+	switch name {
+	case openAIProvider:
+		apiKey := os.Getenv("OPENAI_API_KEY")
+		if apiKey == "" {
+			return nil, fmt.Errorf("OPENAI_API_KEY environment variable not set")
+		}
+		client := openai.NewClient(option.WithAPIKey(apiKey))
+		return &OpenAIProvider{client: &client}, nil
+	case mockProvider:
+		return MockProvider{}, nil
+	default:
+		return nil, fmt.Errorf("unknown provider %q (known: %s)", name, strings.Join(providers, ", "))
+	}
+}
+
+// OpenAIProvider translates with OpenAI GPT-4-turbo.
+type OpenAIProvider struct {
+	client *openai.Client
+}
+
+// Name returns "openai".
+func (p *OpenAIProvider) Name() string {
+	return openAIProvider
+}
+
+// Complete streams the answer of the chat completions API, so the progress
+// of long translations can be shown and stalled generations detected.
+func (p *OpenAIProvider) Complete(ctx context.Context, systemPrompt, text, targetLang string, progress func(received int)) (string, int, error) {
+	stream := p.client.Chat.Completions.NewStreaming(ctx, openai.ChatCompletionNewParams{
+		Model: openai.ChatModelGPT4Turbo,
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			openai.SystemMessage(systemPrompt),
+			openai.UserMessage(text),
+		},
+		Temperature:   openai.Float(0.3), // Lower temperature for more deterministic translations
+		StreamOptions: openai.ChatCompletionStreamOptionsParam{IncludeUsage: openai.Bool(true)},
+	})
+	defer stream.Close()
+
+	var completion strings.Builder
+	tokens := 0
+	for stream.Next() {
+		chunk := stream.Current()
+		for _, choice := range chunk.Choices {
+			completion.WriteString(choice.Delta.Content)
+		}
+		// The usage comes with the last chunk
+		if chunk.Usage.TotalTokens > 0 {
+			tokens = int(chunk.Usage.TotalTokens)
+		}
+		progress(completion.Len())
+	}
+	if err := stream.Err(); err != nil {
+		return "", 0, err
+	}
+	if completion.Len() == 0 {
+		return "", 0, errNoTranslation
+	}
+	return completion.String(), tokens, nil
+}
+
+// MockProvider writes pseudo-translations without a model: every line of text
+// is tagged with the target language ("[en] Der Hafen"). Markdown markers,
+// code blocks and placeholders stay as they are, so the whole pipeline can
+// run without an API key or cost.
+type MockProvider struct{}
+
+// Name returns "mock".
+func (MockProvider) Name() string {
+	return mockProvider
+}
+
+// mockLineRegex splits a line into its markdown markers (headings, lists,
+// quotes) and its text.
+var mockLineRegex = regexp.MustCompile(`^(\s*(?:#{1,6}\s+|[-*+]\s+|\d+\.\s+|>\s*)*)(.*)$`)
+
+// mockPlaceholderRegex matches the placeholders of shortcodes.
+var mockPlaceholderRegex = regexp.MustCompile(`⟦SC\d+⟧`)
+
+// Complete tags the lines of the text with the target language.
+func (MockProvider) Complete(ctx context.Context, systemPrompt, text, targetLang string, progress func(received int)) (string, int, error) {
+	if err := ctx.Err(); err != nil {
+		return "", 0, err
+	}
+	tag := "[" + strings.ToLower(targetLang) + "] "
+
+	lines := strings.Split(text, "\n")
+	inCode := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inCode = !inCode
+			continue
+		}
+		// Code, rules, tables and lines without words stay unchanged
+		if inCode || trimmed == "" || trimmed == "---" || strings.HasPrefix(trimmed, "|") || !mockHasText(trimmed) {
+			continue
+		}
+		parts := mockLineRegex.FindStringSubmatch(line)
+		lines[i] = parts[1] + tag + parts[2]
+	}
+	translation := strings.Join(lines, "\n")
+	progress(len(translation))
+	return translation, 0, nil
+}
+
+// mockHasText reports whether a line has words outside of its placeholders.
+func mockHasText(line string) bool {
+	line = mockPlaceholderRegex.ReplaceAllString(line, "")
+	return strings.IndexFunc(line, func(r rune) bool { return r > 127 || r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' }) >= 0
+}
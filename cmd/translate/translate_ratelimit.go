@@ -0,0 +1,187 @@
+// Package main provides rate limiting of the API requests of a translation run.
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/BurntSushi/toml"
+)
+
+// openAIProvider is the name of the OpenAI provider in the rate limits file.
+const openAIProvider = "openai"
+
+// RateLimit is the request budget of a provider account per minute.
+// A limit of 0 means no limit.
+type RateLimit struct {
+	RequestsPerMinute int `toml:"requests_per_minute"`
+	TokensPerMinute   int `toml:"tokens_per_minute"`
+}
+
+// defaultRateLimits are the limits of the lowest paid OpenAI tier for gpt-4-turbo.
+var defaultRateLimits = map[string]RateLimit{
+	openAIProvider: {RequestsPerMinute: 500, TokensPerMinute: 30000},
+}
+
+// LoadRateLimits reads the limits of a TOML file like
+//
+//	[openai]
+//	requests_per_minute = 5000
+//	tokens_per_minute = 600000
+//
+// on top of the default limits; a provider's table replaces its default limits.
+func LoadRateLimits(path string) (map[string]RateLimit, error) {
+	limits := make(map[string]RateLimit, len(defaultRateLimits))
+	for provider, limit := range defaultRateLimits {
+		limits[provider] = limit
+	}
+	if path == "" {
+		return limits, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading rate limits: %w", err)
+	}
+	var file map[string]RateLimit
+	if _, err := toml.Decode(string(data), &file); err != nil {
+		return nil, fmt.Errorf("parsing rate limits %s: %w", path, err)
+	}
+	for provider, limit := range file {
+		if limit.RequestsPerMinute < 0 || limit.TokensPerMinute < 0 {
+			return nil, fmt.Errorf("rate limits of %s must not be negative", provider)
+		}
+		limits[provider] = limit
+	}
+	return limits, nil
+}
+
+// rateRequest is a request of the last minute with its (estimated or used) tokens.
+type rateRequest struct {
+	at     time.Time
+	tokens int
+}
+
+// RateLimiter keeps the requests of all translations of a run within the
+// limits of a provider. It is safe for concurrent use.
+type RateLimiter struct {
+	limit RateLimit
+	now   func() time.Time
+	sleep func(context.Context, time.Duration) error
+
+	mu       sync.Mutex
+	requests []*rateRequest
+	paused   time.Time // No requests before this time (after a 429 response)
+}
+
+// NewRateLimiter creates a rate limiter for a provider's limits.
+func NewRateLimiter(limit RateLimit) *RateLimiter {
+	return &RateLimiter{limit: limit, now: time.Now, sleep: sleepContext}
+}
+
+// Wait blocks until a request with the estimated tokens fits into the limits
+// of the last minute and records it. The returned function corrects the
+// tokens once the response tells how many were used.
+func (l *RateLimiter) Wait(ctx context.Context, tokens int) (func(used int), error) {
+	for {
+		l.mu.Lock()
+		wait := l.reserve(tokens)
+		if wait == 0 {
+			request := l.requests[len(l.requests)-1]
+			l.mu.Unlock()
+			return func(used int) {
+				l.mu.Lock()
+				request.tokens = used
+				l.mu.Unlock()
+			}, nil
+		}
+		l.mu.Unlock()
+
+		if err := l.sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Pause stops all requests for a while, after the provider answered
+// with "too many requests".
+func (l *RateLimiter) Pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := l.now().Add(d); until.After(l.paused) {
+		l.paused = until
+	}
+}
+
+// reserve records a request if it fits into the limits, or returns how long
+// to wait until it may fit. The caller holds the lock.
+func (l *RateLimiter) reserve(tokens int) time.Duration {
+	now := l.now()
+	if now.Before(l.paused) {
+		return l.paused.Sub(now)
+	}
+
+	// Only the requests of the last minute count
+	start := 0
+	for start < len(l.requests) && !l.requests[start].at.After(now.Add(-time.Minute)) {
+		start++
+	}
+	l.requests = l.requests[start:]
+
+	if l.limit.RequestsPerMinute > 0 && len(l.requests) >= l.limit.RequestsPerMinute {
+		return l.requests[0].at.Add(time.Minute).Sub(now)
+	}
+	if l.limit.TokensPerMinute > 0 {
+		used := 0
+		for _, request := range l.requests {
+			used += request.tokens
+		}
+		// A request larger than the whole budget is sent once the minute is empty
+		for _, request := range l.requests {
+			if used+tokens <= l.limit.TokensPerMinute {
+				break
+			}
+			used -= request.tokens
+			if used+tokens <= l.limit.TokensPerMinute || used == 0 {
+				return request.at.Add(time.Minute).Sub(now)
+			}
+		}
+	}
+
+	l.requests = append(l.requests, &rateRequest{at: now, tokens: tokens})
+	return 0
+}
+
+// retryAfter returns how long a provider asks to wait after "too many requests",
+// or 20 seconds if the response doesn't say.
+func retryAfter(response *http.Response) time.Duration {
+	if response != nil {
+		if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return 20 * time.Second
+}
+
+// estimateTokens estimates the tokens of a text (about 4 characters per token).
+func estimateTokens(text string) int {
+	return utf8.RuneCountInString(text)/4 + 1
+}
+
+// sleepContext waits for a duration or until the context is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
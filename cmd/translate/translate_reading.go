@@ -0,0 +1,51 @@
+// Package main provides word count and reading time updates for translated files.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/BurntSushi/toml"
+
+	"logseq-to-hugo-converter/internal/words"
+)
+
+// LoadWordsPerMinute reads the reading speed of the estimate from the converter
+// configuration (reading.words_per_minute), so the translations get the
+// reading time the converter would give them. Without a configuration, or if
+// it doesn't set the speed, the converter's default is used.
+func LoadWordsPerMinute(path string) (int, error) {
+	if path == "" {
+		return words.DefaultPerMinute, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("reading converter configuration: %w", err)
+	}
+	config := struct {
+		Reading struct {
+			WordsPerMinute int `toml:"words_per_minute"`
+		} `toml:"reading"`
+	}{}
+	config.Reading.WordsPerMinute = words.DefaultPerMinute
+	if _, err := toml.Decode(string(data), &config); err != nil {
+		return 0, fmt.Errorf("parsing converter configuration %s: %w", path, err)
+	}
+	if config.Reading.WordsPerMinute <= 0 {
+		return 0, fmt.Errorf("reading.words_per_minute of %s must be positive", path)
+	}
+	return config.Reading.WordsPerMinute, nil
+}
+
+// updateReadingParams recomputes the wordcount and readingtime params
+// if the source file has them.
+func updateReadingParams(fm *Frontmatter, content string, wordsPerMinute int) {
+	if _, ok := fm.Params["wordcount"]; !ok {
+		return
+	}
+
+	count := words.Count(content)
+	fm.Params["wordcount"] = count
+	fm.Params["readingtime"] = words.ReadingTime(count, wordsPerMinute)
+}
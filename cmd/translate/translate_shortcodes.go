@@ -0,0 +1,220 @@
+// Package main provides the shortcode handling of the translation pipeline.
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ShortcodeRule controls how a Hugo shortcode is translated.
+type ShortcodeRule struct {
+	Translate []string `toml:"translate"`  // Attributes whose values are translated (e.g. "caption")
+	KeepInner bool     `toml:"keep_inner"` // The text between the opening and closing tag stays unchanged
+}
+
+// anyShortcode is the name of the rule for shortcodes without their own rule.
+const anyShortcode = "*"
+
+// defaultShortcodeRules translate the usual text attributes of all shortcodes
+// and keep the content of code shortcodes. Everything else (src, width, ...) is kept.
+var defaultShortcodeRules = map[string]ShortcodeRule{
+	anyShortcode: {Translate: []string{"alt", "caption", "description", "title"}},
+	"highlight":  {KeepInner: true},
+	"mermaid":    {KeepInner: true},
+	"math":       {KeepInner: true},
+}
+
+// LoadShortcodeRules reads the rules of a TOML file like
+//
+//	[figure]
+//	translate = ["caption", "alt"]
+//
+//	[chart]
+//	keep_inner = true
+//
+// on top of the default rules; a rule replaces the default rule of its shortcode.
+func LoadShortcodeRules(path string) (map[string]ShortcodeRule, error) {
+	rules := make(map[string]ShortcodeRule, len(defaultShortcodeRules))
+	for name, rule := range defaultShortcodeRules {
+		rules[name] = rule
+	}
+	if path == "" {
+		return rules, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading shortcode rules: %w", err)
+	}
+	var file map[string]ShortcodeRule
+	if _, err := toml.Decode(string(data), &file); err != nil {
+		return nil, fmt.Errorf("parsing shortcode rules %s: %w", path, err)
+	}
+	for name, rule := range file {
+		rules[name] = rule
+	}
+	return rules, nil
+}
+
+// shortcodeTagRegex matches an opening, closing or self-closing shortcode tag:
+// {{< figure src="a.jpg" caption="Hafen" >}}, {{% /note %}}.
+var shortcodeTagRegex = regexp.MustCompile(`(?s)\{\{([<%])\s*(/?)\s*([\w.\-]+(?:/[\w.\-]+)*)(.*?)\s*[>%]\}\}`)
+
+// shortcodeAttrRegex matches a named attribute of a shortcode with its value.
+var shortcodeAttrRegex = regexp.MustCompile("([\\w-]+)\\s*=\\s*(\"(?:[^\"\\\\]|\\\\.)*\"|`[^`]*`|[^\\s\"`]+)")
+
+// shortcodeCodeRegex matches code, where shortcodes are examples and not translated.
+var shortcodeCodeRegex = regexp.MustCompile("(?s)```.*?```|`[^`\n]*`")
+
+// shortcode is a shortcode tag (or a whole block for keep_inner rules)
+// replaced by a placeholder while the content is translated.
+type shortcode struct {
+	placeholder string
+	text        string   // Original text
+	values      [][]int  // Spans of the attribute values to translate in text
+	translated  []string // Translated values (quoted like in the original)
+}
+
+// Text returns the shortcode with the translated attribute values.
+func (s *shortcode) Text() string {
+	if len(s.translated) == 0 {
+		return s.text
+	}
+	var builder strings.Builder
+	last := 0
+	for i, span := range s.values {
+		builder.WriteString(s.text[last:span[0]])
+		builder.WriteString(s.translated[i])
+		last = span[1]
+	}
+	builder.WriteString(s.text[last:])
+	return builder.String()
+}
+
+// protectShortcodes replaces the shortcodes of the content by placeholders
+// (like ⟦SC1⟧), so the model can't change them. Shortcodes in code are left alone.
+func protectShortcodes(content string, rules map[string]ShortcodeRule) (string, []*shortcode) {
+	code := shortcodeCodeRegex.FindAllStringIndex(content, -1)
+	inCode := func(pos int) bool {
+		for _, span := range code {
+			if pos >= span[0] && pos < span[1] {
+				return true
+			}
+		}
+		return false
+	}
+
+	var shortcodes []*shortcode
+	var builder strings.Builder
+	last := 0
+	for _, match := range shortcodeTagRegex.FindAllStringSubmatchIndex(content, -1) {
+		start, end := match[0], match[1]
+		if start < last || inCode(start) {
+			continue
+		}
+		closing := content[match[4]:match[5]] == "/"
+		name := content[match[6]:match[7]]
+		rule, ok := rules[name]
+		if !ok {
+			rule = rules[anyShortcode]
+		}
+
+		// Blocks whose content is kept are protected as a whole, up to the closing tag
+		if rule.KeepInner && !closing {
+			closeRegex := regexp.MustCompile(`\{\{[<%]\s*/\s*` + regexp.QuoteMeta(name) + `\s*[>%]\}\}`)
+			if loc := closeRegex.FindStringIndex(content[end:]); loc != nil {
+				end += loc[1]
+			}
+		}
+
+		s := &shortcode{placeholder: fmt.Sprintf("⟦SC%d⟧", len(shortcodes)+1), text: content[start:end]}
+		if !closing {
+			args := match[8] - start
+			tagEnd := match[1] - start
+			for _, attr := range shortcodeAttrRegex.FindAllStringSubmatchIndex(s.text[args:tagEnd], -1) {
+				if containsString(rule.Translate, s.text[args+attr[2]:args+attr[3]]) {
+					s.values = append(s.values, []int{args + attr[4], args + attr[5]})
+				}
+			}
+		}
+		shortcodes = append(shortcodes, s)
+
+		builder.WriteString(content[last:start])
+		builder.WriteString(s.placeholder)
+		last = end
+	}
+	builder.WriteString(content[last:])
+	return builder.String(), shortcodes
+}
+
+// translateShortcodes translates the attribute values of the shortcodes with translate.
+// Values are unquoted for the translation and quoted like the original afterwards.
+func translateShortcodes(shortcodes []*shortcode, translate func(string) (string, error)) error {
+	for _, s := range shortcodes {
+		s.translated = nil
+		for _, span := range s.values {
+			value := s.text[span[0]:span[1]]
+			text, quote := unquoteShortcodeValue(value)
+			if strings.TrimSpace(text) == "" {
+				s.translated = append(s.translated, value)
+				continue
+			}
+			translated, err := translate(text)
+			if err != nil {
+				return fmt.Errorf("translating shortcode attribute %q: %w", text, err)
+			}
+			s.translated = append(s.translated, quoteShortcodeValue(strings.TrimSpace(translated), quote))
+		}
+	}
+	return nil
+}
+
+// restoreShortcodes replaces the placeholders of a translation by the (translated)
+// shortcodes. It returns the placeholders the translation lost.
+func restoreShortcodes(text string, shortcodes []*shortcode) (string, []string) {
+	var missing []string
+	for _, s := range shortcodes {
+		if !strings.Contains(text, s.placeholder) {
+			missing = append(missing, s.placeholder)
+			continue
+		}
+		text = strings.ReplaceAll(text, s.placeholder, s.Text())
+	}
+	return text, missing
+}
+
+// unquoteShortcodeValue returns the text of an attribute value and its quote
+// character ('"', '`' or 0 for bare values).
+func unquoteShortcodeValue(value string) (string, byte) {
+	switch {
+	case len(value) >= 2 && value[0] == '"':
+		return strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(value[1 : len(value)-1]), '"'
+	case len(value) >= 2 && value[0] == '`':
+		return value[1 : len(value)-1], '`'
+	default:
+		return value, 0
+	}
+}
+
+// quoteShortcodeValue quotes a translated value like the original. Bare values
+// are quoted, because the translation may have spaces.
+func quoteShortcodeValue(text string, quote byte) string {
+	if quote == '`' && !strings.Contains(text, "`") {
+		return "`" + text + "`"
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text) + `"`
+}
+
+// containsString reports whether list contains s.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
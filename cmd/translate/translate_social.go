@@ -0,0 +1,36 @@
+// Package main provides social preview updates for translated files.
+package main
+
+// updateSocialParams sets the title and description of the [params.social]
+// section written by the converter to the (translated) title and summary.
+// Files without social params are left unchanged.
+func updateSocialParams(fm *Frontmatter) {
+	social, ok := fm.Params["social"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	if _, ok := social["title"]; ok {
+		social["title"] = fm.Title
+	}
+	if _, ok := social["description"]; ok {
+		social["description"] = fm.Summary
+	}
+}
+
+// copyParams copies params including nested tables, so changes to the copy
+// don't modify the original.
+func copyParams(params map[string]interface{}) map[string]interface{} {
+	if params == nil {
+		return nil
+	}
+
+	copied := make(map[string]interface{}, len(params))
+	for key, value := range params {
+		if table, ok := value.(map[string]interface{}); ok {
+			value = copyParams(table)
+		}
+		copied[key] = value
+	}
+	return copied
+}
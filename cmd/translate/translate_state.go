@@ -0,0 +1,92 @@
+// Package main provides the state of translation runs, so interrupted runs can be resumed.
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// stateFilename is the file next to the index files that records the finished
+// translations. Hugo ignores files starting with a dot.
+const stateFilename = ".translations.toml"
+
+// TranslationState records which languages of a post are translated.
+// The state belongs to one version of the source; if the source changes,
+// all languages are translated again.
+type TranslationState struct {
+	Source    string               `toml:"source"`    // File name of the translated index file
+	Hash      string               `toml:"hash"`      // SHA-256 of the source when it was translated
+	Languages map[string]time.Time `toml:"languages"` // Finished languages and when they were written
+
+	path string
+}
+
+// LoadTranslationState reads the state of the translations of an index file.
+// The state is empty if there is none yet, or if it belongs to another
+// source or an older version of it.
+func LoadTranslationState(inputPath string) (*TranslationState, error) {
+	source, err := os.ReadFile(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", inputPath, err)
+	}
+	hash := sha256.Sum256(source)
+	fresh := &TranslationState{
+		Source:    filepath.Base(inputPath),
+		Hash:      hex.EncodeToString(hash[:]),
+		Languages: make(map[string]time.Time),
+		path:      filepath.Join(filepath.Dir(inputPath), stateFilename),
+	}
+
+	data, err := os.ReadFile(fresh.path)
+	if os.IsNotExist(err) {
+		return fresh, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading translation state: %w", err)
+	}
+	var state TranslationState
+	if _, err := toml.Decode(string(data), &state); err != nil {
+		return nil, fmt.Errorf("parsing translation state %s: %w", fresh.path, err)
+	}
+	if state.Source != fresh.Source || state.Hash != fresh.Hash || state.Languages == nil {
+		return fresh, nil
+	}
+	state.path = fresh.path
+	return &state, nil
+}
+
+// Done reports whether a language was translated from the current source
+// and its translation still exists.
+func (s *TranslationState) Done(code, outputPath string) bool {
+	if _, ok := s.Languages[code]; !ok {
+		return false
+	}
+	_, err := os.Stat(outputPath)
+	return err == nil
+}
+
+// MarkDone records a finished language and saves the state right away,
+// so the language isn't lost if the run is interrupted later.
+func (s *TranslationState) MarkDone(code string, now time.Time) error {
+	s.Languages[code] = now.UTC().Truncate(time.Second)
+	return s.Save()
+}
+
+// Save writes the state next to the index files.
+func (s *TranslationState) Save() error {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(s); err != nil {
+		return fmt.Errorf("encoding translation state: %w", err)
+	}
+	if err := os.WriteFile(s.path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("writing translation state: %w", err)
+	}
+	return nil
+}
@@ -1,12 +1,21 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
+
+	"logseq-to-hugo-converter/internal/indexfile"
 )
 
+// testFilename is the default template of the index file names.
+var testFilename = indexfile.MustParse(indexfile.Default)
+
 // TestDetectLanguage tests language detection from filenames
 func TestDetectLanguage(t *testing.T) {
 	tests := []struct {
@@ -19,6 +28,9 @@ func TestDetectLanguage(t *testing.T) {
 		{"Spanish file", "index.es.md", "es"},
 		{"French file", "index.fr.md", "fr"},
 		{"Italian file", "index.it.md", "it"},
+		{"Regional variant", "index.pt-BR.md", "pt-br"},
+		{"Unknown language", "index.xx.md", ""},
+		{"Language name", "index.deutsch.md", ""},
 		{"With path", "/path/to/blog/index.de.md", "de"},
 		{"Invalid format", "blog.md", ""},
 		{"Invalid format 2", "index.md", ""},
@@ -27,12 +39,25 @@ func TestDetectLanguage(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := detectLanguage(tt.filename)
+			got := detectLanguage(tt.filename, testFilename)
 			if got != tt.want {
 				t.Errorf("detectLanguage(%q) = %q, want %q", tt.filename, got, tt.want)
 			}
 		})
 	}
+
+	// Index files named after the bundle
+	slugFilename := indexfile.MustParse("{{.Slug}}.{{.Lang}}.md")
+	if got := detectLanguage("/blog/2025-01-20_Post/2025-01-20_Post.en.md", slugFilename); got != "en" {
+		t.Errorf("detectLanguage() with the slug template = %q, want en", got)
+	}
+	if got := detectLanguage("/blog/2025-01-20_Post/index.en.md", slugFilename); got != "" {
+		t.Errorf("detectLanguage() of another name with the slug template = %q, want none", got)
+	}
+	writer := NewTranslationWriter("/blog/2025-01-20_Post/2025-01-20_Post.de.md", slugFilename)
+	if got, want := writer.GetOutputPath("fr"), filepath.Join("/blog/2025-01-20_Post", "2025-01-20_Post.fr.md"); got != want {
+		t.Errorf("GetOutputPath() with the slug template = %q, want %q", got, want)
+	}
 }
 
 // TestGetTargetLanguages tests getting target languages excluding source
@@ -55,6 +80,18 @@ func TestGetTargetLanguages(t *testing.T) {
 			wantCount:  4,
 			wantCodes:  []string{"de", "es", "fr", "it"},
 		},
+		{
+			name:       "Source is a regional variant",
+			sourceLang: "de-ch",
+			wantCount:  4,
+			wantCodes:  []string{"en", "es", "fr", "it"},
+		},
+		{
+			name:       "Source is Portuguese",
+			sourceLang: "pt-br",
+			wantCount:  5,
+			wantCodes:  []string{"en", "de", "es", "fr", "it"},
+		},
 		{
 			name:       "Source is Spanish",
 			sourceLang: "es",
@@ -196,6 +233,11 @@ func TestEscapeTomlString(t *testing.T) {
 			input: `C:\Users\Name\File.txt`,
 			want:  `C:\\Users\\Name\\File.txt`,
 		},
+		{
+			name:  "Line breaks",
+			input: "Title\r\nsecond line",
+			want:  `Title\r\nsecond line`,
+		},
 		{
 			name:  "Mixed special chars",
 			input: `He wrote "\n" for newline`,
@@ -311,7 +353,7 @@ Content`,
 			}
 
 			// Parse the file
-			got, err := ParseMarkdownFile(testPath)
+			got, err := ParseMarkdownFile(testPath, testFilename)
 
 			if tt.wantErr {
 				if err == nil {
@@ -348,7 +390,7 @@ func TestSerializeToMarkdown(t *testing.T) {
 			Draft:   false,
 			Title:   "Test Title",
 			Summary: "Test Summary",
-			Params: map[string]string{
+			Params: map[string]interface{}{
 				"author": "TestAuthor",
 			},
 		},
@@ -398,7 +440,7 @@ func TestSerializeToMarkdownWithEscaping(t *testing.T) {
 			Draft:   false,
 			Title:   `Title with "quotes"`,
 			Summary: `Summary with "quotes" and \backslash`,
-			Params: map[string]string{
+			Params: map[string]interface{}{
 				"author": `Author "Name"`,
 			},
 		},
@@ -441,7 +483,7 @@ func TestGetTranslationDisclaimer(t *testing.T) {
 				"Large Language Model",
 				"original blog post",
 			},
-			wantLink: "index.de.md",
+			wantLink: `{{< relref path="index.md" lang="de" >}}`,
 		},
 		{
 			name:       "German disclaimer from English",
@@ -453,7 +495,7 @@ func TestGetTranslationDisclaimer(t *testing.T) {
 				"Large Language Model",
 				"originalen Blogbeitrag",
 			},
-			wantLink: "index.en.md",
+			wantLink: `{{< relref path="index.md" lang="en" >}}`,
 		},
 		{
 			name:       "Spanish disclaimer",
@@ -465,7 +507,7 @@ func TestGetTranslationDisclaimer(t *testing.T) {
 				"Large Language Model",
 				"publicación original",
 			},
-			wantLink: "index.en.md",
+			wantLink: `{{< relref path="index.md" lang="en" >}}`,
 		},
 		{
 			name:       "French disclaimer",
@@ -477,7 +519,7 @@ func TestGetTranslationDisclaimer(t *testing.T) {
 				"Large Language Model",
 				"article original",
 			},
-			wantLink: "index.de.md",
+			wantLink: `{{< relref path="index.md" lang="de" >}}`,
 		},
 		{
 			name:       "Italian disclaimer",
@@ -489,13 +531,13 @@ func TestGetTranslationDisclaimer(t *testing.T) {
 				"Large Language Model",
 				"post originale",
 			},
-			wantLink: "index.en.md",
+			wantLink: `{{< relref path="index.md" lang="en" >}}`,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := getTranslationDisclaimer(tt.targetLang, tt.sourceLang)
+			got := getTranslationDisclaimer(tt.targetLang, tt.sourceLang, "index."+tt.sourceLang+".md")
 
 			// Check that all expected strings are present
 			for _, want := range tt.wantContains {
@@ -524,6 +566,49 @@ func TestGetTranslationDisclaimer(t *testing.T) {
 	}
 }
 
+// TestOriginalPostLink tests linking the source file of the post without its language
+func TestOriginalPostLink(t *testing.T) {
+	tests := []struct {
+		sourceLang, sourceFile, want string
+	}{
+		{"de", "index.de.md", `{{< relref path="index.md" lang="de" >}}`},
+		{"pt-br", "index.pt-BR.md", `{{< relref path="index.md" lang="pt-br" >}}`},
+		{"de", "2025-01-20_Post.de.md", `{{< relref path="2025-01-20_Post.md" lang="de" >}}`},
+		{"de", "german.md", `{{< relref path="german.md" lang="de" >}}`},
+		{"en", "", `{{< relref path="index.md" lang="en" >}}`},
+	}
+
+	for _, tt := range tests {
+		if got := originalPostLink(tt.sourceLang, tt.sourceFile); got != tt.want {
+			t.Errorf("originalPostLink(%q, %q) = %s, want %s", tt.sourceLang, tt.sourceFile, got, tt.want)
+		}
+	}
+}
+
+// TestTranslateMarkdownFile_FilenameTemplate tests that the disclaimer links the source file named by the template
+func TestTranslateMarkdownFile_FilenameTemplate(t *testing.T) {
+	filename := indexfile.MustParse("{{.Slug}}.{{.Lang}}.md")
+	inputPath := filepath.Join(t.TempDir(), "2025-01-20_Post", "2025-01-20_Post.de.md")
+	if err := os.MkdirAll(filepath.Dir(inputPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(inputPath, []byte("+++\ntitle = \"Im Hafen\"\n+++\n\nWir lagen im Hafen.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mf, err := ParseMarkdownFile(inputPath, filename)
+	if err != nil {
+		t.Fatalf("ParseMarkdownFile() error = %v", err)
+	}
+
+	translated, err := NewTranslator(MockProvider{}).TranslateMarkdownFile(context.Background(), mf, Language{Code: "en", Name: "English"})
+	if err != nil {
+		t.Fatalf("TranslateMarkdownFile() error = %v", err)
+	}
+	if want := `{{< relref path="2025-01-20_Post.md" lang="de" >}}`; !strings.Contains(translated.Content, want) {
+		t.Errorf("content = %q, want it to contain %q", translated.Content, want)
+	}
+}
+
 // TestRoundTrip tests parsing and serialization round-trip
 func TestRoundTrip(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -534,6 +619,7 @@ lastmod = "2025-01-20"
 draft = false
 title = "Test Title"
 summary = "Test Summary"
+aliases = ["/posts/2025-01-19_old-title/", "/posts/test/"]
 [params]
   author = "TestAuthor"
 +++
@@ -552,7 +638,7 @@ More content here.`
 	}
 
 	// Parse
-	parsed, err := ParseMarkdownFile(testPath)
+	parsed, err := ParseMarkdownFile(testPath, testFilename)
 	if err != nil {
 		t.Fatalf("ParseMarkdownFile() error: %v", err)
 	}
@@ -567,7 +653,7 @@ More content here.`
 		t.Fatalf("Failed to create test file: %v", err)
 	}
 
-	parsed2, err := ParseMarkdownFile(testPath2)
+	parsed2, err := ParseMarkdownFile(testPath2, testFilename)
 	if err != nil {
 		t.Fatalf("Second ParseMarkdownFile() error: %v", err)
 	}
@@ -582,7 +668,620 @@ More content here.`
 	if parsed.Frontmatter.Summary != parsed2.Frontmatter.Summary {
 		t.Errorf("Summary mismatch after round-trip")
 	}
+	if len(parsed2.Frontmatter.Aliases) != 2 || !reflect.DeepEqual(parsed.Frontmatter.Aliases, parsed2.Frontmatter.Aliases) {
+		t.Errorf("Aliases mismatch after round-trip: %v, want %v", parsed2.Frontmatter.Aliases, parsed.Frontmatter.Aliases)
+	}
 	if strings.TrimSpace(parsed.Content) != strings.TrimSpace(parsed2.Content) {
 		t.Errorf("Content mismatch after round-trip")
 	}
 }
+
+// TestUpdateReadingParams tests recomputing reading params for translated content
+func TestUpdateReadingParams(t *testing.T) {
+	fm := &Frontmatter{Params: map[string]interface{}{"author": "benno", "wordcount": int64(3), "readingtime": int64(1)}}
+	updateReadingParams(fm, strings.Repeat("word ", 450), 200)
+
+	if fm.Params["wordcount"] != 450 {
+		t.Errorf("wordcount = %v, want 450", fm.Params["wordcount"])
+	}
+	if fm.Params["readingtime"] != 3 {
+		t.Errorf("readingtime = %v, want 3", fm.Params["readingtime"])
+	}
+
+	// Files without reading params are left alone
+	fm = &Frontmatter{Params: map[string]interface{}{"author": "benno"}}
+	updateReadingParams(fm, "some words", 200)
+	if _, ok := fm.Params["wordcount"]; ok {
+		t.Error("updateReadingParams() added wordcount to a file without it")
+	}
+}
+
+// TestLoadWordsPerMinute tests reading the reading speed from the converter configuration
+func TestLoadWordsPerMinute(t *testing.T) {
+	if got, err := LoadWordsPerMinute(""); err != nil || got != 200 {
+		t.Errorf("LoadWordsPerMinute(\"\") = %d, %v, want 200", got, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "converter.toml")
+	if err := os.WriteFile(path, []byte("[output]\nfilename = \"index.md\"\n\n[reading]\nenabled = true\nwords_per_minute = 150\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadWordsPerMinute(path)
+	if err != nil || got != 150 {
+		t.Errorf("LoadWordsPerMinute() = %d, %v, want 150", got, err)
+	}
+	fm := &Frontmatter{Params: map[string]interface{}{"wordcount": int64(3), "readingtime": int64(1)}}
+	updateReadingParams(fm, strings.Repeat("word ", 350), got)
+	if fm.Params["readingtime"] != 3 {
+		t.Errorf("readingtime = %v, want 3", fm.Params["readingtime"])
+	}
+
+	if err := os.WriteFile(path, []byte("[reading]\nwords_per_minute = 0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadWordsPerMinute(path); err == nil {
+		t.Error("LoadWordsPerMinute() with a speed of 0 should fail")
+	}
+}
+
+// TestParseMarkdownFileWithNumericParams tests that non-string params survive a round trip
+func TestParseMarkdownFileWithNumericParams(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "index.de.md")
+	content := "+++\ndate = \"2025-01-20\"\nlastmod = \"2025-01-20\"\ndraft = false\ntitle = \"T\"\nsummary = \"S\"\n[params]\n  wordcount = 42\n+++\n\nText\n"
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	mf, err := ParseMarkdownFile(testFile, testFilename)
+	if err != nil {
+		t.Fatalf("ParseMarkdownFile() error = %v", err)
+	}
+
+	if result := mf.SerializeToMarkdown(); !strings.Contains(result, "  wordcount = 42\n") {
+		t.Errorf("SerializeToMarkdown() lost numeric param:\n%s", result)
+	}
+}
+
+// TestSerializeToMarkdownIsStable tests that an unchanged file is serialized byte for byte
+func TestSerializeToMarkdownIsStable(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "index.de.md")
+	content := "+++\ndate = \"2025-01-20\"\nlastmod = \"2025-01-20\"\ndraft = false\ntitle = \"T\"\nsummary = \"S\"\ndescription = \"D\"\nexpiryDate = \"2025-06-30\"\nlayout = \"recipe\"\ntags = [\"a\", \"b\"]\ncategories = [\"Sailing\"]\n[params]\n  author = \"benno\"\n  \"légende\" = \"x\"\n  wordcount = 42\n  readingtime = 1\n  related = [\"2025-01-19_X\"]\n+++\n\nText\n"
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	// Serialize several times, map order must not leak into the output
+	for i := 0; i < 10; i++ {
+		mf, err := ParseMarkdownFile(testFile, testFilename)
+		if err != nil {
+			t.Fatalf("ParseMarkdownFile() error = %v", err)
+		}
+		if got := mf.SerializeToMarkdown(); got != content {
+			t.Fatalf("SerializeToMarkdown() =\n%s\nwant\n%s", got, content)
+		}
+	}
+}
+
+// TestParamKeys tests the order of params that were added after parsing
+func TestParamKeys(t *testing.T) {
+	fm := &Frontmatter{
+		Params:     map[string]interface{}{"zeta": 1, "author": "benno", "alpha": 2, "beta": 3},
+		ParamOrder: []string{"author", "beta", "removed"},
+	}
+
+	want := []string{"author", "beta", "alpha", "zeta"}
+	if got := fm.paramKeys(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("paramKeys() = %v, want %v", got, want)
+	}
+}
+
+// TestSocialParamsRoundTrip tests that [params.social] survives parsing and serializing unchanged
+func TestSocialParamsRoundTrip(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "index.de.md")
+	content := "+++\ndate = \"2025-01-20\"\nlastmod = \"2025-01-20\"\ndraft = false\ntitle = \"Titel\"\nsummary = \"Zusammenfassung\"\nimages = [\"og-image.jpeg\"]\n[params]\n  author = \"benno\"\n[params.social]\n  title = \"Titel\"\n  description = \"Zusammenfassung\"\n  image = \"og-image.jpeg\"\n  card = \"summary_large_image\"\n+++\n\nText\n"
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	mf, err := ParseMarkdownFile(testFile, testFilename)
+	if err != nil {
+		t.Fatalf("ParseMarkdownFile() error = %v", err)
+	}
+	if got := mf.SerializeToMarkdown(); got != content {
+		t.Fatalf("SerializeToMarkdown() =\n%s\nwant\n%s", got, content)
+	}
+
+	// A translation updates the copy, not the source
+	translated := mf.Frontmatter
+	translated.Params = copyParams(mf.Frontmatter.Params)
+	translated.Title = "Title"
+	translated.Summary = "Summary"
+	updateSocialParams(&translated)
+
+	social := translated.Params["social"].(map[string]interface{})
+	if social["title"] != "Title" || social["description"] != "Summary" || social["card"] != "summary_large_image" {
+		t.Errorf("Unexpected social params: %v", social)
+	}
+	if source := mf.Frontmatter.Params["social"].(map[string]interface{}); source["title"] != "Titel" {
+		t.Errorf("Source social params were modified: %v", source)
+	}
+}
+
+// TestCountNumbers tests finding numbers, dates and units
+func TestCountNumbers(t *testing.T) {
+	got := countNumbers("Am 2026-01-17 um 14:30 segelten wir 12kn, dann 12 kn über 3,5 km. Seite 7.")
+	want := map[string]int{"2026-01-17": 1, "14:30": 1, "12 kn": 2, "3,5 km": 1, "7": 1}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("countNumbers() = %v, want %v", got, want)
+	}
+}
+
+// TestVerifyTranslations tests reporting inconsistent terms and changed numbers per language
+func TestVerifyTranslations(t *testing.T) {
+	source := &MarkdownFile{
+		Frontmatter: Frontmatter{Title: "Törn 2026"},
+		Content:     "Wir setzten das Großsegel bei 12 kn. Das Großsegel hielt. Der SKS ist geschafft.\n\n![Bild 1](./image_1.jpg)",
+		SourceLang:  "de",
+	}
+	translations := map[string]*MarkdownFile{
+		"en": {
+			Frontmatter: Frontmatter{Title: "Trip 2026"},
+			Content:     "We set the mainsail at 12 kn. The mainsail held. The SKS is done.\n\n![Image 1](./image_1.jpg)\n\n---\n\n*Translated, see the [original](index.de.md)*",
+		},
+		"fr": {
+			Frontmatter: Frontmatter{Title: "Croisière 2025"},
+			Content:     "Nous avons hissé la voile à 12 kn. La grand-voile a tenu. Le permis est obtenu.",
+		},
+	}
+	glossary := &Glossary{Terms: []map[string]string{
+		{"de": "Großsegel", "en": "mainsail", "fr": "grand-voile"},
+		{"de": "SKS"},
+		{"de": "Genua", "en": "genoa"},
+	}}
+
+	var got []string
+	for _, discrepancy := range VerifyTranslations(source, translations, glossary, false) {
+		got = append(got, discrepancy.String())
+	}
+	want := []string{
+		`fr: term: "Großsegel" is used 2 times in the source, "grand-voile" 1 times`,
+		`fr: term: "SKS" is used 1 times in the source, "SKS" 0 times`,
+		`fr: number: "1" is missing (1 in the source, 0 in the translation)`,
+		`fr: number: "2025" was added (0 in the source, 1 in the translation)`,
+		`fr: number: "2026" is missing (1 in the source, 0 in the translation)`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("VerifyTranslations() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// TestLoadGlossary tests reading a glossary and normalizing its language codes
+func TestLoadGlossary(t *testing.T) {
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, "glossary.toml")
+	if err := os.WriteFile(path, []byte("[[terms]]\nde-CH = \"Großsegel\"\nEnglish = \"mainsail\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	glossary, err := LoadGlossary(path)
+	if err != nil {
+		t.Fatalf("LoadGlossary() error = %v", err)
+	}
+	if want := []map[string]string{{"de": "Großsegel", "en": "mainsail"}}; !reflect.DeepEqual(glossary.Terms, want) {
+		t.Errorf("Terms = %v, want %v", glossary.Terms, want)
+	}
+
+	if err := os.WriteFile(path, []byte("[[terms]]\nklingon = \"x\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadGlossary(path); err == nil {
+		t.Error("LoadGlossary() with an unknown language error = nil, want an error")
+	}
+}
+
+// TestLocalizeNumber tests converting numbers between the formats of two languages
+func TestLocalizeNumber(t *testing.T) {
+	de, en, fr := numberFormats["de"], numberFormats["en"], numberFormats["fr"]
+	tests := []struct {
+		token    string
+		from, to numberFormat
+		want     string
+		ok       bool
+	}{
+		{"3,5", de, en, "3.5", true},
+		{"1.500", de, en, "1,500", true},
+		{"1.500,25", de, en, "1,500.25", true},
+		{"12.345.678", de, fr, "12 345 678", true},
+		{"1,500.25", en, de, "1.500,25", true},
+		{"2026", de, en, "2026", false},
+		{"17.01.2026", de, en, "17.01.2026", false},
+		{"1.2.3", en, de, "1.2.3", false},
+		{"1,2", en, de, "1,2", false},
+		{"1,2,3", de, en, "1,2,3", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := localizeNumber(tt.token, tt.from, tt.to)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("localizeNumber(%q) = %q, %v, want %q, %v", tt.token, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// TestLocalizeNumbers tests that only copied numbers outside of code and links are converted
+func TestLocalizeNumbers(t *testing.T) {
+	source := "Wir segelten 3,5 sm bei 1.015 hPa. `v1.500` ![Foto](./img_1.500.jpg)"
+	translated := "We sailed 3,5 nm at 1.015 hPa and 2,5 knots. `v1.500` ![Photo](./img_1.500.jpg)"
+	want := "We sailed 3.5 nm at 1,015 hPa and 2,5 knots. `v1.500` ![Photo](./img_1.500.jpg)"
+	if got := localizeNumbers(translated, "de", "en", numberTokens(source)); got != want {
+		t.Errorf("localizeNumbers() = %q, want %q", got, want)
+	}
+	if got := localizeNumbers(source, "de", "de-ch", nil); got != source {
+		t.Errorf("localizeNumbers() within a language = %q, want it unchanged", got)
+	}
+
+	// Localized numbers are no discrepancies
+	translations := map[string]*MarkdownFile{"en": {Content: "We hiked 3.5 km at 1,015 hPa."}}
+	if got := VerifyTranslations(&MarkdownFile{Content: "Wir wanderten 3,5 km bei 1.015 hPa.", SourceLang: "de"}, translations, nil, true); len(got) != 0 {
+		t.Errorf("VerifyTranslations() = %v, want no discrepancies", got)
+	}
+}
+
+// TestProtectShortcodes tests that shortcodes are replaced by placeholders and only their text attributes are translated
+func TestProtectShortcodes(t *testing.T) {
+	content := "Im Hafen {{< figure src=\"./hafen.jpg\" caption=\"Der \\\"alte\\\" Hafen\" width=600 >}}\n\n" +
+		"{{< highlight go >}}x := \"Hafen\"{{< /highlight >}}\n\n" +
+		"{{% note title=Hinweis %}}Ankern verboten{{% /note %}}\n\n" +
+		"`{{< figure caption=\"Beispiel\" >}}`"
+	protected, shortcodes := protectShortcodes(content, defaultShortcodeRules)
+
+	wantProtected := "Im Hafen ⟦SC1⟧\n\n⟦SC2⟧\n\n⟦SC3⟧Ankern verboten⟦SC4⟧\n\n`{{< figure caption=\"Beispiel\" >}}`"
+	if protected != wantProtected {
+		t.Fatalf("protectShortcodes() = %q, want %q", protected, wantProtected)
+	}
+
+	var translatedValues []string
+	err := translateShortcodes(shortcodes, func(text string) (string, error) {
+		translatedValues = append(translatedValues, text)
+		return map[string]string{`Der "alte" Hafen`: `The "old" harbour`, "Hinweis": "Note"}[text], nil
+	})
+	if err != nil {
+		t.Fatalf("translateShortcodes() error = %v", err)
+	}
+	if want := []string{`Der "alte" Hafen`, "Hinweis"}; !reflect.DeepEqual(translatedValues, want) {
+		t.Errorf("translated values = %q, want %q", translatedValues, want)
+	}
+
+	restored, missing := restoreShortcodes("In the harbour ⟦SC1⟧\n\n⟦SC2⟧\n\n⟦SC3⟧No anchoring⟦SC4⟧", shortcodes)
+	want := "In the harbour {{< figure src=\"./hafen.jpg\" caption=\"The \\\"old\\\" harbour\" width=600 >}}\n\n" +
+		"{{< highlight go >}}x := \"Hafen\"{{< /highlight >}}\n\n" +
+		"{{% note title=\"Note\" %}}No anchoring{{% /note %}}"
+	if restored != want || len(missing) != 0 {
+		t.Errorf("restoreShortcodes() = %q, %v, want %q", restored, missing, want)
+	}
+
+	// Lost placeholders are reported
+	if _, missing := restoreShortcodes("⟦SC1⟧ ⟦SC3⟧", shortcodes); !reflect.DeepEqual(missing, []string{"⟦SC2⟧", "⟦SC4⟧"}) {
+		t.Errorf("restoreShortcodes() missing = %v, want [⟦SC2⟧ ⟦SC4⟧]", missing)
+	}
+}
+
+// TestLoadShortcodeRules tests that the rules of a file replace the default rule of their shortcode
+func TestLoadShortcodeRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shortcodes.toml")
+	if err := os.WriteFile(path, []byte("[figure]\ntranslate = [\"caption\"]\n\n[chart]\nkeep_inner = true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := LoadShortcodeRules(path)
+	if err != nil {
+		t.Fatalf("LoadShortcodeRules() error = %v", err)
+	}
+
+	_, shortcodes := protectShortcodes("{{< figure caption=\"Hafen\" title=\"Hafen\" >}} {{< video title=\"Hafen\" >}} {{< chart >}}a{{< /chart >}}", rules)
+	if len(shortcodes) != 3 {
+		t.Fatalf("protectShortcodes() found %d shortcodes, want 3", len(shortcodes))
+	}
+	for i, want := range []int{1, 1, 0} {
+		if got := len(shortcodes[i].values); got != want {
+			t.Errorf("shortcode %d has %d values to translate, want %d", i+1, got, want)
+		}
+	}
+	if want := "{{< chart >}}a{{< /chart >}}"; shortcodes[2].text != want {
+		t.Errorf("chart shortcode = %q, want %q", shortcodes[2].text, want)
+	}
+
+	if _, err := LoadShortcodeRules(filepath.Join(t.TempDir(), "missing.toml")); err == nil {
+		t.Error("LoadShortcodeRules() of a missing file should fail")
+	}
+}
+
+// TestTranslationState tests that finished languages are remembered until the source changes
+func TestTranslationState(t *testing.T) {
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "index.de.md")
+	if err := os.WriteFile(inputPath, []byte("+++\ntitle = \"Hafen\"\n+++\n\nText\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writer := NewTranslationWriter(inputPath, testFilename)
+
+	state, err := LoadTranslationState(inputPath)
+	if err != nil {
+		t.Fatalf("LoadTranslationState() error = %v", err)
+	}
+	if state.Done("en", writer.GetOutputPath("en")) {
+		t.Error("Done() without a state should be false")
+	}
+
+	// A finished language with its file is done in the next run
+	if err := os.WriteFile(writer.GetOutputPath("en"), []byte("translated"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := state.MarkDone("en", time.Date(2026, 1, 17, 10, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("MarkDone() error = %v", err)
+	}
+	state, err = LoadTranslationState(inputPath)
+	if err != nil {
+		t.Fatalf("LoadTranslationState() error = %v", err)
+	}
+	if !state.Done("en", writer.GetOutputPath("en")) {
+		t.Error("Done(en) after MarkDone() should be true")
+	}
+	if state.Done("fr", writer.GetOutputPath("fr")) {
+		t.Error("Done(fr) should be false")
+	}
+
+	// A deleted translation is translated again
+	if err := os.Remove(writer.GetOutputPath("en")); err != nil {
+		t.Fatal(err)
+	}
+	if state.Done("en", writer.GetOutputPath("en")) {
+		t.Error("Done(en) without the translation should be false")
+	}
+
+	// A changed source starts over
+	if err := os.WriteFile(writer.GetOutputPath("en"), []byte("translated"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(inputPath, []byte("+++\ntitle = \"Hafen\"\n+++\n\nNeuer Text\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	state, err = LoadTranslationState(inputPath)
+	if err != nil {
+		t.Fatalf("LoadTranslationState() error = %v", err)
+	}
+	if state.Done("en", writer.GetOutputPath("en")) {
+		t.Error("Done(en) after the source changed should be false")
+	}
+}
+
+// TestRateLimiter tests that requests wait for the request and token budget of the last minute
+func TestRateLimiter(t *testing.T) {
+	start := time.Date(2026, 1, 17, 10, 0, 0, 0, time.UTC)
+	now := start
+	limiter := NewRateLimiter(RateLimit{RequestsPerMinute: 3, TokensPerMinute: 1000})
+	limiter.now = func() time.Time { return now }
+	limiter.sleep = func(ctx context.Context, d time.Duration) error {
+		now = now.Add(d)
+		return nil
+	}
+	wait := func(tokens int) time.Duration {
+		t.Helper()
+		before := now
+		if _, err := limiter.Wait(context.Background(), tokens); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+		return now.Sub(before)
+	}
+
+	// Three requests per minute
+	for i := 0; i < 3; i++ {
+		if got := wait(100); got != 0 {
+			t.Errorf("request %d waited %s, want 0", i+1, got)
+		}
+	}
+	if got := wait(100); got != time.Minute {
+		t.Errorf("request 4 waited %s, want 1m0s", got)
+	}
+
+	// 1000 tokens per minute, corrected by the used tokens
+	now = start.Add(time.Hour)
+	used, err := limiter.Wait(context.Background(), 100)
+	if err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	used(900)
+	now = now.Add(10 * time.Second)
+	if got := wait(200); got != 50*time.Second {
+		t.Errorf("request over the token budget waited %s, want 50s", got)
+	}
+
+	// A request over the whole budget waits for an empty minute
+	if got := wait(5000); got != time.Minute {
+		t.Errorf("request over the whole budget waited %s, want 1m0s", got)
+	}
+
+	// Too many requests pause all requests
+	now = start.Add(2 * time.Hour)
+	limiter.Pause(30 * time.Second)
+	if got := wait(100); got != 30*time.Second {
+		t.Errorf("request after Pause() waited %s, want 30s", got)
+	}
+
+	// Canceled contexts stop waiting
+	limiter.sleep = sleepContext
+	limiter.Pause(time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := limiter.Wait(ctx, 100); err == nil {
+		t.Error("Wait() with a canceled context should fail")
+	}
+}
+
+// TestLoadRateLimits tests that a provider's limits replace its default limits
+func TestLoadRateLimits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "limits.toml")
+	if err := os.WriteFile(path, []byte("[openai]\nrequests_per_minute = 5000\ntokens_per_minute = 600000\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	limits, err := LoadRateLimits(path)
+	if err != nil {
+		t.Fatalf("LoadRateLimits() error = %v", err)
+	}
+	if want := (RateLimit{RequestsPerMinute: 5000, TokensPerMinute: 600000}); limits[openAIProvider] != want {
+		t.Errorf("openai limits = %+v, want %+v", limits[openAIProvider], want)
+	}
+
+	if err := os.WriteFile(path, []byte("[openai]\ntokens_per_minute = -1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadRateLimits(path); err == nil {
+		t.Error("LoadRateLimits() with a negative limit should fail")
+	}
+}
+
+// TestMockProvider tests that pseudo-translations tag the text of every line and keep the markdown
+func TestMockProvider(t *testing.T) {
+	text := "# Der Hafen\n\n- Erster Punkt\n> Zitat\n\n```go\nx := 1\n```\n\n⟦SC1⟧\n| a | b |\n---"
+	want := "# [en] Der Hafen\n\n- [en] Erster Punkt\n> [en] Zitat\n\n```go\nx := 1\n```\n\n⟦SC1⟧\n| a | b |\n---"
+	got, _, err := MockProvider{}.Complete(context.Background(), "", text, "en", func(int) {})
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("Complete() = %q, want %q", got, want)
+	}
+
+	if _, err := NewProvider("deepl"); err == nil {
+		t.Error("NewProvider() of an unknown provider should fail")
+	}
+}
+
+// TestTranslateMarkdownFile_Mock tests the whole translation pipeline without an API key
+func TestTranslateMarkdownFile_Mock(t *testing.T) {
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "index.de.md")
+	source := "+++\ntitle = \"Im Hafen\"\ndate = \"2026-01-17\"\n+++\n\nWir lagen im Hafen.\n\n{{< figure src=\"./hafen.jpg\" caption=\"Der Hafen\" >}}\n"
+	if err := os.WriteFile(inputPath, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mf, err := ParseMarkdownFile(inputPath, testFilename)
+	if err != nil {
+		t.Fatalf("ParseMarkdownFile() error = %v", err)
+	}
+
+	translator := NewTranslator(MockProvider{})
+	translated, err := translator.TranslateMarkdownFile(context.Background(), mf, Language{Code: "en", Name: "English"})
+	if err != nil {
+		t.Fatalf("TranslateMarkdownFile() error = %v", err)
+	}
+	outputPath, err := NewTranslationWriter(inputPath, testFilename).WriteTranslation(translated, "en")
+	if err != nil {
+		t.Fatalf("WriteTranslation() error = %v", err)
+	}
+
+	written, err := ParseMarkdownFile(outputPath, testFilename)
+	if err != nil {
+		t.Fatalf("ParseMarkdownFile() of the translation error = %v", err)
+	}
+	if written.Frontmatter.Title != "[en] Im Hafen" {
+		t.Errorf("title = %q, want %q", written.Frontmatter.Title, "[en] Im Hafen")
+	}
+	for _, want := range []string{"[en] Wir lagen im Hafen.", `{{< figure src="./hafen.jpg" caption="[en] Der Hafen" >}}`} {
+		if !strings.Contains(written.Content, want) {
+			t.Errorf("content = %q, want it to contain %q", written.Content, want)
+		}
+	}
+}
+
+// stallingProvider sends a few chunks and then nothing until the request is canceled
+type stallingProvider struct {
+	chunks int
+}
+
+func (stallingProvider) Name() string { return "stalling" }
+
+func (p stallingProvider) Complete(ctx context.Context, systemPrompt, text, targetLang string, progress func(received int)) (string, int, error) {
+	for i := 1; i <= p.chunks; i++ {
+		time.Sleep(5 * time.Millisecond)
+		progress(i)
+	}
+	<-ctx.Done()
+	return "", 0, ctx.Err()
+}
+
+// TestTranslatorComplete_Stalled tests that the watchdog cancels a generation that stops sending
+func TestTranslatorComplete_Stalled(t *testing.T) {
+	translator := NewTranslator(stallingProvider{chunks: 5})
+	translator.stallTimeout = 30 * time.Millisecond
+
+	received := 0
+	_, _, err := translator.complete(context.Background(), "", "Text", "en", func(n int) { received = n })
+	if !errors.Is(err, errStalled) {
+		t.Errorf("complete() error = %v, want %v", err, errStalled)
+	}
+	if received != 5 {
+		t.Errorf("progress received %d chunks, want 5", received)
+	}
+
+	// Aborted runs aren't stalled
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	translator.stallTimeout = time.Minute
+	if _, _, err := translator.complete(ctx, "", "Text", "en", nil); errors.Is(err, errStalled) || err == nil {
+		t.Errorf("complete() of an aborted run error = %v, want the context's error", err)
+	}
+}
+
+// TestFrontmatterTranslationOptOut tests the translate and translate_languages params of the source
+func TestFrontmatterTranslationOptOut(t *testing.T) {
+	targets := GetTargetLanguages("de")
+	codes := func(languages []Language) []string {
+		var result []string
+		for _, language := range languages {
+			result = append(result, language.Code)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name      string
+		params    string
+		translate bool
+		want      []string
+		wantErr   bool
+	}{
+		{"no params", "", true, []string{"en", "es", "fr", "it"}, false},
+		{"opted out", "translate = false", false, []string{"en", "es", "fr", "it"}, false},
+		{"some languages", `translate_languages = ["en", "FR"]`, true, []string{"en", "fr"}, false},
+		{"regional variant", `translate_languages = ["en-GB"]`, true, []string{"en"}, false},
+		{"no languages", `translate_languages = []`, true, nil, false},
+		{"unknown language", `translate_languages = ["klingon"]`, true, nil, true},
+		{"not a list", `translate_languages = "en"`, true, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "index.de.md")
+			source := "+++\ntitle = \"Gedicht\"\n[params]\n" + tt.params + "\n+++\n\nText\n"
+			if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+				t.Fatal(err)
+			}
+			mf, err := ParseMarkdownFile(path, testFilename)
+			if err != nil {
+				t.Fatalf("ParseMarkdownFile() error = %v", err)
+			}
+
+			if translate, err := mf.Frontmatter.Translate(); err != nil || translate != tt.translate {
+				t.Errorf("Translate() = %v, %v, want %v", translate, err, tt.translate)
+			}
+			filtered, err := mf.Frontmatter.FilterLanguages(targets)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FilterLanguages() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := codes(filtered); !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FilterLanguages() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+// Package main provides the verification of the translations of a post.
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/BurntSushi/toml"
+
+	"logseq-to-hugo-converter/internal/lang"
+)
+
+// Glossary holds the key terms of the blog and their translations.
+// Every term maps language codes to the term in that language; a term
+// without a translation for a language is expected to stay unchanged.
+type Glossary struct {
+	Terms []map[string]string `toml:"terms"`
+}
+
+// LoadGlossary reads a glossary file like
+//
+//	[[terms]]
+//	de = "Großsegel"
+//	en = "mainsail"
+//
+// The language codes are normalized, so "de-CH" and "Deutsch" are "de".
+func LoadGlossary(path string) (*Glossary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading glossary: %w", err)
+	}
+	var glossary Glossary
+	if _, err := toml.Decode(string(data), &glossary); err != nil {
+		return nil, fmt.Errorf("parsing glossary %s: %w", path, err)
+	}
+
+	for i, term := range glossary.Terms {
+		normalized := make(map[string]string, len(term))
+		for code, text := range term {
+			tag, ok := lang.Parse(code)
+			if !ok {
+				return nil, fmt.Errorf("glossary term %d: unknown language %q", i+1, code)
+			}
+			if strings.TrimSpace(text) == "" {
+				return nil, fmt.Errorf("glossary term %d: empty %s term", i+1, code)
+			}
+			normalized[tag.Base] = strings.TrimSpace(text)
+		}
+		glossary.Terms[i] = normalized
+	}
+	return &glossary, nil
+}
+
+// Discrepancy is a difference between a post and one of its translations.
+type Discrepancy struct {
+	Lang    string // Language code of the translation
+	Kind    string // "term" or "number"
+	Message string
+}
+
+// String formats the discrepancy for the report.
+func (d Discrepancy) String() string {
+	return fmt.Sprintf("%s: %s: %s", d.Lang, d.Kind, d.Message)
+}
+
+// numberRegex matches numbers, dates and times with an optional unit
+// ("12", "3,5", "2026-01-17", "14:30", "12 kn", "25 %").
+var numberRegex = regexp.MustCompile(`\d+(?:[.,:/-]\d+)*(?:\s?(?:km/h|km|nm|sm|kn|m|cm|mm|kg|g|l|h|min|°C|°F|°|%)(?:[^\p{L}\p{N}]|$))?`)
+
+// verifyIgnoreRegex matches the parts of a post whose numbers aren't text:
+// code, shortcodes, HTML tags and the targets of links and images.
+var verifyIgnoreRegex = regexp.MustCompile("(?s)```.*?```|`[^`\n]*`|\\{\\{[<%].*?[%>]\\}\\}|<[^>\n]+>|\\]\\([^)\n]*\\)")
+
+// VerifyTranslations compares a post with its translations (by language code):
+// the terms of the glossary must be translated consistently, and numbers,
+// dates and units must be kept exactly (in the number format of the translation's
+// language if localized is set). The discrepancies are sorted by language.
+func VerifyTranslations(source *MarkdownFile, translations map[string]*MarkdownFile, glossary *Glossary, localized bool) []Discrepancy {
+	sourceText := verifyText(source)
+	sourceNumbers := countNumbers(sourceText)
+	sourceLang, _ := lang.Parse(source.SourceLang)
+
+	codes := make([]string, 0, len(translations))
+	for code := range translations {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	var discrepancies []Discrepancy
+	for _, code := range codes {
+		text := verifyText(translations[code])
+		add := func(kind, format string, args ...interface{}) {
+			discrepancies = append(discrepancies, Discrepancy{Lang: code, Kind: kind, Message: fmt.Sprintf(format, args...)})
+		}
+
+		if glossary != nil {
+			targetLang, _ := lang.Parse(code)
+			for _, term := range glossary.Terms {
+				sourceTerm, ok := term[sourceLang.Base]
+				if !ok {
+					continue
+				}
+				uses := countTerm(sourceText, sourceTerm)
+				if uses == 0 {
+					continue
+				}
+				want, ok := term[targetLang.Base]
+				if !ok {
+					want = sourceTerm
+				}
+				if got := countTerm(text, want); got < uses {
+					add("term", "%q is used %d times in the source, %q %d times", sourceTerm, uses, want, got)
+				}
+			}
+		}
+
+		sourceNumbers := sourceNumbers
+		if localized {
+			sourceNumbers = countNumbers(localizeNumbers(sourceText, source.SourceLang, code, nil))
+		}
+		numbers := countNumbers(text)
+		for _, number := range unionKeys(sourceNumbers, numbers) {
+			switch want, got := sourceNumbers[number], numbers[number]; {
+			case got < want:
+				add("number", "%q is missing (%d in the source, %d in the translation)", number, want, got)
+			case got > want:
+				add("number", "%q was added (%d in the source, %d in the translation)", number, want, got)
+			}
+		}
+	}
+	return discrepancies
+}
+
+// verifyText returns the title and content of a file without the parts
+// whose numbers aren't text. The translation disclaimer is left out.
+func verifyText(mf *MarkdownFile) string {
+	content := mf.Content
+	if i := strings.LastIndex(content, "\n---\n\n*"); i >= 0 {
+		content = content[:i]
+	}
+	return verifyIgnoreRegex.ReplaceAllString(mf.Frontmatter.Title+"\n"+content, " ")
+}
+
+// countNumbers counts the numbers of a text, with their units normalized
+// to a single space ("12kn" and "12 kn" are both "12 kn").
+func countNumbers(text string) map[string]int {
+	counts := make(map[string]int)
+	for _, match := range numberRegex.FindAllString(text, -1) {
+		match = strings.TrimRightFunc(match, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '%' && r != '°'
+		})
+		number := strings.TrimRightFunc(match, func(r rune) bool { return !unicode.IsDigit(r) })
+		if unit := strings.TrimSpace(strings.TrimPrefix(match, number)); unit != "" {
+			number += " " + unit
+		}
+		counts[number]++
+	}
+	return counts
+}
+
+// countTerm counts the uses of a term in a text, ignoring case. A term only
+// counts at the start of a word, but may be inflected ("mainsails").
+func countTerm(text, term string) int {
+	text, term = strings.ToLower(text), strings.ToLower(term)
+	count := 0
+	for i := 0; ; {
+		j := strings.Index(text[i:], term)
+		if j < 0 {
+			return count
+		}
+		start := i + j
+		if before, _ := utf8.DecodeLastRuneInString(text[:start]); start == 0 || !unicode.IsLetter(before) {
+			count++
+		}
+		i = start + len(term)
+	}
+}
+
+// unionKeys returns the keys of both maps in lexical order.
+func unionKeys(a, b map[string]int) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, m := range []map[string]int{a, b} {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// loadTranslations reads the existing translations of a post
+// (the index files of the target languages next to it).
+func loadTranslations(writer *TranslationWriter, targets []Language) (map[string]*MarkdownFile, error) {
+	translations := make(map[string]*MarkdownFile)
+	for _, target := range targets {
+		path := writer.GetOutputPath(target.Code)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+		translation, err := ParseMarkdownFile(path, writer.filename)
+		if err != nil {
+			return nil, err
+		}
+		translations[target.Code] = translation
+	}
+	return translations, nil
+}
+
+// printDiscrepancies prints the verification report.
+func printDiscrepancies(discrepancies []Discrepancy, languages int) {
+	if len(discrepancies) == 0 {
+		fmt.Printf("✓ %d translations are consistent with the source\n", languages)
+		return
+	}
+	fmt.Printf("⚠ %d discrepancies found:\n", len(discrepancies))
+	for _, discrepancy := range discrepancies {
+		fmt.Printf("  %s\n", discrepancy)
+	}
+}
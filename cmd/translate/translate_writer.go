@@ -6,29 +6,30 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"logseq-to-hugo-converter/internal/indexfile"
+	"logseq-to-hugo-converter/internal/lang"
 )
 
 // TranslationWriter handles writing translated markdown files.
 type TranslationWriter struct {
 	inputPath string
+	filename  *indexfile.Name // Template of the index file names (the converter's output.filename)
 }
 
 // NewTranslationWriter creates a new TranslationWriter.
-func NewTranslationWriter(inputPath string) *TranslationWriter {
+func NewTranslationWriter(inputPath string, filename *indexfile.Name) *TranslationWriter {
 	return &TranslationWriter{
 		inputPath: inputPath,
+		filename:  filename,
 	}
 }
 
 // WriteTranslation writes a translated markdown file to disk.
 // It places the file in the same directory as the input file.
 func (w *TranslationWriter) WriteTranslation(mf *MarkdownFile, targetLang string) (string, error) {
-	// Get the directory of the input file
-	dir := filepath.Dir(w.inputPath)
-
-	// Create the output filename (e.g., index.es.md for Spanish)
-	outputFilename := fmt.Sprintf("index.%s.md", targetLang)
-	outputPath := filepath.Join(dir, outputFilename)
+	// The output file is next to the input file (e.g., index.es.md for Spanish)
+	outputPath := w.GetOutputPath(targetLang)
 
 	// Serialize the markdown file
 	content := mf.SerializeToMarkdown()
@@ -42,9 +43,18 @@ func (w *TranslationWriter) WriteTranslation(mf *MarkdownFile, targetLang string
 }
 
 // GetOutputPath returns the expected output path for a given language code.
+// The template was checked when it was parsed, a name it still can't give
+// falls back to "index.<lang>.md".
 func (w *TranslationWriter) GetOutputPath(langCode string) string {
 	dir := filepath.Dir(w.inputPath)
-	outputFilename := fmt.Sprintf("index.%s.md", langCode)
+	language := langCode
+	if tag, ok := lang.Parse(langCode); ok {
+		language = strings.ToLower(tag.Name())
+	}
+	outputFilename, err := w.filename.Execute(indexfile.Data{Lang: langCode, Language: language, Slug: filepath.Base(dir)})
+	if err != nil {
+		outputFilename = fmt.Sprintf("index.%s.md", langCode)
+	}
 	return filepath.Join(dir, outputFilename)
 }
 
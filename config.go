@@ -0,0 +1,718 @@
+// This file handles the optional converter configuration.
+// The configuration is a TOML file (usually converter.toml) that lets users
+// adapt the conversion to their Hugo theme without changing code.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"logseq-to-hugo-converter/internal/words"
+)
+
+// Config holds all user-configurable conversion settings.
+// Every field has a sensible default so the converter works without a config file.
+type Config struct {
+	// CodeShortcodes maps fenced code block languages to Hugo shortcode names.
+	// For example {"mermaid": "mermaid"} turns ```mermaid blocks into
+	// {{< mermaid >}}...{{< /mermaid >}}. Unmapped languages stay fenced.
+	CodeShortcodes map[string]string `toml:"code_shortcodes"`
+
+	// Admonitions controls how callouts (#+BEGIN_NOTE, > [!NOTE]) are converted.
+	Admonitions AdmonitionConfig `toml:"admonitions"`
+
+	// Cleanup controls the removal of Logseq-specific inline markup.
+	Cleanup CleanupConfig `toml:"cleanup"`
+
+	// Queries controls the Logseq queries ({{query ...}}, #+BEGIN_QUERY) in the posts.
+	Queries QueryConfig `toml:"queries"`
+
+	// TOC controls the table of contents of converted posts.
+	TOC TOCConfig `toml:"toc"`
+
+	// Reading controls the word count and reading time params.
+	Reading ReadingConfig `toml:"reading"`
+
+	// Related controls the related posts param.
+	Related RelatedConfig `toml:"related"`
+
+	// Output controls the generated bundles.
+	Output OutputConfig `toml:"output"`
+
+	// Remote controls uploading the generated bundles instead of writing them to the output directory.
+	Remote RemoteConfig `toml:"remote"`
+
+	// Categories controls inferring categories from the journal bullets a post is nested under.
+	Categories CategoriesConfig `toml:"categories"`
+
+	// Sections controls writing posts from namespace pages into section directories.
+	Sections SectionsConfig `toml:"sections"`
+
+	// Dates controls the date format of the front matter and the directory names.
+	Dates DatesConfig `toml:"dates"`
+
+	// Header controls the featured (header) image of the bundles.
+	Header HeaderConfig `toml:"header"`
+
+	// Social controls the metadata for social media previews.
+	Social SocialConfig `toml:"social"`
+
+	// IndieWeb controls the canonical address and syndication metadata for webmentions.
+	IndieWeb IndieWebConfig `toml:"indieweb"`
+
+	// Gallery controls collapsing consecutive images into a gallery shortcode.
+	Gallery GalleryConfig `toml:"gallery"`
+
+	// Captions controls using the block after an image as its caption.
+	Captions CaptionConfig `toml:"captions"`
+
+	// Images controls the size hints Logseq writes after resized images.
+	Images ImageConfig `toml:"images"`
+
+	// Authors maps the Logseq author values to the published author names.
+	Authors AuthorsConfig `toml:"authors"`
+
+	// Location controls the map of posts with a "location::" property.
+	Location LocationConfig `toml:"location"`
+
+	// Tracks controls the maps of GPX tracks referenced in the posts.
+	Tracks TrackConfig `toml:"tracks"`
+
+	// Video controls how the videos of the posts are written.
+	Video VideoConfig `toml:"video"`
+
+	// Data controls turning structured properties into front matter or a table.
+	Data DataConfig `toml:"data"`
+
+	// Marker selects the Logseq blocks that are blog posts: a property like
+	// "type:: blog" (the default) or "publish:: blog", or a tag like "#blog".
+	Marker string `toml:"marker"`
+
+	// Types are content types besides blog posts (e.g. recipes), converted in the same run.
+	Types []ContentTypeConfig `toml:"types"`
+
+	// LLM selects the language model of the optional steps that use one (like AltText).
+	LLM LLMConfig `toml:"llm"`
+
+	// AltText controls generating alt text for images without one.
+	AltText AltTextConfig `toml:"alt_text"`
+
+	// Summary controls generating the summary of posts without a "summary::" property.
+	Summary SummaryConfig `toml:"summary"`
+
+	// Proofread controls checking the spelling and grammar of the posts.
+	Proofread ProofreadConfig `toml:"proofread"`
+
+	// Snippets controls writing a short social media post per converted post.
+	Snippets SnippetConfig `toml:"snippets"`
+
+	// Digest controls the digest of the posts converted by import-all (-digest).
+	Digest DigestConfig `toml:"digest"`
+
+	// Obsidian controls converting Obsidian vaults (detected by their .obsidian directory).
+	Obsidian ObsidianConfig `toml:"obsidian"`
+
+	// PublishLog controls recording when and from what source the posts were generated.
+	PublishLog PublishLogConfig `toml:"publish_log"`
+
+	// SyncBack controls the properties the "sync-back" subcommand writes into the graph.
+	SyncBack SyncBackConfig `toml:"sync_back"`
+
+	// Search controls the search index of the converted posts.
+	Search SearchConfig `toml:"search"`
+
+	// Redirects controls the redirects of posts whose bundle moved or was removed.
+	Redirects RedirectConfig `toml:"redirects"`
+
+	// Language controls the language keys of the index files and detecting the language of posts.
+	Language LanguageConfig `toml:"language"`
+
+	// Filters are the content filters run on every post, in this order.
+	// Removing a name switches a filter off (see DefaultFilters for the built-in ones).
+	Filters []string `toml:"filters"`
+
+	// Rules modify the metadata and content of posts matching a condition
+	// (run by the "rules" content filter).
+	Rules []RuleConfig `toml:"rules"`
+
+	// Hooks are external commands run for every converted post.
+	Hooks []HookConfig `toml:"hooks"`
+
+	// BooleanParams maps boolean properties to front matter params. For example
+	// {"comments": "comments"} turns "comments:: false" into comments = false,
+	// so single posts can switch off the theme's comments. Unmapped properties are ignored.
+	BooleanParams map[string]string `toml:"boolean_params"`
+}
+
+// AdmonitionConfig configures the conversion of Logseq callouts.
+type AdmonitionConfig struct {
+	// Shortcode is the theme's admonition shortcode (e.g. "admonition", "alert").
+	// If empty, callouts become plain blockquotes with a bold label.
+	Shortcode string `toml:"shortcode"`
+
+	// TypeAttribute is the shortcode attribute receiving the callout kind
+	// (e.g. type="note"). If empty, no attribute is written.
+	TypeAttribute string `toml:"type_attribute"`
+}
+
+// CleanupConfig configures the cleanup of Logseq-specific markup.
+type CleanupConfig struct {
+	Highlights   bool `toml:"highlights"`    // Convert ^^text^^ to <mark>text</mark>
+	StripMacros  bool `toml:"strip_macros"`  // Remove {{macros}} (keeping the text of {{cloze}})
+	ReportMarkup bool `toml:"report_markup"` // Warn about Logseq markup left in the output
+}
+
+// QueryConfig configures the Logseq queries in the posts. Their results only
+// exist in Logseq, so queries are removed unless they can be executed.
+type QueryConfig struct {
+	// Placeholder replaces the queries that are not executed, e.g. "<!-- Logseq query -->".
+	// If empty, the queries are removed.
+	Placeholder string `toml:"placeholder"`
+
+	// Execute runs simple tag queries ({{query #hiking}}, {{query [[hiking]]}},
+	// {{query (and #hiking #alps)}}) against the converted posts. The query
+	// becomes a list of links to the other posts with all the tags.
+	Execute bool `toml:"execute"`
+}
+
+// TOCConfig configures the table of contents.
+type TOCConfig struct {
+	// Mode is "front_matter" (writes toc = true) or "shortcode" (injects {{< toc >}}).
+	Mode string `toml:"mode"`
+
+	// Shortcode is the shortcode name used in "shortcode" mode.
+	Shortcode string `toml:"shortcode"`
+
+	// MinHeadings enables the table of contents automatically for posts
+	// with at least this many headings. 0 disables the automatic mode.
+	MinHeadings int `toml:"min_headings"`
+}
+
+// ReadingConfig configures the word count and reading time params.
+type ReadingConfig struct {
+	Enabled        bool `toml:"enabled"`          // Write wordcount and readingtime params
+	WordsPerMinute int  `toml:"words_per_minute"` // Reading speed used for the estimate
+}
+
+// RelatedConfig configures the related posts computed from shared tags and page references.
+type RelatedConfig struct {
+	Enabled bool `toml:"enabled"` // Write a related = [...] param
+	Max     int  `toml:"max"`     // Maximum number of related posts per post
+}
+
+// OutputConfig configures the generated bundles.
+type OutputConfig struct {
+	// SlugPolicy controls non-ASCII characters in directory names:
+	// "unicode" (keep), "ascii" (transliterate) or "percent" (percent-encode).
+	SlugPolicy string `toml:"slug_policy"`
+
+	// PruneExpired removes the bundles of posts whose "expirydate::" has passed
+	// instead of writing them (Hugo hides expired posts, but keeps them in the output).
+	PruneExpired bool `toml:"prune_expired"`
+
+	// Order is the order the posts are converted and reported in:
+	// "date" (then title), "title" (then date) or "source" (as found in the files).
+	Order string `toml:"order"`
+
+	// Filename is the template of the index file name of a bundle, e.g.
+	// "index.{{.Lang}}.md" (the default), "{{.Slug}}.{{.Lang}}.md" or "index.md".
+	Filename string `toml:"filename"`
+
+	// Monolingual writes plain index.md files without language codes for
+	// single-language sites; the dashboard doesn't offer translations then.
+	Monolingual bool `toml:"monolingual"`
+
+	// FileMode and DirMode are the octal permissions of the written files and
+	// directories, e.g. "0640" and "0750" (empty for 0644 and 0755 before the umask).
+	FileMode string `toml:"file_mode"`
+	DirMode  string `toml:"dir_mode"`
+
+	// Owner is the numeric owner of the written files and directories, like
+	// chown: "uid:gid", "uid" or ":gid" (empty for the user running the converter).
+	Owner string `toml:"owner"`
+
+	// MaxBundleSize is the size budget of the media written into a bundle,
+	// e.g. "25MB" (empty for no budget). Oversized bundles are reported with
+	// their largest assets.
+	MaxBundleSize string `toml:"max_bundle_size"`
+
+	// BundleSizeAction is "warn" (print a warning and convert the post anyway)
+	// or "fail" (stop the conversion) for bundles over MaxBundleSize.
+	BundleSizeAction string `toml:"bundle_size_action"`
+}
+
+// RemoteConfig configures the remote target of the generated bundles.
+type RemoteConfig struct {
+	// Target is where the bundles are uploaded to: "s3://bucket/prefix" or
+	// "sftp://user@host/dir" (empty writes to the output directory).
+	Target string `toml:"target"`
+
+	// Endpoint is the URL of an S3-compatible service (empty for AWS in Region).
+	Endpoint string `toml:"endpoint"`
+
+	// Region is the region of the bucket (empty for AWS_REGION or "us-east-1").
+	Region string `toml:"region"`
+
+	// PathStyle puts the bucket into the path instead of the host name
+	// (needed by MinIO and most self-hosted services).
+	PathStyle bool `toml:"path_style"`
+
+	// SFTPCommand is the sftp program and its arguments (default ["sftp"]).
+	SFTPCommand []string `toml:"sftp_command"`
+}
+
+// ContentTypeConfig configures a content type besides blog posts, like recipes.
+type ContentTypeConfig struct {
+	Name       string            `toml:"name"`       // Name of the type (e.g. "recipe")
+	Marker     string            `toml:"marker"`     // Marker of the pages of the type (e.g. "type:: recipe")
+	Section    string            `toml:"section"`    // Output subdirectory (e.g. "recipes", empty for none)
+	Layout     string            `toml:"layout"`     // Hugo layout written to the front matter (optional)
+	Params     map[string]string `toml:"params"`     // Params written for every page of the type
+	Properties []string          `toml:"properties"` // Properties written as params (e.g. "servings")
+	Required   []string          `toml:"required"`   // Properties a page must have, others are skipped
+	Filename   string            `toml:"filename"`   // Template of the index file name (empty for output.filename)
+}
+
+// CategoriesConfig configures the pages referenced by the ancestor bullets of a post
+// ("- [[Sailing]]" with the post nested below it).
+type CategoriesConfig struct {
+	// FromAncestors is the taxonomy the ancestor pages are added to:
+	// "categories", "tags" or "" (not used).
+	FromAncestors string `toml:"from_ancestors"`
+
+	// Ignore lists pages that are only used for structure (e.g. "Blog").
+	Ignore []string `toml:"ignore"`
+}
+
+// LLMConfig configures the language model (the API key is read from OPENAI_API_KEY).
+type LLMConfig struct {
+	Model string `toml:"model"` // OpenAI model (default: DefaultLLMModel)
+}
+
+// AltTextConfig configures generating alt text for images without one.
+type AltTextConfig struct {
+	Enabled bool   `toml:"enabled"` // Ask the language model for the missing alt text
+	Cache   string `toml:"cache"`   // File caching the alt text by image hash
+}
+
+// SummaryConfig configures generating the summary and meta description of posts
+// without a "summary::" property.
+type SummaryConfig struct {
+	Generate bool   `toml:"generate"` // Ask the language model instead of using the first paragraph
+	Cache    string `toml:"cache"`    // File caching the summaries by content hash
+}
+
+// SnippetConfig configures the short social media post (a Mastodon toot or a
+// Bluesky post) written per converted post, to be posted by hand or by a script.
+type SnippetConfig struct {
+	Enabled   bool   `toml:"enabled"`    // Write a snippet per post
+	Generate  bool   `toml:"generate"`   // Ask the language model instead of using the title and summary
+	MaxLength int    `toml:"max_length"` // Maximum characters with hashtags and link (Bluesky: 300, Mastodon: 500)
+	Hashtags  bool   `toml:"hashtags"`   // Add the tags of the post as hashtags (if they fit)
+	File      string `toml:"file"`       // File in the bundle, e.g. "social.txt"
+	Queue     string `toml:"queue"`      // JSON queue file relative to the output directory instead of the bundle files
+	URL       string `toml:"url"`        // Address of the output directory on the site ("https://example.com/posts/")
+	Cache     string `toml:"cache"`      // File caching the generated snippets by content hash
+}
+
+// DigestConfig configures the digest of the posts converted by import-all,
+// which is written with -digest (e.g. for a newsletter).
+type DigestConfig struct {
+	Title    string `toml:"title"`    // Heading of the digest
+	URL      string `toml:"url"`      // Address of the output directory on the site ("https://example.com/posts/")
+	Template string `toml:"template"` // Go template file instead of the built-in one (an HTML template for .html digests)
+}
+
+// ProofreadConfig configures checking the spelling and grammar of the posts.
+type ProofreadConfig struct {
+	Enabled bool   `toml:"enabled"` // Proofread the posts and report the issues
+	Method  string `toml:"method"`  // ProofreadDictionary (default) or ProofreadLLM
+
+	// Dictionaries are the word lists of the dictionary method by language ("german", "english").
+	Dictionaries map[string]string `toml:"dictionaries"`
+
+	Ignore []string `toml:"ignore"` // Words that are always right (names, places)
+	Cache  string   `toml:"cache"`  // File caching the answers of the model by content hash
+}
+
+// PublishLogConfig configures the publish log of the bundles.
+type PublishLogConfig struct {
+	Enabled bool   `toml:"enabled"` // Record the publish events in the bundle and the front matter
+	File    string `toml:"file"`    // History file in the bundle (default DefaultPublishLog)
+}
+
+// LanguageConfig configures the languages of the posts.
+type LanguageConfig struct {
+	Detect        bool    `toml:"detect"`         // Detect the language from the content instead of using German
+	MinConfidence float64 `toml:"min_confidence"` // Detections below this confidence (0-1) are reported
+
+	// Keys are the Hugo language keys of the site ("de", "en", "pt-br"). A post's
+	// language is written with the best matching key ("de-CH" -> "de");
+	// without keys the language code of the post is used as it is.
+	Keys []string `toml:"keys"`
+}
+
+// SyncBackConfig configures writing the publication state back into the Logseq blocks of the posts.
+type SyncBackConfig struct {
+	// URL is the address of the output directory on the site ("https://example.com/posts/").
+	// If set, the posts get a "published_url::" property with the address of their bundle.
+	URL string `toml:"url"`
+
+	// Properties are set on the converted posts (e.g. {published = "true"}).
+	Properties map[string]string `toml:"properties"`
+}
+
+// SearchConfig configures the search index of the converted posts.
+type SearchConfig struct {
+	// Index is the path of the JSON search index, relative to the output directory
+	// (e.g. "../../static/search.json"). If empty, no index is written.
+	Index string `toml:"index"`
+
+	// URL is the address of the output directory on the site ("/posts/" or
+	// "https://example.com/posts/"), the URLs of the posts are below it.
+	URL string `toml:"url"`
+}
+
+// RedirectConfig configures the redirects of moved and removed posts. The
+// bundle paths of the posts are recorded in the slug history of the output
+// directory, so the old addresses are known when a title (and the slug) changes.
+type RedirectConfig struct {
+	// File is the path of a Netlify-style _redirects file, relative to the output
+	// directory (e.g. "../../static/_redirects"). If empty, no file is written.
+	File string `toml:"file"`
+
+	// URL is the address of the output directory on the site ("/posts/").
+	URL string `toml:"url"`
+
+	// Aliases writes the old addresses of moved posts into their "aliases"
+	// front matter, Hugo then writes redirect pages for them.
+	Aliases bool `toml:"aliases"`
+
+	// Pruned is the address removed posts (e.g. expired ones) redirect to in
+	// the redirects file, e.g. "/posts/". If empty, they are not redirected.
+	Pruned string `toml:"pruned"`
+}
+
+// ObsidianConfig configures the conversion of Obsidian vaults.
+type ObsidianConfig struct {
+	// Attachments is the folder of embedded files: a path in the vault ("attachments"),
+	// "/" for the vault root or "./..." for a folder next to the note.
+	// If empty, the attachment folder of the vault settings is used.
+	Attachments string `toml:"attachments"`
+}
+
+// SectionsConfig configures mapping Logseq namespaces to Hugo sections.
+// A post on the page "blog/trips/Renan" is written to "blog/trips/<bundle>".
+type SectionsConfig struct {
+	Enabled bool `toml:"enabled"`
+
+	// Mapping replaces namespaces with section directories (e.g. "blog" = "" or
+	// "blog/trips" = "travel"). The longest matching namespace wins,
+	// unmapped namespace segments become lowercase directory names.
+	Mapping map[string]string `toml:"mapping"`
+}
+
+// DatesConfig configures the date formats. Layouts use Go's reference time
+// (e.g. "2006-01-02T15:04:05-07:00"). Empty layouts keep the dates as written in Logseq.
+type DatesConfig struct {
+	Layout       string `toml:"layout"`        // Layout of date and lastmod in the front matter
+	FolderLayout string `toml:"folder_layout"` // Layout of the date in the directory names
+	TimeZone     string `toml:"time_zone"`     // Time zone of the dates (e.g. "Europe/Zurich", default: local)
+	Time         string `toml:"time"`          // Time of day (HH:MM) for dates without a time
+}
+
+// HeaderConfig configures the featured (header) image.
+type HeaderConfig struct {
+	// FirstImage uses the first inline image as featured image
+	// for posts without a "header::" property.
+	FirstImage bool `toml:"first_image"`
+
+	// RemoveFirstImage removes that image from the content
+	// (otherwise it stays inline as well).
+	RemoveFirstImage bool `toml:"remove_first_image"`
+
+	// FeaturedSize center-crops and resizes the featured image to this size
+	// (e.g. "1600x900") and writes it as featured.jpeg. Empty copies the original.
+	FeaturedSize string `toml:"featured_size"`
+
+	// OpenGraphSize additionally writes an og-image.jpeg of this size
+	// (e.g. "1200x630" for social media previews). Empty writes none.
+	OpenGraphSize string `toml:"og_image_size"`
+
+	// Quality is the JPEG quality (1-100) of resized images.
+	Quality int `toml:"jpeg_quality"`
+}
+
+// SocialConfig configures the OpenGraph and Twitter card metadata.
+type SocialConfig struct {
+	Enabled     bool   `toml:"enabled"`      // Write images = [...] and a [params.social] section
+	TwitterSite string `toml:"twitter_site"` // Twitter/X handle of the site (e.g. "@example")
+}
+
+// IndieWebConfig configures the [params.indieweb] section IndieWeb themes use
+// for microformats and webmentions.
+type IndieWebConfig struct {
+	Enabled     bool     `toml:"enabled"`      // Write the [params.indieweb] section
+	URL         string   `toml:"url"`          // Address of the output directory on the site ("https://example.com/posts/")
+	SyndicateTo []string `toml:"syndicate_to"` // Where the posts are published, e.g. "https://brid.gy/publish/mastodon"
+}
+
+// GalleryConfig configures the gallery shortcode for runs of consecutive images.
+type GalleryConfig struct {
+	// Shortcode is the theme's gallery shortcode (e.g. "gallery").
+	// If empty, images are never collapsed into a gallery.
+	Shortcode string `toml:"shortcode"`
+
+	// MinImages is the number of consecutive images that form a gallery.
+	MinImages int `toml:"min_images"`
+}
+
+// CaptionConfig configures the caption heuristic for images.
+type CaptionConfig struct {
+	// Mode is "nested" (the caption is a bullet nested below the image),
+	// "sibling" (the caption is the next bullet after the image) or empty (off).
+	Mode string `toml:"mode"`
+}
+
+// ImageConfig configures the images of the posts.
+type ImageConfig struct {
+	// SizeShortcode is the theme's image shortcode (e.g. "figure") for images
+	// resized in Logseq ({:height 400, :width 600}), written as
+	// {{< figure src="photo.jpg" alt="..." width="600" height="400" >}}.
+	// If empty, the size hints are removed and the images stay markdown images.
+	SizeShortcode string `toml:"size_shortcode"`
+
+	// LazyShortcode is the theme's image shortcode (e.g. "figure") every image in
+	// the bundle is written with, with loading="lazy" so browsers only load the
+	// images scrolled into view. It takes precedence over SizeShortcode; the size
+	// hints are passed along. If empty, the images stay markdown images.
+	LazyShortcode string `toml:"lazy_shortcode"`
+
+	// Placeholders writes a small blurred thumbnail next to every JPEG, PNG and GIF
+	// image ("photo.jpg" -> "photo.placeholder.jpg"), passed to the lazy shortcode
+	// as placeholder="photo.placeholder.jpg" for themes to show while loading.
+	Placeholders bool `toml:"placeholders"`
+
+	// PlaceholderWidth is the width of the placeholder thumbnails in pixels.
+	PlaceholderWidth int `toml:"placeholder_width"`
+
+	// FrontMatter lists all images of the bundle in the "images" front matter,
+	// the featured image first, then the images in the order of the content.
+	// Themes use it for galleries and social cards.
+	FrontMatter bool `toml:"front_matter"`
+}
+
+// LocationConfig configures the map of a post's location.
+type LocationConfig struct {
+	// MapShortcode is the theme's map shortcode (e.g. "map") added at the end
+	// of posts with a location. If empty, the location is only written to the front matter.
+	MapShortcode string `toml:"map_shortcode"`
+}
+
+// TrackConfig configures GPX tracks (e.g. hiking or sailing routes).
+type TrackConfig struct {
+	// Shortcode is the theme's map shortcode for tracks (e.g. "gpx"), written as
+	// {{< gpx src="track.gpx" >}}. If empty, GPX files are copied like other assets.
+	Shortcode string `toml:"shortcode"`
+}
+
+// VideoConfig configures how videos from the assets are written.
+type VideoConfig struct {
+	// Mode is "shortcode" (the theme's video shortcode) or "html" (an HTML5
+	// <video controls> tag, for themes without a video shortcode).
+	Mode string `toml:"mode"`
+
+	// Shortcode is the shortcode name used in "shortcode" mode (default "video").
+	Shortcode string `toml:"shortcode"`
+
+	// Source is the shortcode attribute with the file name (default "src").
+	Source string `toml:"source"`
+
+	// Attributes are added to every video, sorted by name (e.g. {autoplay = "true"}).
+	// In "html" mode, attributes with an empty value are written without one ("muted").
+	Attributes map[string]string `toml:"attributes"`
+
+	// Transcode re-encodes the videos with ffmpeg before they are written into the bundle.
+	Transcode VideoTranscodeConfig `toml:"transcode"`
+}
+
+// VideoTranscodeConfig configures re-encoding videos to web-friendly files.
+type VideoTranscodeConfig struct {
+	Enabled   bool   `toml:"enabled"`    // Transcode the videos (if ffmpeg is found)
+	Codec     string `toml:"codec"`      // "h264" (.mp4, default) or "vp9" (.webm)
+	Bitrate   string `toml:"bitrate"`    // Video bitrate in ffmpeg notation (e.g. "2M")
+	MaxHeight int    `toml:"max_height"` // Videos higher than this are scaled down (0 = keep the size)
+	FFmpeg    string `toml:"ffmpeg"`     // ffmpeg program (default "ffmpeg" on the PATH)
+}
+
+// DataConfig configures structured data properties (e.g. weather data of a garden journal).
+type DataConfig struct {
+	// Mode is "front_matter" (writes a [params.data] section) or "table"
+	// (puts a markdown table at the top of the post).
+	Mode string `toml:"mode"`
+
+	// Properties are the properties used as data, in the order they are written.
+	// If empty, no data is written.
+	Properties []string `toml:"properties"`
+
+	// Labels are the table headers of the properties (default: the capitalized property name).
+	Labels map[string]string `toml:"labels"`
+}
+
+// RuleConfig is a rule that modifies the posts matching its condition.
+type RuleConfig struct {
+	// When is the condition, e.g. 'tags contains "garden" and language == "english"'.
+	// Fields are the metadata (title, date, author, summary, status, language, header,
+	// tags), "content" and other properties, operators are ==, !=, contains and matches
+	// (a regular expression). Empty matches every post.
+	When string `toml:"when"`
+
+	Set        map[string]string `toml:"set"`         // Properties to set (e.g. {author = "Team"})
+	AddTags    []string          `toml:"add_tags"`    // Tags to add
+	RemoveTags []string          `toml:"remove_tags"` // Tags to remove (case-insensitive)
+	Replace    []RuleReplacement `toml:"replace"`     // Replacements in the content blocks
+}
+
+// RuleReplacement replaces a regular expression in the content blocks.
+type RuleReplacement struct {
+	Pattern string `toml:"pattern"` // Regular expression (Go syntax)
+	With    string `toml:"with"`    // Replacement ($1 refers to the first group)
+}
+
+// HookConfig configures an external command run for every converted post.
+// The command runs in the bundle directory and gets the post metadata as JSON on stdin
+// and as LOGSEQ2HUGO_BUNDLE_DIR, LOGSEQ2HUGO_POST_TITLE, LOGSEQ2HUGO_POST_DATE,
+// LOGSEQ2HUGO_POST_SLUG, LOGSEQ2HUGO_POST_SECTION and LOGSEQ2HUGO_POST_SOURCE
+// environment variables. They don't use the HUGO_ prefix, which Hugo reads as
+// configuration overrides (e.g. HUGO_PARAMS_*) when a hook runs hugo.
+type HookConfig struct {
+	Name    string   `toml:"name"`    // Name used in messages (optional)
+	Command []string `toml:"command"` // Program and arguments (e.g. ["jpegoptim", "--strip-all"])
+
+	// Stage is "pre" (before the index file is written, the assets are already
+	// in the bundle) or "post" (after the index file is written, the default).
+	Stage string `toml:"stage"`
+
+	// OnFailure is "warn" (the default), "fail" (stop the conversion) or "ignore".
+	OnFailure string `toml:"on_failure"`
+}
+
+// AuthorsConfig configures the author registry.
+type AuthorsConfig struct {
+	// Strict fails the conversion for authors missing from the registry
+	// (otherwise they are published as written in Logseq).
+	Strict bool `toml:"strict"`
+
+	// Registry maps the "author::" values (case-insensitive) to the published authors.
+	Registry map[string]AuthorConfig `toml:"registry"`
+}
+
+// AuthorConfig is a published author of the registry.
+type AuthorConfig struct {
+	Name  string `toml:"name"`  // Display name (empty keeps the Logseq value)
+	Email string `toml:"email"` // Email address (optional)
+	URL   string `toml:"url"`   // Profile URL (optional)
+}
+
+// DefaultConfig returns the configuration used when no config file is given.
+func DefaultConfig() *Config {
+	return &Config{
+		Marker:         DefaultMarker,
+		CodeShortcodes: map[string]string{},
+		BooleanParams:  map[string]string{"comments": "comments"},
+		Filters:        append([]string(nil), DefaultFilters...),
+		Admonitions: AdmonitionConfig{
+			TypeAttribute: "type",
+		},
+		Cleanup: CleanupConfig{
+			Highlights:   true,
+			StripMacros:  true,
+			ReportMarkup: true,
+		},
+		TOC: TOCConfig{
+			Mode:      "front_matter",
+			Shortcode: "toc",
+		},
+		Reading: ReadingConfig{
+			WordsPerMinute: words.DefaultPerMinute,
+		},
+		Related: RelatedConfig{
+			Enabled: true,
+			Max:     3,
+		},
+		Output: OutputConfig{
+			SlugPolicy:       SlugPolicyUnicode,
+			Order:            OrderDate,
+			Filename:         DefaultFilename,
+			BundleSizeAction: BundleSizeWarn,
+		},
+		Search: SearchConfig{
+			URL: "/",
+		},
+		Redirects: RedirectConfig{
+			URL: "/",
+		},
+		Header: HeaderConfig{
+			Quality: 85,
+		},
+		Gallery: GalleryConfig{
+			MinImages: 2,
+		},
+		Images: ImageConfig{
+			PlaceholderWidth: 24,
+		},
+		Video: DefaultVideoConfig,
+		Data: DataConfig{
+			Mode: DataModeFrontMatter,
+		},
+		AltText: AltTextConfig{
+			Cache: DefaultAltTextCache,
+		},
+		Summary: SummaryConfig{
+			Cache: DefaultSummaryCache,
+		},
+		PublishLog: PublishLogConfig{
+			File: DefaultPublishLog,
+		},
+		Language: LanguageConfig{
+			MinConfidence: 0.7,
+		},
+		Proofread: ProofreadConfig{
+			Method: ProofreadDictionary,
+			Cache:  DefaultProofreadCache,
+		},
+		Digest: DigestConfig{
+			Title: "New posts",
+			URL:   "/",
+		},
+		Snippets: SnippetConfig{
+			MaxLength: 300,
+			Hashtags:  true,
+			File:      DefaultSnippetFile,
+			Cache:     DefaultSnippetCache,
+		},
+	}
+}
+
+// LoadConfig reads a TOML configuration file on top of the defaults.
+// An empty path returns the default configuration. An invalid file returns a
+// *ConfigError with the position of every issue.
+func LoadConfig(path string) (*Config, error) {
+	cfg := DefaultConfig()
+	if path == "" {
+		return cfg, nil
+	}
+
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config %s: %w", path, err)
+	}
+
+	// Unknown keys, wrong types and conflicting options fail before anything is converted
+	cfg, issues := validateConfig(path, source)
+	if len(issues) > 0 {
+		return nil, &ConfigError{Issues: issues}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,583 @@
+// This file handles validating configuration files and implements the
+// "config validate" subcommand. Unknown keys (usually typos), values of the
+// wrong type and options that don't work together are reported with the line
+// and column of the key, instead of being ignored or failing in the middle of a conversion.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"logseq-to-hugo-converter/internal/indexfile"
+	"logseq-to-hugo-converter/internal/lang"
+)
+
+// ConfigIssue is a problem of a configuration file.
+type ConfigIssue struct {
+	Path    string // Configuration file
+	Line    int    // Line of the key (1-based, 0 if the key isn't in the file)
+	Col     int    // Column of the key (1-based)
+	Key     string // Key of the option ("toc.mode")
+	Message string
+}
+
+// String formats the issue like a compiler message: "converter.toml:12:1: message".
+func (i ConfigIssue) String() string {
+	if i.Line == 0 {
+		return fmt.Sprintf("%s: %s", i.Path, i.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", i.Path, i.Line, i.Col, i.Message)
+}
+
+// ConfigError is returned by LoadConfig for an invalid configuration file.
+type ConfigError struct {
+	Issues []ConfigIssue
+}
+
+// Error lists all issues, one per line.
+func (e *ConfigError) Error() string {
+	lines := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		lines[i] = issue.String()
+	}
+	return "invalid configuration:\n  " + strings.Join(lines, "\n  ")
+}
+
+// validateConfig decodes a configuration file on top of the defaults and checks it.
+// The issues are sorted by their position in the file.
+func validateConfig(path string, source []byte) (*Config, []ConfigIssue) {
+	cfg := DefaultConfig()
+	keys := newConfigKeyIndex(string(source))
+	issue := func(key string, index int, format string, args ...interface{}) ConfigIssue {
+		line, col := keys.position(key, index)
+		return ConfigIssue{Path: path, Line: line, Col: col, Key: key, Message: fmt.Sprintf(format, args...)}
+	}
+
+	meta, err := toml.Decode(string(source), cfg)
+	if err != nil {
+		return nil, []ConfigIssue{decodeIssue(path, err, keys)}
+	}
+
+	var issues []ConfigIssue
+	known := configKeys(reflect.TypeOf(Config{}), nil)
+	reported := make(map[string]bool)
+	for _, key := range meta.Undecoded() {
+		// Keys below an unknown table are not reported again
+		if reported[key[:len(key)-1].String()] {
+			reported[key.String()] = true
+			continue
+		}
+		reported[key.String()] = true
+		message := fmt.Sprintf("unknown key %q", key.String())
+		if suggestion := closestKey(key, known); suggestion != "" {
+			message += fmt.Sprintf(" (did you mean %q?)", suggestion)
+		}
+		issues = append(issues, issue(key.String(), -1, "%s", message))
+	}
+
+	for _, problem := range checkConfig(cfg) {
+		issues = append(issues, issue(problem.key, problem.index, "%s: %s", displayKey(problem.key, problem.index), problem.message))
+	}
+
+	sortConfigIssues(issues)
+	return cfg, issues
+}
+
+// decodeIssue turns an error of the TOML decoder into an issue.
+// Syntax errors have a position, type errors only the line and key.
+func decodeIssue(path string, err error, keys *configKeyIndex) ConfigIssue {
+	var parseErr toml.ParseError
+	if errors.As(err, &parseErr) {
+		return ConfigIssue{Path: path, Line: parseErr.Position.Line, Col: parseErr.Position.Col, Key: parseErr.LastKey, Message: parseErr.Message}
+	}
+
+	match := typeErrorRegex.FindStringSubmatch(err.Error())
+	if match == nil {
+		return ConfigIssue{Path: path, Message: err.Error()}
+	}
+	key, message := match[2], match[3]
+	if types := incompatibleTypeRegex.FindStringSubmatch(message); types != nil {
+		message = fmt.Sprintf("expected %s, got %s", types[2], types[1])
+	}
+	line, _ := strconv.Atoi(match[1])
+	return ConfigIssue{Path: path, Line: line, Col: keys.column(line), Key: key, Message: key + ": " + message}
+}
+
+// Patterns of the type errors of the TOML decoder:
+// toml: line 3 (last key "toc.min_headings"): incompatible types: TOML value has type string; destination has type integer
+var (
+	typeErrorRegex        = regexp.MustCompile(`^toml: line (\d+) \(last key "([^"]*)"\): (.*)$`)
+	incompatibleTypeRegex = regexp.MustCompile(`^incompatible types: TOML value has type (.+); destination has type (.+)$`)
+)
+
+// sortConfigIssues sorts the issues by line and column, issues without a position last.
+func sortConfigIssues(issues []ConfigIssue) {
+	sort.SliceStable(issues, func(a, b int) bool {
+		x, y := issues[a], issues[b]
+		if (x.Line == 0) != (y.Line == 0) {
+			return y.Line == 0
+		}
+		if x.Line != y.Line {
+			return x.Line < y.Line
+		}
+		return x.Col < y.Col
+	})
+}
+
+// configKeys returns the keys of a configuration struct from its toml tags
+// ("toc", "toc.mode", ...). Maps take any key, so only the map itself is listed.
+func configKeys(t reflect.Type, prefix []string) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Tag.Get("toml")
+		if name == "" || name == "-" {
+			continue
+		}
+		key := append(append([]string(nil), prefix...), name)
+		keys = append(keys, strings.Join(key, "."))
+
+		fieldType := t.Field(i).Type
+		if fieldType.Kind() == reflect.Slice {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Struct {
+			keys = append(keys, configKeys(fieldType, key)...)
+		}
+	}
+	return keys
+}
+
+// closestKey suggests the known key with the same parent that is closest to
+// an unknown key (at most 3 edits away), or "" if none is.
+func closestKey(key toml.Key, known []string) string {
+	parent := key[:len(key)-1].String()
+	best, bestDistance := "", 4
+	for _, candidate := range known {
+		candidateParent, name := "", candidate
+		if i := strings.LastIndex(candidate, "."); i >= 0 {
+			candidateParent, name = candidate[:i], candidate[i+1:]
+		}
+		if candidateParent != parent {
+			continue
+		}
+		if distance := editDistance(key[len(key)-1], name); distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance of two strings.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current := make([]int, len(rb)+1)
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(rb)]
+}
+
+// configProblem is a value of the configuration that doesn't work.
+type configProblem struct {
+	key     string // Key of the option ("toc.mode")
+	index   int    // Index of the table in an array of tables ([[types]]), -1 for others
+	message string
+}
+
+// displayKey formats a key for messages: "types[2].marker" for the second [[types]] table.
+func displayKey(key string, index int) string {
+	if index < 0 {
+		return key
+	}
+	table, rest, _ := strings.Cut(key, ".")
+	return fmt.Sprintf("%s[%d].%s", table, index+1, rest)
+}
+
+// checkConfig checks the values of the options and the options that conflict.
+// The checks match what the conversion would fail on (or silently ignore) later.
+func checkConfig(cfg *Config) []configProblem {
+	var problems []configProblem
+	add := func(key string, format string, args ...interface{}) {
+		problems = append(problems, configProblem{key: key, index: -1, message: fmt.Sprintf(format, args...)})
+	}
+	addAt := func(key string, index int, format string, args ...interface{}) {
+		problems = append(problems, configProblem{key: key, index: index, message: fmt.Sprintf(format, args...)})
+	}
+	oneOf := func(key, value string, allowed ...string) {
+		for _, option := range allowed {
+			if value == option {
+				return
+			}
+		}
+		var quoted []string
+		for _, option := range allowed {
+			if option != "" {
+				quoted = append(quoted, strconv.Quote(option))
+			}
+		}
+		last := len(quoted) - 1
+		add(key, "unknown value %q (use %s or %s)", value, strings.Join(quoted[:last], ", "), quoted[last])
+	}
+
+	if _, err := NewBlogMarker(cfg.Marker); err != nil {
+		add("marker", "%v", err)
+	}
+
+	oneOf("toc.mode", cfg.TOC.Mode, "front_matter", "shortcode")
+	if cfg.TOC.Mode == "shortcode" && cfg.TOC.Shortcode == "" {
+		add("toc.shortcode", "a shortcode is needed for toc.mode = \"shortcode\"")
+	}
+	if cfg.TOC.MinHeadings < 0 {
+		add("toc.min_headings", "must not be negative")
+	}
+
+	oneOf("video.mode", cfg.Video.Mode, VideoModeShortcode, VideoModeHTML)
+	if cfg.Video.Mode == VideoModeShortcode && cfg.Video.Shortcode == "" {
+		add("video.shortcode", "a shortcode is needed for video.mode = \"shortcode\"")
+	}
+	if cfg.Video.Mode == VideoModeShortcode && !videoAttributeRegex.MatchString(cfg.Video.Source) {
+		add("video.source", "%q is not an attribute name", cfg.Video.Source)
+	}
+	oneOf("video.transcode.codec", cfg.Video.Transcode.Codec, VideoCodecH264, VideoCodecVP9)
+	if cfg.Video.Transcode.Enabled && !videoBitrateRegex.MatchString(cfg.Video.Transcode.Bitrate) {
+		add("video.transcode.bitrate", "invalid bitrate %q (use a number with an optional k or M like \"2M\")", cfg.Video.Transcode.Bitrate)
+	}
+	if cfg.Video.Transcode.MaxHeight < 0 {
+		add("video.transcode.max_height", "must not be negative")
+	}
+	attributes := make([]string, 0, len(cfg.Video.Attributes))
+	for name := range cfg.Video.Attributes {
+		attributes = append(attributes, name)
+	}
+	sort.Strings(attributes)
+	for _, name := range attributes {
+		if !videoAttributeRegex.MatchString(name) {
+			add("video.attributes", "%q is not an attribute name", name)
+		}
+	}
+
+	if cfg.Reading.Enabled && cfg.Reading.WordsPerMinute <= 0 {
+		add("reading.words_per_minute", "must be positive to compute the reading time")
+	}
+	if cfg.Related.Enabled && cfg.Related.Max <= 0 {
+		add("related.max", "must be positive for related posts")
+	}
+
+	oneOf("output.slug_policy", cfg.Output.SlugPolicy, SlugPolicyUnicode, SlugPolicyASCII, SlugPolicyPercent)
+	oneOf("output.order", cfg.Output.Order, OrderDate, OrderTitle, OrderSource)
+	if tmpl, err := indexfile.Parse(outputFilename(cfg.Output)); err != nil {
+		add("output.filename", "%v", err)
+	} else if cfg.Output.Monolingual && tmpl.UsesLanguage() {
+		add("output.filename", "a monolingual site has no language in the file names")
+	}
+	if _, err := parseFileMode(cfg.Output.FileMode); err != nil {
+		add("output.file_mode", "%v", err)
+	}
+	if _, err := parseFileMode(cfg.Output.DirMode); err != nil {
+		add("output.dir_mode", "%v", err)
+	}
+	if _, err := parseFileOwner(cfg.Output.Owner); err != nil {
+		add("output.owner", "%v", err)
+	}
+	if cfg.Output.MaxBundleSize != "" {
+		if _, err := parseByteSize(cfg.Output.MaxBundleSize); err != nil {
+			add("output.max_bundle_size", "%v", err)
+		}
+	}
+	oneOf("output.bundle_size_action", cfg.Output.BundleSizeAction, BundleSizeWarn, BundleSizeFail)
+	if cfg.Remote.Target != "" {
+		if _, err := parseRemoteTarget(cfg.Remote.Target); err != nil {
+			add("remote.target", "%v", err)
+		}
+	}
+	oneOf("categories.from_ancestors", cfg.Categories.FromAncestors, "", AncestorsCategories, AncestorsTags)
+	if err := validateSections(cfg.Sections.Mapping); err != nil {
+		add("sections.mapping", "%v", err)
+	}
+	if _, err := NewDateFormatter(cfg.Dates); err != nil {
+		add("dates", "%v", err)
+	}
+
+	if cfg.Header.RemoveFirstImage && !cfg.Header.FirstImage {
+		add("header.remove_first_image", "only works with header.first_image = true")
+	}
+	for key, size := range map[string]string{"header.featured_size": cfg.Header.FeaturedSize, "header.og_image_size": cfg.Header.OpenGraphSize} {
+		if size != "" {
+			if _, _, err := parseImageSize(size); err != nil {
+				add(key, "%v", err)
+			}
+		}
+	}
+	if cfg.Header.Quality < 1 || cfg.Header.Quality > 100 {
+		add("header.jpeg_quality", "must be between 1 and 100")
+	}
+
+	if cfg.Images.Placeholders && cfg.Images.LazyShortcode == "" {
+		add("images.placeholders", "only works with images.lazy_shortcode")
+	}
+	if cfg.Images.Placeholders && (cfg.Images.PlaceholderWidth < 1 || cfg.Images.PlaceholderWidth > 200) {
+		add("images.placeholder_width", "must be between 1 and 200")
+	}
+	if cfg.IndieWeb.Enabled && !isHTTPAddress(cfg.IndieWeb.URL) {
+		add("indieweb.url", "%q is not an http(s) address (the canonical addresses need the address of the site)", cfg.IndieWeb.URL)
+	}
+	for _, target := range cfg.IndieWeb.SyndicateTo {
+		if !isHTTPAddress(target) {
+			add("indieweb.syndicate_to", "%q is not an http(s) address", target)
+		}
+	}
+	if cfg.Gallery.Shortcode != "" && cfg.Gallery.MinImages < 2 {
+		add("gallery.min_images", "a gallery needs at least 2 images")
+	}
+	oneOf("captions.mode", cfg.Captions.Mode, "", CaptionModeNested, CaptionModeSibling)
+	oneOf("data.mode", cfg.Data.Mode, DataModeFrontMatter, DataModeTable)
+
+	for _, name := range cfg.Filters {
+		if _, ok := contentFilters[name]; !ok {
+			add("filters", "unknown content filter %q (known filters: %s)", name, strings.Join(filterNames(), ", "))
+		}
+	}
+
+	markers := map[string]bool{strings.ToLower(cfg.Marker): true}
+	names := make(map[string]bool)
+	for i, typeConfig := range cfg.Types {
+		if typeConfig.Name == "" || names[typeConfig.Name] {
+			addAt("types.name", i, "missing or duplicate name %q", typeConfig.Name)
+		}
+		names[typeConfig.Name] = true
+		if _, err := NewBlogMarker(typeConfig.Marker); err != nil {
+			addAt("types.marker", i, "%v", err)
+		} else if markers[strings.ToLower(typeConfig.Marker)] {
+			addAt("types.marker", i, "marker %q is already used", typeConfig.Marker)
+		}
+		markers[strings.ToLower(typeConfig.Marker)] = true
+		if typeConfig.Filename != "" {
+			if tmpl, err := indexfile.Parse(typeConfig.Filename); err != nil {
+				addAt("types.filename", i, "%v", err)
+			} else if cfg.Output.Monolingual && tmpl.UsesLanguage() {
+				addAt("types.filename", i, "a monolingual site has no language in the file names")
+			}
+		}
+	}
+
+	for i, rule := range cfg.Rules {
+		if _, err := compileRules([]RuleConfig{rule}); err != nil {
+			addAt("rules.when", i, "%v", strings.TrimPrefix(err.Error(), "rule 1: "))
+		}
+	}
+
+	for i, hook := range cfg.Hooks {
+		if len(hook.Command) == 0 {
+			addAt("hooks.command", i, "a command is needed")
+		}
+		if hook.Stage != "" && hook.Stage != HookStagePre && hook.Stage != HookStagePost {
+			addAt("hooks.stage", i, "unknown stage %q (use %q or %q)", hook.Stage, HookStagePre, HookStagePost)
+		}
+		if hook.OnFailure != "" && hook.OnFailure != HookFailureWarn && hook.OnFailure != HookFailureFail && hook.OnFailure != HookFailureIgnore {
+			addAt("hooks.on_failure", i, "unknown policy %q (use %q, %q or %q)", hook.OnFailure, HookFailureWarn, HookFailureFail, HookFailureIgnore)
+		}
+	}
+
+	oneOf("proofread.method", cfg.Proofread.Method, ProofreadDictionary, ProofreadLLM)
+	if cfg.Proofread.Enabled && cfg.Proofread.Method == ProofreadDictionary && len(cfg.Proofread.Dictionaries) == 0 {
+		add("proofread.dictionaries", "the dictionary method needs a word list per language")
+	}
+
+	if cfg.Snippets.MaxLength < minSnippetLength {
+		add("snippets.max_length", "must be at least %d characters", minSnippetLength)
+	}
+	if cfg.Snippets.Enabled && cfg.Snippets.Queue == "" && (cfg.Snippets.File == "" || strings.ContainsAny(cfg.Snippets.File, `/\`) || cfg.Snippets.File == "..") {
+		add("snippets.file", "%q is not a file name in the bundle", cfg.Snippets.File)
+	}
+	if cfg.Snippets.URL != "" {
+		if !isHTTPAddress(cfg.Snippets.URL) {
+			add("snippets.url", "%q is not an http(s) address", cfg.Snippets.URL)
+		}
+	}
+
+	if cfg.PublishLog.Enabled && (cfg.PublishLog.File == "" || strings.ContainsAny(cfg.PublishLog.File, `/\`) || cfg.PublishLog.File == "..") {
+		add("publish_log.file", "%q is not a file name in the bundle", cfg.PublishLog.File)
+	}
+
+	if cfg.Language.MinConfidence < 0 || cfg.Language.MinConfidence > 1 {
+		add("language.min_confidence", "must be between 0 and 1")
+	}
+	for _, key := range cfg.Language.Keys {
+		if _, ok := lang.Parse(key); !ok {
+			add("language.keys", "unknown language %q", key)
+		}
+	}
+
+	if cfg.SyncBack.URL != "" {
+		if !isHTTPAddress(cfg.SyncBack.URL) {
+			add("sync_back.url", "%q is not an http(s) address", cfg.SyncBack.URL)
+		}
+	}
+	keys := make([]string, 0, len(cfg.SyncBack.Properties))
+	for key := range cfg.SyncBack.Properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := cfg.SyncBack.Properties[key]
+		switch {
+		case !blockPropertyRegex.MatchString(key + ":: " + value):
+			add("sync_back.properties", "%q is not a property name", key)
+		case strings.ContainsAny(value, "\r\n"):
+			add("sync_back.properties", "the value of %q must be a single line", key)
+		}
+	}
+
+	return problems
+}
+
+// configKeyIndex knows where the keys of a TOML file are.
+// It reads the file line by line, which is enough for configuration files:
+// table headers, arrays of tables and "key = value" lines.
+type configKeyIndex struct {
+	entries []configKeyEntry
+}
+
+// configKeyEntry is a key or table header of the file.
+type configKeyEntry struct {
+	key   string // Full key ("toc.mode", "types" for a [[types]] header)
+	index int    // Index of the [[table]] the key is in, -1 outside of arrays of tables
+	line  int
+	col   int
+}
+
+// configHeaderRegex matches table headers ([toc], [[types]]), configKeyRegex "key = value" lines.
+var (
+	configHeaderRegex = regexp.MustCompile(`^(\s*)(\[\[?)\s*([^\]]+?)\s*\]\]?`)
+	configKeyRegex    = regexp.MustCompile(`^(\s*)((?:[A-Za-z0-9_-]+|"[^"]*"|'[^']*')(?:\s*\.\s*(?:[A-Za-z0-9_-]+|"[^"]*"|'[^']*'))*)\s*=`)
+)
+
+// newConfigKeyIndex indexes the keys of a TOML file.
+func newConfigKeyIndex(source string) *configKeyIndex {
+	index := &configKeyIndex{}
+	table, tableIndex := "", -1
+	arrays := make(map[string]int)
+	inString := false
+	for i, line := range strings.Split(source, "\n") {
+		// Lines of multi-line strings are no keys
+		if strings.Count(line, `"""`)%2 == 1 || strings.Count(line, `'''`)%2 == 1 {
+			wasInString := inString
+			inString = !inString
+			if wasInString {
+				continue
+			}
+		} else if inString {
+			continue
+		}
+
+		if match := configHeaderRegex.FindStringSubmatch(line); match != nil {
+			table, tableIndex = normalizeConfigKey(match[3]), -1
+			if match[2] == "[[" {
+				tableIndex = arrays[table]
+				arrays[table]++
+			} else if top, _, _ := strings.Cut(table, "."); arrays[top] > 0 {
+				tableIndex = arrays[top] - 1 // Sub-table of the last [[types]] table
+			}
+			index.entries = append(index.entries, configKeyEntry{key: table, index: tableIndex, line: i + 1, col: len(match[1]) + 1})
+			continue
+		}
+		if match := configKeyRegex.FindStringSubmatch(line); match != nil {
+			key := normalizeConfigKey(match[2])
+			if table != "" {
+				key = table + "." + key
+			}
+			index.entries = append(index.entries, configKeyEntry{key: key, index: tableIndex, line: i + 1, col: len(match[1]) + 1})
+		}
+	}
+	return index
+}
+
+// normalizeConfigKey removes the quotes and spaces of a dotted key (a . "b c" -> a.b c),
+// the way toml.Key.String() writes keys without special characters.
+func normalizeConfigKey(key string) string {
+	var parts []string
+	for _, part := range strings.Split(key, ".") {
+		parts = append(parts, strings.Trim(strings.TrimSpace(part), `"'`))
+	}
+	return strings.Join(parts, ".")
+}
+
+// position returns the line and column of a key (and of its index for arrays of tables).
+// Keys that aren't in the file return the position of their table, or 0, 0.
+func (x *configKeyIndex) position(key string, index int) (int, int) {
+	for key != "" {
+		for _, entry := range x.entries {
+			if entry.key == key && (index < 0 || entry.index == index) {
+				return entry.line, entry.col
+			}
+		}
+		if i := strings.LastIndex(key, "."); i >= 0 {
+			key = key[:i]
+		} else {
+			key = ""
+		}
+	}
+	return 0, 0
+}
+
+// column returns the column of the key in a line.
+func (x *configKeyIndex) column(line int) int {
+	for _, entry := range x.entries {
+		if entry.line == line {
+			return entry.col
+		}
+	}
+	return 1
+}
+
+// runConfig runs the config subcommand and returns the process exit code.
+// Usage: go run . config validate [converter.toml]
+func runConfig(args []string) int {
+	if len(args) == 0 || args[0] != "validate" {
+		fmt.Println("Usage: go run . config validate [converter.toml]")
+		return 2
+	}
+
+	flags := flag.NewFlagSet("config validate", flag.ContinueOnError)
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+	path := "converter.toml"
+	if flags.NArg() > 0 {
+		path = flags.Arg(0)
+	}
+
+	if _, err := LoadConfig(path); err != nil {
+		var configErr *ConfigError
+		if errors.As(err, &configErr) {
+			for _, issue := range configErr.Issues {
+				fmt.Println(issue)
+			}
+			return 1
+		}
+		fmt.Printf("Error: %v\n", err)
+		return 2
+	}
+	fmt.Printf("%s is valid\n", path)
+	return 0
+}
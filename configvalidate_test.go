@@ -0,0 +1,210 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestValidateConfig tests the issues found in configuration files and their positions
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []string
+	}{
+		{
+			name:   "valid",
+			source: "marker = \"#blog\"\n\n[toc]\nmode = \"shortcode\"\n\n[[types]]\nname = \"recipe\"\nmarker = \"type:: recipe\"\n",
+			want:   nil,
+		},
+		{
+			name:   "syntax error",
+			source: "[toc]\nmode = \"shortcode\n",
+			want:   []string{"c.toml:2:18: strings cannot contain newlines"},
+		},
+		{
+			name:   "wrong type",
+			source: "[toc]\n  min_headings = \"three\"\n",
+			want:   []string{"c.toml:2:3: toc.min_headings: expected integer, got string"},
+		},
+		{
+			name:   "unknown keys",
+			source: "[toc]\nmod = \"shortcode\"\n\n[unknown]\na = 1\nb = 2\n",
+			want: []string{
+				`c.toml:2:1: unknown key "toc.mod" (did you mean "toc.mode"?)`,
+				`c.toml:4:1: unknown key "unknown"`,
+			},
+		},
+		{
+			name:   "unknown value",
+			source: "[output]\norder = \"random\"\n",
+			want:   []string{`c.toml:2:1: output.order: unknown value "random" (use "date", "title" or "source")`},
+		},
+		{
+			name:   "permissions",
+			source: "[output]\nfile_mode = \"640\"\ndir_mode = \"rwx\"\nowner = \"www-data\"\n",
+			want: []string{
+				`c.toml:3:1: output.dir_mode: invalid permissions "rwx" (use octal like "0640")`,
+				`c.toml:4:1: output.owner: invalid owner "www-data" (use numeric ids like "1000:33")`,
+			},
+		},
+		{
+			name:   "video",
+			source: "[video]\nmode = \"shortcode\"\nshortcode = \"\"\nsource = \"file name\"\n\n[video.attributes]\n\"data loop\" = \"true\"\n",
+			want: []string{
+				`c.toml:3:1: video.shortcode: a shortcode is needed for video.mode = "shortcode"`,
+				`c.toml:4:1: video.source: "file name" is not an attribute name`,
+				`c.toml:6:1: video.attributes: "data loop" is not an attribute name`,
+			},
+		},
+		{
+			name:   "image placeholders",
+			source: "[images]\nplaceholders = true\nplaceholder_width = 0\n",
+			want: []string{
+				`c.toml:2:1: images.placeholders: only works with images.lazy_shortcode`,
+				`c.toml:3:1: images.placeholder_width: must be between 1 and 200`,
+			},
+		},
+		{
+			name:   "video transcoding",
+			source: "[video.transcode]\nenabled = true\ncodec = \"av1\"\nbitrate = \"fast\"\n",
+			want: []string{
+				`c.toml:3:1: video.transcode.codec: unknown value "av1" (use "h264" or "vp9")`,
+				`c.toml:4:1: video.transcode.bitrate: invalid bitrate "fast" (use a number with an optional k or M like "2M")`,
+			},
+		},
+		{
+			name:   "bundle size",
+			source: "[output]\nmax_bundle_size = \"25 MiB\"\nbundle_size_action = \"stop\"\n",
+			want: []string{
+				`c.toml:2:1: output.max_bundle_size: invalid size "25 MiB" (use a number with B, kB, MB or GB like "25MB")`,
+				`c.toml:3:1: output.bundle_size_action: unknown value "stop" (use "warn" or "fail")`,
+			},
+		},
+		{
+			name:   "remote target",
+			source: "[remote]\ntarget = \"ftp://example.com/blog\"\n",
+			want:   []string{`c.toml:2:1: remote.target: unknown target "ftp://example.com/blog" (use s3://bucket/prefix or sftp://user@host/dir)`},
+		},
+		{
+			name:   "filename templates",
+			source: "[output]\nfilename = \"{{.Lang}}/index.md\"\n\n[[types]]\nname = \"recipe\"\nmarker = \"type:: recipe\"\nfilename = \"index.{{.Locale}}.md\"\n",
+			want: []string{
+				`c.toml:2:1: output.filename: file name "de/index.md" must not contain a directory`,
+				`c.toml:7:1: types[1].filename: template: filename:1:8: executing "filename" at <.Locale>`,
+			},
+		},
+		{
+			name:   "monolingual file names",
+			source: "[output]\nmonolingual = true\nfilename = \"{{.Slug}}.{{.Lang}}.md\"\n",
+			want:   []string{"c.toml:3:1: output.filename: a monolingual site has no language in the file names"},
+		},
+		{
+			name:   "out of range",
+			source: "[language]\ndetect = true\nmin_confidence = 70\n",
+			want:   []string{"c.toml:3:1: language.min_confidence: must be between 0 and 1"},
+		},
+		{
+			name:   "conflicting options",
+			source: "[header]\nremove_first_image = true\n",
+			want:   []string{"c.toml:2:1: header.remove_first_image: only works with header.first_image = true"},
+		},
+		{
+			name:   "default value in the way",
+			source: "[proofread]\nenabled = true\n",
+			want:   []string{"c.toml:1:1: proofread.dictionaries: the dictionary method needs a word list per language"},
+		},
+		{
+			name:   "sync back",
+			source: "[sync_back]\nurl = \"example.com/posts\"\n\n[sync_back.properties]\n\"published url\" = \"true\"\n",
+			want: []string{
+				`c.toml:2:1: sync_back.url: "example.com/posts" is not an http(s) address`,
+				`c.toml:4:1: sync_back.properties: "published url" is not a property name`,
+			},
+		},
+		{
+			name:   "indieweb",
+			source: "[indieweb]\nenabled = true\nsyndicate_to = [\"mastodon\"]\n",
+			want: []string{
+				`c.toml:1:1: indieweb.url: "" is not an http(s) address`,
+				`c.toml:3:1: indieweb.syndicate_to: "mastodon" is not an http(s) address`,
+			},
+		},
+		{
+			name:   "social media snippets",
+			source: "[snippets]\nenabled = true\nmax_length = 20\nfile = \"social/de.txt\"\n",
+			want: []string{
+				"c.toml:3:1: snippets.max_length: must be at least 50 characters",
+				`c.toml:4:1: snippets.file: "social/de.txt" is not a file name in the bundle`,
+			},
+		},
+		{
+			name:   "arrays of tables",
+			source: "[[types]]\nname = \"recipe\"\nmarker = \"type:: recipe\"\n\n[[types]]\nname = \"recipe\"\nmarker = \"type:: blog\"\n",
+			want: []string{
+				`c.toml:6:1: types[2].name: missing or duplicate name "recipe"`,
+				`c.toml:7:1: types[2].marker: marker "type:: blog" is already used`,
+			},
+		},
+		{
+			name:   "multi-line strings are no keys",
+			source: "[[rules]]\nwhen = '''\nmode = x'''\n",
+			want:   []string{`c.toml:2:1: rules[1].when: invalid condition "mode = x": expected field operator "value"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, issues := validateConfig("c.toml", []byte(tt.source))
+			if len(issues) != len(tt.want) {
+				t.Fatalf("validateConfig() found %d issues, want %d: %v", len(issues), len(tt.want), issues)
+			}
+			for i, issue := range issues {
+				if !strings.HasPrefix(issue.String(), tt.want[i]) {
+					t.Errorf("issue %d = %q, want %q", i, issue.String(), tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// TestLoadConfig_Invalid tests that invalid configuration files aren't loaded
+func TestLoadConfig_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "converter.toml")
+	if err := os.WriteFile(path, []byte("[related]\nenable = true\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, err := LoadConfig(path)
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) {
+		t.Fatalf("LoadConfig() error = %v, want a *ConfigError", err)
+	}
+	if want := `converter.toml:2:1: unknown key "related.enable" (did you mean "related.enabled"?)`; !strings.HasSuffix(configErr.Issues[0].String(), want) {
+		t.Errorf("issue = %q, want suffix %q", configErr.Issues[0].String(), want)
+	}
+
+	if code := runConfig([]string{"validate", path}); code != 1 {
+		t.Errorf("runConfig() with an invalid file = %d, want 1", code)
+	}
+	if err := os.WriteFile(path, []byte("[related]\nenabled = true\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if code := runConfig([]string{"validate", path}); code != 0 {
+		t.Errorf("runConfig() with a valid file = %d, want 0", code)
+	}
+}
+
+// TestConfigKeys tests that the known keys cover nested tables and arrays of tables
+func TestConfigKeys(t *testing.T) {
+	keys := strings.Join(configKeys(reflect.TypeOf(Config{}), nil), " ")
+	for _, want := range []string{"marker", "toc.mode", "types.marker", "rules.replace.pattern", "authors.registry"} {
+		if !strings.Contains(" "+keys+" ", " "+want+" ") {
+			t.Errorf("configKeys() is missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,387 @@
+// This file handles transformations of the content blocks before they are written.
+// Each transformation works on the markdown text of a single block.
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// fencedCodeRegex matches a fenced code block including its language.
+//
+//	(?ms)       = multi-line mode (^ and $ match at line breaks), . matches newlines
+//	^```(\S*)   = opening fence with an optional language
+//	[^\n]*\n    = rest of the info string
+//	(.*?)       = the code itself (non-greedy)
+//	^```[ \t]*$ = closing fence on its own line
+var fencedCodeRegex = regexp.MustCompile("(?ms)^```(\\S*)[^\\n]*\\n(.*?)^```[ \\t]*$")
+
+// codeRegex matches the parts of markdown content that are shown as they are:
+// fenced code blocks (also indented in lists, with ``` or ~~~) and inline code spans.
+var codeRegex = regexp.MustCompile("(?ms)^[ \\t]*```.*?^[ \\t]*```[ \\t]*$|^[ \\t]*~~~.*?^[ \\t]*~~~[ \\t]*$|``[^\\n]+?``|`[^`\\n]+`")
+
+// replaceOutsideCode replaces the text between the code blocks and code spans
+// of the content, so markdown syntax in code examples is kept as it is.
+func replaceOutsideCode(content string, replace func(string) string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range codeRegex.FindAllStringIndex(content, -1) {
+		b.WriteString(replace(content[last:loc[0]]))
+		b.WriteString(content[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(replace(content[last:]))
+	return b.String()
+}
+
+// indexOutsideCode returns the index of the first part outside of code blocks
+// and code spans, or -1 if there is none.
+func indexOutsideCode(content, part string) int {
+	last := 0
+	for _, loc := range codeRegex.FindAllStringIndex(content, -1) {
+		if i := strings.Index(content[last:loc[0]], part); i >= 0 {
+			return last + i
+		}
+		last = loc[1]
+	}
+	if i := strings.Index(content[last:], part); i >= 0 {
+		return last + i
+	}
+	return -1
+}
+
+// convertCodeShortcodes replaces fenced code blocks whose language is mapped
+// to a Hugo shortcode. For example with {"mermaid": "mermaid"}:
+//
+//	```mermaid            {{< mermaid >}}
+//	graph TD       ->     graph TD
+//	```                   {{< /mermaid >}}
+func convertCodeShortcodes(block string, shortcodes map[string]string) string {
+	if len(shortcodes) == 0 || !strings.Contains(block, "```") {
+		return block
+	}
+
+	return fencedCodeRegex.ReplaceAllStringFunc(block, func(match string) string {
+		parts := fencedCodeRegex.FindStringSubmatch(match)
+		name, ok := shortcodes[strings.ToLower(parts[1])]
+		if !ok || name == "" {
+			return match
+		}
+		code := strings.TrimRight(parts[2], "\n")
+		return fmt.Sprintf("{{< %s >}}\n%s\n{{< /%s >}}", name, code, name)
+	})
+}
+
+// orgCalloutRegex matches Logseq's org-mode style callouts like
+// #+BEGIN_NOTE ... #+END_NOTE (also TIP, WARNING, IMPORTANT, CAUTION, PINNED, QUOTE).
+var orgCalloutRegex = regexp.MustCompile(`(?ims)^#\+BEGIN_([a-z]+)[ \t]*\n(.*?)\n?^#\+END_([a-z]+)[ \t]*$`)
+
+// quoteCalloutRegex matches GitHub/Obsidian style callouts:
+//
+//	> [!NOTE]
+//	> The text of the note
+var quoteCalloutRegex = regexp.MustCompile(`(?m)^>[ \t]*\[!([A-Za-z]+)\][^\n]*\n((?:>[^\n]*(?:\n|$))*)`)
+
+// calloutKinds lists the callout kinds Logseq supports.
+// Other #+BEGIN_ blocks (e.g. SRC or QUERY) are left untouched.
+var calloutKinds = map[string]bool{
+	"note": true, "tip": true, "important": true, "caution": true,
+	"warning": true, "pinned": true, "quote": true,
+}
+
+// convertCallouts converts Logseq callouts to admonitions.
+// With a configured shortcode the callout becomes {{< shortcode type="note" >}}...{{< /shortcode >}},
+// otherwise it becomes a plain blockquote with a bold label so the markup never leaks.
+// #+BEGIN_QUOTE blocks always become plain blockquotes.
+func convertCallouts(block string, config AdmonitionConfig) string {
+	if strings.Contains(strings.ToUpper(block), "#+BEGIN_") {
+		block = orgCalloutRegex.ReplaceAllStringFunc(block, func(match string) string {
+			parts := orgCalloutRegex.FindStringSubmatch(match)
+			kind := strings.ToLower(parts[1])
+			if !strings.EqualFold(parts[1], parts[3]) || !calloutKinds[kind] {
+				return match
+			}
+			return renderAdmonition(kind, parts[2], config)
+		})
+	}
+
+	if strings.Contains(block, "[!") {
+		block = quoteCalloutRegex.ReplaceAllStringFunc(block, func(match string) string {
+			parts := quoteCalloutRegex.FindStringSubmatch(match)
+			var lines []string
+			for _, line := range strings.Split(strings.TrimRight(parts[2], "\n"), "\n") {
+				line = strings.TrimPrefix(line, ">")
+				lines = append(lines, strings.TrimPrefix(line, " "))
+			}
+			suffix := ""
+			if strings.HasSuffix(parts[2], "\n") {
+				suffix = "\n"
+			}
+			return renderAdmonition(strings.ToLower(parts[1]), strings.Join(lines, "\n"), config) + suffix
+		})
+	}
+
+	return block
+}
+
+// renderAdmonition renders the body of a callout of the given kind (e.g. "note").
+func renderAdmonition(kind, body string, config AdmonitionConfig) string {
+	body = strings.TrimSpace(body)
+
+	if kind == "quote" || config.Shortcode == "" {
+		var builder strings.Builder
+		if kind != "quote" {
+			// Capitalize the label: "note" -> "**Note**"
+			builder.WriteString("> **" + strings.ToUpper(kind[:1]) + kind[1:] + "**\n>\n")
+		}
+		for i, line := range strings.Split(body, "\n") {
+			if i > 0 {
+				builder.WriteString("\n")
+			}
+			if strings.TrimSpace(line) == "" {
+				builder.WriteString(">")
+			} else {
+				builder.WriteString("> " + line)
+			}
+		}
+		return builder.String()
+	}
+
+	attribute := ""
+	if config.TypeAttribute != "" {
+		attribute = fmt.Sprintf(` %s="%s"`, config.TypeAttribute, kind)
+	}
+	return fmt.Sprintf("{{< %s%s >}}\n%s\n{{< /%s >}}", config.Shortcode, attribute, body, config.Shortcode)
+}
+
+// highlightRegex matches Logseq highlights: ^^highlighted text^^
+var highlightRegex = regexp.MustCompile(`\^\^(.+?)\^\^`)
+
+// macroRegex matches Logseq macros like {{cloze text}} or {{renderer ...}}.
+// Hugo shortcodes ({{< >}} and {{% %}}) never match because the macro name must start with a letter.
+var macroRegex = regexp.MustCompile(`\{\{\s*([A-Za-z][\w-]*)([^{}]*)\}\}`)
+
+// keptMacros are the Logseq macros that embed content (videos, other pages,
+// plugin renderers). They are kept, so they show up in the report instead of disappearing silently.
+var keptMacros = []string{"video", "youtube", "embed", "renderer"}
+
+// cleanupMarkup converts or removes Logseq-specific inline markup outside of
+// code blocks and code spans, so code examples (e.g. Hugo templates) are kept:
+//   - ^^highlight^^ becomes <mark>highlight</mark>
+//   - {{cloze text}} keeps only the text
+//   - the kept macros stay, all other macros are removed
+func cleanupMarkup(block string, config CleanupConfig) string {
+	return replaceOutsideCode(block, func(text string) string {
+		if config.Highlights && strings.Contains(text, "^^") {
+			text = highlightRegex.ReplaceAllString(text, "<mark>$1</mark>")
+		}
+
+		if config.StripMacros && strings.Contains(text, "{{") {
+			text = macroRegex.ReplaceAllStringFunc(text, func(match string) string {
+				parts := macroRegex.FindStringSubmatch(match)
+				name := strings.ToLower(parts[1])
+				switch {
+				case name == "cloze":
+					return strings.TrimSpace(parts[2])
+				case slices.Contains(keptMacros, name):
+					return match
+				}
+				return ""
+			})
+		}
+		return text
+	})
+}
+
+// markdownImageRegex matches a markdown image with optional Logseq size attributes:
+// ![alt](path){:height 446, :width 778}
+var markdownImageRegex = regexp.MustCompile(`!\[[^\]]*\]\(([^)]*)\)(?:\{[^}]*\})?`)
+
+// convertGalleries collapses runs of consecutive images (with nothing but whitespace
+// between them) into the configured gallery shortcode. Videos and GPX tracks never
+// belong to a gallery, images in code blocks and code spans are left as they are.
+func convertGalleries(block string, config GalleryConfig) string {
+	if config.Shortcode == "" || !strings.Contains(block, "![") {
+		return block
+	}
+	return replaceOutsideCode(block, func(text string) string { return galleryRuns(text, config) })
+}
+
+// galleryRuns collapses the runs of consecutive images of text without code into the gallery shortcode.
+func galleryRuns(block string, config GalleryConfig) string {
+	minImages := max(config.MinImages, 2)
+
+	matches := markdownImageRegex.FindAllStringSubmatchIndex(block, -1)
+	isVideo := func(i int) bool {
+		path := block[matches[i][2]:matches[i][3]]
+		return isVideoFile(path) || isTrackFile(path)
+	}
+	adjacent := func(i int) bool { return strings.TrimSpace(block[matches[i][1]:matches[i+1][0]]) == "" }
+
+	var builder strings.Builder
+	last := 0 // End of the text already written
+	for start := 0; start < len(matches); {
+		if isVideo(start) {
+			start++
+			continue
+		}
+
+		// Extend the run as long as the next image follows after whitespace only
+		end := start
+		for end+1 < len(matches) && !isVideo(end+1) && adjacent(end) {
+			end++
+		}
+
+		if end-start+1 >= minImages {
+			builder.WriteString(block[last:matches[start][0]])
+			builder.WriteString("{{< " + config.Shortcode + " >}}\n")
+			for _, match := range matches[start : end+1] {
+				builder.WriteString(block[match[0]:match[1]] + "\n")
+			}
+			builder.WriteString("{{< /" + config.Shortcode + " >}}")
+			last = matches[end][1]
+		}
+		start = end + 1
+	}
+	builder.WriteString(block[last:])
+	return builder.String()
+}
+
+// Caption modes of CaptionConfig.Mode.
+const (
+	CaptionModeNested  = "nested"
+	CaptionModeSibling = "sibling"
+)
+
+// captionedImageRegex matches a block with a single image and its caption
+// as the only nested bullet below it:
+//
+//	![](../assets/beach.jpg)
+//	* Sunset at the beach
+var captionedImageRegex = regexp.MustCompile(`^(` + markdownImageRegex.String() + `)\n\* ([^\n]+)$`)
+
+// mergeCaptions attaches captions to images according to the caption mode.
+// In "nested" mode the caption is the only nested bullet below an image, in
+// "sibling" mode it is the single-line block following a block with just an image.
+// The image block and its caption are merged into one block:
+//
+//	![Sunset at the beach](../assets/beach.jpg)
+//	*Sunset at the beach*
+func mergeCaptions(blocks []ContentBlock, config CaptionConfig) []ContentBlock {
+	if config.Mode != CaptionModeNested && config.Mode != CaptionModeSibling {
+		return blocks
+	}
+
+	merged := make([]ContentBlock, 0, len(blocks))
+	for i := 0; i < len(blocks); i++ {
+		block := blocks[i]
+		text := strings.TrimSpace(block.Text)
+
+		switch config.Mode {
+		case CaptionModeNested:
+			if parts := captionedImageRegex.FindStringSubmatch(text); parts != nil && isSingleImage(parts[1]) && isCaption(parts[3]) {
+				block.Text = renderCaption(parts[1], parts[3])
+			}
+		case CaptionModeSibling:
+			if i+1 < len(blocks) && isSingleImage(text) {
+				if caption := strings.TrimSpace(blocks[i+1].Text); isCaption(caption) {
+					block.Text = renderCaption(text, caption)
+					i++ // The caption block is part of the image block now
+				}
+			}
+		}
+		merged = append(merged, block)
+	}
+	return merged
+}
+
+// isSingleImage reports whether text is exactly one image (videos get no caption).
+func isSingleImage(text string) bool {
+	match := markdownImageRegex.FindStringSubmatchIndex(text)
+	return match != nil && match[0] == 0 && match[1] == len(text) && !isVideoFile(text[match[2]:match[3]])
+}
+
+// isCaption reports whether text can be a caption: a single line of plain text
+// that is no heading, list, quote, table, code, image or macro.
+func isCaption(text string) bool {
+	if text == "" || strings.Contains(text, "\n") || strings.Contains(text, "![") || strings.Contains(text, "{{") {
+		return false
+	}
+	for _, prefix := range []string{"#", ">", "|", "* ", "- ", "```"} {
+		if strings.HasPrefix(text, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// renderCaption writes the caption in italics below the image. The caption also
+// becomes the alt text if the image has none or just its file name.
+func renderCaption(image, caption string) string {
+	end := strings.Index(image, "](")
+	if alt := image[2:end]; (alt == "" || filepath.Ext(alt) != "") && !strings.ContainsAny(caption, "[]") {
+		image = "![" + caption + image[end:]
+	}
+	return image + "\n*" + caption + "*"
+}
+
+// logseqMarkupPatterns describes Logseq-specific markup that has no meaning in Hugo.
+var logseqMarkupPatterns = []struct {
+	name  string
+	regex *regexp.Regexp
+}{
+	{"page reference [[...]]", regexp.MustCompile(`\[\[[^\]]+\]\]`)},
+	{"block reference ((...))", regexp.MustCompile(`\(\([0-9a-f-]{36}\)\)`)},
+	{"macro {{...}}", macroRegex},
+	{"highlight ^^...^^", highlightRegex},
+	{"property key:: value", regexp.MustCompile(`(?m)^[\w-]+::`)},
+	{"task marker", regexp.MustCompile(`(?m)^(TODO|DOING|DONE|LATER|NOW|WAITING|CANCELED) `)},
+}
+
+// findLogseqMarkup returns a description of every kind of Logseq markup left
+// in the content. Code blocks and code spans are not checked.
+func findLogseqMarkup(content string) []string {
+	content = codeRegex.ReplaceAllString(content, "")
+	var found []string
+	for _, pattern := range logseqMarkupPatterns {
+		if pattern.regex.MatchString(content) {
+			found = append(found, pattern.name)
+		}
+	}
+	return found
+}
+
+// headingRegex matches a markdown heading line (# to ######).
+var headingRegex = regexp.MustCompile(`(?m)^#{1,6} `)
+
+// countHeadings counts the markdown headings in the content, ignoring code blocks.
+func countHeadings(content string) int {
+	return len(headingRegex.FindAllString(fencedCodeRegex.ReplaceAllString(content, ""), -1))
+}
+
+// removeFirst removes the first occurrence of part outside of code from the content.
+// If part is alone on its line, the line is removed with the empty lines around it,
+// so no gap is left between the blocks before and after it.
+func removeFirst(content, part string) string {
+	i := indexOutsideCode(content, part)
+	if i < 0 {
+		return content
+	}
+	before, after := content[:i], content[i+len(part):]
+
+	startsLine := strings.TrimRight(before, " \t") == "" || strings.HasSuffix(strings.TrimRight(before, " \t"), "\n")
+	endsLine := strings.TrimLeft(after, " \t") == "" || strings.HasPrefix(strings.TrimLeft(after, " \t"), "\n")
+	if !startsLine || !endsLine {
+		return before + after
+	}
+
+	before = strings.TrimRight(before, " \t\n")
+	after = strings.TrimLeft(after, " \t\n")
+	if before == "" || after == "" {
+		return before + after
+	}
+	return before + "\n\n" + after
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestConvertCodeShortcodes tests mapping fenced code blocks to Hugo shortcodes
+func TestConvertCodeShortcodes(t *testing.T) {
+	shortcodes := map[string]string{"mermaid": "mermaid", "plantuml": "plantuml"}
+
+	tests := []struct {
+		name  string
+		block string
+		want  string
+	}{
+		{
+			name:  "Mermaid block",
+			block: "```mermaid\ngraph TD\n  A --> B\n```",
+			want:  "{{< mermaid >}}\ngraph TD\n  A --> B\n{{< /mermaid >}}",
+		},
+		{
+			name:  "Unmapped language stays fenced",
+			block: "```go\nfmt.Println(\"x\")\n```",
+			want:  "```go\nfmt.Println(\"x\")\n```",
+		},
+		{
+			name:  "Text around the block",
+			block: "Before\n```plantuml\nA -> B\n```\nAfter",
+			want:  "Before\n{{< plantuml >}}\nA -> B\n{{< /plantuml >}}\nAfter",
+		},
+		{
+			name:  "No code block",
+			block: "Just text",
+			want:  "Just text",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := convertCodeShortcodes(tt.block, shortcodes)
+			if got != tt.want {
+				t.Errorf("convertCodeShortcodes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestConvertCallouts tests converting Logseq callouts to admonitions
+func TestConvertCallouts(t *testing.T) {
+	shortcode := AdmonitionConfig{Shortcode: "admonition", TypeAttribute: "type"}
+	plain := AdmonitionConfig{TypeAttribute: "type"}
+
+	tests := []struct {
+		name   string
+		block  string
+		config AdmonitionConfig
+		want   string
+	}{
+		{
+			name:   "Org note with shortcode",
+			block:  "#+BEGIN_NOTE\nThis is a note\n#+END_NOTE",
+			config: shortcode,
+			want:   "{{< admonition type=\"note\" >}}\nThis is a note\n{{< /admonition >}}",
+		},
+		{
+			name:   "Org warning without shortcode",
+			block:  "#+BEGIN_WARNING\nCareful\n#+END_WARNING",
+			config: plain,
+			want:   "> **Warning**\n>\n> Careful",
+		},
+		{
+			name:   "Org quote is a blockquote",
+			block:  "#+BEGIN_QUOTE\nTo be or not to be\n#+END_QUOTE",
+			config: shortcode,
+			want:   "> To be or not to be",
+		},
+		{
+			name:   "GitHub style callout",
+			block:  "> [!TIP]\n> Use the shortcode\n> twice",
+			config: shortcode,
+			want:   "{{< admonition type=\"tip\" >}}\nUse the shortcode\ntwice\n{{< /admonition >}}",
+		},
+		{
+			name:   "Shortcode without type attribute",
+			block:  "#+BEGIN_TIP\nHint\n#+END_TIP",
+			config: AdmonitionConfig{Shortcode: "alert"},
+			want:   "{{< alert >}}\nHint\n{{< /alert >}}",
+		},
+		{
+			name:   "Source blocks are left untouched",
+			block:  "#+BEGIN_SRC\ncode\n#+END_SRC",
+			config: shortcode,
+			want:   "#+BEGIN_SRC\ncode\n#+END_SRC",
+		},
+		{
+			name:   "Plain blockquote stays",
+			block:  "> Just a quote",
+			config: shortcode,
+			want:   "> Just a quote",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := convertCallouts(tt.block, tt.config)
+			if got != tt.want {
+				t.Errorf("convertCallouts() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCleanupMarkup tests the conversion and removal of Logseq inline markup
+func TestCleanupMarkup(t *testing.T) {
+	all := CleanupConfig{Highlights: true, StripMacros: true}
+
+	tests := []struct {
+		name   string
+		block  string
+		config CleanupConfig
+		want   string
+	}{
+		{"Highlight", "This is ^^important^^!", all, "This is <mark>important</mark>!"},
+		{"Highlight disabled", "This is ^^important^^!", CleanupConfig{}, "This is ^^important^^!"},
+		{"Cloze keeps text", "The answer is {{cloze 42}}.", all, "The answer is 42."},
+		{"Unknown macro is removed", "Before {{namespace Sailing}}after", all, "Before after"},
+		{"Known macros are kept", "{{video https://youtu.be/abc}} {{renderer :todomaster}}", all, "{{video https://youtu.be/abc}} {{renderer :todomaster}}"},
+		{"Hugo template in a code block is kept", "```go-html-template\n{{ range .Pages }}\n  {{ with .Params }}^^x^^{{ end }}\n{{ end }}\n```", all, "```go-html-template\n{{ range .Pages }}\n  {{ with .Params }}^^x^^{{ end }}\n{{ end }}\n```"},
+		{"Code span is kept", "Use `{{ with .Params }}` and ^^this^^", all, "Use `{{ with .Params }}` and <mark>this</mark>"},
+		{"Hugo shortcodes are kept", `{{< video src="a.mp4" >}}`, all, `{{< video src="a.mp4" >}}`},
+		{"Hugo markdown shortcodes are kept", "{{% notice %}}x{{% /notice %}}", all, "{{% notice %}}x{{% /notice %}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cleanupMarkup(tt.block, tt.config)
+			if got != tt.want {
+				t.Errorf("cleanupMarkup() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFindLogseqMarkup tests reporting of leftover Logseq markup
+func TestFindLogseqMarkup(t *testing.T) {
+	found := findLogseqMarkup("See [[Sailing]] and ((6650f3e2-1234-4d2b-9a7e-0123456789ab))\nTODO clean up")
+	want := []string{"page reference [[...]]", "block reference ((...))", "task marker"}
+
+	if strings.Join(found, "|") != strings.Join(want, "|") {
+		t.Errorf("findLogseqMarkup() = %v, want %v", found, want)
+	}
+
+	if found := findLogseqMarkup("Plain **markdown** with a [link](https://example.com) and `{{ .Title }}`"); len(found) != 0 {
+		t.Errorf("findLogseqMarkup() = %v, want none", found)
+	}
+}
+
+// TestRemoveFirst tests removing an image reference from the content, but not from code
+func TestRemoveFirst(t *testing.T) {
+	tests := []struct {
+		name, content, part, want string
+	}{
+		{"Image block", "Intro\n\n![a](a.png)\n\nText", "![a](a.png)", "Intro\n\nText"},
+		{"First block", "![a](a.png)\n\nText", "![a](a.png)", "Text"},
+		{"Inline image", "See ![a](a.png) here", "![a](a.png)", "See  here"},
+		{"Only the first one", "![a](a.png)\n\n![a](a.png)", "![a](a.png)", "![a](a.png)"},
+		{"Not found", "Text", "![a](a.png)", "Text"},
+		{"Not in code", "`![a](a.png)`\n\n![a](a.png)\n\nText", "![a](a.png)", "`![a](a.png)`\n\nText"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := removeFirst(tt.content, tt.part); got != tt.want {
+				t.Errorf("removeFirst() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestConvertGalleries tests collapsing consecutive images into a gallery shortcode
+func TestConvertGalleries(t *testing.T) {
+	gallery := GalleryConfig{Shortcode: "gallery", MinImages: 2}
+
+	tests := []struct {
+		name   string
+		block  string
+		config GalleryConfig
+		want   string
+	}{
+		{
+			name:   "Two images",
+			block:  "![a](../assets/a.png)\n![b](../assets/b.png){:height 100, :width 200}",
+			config: gallery,
+			want:   "{{< gallery >}}\n![a](../assets/a.png)\n![b](../assets/b.png){:height 100, :width 200}\n{{< /gallery >}}",
+		},
+		{
+			name:   "Text around the run",
+			block:  "Look:\n![a](a.png) ![b](b.png)\nNice",
+			config: gallery,
+			want:   "Look:\n{{< gallery >}}\n![a](a.png)\n![b](b.png)\n{{< /gallery >}}\nNice",
+		},
+		{
+			name:   "Text between images",
+			block:  "![a](a.png)\nand\n![b](b.png)",
+			config: gallery,
+			want:   "![a](a.png)\nand\n![b](b.png)",
+		},
+		{
+			name:   "Videos end a run",
+			block:  "![a](a.png)\n![v](v.mp4)\n![b](b.png)\n![c](c.png)",
+			config: gallery,
+			want:   "![a](a.png)\n![v](v.mp4)\n{{< gallery >}}\n![b](b.png)\n![c](c.png)\n{{< /gallery >}}",
+		},
+		{
+			name:   "Images in a code block",
+			block:  "```markdown\n![a](a.png)\n![b](b.png)\n```",
+			config: gallery,
+			want:   "```markdown\n![a](a.png)\n![b](b.png)\n```",
+		},
+		{
+			name:   "Images in code spans",
+			block:  "`![a](a.png)` `![b](b.png)`",
+			config: gallery,
+			want:   "`![a](a.png)` `![b](b.png)`",
+		},
+		{
+			name:   "Code block between images",
+			block:  "![a](a.png)\n```\n![x](x.png)\n```\n![b](b.png)\n![c](c.png)",
+			config: gallery,
+			want:   "![a](a.png)\n```\n![x](x.png)\n```\n{{< gallery >}}\n![b](b.png)\n![c](c.png)\n{{< /gallery >}}",
+		},
+		{
+			name:   "Run too short",
+			block:  "![a](a.png)\n![b](b.png)",
+			config: GalleryConfig{Shortcode: "gallery", MinImages: 3},
+			want:   "![a](a.png)\n![b](b.png)",
+		},
+		{
+			name:   "Disabled",
+			block:  "![a](a.png)\n![b](b.png)",
+			config: GalleryConfig{MinImages: 2},
+			want:   "![a](a.png)\n![b](b.png)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convertGalleries(tt.block, tt.config); got != tt.want {
+				t.Errorf("convertGalleries() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMergeCaptions tests using the bullet after an image as its caption
+func TestMergeCaptions(t *testing.T) {
+	tests := []struct {
+		name   string
+		blocks []string
+		mode   string
+		want   []string
+	}{
+		{
+			name:   "Nested caption",
+			blocks: []string{"![](../assets/beach.jpg)\n* Sunset at the beach"},
+			mode:   CaptionModeNested,
+			want:   []string{"![Sunset at the beach](../assets/beach.jpg)\n*Sunset at the beach*"},
+		},
+		{
+			name:   "Nested caption keeps a real alt text",
+			blocks: []string{"![Beach](../assets/beach.jpg){:height 100, :width 200}\n* Sunset"},
+			mode:   CaptionModeNested,
+			want:   []string{"![Beach](../assets/beach.jpg){:height 100, :width 200}\n*Sunset*"},
+		},
+		{
+			name:   "File name as alt text is replaced",
+			blocks: []string{"![beach.jpg](../assets/beach.jpg)\n* Sunset"},
+			mode:   CaptionModeNested,
+			want:   []string{"![Sunset](../assets/beach.jpg)\n*Sunset*"},
+		},
+		{
+			name:   "Several nested bullets are no caption",
+			blocks: []string{"![](a.jpg)\n* One\n* Two"},
+			mode:   CaptionModeNested,
+			want:   []string{"![](a.jpg)\n* One\n* Two"},
+		},
+		{
+			name:   "Sibling caption",
+			blocks: []string{"Intro", "![](a.jpg)", "My caption", "More text"},
+			mode:   CaptionModeSibling,
+			want:   []string{"Intro", "![My caption](a.jpg)\n*My caption*", "More text"},
+		},
+		{
+			name:   "Sibling heading is no caption",
+			blocks: []string{"![](a.jpg)", "## Next chapter"},
+			mode:   CaptionModeSibling,
+			want:   []string{"![](a.jpg)", "## Next chapter"},
+		},
+		{
+			name:   "Sibling image is no caption",
+			blocks: []string{"![](a.jpg)", "![](b.jpg)"},
+			mode:   CaptionModeSibling,
+			want:   []string{"![](a.jpg)", "![](b.jpg)"},
+		},
+		{
+			name:   "Videos get no caption",
+			blocks: []string{"![](clip.mp4)", "My clip"},
+			mode:   CaptionModeSibling,
+			want:   []string{"![](clip.mp4)", "My clip"},
+		},
+		{
+			name:   "Disabled",
+			blocks: []string{"![](a.jpg)\n* Caption"},
+			mode:   "",
+			want:   []string{"![](a.jpg)\n* Caption"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var blocks []ContentBlock
+			for _, text := range tt.blocks {
+				blocks = append(blocks, ContentBlock{Text: text})
+			}
+
+			var got []string
+			for _, block := range mergeCaptions(blocks, CaptionConfig{Mode: tt.mode}) {
+				got = append(got, block.Text)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeCaptions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
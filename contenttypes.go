@@ -0,0 +1,162 @@
+// This file handles content types besides blog posts, like recipes or projects.
+// Every type has its own marker ("type:: recipe"), output section,
+// front matter and required properties; all types are converted in one run.
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"logseq-to-hugo-converter/internal/indexfile"
+)
+
+// contentType is a kind of page that is converted: blog posts or a configured type.
+type contentType struct {
+	config   *ContentTypeConfig // nil for blog posts
+	marker   *BlogMarker
+	filename *indexfile.Name // Template of the index file name
+}
+
+// newContentTypes creates the content types of a configuration:
+// blog posts (with the configured marker) first, then the configured types.
+func newContentTypes(config *Config) ([]contentType, error) {
+	marker, err := NewBlogMarker(config.Marker)
+	if err != nil {
+		return nil, err
+	}
+	filename := defaultFilename
+	if text := outputFilename(config.Output); text != DefaultFilename {
+		if filename, err = indexfile.Parse(text); err != nil {
+			return nil, fmt.Errorf("output filename: %w", err)
+		}
+	}
+	types := []contentType{{marker: marker, filename: filename}}
+
+	seen := map[string]bool{}
+	for i := range config.Types {
+		typeConfig := &config.Types[i]
+		if typeConfig.Name == "" || seen[typeConfig.Name] {
+			return nil, fmt.Errorf("content type %d: missing or duplicate name %q", i+1, typeConfig.Name)
+		}
+		seen[typeConfig.Name] = true
+
+		marker, err := NewBlogMarker(typeConfig.Marker)
+		if err != nil {
+			return nil, fmt.Errorf("content type %q: %w", typeConfig.Name, err)
+		}
+		if err := validateSections(map[string]string{typeConfig.Name: typeConfig.Section}); err != nil {
+			return nil, fmt.Errorf("content type %q: %w", typeConfig.Name, err)
+		}
+		typeFilename := filename
+		if typeConfig.Filename != "" {
+			if typeFilename, err = indexfile.Parse(typeConfig.Filename); err != nil {
+				return nil, fmt.Errorf("content type %q: filename: %w", typeConfig.Name, err)
+			}
+		}
+		types = append(types, contentType{config: typeConfig, marker: marker, filename: typeFilename})
+	}
+	return types, nil
+}
+
+// outputFilename returns the template of the index file names of the output configuration.
+// Monolingual sites write index.md unless another name is configured.
+func outputFilename(output OutputConfig) string {
+	switch {
+	case output.Monolingual && (output.Filename == "" || output.Filename == DefaultFilename):
+		return MonolingualFilename
+	case output.Filename == "":
+		return DefaultFilename
+	default:
+		return output.Filename
+	}
+}
+
+// typeName returns the content type of a post for messages ("blog post", "recipe").
+func typeName(post *BlogPost) string {
+	if post.Type == nil {
+		return "blog post"
+	}
+	return post.Type.Name
+}
+
+// indexWriter returns the writer of the index file of a post in outputDir,
+// using the file name template of the post's content type.
+func (c *Converter) indexWriter(post *BlogPost, outputDir string) *HugoWriter {
+	writer := NewHugoWriter(c.fs, outputDir)
+	writer.languages = c.config.Language.Keys
+	for _, contentType := range c.types {
+		if contentType.config == post.Type {
+			writer.filename = contentType.filename
+			break
+		}
+	}
+	return writer
+}
+
+// missingProperties returns the required properties a post doesn't have.
+func missingProperties(meta BlogMeta, required []string) []string {
+	var missing []string
+	for _, property := range required {
+		if propertyValue(meta, property) == "" {
+			missing = append(missing, property)
+		}
+	}
+	return missing
+}
+
+// propertyValue returns the value of a property of a post,
+// from its field for the known properties (like "title::") or from meta.Properties.
+func propertyValue(meta BlogMeta, property string) string {
+	switch key := normalizeKey(property); key {
+	case "date":
+		return meta.Date
+	case "title":
+		return meta.Title
+	case "author":
+		return meta.Author
+	case "summary":
+		return meta.Summary
+	case "header":
+		return meta.Header
+	case "status":
+		return meta.Status
+	case "language":
+		return meta.Language
+	case "tags":
+		return strings.Join(meta.Tags, ", ")
+	case "toc":
+		return meta.TOC
+	case "expirydate":
+		return meta.ExpiryDate
+	case "location":
+		switch {
+		case meta.Location == nil:
+			return ""
+		case meta.Location.HasCoordinates:
+			return fmt.Sprintf("%g, %g", meta.Location.Lat, meta.Location.Lng)
+		default:
+			return meta.Location.Name
+		}
+	default:
+		return meta.Properties[key]
+	}
+}
+
+// applyContentType sets the front matter of a post of a configured type:
+// the Hugo layout, the fixed params and the properties written as params.
+func applyContentType(meta *BlogMeta, config *ContentTypeConfig) {
+	meta.Layout = config.Layout
+
+	params := make(map[string]string, len(config.Params)+len(config.Properties))
+	for key, value := range config.Params {
+		params[key] = value
+	}
+	for _, property := range config.Properties {
+		if value := propertyValue(*meta, property); value != "" {
+			params[normalizeKey(property)] = value
+		}
+	}
+	if len(params) > 0 {
+		meta.Params = params
+	}
+}
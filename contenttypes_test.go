@@ -0,0 +1,58 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestNewContentTypes tests creating blog posts and the configured content types
+func TestNewContentTypes(t *testing.T) {
+	config := DefaultConfig()
+	config.Types = []ContentTypeConfig{{Name: "recipe", Marker: "type:: recipe"}, {Name: "project", Marker: "#project"}}
+	types, err := newContentTypes(config)
+	if err != nil {
+		t.Fatalf("newContentTypes() error = %v", err)
+	}
+	if len(types) != 3 || types[0].config != nil || types[1].config.Name != "recipe" || types[2].config.Name != "project" {
+		t.Errorf("newContentTypes() = %+v, want blog posts, recipes and projects", types)
+	}
+
+	for _, invalid := range [][]ContentTypeConfig{
+		{{Marker: "type:: recipe"}},
+		{{Name: "recipe", Marker: "type:: recipe"}, {Name: "recipe", Marker: "type:: dish"}},
+		{{Name: "recipe", Marker: "recipe"}},
+		{{Name: "recipe", Marker: "type:: recipe", Section: "../static"}},
+		{{Name: "recipe", Marker: "type:: recipe", Filename: "recipe.html"}},
+	} {
+		config.Types = invalid
+		if _, err := newContentTypes(config); err == nil {
+			t.Errorf("newContentTypes(%+v) error = nil, want an error", invalid)
+		}
+	}
+}
+
+// TestMissingProperties tests the validation of required properties
+func TestMissingProperties(t *testing.T) {
+	meta := BlogMeta{Title: "Zopf", Tags: []string{"baking"}, Properties: map[string]string{"servings": "4"}}
+	got := missingProperties(meta, []string{"title", "Servings", "tags", "duration", "date"})
+	if want := []string{"duration", "date"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("missingProperties() = %q, want %q", got, want)
+	}
+}
+
+// TestApplyContentType tests the front matter of a content type
+func TestApplyContentType(t *testing.T) {
+	meta := BlogMeta{Title: "Zopf", Properties: map[string]string{"servings": "4"}}
+	applyContentType(&meta, &ContentTypeConfig{
+		Layout:     "recipe",
+		Params:     map[string]string{"kind": "bread"},
+		Properties: []string{"servings", "duration"},
+	})
+
+	if meta.Layout != "recipe" {
+		t.Errorf("Layout = %q, want %q", meta.Layout, "recipe")
+	}
+	if want := map[string]string{"kind": "bread", "servings": "4"}; !reflect.DeepEqual(meta.Params, want) {
+		t.Errorf("Params = %v, want %v", meta.Params, want)
+	}
+}
@@ -0,0 +1,695 @@
+// This file contains the conversion logic that turns Logseq files into Hugo bundles.
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"logseq-to-hugo-converter/internal/lang"
+)
+
+// OutputInfo contains information about a created output file.
+type OutputInfo struct {
+	Dir      string // The directory path
+	Filename string // The created filename (e.g., "index.de.md")
+}
+
+// Converter converts Logseq markdown files to Hugo page bundles
+// according to a configuration.
+type Converter struct {
+	config       *Config
+	fs           FileSystem             // File system the Logseq files are read from and the bundles written to
+	copies       *CopyManager           // Copies the assets of all posts into the bundles
+	links        map[string]string      // Page names of the posts being converted -> bundle names
+	tagged       map[string][]*BlogPost // Lowercase tags -> posts being converted with the tag (for queries)
+	filters      []ContentFilter        // Content filters in the configured order
+	dates        *DateFormatter         // Formats the dates of the front matter and directory names
+	types        []contentType          // Blog posts and the configured content types with their markers
+	extractor    Extractor              // Finds the posts in the files of the input format
+	altText      *AltTextGenerator      // Writes alt text for images without one (nil = off)
+	summaries    *SummaryGenerator      // Writes summaries of posts without one (nil = off)
+	proofread    *Proofreader           // Checks the spelling and grammar of the posts (nil = off)
+	snippets     *SnippetGenerator      // Writes the text of social media snippets (nil = title and summary)
+	review       *PostReviewer          // Asks before each post is written (nil = off)
+	digest       *Digest                // Posts written in this conversion for the digest (nil = off)
+	videos       *VideoTranscoder       // Re-encodes the videos of the posts (nil = off)
+	search       []SearchEntry          // Search index entries of the posts written in this conversion
+	snippetQueue []SocialSnippet        // Social media snippets of the posts written in this conversion (for the queue file)
+	slugs        map[string]*SlugRecord // Slug history of the output directory (nil = redirects off)
+	force        bool                   // Overwrite index files edited by hand
+	only         func(*BlogPost) bool   // Selects the posts that are converted (nil = all)
+	now          func() time.Time       // Current time for expiry dates (replaceable in tests)
+	stats        *ConversionStats       // What the conversion did (for the migration report)
+}
+
+// NewConverter creates a new Converter using the given configuration and file system.
+func NewConverter(config *Config, fsys FileSystem) *Converter {
+	return &Converter{config: config, fs: fsys, copies: NewCopyManager(fsys), now: time.Now, stats: newConversionStats()}
+}
+
+// convertFile converts a Logseq markdown file to Hugo format using the default configuration.
+// It finds all blog posts in the file and converts each one.
+func convertFile(inputPath, outputBasePath string) ([]OutputInfo, error) {
+	return NewConverter(DefaultConfig(), OSFileSystem{}).ConvertFile(context.Background(), inputPath, outputBasePath)
+}
+
+// ConvertFile converts a Logseq markdown file to Hugo format.
+// It finds all blog posts in the file and converts each one.
+// The conversion stops with the context's error when ctx is cancelled.
+func (c *Converter) ConvertFile(ctx context.Context, inputPath, outputBasePath string) ([]OutputInfo, error) {
+	if err := c.prepareTypes(); err != nil {
+		return nil, err
+	}
+
+	extractor, err := openExtractor(c.fs, filepath.Dir(inputPath), c.config)
+	if err != nil {
+		return nil, err
+	}
+	c.extractor = extractor
+
+	posts, err := c.extractFile(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(posts) == 0 {
+		return nil, fmt.Errorf("no blog post found with '%s' marker", c.config.Marker)
+	}
+
+	return c.convertPosts(ctx, posts, outputBasePath)
+}
+
+// ConvertGraph converts all blog posts of a Logseq graph directory.
+// All posts are extracted first, so information across posts (like related posts)
+// is available when the posts are written.
+func (c *Converter) ConvertGraph(ctx context.Context, graphDir, outputBasePath string) ([]OutputInfo, error) {
+	posts, err := c.extractGraph(ctx, graphDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(posts) == 0 {
+		return nil, fmt.Errorf("no blog post found with '%s' marker in %s", c.config.Marker, graphDir)
+	}
+
+	return c.convertPosts(ctx, posts, outputBasePath)
+}
+
+// extractGraph extracts the posts of all markdown files of a graph directory.
+// Files that can't be read and whiteboards are skipped.
+func (c *Converter) extractGraph(ctx context.Context, graphDir string) ([]*BlogPost, error) {
+	if err := c.prepareTypes(); err != nil {
+		return nil, err
+	}
+
+	extractor, err := openExtractor(c.fs, graphDir, c.config)
+	if err != nil {
+		return nil, err
+	}
+	c.extractor = extractor
+
+	files, err := findMarkdownFiles(c.fs, graphDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var posts []*BlogPost
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		c.stats.Files++
+		// Whiteboards may contain copies of posts, but never are posts
+		if isWhiteboardFile(file) {
+			fmt.Printf("Skipping %s: whiteboard, not a blog post\n", file)
+			c.stats.SkippedFiles++
+			continue
+		}
+		// A file that can't be read doesn't stop the conversion of the others
+		filePosts, err := c.extractFile(file)
+		if err != nil {
+			fmt.Printf("Warning: skipping %s: %v\n", file, err)
+			c.stats.SkippedFiles++
+			continue
+		}
+		posts = append(posts, filePosts...)
+	}
+	return posts, nil
+}
+
+// prepareTypes creates the content types of the configuration with their markers.
+func (c *Converter) prepareTypes() error {
+	types, err := newContentTypes(c.config)
+	if err != nil {
+		return err
+	}
+	c.types = types
+	return nil
+}
+
+// extractFile reads a Logseq markdown file and extracts all blog posts in it.
+func (c *Converter) extractFile(inputPath string) ([]*BlogPost, error) {
+	// Read the input file
+	source, err := readFile(c.fs, inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading input file: %w", err)
+	}
+
+	// Remove the parts that could hang the markdown parser
+	source = sanitizeSource(source)
+
+	// Extract the posts of all content types and remember where they come from;
+	// malformed posts are reported and skipped
+	var posts []*BlogPost
+	for _, contentType := range c.types {
+		typePosts, issues := c.extractor.Extract(inputPath, source, contentType.marker)
+		for _, issue := range issues {
+			fmt.Printf("Warning: %s:%d: %s\n", inputPath, issue.Line, issue.Message)
+		}
+		c.stats.Issues += len(issues)
+		for _, post := range typePosts {
+			post.SourcePath = inputPath
+			post.Type = contentType.config
+			c.stats.Found[typeName(post)]++
+		}
+		posts = append(posts, typePosts...)
+	}
+
+	return posts, nil
+}
+
+// convertPosts converts the extracted blog posts that are online: the posts to
+// convert are selected, then each one is transformed into a Document and
+// written (see pipeline.go), and the files of the whole run are written last.
+func (c *Converter) convertPosts(ctx context.Context, posts []*BlogPost, outputBasePath string) ([]OutputInfo, error) {
+	// The posts are told apart in the slug history by their position in the file
+	redirects := c.config.Redirects.File != "" || c.config.Redirects.Aliases
+	var slugs map[*BlogPost]string
+	if redirects {
+		slugs = slugKeys(posts)
+	}
+
+	// Posts are handled in a stable order, independent of the file traversal
+	if err := sortPosts(posts, c.config.Output.Order); err != nil {
+		return nil, err
+	}
+
+	dates, err := NewDateFormatter(c.config.Dates)
+	if err != nil {
+		return nil, err
+	}
+	c.dates = dates
+
+	if redirects {
+		if c.slugs, err = readSlugHistory(c.fs, outputBasePath); err != nil {
+			return nil, err
+		}
+	}
+
+	// Videos are only transcoded if ffmpeg is installed
+	if c.config.Video.Transcode.Enabled && c.videos == nil {
+		if c.videos, err = NewVideoTranscoder(c.config.Video.Transcode, exec.LookPath); err != nil {
+			fmt.Printf("Warning: videos are copied without transcoding: %v\n", err)
+		}
+	}
+
+	// Generated alt text, summaries and proofreading are cached, also if the conversion fails later
+	if err := c.prepareLLMSteps(); err != nil {
+		return nil, err
+	}
+	defer c.saveLLMCaches()
+
+	if err := validateSections(c.config.Sections.Mapping); err != nil {
+		return nil, err
+	}
+
+	// The source is hashed before any processing step changes the posts
+	if c.config.PublishLog.Enabled {
+		for _, post := range posts {
+			post.SourceHash = sourceHash(post)
+		}
+	}
+
+	online, err := c.selectPosts(posts, slugs, outputBasePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.preparePosts(online); err != nil {
+		return nil, err
+	}
+
+	var outputs []OutputInfo
+	for _, post := range online {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// Index files edited by hand after the last conversion are kept
+		outputDir := createOutputDir(outputBasePath, post)
+		filename, err := c.indexWriter(post, outputDir).getFilename(post.Meta.Language)
+		if err != nil {
+			return nil, fmt.Errorf("index file name: %w", err)
+		}
+		edited, err := c.editedByHand(outputDir, filename)
+		if err != nil {
+			return nil, err
+		}
+		if edited && !c.force {
+			fmt.Printf("Skipping %s '%s': %s was edited by hand\n", typeName(post), post.Meta.Title, filename)
+			c.stats.Skipped["edited by hand"]++
+			c.stats.Conflicts = append(c.stats.Conflicts, filepath.Join(outputDir, filename))
+			continue
+		}
+
+		doc, err := c.transformPost(ctx, post, outputBasePath)
+		if err != nil {
+			return nil, err
+		}
+		output, err := c.writeDocument(ctx, doc)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, output)
+		c.stats.Converted++
+
+		// The content is written, release it so large graphs don't keep every post in memory
+		post.Content = nil
+	}
+	if err := c.writeRunFiles(outputBasePath); err != nil {
+		return nil, err
+	}
+
+	return outputs, nil
+}
+
+// selectPosts returns the posts that are converted: online posts with the
+// required properties of their content type that are not expired, and of
+// those the ones selected (c.only) and confirmed in the review. The bundles
+// of expired posts are removed. slugs are the keys of the posts in the slug history.
+func (c *Converter) selectPosts(posts []*BlogPost, slugs map[*BlogPost]string, outputBasePath string) ([]*BlogPost, error) {
+	var online []*BlogPost
+	for _, post := range posts {
+		if post.Meta.Status != "online" {
+			fmt.Printf("Skipping %s '%s': status is '%s'\n", typeName(post), post.Meta.Title, post.Meta.Status)
+			c.stats.Skipped[fmt.Sprintf("status '%s'", post.Meta.Status)]++
+			continue
+		}
+		c.placePost(post)
+
+		// The language chooses the index file, so it is detected before anything else
+		c.applyLanguageDetection(post)
+
+		// Pages of other content types get their own front matter
+		if post.Type != nil {
+			if missing := missingProperties(post.Meta, post.Type.Required); len(missing) > 0 {
+				fmt.Printf("Skipping %s '%s': missing %s\n", typeName(post), post.Meta.Title, strings.Join(missing, ", "))
+				c.stats.Skipped["missing properties"]++
+				continue
+			}
+			applyContentType(&post.Meta, post.Type)
+		}
+
+		// Expired posts are unpublished by removing their bundle
+		if c.config.Output.PruneExpired && isExpired(post.Meta, c.now()) {
+			fmt.Printf("Removing expired blog post '%s': expired on %s\n", post.Meta.Title, post.Meta.ExpiryDate)
+			// Only ever the bundle, never the output or section directory
+			dir := createOutputDir(outputBasePath, post)
+			if filepath.Dir(dir) != filepath.Join(outputBasePath, filepath.FromSlash(post.Section)) {
+				return nil, fmt.Errorf("removing expired post '%s': %s is no bundle directory", post.Meta.Title, dir)
+			}
+			if err := c.fs.RemoveAll(dir); err != nil {
+				return nil, fmt.Errorf("removing expired post: %w", err)
+			}
+			c.stats.Skipped["expired"]++
+			if c.slugs != nil {
+				c.removeSlug(slugs[post], post)
+			}
+			continue
+		}
+		online = append(online, post)
+	}
+
+	// Moved bundles are recorded before posts are left out below, they still exist at their new path
+	if c.slugs != nil {
+		c.trackSlugs(slugs, online)
+	}
+
+	// Only the posts selected (e.g. in the dashboard) are converted
+	if c.only != nil {
+		online = slices.DeleteFunc(online, func(post *BlogPost) bool { return !c.only(post) })
+	}
+
+	// In the interactive mode only the confirmed posts are converted
+	if c.review != nil {
+		online = c.reviewPosts(online)
+	}
+	return online, nil
+}
+
+// preparePosts sets up what the transform phase needs to know across the
+// converted posts: categories, related posts, authors, the links between the
+// posts, the tag index of the queries and the content filters.
+func (c *Converter) preparePosts(posts []*BlogPost) error {
+	// Categories from the journal hierarchy count for related posts and rules too
+	for _, post := range posts {
+		if err := applyAncestors(post, c.config.Categories); err != nil {
+			return err
+		}
+	}
+
+	if c.config.Related.Enabled {
+		findRelatedPosts(posts, c.config.Related.Max)
+	}
+
+	// Unknown authors fail the conversion before anything is written
+	if err := resolveAuthors(posts, c.config.Authors); err != nil {
+		return err
+	}
+
+	// Links between the converted posts are rewritten to Hugo links
+	c.links = buildLinkMap(posts)
+	if c.config.Queries.Execute {
+		c.tagged = buildTagIndex(posts)
+	}
+
+	filters, err := newContentFilters(c, c.config.Filters)
+	if err != nil {
+		return err
+	}
+	c.filters = filters
+	return nil
+}
+
+// prepareLLMSteps creates the generators of the enabled steps that use the language model,
+// and the proofreader (which only uses it with the "llm" method).
+// Generators that are already set (e.g. in tests) are kept.
+func (c *Converter) prepareLLMSteps() error {
+	needAltText := c.config.AltText.Enabled && c.altText == nil
+	needSummaries := c.config.Summary.Generate && c.summaries == nil
+	needProofread := c.config.Proofread.Enabled && c.proofread == nil
+	needSnippets := c.config.Snippets.Enabled && c.config.Snippets.Generate && c.snippets == nil
+	if !needAltText && !needSummaries && !needProofread && !needSnippets {
+		return nil
+	}
+
+	var client *llmClient
+	var err error
+	if needAltText || needSummaries || needSnippets || (needProofread && c.config.Proofread.Method == ProofreadLLM) {
+		if client, err = newLLMClient(c.config.LLM); err != nil {
+			return err
+		}
+	}
+	if needAltText {
+		if c.altText, err = NewAltTextGenerator(c.fs, c.config.AltText.Cache, client.describeImage); err != nil {
+			return err
+		}
+	}
+	if needSummaries {
+		if c.summaries, err = NewSummaryGenerator(c.fs, c.config.Summary.Cache, client.summarizePost); err != nil {
+			return err
+		}
+	}
+	if needSnippets {
+		if c.snippets, err = NewSnippetGenerator(c.fs, c.config.Snippets.Cache, client.writeSnippet); err != nil {
+			return err
+		}
+	}
+	if needProofread {
+		var proofread proofreadFunc
+		if client != nil {
+			proofread = client.proofreadPost
+		}
+		if c.proofread, err = NewProofreader(c.fs, c.config.Proofread, proofread); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// proofreadPost checks the spelling of a post, prints its issues and adds them to the report.
+// A failing check is a warning, the post is converted anyway.
+func (c *Converter) proofreadPost(ctx context.Context, meta BlogMeta, content string) {
+	language := llmLanguage(meta.Language)
+	issues, err := c.proofread.Check(ctx, meta.Title, content, language)
+	if err != nil {
+		fmt.Printf("Warning: '%s' not proofread: %v\n", meta.Title, err)
+		return
+	}
+	c.stats.Proofread[language]++
+	if len(issues) == 0 {
+		return
+	}
+	for _, issue := range issues {
+		fmt.Printf("Warning: '%s' (%s): %s\n", meta.Title, language, issue)
+	}
+	c.stats.Proofreading = append(c.stats.Proofreading, ProofreadResult{Title: meta.Title, Language: language, Issues: issues})
+}
+
+// saveLLMCaches writes the caches of the generated alt text, summaries, snippets and proofreading.
+func (c *Converter) saveLLMCaches() {
+	if c.altText != nil {
+		if err := c.altText.Save(); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+	}
+	if c.summaries != nil {
+		if err := c.summaries.Save(); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+	}
+	if c.snippets != nil {
+		if err := c.snippets.Save(); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+	}
+	if c.proofread != nil {
+		if err := c.proofread.Save(); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+	}
+}
+
+// useFirstImageAsHeader makes the first inline image the header image of a post
+// and removes it from the content if configured.
+func (c *Converter) useFirstImageAsHeader(processor *ImageProcessor, meta *BlogMeta, content string) string {
+	match, ref := processor.FirstImage(content)
+	if ref == "" {
+		return content
+	}
+
+	meta.Header = ref
+	if c.config.Header.RemoveFirstImage {
+		content = removeFirst(content, match)
+	}
+	return content
+}
+
+// applySocial sets the images and social preview metadata of a post.
+// The preview image is the OpenGraph variant of the header image if one is written,
+// otherwise the featured image.
+func (c *Converter) applySocial(meta *BlogMeta) {
+	social := &SocialMeta{
+		Title:       meta.Title,
+		Description: cmp.Or(meta.Description, meta.Summary),
+		Card:        "summary",
+		Site:        c.config.Social.TwitterSite,
+	}
+
+	if meta.Header != "" {
+		social.Image = c.featuredName(meta.Header)
+		if c.config.Header.OpenGraphSize != "" {
+			social.Image = "og-image.jpeg"
+		}
+		social.Card = "summary_large_image"
+		// The preview image comes first, before the other images of the bundle
+		if !slices.Contains(meta.Images, social.Image) {
+			meta.Images = slices.Insert(meta.Images, 0, social.Image)
+		}
+	}
+
+	meta.Social = social
+}
+
+// featuredName returns the name of the featured image written for a header image:
+// "featured.jpeg" if it is resized, otherwise "featured" with the header's extension.
+func (c *Converter) featuredName(header string) string {
+	if c.config.Header.FeaturedSize != "" {
+		return "featured.jpeg"
+	}
+	return "featured" + filepath.Ext(localPath(header))
+}
+
+// booleanParams maps the boolean properties of a post to their param names.
+// Properties without a param name or without a boolean value are left out.
+func booleanParams(properties map[string]string, mapping map[string]string) map[string]bool {
+	params := make(map[string]bool)
+	for property, value := range properties {
+		if param := mapping[property]; param != "" {
+			if flag, ok := parseBool(value); ok {
+				params[param] = flag
+			}
+		}
+	}
+	if len(params) == 0 {
+		return nil
+	}
+	return params
+}
+
+// translationParams reads the "translate::" and "translate-languages::" properties
+// the translation tool (cmd/translate) honors: "translate:: false" keeps a poem
+// from being machine translated, "translate-languages:: en, fr" limits the
+// languages. Invalid values are reported and left out.
+func translationParams(meta *BlogMeta) {
+	meta.Translate, meta.TranslateLanguages = nil, nil
+	if _, mapped := meta.Flags["translate"]; mapped {
+		// Already written by a boolean_params mapping
+	} else if value, ok := meta.Properties["translate"]; ok {
+		if translate, ok := parseBool(value); ok {
+			meta.Translate = &translate
+		} else {
+			fmt.Printf("Warning: translate:: of '%s' must be true or false, not '%s'\n", meta.Title, value)
+		}
+	}
+	if value, ok := meta.Properties["translate-languages"]; ok {
+		meta.TranslateLanguages = []string{}
+		for _, item := range parseTagList(value) {
+			tag, ok := lang.Parse(item)
+			if !ok {
+				fmt.Printf("Warning: translate-languages:: of '%s': %s is not a known language\n", meta.Title, item)
+				continue
+			}
+			if key := tag.Key(); !slices.Contains(meta.TranslateLanguages, key) {
+				meta.TranslateLanguages = append(meta.TranslateLanguages, key)
+			}
+		}
+	}
+}
+
+// Post orders of OutputConfig.Order.
+const (
+	OrderDate   = "date"   // By date, then title
+	OrderTitle  = "title"  // By title, then date
+	OrderSource = "source" // In the order the posts were found in the files
+)
+
+// sortPosts sorts the posts in the given order. Titles are compared case-insensitively,
+// posts that are still equal keep their source order.
+func sortPosts(posts []*BlogPost, order string) error {
+	byTitle := func(a, b *BlogPost) int {
+		return strings.Compare(strings.ToLower(a.Meta.Title), strings.ToLower(b.Meta.Title))
+	}
+	byDate := func(a, b *BlogPost) int { return strings.Compare(a.Meta.Date, b.Meta.Date) }
+
+	switch order {
+	case OrderDate, "":
+		slices.SortStableFunc(posts, func(a, b *BlogPost) int { return cmp.Or(byDate(a, b), byTitle(a, b)) })
+	case OrderTitle:
+		slices.SortStableFunc(posts, func(a, b *BlogPost) int { return cmp.Or(byTitle(a, b), byDate(a, b)) })
+	case OrderSource:
+	default:
+		return fmt.Errorf("unknown post order %q (use %q, %q or %q)", order, OrderDate, OrderTitle, OrderSource)
+	}
+	return nil
+}
+
+// isExpired reports whether the expiry date of a post has passed.
+// Dates without a time zone are local time, an invalid date never expires.
+func isExpired(meta BlogMeta, now time.Time) bool {
+	if meta.ExpiryDate == "" {
+		return false
+	}
+	if expiry, ok := parseDate(meta.ExpiryDate, time.Local); ok {
+		return !now.Before(expiry)
+	}
+	fmt.Printf("Warning: '%s' has an invalid expiry date '%s'\n", meta.Title, meta.ExpiryDate)
+	return false
+}
+
+// placePost sets the bundle name and the section directory of a post.
+// Content types put their posts into their own section.
+func (c *Converter) placePost(post *BlogPost) {
+	post.Slug = postSlug(c.dates.Folder(post.Meta.Date), post.Meta.Title, post.SourcePath, c.config.Output.SlugPolicy)
+	post.Section = ""
+	if c.config.Sections.Enabled {
+		post.Section = postSection(pageNamespace(post), c.config.Sections.Mapping, c.config.Output.SlugPolicy)
+	}
+	if post.Type != nil {
+		post.Section = path.Join(strings.Trim(post.Type.Section, "/"), post.Section)
+	}
+}
+
+// createOutputDir builds the output directory path of a post.
+func createOutputDir(basePath string, post *BlogPost) string {
+	return filepath.Join(basePath, filepath.FromSlash(post.Section), post.Slug)
+}
+
+// findMarkdownFiles returns all markdown files of a Logseq graph in lexical order.
+// Hidden directories, the Logseq config directory ("logseq") and assets are skipped.
+func findMarkdownFiles(fsys FileSystem, graphDir string) ([]string, error) {
+	var files []string
+	err := walkDir(fsys, graphDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			name := entry.Name()
+			if path != graphDir && (strings.HasPrefix(name, ".") || name == "logseq" || name == "assets") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.EqualFold(filepath.Ext(path), ".md") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scanning graph %s: %w", graphDir, err)
+	}
+	return files, nil
+}
+
+// buildContent runs the content filters on the post and combines its blocks into a single string.
+func (c *Converter) buildContent(post *BlogPost) (string, error) {
+	for _, filter := range c.filters {
+		if err := filter(post); err != nil {
+			return "", fmt.Errorf("filtering '%s': %w", post.Meta.Title, err)
+		}
+	}
+
+	var builder strings.Builder
+	for _, block := range post.Content {
+		if cleaned := strings.TrimSpace(block.Text); cleaned != "" {
+			builder.WriteString(cleaned)
+			builder.WriteString("\n\n")
+		}
+	}
+	return strings.TrimSpace(builder.String()), nil
+}
+
+// applyTOC decides whether the post gets a table of contents.
+// An explicit "toc::" property always wins, otherwise posts with enough headings
+// get one if configured. In shortcode mode the shortcode is put in front of the content,
+// in front matter mode meta.TOC is set for the writer.
+func (c *Converter) applyTOC(meta *BlogMeta, content string) string {
+	config := c.config.TOC
+	if meta.TOC == "" && config.MinHeadings > 0 && countHeadings(content) >= config.MinHeadings {
+		meta.TOC = "true"
+	}
+
+	if config.Mode == "shortcode" {
+		if meta.TOC == "true" {
+			content = fmt.Sprintf("{{< %s >}}\n\n%s", config.Shortcode, content)
+		}
+		meta.TOC = ""
+	}
+
+	return content
+}
@@ -0,0 +1,108 @@
+// This file handles copying assets into the bundles.
+// The CopyManager makes sure every destination file is written only once,
+// even if several posts are converted at the same time and reference the same file.
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+)
+
+// CopyManager serializes and deduplicates file copies per destination path.
+// The first copy to a destination does the work, concurrent and later copies
+// of the same source wait for it and share its result. Copying a different
+// source to a destination that is already taken is refused instead of
+// overwriting the file.
+type CopyManager struct {
+	fs      FileSystem
+	mu      sync.Mutex
+	entries map[string]*copyEntry // Cleaned destination path -> copy
+}
+
+// copyEntry is a copy to one destination that is running or done.
+type copyEntry struct {
+	src  string
+	done chan struct{} // Closed when the copy finished
+	err  error         // Result of the copy (valid after done is closed)
+}
+
+// NewCopyManager creates a CopyManager that copies files on fsys.
+func NewCopyManager(fsys FileSystem) *CopyManager {
+	return &CopyManager{fs: fsys, entries: make(map[string]*copyEntry)}
+}
+
+// Copy copies src to dst unless the same copy was already done.
+// It is safe to call from several goroutines.
+func (m *CopyManager) Copy(ctx context.Context, src, dst string) error {
+	return m.CopyWith(ctx, src, dst, func(ctx context.Context, src, dst string) error {
+		return copyFile(ctx, m.fs, src, dst)
+	})
+}
+
+// CopyWith writes dst from src with write (e.g. a transcoder) unless the same
+// copy was already done. It is safe to call from several goroutines.
+func (m *CopyManager) CopyWith(ctx context.Context, src, dst string, write func(ctx context.Context, src, dst string) error) error {
+	dst = filepath.Clean(dst)
+
+	m.mu.Lock()
+	entry, exists := m.entries[dst]
+	if !exists {
+		entry = &copyEntry{src: src, done: make(chan struct{})}
+		m.entries[dst] = entry
+	}
+	m.mu.Unlock()
+
+	if exists {
+		if entry.src != src {
+			return fmt.Errorf("not copying %s to %s: already used for %s", src, dst, entry.src)
+		}
+		select {
+		case <-entry.done:
+			return entry.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	entry.err = write(ctx, src, dst)
+	close(entry.done)
+	return entry.err
+}
+
+// copyFile copies a file from src to dst on fsys.
+func copyFile(ctx context.Context, fsys FileSystem, src, dst string) error {
+	in, err := fsys.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := fsys.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	// io.Copy reads until EOF or until the context is cancelled
+	if _, err := io.Copy(out, contextReader{ctx: ctx, r: in}); err != nil {
+		out.Close()
+		return fmt.Errorf("copying %s: %w", src, err)
+	}
+	return out.Close()
+}
+
+// contextReader is a reader that stops reading once its context is cancelled.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+// Read reads from the underlying reader unless the context is done.
+func (r contextReader) Read(b []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(b)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// countingFileSystem counts the files created on the wrapped file system
+type countingFileSystem struct {
+	FileSystem
+	creates atomic.Int32
+}
+
+func (c *countingFileSystem) Create(name string) (io.WriteCloser, error) {
+	c.creates.Add(1)
+	return c.FileSystem.Create(name)
+}
+
+// TestCopyManager_Concurrent tests that concurrent copies to the same destination are done once
+func TestCopyManager_Concurrent(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "image.png")
+	dst := filepath.Join(dir, "bundle", "image.png")
+	if err := os.WriteFile(src, []byte("image data"), 0644); err != nil {
+		t.Fatalf("Failed to create source: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		t.Fatalf("Failed to create bundle: %v", err)
+	}
+
+	fsys := &countingFileSystem{FileSystem: OSFileSystem{}}
+	copies := NewCopyManager(fsys)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- copies.Copy(context.Background(), src, dst)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Copy() error = %v", err)
+		}
+	}
+	if n := fsys.creates.Load(); n != 1 {
+		t.Errorf("Destination was created %d times, want 1", n)
+	}
+	if data, err := os.ReadFile(dst); err != nil || string(data) != "image data" {
+		t.Errorf("Destination = %q, %v; want copied data", data, err)
+	}
+}
+
+// TestCopyManager_Conflicts tests copies that can't be done
+func TestCopyManager_Conflicts(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	fsys.WriteFile(filepath.Join("assets", "a.png"), []byte("a"))
+	fsys.WriteFile(filepath.Join("assets", "b.png"), []byte("b"))
+	fsys.MkdirAll("bundle", 0755)
+	copies := NewCopyManager(fsys)
+	dst := filepath.Join("bundle", "image.png")
+
+	if err := copies.Copy(context.Background(), filepath.Join("assets", "a.png"), dst); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if err := copies.Copy(context.Background(), filepath.Join("assets", "b.png"), dst); err == nil {
+		t.Error("Copying a different source to the same destination should fail")
+	}
+	if data, _ := readFile(fsys, dst); string(data) != "a" {
+		t.Errorf("Destination = %q, want the first copy", data)
+	}
+
+	err := copies.Copy(context.Background(), filepath.Join("assets", "missing.png"), filepath.Join("bundle", "missing.png"))
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Copy() of a missing file error = %v, want %v", err, fs.ErrNotExist)
+	}
+}
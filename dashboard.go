@@ -0,0 +1,445 @@
+// This file implements the "dashboard" subcommand, a terminal UI that lists
+// the posts of a graph with their publishing status: whether they are
+// converted, when they were last written and which translations exist.
+// Selected posts can be converted and translated from the list.
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"logseq-to-hugo-converter/internal/indexfile"
+)
+
+// translationLanguages are the languages the translation tool (cmd/translate) writes.
+var translationLanguages = []string{"de", "en", "es", "fr", "it"}
+
+// dashboardTitleWidth is the number of characters of the titles shown in the list.
+const dashboardTitleWidth = 40
+
+// postStatus is a post of the graph with its publishing status.
+type postStatus struct {
+	Post      *BlogPost
+	Index     string          // Index file the converter writes ("" if the post isn't converted yet)
+	Languages []string        // Language codes of the index files in the bundle (translations)
+	Filename  *indexfile.Name // Template of the index file names of the post's content type
+	Written   time.Time       // When the index file was last written
+}
+
+// Converted reports whether the bundle of the post exists.
+func (p postStatus) Converted() bool {
+	return p.Index != ""
+}
+
+// dashboard loads the posts of a graph and runs the actions on them.
+type dashboard struct {
+	config     *Config
+	configPath string // Configuration file, passed on to the translation tool ("" = defaults)
+	graphDir   string
+	outputDir  string
+	translate  []string // Command of the translation tool, the index file is appended (nil = no translations)
+}
+
+// runDashboard runs the dashboard subcommand and returns the process exit code.
+// Usage: go run . dashboard [-config converter.toml] <graph_directory> <output_directory>
+func runDashboard(args []string) int {
+	flags := flag.NewFlagSet("dashboard", flag.ContinueOnError)
+	configPath := flags.String("config", "", "path to a converter.toml configuration file")
+	translate := flags.String("translate", "go run ./cmd/translate", "command of the translation tool, the index file is appended")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() < 2 || len(strings.Fields(*translate)) == 0 {
+		fmt.Println("Usage: go run . dashboard [-config converter.toml] [-translate \"go run ./cmd/translate\"] <graph_directory> <output_directory>")
+		return 2
+	}
+
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 2
+	}
+	d := &dashboard{config: config, configPath: *configPath, graphDir: flags.Arg(0), outputDir: flags.Arg(1), translate: strings.Fields(*translate)}
+	if config.Output.Monolingual {
+		d.translate = nil // Monolingual sites have no translations
+	}
+
+	posts, err := d.load()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 1
+	}
+	if _, err := tea.NewProgram(newDashboardModel(d, posts), tea.WithAltScreen()).Run(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// load extracts the posts of the graph and looks up their bundles in the output directory.
+func (d *dashboard) load() ([]postStatus, error) {
+	return loadPostStatus(d.config, d.graphDir, d.outputDir)
+}
+
+// loadPostStatus extracts the posts of a graph and looks up their bundles in the output directory.
+// The posts are sorted by date, the newest first.
+func loadPostStatus(config *Config, graphDir, outputDir string) ([]postStatus, error) {
+	converter := NewConverter(config, OSFileSystem{})
+	dates, err := NewDateFormatter(config.Dates)
+	if err != nil {
+		return nil, err
+	}
+	converter.dates = dates
+
+	posts, err := converter.extractGraph(context.Background(), graphDir)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]postStatus, 0, len(posts))
+	for _, post := range posts {
+		converter.placePost(post)
+		converter.applyLanguageDetection(post)
+		bundle := createOutputDir(outputDir, post)
+		writer := converter.indexWriter(post, bundle)
+		row := postStatus{Post: post, Languages: bundleLanguages(bundle, writer.filename), Filename: writer.filename}
+
+		filename, err := writer.getFilename(post.Meta.Language)
+		if err != nil {
+			return nil, err
+		}
+		index := filepath.Join(bundle, filename)
+		if info, err := os.Stat(index); err == nil {
+			row.Index, row.Written = index, info.ModTime()
+		}
+		rows = append(rows, row)
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].Post.Meta.Date > rows[j].Post.Meta.Date
+	})
+	return rows, nil
+}
+
+// bundleLanguages returns the language codes of the index files of a bundle
+// named like filename ("index.de.md" -> "de", "index.md" is left out) in the
+// order of translationLanguages.
+func bundleLanguages(bundle string, filename *indexfile.Name) []string {
+	files, _ := filepath.Glob(filepath.Join(bundle, "*.md"))
+	found := make(map[string]bool)
+	for _, file := range files {
+		if language, _ := filename.Match(filepath.Base(file), filepath.Base(bundle)); language != "" {
+			found[language] = true
+		}
+	}
+
+	var languages, others []string
+	for _, language := range translationLanguages {
+		if found[language] {
+			languages = append(languages, language)
+			delete(found, language)
+		}
+	}
+	for language := range found {
+		others = append(others, language)
+	}
+	sort.Strings(others)
+	return append(languages, others...)
+}
+
+// convert converts the selected posts. The whole graph is extracted,
+// so the posts are converted exactly like in a full conversion.
+func (d *dashboard) convert(posts []postStatus) error {
+	bundles := make(map[string]bool)
+	for _, post := range posts {
+		bundles[post.Post.BundlePath()] = true
+	}
+
+	fsys, err := NewOSFileSystem(d.config.Output)
+	if err != nil {
+		return err
+	}
+	converter := NewConverter(d.config, fsys)
+	converter.only = func(post *BlogPost) bool { return bundles[post.BundlePath()] }
+	outputs, err := converter.ConvertGraph(context.Background(), d.graphDir, d.outputDir)
+	for _, output := range outputs {
+		fmt.Printf("Created: %s/%s\n", output.Dir, output.Filename)
+	}
+	return err
+}
+
+// translateIndexes runs the translation tool for the converted posts.
+func (d *dashboard) translateIndexes(posts []postStatus) error {
+	if d.translate == nil {
+		return fmt.Errorf("translations are disabled for monolingual sites")
+	}
+	failed := 0
+	for _, post := range posts {
+		if !post.Converted() {
+			fmt.Printf("Skipping '%s': not converted yet\n", post.Post.Meta.Title)
+			continue
+		}
+		args := slices.Clone(d.translate[1:])
+		if d.configPath != "" {
+			args = append(args, "-config", d.configPath)
+		}
+		if post.Filename != nil && post.Filename.String() != DefaultFilename {
+			args = append(args, "-filename", post.Filename.String())
+		}
+		cmd := exec.Command(d.translate[0], append(args, post.Index)...)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			fmt.Printf("Error: translating '%s': %v\n", post.Post.Meta.Title, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d translations failed", failed)
+	}
+	return nil
+}
+
+// dashboardAction runs an action of the dashboard while the terminal UI is
+// suspended, so the action can print its progress. The posts are loaded
+// again afterwards to show the new status.
+type dashboardAction struct {
+	name  string
+	run   func() error
+	load  func() ([]postStatus, error)
+	stdin io.Reader
+
+	posts []postStatus // Posts loaded after the action
+	err   error        // Error of loading the posts
+}
+
+// Run runs the action and waits for Enter, so its output can be read.
+func (a *dashboardAction) Run() error {
+	err := a.run()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+	a.posts, a.err = a.load()
+
+	fmt.Print("\nPress Enter to return to the dashboard ")
+	bufio.NewReader(a.stdin).ReadString('\n')
+	return err
+}
+
+// SetStdin sets the input the action waits on for Enter.
+func (a *dashboardAction) SetStdin(r io.Reader) { a.stdin = r }
+
+// SetStdout is part of tea.ExecCommand, the action prints to os.Stdout.
+func (a *dashboardAction) SetStdout(io.Writer) {}
+
+// SetStderr is part of tea.ExecCommand, the action prints to os.Stdout.
+func (a *dashboardAction) SetStderr(io.Writer) {}
+
+// dashboardDoneMsg is sent when an action has finished.
+type dashboardDoneMsg struct {
+	action *dashboardAction
+	err    error
+}
+
+// dashboardModel is the state of the terminal UI.
+type dashboardModel struct {
+	dashboard *dashboard
+	posts     []postStatus
+	selected  map[string]bool // Bundle paths of the selected posts
+	cursor    int
+	offset    int // First post shown
+	height    int // Height of the terminal (0 = unknown)
+	message   string
+}
+
+// newDashboardModel creates the model of the terminal UI.
+func newDashboardModel(d *dashboard, posts []postStatus) dashboardModel {
+	return dashboardModel{dashboard: d, posts: posts, selected: make(map[string]bool)}
+}
+
+// Init is part of tea.Model, there is nothing to do at the start.
+func (m dashboardModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles the keys and the results of the actions.
+func (m dashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+	case dashboardDoneMsg:
+		m.message = msg.action.name + " finished"
+		if msg.err != nil {
+			m.message = fmt.Sprintf("%s failed: %v", msg.action.name, msg.err)
+		}
+		if msg.action.err != nil {
+			m.message = fmt.Sprintf("Reloading failed: %v", msg.action.err)
+		} else {
+			m.posts = msg.action.posts
+			m.cursor = min(m.cursor, max(len(m.posts)-1, 0))
+		}
+	case tea.KeyMsg:
+		m.message = ""
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return m, tea.Quit
+		case "up", "k":
+			m.cursor = max(m.cursor-1, 0)
+		case "down", "j":
+			m.cursor = min(m.cursor+1, max(len(m.posts)-1, 0))
+		case "home", "g":
+			m.cursor = 0
+		case "end", "G":
+			m.cursor = max(len(m.posts)-1, 0)
+		case " ", "x":
+			if len(m.posts) > 0 {
+				bundle := m.posts[m.cursor].Post.BundlePath()
+				if m.selected[bundle] {
+					delete(m.selected, bundle)
+				} else {
+					m.selected[bundle] = true
+				}
+				m.cursor = min(m.cursor+1, len(m.posts)-1)
+			}
+		case "a":
+			if len(m.selected) > 0 {
+				m.selected = make(map[string]bool)
+			} else {
+				for _, post := range m.posts {
+					m.selected[post.Post.BundlePath()] = true
+				}
+			}
+		case "c":
+			posts := m.targets()
+			return m, m.runAction(fmt.Sprintf("Converting %d posts", len(posts)), func() error { return m.dashboard.convert(posts) })
+		case "t":
+			if m.dashboard.translate == nil {
+				m.message = "Translations are disabled for monolingual sites"
+				break
+			}
+			posts := m.targets()
+			return m, m.runAction(fmt.Sprintf("Translating %d posts", len(posts)), func() error { return m.dashboard.translateIndexes(posts) })
+		case "r":
+			return m, m.runAction("Reloading", func() error { return nil })
+		}
+	}
+	m.scroll()
+	return m, nil
+}
+
+// targets returns the selected posts, or the post under the cursor if none is selected.
+func (m dashboardModel) targets() []postStatus {
+	var posts []postStatus
+	for _, post := range m.posts {
+		if m.selected[post.Post.BundlePath()] {
+			posts = append(posts, post)
+		}
+	}
+	if len(posts) == 0 && len(m.posts) > 0 {
+		posts = append(posts, m.posts[m.cursor])
+	}
+	return posts
+}
+
+// runAction suspends the terminal UI while the action runs.
+func (m dashboardModel) runAction(name string, run func() error) tea.Cmd {
+	action := &dashboardAction{name: name, run: run, load: m.dashboard.load, stdin: os.Stdin}
+	return tea.Exec(action, func(err error) tea.Msg {
+		return dashboardDoneMsg{action: action, err: err}
+	})
+}
+
+// visibleRows returns the number of posts that fit on the screen.
+func (m dashboardModel) visibleRows() int {
+	if m.height == 0 {
+		return len(m.posts)
+	}
+	return max(m.height-5, 1) // Header, column titles, message and help
+}
+
+// scroll keeps the cursor on the screen.
+func (m *dashboardModel) scroll() {
+	rows := m.visibleRows()
+	switch {
+	case m.cursor < m.offset:
+		m.offset = m.cursor
+	case m.cursor >= m.offset+rows:
+		m.offset = m.cursor - rows + 1
+	}
+}
+
+// View renders the list of posts.
+func (m dashboardModel) View() string {
+	converted := 0
+	for _, post := range m.posts {
+		if post.Converted() {
+			converted++
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %d posts, %d converted, %d selected\n", m.dashboard.graphDir, len(m.posts), converted, len(m.selected))
+	translated := m.dashboard.translate != nil
+	columns := fmt.Sprintf("      %-8s %-10s  %-*s  %-16s", "Status", "Date", dashboardTitleWidth, "Title", "Written")
+	if translated {
+		columns += "  Translations"
+	}
+	b.WriteString(strings.TrimRight(columns, " ") + "\n")
+
+	end := min(m.offset+m.visibleRows(), len(m.posts))
+	for i := m.offset; i < end; i++ {
+		post := m.posts[i]
+		cursor, mark := " ", "[ ]"
+		if i == m.cursor {
+			cursor = ">"
+		}
+		if m.selected[post.Post.BundlePath()] {
+			mark = "[x]"
+		}
+		written := "not converted"
+		if post.Converted() {
+			written = post.Written.Format("2006-01-02 15:04")
+		}
+		row := fmt.Sprintf("%s %s %-8s %-10s  %-*s  %-16s", cursor, mark, post.Post.Meta.Status, post.Post.Meta.Date,
+			dashboardTitleWidth, truncateTitle(post.Post.Meta.Title, dashboardTitleWidth), written)
+		if translated {
+			translations := "-"
+			if len(post.Languages) > 0 {
+				translations = fmt.Sprintf("%d/%d %s", len(post.Languages), len(translationLanguages), strings.Join(post.Languages, " "))
+			}
+			row += "  " + translations
+		}
+		b.WriteString(strings.TrimRight(row, " ") + "\n")
+	}
+	if len(m.posts) == 0 {
+		b.WriteString("  No posts found\n")
+	}
+
+	b.WriteString(m.message + "\n")
+	if translated {
+		b.WriteString("↑/↓ move  space select  a select all  c convert  t translate  r reload  q quit\n")
+	} else {
+		b.WriteString("↑/↓ move  space select  a select all  c convert  r reload  q quit\n")
+	}
+	return b.String()
+}
+
+// truncateTitle shortens a title to width characters.
+func truncateTitle(title string, width int) string {
+	runes := []rune(title)
+	if len(runes) <= width {
+		return title
+	}
+	return string(runes[:width-1]) + "…"
+}
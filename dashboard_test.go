@@ -0,0 +1,174 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"logseq-to-hugo-converter/internal/testgraph"
+)
+
+// newTestDashboard writes a graph with a converted, a translated and a draft post.
+func newTestDashboard(t *testing.T) *dashboard {
+	t.Helper()
+	dir := t.TempDir()
+	converted := testgraph.Post{Title: "Renan", Date: "2026-01-17", Blocks: []string{"Hiking."}}
+	translated := testgraph.Post{Title: "Ibiza", Date: "2026-02-01", Properties: []string{"language:: english"}, Blocks: []string{"Sailing."}}
+	draft := testgraph.Post{Title: "Plans", Date: "2026-03-01", Status: "draft", Blocks: []string{"Later."}}
+	graph := testgraph.New().
+		Page("Renan", converted.PageContent()).
+		Page("Ibiza", translated.PageContent()).
+		Page("Plans", draft.PageContent())
+	if err := graph.WriteDir(filepath.Join(dir, "graph")); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(dir, "posts")
+	writeTestFile(t, filepath.Join(output, "2026-01-17_Renan", "index.de.md"), "+++\n+++\n")
+	for _, language := range []string{"en", "de", "xx"} {
+		writeTestFile(t, filepath.Join(output, "2026-02-01_Ibiza", "index."+language+".md"), "+++\n+++\n")
+	}
+	return &dashboard{config: DefaultConfig(), graphDir: filepath.Join(dir, "graph"), outputDir: output, translate: []string{"true"}}
+}
+
+// TestDashboard_Load tests the publishing status of the posts of a graph
+func TestDashboard_Load(t *testing.T) {
+	d := newTestDashboard(t)
+	posts, err := d.load()
+	if err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+
+	type status struct {
+		Title     string
+		Converted bool
+		Languages []string
+	}
+	var got []status
+	for _, post := range posts {
+		got = append(got, status{post.Post.Meta.Title, post.Converted(), post.Languages})
+	}
+	want := []status{
+		{"Plans", false, nil},
+		{"Ibiza", true, []string{"de", "en", "xx"}},
+		{"Renan", true, []string{"de"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("load() = %+v, want %+v", got, want)
+	}
+	if posts[2].Written.IsZero() || posts[2].Index != filepath.Join(d.outputDir, "2026-01-17_Renan", "index.de.md") {
+		t.Errorf("converted post = %+v, want its index file and write time", posts[2])
+	}
+}
+
+// TestDashboard_Convert tests that only the selected posts are converted
+func TestDashboard_Convert(t *testing.T) {
+	d := newTestDashboard(t)
+	posts, err := d.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.RemoveAll(d.outputDir)
+
+	if err := d.convert(posts[1:2]); err != nil {
+		t.Fatalf("convert() error = %v", err)
+	}
+	entries, _ := os.ReadDir(d.outputDir)
+	if len(entries) != 1 || entries[0].Name() != "2026-02-01_Ibiza" {
+		t.Errorf("converted bundles = %v, want only 2026-02-01_Ibiza", entries)
+	}
+}
+
+// TestDashboardModel_Update tests moving through the list and selecting posts
+func TestDashboardModel_Update(t *testing.T) {
+	d := newTestDashboard(t)
+	posts, err := d.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var model tea.Model = newDashboardModel(d, posts)
+	press := func(key string) tea.Cmd {
+		var cmd tea.Cmd
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		switch key {
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		case "space":
+			msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+		}
+		model, cmd = model.Update(msg)
+		return cmd
+	}
+
+	press("down")
+	press("space") // Selects Ibiza and moves to Renan
+	m := model.(dashboardModel)
+	if m.cursor != 2 || !m.selected["2026-02-01_Ibiza"] || len(m.selected) != 1 {
+		t.Errorf("cursor = %d, selected = %v, want 2 and Ibiza", m.cursor, m.selected)
+	}
+	if targets := m.targets(); len(targets) != 1 || targets[0].Post.Meta.Title != "Ibiza" {
+		t.Errorf("targets() = %v, want the selected post", targets)
+	}
+
+	view := m.View()
+	for _, want := range []string{"3 posts, 2 converted, 1 selected", "> [ ] online", "[x] online   2026-02-01  Ibiza", "3/5 de en xx", "not converted"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() is missing %q:\n%s", want, view)
+		}
+	}
+
+	if cmd := press("c"); cmd == nil {
+		t.Error("c returns no command to convert the posts")
+	}
+	if cmd := press("q"); cmd == nil {
+		t.Error("q returns no command to quit")
+	}
+}
+
+// TestDashboardModel_Monolingual tests that a monolingual site has no translations
+func TestDashboardModel_Monolingual(t *testing.T) {
+	d := newTestDashboard(t)
+	d.translate = nil
+	posts, err := d.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	model, cmd := newDashboardModel(d, posts).Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	if cmd != nil {
+		t.Error("t returns a command to translate the posts")
+	}
+	view := model.View()
+	if !strings.Contains(view, "Translations are disabled") || strings.Contains(view, "3/5") || strings.Contains(view, "t translate") {
+		t.Errorf("View() shows translations of a monolingual site:\n%s", view)
+	}
+	if err := d.translateIndexes(posts); err == nil {
+		t.Error("translateIndexes() error = nil, want an error")
+	}
+}
+
+// TestDashboardModel_Scroll tests that the cursor stays on a small screen
+func TestDashboardModel_Scroll(t *testing.T) {
+	posts := make([]postStatus, 10)
+	for i := range posts {
+		posts[i] = postStatus{Post: &BlogPost{Slug: string(rune('a' + i))}}
+	}
+	var model tea.Model = newDashboardModel(&dashboard{}, posts)
+	model, _ = model.Update(tea.WindowSizeMsg{Height: 8}) // 3 posts fit
+	for i := 0; i < 5; i++ {
+		model, _ = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+
+	m := model.(dashboardModel)
+	if m.cursor != 5 || m.offset != 3 {
+		t.Errorf("cursor = %d, offset = %d, want 5 and 3", m.cursor, m.offset)
+	}
+	if rows := strings.Count(m.View(), "[ ]"); rows != 3 {
+		t.Errorf("View() shows %d posts, want 3", rows)
+	}
+}
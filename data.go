@@ -0,0 +1,78 @@
+// This file handles structured data properties like "temperature:: 18" or "rainfall:: 12".
+// Selected properties become a [params.data] section or a table at the top of the post.
+package main
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Data modes of DataConfig.Mode.
+const (
+	DataModeFrontMatter = "front_matter"
+	DataModeTable       = "table"
+)
+
+// dataFields returns the configured data properties of a post in the configured order.
+// Properties the post doesn't have are left out.
+func dataFields(properties map[string]string, config DataConfig) []DataField {
+	var fields []DataField
+	for _, property := range config.Properties {
+		key := normalizeKey(property)
+		value, ok := properties[key]
+		if !ok || value == "" {
+			continue
+		}
+		label := config.Labels[property]
+		if label == "" {
+			label = config.Labels[key]
+		}
+		if label == "" {
+			label = capitalize(strings.ReplaceAll(key, "-", " "))
+		}
+		fields = append(fields, DataField{Key: key, Label: label, Value: value})
+	}
+	return fields
+}
+
+// dataTable renders the data fields as a markdown table with one column per field:
+//
+//	| Temperature | Rainfall |
+//	| --- | --- |
+//	| 18 °C | 12 mm |
+func dataTable(fields []DataField) string {
+	var header, separator, values []string
+	for _, field := range fields {
+		header = append(header, escapeTableCell(field.Label))
+		separator = append(separator, "---")
+		values = append(values, escapeTableCell(field.Value))
+	}
+	return "| " + strings.Join(header, " | ") + " |\n" +
+		"| " + strings.Join(separator, " | ") + " |\n" +
+		"| " + strings.Join(values, " | ") + " |"
+}
+
+// dataValue converts a data value for the front matter: numbers stay numbers
+// (so templates can compare them), everything else is a string.
+func dataValue(value string) interface{} {
+	if number, err := strconv.Atoi(value); err == nil {
+		return number
+	}
+	if number, err := strconv.ParseFloat(value, 64); err == nil {
+		return number
+	}
+	return value
+}
+
+// escapeTableCell escapes the characters that would break a markdown table cell.
+func escapeTableCell(text string) string {
+	return strings.ReplaceAll(text, "|", `\|`)
+}
+
+// capitalize converts the first letter of text to upper case ("rainfall" -> "Rainfall").
+func capitalize(text string) string {
+	first, size := utf8.DecodeRuneInString(text)
+	return string(unicode.ToUpper(first)) + text[size:]
+}
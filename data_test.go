@@ -0,0 +1,59 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestDataFields tests selecting the data properties in the configured order
+func TestDataFields(t *testing.T) {
+	properties := map[string]string{"rainfall": "12 mm", "temperature": "18", "soil-moisture": "wet", "mood": "happy"}
+	config := DataConfig{
+		Properties: []string{"temperature", "soil_moisture", "rainfall", "wind"},
+		Labels:     map[string]string{"temperature": "Temperature (°C)"},
+	}
+
+	want := []DataField{
+		{Key: "temperature", Label: "Temperature (°C)", Value: "18"},
+		{Key: "soil-moisture", Label: "Soil moisture", Value: "wet"},
+		{Key: "rainfall", Label: "Rainfall", Value: "12 mm"},
+	}
+	if got := dataFields(properties, config); !reflect.DeepEqual(got, want) {
+		t.Errorf("dataFields() = %+v, want %+v", got, want)
+	}
+
+	if got := dataFields(properties, DataConfig{}); got != nil {
+		t.Errorf("dataFields() without properties = %+v, want nil", got)
+	}
+}
+
+// TestDataTable tests rendering the data fields as a markdown table
+func TestDataTable(t *testing.T) {
+	fields := []DataField{
+		{Key: "temperature", Label: "Temperature", Value: "18 °C"},
+		{Key: "weather", Label: "Weather", Value: "sun | clouds"},
+	}
+
+	want := "| Temperature | Weather |\n| --- | --- |\n| 18 °C | sun \\| clouds |"
+	if got := dataTable(fields); got != want {
+		t.Errorf("dataTable() = %q, want %q", got, want)
+	}
+}
+
+// TestDataValue tests keeping numbers as numbers in the front matter
+func TestDataValue(t *testing.T) {
+	tests := []struct {
+		value string
+		want  interface{}
+	}{
+		{"18", 18},
+		{"-2.5", -2.5},
+		{"12 mm", "12 mm"},
+	}
+
+	for _, tt := range tests {
+		if got := dataValue(tt.value); got != tt.want {
+			t.Errorf("dataValue(%q) = %#v, want %#v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// This file handles formatting the post dates for the front matter and the directory names.
+// Logseq dates are written as "2026-01-17", some themes need a full timestamp
+// like "2026-01-17T08:00:00+01:00" instead.
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// dateLayouts are the accepted formats of dates in properties ("date::", "expirydate::").
+var dateLayouts = []string{"2006-01-02", "2006-01-02 15:04", "2006-01-02T15:04", time.RFC3339}
+
+// parseDate parses a date property. Dates without a time zone are in loc.
+func parseDate(value string, loc *time.Location) (time.Time, bool) {
+	for _, layout := range dateLayouts {
+		if date, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// DateFormatter formats the dates of the posts according to the configuration.
+type DateFormatter struct {
+	config   DatesConfig
+	location *time.Location // Time zone of dates without one
+	clock    time.Duration  // Time of day for dates without a time
+}
+
+// NewDateFormatter creates a DateFormatter and checks the time zone and time of day.
+func NewDateFormatter(config DatesConfig) (*DateFormatter, error) {
+	formatter := &DateFormatter{config: config, location: time.Local}
+	if config.TimeZone != "" {
+		location, err := time.LoadLocation(config.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone: %w", err)
+		}
+		formatter.location = location
+	}
+	if config.Time != "" {
+		clock, err := time.Parse("15:04", config.Time)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time %q (use HH:MM)", config.Time)
+		}
+		formatter.clock = time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute
+	}
+	return formatter, nil
+}
+
+// FrontMatter formats a date for the front matter (date and lastmod).
+func (f *DateFormatter) FrontMatter(date string) string {
+	return f.format(date, f.config.Layout)
+}
+
+// Folder formats a date for the bundle directory name.
+func (f *DateFormatter) Folder(date string) string {
+	return f.format(date, f.config.FolderLayout)
+}
+
+// format formats a date with a Go time layout. Dates are kept as written
+// if no layout is configured or if they can't be parsed.
+func (f *DateFormatter) format(date, layout string) string {
+	if layout == "" {
+		return date
+	}
+	parsed, ok := parseDate(date, f.location)
+	if !ok {
+		return date
+	}
+	if len(date) == len("2006-01-02") {
+		parsed = parsed.Add(f.clock) // Date without a time
+	}
+	return parsed.In(f.location).Format(layout)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"testing"
+)
+
+// TestDateFormatter tests formatting dates for the front matter and the directory names
+func TestDateFormatter(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     DatesConfig
+		date       string
+		wantFront  string
+		wantFolder string
+	}{
+		{
+			name:       "no layouts keep the dates",
+			config:     DatesConfig{TimeZone: "Europe/Zurich"},
+			date:       "2026-01-17",
+			wantFront:  "2026-01-17",
+			wantFolder: "2026-01-17",
+		},
+		{
+			name:       "timestamp with time zone and time of day",
+			config:     DatesConfig{Layout: "2006-01-02T15:04:05-07:00", TimeZone: "Europe/Zurich", Time: "08:00"},
+			date:       "2026-01-17",
+			wantFront:  "2026-01-17T08:00:00+01:00",
+			wantFolder: "2026-01-17",
+		},
+		{
+			name:       "summer time",
+			config:     DatesConfig{Layout: "2006-01-02T15:04:05-07:00", TimeZone: "Europe/Zurich"},
+			date:       "2026-07-01 18:30",
+			wantFront:  "2026-07-01T18:30:00+02:00",
+			wantFolder: "2026-07-01 18:30",
+		},
+		{
+			name:       "dates with a time zone are converted",
+			config:     DatesConfig{Layout: "2006-01-02 15:04", TimeZone: "Europe/Zurich"},
+			date:       "2026-01-17T08:00:00Z",
+			wantFront:  "2026-01-17 09:00",
+			wantFolder: "2026-01-17T08:00:00Z",
+		},
+		{
+			name:       "separate folder layout",
+			config:     DatesConfig{Layout: "2006-01-02", FolderLayout: "20060102", TimeZone: "UTC"},
+			date:       "2026-01-17",
+			wantFront:  "2026-01-17",
+			wantFolder: "20260117",
+		},
+		{
+			name:       "invalid dates are kept",
+			config:     DatesConfig{Layout: "2006-01-02T15:04:05-07:00", FolderLayout: "20060102", TimeZone: "UTC"},
+			date:       "17.01.2026",
+			wantFront:  "17.01.2026",
+			wantFolder: "17.01.2026",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatter, err := NewDateFormatter(tt.config)
+			if err != nil {
+				t.Fatalf("NewDateFormatter() error = %v", err)
+			}
+			if got := formatter.FrontMatter(tt.date); got != tt.wantFront {
+				t.Errorf("FrontMatter(%q) = %q, want %q", tt.date, got, tt.wantFront)
+			}
+			if got := formatter.Folder(tt.date); got != tt.wantFolder {
+				t.Errorf("Folder(%q) = %q, want %q", tt.date, got, tt.wantFolder)
+			}
+		})
+	}
+}
+
+// TestNewDateFormatterErrors tests that invalid time zones and times are rejected
+func TestNewDateFormatterErrors(t *testing.T) {
+	for _, config := range []DatesConfig{
+		{TimeZone: "Mars/Olympus_Mons"},
+		{Time: "8 Uhr"},
+	} {
+		if _, err := NewDateFormatter(config); err == nil {
+			t.Errorf("NewDateFormatter(%+v) error = nil, want an error", config)
+		}
+	}
+}
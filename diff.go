@@ -0,0 +1,244 @@
+// This file handles the -diff mode of the conversion.
+// The conversion writes into memory like a dry run, and every written file is
+// compared with the file in the output directory: index files as unified
+// diffs, assets only by their content, so a re-conversion can be reviewed
+// before it changes the site.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// diffContext is the number of unchanged lines around the changes of a hunk.
+const diffContext = 3
+
+// writeOutputDiff writes the differences between the files written into memory
+// below outputDir and the files on disk, without the state files. Files on
+// disk the conversion removed (like the bundles of expired posts) are deleted.
+// It returns the number of changed files.
+func writeOutputDiff(w io.Writer, mem *MemFileSystem, outputDir string, state []string) (int, error) {
+	files, err := collectUploads(mem, outputDir, state, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for _, file := range files {
+		oldName, newName := "a/"+file.Name, "b/"+file.Name
+		old, err := os.ReadFile(filepath.Join(outputDir, filepath.FromSlash(file.Name)))
+		if os.IsNotExist(err) {
+			oldName = "/dev/null"
+		} else if err != nil {
+			return changed, err
+		}
+		if err == nil && bytes.Equal(old, file.Data) {
+			continue
+		}
+		changed++
+
+		if !isText(old) || !isText(file.Data) {
+			if _, err := fmt.Fprintf(w, "Binary files %s and %s differ\n", oldName, newName); err != nil {
+				return changed, err
+			}
+			continue
+		}
+		if _, err := io.WriteString(w, unifiedDiff(oldName, newName, string(old), string(file.Data))); err != nil {
+			return changed, err
+		}
+	}
+
+	deleted, err := removedFiles(mem, outputDir, state)
+	if err != nil {
+		return changed, err
+	}
+	for _, name := range deleted {
+		old, err := os.ReadFile(filepath.Join(outputDir, filepath.FromSlash(name)))
+		if err != nil {
+			return changed, err
+		}
+		changed++
+		if !isText(old) {
+			_, err = fmt.Fprintf(w, "Binary files a/%s and /dev/null differ\n", name)
+		} else {
+			_, err = io.WriteString(w, unifiedDiff("a/"+name, "/dev/null", string(old), ""))
+		}
+		if err != nil {
+			return changed, err
+		}
+	}
+	return changed, nil
+}
+
+// removedFiles returns the files on disk below outputDir the conversion removed
+// and didn't write again, as slash-separated paths relative to outputDir.
+// The state files are left out.
+func removedFiles(mem *MemFileSystem, outputDir string, state []string) ([]string, error) {
+	var names []string
+	for _, removed := range mem.Removed() {
+		if !isInsideDir(outputDir, removed) {
+			continue
+		}
+		err := filepath.WalkDir(removed, func(path string, entry os.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if entry.IsDir() || slices.Contains(state, entry.Name()) {
+				return nil
+			}
+			if _, err := mem.Stat(path); err == nil {
+				return nil // Written again
+			}
+			rel, err := filepath.Rel(outputDir, path)
+			if err != nil {
+				return err
+			}
+			names = append(names, filepath.ToSlash(rel))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return names, nil
+}
+
+// isText reports whether a file is text (UTF-8 without NUL bytes).
+func isText(data []byte) bool {
+	return utf8.Valid(data) && bytes.IndexByte(data, 0) < 0
+}
+
+// diffLine is a line of a diff: ' ' (unchanged), '-' (removed) or '+' (added).
+type diffLine struct {
+	kind byte
+	text string
+}
+
+// diffLines returns the lines of old and new as an edit script with the
+// fewest changes (longest common subsequence).
+func diffLines(old, new []string) []diffLine {
+	// common[i][j] is the length of the longest common subsequence of old[i:] and new[j:]
+	common := make([][]int, len(old)+1)
+	for i := range common {
+		common[i] = make([]int, len(new)+1)
+	}
+	for i := len(old) - 1; i >= 0; i-- {
+		for j := len(new) - 1; j >= 0; j-- {
+			if old[i] == new[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	var lines []diffLine
+	i, j := 0, 0
+	for i < len(old) || j < len(new) {
+		switch {
+		case i < len(old) && j < len(new) && old[i] == new[j]:
+			lines = append(lines, diffLine{' ', old[i]})
+			i++
+			j++
+		case j < len(new) && (i == len(old) || common[i][j+1] > common[i+1][j]):
+			lines = append(lines, diffLine{'+', new[j]})
+			j++
+		default:
+			lines = append(lines, diffLine{'-', old[i]})
+			i++
+		}
+	}
+	return lines
+}
+
+// splitLines splits a file into lines without their line breaks.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// unifiedDiff returns the differences of two texts in the unified format of
+// diff -u, with diffContext unchanged lines around the changes.
+// It returns "" if the texts are equal.
+func unifiedDiff(oldName, newName, old, new string) string {
+	lines := diffLines(splitLines(old), splitLines(new))
+
+	var b strings.Builder
+	for start := 0; start < len(lines); {
+		// The next change starts a hunk
+		first := start
+		for first < len(lines) && lines[first].kind == ' ' {
+			first++
+		}
+		if first == len(lines) {
+			break
+		}
+		if b.Len() == 0 {
+			fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+		}
+
+		// The hunk ends where more than twice the context is unchanged
+		last, unchanged := first, 0
+		for k := first; k < len(lines) && unchanged <= 2*diffContext; k++ {
+			if lines[k].kind == ' ' {
+				unchanged++
+			} else {
+				last, unchanged = k, 0
+			}
+		}
+		from := max(first-diffContext, start)
+		to := min(last+diffContext+1, len(lines))
+
+		// Line numbers of the hunk start: the lines before it in both files
+		oldLine, newLine := 0, 0
+		for _, line := range lines[:from] {
+			if line.kind != '+' {
+				oldLine++
+			}
+			if line.kind != '-' {
+				newLine++
+			}
+		}
+		oldCount, newCount := 0, 0
+		for _, line := range lines[from:to] {
+			if line.kind != '+' {
+				oldCount++
+			}
+			if line.kind != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(oldLine, oldCount), hunkRange(newLine, newCount))
+		for _, line := range lines[from:to] {
+			b.WriteByte(line.kind)
+			b.WriteString(line.text)
+			b.WriteByte('\n')
+		}
+		start = to
+	}
+	return b.String()
+}
+
+// hunkRange formats the range of a hunk like diff -u: the first line and the
+// number of lines, which is left out if it is 1. Empty ranges start at the
+// line before them.
+func hunkRange(before, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", before)
+	case 1:
+		return fmt.Sprintf("%d", before+1)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
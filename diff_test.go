@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestUnifiedDiff tests hunks, context lines and line ranges like diff -u
+func TestUnifiedDiff(t *testing.T) {
+	lines := func(s string) string { return strings.Join(strings.Split(s, ""), "\n") + "\n" }
+
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		want string
+	}{
+		{
+			name: "equal",
+			old:  lines("abc"),
+			new:  lines("abc"),
+			want: "",
+		},
+		{
+			name: "two hunks",
+			old:  lines("abcdefghijklm"),
+			new:  lines("aBcdefghijklmn"),
+			want: "--- a/x\n+++ b/x\n" +
+				"@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n d\n e\n" +
+				"@@ -11,3 +11,4 @@\n k\n l\n m\n+n\n",
+		},
+		{
+			name: "close changes in one hunk",
+			old:  lines("abcdefgh"),
+			new:  lines("aBcdefgH"),
+			want: "--- a/x\n+++ b/x\n" +
+				"@@ -1,8 +1,8 @@\n a\n-b\n+B\n c\n d\n e\n f\n g\n-h\n+H\n",
+		},
+		{
+			name: "new file",
+			old:  "",
+			new:  lines("ab"),
+			want: "--- a/x\n+++ b/x\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name: "removed file",
+			old:  lines("a"),
+			new:  "",
+			want: "--- a/x\n+++ b/x\n@@ -1 +0,0 @@\n-a\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff("a/x", "b/x", tt.old, tt.new); got != tt.want {
+				t.Errorf("unifiedDiff() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestWriteOutputDiff tests comparing the files of a conversion with the output directory
+func TestWriteOutputDiff(t *testing.T) {
+	outputDir := t.TempDir()
+	bundle := filepath.Join(outputDir, "2026-01-17_Ibiza")
+	if err := os.MkdirAll(bundle, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"index.de.md": "+++\ntitle = \"Ibiza\"\n+++\n\nAlt\n",
+		"hafen.jpg":   "\xff\xd8jpg",
+		"same.md":     "Gleich\n",
+	} {
+		if err := os.WriteFile(filepath.Join(bundle, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mem := NewMemFileSystem(OSFileSystem{})
+	mem.WriteFile(filepath.Join(bundle, "index.de.md"), []byte("+++\ntitle = \"Ibiza\"\n+++\n\nNeu\n"))
+	mem.WriteFile(filepath.Join(bundle, "hafen.jpg"), []byte("\xff\xd8jpeg"))
+	mem.WriteFile(filepath.Join(bundle, "same.md"), []byte("Gleich\n"))
+	mem.WriteFile(filepath.Join(outputDir, "2026-01-18_Renan", "index.de.md"), []byte("Renan\n"))
+	mem.WriteFile(filepath.Join(outputDir, "2026-01-18_Renan", GeneratedFile), []byte("{}\n"))
+
+	var out bytes.Buffer
+	changed, err := writeOutputDiff(&out, mem, outputDir, stateFiles(DefaultConfig()))
+	if err != nil {
+		t.Fatalf("writeOutputDiff() error = %v", err)
+	}
+	want := "Binary files a/2026-01-17_Ibiza/hafen.jpg and b/2026-01-17_Ibiza/hafen.jpg differ\n" +
+		"--- a/2026-01-17_Ibiza/index.de.md\n+++ b/2026-01-17_Ibiza/index.de.md\n" +
+		"@@ -2,4 +2,4 @@\n title = \"Ibiza\"\n +++\n \n-Alt\n+Neu\n" +
+		"--- /dev/null\n+++ b/2026-01-18_Renan/index.de.md\n@@ -0,0 +1 @@\n+Renan\n"
+	if changed != 3 || out.String() != want {
+		t.Errorf("writeOutputDiff() = %d files:\n%s\nwant 3 files:\n%s", changed, out.String(), want)
+	}
+
+	// Removed bundles are deleted, the files written again are compared
+	mem = NewMemFileSystem(OSFileSystem{})
+	mem.RemoveAll(bundle)
+	mem.WriteFile(filepath.Join(bundle, "same.md"), []byte("Gleich\n"))
+	out.Reset()
+	changed, err = writeOutputDiff(&out, mem, outputDir, stateFiles(DefaultConfig()))
+	if err != nil {
+		t.Fatalf("writeOutputDiff() error = %v", err)
+	}
+	want = "Binary files a/2026-01-17_Ibiza/hafen.jpg and /dev/null differ\n" +
+		"--- a/2026-01-17_Ibiza/index.de.md\n+++ /dev/null\n" +
+		"@@ -1,5 +0,0 @@\n-+++\n-title = \"Ibiza\"\n-+++\n-\n-Alt\n"
+	if changed != 2 || out.String() != want {
+		t.Errorf("writeOutputDiff() = %d files:\n%s\nwant 2 files:\n%s", changed, out.String(), want)
+	}
+}
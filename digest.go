@@ -0,0 +1,136 @@
+// This file handles the digest of an import-all run: the titles, summaries and
+// links of the converted posts, rendered with a template as Markdown or HTML
+// for a newsletter (e.g. the posts of the last month).
+package main
+
+import (
+	"bytes"
+	"cmp"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Digest is the data of the digest template.
+type Digest struct {
+	Title string       // Heading of the digest (DigestConfig.Title)
+	Since time.Time    // Posts dated before are left out (zero = all posts)
+	Posts []DigestPost // Converted posts, the newest first
+}
+
+// DigestPost is a converted post in the digest.
+type DigestPost struct {
+	Title   string
+	URL     string
+	Date    time.Time // Zero if the post has no date
+	Lang    string
+	Summary string // Plain text of the summary or meta description
+	Tags    []string
+	Path    string // Bundle path below the output directory (e.g. "trips/2026-01-17_Ibiza")
+}
+
+// digestMarkdown is the built-in Markdown template.
+const digestMarkdown = `# {{.Title}}
+{{range .Posts}}
+## [{{.Title}}]({{.URL}})
+{{if not .Date.IsZero}}
+*{{.Date.Format "2006-01-02"}}{{if .Tags}} · {{join .Tags ", "}}{{end}}*
+{{end}}{{with .Summary}}
+{{.}}
+{{end}}{{else}}
+No new posts.
+{{end}}`
+
+// digestHTML is the built-in HTML template, for digests written to .html files.
+const digestHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{range .Posts}}
+<h2><a href="{{.URL}}">{{.Title}}</a></h2>
+{{if not .Date.IsZero}}<p><small>{{.Date.Format "2006-01-02"}}{{if .Tags}} · {{join .Tags ", "}}{{end}}</small></p>
+{{end}}{{with .Summary}}<p>{{.}}</p>
+{{end}}{{else}}
+<p>No new posts.</p>
+{{end}}
+</body>
+</html>`
+
+// digestFuncs are the functions of the digest templates besides the built-in ones.
+var digestFuncs = map[string]any{"join": strings.Join}
+
+// isHTMLFile reports whether a digest is written as HTML (by its extension).
+func isHTMLFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".html" || ext == ".htm"
+}
+
+// addDigestPost adds a written post to the digest of the conversion.
+// Posts dated before the start of the digest are left out.
+func (c *Converter) addDigestPost(post *BlogPost) {
+	date, _ := parseDate(post.Meta.Date, time.Local)
+	if !c.digest.Since.IsZero() && !date.IsZero() && date.Before(c.digest.Since) {
+		return
+	}
+	c.digest.Posts = append(c.digest.Posts, DigestPost{
+		Title:   post.Meta.Title,
+		URL:     publishedURL(c.config.Digest.URL, post),
+		Date:    date,
+		Lang:    languageKey(post.Meta.Language, c.config.Language.Keys),
+		Summary: searchText(cmp.Or(post.Meta.Description, post.Meta.Summary)),
+		Tags:    post.Meta.Tags,
+		Path:    post.BundlePath(),
+	})
+}
+
+// renderDigest renders a digest with the template file, or the built-in
+// template if it is empty. HTML digests escape the data like html/template.
+func renderDigest(w io.Writer, digest *Digest, templatePath string, html bool) error {
+	posts := slices.Clone(digest.Posts)
+	slices.SortStableFunc(posts, func(a, b DigestPost) int {
+		return cmp.Or(b.Date.Compare(a.Date), cmp.Compare(a.Title, b.Title), cmp.Compare(a.Lang, b.Lang))
+	})
+	data := *digest
+	data.Posts = posts
+
+	source := digestMarkdown
+	if html {
+		source = digestHTML
+	}
+	if templatePath != "" {
+		content, err := os.ReadFile(templatePath)
+		if err != nil {
+			return err
+		}
+		source = string(content)
+	}
+
+	var tmpl interface {
+		Execute(io.Writer, any) error
+	}
+	var err error
+	if html {
+		tmpl, err = htmltemplate.New("digest").Funcs(digestFuncs).Parse(source)
+	} else {
+		tmpl, err = template.New("digest").Funcs(digestFuncs).Parse(source)
+	}
+	if err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, strings.TrimSpace(b.String())+"\n")
+	return err
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestAddDigestPost tests collecting the posts of a conversion since a date
+func TestAddDigestPost(t *testing.T) {
+	config := DefaultConfig()
+	config.Digest.URL = "https://example.com/posts/"
+	c := NewConverter(config, NewMemFileSystem(nil))
+	c.digest = &Digest{Title: "Neu im Blog", Since: time.Date(2026, 1, 10, 0, 0, 0, 0, time.Local)}
+
+	for _, post := range []*BlogPost{
+		{Meta: BlogMeta{Title: "Ibiza", Date: "2026-01-17", Language: "german", Summary: "Wir segelten nach [[Ibiza]]."}, Slug: "2026-01-17_Ibiza"},
+		{Meta: BlogMeta{Title: "Old", Date: "2026-01-02", Language: "german"}, Slug: "2026-01-02_Old"},
+		{Meta: BlogMeta{Title: "Undated", Language: "english"}, Slug: "Undated"},
+	} {
+		c.addDigestPost(post)
+	}
+
+	if len(c.digest.Posts) != 2 {
+		t.Fatalf("Digest has %d posts, want the post since the date and the undated one: %+v", len(c.digest.Posts), c.digest.Posts)
+	}
+	got := c.digest.Posts[0]
+	if got.URL != "https://example.com/posts/2026-01-17_ibiza/" || got.Summary != "Wir segelten nach Ibiza." || got.Lang != "de" || got.Date.Day() != 17 {
+		t.Errorf("Digest post = %+v", got)
+	}
+}
+
+// TestRenderDigest tests the built-in Markdown and HTML templates and a template file
+func TestRenderDigest(t *testing.T) {
+	digest := &Digest{Title: "Neu im Blog", Posts: []DigestPost{
+		{Title: "Old", URL: "/old/", Date: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{Title: "Fish & Chips", URL: "/fish/", Date: time.Date(2026, 1, 17, 0, 0, 0, 0, time.UTC), Summary: "<b>Lecker</b>", Tags: []string{"Essen", "UK"}},
+	}}
+
+	var markdown strings.Builder
+	if err := renderDigest(&markdown, digest, "", false); err != nil {
+		t.Fatalf("renderDigest() error: %v", err)
+	}
+	want := "# Neu im Blog\n\n## [Fish & Chips](/fish/)\n\n*2026-01-17 · Essen, UK*\n\n<b>Lecker</b>\n\n## [Old](/old/)\n\n*2026-01-02*\n"
+	if markdown.String() != want {
+		t.Errorf("Markdown digest =\n%s\nwant\n%s", markdown.String(), want)
+	}
+
+	var html strings.Builder
+	if err := renderDigest(&html, digest, "", true); err != nil {
+		t.Fatalf("renderDigest() error: %v", err)
+	}
+	for _, want := range []string{`<h2><a href="/fish/">Fish &amp; Chips</a></h2>`, "<p>&lt;b&gt;Lecker&lt;/b&gt;</p>"} {
+		if !strings.Contains(html.String(), want) {
+			t.Errorf("HTML digest is missing %q:\n%s", want, html.String())
+		}
+	}
+
+	var empty strings.Builder
+	if err := renderDigest(&empty, &Digest{Title: "Neu im Blog"}, "", false); err != nil || !strings.Contains(empty.String(), "No new posts.") {
+		t.Errorf("Empty digest = %q, %v", empty.String(), err)
+	}
+
+	path := filepath.Join(t.TempDir(), "newsletter.tmpl")
+	os.WriteFile(path, []byte(`{{range .Posts}}- {{.Title}}: {{.URL}}{{"\n"}}{{end}}`), 0644)
+	var custom strings.Builder
+	if err := renderDigest(&custom, digest, path, false); err != nil {
+		t.Fatalf("renderDigest() with a template error: %v", err)
+	}
+	if custom.String() != "- Fish & Chips: /fish/\n- Old: /old/\n" {
+		t.Errorf("Digest with a template = %q", custom.String())
+	}
+}
+
+// TestIsHTMLFile tests choosing the format of the digest
+func TestIsHTMLFile(t *testing.T) {
+	if !isHTMLFile("digest.HTML") || isHTMLFile("digest.md") {
+		t.Error("isHTMLFile() should only accept .html and .htm files")
+	}
+}
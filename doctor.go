@@ -0,0 +1,462 @@
+// This file implements the "doctor" subcommand. It checks the environment and
+// the inputs of a conversion (the configuration, the graph, the output
+// directory, the tools and the API keys) and explains how to fix what's wrong,
+// which answers the most common questions about a failing setup at once.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// doctorStatus is the result of a single check.
+type doctorStatus int
+
+const (
+	doctorOK   doctorStatus = iota // Everything is fine
+	doctorWarn                     // Works, but something is missing or looks wrong
+	doctorFail                     // The conversion won't work
+)
+
+// String returns the label the status is printed with.
+func (s doctorStatus) String() string {
+	switch s {
+	case doctorWarn:
+		return "WARN"
+	case doctorFail:
+		return "FAIL"
+	default:
+		return "OK"
+	}
+}
+
+// doctorCheck is the result of a check with the fix for a problem.
+type doctorCheck struct {
+	Status  doctorStatus
+	Name    string // What was checked ("Graph")
+	Message string // What was found
+	Fix     string // How to fix a problem ("" if there is nothing to do)
+}
+
+// String formats the check for the terminal, the fix on its own line.
+func (c doctorCheck) String() string {
+	line := fmt.Sprintf("%-4s  %s: %s", c.Status, c.Name, c.Message)
+	if c.Fix != "" {
+		line += "\n      Fix: " + c.Fix
+	}
+	return line
+}
+
+// doctorEnv is what the checks look at. The environment is
+// replaceable, so the checks can be tested without the real tools.
+type doctorEnv struct {
+	ConfigPath string // Configuration file ("" = converter.toml if it exists)
+	GraphDir   string // Graph directory or Notion export ("" = not checked)
+	OutputDir  string // Output directory ("" = not checked)
+	GOOS       string // Operating system, selects the file watcher tool
+
+	lookPath func(file string) (string, error) // Finds a program on the PATH
+	getenv   func(key string) string           // Reads an environment variable
+}
+
+// doctorIssueLimit is the number of extraction issues listed.
+const doctorIssueLimit = 5
+
+// runDoctor runs the doctor subcommand and returns the process exit code.
+// Usage: go run . doctor [-config converter.toml] [graph_directory] [output_directory]
+func runDoctor(args []string) int {
+	flags := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	configPath := flags.String("config", "", "path to a converter.toml configuration file (default converter.toml if it exists)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() > 2 {
+		fmt.Println("Usage: go run . doctor [-config converter.toml] [graph_directory] [output_directory]")
+		return 2
+	}
+
+	env := doctorEnv{
+		ConfigPath: *configPath,
+		GraphDir:   flags.Arg(0),
+		OutputDir:  flags.Arg(1),
+		GOOS:       runtime.GOOS,
+		lookPath:   exec.LookPath,
+		getenv:     os.Getenv,
+	}
+	checks := diagnose(env)
+
+	warnings, failures := 0, 0
+	for _, check := range checks {
+		fmt.Println(check)
+		switch check.Status {
+		case doctorWarn:
+			warnings++
+		case doctorFail:
+			failures++
+		}
+	}
+	fmt.Printf("%d checks: %d warnings, %d failures\n", len(checks), warnings, failures)
+
+	if failures > 0 {
+		return 1
+	}
+	return 0
+}
+
+// diagnose runs all checks. A configuration that can't be loaded
+// is reported, the other checks use the defaults then.
+func diagnose(env doctorEnv) []doctorCheck {
+	config, configCheck := checkConfigFile(env.ConfigPath)
+	checks := []doctorCheck{configCheck}
+	checks = append(checks, checkGraph(env.GraphDir, config)...)
+	checks = append(checks, checkOutputDir(env.OutputDir))
+	checks = append(checks, checkTools(env, config)...)
+	checks = append(checks, checkAPIKeys(env, config)...)
+	return checks
+}
+
+// checkConfigFile loads the configuration. Without a path, converter.toml
+// in the current directory is used if there is one.
+func checkConfigFile(path string) (*Config, doctorCheck) {
+	check := doctorCheck{Name: "Configuration"}
+	if path == "" {
+		if _, err := os.Stat("converter.toml"); err != nil {
+			check.Message = "no converter.toml, the defaults are used"
+			return DefaultConfig(), check
+		}
+		path = "converter.toml"
+	}
+
+	config, err := LoadConfig(path)
+	var configErr *ConfigError
+	switch {
+	case errors.As(err, &configErr):
+		check.Status = doctorFail
+		check.Message = fmt.Sprintf("%s has %d issues, e.g. %s", path, len(configErr.Issues), configErr.Issues[0])
+		check.Fix = fmt.Sprintf("run \"go run . config validate %s\" for all issues", path)
+		return DefaultConfig(), check
+	case err != nil:
+		check.Status = doctorFail
+		check.Message = err.Error()
+		check.Fix = "check the path given with -config"
+		return DefaultConfig(), check
+	}
+	check.Message = path + " is valid"
+	return config, check
+}
+
+// checkGraph checks that the graph can be read and that posts can be extracted from it.
+func checkGraph(dir string, config *Config) []doctorCheck {
+	check := doctorCheck{Name: "Graph"}
+	if dir == "" {
+		check.Status = doctorWarn
+		check.Message = "no graph directory given, the graph isn't checked"
+		check.Fix = "run \"go run . doctor <graph_directory> <output_directory>\""
+		return []doctorCheck{check}
+	}
+
+	// Notion exports are checked like the conversion reads them
+	if strings.EqualFold(filepath.Ext(dir), ".zip") {
+		exportDir, cleanup, err := unzipExport(dir)
+		if err != nil {
+			check.Status = doctorFail
+			check.Message = fmt.Sprintf("%s: %v", dir, err)
+			check.Fix = "export the Notion workspace again as \"Markdown & CSV\""
+			return []doctorCheck{check}
+		}
+		defer cleanup()
+		dir = exportDir
+	}
+
+	info, err := os.Stat(dir)
+	switch {
+	case err != nil:
+		check.Status = doctorFail
+		check.Message = fmt.Sprintf("%s can't be read: %v", dir, err)
+		check.Fix = "check the path of the graph"
+		return []doctorCheck{check}
+	case !info.IsDir():
+		check.Message = dir + " is a single file"
+	}
+
+	fsys := OSFileSystem{}
+	files := []string{dir}
+	format := "Logseq"
+	var extractor Extractor = logseqGraph{}
+	if info.IsDir() {
+		if _, err := os.ReadDir(dir); err != nil {
+			check.Status = doctorFail
+			check.Message = fmt.Sprintf("%s can't be read: %v", dir, err)
+			check.Fix = "give the user running the converter read permissions on the graph"
+			return []doctorCheck{check}
+		}
+		if extractor, err = openExtractor(fsys, dir, config); err != nil {
+			check.Status = doctorFail
+			check.Message = err.Error()
+			return []doctorCheck{check}
+		}
+		switch extractor.(type) {
+		case *obsidianVault:
+			format = "Obsidian"
+		case notionExport:
+			format = "Notion"
+		}
+		if files, err = findMarkdownFiles(fsys, dir); err != nil {
+			check.Status = doctorFail
+			check.Message = err.Error()
+			return []doctorCheck{check}
+		}
+		if len(files) == 0 {
+			check.Status = doctorFail
+			check.Message = dir + " has no markdown files"
+			check.Fix = "newer Logseq versions keep the graph in a database, convert it with -api (see the README)"
+			return []doctorCheck{check}
+		}
+		check.Message = fmt.Sprintf("%s graph with %d markdown files", format, len(files))
+	}
+
+	types, err := newContentTypes(config)
+	if err != nil {
+		check.Status = doctorFail
+		check.Message = err.Error()
+		return []doctorCheck{check}
+	}
+
+	// Extract the posts like the conversion does, without converting them
+	var posts []*BlogPost
+	var issues, unreadable []string
+	for _, file := range files {
+		if isWhiteboardFile(file) {
+			continue
+		}
+		source, err := readFile(fsys, file)
+		if err != nil {
+			unreadable = append(unreadable, file)
+			continue
+		}
+		source = sanitizeSource(source)
+		for _, contentType := range types {
+			found, fileIssues := extractor.Extract(file, source, contentType.marker)
+			for _, issue := range fileIssues {
+				issues = append(issues, fmt.Sprintf("%s:%d: %s", file, issue.Line, issue.Message))
+			}
+			for _, post := range found {
+				post.SourcePath = file
+				post.Type = contentType.config
+			}
+			posts = append(posts, found...)
+		}
+	}
+	if len(unreadable) > 0 {
+		check.Status = doctorWarn
+		check.Message += fmt.Sprintf(", %d can't be read (%s)", len(unreadable), strings.Join(limitList(unreadable, doctorIssueLimit), ", "))
+		check.Fix = "give the user running the converter read permissions on these files"
+	}
+
+	return []doctorCheck{check, checkPosts(posts, issues, config)}
+}
+
+// checkPosts checks the extracted posts and names one that will be converted as an example.
+func checkPosts(posts []*BlogPost, issues []string, config *Config) doctorCheck {
+	check := doctorCheck{Name: "Posts"}
+	if len(posts) == 0 {
+		check.Status = doctorFail
+		check.Message = fmt.Sprintf("no post found with the %q marker", config.Marker)
+		check.Fix = fmt.Sprintf("add \"%s\" to the properties of a post, or set the marker the graph uses in converter.toml (\"go run . init\" finds it)", config.Marker)
+		if len(issues) > 0 {
+			check.Message += fmt.Sprintf(", %d malformed: %s", len(issues), strings.Join(limitList(issues, doctorIssueLimit), "; "))
+		}
+		return check
+	}
+
+	var example *BlogPost
+	online := 0
+	for _, post := range posts {
+		if post.Meta.Status == "online" {
+			if online == 0 {
+				example = post
+			}
+			online++
+		}
+	}
+	check.Message = fmt.Sprintf("%d found, %d online", len(posts), online)
+	if example != nil {
+		check.Message += fmt.Sprintf(", e.g. %s %q (%s) in %s", typeName(example), example.Meta.Title, example.Meta.Date, example.SourcePath)
+	}
+
+	switch {
+	case len(issues) > 0:
+		check.Status = doctorWarn
+		check.Message += fmt.Sprintf("; %d malformed posts are skipped: %s", len(issues), strings.Join(limitList(issues, doctorIssueLimit), "; "))
+		check.Fix = "fix the properties of these posts (every property on its own line, directly below the marker)"
+	case online == 0:
+		check.Status = doctorWarn
+		check.Fix = "set \"status:: online\" on the posts that should be published"
+	case example.Meta.Title == "" || example.Meta.Date == "":
+		check.Status = doctorWarn
+		check.Fix = "add the title:: and date:: properties, which every post needs"
+	}
+	return check
+}
+
+// checkOutputDir checks that the output directory can be written to.
+// A missing directory is fine if it can be created.
+func checkOutputDir(dir string) doctorCheck {
+	check := doctorCheck{Name: "Output directory"}
+	if dir == "" {
+		check.Status = doctorWarn
+		check.Message = "no output directory given, it isn't checked"
+		check.Fix = "run \"go run . doctor <graph_directory> <output_directory>\""
+		return check
+	}
+
+	// The nearest existing directory is where the bundles (or their parents) are created
+	existing := dir
+	for {
+		if _, err := os.Stat(existing); err == nil || filepath.Dir(existing) == existing {
+			break
+		}
+		existing = filepath.Dir(existing)
+	}
+	info, err := os.Stat(existing)
+	if err != nil || !info.IsDir() {
+		check.Status = doctorFail
+		check.Message = existing + " is not a directory"
+		check.Fix = "give the directory of the site's posts (e.g. ../hugo-data/content/posts)"
+		return check
+	}
+
+	probe, err := os.CreateTemp(existing, ".doctor-")
+	if err != nil {
+		check.Status = doctorFail
+		check.Message = fmt.Sprintf("%s is not writable: %v", existing, err)
+		check.Fix = "give the user running the converter write permissions on " + existing
+		return check
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	if existing == dir {
+		check.Message = dir + " is writable"
+	} else {
+		check.Message = fmt.Sprintf("%s will be created in %s", dir, existing)
+	}
+	return check
+}
+
+// checkTools checks for Hugo, the file watcher tools, ffmpeg (if videos are
+// transcoded) and the commands of the hooks.
+func checkTools(env doctorEnv, config *Config) []doctorCheck {
+	hugo := doctorCheck{Name: "Hugo"}
+	if path, err := env.lookPath("hugo"); err == nil {
+		hugo.Message = "found at " + path
+	} else {
+		hugo.Status = doctorWarn
+		hugo.Message = "hugo is not on the PATH, the site can't be previewed"
+		hugo.Fix = "install Hugo (brew install hugo, sudo apt install hugo) to preview the converted posts with \"hugo server\""
+	}
+	checks := []doctorCheck{hugo}
+
+	watcher := doctorCheck{Name: "File watcher"}
+	tool, install := "inotifywait", "sudo apt install inotify-tools"
+	if env.GOOS == "darwin" {
+		tool, install = "fswatch", "brew install fswatch"
+	}
+	var missing []string
+	for _, program := range []string{tool, "git"} {
+		if _, err := env.lookPath(program); err != nil {
+			missing = append(missing, program)
+		}
+	}
+	if len(missing) == 0 {
+		watcher.Message = tool + " and git found"
+	} else {
+		watcher.Status = doctorWarn
+		watcher.Message = strings.Join(missing, " and ") + " not found, the watch-and-convert script won't work"
+		watcher.Fix = install
+		if missing[len(missing)-1] == "git" {
+			watcher.Fix = "install " + strings.Join(missing, " and ")
+		}
+	}
+	checks = append(checks, watcher)
+
+	if config.Video.Transcode.Enabled {
+		check := doctorCheck{Name: "Video transcoding"}
+		if transcoder, err := NewVideoTranscoder(config.Video.Transcode, env.lookPath); err != nil {
+			check.Status = doctorWarn
+			check.Message = err.Error() + ", videos are copied without transcoding"
+			check.Fix = "install ffmpeg (brew install ffmpeg, sudo apt install ffmpeg) or set video.transcode.ffmpeg"
+		} else {
+			check.Message = "ffmpeg found at " + transcoder.ffmpeg
+		}
+		checks = append(checks, check)
+	}
+
+	for _, hook := range config.Hooks {
+		if len(hook.Command) == 0 {
+			continue
+		}
+		check := doctorCheck{Name: "Hook " + hook.name()}
+		if _, err := env.lookPath(hook.Command[0]); err != nil {
+			check.Status = doctorWarn
+			if hook.OnFailure == HookFailureFail {
+				check.Status = doctorFail
+			}
+			check.Message = hook.Command[0] + " not found"
+			check.Fix = "install " + hook.Command[0] + " or remove the hook from converter.toml"
+		} else {
+			check.Message = hook.Command[0] + " found"
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// checkAPIKeys checks the API keys of the language model and the Logseq API.
+// A missing OpenAI key fails if the configuration uses the language model.
+func checkAPIKeys(env doctorEnv, config *Config) []doctorCheck {
+	var features []string
+	if config.AltText.Enabled {
+		features = append(features, "alt_text")
+	}
+	if config.Summary.Generate {
+		features = append(features, "summary")
+	}
+	if config.Proofread.Enabled && config.Proofread.Method == ProofreadLLM {
+		features = append(features, "proofread")
+	}
+
+	openAI := doctorCheck{Name: "OPENAI_API_KEY"}
+	switch {
+	case env.getenv("OPENAI_API_KEY") != "":
+		openAI.Message = "set"
+	case len(features) > 0:
+		openAI.Status = doctorFail
+		openAI.Message = "not set, but needed by " + strings.Join(features, ", ") + " in the configuration"
+		openAI.Fix = "export OPENAI_API_KEY=... or disable these options"
+	default:
+		openAI.Status = doctorWarn
+		openAI.Message = "not set, suggest-tags, -generate-summary and the translation tool won't work"
+		openAI.Fix = "export OPENAI_API_KEY=... if you use them"
+	}
+
+	logseq := doctorCheck{Name: "LOGSEQ_API_TOKEN", Message: "set"}
+	if env.getenv("LOGSEQ_API_TOKEN") == "" {
+		logseq.Message = "not set (only needed to convert graphs with -api)"
+	}
+	return []doctorCheck{openAI, logseq}
+}
+
+// limitList returns the first max items of list, followed by "..." if there are more.
+func limitList(list []string, max int) []string {
+	if len(list) <= max {
+		return list
+	}
+	return append(append([]string(nil), list[:max]...), "...")
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"logseq-to-hugo-converter/internal/testgraph"
+)
+
+// TestDiagnose tests the checks on graphs, output directories and environments with problems
+func TestDiagnose(t *testing.T) {
+	online := testgraph.Post{Title: "Spring", Date: "2026-03-01", Blocks: []string{"Text."}}
+	draft := testgraph.Post{Title: "Draft", Date: "2026-03-02", Status: "draft", Blocks: []string{"Text."}}
+
+	tests := []struct {
+		name    string
+		graph   *testgraph.Graph // nil = graph directory not given
+		config  string           // converter.toml ("" = none)
+		env     map[string]string
+		missing []string // Programs not on the PATH
+		want    map[string]doctorStatus
+		message map[string]string // Part of the message of a check
+	}{
+		{
+			name:  "everything fine",
+			graph: testgraph.New().Page("Spring", online.PageContent()),
+			env:   map[string]string{"OPENAI_API_KEY": "key"},
+			want: map[string]doctorStatus{
+				"Configuration": doctorOK, "Graph": doctorOK, "Posts": doctorOK, "Output directory": doctorOK,
+				"Hugo": doctorOK, "File watcher": doctorOK, "OPENAI_API_KEY": doctorOK, "LOGSEQ_API_TOKEN": doctorOK,
+			},
+			message: map[string]string{"Posts": `1 found, 1 online, e.g. blog post "Spring" (2026-03-01)`},
+		},
+		{
+			name:    "no posts with the marker",
+			graph:   testgraph.New().Page("Spring", online.PageContent()),
+			config:  "marker = \"#blog\"\n",
+			want:    map[string]doctorStatus{"Configuration": doctorOK, "Posts": doctorFail},
+			message: map[string]string{"Posts": `no post found with the "#blog" marker`},
+		},
+		{
+			name:    "only drafts",
+			graph:   testgraph.New().Page("Draft", draft.PageContent()),
+			want:    map[string]doctorStatus{"Posts": doctorWarn},
+			message: map[string]string{"Posts": "1 found, 0 online"},
+		},
+		{
+			name:    "empty graph",
+			graph:   testgraph.New().Asset("assets/photo.png", testgraph.PNG(2, 2)),
+			want:    map[string]doctorStatus{"Graph": doctorFail},
+			message: map[string]string{"Graph": "has no markdown files"},
+		},
+		{
+			name:    "invalid configuration",
+			config:  "[toc]\nmod = \"shortcode\"\n",
+			want:    map[string]doctorStatus{"Configuration": doctorFail, "Graph": doctorWarn},
+			message: map[string]string{"Configuration": `unknown key "toc.mod"`},
+		},
+		{
+			name:    "language model without API key",
+			config:  "[alt_text]\nenabled = true\n",
+			want:    map[string]doctorStatus{"OPENAI_API_KEY": doctorFail},
+			message: map[string]string{"OPENAI_API_KEY": "needed by alt_text"},
+		},
+		{
+			name:    "missing tools",
+			config:  "[[hooks]]\ncommand = [\"jpegoptim\"]\non_failure = \"fail\"\n",
+			missing: []string{"hugo", "git", "jpegoptim"},
+			want:    map[string]doctorStatus{"Hugo": doctorWarn, "File watcher": doctorWarn, "Hook 'jpegoptim'": doctorFail, "OPENAI_API_KEY": doctorWarn},
+			message: map[string]string{"File watcher": "git not found"},
+		},
+		{
+			name:    "video transcoding without ffmpeg",
+			config:  "[video.transcode]\nenabled = true\n",
+			missing: []string{"ffmpeg"},
+			want:    map[string]doctorStatus{"Video transcoding": doctorWarn},
+			message: map[string]string{"Video transcoding": "ffmpeg not found"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			env := doctorEnv{
+				OutputDir: filepath.Join(dir, "site", "content", "posts"),
+				GOOS:      "linux",
+				lookPath: func(file string) (string, error) {
+					for _, missing := range tt.missing {
+						if file == missing {
+							return "", os.ErrNotExist
+						}
+					}
+					return "/usr/bin/" + file, nil
+				},
+				getenv: func(key string) string { return tt.env[key] },
+			}
+			if tt.graph != nil {
+				env.GraphDir = filepath.Join(dir, "graph")
+				if err := tt.graph.WriteDir(env.GraphDir); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.config != "" {
+				env.ConfigPath = filepath.Join(dir, "converter.toml")
+				writeTestFile(t, env.ConfigPath, tt.config)
+			}
+
+			checks := make(map[string]doctorCheck)
+			for _, check := range diagnose(env) {
+				checks[check.Name] = check
+			}
+			for name, want := range tt.want {
+				if got := checks[name]; got.Status != want {
+					t.Errorf("%s = %v (%s), want %v", name, got.Status, got.Message, want)
+				}
+			}
+			for name, want := range tt.message {
+				if got := checks[name].Message; !strings.Contains(got, want) {
+					t.Errorf("%s message = %q, want it to contain %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+// TestCheckOutputDir tests the checks of existing, missing and unusable output directories
+func TestCheckOutputDir(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file.txt")
+	writeTestFile(t, file, "text")
+
+	tests := []struct {
+		name    string
+		dir     string
+		want    doctorStatus
+		message string
+	}{
+		{"existing", dir, doctorOK, "is writable"},
+		{"created", filepath.Join(dir, "content", "posts"), doctorOK, "will be created in " + dir},
+		{"below a file", filepath.Join(file, "posts"), doctorFail, "is not a directory"},
+		{"not given", "", doctorWarn, "no output directory given"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := checkOutputDir(tt.dir)
+			if check.Status != tt.want || !strings.Contains(check.Message, tt.message) {
+				t.Errorf("checkOutputDir(%q) = %v %q, want %v containing %q", tt.dir, check.Status, check.Message, tt.want, tt.message)
+			}
+		})
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("checkOutputDir() left files behind: %v", entries)
+	}
+}
@@ -3,63 +3,156 @@
 package main
 
 import (
+	"bytes"
+	"fmt"
+	"regexp"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/yuin/goldmark/ast"
 )
 
+// maxIndent is the deepest indentation (in whitespace characters) kept by sanitizeSource.
+// Parsing gets very slow for deeper lists (thousands of levels take minutes),
+// while real journals rarely nest more than a dozen levels.
+const maxIndent = 100
+
+// sanitizeSource prepares a Logseq file for parsing, so hostile or broken files
+// can't hang or crash the conversion: invalid UTF-8 is replaced with U+FFFD
+// and indentation deeper than maxIndent is cut to maxIndent.
+// Files without such problems are returned unchanged.
+func sanitizeSource(source []byte) []byte {
+	if !utf8.Valid(source) {
+		source = bytes.ToValidUTF8(source, []byte("\uFFFD"))
+	}
+
+	var result []byte // Only allocated once a line has to be changed
+	for start := 0; start < len(source); {
+		end := bytes.IndexByte(source[start:], '\n') + start + 1
+		if end == start {
+			end = len(source)
+		}
+		line := source[start:end]
+		indent := len(line) - len(bytes.TrimLeft(line, " \t"))
+		if indent > maxIndent && result == nil {
+			result = append(make([]byte, 0, len(source)), source[:start]...)
+		}
+		if result != nil {
+			if indent > maxIndent {
+				line = append(line[:maxIndent:maxIndent], line[indent:]...)
+			}
+			result = append(result, line...)
+		}
+		start = end
+	}
+	if result == nil {
+		return source
+	}
+	return result
+}
+
+// ExtractionIssue describes a blog post that could not be extracted.
+type ExtractionIssue struct {
+	Line    int    // Line number of the post in the file (1-based)
+	Message string // Description of the problem
+}
+
 // extractBlogPosts finds all blog posts in a markdown document.
 // It handles two formats:
 // 1. List format: metadata in first list item
 // 2. Top-level format: metadata as paragraphs, content in lists
-func extractBlogPosts(doc ast.Node, source []byte) []*BlogPost {
+// A malformed post doesn't stop the extraction: it is skipped and
+// reported as an issue, and the other posts are extracted as usual.
+func extractBlogPosts(doc ast.Node, source []byte, marker *BlogMarker) ([]*BlogPost, []ExtractionIssue) {
 	var posts []*BlogPost
-	processedLists := make(map[ast.Node]bool)
+	var issues []ExtractionIssue
 	parser := NewMetadataParser()
 
+	// add keeps a post or reports why it is skipped
+	add := func(n ast.Node, post *BlogPost, err error) {
+		switch {
+		case err != nil:
+			issues = append(issues, ExtractionIssue{Line: nodeLine(n, source), Message: err.Error()})
+		case post.Kind != KindArticle:
+			issues = append(issues, ExtractionIssue{Line: nodeLine(n, source), Message: fmt.Sprintf("skipping %s, not a blog post", post.Kind)})
+		case post.Meta.Title == "":
+			issues = append(issues, ExtractionIssue{Line: nodeLine(n, source), Message: "blog post without title:: property"})
+		default:
+			posts = append(posts, post)
+		}
+	}
+
 	// First, check for top-level metadata format
-	if topLevelPost := extractTopLevelPost(doc, source, parser); topLevelPost != nil {
-		posts = append(posts, topLevelPost)
-		return posts
+	topLevelPost, err := recoverPost(func() *BlogPost { return extractTopLevelPost(doc, source, parser, marker) })
+	if topLevelPost != nil || err != nil {
+		add(doc, topLevelPost, err)
+		return posts, issues
 	}
 
 	// Walk through the AST looking for list-based blog posts
 	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
-		if !entering || n.Kind() != ast.KindList || processedLists[n] {
+		if !entering || n.Kind() != ast.KindList {
 			return ast.WalkContinue, nil
 		}
 
-		// Check if first item contains "type:: blog"
+		// Check if first item contains the blog marker ("type:: blog")
 		firstItem := n.FirstChild()
-		if firstItem == nil || !strings.Contains(string(firstItem.Text(source)), "type:: blog") {
+		if firstItem == nil || !hasBlogMarker(firstItem, source, marker) {
+			return ast.WalkContinue, nil
+		}
+
+		// The post is further down, under a bullet like "- [[Blog]]": walk into the list,
+		// so the posts in the other items of this list are found as well
+		if findPostList(n, source, marker) != n {
 			return ast.WalkContinue, nil
 		}
 
 		// Found a blog list! Extract it
-		post := extractListPost(n, firstItem, source, parser)
-		if post != nil {
-			posts = append(posts, post)
+		post, err := recoverPost(func() *BlogPost { return extractListPost(n, source, parser, marker) })
+		if post != nil || err != nil {
+			add(firstItem, post, err)
 		}
 
-		// Mark this list and all nested lists as processed
-		ast.Walk(n, func(child ast.Node, entering bool) (ast.WalkStatus, error) {
-			if entering && child.Kind() == ast.KindList {
-				processedLists[child] = true
-			}
-			return ast.WalkContinue, nil
-		})
+		// The nested lists belong to this post, so the walk doesn't descend into them
+		return ast.WalkSkipChildren, nil
+	})
+
+	return posts, issues
+}
 
+// recoverPost runs the extraction of a single post and turns a panic
+// (caused by markdown the extraction doesn't expect) into an error.
+func recoverPost(extract func() *BlogPost) (post *BlogPost, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			post, err = nil, fmt.Errorf("malformed blog post: %v", r)
+		}
+	}()
+	return extract(), nil
+}
+
+// nodeLine returns the line number (1-based) where a node starts in the source.
+func nodeLine(n ast.Node, source []byte) int {
+	line := 0
+	ast.Walk(n, func(child ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering && child.Type() == ast.TypeBlock && child.Lines().Len() > 0 {
+			start := child.Lines().At(0).Start
+			line = bytes.Count(source[:start], []byte("\n")) + 1
+			return ast.WalkStop, nil
+		}
 		return ast.WalkContinue, nil
 	})
-
-	return posts
+	if line == 0 {
+		return 1
+	}
+	return line
 }
 
 // extractTopLevelPost extracts a blog post from top-level metadata format.
 // In this format, metadata is in paragraphs at the start, followed by content lists.
-func extractTopLevelPost(doc ast.Node, source []byte, parser *MetadataParser) *BlogPost {
+func extractTopLevelPost(doc ast.Node, source []byte, parser *MetadataParser, marker *BlogMarker) *BlogPost {
 	var metadataLines []string
-	var contentBlocks []string
+	var contentBlocks []ContentBlock
 	foundBlogMarker := false
 
 	// Walk and collect metadata and content
@@ -69,18 +162,26 @@ func extractTopLevelPost(doc ast.Node, source []byte, parser *MetadataParser) *B
 		}
 
 		// Look for metadata in paragraphs
-		if n.Kind() == ast.KindParagraph {
+		// (the raw lines are checked first, building the text is more expensive)
+		if n.Kind() == ast.KindParagraph && linesContain(n, source, propertySeparator) {
 			text := string(n.Text(source))
 			if strings.Contains(text, "::") {
 				lines := strings.Split(text, "\n")
+				inProperty := false
 				for _, line := range lines {
+					if marker.Matches(line) {
+						foundBlogMarker = true
+					}
 					if strings.Contains(line, "::") {
 						metadataLines = append(metadataLines, line)
-						if strings.Contains(line, "type:: blog") {
-							foundBlogMarker = true
-						}
+						inProperty = true
+					} else if inProperty {
+						// Continuation of a multi-line property value
+						metadataLines = append(metadataLines, line)
 					}
 				}
+				// The paragraph ends here, so does its last property
+				metadataLines = append(metadataLines, "")
 			}
 		}
 
@@ -90,7 +191,7 @@ func extractTopLevelPost(doc ast.Node, source []byte, parser *MetadataParser) *B
 				return ast.WalkContinue, nil
 			}
 			for item := n.FirstChild(); item != nil; item = item.NextSibling() {
-				contentBlocks = append(contentBlocks, extractText(item, source))
+				contentBlocks = append(contentBlocks, newContentBlock(extractText(item, source)))
 			}
 		}
 
@@ -105,27 +206,32 @@ func extractTopLevelPost(doc ast.Node, source []byte, parser *MetadataParser) *B
 	post := &BlogPost{
 		Meta:    meta,
 		Content: contentBlocks,
+		Kind:    blockKind(metadataLines),
 	}
 
-	if len(contentBlocks) > 0 && post.Meta.Summary == "" {
-		post.Meta.Summary = strings.ReplaceAll(contentBlocks[0], "\n", " ")
-	}
+	useFirstParagraph(post)
 
 	return post
 }
 
+// useFirstParagraph makes the first content block the summary of a post
+// without a "summary::" property.
+func useFirstParagraph(post *BlogPost) {
+	if len(post.Content) > 0 && post.Meta.Summary == "" {
+		post.Meta.Summary = strings.ReplaceAll(post.Content[0].Text, "\n", " ")
+		post.SummaryFromContent = true
+	}
+}
+
 // extractListPost extracts a single blog post from a list node.
 // It handles both flat and nested list structures.
-func extractListPost(listNode ast.Node, firstItem ast.Node, source []byte, parser *MetadataParser) *BlogPost {
-	// Find the deepest nested list (handles arbitrary nesting)
-	deepestList := findDeepestList(firstItem)
-	if deepestList != firstItem {
-		listNode = deepestList
-	}
+func extractListPost(listNode ast.Node, source []byte, parser *MetadataParser, marker *BlogMarker) *BlogPost {
+	// Find the nested list with the properties (handles arbitrary nesting)
+	listNode = findPostList(listNode, source, marker)
 
 	// Extract metadata and content
 	var metadataLines []string
-	var contentBlocks []string
+	var contentBlocks []ContentBlock
 
 	count := 0
 	for item := listNode.FirstChild(); item != nil; item = item.NextSibling() {
@@ -135,9 +241,9 @@ func extractListPost(listNode ast.Node, firstItem ast.Node, source []byte, parse
 			metadataLines = append(metadataLines, lines...)
 		} else {
 			// Remaining items are content
-			content := extractText(item, source)
-			if content != "" {
-				contentBlocks = append(contentBlocks, content)
+			block := newContentBlock(extractText(item, source))
+			if block.Text != "" {
+				contentBlocks = append(contentBlocks, block)
 			}
 		}
 		count++
@@ -150,25 +256,109 @@ func extractListPost(listNode ast.Node, firstItem ast.Node, source []byte, parse
 	post := &BlogPost{
 		Meta:    meta,
 		Content: contentBlocks,
+		Kind:    blockKind(metadataLines),
 	}
 
 	// Use first content block as summary if available
-	if len(contentBlocks) > 0 && post.Meta.Summary == "" {
-		post.Meta.Summary = strings.ReplaceAll(contentBlocks[0], "\n", " ")
-	}
+	useFirstParagraph(post)
+
+	// Journal posts are often nested under category bullets like "- [[Sailing]]"
+	post.Ancestors = ancestorReferences(listNode, source)
 
 	return post
 }
 
-// findDeepestList recursively finds the deepest nested list within a node.
-func findDeepestList(node ast.Node) ast.Node {
-	deepest := node
-	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
-		if child.Kind() == ast.KindList {
-			return findDeepestList(child)
+// ancestorReferences returns the pages referenced by the bullets a list is nested under,
+// outermost first: "- [[Sailing]]" with the post below it gives ["Sailing"].
+// Only the text of the bullets counts, not their other children.
+func ancestorReferences(list ast.Node, source []byte) []string {
+	var pages []string
+	for n := list.Parent(); n != nil; n = n.Parent() {
+		if n.Kind() != ast.KindListItem {
+			continue
+		}
+		var level []string
+		for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+			if child.Kind() == ast.KindList {
+				continue
+			}
+			for _, match := range pageReferenceRegex.FindAllStringSubmatch(string(child.Text(source)), -1) {
+				if page := strings.TrimSpace(match[1]); page != "" {
+					level = append(level, page)
+				}
+			}
+		}
+		pages = append(level, pages...)
+	}
+	return pages
+}
+
+// propertySeparator separates property keys and values.
+var propertySeparator = []byte("::")
+
+// hasBlogMarker checks if a node or any block inside it contains the blog marker.
+// Large journals contain many lists, so the raw source lines are searched
+// and the search stops at the first match instead of building the node's text.
+func hasBlogMarker(n ast.Node, source []byte, marker *BlogMarker) bool {
+	found := false
+	ast.Walk(n, func(child ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering && child.Type() == ast.TypeBlock && linesMatch(child, source, marker) {
+			found = true
+			return ast.WalkStop, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return found
+}
+
+// linesContain checks if one of the raw source lines of a block node contains sub.
+func linesContain(n ast.Node, source, sub []byte) bool {
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		if bytes.Contains(line.Value(source), sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// linesMatch checks if one of the raw source lines of a block node matches the blog marker.
+func linesMatch(n ast.Node, source []byte, marker *BlogMarker) bool {
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		if marker.matchesBytes(line.Value(source)) {
+			return true
 		}
 	}
-	return deepest
+	return false
+}
+
+// findPostList recursively finds the list of a post: the list whose first item
+// holds the blog marker itself. The bullets above it (like "- [[Sailing]]")
+// are left in the tree, so ancestorReferences can read them.
+func findPostList(list ast.Node, source []byte, marker *BlogMarker) ast.Node {
+	first := list.FirstChild()
+	if first == nil || ownsBlogMarker(first, source, marker) {
+		return list
+	}
+	for child := first.FirstChild(); child != nil; child = child.NextSibling() {
+		if child.Kind() == ast.KindList && hasBlogMarker(child, source, marker) {
+			return findPostList(child, source, marker)
+		}
+	}
+	return list
+}
+
+// ownsBlogMarker checks if the text of a list item itself (not its nested lists) contains the blog marker.
+func ownsBlogMarker(item ast.Node, source []byte, marker *BlogMarker) bool {
+	for child := item.FirstChild(); child != nil; child = child.NextSibling() {
+		if child.Kind() != ast.KindList && hasBlogMarker(child, source, marker) {
+			return true
+		}
+	}
+	return false
 }
 
 // extractText extracts text from an AST node while preserving markdown formatting.
@@ -195,6 +385,14 @@ func extractText(n ast.Node, source []byte) string {
 			builder.WriteString(" ")
 			builder.WriteString(string(child.Text(source)))
 			builder.WriteString("\n")
+		} else if child.Kind() == ast.KindFencedCodeBlock {
+			// Fenced code blocks only return the code lines,
+			// so we restore the fences and the language
+			writeFencedCode(&builder, child.(*ast.FencedCodeBlock), source)
+		} else if child.Kind() == ast.KindBlockquote {
+			// Blockquotes are containers without own lines,
+			// so we extract their children and quote every line
+			writeBlockquote(&builder, child, source)
 		} else {
 			// For other children (paragraphs, images, etc.), get their raw lines
 			// This preserves markdown formatting like **, [], etc.
@@ -208,3 +406,63 @@ func extractText(n ast.Node, source []byte) string {
 
 	return strings.TrimSpace(builder.String())
 }
+
+// writeFencedCode writes a fenced code block including its opening and closing fences.
+func writeFencedCode(builder *strings.Builder, code *ast.FencedCodeBlock, source []byte) {
+	builder.WriteString("```")
+	if code.Info != nil {
+		builder.Write(code.Info.Segment.Value(source))
+	}
+	builder.WriteString("\n")
+	lines := code.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		builder.Write(line.Value(source))
+	}
+	builder.WriteString("```\n")
+}
+
+// writeBlockquote writes a blockquote by prefixing each line of its content with "> ".
+func writeBlockquote(builder *strings.Builder, quote ast.Node, source []byte) {
+	for _, line := range strings.Split(extractText(quote, source), "\n") {
+		if strings.TrimSpace(line) == "" {
+			builder.WriteString(">\n")
+			continue
+		}
+		builder.WriteString("> ")
+		builder.WriteString(line)
+		builder.WriteString("\n")
+	}
+}
+
+// blockPropertyRegex matches a Logseq block property line like "id:: 6650f3e2-..."
+// Keys may contain letters of any language, digits, "_" and "-".
+var blockPropertyRegex = regexp.MustCompile(`^\s*(\p{L}[\p{L}\p{N}_-]*)::\s*(.*)$`)
+
+// newContentBlock creates a content block from extracted text.
+// Property lines (outside of code blocks) are moved from the text into the block's properties.
+func newContentBlock(text string) ContentBlock {
+	if !strings.Contains(text, "::") {
+		return ContentBlock{Text: text}
+	}
+
+	block := ContentBlock{}
+	var lines []string
+	inCode := false
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+		}
+		if match := blockPropertyRegex.FindStringSubmatch(line); match != nil && !inCode {
+			if block.Properties == nil {
+				block.Properties = make(map[string]string)
+			}
+			block.Properties[strings.ToLower(match[1])] = strings.TrimSpace(match[2])
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	block.Text = strings.TrimSpace(strings.Join(lines, "\n"))
+	return block
+}
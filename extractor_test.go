@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// testMarker is the default blog marker ("type:: blog") used by the extraction tests.
+var testMarker, _ = NewBlogMarker(DefaultMarker)
+
+// TestNewContentBlock tests moving block properties out of the content text
+func TestNewContentBlock(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		wantText  string
+		wantProps map[string]string
+	}{
+		{
+			name:     "No properties",
+			text:     "Just a paragraph",
+			wantText: "Just a paragraph",
+		},
+		{
+			name:      "Id and collapsed properties",
+			text:      "Some text\nid:: 6650f3e2-1234-4d2b-9a7e-0123456789ab\ncollapsed:: true",
+			wantText:  "Some text",
+			wantProps: map[string]string{"id": "6650f3e2-1234-4d2b-9a7e-0123456789ab", "collapsed": "true"},
+		},
+		{
+			name:      "Hyphenated property key",
+			text:      "Colored\nbackground-color:: yellow",
+			wantText:  "Colored",
+			wantProps: map[string]string{"background-color": "yellow"},
+		},
+		{
+			name:     "Properties inside code blocks are kept",
+			text:     "```\nkey:: value\n```",
+			wantText: "```\nkey:: value\n```",
+		},
+		{
+			name:     "Inline double colons are kept",
+			text:     "C++ uses std::vector",
+			wantText: "C++ uses std::vector",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newContentBlock(tt.text)
+			if got.Text != tt.wantText {
+				t.Errorf("newContentBlock().Text = %q, want %q", got.Text, tt.wantText)
+			}
+			if len(got.Properties) != len(tt.wantProps) {
+				t.Errorf("newContentBlock().Properties = %v, want %v", got.Properties, tt.wantProps)
+			}
+			for key, want := range tt.wantProps {
+				if got.Properties[key] != want {
+					t.Errorf("Properties[%q] = %q, want %q", key, got.Properties[key], want)
+				}
+			}
+		})
+	}
+}
+
+// TestExtractBlogPosts_MultiLineValues tests multi-line properties in both Logseq formats
+func TestExtractBlogPosts_MultiLineValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{
+			name:   "List format",
+			source: "- type:: blog\n  title:: Short\n  summary:: A summary that\n  wraps onto a second line\n  status:: online\n- Content\n",
+		},
+		{
+			name:   "Top-level format",
+			source: "type:: blog\ntitle:: Short\nsummary:: A summary that\nwraps onto a second line\nstatus:: online\n\n- Content\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := []byte(tt.source)
+			posts, _ := extractBlogPosts(goldmark.New().Parser().Parse(text.NewReader(source)), source, testMarker)
+			if len(posts) != 1 {
+				t.Fatalf("Expected 1 post, got %d", len(posts))
+			}
+			meta := posts[0].Meta
+			if meta.Summary != "A summary that wraps onto a second line" || meta.Status != "online" || meta.Title != "Short" {
+				t.Errorf("Unexpected metadata: %+v", meta)
+			}
+		})
+	}
+}
+
+// largeJournal builds a journal with many non-blog blocks and one blog post
+// with the given number of content blocks, each with a nested list.
+func largeJournal(blocks int) []byte {
+	var builder strings.Builder
+	for i := 0; i < blocks; i++ {
+		fmt.Fprintf(&builder, "- Journal note %d with [[Some Page]] and **bold** text\n\t- Nested detail %d\n\t\t- Deeper detail %d\n", i, i, i)
+	}
+	builder.WriteString("- Blog\n\t- type:: blog\n\t  status:: online\n\t  date:: 2026-01-01\n\t  title:: Large\n\t  author:: me\n")
+	for i := 0; i < blocks; i++ {
+		fmt.Fprintf(&builder, "\t- Paragraph %d of the post with ![image](../assets/image_%d.png)\n\t\t- A nested point\n", i, i)
+	}
+	return []byte(builder.String())
+}
+
+// BenchmarkExtractBlogPosts measures parsing and extracting a large journal file
+func BenchmarkExtractBlogPosts(b *testing.B) {
+	source := largeJournal(2000)
+	b.SetBytes(int64(len(source)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		doc := goldmark.New().Parser().Parse(text.NewReader(source))
+		if posts, _ := extractBlogPosts(doc, source, testMarker); len(posts) != 1 {
+			b.Fatalf("Expected 1 post, got %d", len(posts))
+		}
+	}
+}
+
+// BenchmarkBlogMarker compares searching the blog marker in the raw lines of
+// the blocks ("lines") with searching it in the text built for every list item
+// ("text", the check before the lines were searched)
+func BenchmarkBlogMarker(b *testing.B) {
+	source := largeJournal(2000)
+	doc := goldmark.New().Parser().Parse(text.NewReader(source))
+	list := doc.FirstChild()
+	checks := map[string]func(item ast.Node) bool{
+		"lines": func(item ast.Node) bool { return hasBlogMarker(item, source, testMarker) },
+		"text":  func(item ast.Node) bool { return strings.Contains(string(item.Text(source)), "type:: blog") },
+	}
+
+	for name, check := range checks {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				found := 0
+				for item := list.FirstChild(); item != nil; item = item.NextSibling() {
+					if check(item) {
+						found++
+					}
+				}
+				if found != 1 {
+					b.Fatalf("Found the marker in %d items, want 1", found)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkExtractBlogPosts_DeepNesting measures extracting a post from deeply nested lists
+func BenchmarkExtractBlogPosts_DeepNesting(b *testing.B) {
+	var builder strings.Builder
+	builder.WriteString("- Blog\n")
+	indent := "\t"
+	for depth := 0; depth < 20; depth++ {
+		builder.WriteString(indent + "- Level " + fmt.Sprint(depth) + "\n")
+		indent += "\t"
+	}
+	builder.WriteString(indent + "- type:: blog\n" + indent + "  status:: online\n" + indent + "  date:: 2026-01-01\n" + indent + "  title:: Deep\n")
+	for i := 0; i < 500; i++ {
+		fmt.Fprintf(&builder, "%s- Paragraph %d\n%s\t- Nested %d\n%s\t\t- Deeper %d\n", indent, i, indent, i, indent, i)
+	}
+	source := []byte(builder.String())
+	b.SetBytes(int64(len(source)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		doc := goldmark.New().Parser().Parse(text.NewReader(source))
+		if posts, _ := extractBlogPosts(doc, source, testMarker); len(posts) != 1 {
+			b.Fatalf("Expected 1 post, got %d", len(posts))
+		}
+	}
+}
+
+// TestExtractBlogPosts_Issues tests that malformed posts and flashcards are reported with their line and the others are extracted
+func TestExtractBlogPosts_Issues(t *testing.T) {
+	source := []byte("- Morning\n- Untitled\n\t- type:: blog\n\t  status:: online\n- Blog\n\t- type:: blog\n\t  title:: Fine\n\t  status:: online\n\t- Content\n- Cards\n\t- What marks a post? #card\n\t  type:: blog\n\t\t- The property\n")
+	posts, issues := extractBlogPosts(goldmark.New().Parser().Parse(text.NewReader(source)), source, testMarker)
+
+	if len(posts) != 1 || posts[0].Meta.Title != "Fine" {
+		t.Errorf("Expected only the post 'Fine', got %d posts", len(posts))
+	}
+	want := []ExtractionIssue{
+		{Line: 3, Message: "blog post without title:: property"},
+		{Line: 11, Message: "skipping flashcard, not a blog post"},
+	}
+	if !reflect.DeepEqual(issues, want) {
+		t.Errorf("issues = %+v, want %+v", issues, want)
+	}
+}
+
+// TestExtractBlogPosts_Ancestors tests capturing the page references of the bullets a post is nested under
+func TestExtractBlogPosts_Ancestors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []string
+	}{
+		{
+			name:   "Nested under categories",
+			source: "- [[Sailing]] and [[Boats]]\n\t- Trips\n\t\t- [[Blog]]\n\t\t\t- type:: blog\n\t\t\t  title:: Renan\n\t\t\t- Content [[Not an ancestor]]\n",
+			want:   []string{"Sailing", "Boats", "Blog"},
+		},
+		{
+			name:   "Top-level post",
+			source: "- type:: blog\n  title:: Renan\n- [[Content]]\n",
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := []byte(tt.source)
+			posts, _ := extractBlogPosts(goldmark.New().Parser().Parse(text.NewReader(source)), source, testMarker)
+			if len(posts) != 1 {
+				t.Fatalf("Expected 1 post, got %d", len(posts))
+			}
+			if !reflect.DeepEqual(posts[0].Ancestors, tt.want) {
+				t.Errorf("Ancestors = %q, want %q", posts[0].Ancestors, tt.want)
+			}
+		})
+	}
+}
+
+// TestRecoverPost tests that a panic while extracting a post becomes an error
+func TestRecoverPost(t *testing.T) {
+	post, err := recoverPost(func() *BlogPost {
+		var node ast.Node
+		return &BlogPost{Meta: BlogMeta{Title: string(node.Text(nil))}}
+	})
+	if post != nil || err == nil || !strings.HasPrefix(err.Error(), "malformed blog post: ") {
+		t.Errorf("recoverPost() = %v, %v; want malformed blog post error", post, err)
+	}
+}
+
+// TestSanitizeSource tests cutting deep indentation and replacing invalid UTF-8
+func TestSanitizeSource(t *testing.T) {
+	deep := strings.Repeat("\t", maxIndent+50)
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"Unchanged", "- a\n\t- b\n", "- a\n\t- b\n"},
+		{"Deep indentation", "- a\n" + deep + "- b\n- c", "- a\n" + deep[:maxIndent] + "- b\n- c"},
+		{"Invalid UTF-8", "- caf\xe9\n", "- caf�\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(sanitizeSource([]byte(tt.source))); got != tt.want {
+				t.Errorf("sanitizeSource() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// FuzzExtractBlogPosts tests that no input can crash the extraction
+// and that issues point to lines of the file
+func FuzzExtractBlogPosts(f *testing.F) {
+	f.Add("- type:: blog\n  title:: T\n  status:: online\n- Content\n\t- Nested\n")
+	f.Add("type:: blog\ntitle:: Top\n\n- ```go\ncode\n```\n- > quote\n")
+	f.Add("- Day\n\t- type:: blog\n\t  status:: online\n\t- #+BEGIN_NOTE\n\t  x\n\t  #+END_NOTE\n")
+	f.Add(strings.Repeat("\t", 500) + "- type:: blog\n")
+
+	f.Fuzz(func(t *testing.T, input string) {
+		source := sanitizeSource([]byte(input))
+		posts, issues := extractBlogPosts(goldmark.New().Parser().Parse(text.NewReader(source)), source, testMarker)
+
+		lines := bytes.Count(source, []byte("\n")) + 1
+		for _, issue := range issues {
+			if issue.Line < 1 || issue.Line > lines {
+				t.Errorf("Issue line %d outside of the %d lines", issue.Line, lines)
+			}
+		}
+		for _, post := range posts {
+			if post.Meta.Title == "" {
+				t.Errorf("Post without title extracted")
+			}
+			for _, block := range post.Content {
+				if !utf8.ValidString(block.Text) {
+					t.Errorf("Invalid UTF-8 in block %q", block.Text)
+				}
+			}
+		}
+	})
+}
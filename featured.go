@@ -0,0 +1,154 @@
+// This file handles resized variants of the featured (header) image.
+// Themes expect featured images with a particular aspect ratio, and social media
+// previews (OpenGraph) expect 1200x630. The header image is center-cropped to the
+// configured ratio and scaled to the configured size.
+package main
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif" // Register the GIF decoder
+	"image/jpeg"
+	_ "image/png" // Register the PNG decoder
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ProcessHeaderVariants writes the resized variants of the header image
+// that are configured: featured.jpeg (FeaturedSize) and og-image.jpeg (OpenGraphSize).
+// If the featured image can't be resized, the original is copied instead.
+func (p *ImageProcessor) ProcessHeaderVariants(ctx context.Context, headerPath string, config HeaderConfig) {
+	if headerPath == "" || (config.FeaturedSize == "" && config.OpenGraphSize == "") {
+		return
+	}
+
+	src := p.sourcePath(headerPath)
+	img, err := p.decodeImage(src)
+	if err != nil {
+		fmt.Printf("Warning: Can't resize header image %s: %v\n", src, err)
+		if config.FeaturedSize != "" {
+			p.ProcessHeaderImage(ctx, headerPath)
+		}
+		return
+	}
+
+	variants := []struct{ size, name string }{
+		{config.FeaturedSize, "featured.jpeg"},
+		{config.OpenGraphSize, "og-image.jpeg"},
+	}
+	for _, variant := range variants {
+		if variant.size == "" || ctx.Err() != nil {
+			continue
+		}
+		width, height, err := parseImageSize(variant.size)
+		if err != nil {
+			fmt.Printf("Warning: %v\n", err)
+			continue
+		}
+		dst := filepath.Join(p.outputDir, variant.name)
+		if err := p.writeJPEG(dst, cropAndScale(img, width, height), config.Quality); err != nil {
+			fmt.Printf("Warning: Writing %s: %v\n", variant.name, err)
+			continue
+		}
+		p.recordAsset(dst, "resized")
+	}
+}
+
+// decodeImage reads and decodes a JPEG, PNG or GIF image.
+func (p *ImageProcessor) decodeImage(path string) (image.Image, error) {
+	f, err := p.fs.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	return img, err
+}
+
+// writeJPEG encodes an image as JPEG file.
+func (p *ImageProcessor) writeJPEG(path string, img image.Image, quality int) error {
+	f, err := p.fs.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := jpeg.Encode(f, img, &jpeg.Options{Quality: quality}); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// parseImageSize parses a size like "1200x630".
+func parseImageSize(size string) (width, height int, err error) {
+	w, h, found := strings.Cut(strings.ToLower(strings.TrimSpace(size)), "x")
+	if found {
+		width, err = strconv.Atoi(w)
+		if err == nil {
+			height, err = strconv.Atoi(h)
+		}
+	}
+	if !found || err != nil || width <= 0 || height <= 0 {
+		return 0, 0, fmt.Errorf("invalid image size %q, expected WIDTHxHEIGHT (e.g. 1200x630)", size)
+	}
+	return width, height, nil
+}
+
+// cropToRatio returns the largest centered rectangle of bounds with the aspect ratio width:height.
+func cropToRatio(bounds image.Rectangle, width, height int) image.Rectangle {
+	w, h := bounds.Dx(), bounds.Dy()
+	if w*height > h*width {
+		// Too wide: cut left and right
+		cropWidth := max(h*width/height, 1)
+		x := bounds.Min.X + (w-cropWidth)/2
+		return image.Rect(x, bounds.Min.Y, x+cropWidth, bounds.Max.Y)
+	}
+	// Too high: cut top and bottom
+	cropHeight := max(w*height/width, 1)
+	y := bounds.Min.Y + (h-cropHeight)/2
+	return image.Rect(bounds.Min.X, y, bounds.Max.X, y+cropHeight)
+}
+
+// cropAndScale center-crops an image to the aspect ratio of width:height and scales it to that size.
+// Every target pixel is the average of the source pixels it covers (box filter),
+// which gives smooth results when scaling down photos.
+func cropAndScale(img image.Image, width, height int) *image.RGBA {
+	crop := cropToRatio(img.Bounds(), width, height)
+
+	// Work on RGBA pixels directly, going through img.At is too slow for photos
+	src := image.NewRGBA(image.Rect(0, 0, crop.Dx(), crop.Dy()))
+	draw.Draw(src, src.Bounds(), img, crop.Min, draw.Src)
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := y * crop.Dy() / height
+		y1 := max((y+1)*crop.Dy()/height, y0+1)
+		for x := 0; x < width; x++ {
+			x0 := x * crop.Dx() / width
+			x1 := max((x+1)*crop.Dx()/width, x0+1)
+
+			var r, g, b, a, n int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					pixel := row[sx*4 : sx*4+4]
+					r += int(pixel[0])
+					g += int(pixel[1])
+					b += int(pixel[2])
+					a += int(pixel[3])
+					n++
+				}
+			}
+
+			i := y*dst.Stride + x*4
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(b / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
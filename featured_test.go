@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"path/filepath"
+	"testing"
+)
+
+// TestParseImageSize tests parsing WIDTHxHEIGHT sizes
+func TestParseImageSize(t *testing.T) {
+	tests := []struct {
+		size          string
+		width, height int
+		wantErr       bool
+	}{
+		{"1200x630", 1200, 630, false},
+		{" 1600X900 ", 1600, 900, false},
+		{"1200", 0, 0, true},
+		{"0x630", 0, 0, true},
+		{"ax630", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		width, height, err := parseImageSize(tt.size)
+		if (err != nil) != tt.wantErr || width != tt.width || height != tt.height {
+			t.Errorf("parseImageSize(%q) = %d, %d, %v; want %d, %d (error %t)", tt.size, width, height, err, tt.width, tt.height, tt.wantErr)
+		}
+	}
+}
+
+// TestCropToRatio tests center-cropping to an aspect ratio
+func TestCropToRatio(t *testing.T) {
+	tests := []struct {
+		name          string
+		bounds        image.Rectangle
+		width, height int
+		want          image.Rectangle
+	}{
+		{"Wide image", image.Rect(0, 0, 400, 100), 2, 1, image.Rect(100, 0, 300, 100)},
+		{"High image", image.Rect(0, 0, 100, 400), 2, 1, image.Rect(0, 175, 100, 225)},
+		{"Same ratio", image.Rect(0, 0, 1200, 630), 1200, 630, image.Rect(0, 0, 1200, 630)},
+		{"Tiny image", image.Rect(0, 0, 1, 1), 16, 9, image.Rect(0, 0, 1, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cropToRatio(tt.bounds, tt.width, tt.height); got != tt.want {
+				t.Errorf("cropToRatio() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestProcessHeaderVariants tests writing the resized featured and OpenGraph images
+func TestProcessHeaderVariants(t *testing.T) {
+	// A 400x100 image: red on the left half, blue on the right half
+	img := image.NewRGBA(image.Rect(0, 0, 400, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 400; x++ {
+			if x < 200 {
+				img.Set(x, y, color.RGBA{255, 0, 0, 255})
+			} else {
+				img.Set(x, y, color.RGBA{0, 0, 255, 255})
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to encode image: %v", err)
+	}
+
+	fsys := NewMemFileSystem(nil)
+	fsys.WriteFile(filepath.Join("graph", "assets", "header.png"), buf.Bytes())
+	fsys.MkdirAll("out", 0755)
+
+	processor := NewImageProcessor(NewCopyManager(fsys), filepath.Join("graph", "journals"), "out")
+	config := HeaderConfig{FeaturedSize: "160x90", OpenGraphSize: "120x63", Quality: 90}
+	processor.ProcessHeaderVariants(context.Background(), "../assets/header.png", config)
+
+	for name, want := range map[string]image.Point{"featured.jpeg": {160, 90}, "og-image.jpeg": {120, 63}} {
+		data, err := readFile(fsys, filepath.Join("out", name))
+		if err != nil {
+			t.Fatalf("%s was not written: %v", name, err)
+		}
+		decoded, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%s is no JPEG: %v", name, err)
+		}
+		if got := decoded.Bounds().Size(); got != want {
+			t.Errorf("%s size = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// This file handles the content filters that transform a blog post before it is written.
+// Filters are registered by name and run in the order of the "filters" configuration,
+// so transforms can be switched off, reordered or added without changing buildContent.
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ContentFilter transforms a blog post (usually its content blocks) before it is rendered.
+type ContentFilter func(post *BlogPost) error
+
+// ContentFilterFactory creates a content filter for a conversion.
+// The filter may use the configuration and state of the converter.
+type ContentFilterFactory func(c *Converter) ContentFilter
+
+// contentFilters are the registered filter factories by name.
+var contentFilters = map[string]ContentFilterFactory{}
+
+// RegisterContentFilter registers a content filter under a name for the "filters" configuration.
+// Registering a name twice replaces the first filter.
+func RegisterContentFilter(name string, factory ContentFilterFactory) {
+	contentFilters[name] = factory
+}
+
+// DefaultFilters is the order of the built-in filters.
+var DefaultFilters = []string{"rules", "captions", "queries", "links", "code_shortcodes", "callouts", "cleanup", "galleries"}
+
+func init() {
+	RegisterContentFilter("rules", func(c *Converter) ContentFilter {
+		rules, err := compileRules(c.config.Rules)
+		return func(post *BlogPost) error {
+			if err != nil {
+				return err
+			}
+			applyRules(post, rules)
+			return nil
+		}
+	})
+	RegisterContentFilter("captions", func(c *Converter) ContentFilter {
+		return func(post *BlogPost) error {
+			post.Content = mergeCaptions(post.Content, c.config.Captions)
+			return nil
+		}
+	})
+	RegisterContentFilter("queries", func(c *Converter) ContentFilter {
+		return func(post *BlogPost) error {
+			for i := range post.Content {
+				post.Content[i].Text = c.convertQueries(post, post.Content[i].Text)
+			}
+			return nil
+		}
+	})
+	RegisterContentFilter("links", func(c *Converter) ContentFilter {
+		return blockFilter(func(block string) string {
+			c.stats.countLinks(block, c.links)
+			return rewriteInternalLinks(block, c.links)
+		})
+	})
+	RegisterContentFilter("code_shortcodes", func(c *Converter) ContentFilter {
+		return blockFilter(func(block string) string { return convertCodeShortcodes(block, c.config.CodeShortcodes) })
+	})
+	RegisterContentFilter("callouts", func(c *Converter) ContentFilter {
+		return blockFilter(func(block string) string { return convertCallouts(block, c.config.Admonitions) })
+	})
+	RegisterContentFilter("cleanup", func(c *Converter) ContentFilter {
+		return blockFilter(func(block string) string { return cleanupMarkup(block, c.config.Cleanup) })
+	})
+	RegisterContentFilter("galleries", func(c *Converter) ContentFilter {
+		return blockFilter(func(block string) string { return convertGalleries(block, c.config.Gallery) })
+	})
+	RegisterContentFilter("strip_tasks", func(c *Converter) ContentFilter {
+		return stripTasks
+	})
+}
+
+// newContentFilters creates the filters of a conversion in the configured order.
+func newContentFilters(c *Converter, names []string) ([]ContentFilter, error) {
+	filters := make([]ContentFilter, 0, len(names))
+	for _, name := range names {
+		factory, ok := contentFilters[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown content filter %q (known filters: %s)", name, strings.Join(filterNames(), ", "))
+		}
+		filters = append(filters, factory(c))
+	}
+	return filters, nil
+}
+
+// filterNames returns the names of the registered filters in lexical order.
+func filterNames() []string {
+	names := make([]string, 0, len(contentFilters))
+	for name := range contentFilters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// blockFilter creates a filter that transforms the text of every content block.
+func blockFilter(transform func(block string) string) ContentFilter {
+	return func(post *BlogPost) error {
+		for i := range post.Content {
+			post.Content[i].Text = transform(post.Content[i].Text)
+		}
+		return nil
+	}
+}
+
+// taskRegex matches a block that is a Logseq task: "TODO Buy seeds".
+var taskRegex = regexp.MustCompile(`^(TODO|DOING|DONE|LATER|NOW|WAITING|CANCELED|CANCELLED) `)
+
+// stripTasks removes the blocks that are Logseq tasks (TODO, DONE, ...) from the post.
+func stripTasks(post *BlogPost) error {
+	kept := post.Content[:0]
+	for _, block := range post.Content {
+		if !taskRegex.MatchString(strings.TrimSpace(block.Text)) {
+			kept = append(kept, block)
+		}
+	}
+	post.Content = kept
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestNewContentFilters tests creating the filters in the configured order
+func TestNewContentFilters(t *testing.T) {
+	RegisterContentFilter("test_shout", func(c *Converter) ContentFilter {
+		return blockFilter(strings.ToUpper)
+	})
+	defer delete(contentFilters, "test_shout")
+
+	post := &BlogPost{Content: []ContentBlock{{Text: "TODO water the beans"}, {Text: "The beans are growing"}}}
+	filters, err := newContentFilters(NewConverter(DefaultConfig(), OSFileSystem{}), []string{"strip_tasks", "test_shout"})
+	if err != nil {
+		t.Fatalf("newContentFilters() error = %v", err)
+	}
+	for _, filter := range filters {
+		if err := filter(post); err != nil {
+			t.Fatalf("filter error = %v", err)
+		}
+	}
+
+	if len(post.Content) != 1 || post.Content[0].Text != "THE BEANS ARE GROWING" {
+		t.Errorf("Filtered content = %+v, want only the uppercase text block", post.Content)
+	}
+
+	if _, err := newContentFilters(nil, []string{"links", "nope"}); err == nil || !strings.Contains(err.Error(), `unknown content filter "nope"`) {
+		t.Errorf("newContentFilters() error = %v, want unknown filter", err)
+	}
+}
+
+// TestConvertGraph_Filters tests switching off a built-in filter by configuration
+func TestConvertGraph_Filters(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	fsys.WriteFile(filepath.Join("graph", "pages", "post.md"), []byte("type:: blog\nstatus:: online\ndate:: 2026-01-01\ntitle:: Garden\n\n- DONE Sow the ^^beans^^\n- The ^^beans^^ are up\n"))
+
+	config := DefaultConfig()
+	config.Filters = []string{"strip_tasks", "links"} // No cleanup of highlights
+	config.Cleanup.ReportMarkup = false
+	if _, err := NewConverter(config, fsys).ConvertGraph(context.Background(), "graph", "out"); err != nil {
+		t.Fatalf("ConvertGraph() error = %v", err)
+	}
+
+	index, err := readFile(fsys, filepath.Join("out", "2026-01-01_Garden", "index.de.md"))
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+	if !strings.HasSuffix(string(index), "+++\n\nThe ^^beans^^ are up\n") {
+		t.Errorf("Unexpected content:\n%s", index)
+	}
+}
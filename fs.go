@@ -0,0 +1,441 @@
+// This file handles file system access for the conversion.
+// All reads and writes of the converter go through the FileSystem interface,
+// so conversions can run against the real disk, in memory (tests) or
+// against an in-memory layer on top of the disk (dry runs).
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileSystem is the file system the converter reads from and writes to.
+// Paths use the separator of the operating system, like the os package.
+type FileSystem interface {
+	Open(name string) (fs.File, error)
+	Stat(name string) (fs.FileInfo, error)
+	ReadDir(name string) ([]fs.DirEntry, error)
+	EvalSymlinks(path string) (string, error)
+	MkdirAll(path string, perm fs.FileMode) error
+	Create(name string) (io.WriteCloser, error)
+	RemoveAll(path string) error
+}
+
+// OSFileSystem is the FileSystem of the operating system.
+// The zero value creates files and directories like the os package
+// (0644 and 0755 before the umask) owned by the user running the converter.
+type OSFileSystem struct {
+	FileMode fs.FileMode // Permissions of created files (0 = default)
+	DirMode  fs.FileMode // Permissions of created directories (0 = default)
+	Owner    *FileOwner  // Owner of created files and directories (nil = unchanged)
+}
+
+// FileOwner is the numeric owner of files, -1 keeps the user or group.
+type FileOwner struct {
+	UID int
+	GID int
+}
+
+// NewOSFileSystem creates the FileSystem of the operating system with the
+// permissions and the owner of the output configuration.
+func NewOSFileSystem(config OutputConfig) (OSFileSystem, error) {
+	var fsys OSFileSystem
+	var err error
+	if fsys.FileMode, err = parseFileMode(config.FileMode); err != nil {
+		return fsys, fmt.Errorf("file_mode: %w", err)
+	}
+	if fsys.DirMode, err = parseFileMode(config.DirMode); err != nil {
+		return fsys, fmt.Errorf("dir_mode: %w", err)
+	}
+	if fsys.Owner, err = parseFileOwner(config.Owner); err != nil {
+		return fsys, fmt.Errorf("owner: %w", err)
+	}
+	return fsys, nil
+}
+
+func (OSFileSystem) Open(name string) (fs.File, error)          { return os.Open(name) }
+func (OSFileSystem) Stat(name string) (fs.FileInfo, error)      { return os.Stat(name) }
+func (OSFileSystem) ReadDir(name string) ([]fs.DirEntry, error) { return os.ReadDir(name) }
+func (OSFileSystem) EvalSymlinks(path string) (string, error)   { return filepath.EvalSymlinks(path) }
+func (OSFileSystem) RemoveAll(path string) error                { return os.RemoveAll(path) }
+
+// MkdirAll creates a directory and its parents. Only the directories it
+// creates get the configured permissions and owner.
+func (o OSFileSystem) MkdirAll(path string, perm fs.FileMode) error {
+	if o.DirMode == 0 && o.Owner == nil {
+		return os.MkdirAll(path, perm)
+	}
+
+	var created []string
+	for dir := filepath.Clean(path); ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		created = append(created, dir)
+		if parent := filepath.Dir(dir); parent == dir {
+			break
+		}
+	}
+	if o.DirMode != 0 {
+		perm = o.DirMode
+	}
+	if err := os.MkdirAll(path, perm); err != nil {
+		return err
+	}
+
+	// The parents first, the umask doesn't apply to Chmod
+	for i := len(created) - 1; i >= 0; i-- {
+		if o.DirMode != 0 {
+			if err := os.Chmod(created[i], o.DirMode); err != nil {
+				return err
+			}
+		}
+		if o.Owner != nil {
+			if err := os.Chown(created[i], o.Owner.UID, o.Owner.GID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Create creates (or truncates) a file with the configured permissions and owner.
+func (o OSFileSystem) Create(name string) (io.WriteCloser, error) {
+	f, err := os.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	if o.FileMode != 0 {
+		err = f.Chmod(o.FileMode)
+	}
+	if err == nil && o.Owner != nil {
+		err = f.Chown(o.Owner.UID, o.Owner.GID)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// parseFileMode parses octal permissions like "0640" (empty for the default).
+func parseFileMode(value string) (fs.FileMode, error) {
+	if value == "" {
+		return 0, nil
+	}
+	mode, err := strconv.ParseUint(value, 8, 32)
+	if err != nil || mode == 0 || mode > 0777 {
+		return 0, fmt.Errorf("invalid permissions %q (use octal like \"0640\")", value)
+	}
+	return fs.FileMode(mode), nil
+}
+
+// parseFileOwner parses a numeric owner like chown does: "uid:gid", "uid" or ":gid"
+// (empty for the user running the converter).
+func parseFileOwner(value string) (*FileOwner, error) {
+	if value == "" {
+		return nil, nil
+	}
+	owner := &FileOwner{UID: -1, GID: -1}
+	user, group, hasGroup := strings.Cut(value, ":")
+	ids := []struct {
+		value string
+		id    *int
+	}{{user, &owner.UID}, {group, &owner.GID}}
+	for _, id := range ids {
+		if id.value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(id.value)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid owner %q (use numeric ids like \"1000:33\")", value)
+		}
+		*id.id = n
+	}
+	if user == "" && (!hasGroup || group == "") {
+		return nil, fmt.Errorf("invalid owner %q (use numeric ids like \"1000:33\")", value)
+	}
+	return owner, nil
+}
+
+// MemFileSystem keeps written files in memory.
+// Reads see the files written to the memory layer first and fall back to the
+// base file system (if any), so a MemFileSystem on top of OSFileSystem reads
+// the Logseq graph from disk without ever writing to it. Removed paths hide
+// the files of the base file system below them.
+type MemFileSystem struct {
+	base    FileSystem        // Read-only file system below the memory layer (may be nil)
+	files   map[string][]byte // Cleaned path -> file content
+	dirs    map[string]bool   // Cleaned paths of created directories
+	removed map[string]bool   // Cleaned paths removed with RemoveAll, hidden in the base file system
+}
+
+// NewMemFileSystem creates an empty in-memory file system on top of base.
+// Use nil as base for a file system that only contains what is written to it.
+func NewMemFileSystem(base FileSystem) *MemFileSystem {
+	return &MemFileSystem{
+		base:    base,
+		files:   make(map[string][]byte),
+		dirs:    make(map[string]bool),
+		removed: make(map[string]bool),
+	}
+}
+
+// WriteFile stores a file in memory (creating its parent directories).
+func (m *MemFileSystem) WriteFile(name string, data []byte) {
+	name = filepath.Clean(name)
+	m.MkdirAll(filepath.Dir(name), 0755)
+	m.files[name] = append([]byte(nil), data...)
+}
+
+// Files returns the paths of all files written to the memory layer in lexical order.
+func (m *MemFileSystem) Files() []string {
+	var names []string
+	for name := range m.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Removed returns the paths removed with RemoveAll in lexical order.
+func (m *MemFileSystem) Removed() []string {
+	var names []string
+	for name := range m.removed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// hidden reports whether a path of the base file system was removed, itself or with a parent directory.
+func (m *MemFileSystem) hidden(clean string) bool {
+	for path := clean; ; path = filepath.Dir(path) {
+		if m.removed[path] {
+			return true
+		}
+		if filepath.Dir(path) == path {
+			return false
+		}
+	}
+}
+
+// Open opens a file of the memory layer or the base file system.
+func (m *MemFileSystem) Open(name string) (fs.File, error) {
+	clean := filepath.Clean(name)
+	if data, ok := m.files[clean]; ok {
+		return &memFile{Reader: bytes.NewReader(data), info: memFileInfo{name: filepath.Base(clean), size: int64(len(data))}}, nil
+	}
+	if m.base != nil && !m.dirs[clean] && !m.hidden(clean) {
+		return m.base.Open(name)
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// Stat returns information about a file or directory.
+func (m *MemFileSystem) Stat(name string) (fs.FileInfo, error) {
+	clean := filepath.Clean(name)
+	if data, ok := m.files[clean]; ok {
+		return memFileInfo{name: filepath.Base(clean), size: int64(len(data))}, nil
+	}
+	if m.dirs[clean] {
+		return memFileInfo{name: filepath.Base(clean), dir: true}, nil
+	}
+	if m.base != nil && !m.hidden(clean) {
+		return m.base.Stat(name)
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir lists a directory of the memory layer merged with the base file system.
+func (m *MemFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	clean := filepath.Clean(name)
+	entries := make(map[string]fs.DirEntry)
+
+	var baseErr error = fs.ErrNotExist
+	if m.base != nil && !m.hidden(clean) {
+		var baseEntries []fs.DirEntry
+		baseEntries, baseErr = m.base.ReadDir(name)
+		for _, entry := range baseEntries {
+			if !m.removed[filepath.Join(clean, entry.Name())] {
+				entries[entry.Name()] = entry
+			}
+		}
+	}
+
+	for path := range m.dirs {
+		if path != clean && filepath.Dir(path) == clean {
+			entries[filepath.Base(path)] = fs.FileInfoToDirEntry(memFileInfo{name: filepath.Base(path), dir: true})
+		}
+	}
+	for path, data := range m.files {
+		if filepath.Dir(path) == clean {
+			entries[filepath.Base(path)] = fs.FileInfoToDirEntry(memFileInfo{name: filepath.Base(path), size: int64(len(data))})
+		}
+	}
+
+	if len(entries) == 0 && !m.dirs[clean] && baseErr != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	result := make([]fs.DirEntry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, entry)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name() < result[j].Name() })
+	return result, nil
+}
+
+// EvalSymlinks resolves symlinks of the base file system.
+// The memory layer has no symlinks, so its paths are returned cleaned.
+func (m *MemFileSystem) EvalSymlinks(path string) (string, error) {
+	clean := filepath.Clean(path)
+	if _, ok := m.files[clean]; ok || m.dirs[clean] || m.base == nil || m.hidden(clean) {
+		return clean, nil
+	}
+	return m.base.EvalSymlinks(path)
+}
+
+// MkdirAll creates a directory and its parents in memory.
+func (m *MemFileSystem) MkdirAll(path string, perm fs.FileMode) error {
+	for dir := filepath.Clean(path); !m.dirs[dir]; dir = filepath.Dir(dir) {
+		m.dirs[dir] = true
+		if parent := filepath.Dir(dir); parent == dir {
+			break
+		}
+	}
+	return nil
+}
+
+// Create creates (or truncates) a file in memory. The content is stored when the file is closed.
+func (m *MemFileSystem) Create(name string) (io.WriteCloser, error) {
+	dir := filepath.Dir(filepath.Clean(name))
+	if !m.dirs[dir] {
+		if info, err := m.Stat(dir); err != nil || !info.IsDir() {
+			return nil, &fs.PathError{Op: "create", Path: name, Err: fs.ErrNotExist}
+		}
+	}
+	m.files[filepath.Clean(name)] = nil
+	return &memWriter{fs: m, name: filepath.Clean(name)}, nil
+}
+
+// RemoveAll removes a file or directory and everything below it from the memory layer
+// and hides it in the base file system, which is never changed.
+func (m *MemFileSystem) RemoveAll(path string) error {
+	clean := filepath.Clean(path)
+	m.removed[clean] = true
+	prefix := clean + string(filepath.Separator)
+	for name := range m.files {
+		if name == clean || strings.HasPrefix(name, prefix) {
+			delete(m.files, name)
+		}
+	}
+	for dir := range m.dirs {
+		if dir == clean || strings.HasPrefix(dir, prefix) {
+			delete(m.dirs, dir)
+		}
+	}
+	return nil
+}
+
+// memWriter collects the content of a file created in a MemFileSystem.
+type memWriter struct {
+	bytes.Buffer
+	fs   *MemFileSystem
+	name string
+}
+
+func (w *memWriter) Close() error {
+	w.fs.files[w.name] = w.Bytes()
+	return nil
+}
+
+// memFile is a file of a MemFileSystem opened for reading.
+type memFile struct {
+	*bytes.Reader
+	info memFileInfo
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *memFile) Close() error               { return nil }
+
+// memFileInfo describes a file or directory of a MemFileSystem.
+type memFileInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i memFileInfo) Name() string       { return i.name }
+func (i memFileInfo) Size() int64        { return i.size }
+func (i memFileInfo) ModTime() time.Time { return time.Time{} }
+func (i memFileInfo) IsDir() bool        { return i.dir }
+func (i memFileInfo) Sys() any           { return nil }
+func (i memFileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0755
+	}
+	return 0644
+}
+
+// readFile reads a whole file from a FileSystem.
+func readFile(fsys FileSystem, name string) ([]byte, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// walkDir walks the file tree below root in lexical order, like filepath.WalkDir.
+func walkDir(fsys FileSystem, root string, fn fs.WalkDirFunc) error {
+	info, err := fsys.Stat(root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = walkDirEntry(fsys, root, fs.FileInfoToDirEntry(info), fn)
+	}
+	if err == filepath.SkipDir || err == filepath.SkipAll {
+		return nil
+	}
+	return err
+}
+
+// walkDirEntry calls fn for path and, for directories, for everything below it.
+func walkDirEntry(fsys FileSystem, path string, entry fs.DirEntry, fn fs.WalkDirFunc) error {
+	if err := fn(path, entry, nil); err != nil || !entry.IsDir() {
+		if err == filepath.SkipDir && entry.IsDir() {
+			err = nil
+		}
+		return err
+	}
+
+	entries, err := fsys.ReadDir(path)
+	if err != nil {
+		if err = fn(path, entry, err); err != nil {
+			if err == filepath.SkipDir {
+				err = nil
+			}
+			return err
+		}
+	}
+
+	for _, child := range entries {
+		if err := walkDirEntry(fsys, filepath.Join(path, child.Name()), child, fn); err != nil {
+			if err == filepath.SkipDir {
+				break
+			}
+			return err
+		}
+	}
+	return nil
+}
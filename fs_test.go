@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMemFileSystem_ConvertGraph tests a conversion that only happens in memory
+func TestMemFileSystem_ConvertGraph(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	graphDir := "graph"
+	fsys.WriteFile(filepath.Join(graphDir, "pages", "post.md"), []byte("type:: blog\nstatus:: online\ndate:: 2026-01-01\ntitle:: Memory\nauthor:: me\n\n- Hello ![img](../assets/photo.png)\n"))
+	fsys.WriteFile(filepath.Join(graphDir, "assets", "photo.png"), []byte("png"))
+	fsys.WriteFile(filepath.Join(graphDir, "assets", "ignored.md"), []byte("type:: blog"))
+
+	outputs, err := NewConverter(DefaultConfig(), fsys).ConvertGraph(context.Background(), graphDir, "out")
+	if err != nil {
+		t.Fatalf("ConvertGraph() error = %v", err)
+	}
+	if len(outputs) != 1 {
+		t.Fatalf("Expected 1 output, got %d", len(outputs))
+	}
+
+	index, err := readFile(fsys, filepath.Join("out", "2026-01-01_Memory", "index.de.md"))
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+	if !strings.Contains(string(index), "Hello ![img](photo.png)") {
+		t.Errorf("Unexpected content:\n%s", index)
+	}
+	if data, err := readFile(fsys, filepath.Join("out", "2026-01-01_Memory", "photo.png")); err != nil || string(data) != "png" {
+		t.Errorf("photo.png = %q, %v; want copied image", data, err)
+	}
+}
+
+// TestMemFileSystem_DryRun tests that a memory layer on top of the disk never writes to the disk
+func TestMemFileSystem_DryRun(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "post.md")
+	if err := os.WriteFile(input, []byte("type:: blog\nstatus:: online\ndate:: 2026-01-01\ntitle:: Dry\nauthor:: me\n\n- Text\n"), 0644); err != nil {
+		t.Fatalf("Failed to create input: %v", err)
+	}
+	outputDir := filepath.Join(dir, "out")
+
+	fsys := NewMemFileSystem(OSFileSystem{})
+	if _, err := NewConverter(DefaultConfig(), fsys).ConvertFile(context.Background(), input, outputDir); err != nil {
+		t.Fatalf("ConvertFile() error = %v", err)
+	}
+
+	if _, err := os.Stat(outputDir); !os.IsNotExist(err) {
+		t.Errorf("Dry run created %s on disk", outputDir)
+	}
+	want := []string{filepath.Join(outputDir, "2026-01-01_Dry", GeneratedFile), filepath.Join(outputDir, "2026-01-01_Dry", "index.de.md")}
+	if got := fsys.Files(); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Files() = %v, want %v", got, want)
+	}
+}
+
+// TestMemFileSystem_RemoveAll tests that removed files of the base file system are no longer visible
+func TestMemFileSystem_RemoveAll(t *testing.T) {
+	base := NewMemFileSystem(nil)
+	base.WriteFile(filepath.Join("out", "2026-01-01_Old", "index.de.md"), []byte("old"))
+	base.WriteFile(filepath.Join("out", "2026-01-01_Old", "photo.png"), []byte("png"))
+	base.WriteFile(filepath.Join("out", "2026-01-02_Kept", "index.de.md"), []byte("kept"))
+
+	fsys := NewMemFileSystem(base)
+	if err := fsys.RemoveAll(filepath.Join("out", "2026-01-01_Old")); err != nil {
+		t.Fatalf("RemoveAll() error = %v", err)
+	}
+
+	for _, name := range []string{filepath.Join("out", "2026-01-01_Old"), filepath.Join("out", "2026-01-01_Old", "index.de.md")} {
+		if _, err := fsys.Stat(name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Stat(%s) error = %v, want not exist", name, err)
+		}
+		if _, err := fsys.Open(name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Open(%s) error = %v, want not exist", name, err)
+		}
+	}
+	entries, err := fsys.ReadDir("out")
+	if err != nil || len(entries) != 1 || entries[0].Name() != "2026-01-02_Kept" {
+		t.Errorf("ReadDir(out) = %v, %v; want only 2026-01-02_Kept", entries, err)
+	}
+
+	// A bundle written again only has its new files
+	fsys.WriteFile(filepath.Join("out", "2026-01-01_Old", "index.de.md"), []byte("new"))
+	if data, err := readFile(fsys, filepath.Join("out", "2026-01-01_Old", "index.de.md")); err != nil || string(data) != "new" {
+		t.Errorf("index.de.md = %q, %v; want the new file", data, err)
+	}
+	if _, err := fsys.Stat(filepath.Join("out", "2026-01-01_Old", "photo.png")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat(photo.png) error = %v, want not exist", err)
+	}
+	if got := fsys.Removed(); len(got) != 1 || got[0] != filepath.Join("out", "2026-01-01_Old") {
+		t.Errorf("Removed() = %v", got)
+	}
+}
+
+// TestConvertGraph_Cancelled tests that a cancelled context stops the conversion
+func TestConvertGraph_Cancelled(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	fsys.WriteFile(filepath.Join("graph", "pages", "post.md"), []byte("type:: blog\nstatus:: online\ndate:: 2026-01-01\ntitle:: Cancelled\nauthor:: me\n\n- Text\n"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewConverter(DefaultConfig(), fsys).ConvertGraph(ctx, "graph", "out")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ConvertGraph() error = %v, want %v", err, context.Canceled)
+	}
+	if files := fsys.Files(); len(files) != 1 {
+		t.Errorf("Cancelled conversion wrote files: %v", files)
+	}
+}
+
+// TestConvertFile_PruneExpired tests removing the bundle of an expired post
+func TestConvertFile_PruneExpired(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	fsys.WriteFile("post.md", []byte("type:: blog\nstatus:: online\ndate:: 2026-01-01\ntitle:: Sale\nexpirydate:: 2026-02-01\n\n- Until February\n"))
+	fsys.WriteFile(filepath.Join("out", "2026-01-01_Sale", "index.de.md"), []byte("old"))
+
+	config := DefaultConfig()
+	config.Output.PruneExpired = true
+	converter := NewConverter(config, fsys)
+
+	// Before the expiry date the post is written with its expiry date
+	converter.now = func() time.Time { return time.Date(2026, 1, 15, 12, 0, 0, 0, time.Local) }
+	if _, err := converter.ConvertFile(context.Background(), "post.md", "out"); err != nil {
+		t.Fatalf("ConvertFile() error = %v", err)
+	}
+	index, _ := readFile(fsys, filepath.Join("out", "2026-01-01_Sale", "index.de.md"))
+	if !strings.Contains(string(index), `expiryDate = "2026-02-01"`) {
+		t.Errorf("Expected expiryDate in front matter:\n%s", index)
+	}
+
+	// Afterwards the bundle is removed
+	converter.now = func() time.Time { return time.Date(2026, 2, 1, 0, 0, 0, 0, time.Local) }
+	outputs, err := converter.ConvertFile(context.Background(), "post.md", "out")
+	if err != nil {
+		t.Fatalf("ConvertFile() error = %v", err)
+	}
+	if len(outputs) != 0 {
+		t.Errorf("Expected no output for an expired post, got %d", len(outputs))
+	}
+	if got := fsys.Files(); strings.Join(got, "|") != "post.md" {
+		t.Errorf("Files() = %v, want only post.md", got)
+	}
+}
+
+// TestOSFileSystem_Permissions tests the configured permissions and owner of created files and directories
+func TestOSFileSystem_Permissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix permissions")
+	}
+	dir := t.TempDir()
+	if err := os.Chmod(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys, err := NewOSFileSystem(OutputConfig{FileMode: "0640", DirMode: "0750", Owner: fmt.Sprintf(":%d", os.Getgid())})
+	if err != nil {
+		t.Fatalf("NewOSFileSystem() error = %v", err)
+	}
+	if fsys.Owner == nil || fsys.Owner.UID != -1 || fsys.Owner.GID != os.Getgid() {
+		t.Errorf("Owner = %+v, want the group %d only", fsys.Owner, os.Getgid())
+	}
+
+	bundle := filepath.Join(dir, "posts", "2026-01-17_Ibiza")
+	if err := fsys.MkdirAll(bundle, 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	f, err := fsys.Create(filepath.Join(bundle, "index.de.md"))
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	f.Close()
+
+	modes := map[string]os.FileMode{
+		dir:                                  0700, // Existing directories are not changed
+		filepath.Join(dir, "posts"):          0750,
+		bundle:                               0750,
+		filepath.Join(bundle, "index.de.md"): 0640,
+	}
+	for path, want := range modes {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode().Perm(); got != want {
+			t.Errorf("%s has permissions %o, want %o", path, got, want)
+		}
+	}
+}
+
+// TestNewOSFileSystem_Invalid tests rejecting invalid permissions and owners
+func TestNewOSFileSystem_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		config OutputConfig
+		want   string
+	}{
+		{"not octal", OutputConfig{FileMode: "0649"}, "file_mode: invalid permissions"},
+		{"too large", OutputConfig{DirMode: "1777"}, "dir_mode: invalid permissions"},
+		{"user name", OutputConfig{Owner: "www-data"}, "owner: invalid owner"},
+		{"no ids", OutputConfig{Owner: ":"}, "owner: invalid owner"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewOSFileSystem(tt.config)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("NewOSFileSystem() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
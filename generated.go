@@ -0,0 +1,101 @@
+// This file handles detecting index files that were edited by hand.
+// The SHA-256 of every index file the converter writes is recorded in a
+// hidden file in the bundle. An index file that no longer has its recorded
+// hash was changed after the conversion and isn't overwritten without -force.
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+)
+
+// GeneratedFile is the name of the file with the hashes of the generated index files.
+// Hugo doesn't publish files starting with a dot.
+const GeneratedFile = ".generated.json"
+
+// readGenerated reads the hashes of the generated index files of a bundle by file name.
+// A missing file has no hashes.
+func readGenerated(fsys FileSystem, bundleDir string) (map[string]string, error) {
+	path := filepath.Join(bundleDir, GeneratedFile)
+	data, err := readFile(fsys, path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	hashes := make(map[string]string)
+	if err := json.Unmarshal(data, &hashes); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return hashes, nil
+}
+
+// fileHash returns the SHA-256 of a file.
+func fileHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// editedByHand reports whether the index file of a bundle was changed after
+// the converter wrote it. Files without a recorded hash (written by an older
+// version or by hand from the start) and missing files are not edited.
+func (c *Converter) editedByHand(bundleDir, filename string) (bool, error) {
+	data, err := readFile(c.fs, filepath.Join(bundleDir, filename))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	hashes, err := readGenerated(c.fs, bundleDir)
+	if err != nil {
+		return false, err
+	}
+	hash, ok := hashes[filename]
+	return ok && hash != fileHash(data), nil
+}
+
+// recordGenerated records the hash of an index file the converter just wrote.
+func (c *Converter) recordGenerated(bundleDir, filename string) error {
+	data, err := readFile(c.fs, filepath.Join(bundleDir, filename))
+	if err != nil {
+		return err
+	}
+	hashes, err := readGenerated(c.fs, bundleDir)
+	if err != nil {
+		return err
+	}
+	hashes[filename] = fileHash(data)
+
+	data, err = json.MarshalIndent(hashes, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := c.fs.Create(filepath.Join(bundleDir, GeneratedFile))
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", GeneratedFile, err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("writing %s: %w", GeneratedFile, err)
+	}
+	return f.Close()
+}
+
+// writeConflicts lists the index files that were kept because they were edited by hand.
+func writeConflicts(w io.Writer, conflicts []string) {
+	if len(conflicts) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "Edited by hand, not overwritten (use -force to overwrite them)")
+	for _, path := range conflicts {
+		fmt.Fprintf(w, "  %s\n", path)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestConvertFile_EditedByHand tests that index files edited after the conversion are only overwritten with force
+func TestConvertFile_EditedByHand(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "post.md")
+	if err := os.WriteFile(input, []byte("type:: blog\nstatus:: online\ndate:: 2026-01-17\ntitle:: Ibiza\nauthor:: me\n\n- Wir segelten nach Ibiza.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outputDir := filepath.Join(dir, "out")
+	indexPath := filepath.Join(outputDir, "2026-01-17_Ibiza", "index.de.md")
+
+	convert := func(force bool) *Converter {
+		t.Helper()
+		converter := NewConverter(DefaultConfig(), OSFileSystem{})
+		converter.force = force
+		if _, err := converter.ConvertFile(context.Background(), input, outputDir); err != nil {
+			t.Fatalf("ConvertFile() error = %v", err)
+		}
+		return converter
+	}
+
+	// Converting an unchanged file again overwrites it
+	convert(false)
+	if converter := convert(false); len(converter.stats.Conflicts) != 0 {
+		t.Fatalf("unchanged file has conflicts %v", converter.stats.Conflicts)
+	}
+
+	// An edited file is kept and listed
+	generated, _ := os.ReadFile(indexPath)
+	edited := strings.Replace(string(generated), "Wir segelten", "Wir segelten bei Sturm", 1)
+	if err := os.WriteFile(indexPath, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+	converter := convert(false)
+	if len(converter.stats.Conflicts) != 1 || converter.stats.Conflicts[0] != indexPath || converter.stats.Skipped["edited by hand"] != 1 {
+		t.Errorf("Conflicts = %v, Skipped = %v, want %s", converter.stats.Conflicts, converter.stats.Skipped, indexPath)
+	}
+	if data, _ := os.ReadFile(indexPath); string(data) != edited {
+		t.Errorf("edited file was overwritten:\n%s", data)
+	}
+
+	// Force overwrites it and records the new hash
+	if converter := convert(true); len(converter.stats.Conflicts) != 0 {
+		t.Errorf("forced conversion has conflicts %v", converter.stats.Conflicts)
+	}
+	if data, _ := os.ReadFile(indexPath); string(data) != string(generated) {
+		t.Errorf("forced conversion kept the edited file:\n%s", data)
+	}
+	if converter := convert(false); len(converter.stats.Conflicts) != 0 {
+		t.Errorf("conversion after force has conflicts %v", converter.stats.Conflicts)
+	}
+
+	// Files without a recorded hash are overwritten
+	if err := os.Remove(filepath.Join(outputDir, "2026-01-17_Ibiza", GeneratedFile)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(indexPath, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if converter := convert(false); len(converter.stats.Conflicts) != 0 {
+		t.Errorf("file without a hash has conflicts %v", converter.stats.Conflicts)
+	}
+}
@@ -0,0 +1,116 @@
+// This file handles the external commands (hooks) that run for every converted post.
+// Hooks get the bundle directory and the post metadata, so users can run image
+// optimizers or custom scripts without changing the converter.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// Hook stages of HookConfig.Stage.
+const (
+	HookStagePre  = "pre"  // After the assets are copied, before the index file is written
+	HookStagePost = "post" // After the index file is written
+)
+
+// Hook failure policies of HookConfig.OnFailure.
+const (
+	HookFailureWarn   = "warn"   // Print a warning and continue
+	HookFailureFail   = "fail"   // Stop the conversion
+	HookFailureIgnore = "ignore" // Continue silently
+)
+
+// hookPost is the post metadata a hook receives as JSON on stdin.
+type hookPost struct {
+	Title    string   `json:"title"`
+	Date     string   `json:"date"`
+	Author   string   `json:"author"`
+	Language string   `json:"language"`
+	Summary  string   `json:"summary"`
+	Tags     []string `json:"tags"`
+	Slug     string   `json:"slug"`
+	Section  string   `json:"section"`
+	Dir      string   `json:"dir"`
+	Source   string   `json:"source"`
+}
+
+// stage returns the stage of a hook ("post" if none is given).
+func (h HookConfig) stage() string {
+	if h.Stage == "" {
+		return HookStagePost
+	}
+	return h.Stage
+}
+
+// name returns the name of a hook for messages (the command if it has no name).
+func (h HookConfig) name() string {
+	if h.Name != "" {
+		return fmt.Sprintf("'%s'", h.Name)
+	}
+	return fmt.Sprintf("'%s'", h.Command[0])
+}
+
+// runHooks runs the hooks of a stage for a post whose bundle is in outputDir.
+// Hooks only run on the real file system, so dry runs never start commands.
+// An error is only returned for failing hooks with the "fail" policy.
+func (c *Converter) runHooks(ctx context.Context, stage string, post *BlogPost, outputDir string) error {
+	if _, ok := c.fs.(OSFileSystem); !ok {
+		return nil
+	}
+
+	for _, hook := range c.config.Hooks {
+		if hook.stage() != stage || len(hook.Command) == 0 {
+			continue
+		}
+		if err := runHook(ctx, hook, post, outputDir); err != nil {
+			switch hook.OnFailure {
+			case HookFailureFail:
+				return fmt.Errorf("hook %s for '%s': %w", hook.name(), post.Meta.Title, err)
+			case HookFailureIgnore:
+			default:
+				fmt.Printf("Warning: hook %s failed for '%s': %v\n", hook.name(), post.Meta.Title, err)
+			}
+		}
+	}
+	return nil
+}
+
+// runHook runs a single hook command in the bundle directory.
+// The post is passed as JSON on stdin and as LOGSEQ2HUGO_* environment variables.
+func runHook(ctx context.Context, hook HookConfig, post *BlogPost, outputDir string) error {
+	data, err := json.Marshal(hookPost{
+		Title:    post.Meta.Title,
+		Date:     post.Meta.Date,
+		Author:   post.Meta.Author,
+		Language: post.Meta.Language,
+		Summary:  post.Meta.Summary,
+		Tags:     post.Meta.Tags,
+		Slug:     post.Slug,
+		Section:  post.Section,
+		Dir:      outputDir,
+		Source:   post.SourcePath,
+	})
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, hook.Command[0], hook.Command[1:]...)
+	cmd.Dir = outputDir
+	cmd.Env = append(os.Environ(),
+		"LOGSEQ2HUGO_BUNDLE_DIR="+outputDir,
+		"LOGSEQ2HUGO_POST_TITLE="+post.Meta.Title,
+		"LOGSEQ2HUGO_POST_DATE="+post.Meta.Date,
+		"LOGSEQ2HUGO_POST_SLUG="+post.Slug,
+		"LOGSEQ2HUGO_POST_SECTION="+post.Section,
+		"LOGSEQ2HUGO_POST_SOURCE="+post.SourcePath,
+	)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
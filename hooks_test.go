@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRunHooks tests running hook commands with the post metadata
+func TestRunHooks(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+	outputDir := t.TempDir()
+	post := &BlogPost{Meta: BlogMeta{Title: "Garden", Date: "2026-04-01"}, Slug: "2026-04-01_Garden"}
+
+	config := DefaultConfig()
+	config.Hooks = []HookConfig{
+		{Name: "env", Command: []string{"sh", "-c", `echo "$LOGSEQ2HUGO_POST_TITLE $LOGSEQ2HUGO_POST_SLUG" > env.txt`}},
+		{Name: "stdin", Command: []string{"sh", "-c", "cat > post.json"}},
+		{Name: "pre", Command: []string{"sh", "-c", "touch pre.txt"}, Stage: HookStagePre},
+		{Name: "broken", Command: []string{"sh", "-c", "exit 3"}},
+		{Name: "silent", Command: []string{"sh", "-c", "exit 1"}, OnFailure: HookFailureIgnore},
+	}
+	converter := NewConverter(config, OSFileSystem{})
+
+	if err := converter.runHooks(context.Background(), HookStagePost, post, outputDir); err != nil {
+		t.Fatalf("runHooks() error = %v", err)
+	}
+
+	if data, _ := os.ReadFile(filepath.Join(outputDir, "env.txt")); string(data) != "Garden 2026-04-01_Garden\n" {
+		t.Errorf("env.txt = %q, want title and slug", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(outputDir, "post.json")); !strings.Contains(string(data), `"title":"Garden"`) {
+		t.Errorf("post.json = %q, want the post metadata", data)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "pre.txt")); !os.IsNotExist(err) {
+		t.Errorf("Hook of the pre stage ran in the post stage")
+	}
+
+	// A failing hook with the "fail" policy stops the conversion
+	config.Hooks = []HookConfig{{Command: []string{"sh", "-c", "exit 3"}, OnFailure: HookFailureFail}}
+	err := converter.runHooks(context.Background(), HookStagePost, post, outputDir)
+	if err == nil || !strings.Contains(err.Error(), "hook 'sh' for 'Garden'") {
+		t.Errorf("runHooks() error = %v, want failing hook", err)
+	}
+
+	// Dry runs never start commands
+	dryRun := NewConverter(config, NewMemFileSystem(OSFileSystem{}))
+	if err := dryRun.runHooks(context.Background(), HookStagePost, post, outputDir); err != nil {
+		t.Errorf("runHooks() in a dry run error = %v", err)
+	}
+}
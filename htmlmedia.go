@@ -0,0 +1,46 @@
+// This file handles media in raw HTML tags pasted into the blocks:
+//
+//	<img src="../assets/hafen.jpg" width="300">
+//	<video controls poster="../assets/boot.jpg"><source src="../assets/boot.mp4"></video>
+//
+// The referenced assets are copied into the bundle and the attributes point
+// at the copies, the tags are kept with their other attributes.
+package main
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// htmlMediaTagRegex matches an opening HTML media tag.
+var htmlMediaTagRegex = regexp.MustCompile(`(?i)<(?:img|video|audio|source|track)\b[^>]*>`)
+
+// htmlMediaAttrRegex matches an attribute of a media tag with a file:
+// src="..." or poster='...' (the value in parts[3] or parts[4]).
+var htmlMediaAttrRegex = regexp.MustCompile(`(?i)(\s(?:src|poster)\s*=\s*)(?:"([^"]*)"|'([^']*)')`)
+
+// htmlAssetRegex splits an attribute value into the path to the assets and the file name.
+var htmlAssetRegex = regexp.MustCompile(`^(.*?assets[\\/])(.+)$`)
+
+// processHTMLMedia copies the assets of the media tags in the content and
+// rewrites their attributes to the files in the bundle. External files and
+// files outside of the assets are left alone.
+func (p *ImageProcessor) processHTMLMedia(ctx context.Context, content string) string {
+	return htmlMediaTagRegex.ReplaceAllStringFunc(content, func(tag string) string {
+		return htmlMediaAttrRegex.ReplaceAllStringFunc(tag, func(attr string) string {
+			parts := htmlMediaAttrRegex.FindStringSubmatch(attr)
+			value, quote := parts[2], `"`
+			if strings.HasPrefix(attr[len(parts[1]):], "'") {
+				value, quote = parts[3], "'"
+			}
+
+			asset := htmlAssetRegex.FindStringSubmatch(value)
+			if asset == nil || strings.Contains(value, "://") || ctx.Err() != nil {
+				return attr
+			}
+			filename := p.copyAsset(ctx, asset[1]+asset[2], slashPath(unescapePath(asset[2])))
+			return parts[1] + quote + markdownPath(filename) + quote
+		})
+	})
+}
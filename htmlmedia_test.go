@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestProcessContent_HTMLMedia tests copying the assets of raw HTML media tags
+func TestProcessContent_HTMLMedia(t *testing.T) {
+	inputDir := setupGraph(t, "hafen 1.jpg", "boot.mp4", "boot.jpg")
+	outputDir := t.TempDir()
+
+	processor := NewImageProcessor(NewCopyManager(OSFileSystem{}), inputDir, outputDir)
+	got := processor.ProcessContent(context.Background(),
+		`<img src="../assets/hafen%201.jpg" width="300" alt="Hafen">`+"\n"+
+			`<video controls poster='../assets/boot.jpg'><source src="../assets/boot.mp4" type="video/mp4"></video>`+"\n"+
+			`<img src="https://example.com/assets/logo.png"> <a href="../assets/boot.mp4">Video</a>`+"\n"+
+			"`<img src=\"../assets/example.png\">`")
+
+	want := `<img src="hafen%201.jpg" width="300" alt="Hafen">` + "\n" +
+		`<video controls poster='boot.jpg'><source src="boot.mp4" type="video/mp4"></video>` + "\n" +
+		`<img src="https://example.com/assets/logo.png"> <a href="../assets/boot.mp4">Video</a>` + "\n" +
+		"`<img src=\"../assets/example.png\">`"
+	if got != want {
+		t.Errorf("ProcessContent() =\n%s\nwant\n%s", got, want)
+	}
+	for _, name := range []string{"hafen 1.jpg", "boot.mp4", "boot.jpg"} {
+		if _, err := os.Stat(filepath.Join(outputDir, name)); err != nil {
+			t.Errorf("Expected %s to be copied: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// This file handles reference-style images:
+//
+//	![Hafen][hafen]
+//
+//	[hafen]: ../assets/hafen.jpg
+//
+// They are written as inline images before the media is processed, so their
+// assets are copied and rewritten like the ones of inline images.
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// linkDefinitionRegex matches a link reference definition on its own line:
+//
+//	^ {0,3}\[([^\]]+)\]:              = the label, e.g. [hafen]:
+//	[ \t]*(<[^>\n]*>|\S+)              = the destination, optionally in <...>
+//	(?:[ \t]+("[^"\n]*"|'[^'\n]*'|\([^)\n]*\)))? = an optional title
+var linkDefinitionRegex = regexp.MustCompile(`(?m)^ {0,3}\[([^\]]+)\]:[ \t]*(<[^>\n]*>|\S+)(?:[ \t]+("[^"\n]*"|'[^'\n]*'|\([^)\n]*\)))?[ \t]*$`)
+
+// referenceImageRegex matches a reference-style image: full (![alt][label]),
+// collapsed (![alt][]) or shortcut (![alt], which uses the alt text as label).
+var referenceImageRegex = regexp.MustCompile(`!\[([^\]]*)\](?:\[([^\]]*)\])?`)
+
+// normalizeLabel normalizes a link label for matching: case-insensitive and
+// with the whitespace collapsed, like markdown compares labels.
+func normalizeLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+// inlineImageReferences writes the reference-style images of the content as
+// inline images. Definitions that are only used by images are removed, the
+// others are kept for the links using them. Code blocks and code spans are
+// examples and are kept as they are.
+func inlineImageReferences(content string) string {
+	definitions := make(map[string]string)
+	for _, parts := range linkDefinitionRegex.FindAllStringSubmatch(codeRegex.ReplaceAllString(content, ""), -1) {
+		label := normalizeLabel(parts[1])
+		// The first definition of a label counts; spaces (allowed in <...>) are encoded for the inline image
+		if _, ok := definitions[label]; !ok {
+			dest := strings.TrimSuffix(strings.TrimPrefix(parts[2], "<"), ">")
+			definitions[label] = strings.ReplaceAll(dest, " ", "%20")
+		}
+	}
+	if len(definitions) == 0 {
+		return content
+	}
+
+	used := make(map[string]bool)
+	content = replaceOutsideCode(content, func(text string) string {
+		var b strings.Builder
+		last := 0
+		for _, loc := range referenceImageRegex.FindAllStringSubmatchIndex(text, -1) {
+			// Inline images (![alt](path)) are no references
+			if loc[1] < len(text) && text[loc[1]] == '(' && loc[4] < 0 {
+				continue
+			}
+			alt := text[loc[2]:loc[3]]
+			label := alt
+			if loc[4] >= 0 && loc[5] > loc[4] {
+				label = text[loc[4]:loc[5]]
+			}
+			dest, ok := definitions[normalizeLabel(label)]
+			if !ok {
+				continue
+			}
+			used[normalizeLabel(label)] = true
+			b.WriteString(text[last:loc[0]])
+			b.WriteString("![" + alt + "](" + dest + ")")
+			last = loc[1]
+		}
+		b.WriteString(text[last:])
+		return b.String()
+	})
+	if len(used) == 0 {
+		return content
+	}
+
+	// Definitions still used by links stay, the others are removed with their line
+	rest := codeRegex.ReplaceAllString(content, "")
+	rest = strings.ToLower(markdownImageRegex.ReplaceAllString(linkDefinitionRegex.ReplaceAllString(rest, ""), ""))
+	code := codeRegex.FindAllStringIndex(content, -1)
+	var b strings.Builder
+	last := 0
+	for _, loc := range linkDefinitionRegex.FindAllStringSubmatchIndex(content, -1) {
+		label := normalizeLabel(content[loc[2]:loc[3]])
+		if !used[label] || strings.Contains(rest, "["+label+"]") || insideRanges(code, loc[0]) {
+			continue
+		}
+		end := loc[1]
+		if end < len(content) && content[end] == '\n' {
+			end++
+		}
+		// A definition between two paragraphs leaves a single empty line
+		if strings.HasSuffix(content[:loc[0]], "\n\n") && end < len(content) && content[end] == '\n' {
+			end++
+		}
+		b.WriteString(content[last:loc[0]])
+		last = end
+	}
+	b.WriteString(content[last:])
+	if last == len(content) {
+		return strings.TrimRight(b.String(), " \t\n")
+	}
+	return b.String()
+}
+
+// insideRanges reports whether the index i is inside one of the ranges [start, end).
+func insideRanges(ranges [][]int, i int) bool {
+	for _, r := range ranges {
+		if i >= r[0] && i < r[1] {
+			return true
+		}
+	}
+	return false
+}
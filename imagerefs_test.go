@@ -0,0 +1,44 @@
+package main
+
+import "testing"
+
+// TestInlineImageReferences tests writing reference-style images as inline images
+func TestInlineImageReferences(t *testing.T) {
+	tests := []struct {
+		name, content, want string
+	}{
+		{
+			name:    "full reference",
+			content: "![Hafen][hafen]\n\n[hafen]: ../assets/hafen.jpg",
+			want:    "![Hafen](../assets/hafen.jpg)",
+		},
+		{
+			name:    "collapsed and shortcut references with titles",
+			content: "Intro\n\n![Boot][] ![boot]\n\n[BOOT]: <../assets/boot 1.jpg> \"Das Boot\"\n\nText",
+			want:    "Intro\n\n![Boot](../assets/boot%201.jpg) ![boot](../assets/boot%201.jpg)\n\nText",
+		},
+		{
+			name:    "definition used by a link",
+			content: "![Karte][karte] and the [map][karte]\n\n[karte]: ../assets/karte.png",
+			want:    "![Karte](../assets/karte.png) and the [map][karte]\n\n[karte]: ../assets/karte.png",
+		},
+		{
+			name:    "inline images and unknown labels",
+			content: "![a](../assets/a.png) ![b][missing]",
+			want:    "![a](../assets/a.png) ![b][missing]",
+		},
+		{
+			name:    "code",
+			content: "`![Hafen][hafen]`\n\n```\n[hafen]: ../assets/hafen.jpg\n```",
+			want:    "`![Hafen][hafen]`\n\n```\n[hafen]: ../assets/hafen.jpg\n```",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inlineImageReferences(tt.content); got != tt.want {
+				t.Errorf("inlineImageReferences() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
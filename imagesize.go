@@ -0,0 +1,84 @@
+// This file handles the size hints Logseq writes after resized images:
+// ![photo](../assets/photo.jpg){:height 400, :width 600}
+// The hints are removed, or the image becomes the configured image shortcode
+// with the size, e.g. {{< figure src="photo.jpg" alt="photo" width="600" height="400" >}}.
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ImageSize is the size of an image from its Logseq size hints (0 = not given).
+type ImageSize struct {
+	Width  int
+	Height int
+}
+
+// sizedImageRegex matches a markdown image with Logseq size hints:
+//
+//	!\[([^\]]*)\] = alt text
+//	\(([^)]*)\)   = path of the image
+//	(\{:[^}]*\})  = size hints starting with a keyword, e.g. {:height 400, :width 600}
+var sizedImageRegex = regexp.MustCompile(`!\[([^\]]*)\]\(([^)]*)\)(\{:[^}]*\})`)
+
+// imageSizeHintRegex matches a single size hint: ":width 600" or ":height 233.5".
+var imageSizeHintRegex = regexp.MustCompile(`:(width|height)\s+(\d+(?:\.\d+)?)`)
+
+// parseSizeHints returns the size of the hints of an image ("{:height 400, :width 600}").
+// Fractional sizes are rounded, other hints are ignored.
+func parseSizeHints(hints string) ImageSize {
+	var size ImageSize
+	for _, hint := range imageSizeHintRegex.FindAllStringSubmatch(hints, -1) {
+		value, err := strconv.ParseFloat(hint[2], 64)
+		if err != nil {
+			continue
+		}
+		if hint[1] == "width" {
+			size.Width = int(math.Round(value))
+		} else {
+			size.Height = int(math.Round(value))
+		}
+	}
+	return size
+}
+
+// resizeImages removes the size hints of the images in the content. With a
+// shortcode, images with a size are written as the shortcode with width and height.
+// Images in code blocks and code spans are examples and are kept as they are.
+func resizeImages(content, shortcode string) string {
+	return replaceOutsideCode(content, func(text string) string {
+		return sizedImageRegex.ReplaceAllStringFunc(text, func(match string) string {
+			parts := sizedImageRegex.FindStringSubmatch(match)
+			size := parseSizeHints(parts[3])
+			if shortcode == "" || (size.Width == 0 && size.Height == 0) {
+				return "![" + parts[1] + "](" + parts[2] + ")"
+			}
+			return imageShortcode(shortcode, parts[2], parts[1], size)
+		})
+	})
+}
+
+// imageShortcode renders the image shortcode of an image with its size and
+// further attributes (already formatted, e.g. loading="lazy").
+func imageShortcode(shortcode, src, alt string, size ImageSize, attrs ...string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `{{< %s src="%s"`, shortcode, strings.ReplaceAll(src, `"`, `\"`))
+	if alt != "" {
+		fmt.Fprintf(&b, ` alt="%s"`, strings.ReplaceAll(alt, `"`, `\"`))
+	}
+	if size.Width > 0 {
+		fmt.Fprintf(&b, ` width="%d"`, size.Width)
+	}
+	if size.Height > 0 {
+		fmt.Fprintf(&b, ` height="%d"`, size.Height)
+	}
+	for _, attr := range attrs {
+		b.WriteString(" " + attr)
+	}
+	b.WriteString(" >}}")
+	return b.String()
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+// TestResizeImages tests removing the Logseq size hints or writing them into the image shortcode
+func TestResizeImages(t *testing.T) {
+	tests := []struct {
+		name, content, shortcode, want string
+	}{
+		{
+			name:    "hints removed",
+			content: "![Hafen](hafen.jpg){:height 400, :width 600} and ![](https://example.com/a.png){:height 10 :width 20}",
+			want:    "![Hafen](hafen.jpg) and ![](https://example.com/a.png)",
+		},
+		{
+			name:      "shortcode",
+			content:   "![Der \"Hafen\"](hafen.jpg){:height 233.5, :width 600}",
+			shortcode: "figure",
+			want:      `{{< figure src="hafen.jpg" alt="Der \"Hafen\"" width="600" height="234" >}}`,
+		},
+		{
+			name:      "only the width",
+			content:   "![](hafen.jpg){:width 600}",
+			shortcode: "figure",
+			want:      `{{< figure src="hafen.jpg" width="600" >}}`,
+		},
+		{
+			name:      "no size",
+			content:   "![Hafen](hafen.jpg){:class wide}",
+			shortcode: "figure",
+			want:      "![Hafen](hafen.jpg)",
+		},
+		{
+			name:      "code",
+			content:   "`![Hafen](hafen.jpg){:height 400, :width 600}`",
+			shortcode: "figure",
+			want:      "`![Hafen](hafen.jpg){:height 400, :width 600}`",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resizeImages(tt.content, tt.shortcode); got != tt.want {
+				t.Errorf("resizeImages() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestProcessContent_SizeHints tests the size hints of images copied into the bundle
+func TestProcessContent_SizeHints(t *testing.T) {
+	inputDir := setupGraph(t, "hafen.jpg", "boot.jpg")
+
+	processor := NewImageProcessor(NewCopyManager(OSFileSystem{}), inputDir, t.TempDir())
+	processor.sizeShortcode = "figure"
+	got := processor.ProcessContent(context.Background(), "![Hafen](../assets/hafen.jpg){:height 400, :width 600}\n![Boot](../assets/boot.jpg)")
+
+	want := "{{< figure src=\"hafen.jpg\" alt=\"Hafen\" width=\"600\" height=\"400\" >}}\n![Boot](boot.jpg)"
+	if got != want {
+		t.Errorf("ProcessContent() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,236 @@
+// This file implements the "import-all" subcommand.
+// It is meant for the one-time migration of a whole graph: every qualifying post
+// of all journals and pages is converted in one run, and a migration report
+// with statistics shows what was converted, what was skipped and why.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ConversionStats counts what a conversion did, for the migration report.
+type ConversionStats struct {
+	Files        int               // Markdown files scanned
+	SkippedFiles int               // Files that could not be read and whiteboards
+	Found        map[string]int    // Extracted posts by content type ("blog post", "recipe")
+	Issues       int               // Malformed posts, flashcards etc. skipped while extracting
+	Skipped      map[string]int    // Posts that were not converted by reason ("status 'draft'")
+	Converted    int               // Bundles written
+	Links        int               // Links between posts rewritten to Hugo links
+	Unresolved   map[string]int    // Links to pages that are not converted, by page name
+	Proofread    map[string]int    // Proofread posts by language
+	Proofreading []ProofreadResult // Posts with typos or grammar issues
+	Conflicts    []string          // Index files edited by hand that were not overwritten
+	Assets       []BundleAsset     // Media written into the bundles, by post
+	Oversized    []string          // Bundles over the size budget with their largest assets
+
+	Transcoded     int   // Videos re-encoded with ffmpeg
+	VideoSize      int64 // Size of the transcoded videos before
+	TranscodedSize int64 // and after transcoding
+}
+
+// newConversionStats creates empty statistics.
+func newConversionStats() *ConversionStats {
+	return &ConversionStats{
+		Found:      make(map[string]int),
+		Skipped:    make(map[string]int),
+		Unresolved: make(map[string]int),
+		Proofread:  make(map[string]int),
+	}
+}
+
+// countLinks counts the page links of a block: links to converted posts
+// (they become Hugo links) and links to pages that are not converted.
+// Links in code are not links.
+func (s *ConversionStats) countLinks(block string, links map[string]string) {
+	replaceOutsideCode(block, func(text string) string {
+		for _, match := range internalLinkRegex.FindAllStringSubmatch(text, -1) {
+			page := strings.TrimSpace(match[2])
+			if _, ok := links[strings.ToLower(page)]; ok {
+				s.Links++
+			} else {
+				s.Unresolved[page]++
+			}
+		}
+		return text
+	})
+}
+
+// WriteReport writes the migration report.
+func (s *ConversionStats) WriteReport(w io.Writer, duration time.Duration) {
+	fmt.Fprintln(w, "Migration report")
+	fmt.Fprintf(w, "  Converter:         %s\n", toolVersion())
+	fmt.Fprintf(w, "  Files scanned:     %d (%d skipped)\n", s.Files, s.SkippedFiles)
+	fmt.Fprintf(w, "  Posts found:       %s\n", formatCounts(s.Found, 0))
+	fmt.Fprintf(w, "  Converted:         %d\n", s.Converted)
+	fmt.Fprintf(w, "  Skipped:           %s\n", formatCounts(s.Skipped, 0))
+	fmt.Fprintf(w, "  Extraction issues: %d\n", s.Issues)
+	fmt.Fprintf(w, "  Links rewritten:   %d\n", s.Links)
+	fmt.Fprintf(w, "  Unresolved links:  %s\n", formatCounts(s.Unresolved, maxReportedPages))
+	if len(s.Proofread) > 0 {
+		issues := 0
+		for _, result := range s.Proofreading {
+			issues += len(result.Issues)
+		}
+		fmt.Fprintf(w, "  Proofread:         %s (%d issues in %d posts)\n", formatCounts(s.Proofread, 0), issues, len(s.Proofreading))
+	}
+	if s.Transcoded > 0 {
+		fmt.Fprintf(w, "  Videos transcoded: %d (%s)\n", s.Transcoded, sizeChange(s.VideoSize, s.TranscodedSize))
+	}
+	if len(s.Assets) > 0 {
+		fmt.Fprintf(w, "  Assets written:    %d (%s)\n", len(s.Assets), formatSize(assetsSize(s.Assets)))
+	}
+	fmt.Fprintf(w, "  Duration:          %s\n", duration.Round(time.Millisecond))
+
+	// The issues are listed by post, so they can be fixed before publishing
+	if len(s.Proofreading) > 0 {
+		fmt.Fprintln(w, "Proofreading issues")
+		for _, result := range s.Proofreading {
+			fmt.Fprintf(w, "  %s (%s)\n", result.Title, result.Language)
+			for _, issue := range result.Issues {
+				fmt.Fprintf(w, "    %s\n", issue)
+			}
+		}
+	}
+	writeAssets(w, s.Assets)
+	if len(s.Oversized) > 0 {
+		fmt.Fprintln(w, "Bundles over the size budget")
+		for _, bundle := range s.Oversized {
+			fmt.Fprintf(w, "  %s\n", bundle)
+		}
+	}
+	writeConflicts(w, s.Conflicts)
+}
+
+// maxReportedPages is the number of unresolved link targets listed in the report
+// (journals link to many pages that are no posts).
+const maxReportedPages = 10
+
+// formatCounts formats counts by name, the most frequent first: "blog post: 12, recipe: 3".
+// With max > 0, only the max most frequent names are listed.
+func formatCounts(counts map[string]int, max int) string {
+	if len(counts) == 0 {
+		return "none"
+	}
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(a, b int) bool {
+		if counts[names[a]] != counts[names[b]] {
+			return counts[names[a]] > counts[names[b]]
+		}
+		return names[a] < names[b]
+	})
+
+	var parts []string
+	for i, name := range names {
+		if max > 0 && i == max {
+			parts = append(parts, fmt.Sprintf("%d more", len(names)-max))
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%s: %d", name, counts[name]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// runImportAll runs the import-all subcommand and returns the process exit code.
+// Usage: go run . import-all [-config converter.toml] [-dry-run] [-interactive] [-force] [-report report.txt] [-digest digest.md] [-digest-since YYYY-MM-DD] <graph_directory> <output_directory>
+func runImportAll(args []string) int {
+	flags := flag.NewFlagSet("import-all", flag.ContinueOnError)
+	configPath := flags.String("config", "", "path to a converter.toml configuration file")
+	dryRun := flags.Bool("dry-run", false, "convert without writing anything to the output directory")
+	interactive := flags.Bool("interactive", false, "show each post and ask whether to convert it")
+	force := flags.Bool("force", false, "overwrite index files that were edited by hand since the last conversion")
+	reportPath := flags.String("report", "", "also write the migration report to this file")
+	digestPath := flags.String("digest", "", "write a digest of the converted posts to this file (.md or .html)")
+	digestSince := flags.String("digest-since", "", "leave posts dated before this date (YYYY-MM-DD) out of the digest")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() < 2 {
+		fmt.Println("Usage: go run . import-all [-config converter.toml] [-dry-run] [-interactive] [-force] [-report report.txt] [-digest digest.md] [-digest-since YYYY-MM-DD] <graph_directory> <output_directory>")
+		return 2
+	}
+	graphDir, outputBasePath := flags.Arg(0), flags.Arg(1)
+
+	if info, err := os.Stat(graphDir); err != nil || !info.IsDir() {
+		fmt.Printf("Error: %s is not a graph directory\n", graphDir)
+		return 2
+	}
+
+	var since time.Time
+	if *digestSince != "" {
+		var ok bool
+		if since, ok = parseDate(*digestSince, time.Local); !ok {
+			fmt.Printf("Error: invalid -digest-since date %q (use YYYY-MM-DD)\n", *digestSince)
+			return 2
+		}
+	}
+
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 2
+	}
+
+	// A dry run writes into memory on top of the real files
+	osfs, err := NewOSFileSystem(config.Output)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 2
+	}
+	var fsys FileSystem = osfs
+	if *dryRun {
+		fsys = NewMemFileSystem(OSFileSystem{})
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// All posts are extracted before the first one is written,
+	// so links between posts resolve across the whole graph
+	converter := NewConverter(config, fsys)
+	converter.force = *force
+	if *interactive {
+		converter.review = NewPostReviewer(os.Stdin, os.Stdout)
+	}
+	if *digestPath != "" {
+		converter.digest = &Digest{Title: config.Digest.Title, Since: since}
+	}
+	start := time.Now()
+	if _, err := converter.ConvertGraph(ctx, graphDir, outputBasePath); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 1
+	}
+
+	var report strings.Builder
+	converter.stats.WriteReport(&report, time.Since(start))
+	fmt.Print(report.String())
+	if *reportPath != "" {
+		if err := os.WriteFile(*reportPath, []byte(report.String()), 0644); err != nil {
+			fmt.Printf("Error: writing report: %v\n", err)
+			return 1
+		}
+	}
+	if *digestPath != "" {
+		var digest strings.Builder
+		if err := renderDigest(&digest, converter.digest, config.Digest.Template, isHTMLFile(*digestPath)); err != nil {
+			fmt.Printf("Error: digest: %v\n", err)
+			return 1
+		}
+		if err := os.WriteFile(*digestPath, []byte(digest.String()), 0644); err != nil {
+			fmt.Printf("Error: writing digest: %v\n", err)
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRunImportAll tests importing a whole graph and writing the migration report
+func TestRunImportAll(t *testing.T) {
+	graphDir := t.TempDir()
+	outputDir := t.TempDir()
+	reportPath := filepath.Join(t.TempDir(), "report.txt")
+	digestPath := filepath.Join(t.TempDir(), "digest.md")
+
+	files := map[string]string{
+		filepath.Join("journals", "2026_01_17.md"): "- [[Sailing]]\n\t- type:: blog\n\t  status:: online\n\t  date:: 2026-01-17\n\t  title:: Renan\n\t- See [[Home]] and [[Sailing]]\n",
+		filepath.Join("journals", "2026_01_18.md"): "- Blog\n\t- type:: blog\n\t  status:: draft\n\t  date:: 2026-01-18\n\t  title:: Draft\n\t- Text\n",
+		filepath.Join("pages", "Home.md"):          "type:: blog\nstatus:: online\ndate:: 2026-01-01\ntitle:: Home\n\n- Welcome\n",
+		filepath.Join("whiteboards", "Trip.md"):    "type:: blog\ntitle:: Copy\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(graphDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	if code := runImportAll([]string{"-report", reportPath, "-digest", digestPath, "-digest-since", "2026-01-10", graphDir, outputDir}); code != 0 {
+		t.Fatalf("runImportAll() = %d, want 0", code)
+	}
+
+	for _, bundle := range []string{"2026-01-17_Renan", "2026-01-01_Home"} {
+		if _, err := os.Stat(filepath.Join(outputDir, bundle, "index.de.md")); err != nil {
+			t.Errorf("Bundle %s not written: %v", bundle, err)
+		}
+	}
+
+	report, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("Report not written: %v", err)
+	}
+	for _, want := range []string{
+		"Files scanned:     4 (1 skipped)",
+		"Posts found:       blog post: 3",
+		"Converted:         2",
+		"Skipped:           status 'draft': 1",
+		"Links rewritten:   1",
+		"Unresolved links:  Sailing: 1",
+	} {
+		if !strings.Contains(string(report), want) {
+			t.Errorf("Report missing %q:\n%s", want, report)
+		}
+	}
+
+	// The digest has the converted posts since the date
+	digest, err := os.ReadFile(digestPath)
+	if err != nil {
+		t.Fatalf("Digest not written: %v", err)
+	}
+	if !strings.Contains(string(digest), "## [Renan](/2026-01-17_renan/)") || strings.Contains(string(digest), "[Home]") {
+		t.Errorf("Digest should only have the post since 2026-01-10:\n%s", digest)
+	}
+
+	if code := runImportAll([]string{filepath.Join(graphDir, "pages", "Home.md"), outputDir}); code != 2 {
+		t.Errorf("runImportAll() with a file = %d, want 2", code)
+	}
+}
+
+// TestCountLinks tests counting the resolved and unresolved page links, without the links in code
+func TestCountLinks(t *testing.T) {
+	stats := newConversionStats()
+	links := map[string]string{"second post": "2025-01-22_Second_Post"}
+	stats.countLinks("[[Second Post]] and [[Sailing]], not `[[Sailing]]`\n```\n[[Second Post]] [[Diving]]\n```", links)
+
+	if stats.Links != 1 {
+		t.Errorf("Links = %d, want 1", stats.Links)
+	}
+	if len(stats.Unresolved) != 1 || stats.Unresolved["Sailing"] != 1 {
+		t.Errorf("Unresolved = %v, want map[Sailing:1]", stats.Unresolved)
+	}
+}
+
+// TestWriteReport tests the format of the migration report
+func TestWriteReport(t *testing.T) {
+	stats := newConversionStats()
+	stats.Files = 3
+	stats.Found["blog post"] = 2
+	stats.Found["recipe"] = 5
+	for i := 0; i < maxReportedPages+2; i++ {
+		stats.Unresolved[string(rune('A'+i))] = 1
+	}
+
+	var report strings.Builder
+	stats.WriteReport(&report, 1500*time.Millisecond)
+	for _, want := range []string{
+		"Converter:         " + toolVersion() + "\n",
+		"Posts found:       recipe: 5, blog post: 2\n",
+		"Skipped:           none\n",
+		"Unresolved links:  A: 1, B: 1, C: 1, D: 1, E: 1, F: 1, G: 1, H: 1, I: 1, J: 1, 2 more\n",
+		"Duration:          1.5s\n",
+	} {
+		if !strings.Contains(report.String(), want) {
+			t.Errorf("Report missing %q:\n%s", want, report.String())
+		}
+	}
+}
+
+// TestWriteReport_Proofreading tests listing the proofreading issues in the migration report
+func TestWriteReport_Proofreading(t *testing.T) {
+	stats := newConversionStats()
+	var report strings.Builder
+	stats.WriteReport(&report, time.Second)
+	if strings.Contains(report.String(), "Proofread") {
+		t.Errorf("Report without proofreading lists it:\n%s", report.String())
+	}
+
+	stats.Proofread["german"] = 3
+	stats.Proofread["english"] = 1
+	stats.Proofreading = []ProofreadResult{{
+		Title:    "Renan",
+		Language: "german",
+		Issues: []ProofreadIssue{
+			{Text: "Wanderug", Message: "unknown word"},
+			{Text: "Wir ist", Suggestion: "Wir sind", Message: "subject-verb agreement"},
+		},
+	}}
+	report.Reset()
+	stats.WriteReport(&report, time.Second)
+	for _, want := range []string{
+		"Proofread:         german: 3, english: 1 (2 issues in 1 posts)\n",
+		"Proofreading issues\n  Renan (german)\n" +
+			"    \"Wanderug\": unknown word\n" +
+			"    \"Wir ist\" -> \"Wir sind\": subject-verb agreement\n",
+	} {
+		if !strings.Contains(report.String(), want) {
+			t.Errorf("Report missing %q:\n%s", want, report.String())
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// This file handles the IndieWeb metadata of the posts: the canonical address,
+// the copies of a post on other sites (syndication), where the site publishes
+// it and what it replies to. IndieWeb themes write them as microformats
+// (u-url, u-syndication, u-in-reply-to), so webmentions find the posts.
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// IndieWebMeta is the IndieWeb metadata of a post for the [params.indieweb] section.
+type IndieWebMeta struct {
+	Canonical   string   // Address of the post ("canonical::" property or below the configured URL)
+	Syndication []string // Copies of the post on other sites ("syndication::" property)
+	SyndicateTo []string // Where the post is published, e.g. Bridgy ("syndicate-to::" property or configured)
+	InReplyTo   string   // Post this one replies to ("in-reply-to::" property)
+}
+
+// propertyURLRegex matches the addresses in a property value, also inside markdown links.
+var propertyURLRegex = regexp.MustCompile(`https?://[^\s,)\]>]+`)
+
+// propertyURLs returns the addresses in a property value without duplicates:
+// "[Toot](https://mastodon.social/@me/1), https://bsky.app/..." -> both addresses.
+func propertyURLs(value string) []string {
+	var addresses []string
+	for _, address := range propertyURLRegex.FindAllString(value, -1) {
+		if !slices.Contains(addresses, address) {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
+
+// isHTTPAddress reports whether text is an absolute http(s) address.
+func isHTTPAddress(text string) bool {
+	address, err := url.Parse(text)
+	return err == nil && (address.Scheme == "http" || address.Scheme == "https") && address.Host != ""
+}
+
+// applyIndieWeb sets the IndieWeb metadata of a post from the configuration
+// and its "canonical::", "syndication::", "syndicate-to::" and "in-reply-to::"
+// properties. "syndicate-to:: none" keeps a post from being published elsewhere.
+func (c *Converter) applyIndieWeb(post *BlogPost) {
+	properties := post.Meta.Properties
+	indieWeb := &IndieWebMeta{
+		Canonical:   publishedURL(c.config.IndieWeb.URL, post),
+		Syndication: propertyURLs(properties["syndication"]),
+		SyndicateTo: slices.Clone(c.config.IndieWeb.SyndicateTo),
+	}
+	if canonical := propertyURLs(properties["canonical"]); len(canonical) > 0 {
+		indieWeb.Canonical = canonical[0]
+	}
+	if value, ok := properties["syndicate-to"]; ok {
+		indieWeb.SyndicateTo = propertyURLs(value)
+		if len(indieWeb.SyndicateTo) == 0 && !strings.EqualFold(strings.TrimSpace(value), "none") {
+			fmt.Printf("Warning: syndicate-to:: of '%s' must be addresses or none, not '%s'\n", post.Meta.Title, value)
+		}
+	}
+	if replyTo := propertyURLs(properties["in-reply-to"]); len(replyTo) > 0 {
+		indieWeb.InReplyTo = replyTo[0]
+	}
+	post.Meta.IndieWeb = indieWeb
+}
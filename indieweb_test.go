@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestPropertyURLs tests reading the addresses of a property value
+func TestPropertyURLs(t *testing.T) {
+	got := propertyURLs("[Toot](https://mastodon.social/@me/1), https://bsky.app/profile/me/post/2 <https://mastodon.social/@me/1>")
+	want := []string{"https://mastodon.social/@me/1", "https://bsky.app/profile/me/post/2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("propertyURLs() = %v, want %v", got, want)
+	}
+	if got := propertyURLs("none"); got != nil {
+		t.Errorf("propertyURLs() without addresses = %v", got)
+	}
+}
+
+// TestApplyIndieWeb tests the canonical address, syndication and reply metadata of posts
+func TestApplyIndieWeb(t *testing.T) {
+	config := DefaultConfig()
+	config.IndieWeb = IndieWebConfig{Enabled: true, URL: "https://example.com/posts/", SyndicateTo: []string{"https://brid.gy/publish/mastodon"}}
+	c := NewConverter(config, NewMemFileSystem(nil))
+
+	tests := []struct {
+		name       string
+		properties map[string]string
+		want       IndieWebMeta
+	}{
+		{
+			name: "configured",
+			want: IndieWebMeta{Canonical: "https://example.com/posts/2026-01-17_ibiza/", SyndicateTo: []string{"https://brid.gy/publish/mastodon"}},
+		},
+		{
+			name: "properties",
+			properties: map[string]string{
+				"canonical":    "https://medium.com/@me/ibiza",
+				"syndication":  "[Toot](https://mastodon.social/@me/1)",
+				"syndicate-to": "https://brid.gy/publish/bluesky",
+				"in-reply-to":  "https://other.blog/sailing/",
+			},
+			want: IndieWebMeta{
+				Canonical:   "https://medium.com/@me/ibiza",
+				Syndication: []string{"https://mastodon.social/@me/1"},
+				SyndicateTo: []string{"https://brid.gy/publish/bluesky"},
+				InReplyTo:   "https://other.blog/sailing/",
+			},
+		},
+		{
+			name:       "not syndicated",
+			properties: map[string]string{"syndicate-to": "none"},
+			want:       IndieWebMeta{Canonical: "https://example.com/posts/2026-01-17_ibiza/"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post := &BlogPost{Meta: BlogMeta{Title: "Ibiza", Properties: tt.properties}, Slug: "2026-01-17_Ibiza"}
+			c.applyIndieWeb(post)
+			if !reflect.DeepEqual(*post.Meta.IndieWeb, tt.want) {
+				t.Errorf("applyIndieWeb() = %+v, want %+v", *post.Meta.IndieWeb, tt.want)
+			}
+		})
+	}
+}
+
+// TestConvertGraph_IndieWeb tests the [params.indieweb] section of a converted post
+func TestConvertGraph_IndieWeb(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	fsys.WriteFile(filepath.Join("graph", "pages", "Renan.md"), []byte("type:: blog\nstatus:: online\ndate:: 2026-01-17\ntitle:: Renan\nsyndication:: https://mastodon.social/@me/1\nin_reply_to:: https://other.blog/jura/\n\n- Hiking\n"))
+
+	config := DefaultConfig()
+	config.IndieWeb = IndieWebConfig{Enabled: true, URL: "https://example.com/posts/"}
+	if _, err := NewConverter(config, fsys).ConvertGraph(context.Background(), "graph", "out"); err != nil {
+		t.Fatalf("ConvertGraph() error = %v", err)
+	}
+
+	index, _ := readFile(fsys, filepath.Join("out", "2026-01-17_Renan", "index.de.md"))
+	want := "[params.indieweb]\n" +
+		"  canonical = \"https://example.com/posts/2026-01-17_renan/\"\n" +
+		"  syndication = [\"https://mastodon.social/@me/1\"]\n" +
+		"  in_reply_to = \"https://other.blog/jura/\"\n"
+	if !strings.Contains(string(index), want) {
+		t.Errorf("index file has no IndieWeb section:\n%s", index)
+	}
+}
@@ -0,0 +1,419 @@
+// This file implements the "init" subcommand.
+// It inspects a graph and a Hugo site and writes a starter converter.toml:
+// the blog marker the graph uses, the languages of its posts, its asset folder
+// and the section of the site the posts belong to, so new users don't have
+// to find out these settings themselves.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"logseq-to-hugo-converter/internal/lang"
+)
+
+// initMarkers are the blog markers init looks for, the most common first.
+var initMarkers = []string{DefaultMarker, "#blog", "publish:: blog", "tags:: blog"}
+
+// hugoConfigFiles are the configuration files of a Hugo site, in the order Hugo reads them.
+var hugoConfigFiles = []string{
+	"hugo.toml", "hugo.yaml", "hugo.yml", "hugo.json",
+	"config.toml", "config.yaml", "config.yml", "config.json",
+	filepath.Join("config", "_default", "hugo.toml"), filepath.Join("config", "_default", "config.toml"),
+}
+
+// siteInfo is what init found out about a Hugo site.
+type siteInfo struct {
+	Dir             string
+	ConfigFile      string   // Configuration file relative to Dir ("hugo.toml")
+	ContentDir      string   // Content directory relative to Dir ("content")
+	Section         string   // Section with the most bundles ("posts"), "" if none
+	DefaultLanguage string   // Hugo language code of the default content language ("en")
+	Languages       []string // Hugo language codes of the site
+	Taxonomies      []string // Taxonomies of the site ("tags", "categories")
+}
+
+// graphInfo is what init found out about a graph.
+type graphInfo struct {
+	Dir         string
+	Format      string         // "Logseq", "Obsidian" or "Notion"
+	Marker      string         // Blog marker with the most posts
+	Posts       int            // Posts found with Marker
+	Languages   map[string]int // Posts by language ("german")
+	Ancestors   map[string]int // Posts by page bullet they are nested under ("Blog")
+	Assets      string         // Asset folder relative to Dir ("assets"), "" if none
+	AssetFiles  int            // Files in the asset folder
+	Attachments string         // Attachment folder of an Obsidian vault
+}
+
+// runInit runs the init subcommand and returns the process exit code.
+// Usage: go run . init -graph ~/logseq -site ~/blog [-output converter.toml] [-force]
+func runInit(args []string) int {
+	flags := flag.NewFlagSet("init", flag.ContinueOnError)
+	graphDir := flags.String("graph", "", "graph directory (Logseq graph, Obsidian vault or unpacked Notion export)")
+	siteDir := flags.String("site", "", "directory of the Hugo site")
+	outputPath := flags.String("output", "converter.toml", "configuration file to write")
+	force := flags.Bool("force", false, "overwrite an existing configuration file")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *graphDir == "" || *siteDir == "" {
+		fmt.Println("Usage: go run . init -graph <graph_directory> -site <hugo_site_directory> [-output converter.toml] [-force]")
+		return 2
+	}
+
+	if _, err := os.Stat(*outputPath); err == nil && !*force {
+		fmt.Printf("Error: %s already exists (use -force to overwrite it)\n", *outputPath)
+		return 2
+	}
+
+	graph, err := inspectGraph(OSFileSystem{}, *graphDir)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 2
+	}
+	site, err := inspectSite(*siteDir)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 2
+	}
+
+	if err := os.WriteFile(*outputPath, []byte(starterConfig(graph, site, *outputPath)), 0644); err != nil {
+		fmt.Printf("Error: writing %s: %v\n", *outputPath, err)
+		return 1
+	}
+	fmt.Printf("Wrote %s: %d posts found with marker %q\n", *outputPath, graph.Posts, graph.Marker)
+	fmt.Printf("Convert them with:\n  %s\n", importCommand(graph, site, *outputPath))
+	return 0
+}
+
+// inspectGraph finds the format, the blog marker, the languages and the asset folder of a graph.
+// The marker is the one of initMarkers that finds the most posts.
+func inspectGraph(fsys FileSystem, dir string) (*graphInfo, error) {
+	if info, err := fsys.Stat(dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a graph directory", dir)
+	}
+
+	config := DefaultConfig()
+	extractor, err := openExtractor(fsys, dir, config)
+	if err != nil {
+		return nil, err
+	}
+	graph := &graphInfo{Dir: dir, Format: "Logseq", Marker: DefaultMarker}
+	switch e := extractor.(type) {
+	case *obsidianVault:
+		graph.Format, graph.Attachments = "Obsidian", e.attachments
+	case notionExport:
+		graph.Format = "Notion"
+	}
+
+	files, err := findMarkdownFiles(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	var sources [][]byte
+	var paths []string
+	for _, file := range files {
+		if isWhiteboardFile(file) {
+			continue
+		}
+		source, err := readFile(fsys, file)
+		if err != nil {
+			continue
+		}
+		sources = append(sources, sanitizeSource(source))
+		paths = append(paths, file)
+	}
+
+	for _, candidate := range initMarkers {
+		marker, err := NewBlogMarker(candidate)
+		if err != nil {
+			return nil, err
+		}
+		var posts []*BlogPost
+		for i, source := range sources {
+			found, _ := extractor.Extract(paths[i], source, marker)
+			posts = append(posts, found...)
+		}
+		if len(posts) <= graph.Posts {
+			continue
+		}
+
+		graph.Marker, graph.Posts = candidate, len(posts)
+		graph.Languages = make(map[string]int)
+		graph.Ancestors = make(map[string]int)
+		for _, post := range posts {
+			graph.Languages[llmLanguage(post.Meta.Language)]++
+			for _, page := range post.Ancestors {
+				graph.Ancestors[page]++
+			}
+		}
+	}
+
+	// Logseq keeps the assets next to journals and pages
+	if graph.Format == "Logseq" {
+		assets := filepath.Join(dir, "assets")
+		if info, err := fsys.Stat(assets); err == nil && info.IsDir() {
+			graph.Assets = "assets"
+			walkDir(fsys, assets, func(path string, entry fs.DirEntry, err error) error {
+				if err == nil && !entry.IsDir() {
+					graph.AssetFiles++
+				}
+				return nil
+			})
+		}
+	}
+	return graph, nil
+}
+
+// inspectSite reads the configuration of a Hugo site and finds the section with the most bundles.
+func inspectSite(dir string) (*siteInfo, error) {
+	site := &siteInfo{Dir: dir, ContentDir: "content", DefaultLanguage: "en", Taxonomies: []string{"tags", "categories"}}
+
+	var settings map[string]interface{}
+	for _, name := range hugoConfigFiles {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		if settings, err = parseHugoConfig(name, data); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", name, err)
+		}
+		site.ConfigFile = name
+		break
+	}
+	if site.ConfigFile == "" {
+		return nil, fmt.Errorf("%s is not a Hugo site (no hugo.toml, config.toml, ...)", dir)
+	}
+
+	// Hugo's keys are case-insensitive
+	lower := make(map[string]interface{})
+	for key, value := range settings {
+		lower[strings.ToLower(key)] = value
+	}
+	if contentDir, ok := lower["contentdir"].(string); ok && contentDir != "" {
+		site.ContentDir = contentDir
+	}
+	if language, ok := lower["defaultcontentlanguage"].(string); ok && language != "" {
+		site.DefaultLanguage = strings.ToLower(language)
+	}
+	if languages, ok := lower["languages"].(map[string]interface{}); ok {
+		for code := range languages {
+			site.Languages = append(site.Languages, strings.ToLower(code))
+		}
+		sort.Strings(site.Languages)
+	}
+	if len(site.Languages) == 0 {
+		site.Languages = []string{site.DefaultLanguage}
+	}
+	if taxonomies, ok := lower["taxonomies"].(map[string]interface{}); ok {
+		site.Taxonomies = nil
+		for _, plural := range taxonomies {
+			if name, ok := plural.(string); ok {
+				site.Taxonomies = append(site.Taxonomies, name)
+			}
+		}
+		sort.Strings(site.Taxonomies)
+	}
+
+	site.Section = largestSection(filepath.Join(dir, site.ContentDir))
+	return site, nil
+}
+
+// parseHugoConfig reads a Hugo configuration file. YAML files are read with a
+// minimal parser: top-level "key: value" lines and the keys of the maps below
+// "languages:" and "taxonomies:", which is all init needs.
+func parseHugoConfig(name string, data []byte) (map[string]interface{}, error) {
+	settings := make(map[string]interface{})
+	switch filepath.Ext(name) {
+	case ".toml":
+		_, err := toml.Decode(string(data), &settings)
+		return settings, err
+	case ".json":
+		err := json.Unmarshal(data, &settings)
+		return settings, err
+	}
+
+	var current map[string]interface{}
+	childIndent := 0 // Indentation of the keys of current
+	for _, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.TrimSpace(key), unquoteYAML(strings.TrimSpace(value))
+		indent := indentation(line)
+		switch {
+		case indent == 0 && value == "":
+			current, childIndent = make(map[string]interface{}), 0
+			settings[key] = current
+		case indent == 0:
+			settings[key], current = value, nil
+		case current != nil && (childIndent == 0 || indent == childIndent):
+			current[key], childIndent = value, indent
+		}
+	}
+	return settings, nil
+}
+
+// indentation returns the number of leading spaces and tabs of a line.
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// largestSection returns the top-level directory of the content directory
+// with the most page bundles (directories with an index file), or "" if there is none.
+func largestSection(contentDir string) string {
+	counts := make(map[string]int)
+	filepath.WalkDir(contentDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || !strings.HasPrefix(entry.Name(), "index.") {
+			return nil
+		}
+		rel, err := filepath.Rel(contentDir, path)
+		if err != nil {
+			return nil
+		}
+		if section, _, ok := strings.Cut(filepath.ToSlash(rel), "/"); ok && strings.Contains(filepath.ToSlash(rel)[len(section)+1:], "/") {
+			counts[section]++
+		}
+		return nil
+	})
+
+	best := ""
+	for section, count := range counts {
+		if best == "" || count > counts[best] || (count == counts[best] && section < best) {
+			best = section
+		}
+	}
+	return best
+}
+
+// outputDir returns the directory the posts of the site are written to.
+func (s *siteInfo) outputDir() string {
+	section := s.Section
+	if section == "" {
+		section = "posts"
+	}
+	return filepath.Join(s.Dir, s.ContentDir, section)
+}
+
+// importCommand returns the command that converts the graph into the site.
+func importCommand(graph *graphInfo, site *siteInfo, configPath string) string {
+	return fmt.Sprintf("go run . import-all -config %s %s %s", configPath, graph.Dir, site.outputDir())
+}
+
+// starterConfig writes the configuration for the graph and site. Everything
+// init found out is explained in comments; settings are only written where
+// the defaults don't fit.
+func starterConfig(graph *graphInfo, site *siteInfo, configPath string) string {
+	var b strings.Builder
+	b.WriteString("# Starter configuration written by \"go run . init\".\n")
+	b.WriteString("# See the Configuration section of the README for all options.\n#\n")
+	fmt.Fprintf(&b, "# %s graph: %s\n", graph.Format, graph.Dir)
+	switch {
+	case graph.Assets != "":
+		fmt.Fprintf(&b, "#   Asset folder: %s/ (%d files)\n", graph.Assets, graph.AssetFiles)
+	case graph.Format == "Logseq":
+		b.WriteString("#   No assets/ folder: images of the posts won't be found\n")
+	}
+	fmt.Fprintf(&b, "#   %d posts found with the marker below\n", graph.Posts)
+	fmt.Fprintf(&b, "# Hugo site: %s (%s)\n", site.Dir, site.ConfigFile)
+	fmt.Fprintf(&b, "#   Languages: %s (default %s)\n", strings.Join(site.Languages, ", "), site.DefaultLanguage)
+	if site.Section != "" {
+		fmt.Fprintf(&b, "#   Posts are in %s/%s\n", filepath.ToSlash(site.ContentDir), site.Section)
+	} else {
+		fmt.Fprintf(&b, "#   No page bundles yet, posts go to %s/posts\n", filepath.ToSlash(site.ContentDir))
+	}
+	b.WriteString("#\n# Convert the graph with:\n")
+	fmt.Fprintf(&b, "#   %s\n", importCommand(graph, site, configPath))
+
+	// Languages of the posts that the site or the converter doesn't have
+	monolingual := len(site.Languages) == 1
+	if monolingual {
+		fmt.Fprintf(&b, "#\n# The site has one language (%s), the posts are written as index.md\n", site.Languages[0])
+	} else if len(graph.Languages) > 0 {
+		b.WriteString("#\n# Languages of the posts: " + formatCounts(graph.Languages, 0) + "\n")
+		for _, language := range sortedKeys(graph.Languages) {
+			tag, known := lang.Parse(language)
+			if !known {
+				fmt.Fprintf(&b, "#   %s is not a known language, these posts are written as German (index.de.md)\n", language)
+			} else if _, ok := tag.Match(site.Languages); !ok {
+				fmt.Fprintf(&b, "#   The site has no language %q for the %s posts (index.%s.md)\n", tag.Key(), language, tag.Key())
+			}
+		}
+		if site.DefaultLanguage != "de" && graph.Languages["german"] > 0 {
+			b.WriteString("#   Posts without a language:: property are German, add language:: english to English posts\n")
+		}
+	}
+
+	b.WriteString("\nmarker = " + strconv.Quote(graph.Marker) + "\n")
+
+	// The index files of a multilingual site use its language keys
+	if monolingual {
+		b.WriteString("\n[output]\nmonolingual = true\n")
+	} else {
+		var quoted []string
+		for _, key := range site.Languages {
+			if _, ok := lang.Parse(key); ok {
+				quoted = append(quoted, strconv.Quote(key))
+			}
+		}
+		b.WriteString("\n[language]\nkeys = [" + strings.Join(quoted, ", ") + "]\n")
+	}
+
+	if graph.Format == "Obsidian" && graph.Attachments != "" {
+		b.WriteString("\n[obsidian]\nattachments = " + strconv.Quote(graph.Attachments) + "\n")
+	}
+
+	// Page bullets the posts are nested under can become categories
+	if len(graph.Ancestors) > 0 && containsString(site.Taxonomies, AncestorsCategories) {
+		fmt.Fprintf(&b, "\n# Posts are nested under page bullets (%s).\n", formatCounts(graph.Ancestors, 5))
+		b.WriteString("# Uncomment to use these pages as categories:\n")
+		b.WriteString("# [categories]\n# from_ancestors = \"categories\"\n")
+		b.WriteString("# ignore = [" + strconv.Quote(mostCommon(graph.Ancestors)) + "]\n")
+	}
+	return b.String()
+}
+
+// sortedKeys returns the keys of counts in lexical order.
+func sortedKeys(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// mostCommon returns the key with the highest count (the first in lexical order on a tie).
+func mostCommon(counts map[string]int) string {
+	best := ""
+	for _, key := range sortedKeys(counts) {
+		if best == "" || counts[key] > counts[best] {
+			best = key
+		}
+	}
+	return best
+}
+
+// containsString reports whether list contains s.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
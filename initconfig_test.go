@@ -0,0 +1,193 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"logseq-to-hugo-converter/internal/testgraph"
+)
+
+// TestInspectSite tests reading the settings of Hugo sites with different configuration files
+func TestInspectSite(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    siteInfo
+		wantErr bool
+	}{
+		{
+			name: "hugo.toml with languages",
+			files: map[string]string{
+				"hugo.toml": "defaultContentLanguage = \"de\"\ncontentDir = \"inhalt\"\n\n[languages.de]\nweight = 1\n[languages.en]\nweight = 2\n\n[taxonomies]\ntag = \"tags\"\n",
+				"inhalt/blog/2026-01-17_Post/index.de.md": "+++\n+++\n",
+				"inhalt/blog/2026-01-18_Post/index.de.md": "+++\n+++\n",
+				"inhalt/notes/a/index.md":                 "+++\n+++\n",
+				"inhalt/about.md":                         "+++\n+++\n",
+			},
+			want: siteInfo{ConfigFile: "hugo.toml", ContentDir: "inhalt", Section: "blog", DefaultLanguage: "de",
+				Languages: []string{"de", "en"}, Taxonomies: []string{"tags"}},
+		},
+		{
+			name: "config.yaml",
+			files: map[string]string{
+				"config.yaml":                  "# Site\nDefaultContentLanguage: \"en\"\nlanguages:\n    en:\n        weight: 1\n    fr:\n        weight: 2\n",
+				"content/posts/hello/index.md": "---\n---\n",
+			},
+			want: siteInfo{ConfigFile: "config.yaml", ContentDir: "content", Section: "posts", DefaultLanguage: "en",
+				Languages: []string{"en", "fr"}, Taxonomies: []string{"tags", "categories"}},
+		},
+		{
+			name:  "hugo.json without content",
+			files: map[string]string{"hugo.json": `{"title": "Blog"}`},
+			want: siteInfo{ConfigFile: "hugo.json", ContentDir: "content", DefaultLanguage: "en",
+				Languages: []string{"en"}, Taxonomies: []string{"tags", "categories"}},
+		},
+		{
+			name:    "no Hugo site",
+			files:   map[string]string{"README.md": "# Blog\n"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeTestFile(t, filepath.Join(dir, name), content)
+			}
+
+			site, err := inspectSite(dir)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("inspectSite() = %+v, want an error", site)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("inspectSite() error = %v", err)
+			}
+			tt.want.Dir = dir
+			if !reflect.DeepEqual(*site, tt.want) {
+				t.Errorf("inspectSite() = %+v, want %+v", *site, tt.want)
+			}
+		})
+	}
+}
+
+// TestInspectGraph tests finding the blog marker, the languages and the assets of a graph
+func TestInspectGraph(t *testing.T) {
+	german := testgraph.Post{Title: "Frühling", Date: "2026-03-01", Blocks: []string{"Text."}}
+	english := testgraph.Post{Title: "Spring", Date: "2026-03-02", Properties: []string{"language:: english"}, Blocks: []string{"Text."}}
+	graph := testgraph.New().
+		Journal("2026_03_01", german.JournalBlock("[[Reisen]]")+english.JournalBlock("[[Reisen]]")).
+		Page("Garden", english.PageContent()).
+		Page("Other", "- #blog but no properties\n").
+		Image("assets/photo.png", 4, 4)
+
+	fsys := NewMemFileSystem(nil)
+	graph.WriteTo(fsys, "graph")
+
+	info, err := inspectGraph(fsys, "graph")
+	if err != nil {
+		t.Fatalf("inspectGraph() error = %v", err)
+	}
+	if info.Format != "Logseq" || info.Marker != DefaultMarker || info.Posts != 3 {
+		t.Errorf("inspectGraph() = %s, %q, %d posts, want Logseq, %q, 3 posts", info.Format, info.Marker, info.Posts, DefaultMarker)
+	}
+	if want := map[string]int{"german": 1, "english": 2}; !reflect.DeepEqual(info.Languages, want) {
+		t.Errorf("Languages = %v, want %v", info.Languages, want)
+	}
+	if want := map[string]int{"Reisen": 2}; !reflect.DeepEqual(info.Ancestors, want) {
+		t.Errorf("Ancestors = %v, want %v", info.Ancestors, want)
+	}
+	if info.Assets != "assets" || info.AssetFiles != 1 {
+		t.Errorf("Assets = %q (%d files), want assets (1 file)", info.Assets, info.AssetFiles)
+	}
+
+	if _, err := inspectGraph(fsys, "missing"); err == nil {
+		t.Error("inspectGraph() of a missing directory should fail")
+	}
+}
+
+// TestStarterConfig tests that the written configuration is valid and explains what was found
+func TestStarterConfig(t *testing.T) {
+	graph := &graphInfo{
+		Dir: "graph", Format: "Logseq", Marker: "#blog", Posts: 3,
+		Languages: map[string]int{"german": 2, "klingon": 1, "portuguese": 1},
+		Ancestors: map[string]int{"Blog": 3},
+	}
+	site := &siteInfo{Dir: "site", ConfigFile: "hugo.toml", ContentDir: "content", DefaultLanguage: "en",
+		Languages: []string{"en", "fr"}, Taxonomies: []string{"categories", "tags"}}
+
+	source := starterConfig(graph, site, "converter.toml")
+	config, issues := validateConfig("converter.toml", []byte(source))
+	if len(issues) > 0 {
+		t.Fatalf("starter configuration has issues %v:\n%s", issues, source)
+	}
+	if config.Marker != "#blog" {
+		t.Errorf("Marker = %q, want #blog", config.Marker)
+	}
+
+	for _, want := range []string{
+		"No assets/ folder",
+		"klingon is not a known language",
+		`The site has no language "pt" for the portuguese posts`,
+		`keys = ["en", "fr"]`,
+		`The site has no language "de" for the german posts`,
+		"Posts without a language:: property are German",
+		"go run . import-all -config converter.toml graph " + filepath.Join("site", "content", "posts"),
+		"# from_ancestors = \"categories\"\n# ignore = [\"Blog\"]",
+	} {
+		if !strings.Contains(source, want) {
+			t.Errorf("starter configuration is missing %q:\n%s", want, source)
+		}
+	}
+
+	site.Languages = []string{"en"}
+	source = starterConfig(graph, site, "converter.toml")
+	if config, issues := validateConfig("converter.toml", []byte(source)); len(issues) > 0 || !config.Output.Monolingual {
+		t.Errorf("starter configuration of a single-language site isn't monolingual (issues %v):\n%s", issues, source)
+	}
+	if strings.Contains(source, "klingon is not a known language") {
+		t.Errorf("starter configuration of a single-language site has language hints:\n%s", source)
+	}
+}
+
+// TestRunInit tests the init subcommand on a graph and a site on disk
+func TestRunInit(t *testing.T) {
+	dir := t.TempDir()
+	post := testgraph.Post{Title: "Post", Date: "2026-01-17", Blocks: []string{"Text."}}
+	if err := testgraph.New().Page("Post", post.PageContent()).WriteDir(filepath.Join(dir, "graph")); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(dir, "site", "hugo.toml"), "defaultContentLanguage = \"de\"\n")
+	output := filepath.Join(dir, "converter.toml")
+	args := []string{"-graph", filepath.Join(dir, "graph"), "-site", filepath.Join(dir, "site"), "-output", output}
+
+	if code := runInit(args); code != 0 {
+		t.Fatalf("runInit() = %d, want 0", code)
+	}
+	if _, err := LoadConfig(output); err != nil {
+		t.Errorf("LoadConfig() of the written configuration: %v", err)
+	}
+	if code := runInit(args); code != 2 {
+		t.Errorf("runInit() on an existing configuration = %d, want 2", code)
+	}
+	if code := runInit(append(args, "-force")); code != 0 {
+		t.Errorf("runInit() -force = %d, want 0", code)
+	}
+}
+
+// writeTestFile writes a file, creating its directory.
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,53 @@
+// This file handles the input formats of the converter. Each format has an
+// Extractor that finds the posts in a file; everything after the extraction
+// (filters, images, the Hugo writer) is the same for all formats.
+package main
+
+import (
+	"regexp"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/text"
+)
+
+// Extractor finds the posts of an input format (Logseq, Obsidian, Notion) in a file.
+// Further formats implement it and are detected in openExtractor.
+type Extractor interface {
+	// Extract returns the posts in a file marked by marker and the problems
+	// of posts that were skipped (with their line in the file).
+	Extract(path string, source []byte, marker *BlogMarker) ([]*BlogPost, []ExtractionIssue)
+
+	// AssetRegex matches the image references whose files are copied into the bundles.
+	// It captures the alt text, the directory and the filename (see ImageProcessor).
+	AssetRegex() *regexp.Regexp
+}
+
+// logseqGraph extracts the blog posts of Logseq pages and journals.
+type logseqGraph struct{}
+
+// Extract parses the markdown and extracts the posts in both Logseq formats.
+func (logseqGraph) Extract(path string, source []byte, marker *BlogMarker) ([]*BlogPost, []ExtractionIssue) {
+	doc := goldmark.New().Parser().Parse(text.NewReader(source))
+	return extractBlogPosts(doc, source, marker)
+}
+
+// AssetRegex matches the images in the assets folder of the graph.
+func (logseqGraph) AssetRegex() *regexp.Regexp {
+	return logseqAssetRegex
+}
+
+// openExtractor detects the input format of a directory (the graph directory
+// or the directory of a single input file). Logseq is the default.
+func openExtractor(fsys FileSystem, dir string, config *Config) (Extractor, error) {
+	vault, err := openVault(fsys, dir, config.Obsidian)
+	if err != nil {
+		return nil, err
+	}
+	if vault != nil {
+		return vault, nil
+	}
+	if isNotionExport(fsys, dir) {
+		return notionExport{}, nil
+	}
+	return logseqGraph{}, nil
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"logseq-to-hugo-converter/internal/testgraph"
+)
+
+// integrationCase is a synthetic graph with the bundles its conversion should write.
+// Add a case here to cover a regression end to end.
+type integrationCase struct {
+	name      string
+	graph     *testgraph.Graph
+	configure func(*Config)       // Changes the default configuration (optional)
+	want      map[string][]string // Files in the output directory -> texts they contain
+	missing   []string            // Files that must not be written
+	wantErr   bool
+}
+
+// integrationCases covers edge cases of journals, pages and assets.
+var integrationCases = []integrationCase{
+	{
+		name: "journal post with image",
+		graph: testgraph.New().
+			Journal("2026-01-17", testgraph.Post{
+				Title:  "Renan",
+				Date:   "2026-01-17",
+				Blocks: []string{"We sailed.", "![boat.png](../assets/boat_1769019502364_0.png)"},
+			}.JournalBlock("[[Blog]]")).
+			Image("boat_1769019502364_0.png", 8, 4),
+		want: map[string][]string{
+			"2026-01-17_Renan/index.de.md":              {`title = "Renan"`, "We sailed.", "![boat.png](boat_1769019502364_0.png)"},
+			"2026-01-17_Renan/boat_1769019502364_0.png": nil,
+		},
+	},
+	{
+		name: "page in a namespace",
+		graph: testgraph.New().
+			Page("Trips/Renan", testgraph.Post{
+				Title:      "Renan",
+				Date:       "2024-06-14",
+				Properties: []string{"language:: english"},
+				Blocks:     []string{"Ibiza"},
+			}.PageContent()),
+		want: map[string][]string{
+			"2024-06-14_Renan/index.en.md": {`title = "Renan"`, "Ibiza"},
+		},
+	},
+	{
+		name: "several posts in one journal",
+		graph: testgraph.New().
+			Journal("2026-01-17",
+				testgraph.Post{Title: "Morning", Date: "2026-01-17", Blocks: []string{"Coffee"}}.JournalBlock("Blog")+
+					"- Not a post\n"+
+					testgraph.Post{Title: "Evening", Date: "2026-01-17", Blocks: []string{"Tea"}}.JournalBlock("Blog")),
+		want: map[string][]string{
+			"2026-01-17_Morning/index.de.md": {"Coffee"},
+			"2026-01-17_Evening/index.de.md": {"Tea"},
+		},
+	},
+	{
+		name: "links between posts",
+		graph: testgraph.New().
+			Page("Renan", testgraph.Post{Title: "Renan", Date: "2026-01-17", Blocks: []string{"See [[Home]] and [[Sailing]]"}}.PageContent()).
+			Page("Home", testgraph.Post{Title: "Home", Date: "2026-01-18", Blocks: []string{"Welcome"}}.PageContent()),
+		want: map[string][]string{
+			"2026-01-17_Renan/index.de.md": {`[Home]({{< relref "2026-01-18_Home" >}})`, "Sailing"},
+		},
+	},
+	{
+		name: "nested blocks",
+		graph: testgraph.New().
+			Journal("2026-01-17", testgraph.Post{
+				Title:  "Lessons",
+				Date:   "2026-01-17",
+				Blocks: []string{"We learned:\n\t- Be careful\n\t- Go to school"},
+			}.JournalBlock("Blog")),
+		want: map[string][]string{
+			"2026-01-17_Lessons/index.de.md": {"We learned:", "Be careful", "Go to school"},
+		},
+	},
+	{
+		name: "unicode title",
+		graph: testgraph.New().
+			Page("Frühling", testgraph.Post{Title: "Frühlingspläne 2026", Date: "2026-01-17", Blocks: []string{"Segeln"}}.PageContent()),
+		want: map[string][]string{
+			"2026-01-17_Frühlingspläne_2026/index.de.md": {`title = "Frühlingspläne 2026"`},
+		},
+	},
+	{
+		name: "drafts and whiteboards are skipped",
+		graph: testgraph.New().
+			Page("Draft", testgraph.Post{Title: "Draft", Date: "2026-01-17", Status: "draft", Blocks: []string{"Later"}}.PageContent()).
+			Page("Online", testgraph.Post{Title: "Online", Date: "2026-01-18", Blocks: []string{"Now"}}.PageContent()).
+			Whiteboard("Trip", testgraph.Post{Title: "Copy", Date: "2026-01-19"}.PageContent()),
+		want: map[string][]string{
+			"2026-01-18_Online/index.de.md": {"Now"},
+		},
+		missing: []string{"2026-01-17_Draft/index.de.md", "2026-01-19_Copy/index.de.md"},
+	},
+	{
+		name: "missing asset",
+		graph: testgraph.New().
+			Page("Renan", testgraph.Post{Title: "Renan", Date: "2026-01-17", Blocks: []string{"![gone.jpg](../assets/gone.jpg)"}}.PageContent()),
+		want: map[string][]string{
+			"2026-01-17_Renan/index.de.md": {"gone.jpg"},
+		},
+		missing: []string{"2026-01-17_Renan/gone.jpg"},
+	},
+	{
+		name: "header image",
+		graph: testgraph.New().
+			Page("Renan", testgraph.Post{
+				Title:      "Renan",
+				Date:       "2026-01-17",
+				Properties: []string{"header:: ![header](../assets/header.jpg)"},
+				Blocks:     []string{"Text"},
+			}.PageContent()).
+			Image("header.jpg", 16, 9),
+		want: map[string][]string{
+			"2026-01-17_Renan/index.de.md":  {"Text"},
+			"2026-01-17_Renan/featured.jpg": nil,
+		},
+	},
+	{
+		name: "custom marker",
+		graph: testgraph.New().
+			Page("Renan", "publish:: blog\nstatus:: online\ndate:: 2026-01-17\ntitle:: Renan\n\n- Text\n").
+			Page("Home", testgraph.Post{Title: "Home", Date: "2026-01-18"}.PageContent()),
+		configure: func(config *Config) { config.Marker = "publish:: blog" },
+		want: map[string][]string{
+			"2026-01-17_Renan/index.de.md": {"Text"},
+		},
+		missing: []string{"2026-01-18_Home/index.de.md"},
+	},
+	{
+		name:    "no posts",
+		graph:   testgraph.New().Journal("2026-01-17", "- Just a journal\n"),
+		wantErr: true,
+	},
+}
+
+// TestIntegration converts the synthetic graphs in memory and on disk
+// and checks the written bundles.
+func TestIntegration(t *testing.T) {
+	for _, tt := range integrationCases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Run("memory", func(t *testing.T) {
+				fsys := NewMemFileSystem(nil)
+				tt.graph.WriteTo(fsys, "graph")
+				runIntegrationCase(t, tt, fsys, "graph", "out")
+			})
+			t.Run("disk", func(t *testing.T) {
+				graphDir, outputDir := t.TempDir(), t.TempDir()
+				if err := tt.graph.WriteDir(graphDir); err != nil {
+					t.Fatalf("WriteDir() error = %v", err)
+				}
+				runIntegrationCase(t, tt, OSFileSystem{}, graphDir, outputDir)
+			})
+		})
+	}
+}
+
+// runIntegrationCase converts the graph in graphDir and checks the output.
+func runIntegrationCase(t *testing.T, tt integrationCase, fsys FileSystem, graphDir, outputDir string) {
+	t.Helper()
+	config := DefaultConfig()
+	if tt.configure != nil {
+		tt.configure(config)
+	}
+
+	_, err := NewConverter(config, fsys).ConvertGraph(context.Background(), graphDir, outputDir)
+	if tt.wantErr {
+		if err == nil {
+			t.Fatal("ConvertGraph() should fail")
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("ConvertGraph() error = %v", err)
+	}
+
+	for name, texts := range tt.want {
+		data, err := readFile(fsys, filepath.Join(outputDir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Errorf("%s not written: %v", name, err)
+			continue
+		}
+		for _, text := range texts {
+			if !strings.Contains(string(data), text) {
+				t.Errorf("%s missing %q:\n%s", name, text, data)
+			}
+		}
+	}
+	for _, name := range tt.missing {
+		if _, err := fsys.Stat(filepath.Join(outputDir, filepath.FromSlash(name))); err == nil {
+			t.Errorf("%s should not be written", name)
+		}
+	}
+}
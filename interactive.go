@@ -0,0 +1,151 @@
+// This file handles the interactive mode, which shows every post before it
+// is written and asks whether to convert it. This helps when going through
+// a large journal backlog where not every post with the marker should be published.
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// reviewDecision is the answer to the question whether a post is written.
+type reviewDecision int
+
+const (
+	reviewConfirm reviewDecision = iota // Write the post
+	reviewSkip                          // Don't write the post
+	reviewAll                           // Write the post and all following posts without asking
+	reviewQuit                          // Don't write the post and none of the following
+)
+
+// reviewSummaryLength is the number of characters of the summary that are shown.
+const reviewSummaryLength = 200
+
+// PostReviewer asks on a terminal whether posts are written.
+type PostReviewer struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// NewPostReviewer creates a PostReviewer that reads the answers from in
+// and writes the posts and questions to out.
+func NewPostReviewer(in io.Reader, out io.Writer) *PostReviewer {
+	return &PostReviewer{in: bufio.NewReader(in), out: out}
+}
+
+// Review shows a post and asks until it gets a valid answer. A new title
+// is set on the post and the post is shown again. The end of the input
+// counts as quitting, so posts are never written without an answer.
+func (r *PostReviewer) Review(post *BlogPost, number, total int, assets []string) reviewDecision {
+	for {
+		r.show(post, number, total, assets)
+		fmt.Fprint(r.out, "Write this post? [c]onfirm, [s]kip, [e]dit title, [a]ll remaining, [q]uit: ")
+		answer, ok := r.readLine()
+		if !ok {
+			fmt.Fprintln(r.out)
+			return reviewQuit
+		}
+
+		switch strings.ToLower(answer) {
+		case "c", "confirm", "y", "yes":
+			return reviewConfirm
+		case "s", "skip", "n", "no":
+			return reviewSkip
+		case "a", "all":
+			return reviewAll
+		case "q", "quit":
+			return reviewQuit
+		case "e", "edit":
+			fmt.Fprintf(r.out, "New title (empty keeps %q): ", post.Meta.Title)
+			title, ok := r.readLine()
+			if !ok {
+				fmt.Fprintln(r.out)
+				return reviewQuit
+			}
+			if title != "" {
+				post.Meta.Title = title
+			}
+		default:
+			fmt.Fprintf(r.out, "Unknown answer %q\n", answer)
+		}
+	}
+}
+
+// show prints the title, date, source, summary and assets of a post.
+func (r *PostReviewer) show(post *BlogPost, number, total int, assets []string) {
+	fmt.Fprintf(r.out, "\nPost %d/%d: %s %q\n", number, total, typeName(post), post.Meta.Title)
+	fmt.Fprintf(r.out, "  Date:    %s\n", post.Meta.Date)
+	fmt.Fprintf(r.out, "  Source:  %s\n", post.SourcePath)
+	if len(post.Meta.Tags) > 0 {
+		fmt.Fprintf(r.out, "  Tags:    %s\n", strings.Join(post.Meta.Tags, ", "))
+	}
+	if summary := strings.Join(strings.Fields(post.Meta.Summary), " "); summary != "" {
+		if runes := []rune(summary); len(runes) > reviewSummaryLength {
+			summary = string(runes[:reviewSummaryLength]) + "..."
+		}
+		fmt.Fprintf(r.out, "  Summary: %s\n", summary)
+	}
+	if len(assets) > 0 {
+		fmt.Fprintf(r.out, "  Assets:  %s\n", strings.Join(assets, ", "))
+	}
+}
+
+// readLine reads the next answer without surrounding spaces.
+// It returns false at the end of the input.
+func (r *PostReviewer) readLine() (string, bool) {
+	line, err := r.in.ReadString('\n')
+	if err != nil && line == "" {
+		return "", false
+	}
+	return strings.TrimSpace(line), true
+}
+
+// reviewPosts asks for every post whether it is written and returns the confirmed ones.
+// Posts with an edited title get a new bundle name.
+func (c *Converter) reviewPosts(posts []*BlogPost) []*BlogPost {
+	var confirmed []*BlogPost
+	for i, post := range posts {
+		title := post.Meta.Title
+		decision := c.review.Review(post, i+1, len(posts), c.postAssets(post))
+		if post.Meta.Title != title {
+			c.placePost(post)
+		}
+
+		switch decision {
+		case reviewConfirm:
+			confirmed = append(confirmed, post)
+		case reviewAll:
+			return append(confirmed, posts[i:]...)
+		case reviewSkip:
+			fmt.Printf("Skipping %s '%s': skipped in review\n", typeName(post), post.Meta.Title)
+			c.stats.Skipped["skipped in review"]++
+		case reviewQuit:
+			fmt.Printf("Skipping %d posts: review stopped\n", len(posts)-i)
+			c.stats.Skipped["skipped in review"] += len(posts) - i
+			return confirmed
+		}
+	}
+	return confirmed
+}
+
+// postAssets returns the names of the images a post references, in the order of the content.
+func (c *Converter) postAssets(post *BlogPost) []string {
+	var assets []string
+	seen := make(map[string]bool)
+	for _, block := range post.Content {
+		for _, match := range c.extractor.AssetRegex().FindAllStringSubmatch(block.Text, -1) {
+			if name := unescapePath(match[3]); !seen[name] {
+				seen[name] = true
+				assets = append(assets, name)
+			}
+		}
+	}
+	if header := path.Base(filepath.ToSlash(post.Meta.Header)); post.Meta.Header != "" && !seen[header] {
+		assets = append(assets, header+" (header)")
+	}
+	return assets
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestPostReviewer_Review tests the answers to the question whether a post is written
+func TestPostReviewer_Review(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		want      reviewDecision
+		wantTitle string
+	}{
+		{"confirm", "c\n", reviewConfirm, "Renan"},
+		{"yes", "Y\n", reviewConfirm, "Renan"},
+		{"skip", "s\n", reviewSkip, "Renan"},
+		{"all", "a\n", reviewAll, "Renan"},
+		{"quit", "q\n", reviewQuit, "Renan"},
+		{"edit title", "e\nDay Trip to Renan\nc\n", reviewConfirm, "Day Trip to Renan"},
+		{"edit keeps empty title", "e\n\ns\n", reviewSkip, "Renan"},
+		{"unknown answer asks again", "x\n\nc\n", reviewConfirm, "Renan"},
+		{"end of input", "", reviewQuit, "Renan"},
+		{"last line without newline", "c", reviewConfirm, "Renan"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post := &BlogPost{Meta: BlogMeta{Title: "Renan", Date: "2026-01-17"}}
+			var out bytes.Buffer
+			reviewer := NewPostReviewer(strings.NewReader(tt.input), &out)
+
+			if got := reviewer.Review(post, 1, 2, nil); got != tt.want {
+				t.Errorf("Review() = %v, want %v", got, tt.want)
+			}
+			if post.Meta.Title != tt.wantTitle {
+				t.Errorf("Title = %q, want %q", post.Meta.Title, tt.wantTitle)
+			}
+		})
+	}
+}
+
+// TestPostReviewer_Show tests the details of a post shown before the question
+func TestPostReviewer_Show(t *testing.T) {
+	post := &BlogPost{
+		Meta:       BlogMeta{Title: "Renan", Date: "2026-01-17", Tags: []string{"hiking", "jura"}, Summary: strings.Repeat("Wandern ", 40)},
+		SourcePath: "journals/2026_01_17.md",
+	}
+	var out bytes.Buffer
+	NewPostReviewer(strings.NewReader("c\n"), &out).Review(post, 3, 12, []string{"photo.jpg", "header.jpg (header)"})
+
+	for _, want := range []string{
+		`Post 3/12: blog post "Renan"`,
+		"Date:    2026-01-17",
+		"Source:  journals/2026_01_17.md",
+		"Tags:    hiking, jura",
+		"Summary: Wandern Wandern",
+		"...\n",
+		"Assets:  photo.jpg, header.jpg (header)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output is missing %q:\n%s", want, out.String())
+		}
+	}
+}
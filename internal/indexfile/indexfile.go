@@ -0,0 +1,156 @@
+// Package indexfile handles the names of the index files of the bundles. The
+// name is a Go template like "index.{{.Lang}}.md" or "{{.Slug}}.{{.Lang}}.md":
+// the converter and the translator write the index files with it, the
+// commands reading the output directory find them and their language with it.
+package indexfile
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"logseq-to-hugo-converter/internal/lang"
+)
+
+// Default is the template of the index file name: one file per language.
+const Default = "index.{{.Lang}}.md"
+
+// Monolingual is the index file name of monolingual sites.
+const Monolingual = "index.md"
+
+// Data is what a template of the index file name can use.
+type Data struct {
+	Lang     string // Hugo language key (e.g. "de", "en", "pt-br")
+	Language string // English name of the language (e.g. "german"), lowercase
+	Slug     string // Name of the bundle directory
+}
+
+// Markers stand for the fields in the name a pattern is made from.
+const (
+	langMarker     = "\x00"
+	languageMarker = "\x01"
+	slugMarker     = "\x02"
+)
+
+// Name is a parsed template of the index file name.
+type Name struct {
+	text    string // Source of the template
+	tmpl    *template.Template
+	pattern *regexp.Regexp // Matches the names the template gives, the fields are groups
+	fields  []string       // Markers of the groups of pattern, in order
+}
+
+// Parse parses a template of the index file name like "index.{{.Lang}}.md".
+// The template must give a plain file name ending in ".md" for every post.
+func Parse(text string) (*Name, error) {
+	tmpl, err := template.New("filename").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	n := &Name{text: text, tmpl: tmpl}
+	for _, data := range []Data{{"de", "german", "2025-01-20_Post"}, {"en", "english", "2025-01-20_Post"}} {
+		if _, err := n.Execute(data); err != nil {
+			return nil, err
+		}
+	}
+
+	// The name with markers for the fields gives the pattern of the names
+	var b strings.Builder
+	if err := tmpl.Execute(&b, Data{Lang: langMarker, Language: languageMarker, Slug: slugMarker}); err != nil {
+		return nil, err
+	}
+	var pattern strings.Builder
+	for _, r := range b.String() {
+		switch marker := string(r); marker {
+		case langMarker:
+			pattern.WriteString(`([A-Za-z]{2,3}(?:-[A-Za-z0-9]+)*)`)
+			n.fields = append(n.fields, marker)
+		case languageMarker, slugMarker:
+			pattern.WriteString(`([^/\\]+?)`)
+			n.fields = append(n.fields, marker)
+		default:
+			pattern.WriteString(regexp.QuoteMeta(marker))
+		}
+	}
+	if n.pattern, err = regexp.Compile("^" + pattern.String() + "$"); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// MustParse is like Parse but panics if the template is invalid.
+func MustParse(text string) *Name {
+	n, err := Parse(text)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// String returns the source of the template.
+func (n *Name) String() string {
+	return n.text
+}
+
+// Execute returns the index file name of a post and checks it.
+func (n *Name) Execute(data Data) (string, error) {
+	var builder strings.Builder
+	if err := n.tmpl.Execute(&builder, data); err != nil {
+		return "", err
+	}
+	filename := builder.String()
+	if filename != filepath.Base(filename) || strings.ContainsAny(filename, `/\`) {
+		return "", fmt.Errorf("file name %q must not contain a directory", filename)
+	}
+	if !strings.HasSuffix(filename, ".md") {
+		return "", fmt.Errorf("file name %q must end in .md", filename)
+	}
+	if filename == ".md" {
+		return "", fmt.Errorf("file name %q has no name before .md", filename)
+	}
+	return filename, nil
+}
+
+// UsesLanguage reports whether the template gives different names for German and English posts.
+func (n *Name) UsesLanguage() bool {
+	german, _ := n.Execute(Data{Lang: "de", Language: "german", Slug: "2025-01-20_Post"})
+	english, _ := n.Execute(Data{Lang: "en", Language: "english", Slug: "2025-01-20_Post"})
+	return german != english
+}
+
+// Match reports whether filename is an index file of the bundle directory slug
+// and returns its language key in lowercase ("" if the template has no language).
+// Languages must look like codes: "index.de.md" and "index.pt-BR.md" are
+// index files, "index.draft.md" and "index.deutsch.md" aren't. Language
+// names must be known, codes are not checked ("index.xx.md" gives "xx").
+func (n *Name) Match(filename, slug string) (string, bool) {
+	groups := n.pattern.FindStringSubmatch(filename)
+	if groups == nil {
+		return "", false
+	}
+	key := ""
+	for i, marker := range n.fields {
+		value := groups[i+1]
+		switch marker {
+		case slugMarker:
+			if value != slug {
+				return "", false
+			}
+		case langMarker:
+			if key == "" {
+				key = strings.ToLower(value)
+			}
+		case languageMarker:
+			tag, ok := lang.Parse(value)
+			if !ok {
+				return "", false
+			}
+			if key == "" {
+				key = tag.Key()
+			}
+		}
+	}
+	return key, true
+}
@@ -0,0 +1,54 @@
+package indexfile
+
+import (
+	"testing"
+)
+
+// TestMatch tests finding the index files of a bundle and their language
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		template string
+		filename string
+		want     string
+		ok       bool
+	}{
+		{Default, "index.de.md", "de", true},
+		{Default, "index.pt-br.md", "pt-br", true},
+		{Default, "index.md", "", false},
+		{Default, "index.draft.md", "", false},
+		{Default, "index.pt-BR.md", "pt-br", true},
+		{Default, "index.deutsch.md", "", false},
+		{Default, "index.xx.md", "xx", true},
+		{Default, "social.de.txt", "", false},
+		{Monolingual, "index.md", "", true},
+		{Monolingual, "index.de.md", "", false},
+		{"{{.Slug}}.{{.Lang}}.md", "2025-01-20_Post.en.md", "en", true},
+		{"{{.Slug}}.{{.Lang}}.md", "2025-01-19_Other.en.md", "", false},
+		{"{{.Slug}}.{{.Lang}}.md", "index.en.md", "", false},
+		{"index.{{.Language}}.md", "index.english.md", "en", true},
+		{"{{.Language}}.md", "german.md", "de", true},
+		{"{{.Language}}.md", "notes.md", "", false},
+		{"post+{{.Lang}}.md", "post+de.md", "de", true},
+		{"post+{{.Lang}}.md", "postt+de.md", "", false},
+	}
+
+	for _, tt := range tests {
+		name, err := Parse(tt.template)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.template, err)
+		}
+		got, ok := name.Match(tt.filename, "2025-01-20_Post")
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Match(%q) with %q = %q, %v, want %q, %v", tt.filename, tt.template, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// TestUsesLanguage tests telling templates with and without the language apart
+func TestUsesLanguage(t *testing.T) {
+	for template, want := range map[string]bool{Default: true, "{{.Language}}.md": true, Monolingual: false, "{{.Slug}}.md": false} {
+		if got := MustParse(template).UsesLanguage(); got != want {
+			t.Errorf("UsesLanguage(%q) = %v, want %v", template, got, want)
+		}
+	}
+}
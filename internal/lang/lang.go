@@ -0,0 +1,185 @@
+// Package lang normalizes the "language::" values of posts ("de", "Deutsch",
+// "german", "pt-BR") to language tags. The converter uses the tags to name
+// the index files, the translation tool to read the language of an index file.
+package lang
+
+import (
+	"strings"
+)
+
+// Tag is a language with an optional region, like "pt-BR".
+type Tag struct {
+	Base   string // ISO 639-1 code in lowercase ("pt")
+	Script string // ISO 15924 script in title case ("Hant"), empty for none
+	Region string // ISO 3166-1 region in uppercase ("BR") or UN M49 code ("419"), empty for none
+}
+
+// language is a language that can be given by its code, English or native name.
+type language struct {
+	code   string
+	name   string // English name
+	native string // Native name, lowercase
+}
+
+// languages are the known languages.
+var languages = []language{
+	{"ar", "Arabic", "العربية"},
+	{"ca", "Catalan", "català"},
+	{"cs", "Czech", "čeština"},
+	{"da", "Danish", "dansk"},
+	{"de", "German", "deutsch"},
+	{"el", "Greek", "ελληνικά"},
+	{"en", "English", "english"},
+	{"es", "Spanish", "español"},
+	{"fi", "Finnish", "suomi"},
+	{"fr", "French", "français"},
+	{"he", "Hebrew", "עברית"},
+	{"hr", "Croatian", "hrvatski"},
+	{"hu", "Hungarian", "magyar"},
+	{"it", "Italian", "italiano"},
+	{"ja", "Japanese", "日本語"},
+	{"ko", "Korean", "한국어"},
+	{"nb", "Norwegian", "norsk"},
+	{"nl", "Dutch", "nederlands"},
+	{"pl", "Polish", "polski"},
+	{"pt", "Portuguese", "português"},
+	{"ro", "Romanian", "română"},
+	{"ru", "Russian", "русский"},
+	{"sk", "Slovak", "slovenčina"},
+	{"sl", "Slovenian", "slovenščina"},
+	{"sv", "Swedish", "svenska"},
+	{"tr", "Turkish", "türkçe"},
+	{"uk", "Ukrainian", "українська"},
+	{"zh", "Chinese", "中文"},
+}
+
+// aliases are further codes and names of the known languages.
+var aliases = map[string]string{
+	"no":         "nb",
+	"nn":         "nb",
+	"iw":         "he",
+	"ger":        "de",
+	"deu":        "de",
+	"eng":        "en",
+	"spa":        "es",
+	"fra":        "fr",
+	"fre":        "fr",
+	"ita":        "it",
+	"por":        "pt",
+	"nld":        "nl",
+	"dut":        "nl",
+	"castellano": "es",
+	"bokmål":     "nb",
+}
+
+// Parse reads a language value: a code with optional script and region
+// ("de", "pt-BR", "pt_br", "zh-Hant-TW"), an English name ("German") or a
+// native name ("Deutsch"). The result is false for unknown languages.
+func Parse(value string) (Tag, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return Tag{}, false
+	}
+
+	// Codes and names without subtags ("de", "Português")
+	if code, ok := lookup(value); ok {
+		return Tag{Base: code}, true
+	}
+
+	// Codes with subtags ("pt-br", "de_CH")
+	parts := strings.FieldsFunc(value, func(r rune) bool { return r == '-' || r == '_' })
+	if len(parts) == 0 || len(parts) > 3 {
+		return Tag{}, false
+	}
+	code, ok := lookup(parts[0])
+	if !ok {
+		return Tag{}, false
+	}
+	tag := Tag{Base: code}
+	for _, part := range parts[1:] {
+		switch {
+		case len(part) == 4 && isLetters(part) && tag.Script == "" && tag.Region == "":
+			tag.Script = strings.ToUpper(part[:1]) + part[1:]
+		case (len(part) == 2 && isLetters(part) || len(part) == 3 && isDigits(part)) && tag.Region == "":
+			tag.Region = strings.ToUpper(part)
+		default:
+			return Tag{}, false
+		}
+	}
+	return tag, true
+}
+
+// lookup finds the code of a language by its code, name or alias.
+func lookup(value string) (string, bool) {
+	if code, ok := aliases[value]; ok {
+		return code, true
+	}
+	for _, language := range languages {
+		if value == language.code || value == strings.ToLower(language.name) || value == language.native {
+			return language.code, true
+		}
+	}
+	return "", false
+}
+
+// isLetters reports whether s only has the letters a-z.
+func isLetters(s string) bool {
+	return strings.Trim(s, "abcdefghijklmnopqrstuvwxyz") == ""
+}
+
+// isDigits reports whether s only has digits.
+func isDigits(s string) bool {
+	return strings.Trim(s, "0123456789") == ""
+}
+
+// String returns the tag in its canonical form ("pt-BR").
+func (t Tag) String() string {
+	parts := []string{t.Base}
+	if t.Script != "" {
+		parts = append(parts, t.Script)
+	}
+	if t.Region != "" {
+		parts = append(parts, t.Region)
+	}
+	return strings.Join(parts, "-")
+}
+
+// Key returns the tag as a Hugo language key, which Hugo writes in lowercase ("pt-br").
+func (t Tag) Key() string {
+	return strings.ToLower(t.String())
+}
+
+// Name returns the English name of the language without the region ("Portuguese").
+func (t Tag) Name() string {
+	for _, language := range languages {
+		if language.code == t.Base {
+			return language.name
+		}
+	}
+	return t.Base
+}
+
+// Match returns the Hugo language key of a site that fits the tag best:
+// the same tag ("pt-br"), else a key of the language without a region ("pt"),
+// else any key of the language ("pt-pt"). The result is false if the site
+// doesn't have the language.
+func (t Tag) Match(keys []string) (string, bool) {
+	best, bestRank := "", 0
+	for _, key := range keys {
+		other, ok := Parse(key)
+		if !ok || other.Base != t.Base {
+			continue
+		}
+		rank := 1
+		switch {
+		case other == t:
+			rank = 3
+		case other.Region == "" && other.Script == "":
+			rank = 2
+		}
+		if rank > bestRank {
+			best, bestRank = strings.ToLower(key), rank
+		}
+	}
+	return best, bestRank > 0
+}
@@ -0,0 +1,63 @@
+package lang
+
+import (
+	"testing"
+)
+
+// TestParse tests reading codes, names and regional variants
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+		name  string
+		ok    bool
+	}{
+		{"de", "de", "German", true},
+		{"Deutsch", "de", "German", true},
+		{"german", "de", "German", true},
+		{" English ", "en", "English", true},
+		{"pt-BR", "pt-BR", "Portuguese", true},
+		{"pt_br", "pt-BR", "Portuguese", true},
+		{"de-CH", "de-CH", "German", true},
+		{"es-419", "es-419", "Spanish", true},
+		{"zh-hant-tw", "zh-Hant-TW", "Chinese", true},
+		{"Español", "es", "Spanish", true},
+		{"deu", "de", "German", true},
+		{"klingon", "", "", false},
+		{"pt-Brazil", "", "", false},
+		{"de-CH-AT", "", "", false},
+		{"-", "", "", false},
+		{"", "", "", false},
+	}
+
+	for _, tt := range tests {
+		tag, ok := Parse(tt.value)
+		if ok != tt.ok || (ok && (tag.String() != tt.want || tag.Name() != tt.name)) {
+			t.Errorf("Parse(%q) = %q (%s), %v, want %q (%s), %v", tt.value, tag, tag.Name(), ok, tt.want, tt.name, tt.ok)
+		}
+	}
+}
+
+// TestTagMatch tests finding the best language key of a site
+func TestTagMatch(t *testing.T) {
+	tests := []struct {
+		value string
+		keys  []string
+		want  string
+		ok    bool
+	}{
+		{"pt-BR", []string{"pt", "pt-br"}, "pt-br", true},
+		{"pt-BR", []string{"en", "pt"}, "pt", true},
+		{"pt-BR", []string{"pt-PT"}, "pt-pt", true},
+		{"de", []string{"de-ch", "de"}, "de", true},
+		{"de", []string{"en", "fr"}, "", false},
+		{"de", nil, "", false},
+	}
+
+	for _, tt := range tests {
+		tag, _ := Parse(tt.value)
+		if got, ok := tag.Match(tt.keys); got != tt.want || ok != tt.ok {
+			t.Errorf("Parse(%q).Match(%v) = %q, %v, want %q, %v", tt.value, tt.keys, got, ok, tt.want, tt.ok)
+		}
+	}
+}
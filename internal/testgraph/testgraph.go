@@ -0,0 +1,179 @@
+// Package testgraph builds synthetic Logseq graphs for tests.
+// A graph is described in code (journals, pages, whiteboards and assets) and
+// written into an in-memory file system or a temporary directory, so
+// regression cases don't need example graphs with binary assets in the repository.
+package testgraph
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FileWriter is a file system the graph can be written to (like the converter's MemFileSystem).
+type FileWriter interface {
+	WriteFile(name string, data []byte)
+}
+
+// Graph is a synthetic Logseq graph: file paths relative to the graph directory
+// (with forward slashes) and their content.
+type Graph struct {
+	files map[string][]byte
+}
+
+// New creates an empty graph.
+func New() *Graph {
+	return &Graph{files: make(map[string][]byte)}
+}
+
+// Journal adds the journal of a day ("2026-01-17" is written to journals/2026_01_17.md).
+func (g *Graph) Journal(date, content string) *Graph {
+	return g.File("journals/"+strings.ReplaceAll(date, "-", "_")+".md", []byte(content))
+}
+
+// Page adds a page. Namespaces are stored like Logseq does ("Trips/Renan" is
+// written to pages/Trips___Renan.md).
+func (g *Graph) Page(name, content string) *Graph {
+	return g.File("pages/"+strings.ReplaceAll(name, "/", "___")+".md", []byte(content))
+}
+
+// Whiteboard adds a whiteboard, which is never converted.
+func (g *Graph) Whiteboard(name, content string) *Graph {
+	return g.File("whiteboards/"+name+".md", []byte(content))
+}
+
+// Asset adds a file to the assets directory. Posts reference it as "../assets/<name>".
+func (g *Graph) Asset(name string, data []byte) *Graph {
+	return g.File("assets/"+name, data)
+}
+
+// Image adds a generated image to the assets directory, a PNG or a JPEG
+// depending on the extension of name.
+func (g *Graph) Image(name string, width, height int) *Graph {
+	if ext := strings.ToLower(path.Ext(name)); ext == ".jpg" || ext == ".jpeg" {
+		return g.Asset(name, JPEG(width, height))
+	}
+	return g.Asset(name, PNG(width, height))
+}
+
+// File adds any file, path is relative to the graph directory.
+func (g *Graph) File(name string, data []byte) *Graph {
+	g.files[path.Clean(name)] = data
+	return g
+}
+
+// Files returns the paths of the files of the graph in lexical order.
+func (g *Graph) Files() []string {
+	names := make([]string, 0, len(g.files))
+	for name := range g.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WriteTo writes the graph into dir of a file system.
+func (g *Graph) WriteTo(fsys FileWriter, dir string) {
+	for _, name := range g.Files() {
+		fsys.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), g.files[name])
+	}
+}
+
+// WriteDir writes the graph into a directory of the disk (e.g. from t.TempDir()).
+func (g *Graph) WriteDir(dir string) error {
+	for _, name := range g.Files() {
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, g.files[name], 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Post describes a blog post of the graph.
+type Post struct {
+	Title      string   // "title::" property
+	Date       string   // "date::" property, e.g. "2026-01-17"
+	Status     string   // "status::" property, "online" if empty
+	Properties []string // Further properties ("language:: english")
+	Blocks     []string // Content blocks, nested blocks as "text\n\t- child"
+}
+
+// properties returns the property lines of the post, starting with the blog marker.
+func (p Post) properties() []string {
+	status := p.Status
+	if status == "" {
+		status = "online"
+	}
+	lines := []string{"type:: blog", "status:: " + status}
+	if p.Date != "" {
+		lines = append(lines, "date:: "+p.Date)
+	}
+	if p.Title != "" {
+		lines = append(lines, "title:: "+p.Title)
+	}
+	return append(lines, p.Properties...)
+}
+
+// JournalBlock returns the post in the nested format of journals: a block
+// (like "- [[Blog]]") with the properties and the content as its children.
+func (p Post) JournalBlock(heading string) string {
+	var builder strings.Builder
+	builder.WriteString("- " + heading + "\n")
+	builder.WriteString("\t- " + strings.Join(p.properties(), "\n\t  ") + "\n")
+	for _, block := range p.Blocks {
+		builder.WriteString("\t- " + strings.ReplaceAll(block, "\n", "\n\t") + "\n")
+	}
+	return builder.String()
+}
+
+// PageContent returns the post in the format of pages: the properties
+// at the top, followed by the content blocks.
+func (p Post) PageContent() string {
+	var builder strings.Builder
+	builder.WriteString(strings.Join(p.properties(), "\n") + "\n\n")
+	for _, block := range p.Blocks {
+		builder.WriteString("- " + block + "\n")
+	}
+	return builder.String()
+}
+
+// PNG returns a PNG image with a gradient, so images of different sizes differ.
+func PNG(width, height int) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, gradient(width, height)); err != nil {
+		panic(fmt.Sprintf("testgraph: encoding PNG: %v", err))
+	}
+	return buf.Bytes()
+}
+
+// JPEG returns a JPEG image with a gradient.
+func JPEG(width, height int) []byte {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, gradient(width, height), nil); err != nil {
+		panic(fmt.Sprintf("testgraph: encoding JPEG: %v", err))
+	}
+	return buf.Bytes()
+}
+
+// gradient draws an image that goes from black to red and green.
+func gradient(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(255 * x / width), G: uint8(255 * y / height), B: 128, A: 255})
+		}
+	}
+	return img
+}
@@ -0,0 +1,94 @@
+package testgraph
+
+import (
+	"bytes"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// memFS collects the written files like an in-memory file system.
+type memFS map[string][]byte
+
+func (m memFS) WriteFile(name string, data []byte) { m[name] = data }
+
+// TestGraph tests the file names of journals, pages, whiteboards and assets
+func TestGraph(t *testing.T) {
+	post := Post{Title: "Renan", Date: "2026-01-17", Blocks: []string{"Text"}}
+	graph := New().
+		Journal("2026-01-17", post.JournalBlock("[[Blog]]")).
+		Page("Trips/Renan", post.PageContent()).
+		Whiteboard("Trip", "").
+		Asset("clip.mp4", []byte("mp4"))
+
+	want := []string{"assets/clip.mp4", "journals/2026_01_17.md", "pages/Trips___Renan.md", "whiteboards/Trip.md"}
+	if got := graph.Files(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Files() = %q, want %q", got, want)
+	}
+
+	fsys := memFS{}
+	graph.WriteTo(fsys, "graph")
+	if got := string(fsys[filepath.Join("graph", "pages", "Trips___Renan.md")]); got != post.PageContent() {
+		t.Errorf("WriteTo() wrote %q, want %q", got, post.PageContent())
+	}
+
+	dir := t.TempDir()
+	if err := graph.WriteDir(dir); err != nil {
+		t.Fatalf("WriteDir() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "assets", "clip.mp4")); err != nil {
+		t.Errorf("WriteDir() did not write the asset: %v", err)
+	}
+}
+
+// TestPost tests the journal and page formats of a post
+func TestPost(t *testing.T) {
+	post := Post{
+		Title:      "Renan",
+		Date:       "2026-01-17",
+		Properties: []string{"language:: english"},
+		Blocks:     []string{"Intro", "List:\n\t- Item"},
+	}
+
+	wantJournal := "- [[Blog]]\n" +
+		"\t- type:: blog\n\t  status:: online\n\t  date:: 2026-01-17\n\t  title:: Renan\n\t  language:: english\n" +
+		"\t- Intro\n" +
+		"\t- List:\n\t\t- Item\n"
+	if got := post.JournalBlock("[[Blog]]"); got != wantJournal {
+		t.Errorf("JournalBlock() =\n%s\nwant\n%s", got, wantJournal)
+	}
+
+	wantPage := "type:: blog\nstatus:: online\ndate:: 2026-01-17\ntitle:: Renan\nlanguage:: english\n\n" +
+		"- Intro\n" +
+		"- List:\n\t- Item\n"
+	if got := post.PageContent(); got != wantPage {
+		t.Errorf("PageContent() =\n%s\nwant\n%s", got, wantPage)
+	}
+
+	if got := (Post{Title: "Draft", Status: "draft"}).PageContent(); got != "type:: blog\nstatus:: draft\ntitle:: Draft\n\n" {
+		t.Errorf("PageContent() of a draft = %q", got)
+	}
+}
+
+// TestImages tests that the generated images decode with their size
+func TestImages(t *testing.T) {
+	for _, tt := range []struct {
+		format string
+		data   []byte
+	}{
+		{"png", PNG(8, 4)},
+		{"jpeg", JPEG(8, 4)},
+	} {
+		config, format, err := image.DecodeConfig(bytes.NewReader(tt.data))
+		if err != nil {
+			t.Fatalf("%s: DecodeConfig() error = %v", tt.format, err)
+		}
+		if format != tt.format || config.Width != 8 || config.Height != 4 {
+			t.Errorf("%s: got %s %dx%d, want 8x4", tt.format, format, config.Width, config.Height)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// Package words counts the words of markdown content and estimates its reading
+// time. The converter writes the wordcount and readingtime params with it, the
+// translation tool updates them for the translations the same way.
+package words
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// DefaultPerMinute is the reading speed of the estimate if none is configured
+// (reading.words_per_minute of the converter).
+const DefaultPerMinute = 200
+
+// nonWordRegexes match markdown syntax that should not be counted as words:
+// code blocks, shortcodes, image references, link targets and HTML tags.
+var nonWordRegexes = []*regexp.Regexp{
+	regexp.MustCompile("(?ms)^```(\\S*)[^\\n]*\\n(.*?)^```[ \\t]*$"),
+	regexp.MustCompile(`\{\{[<%].*?[>%]\}\}`),
+	regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`),
+	regexp.MustCompile(`\]\([^)]*\)`),
+	regexp.MustCompile(`<[^>]+>`),
+}
+
+// ReplaceMarkup replaces the markdown syntax that isn't readable text (code
+// blocks, shortcodes, images, link targets and HTML tags) with what replace returns for it.
+func ReplaceMarkup(content string, replace func(markup string) string) string {
+	for _, regex := range nonWordRegexes {
+		content = regex.ReplaceAllStringFunc(content, replace)
+	}
+	return content
+}
+
+// Count counts the words of the readable text in markdown content.
+func Count(content string) int {
+	content = ReplaceMarkup(content, func(string) string { return " " })
+
+	count := 0
+	for _, field := range strings.Fields(content) {
+		// Only count fields containing at least one letter or digit (skips "#", "|", "-", ...)
+		if strings.IndexFunc(field, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// ReadingTime estimates the reading time in minutes (at least 1).
+func ReadingTime(words, perMinute int) int {
+	if perMinute <= 0 {
+		return 1
+	}
+	minutes := (words + perMinute - 1) / perMinute
+	if minutes < 1 {
+		return 1
+	}
+	return minutes
+}
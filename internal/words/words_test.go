@@ -0,0 +1,46 @@
+package words
+
+import (
+	"testing"
+)
+
+// TestCount tests counting readable words in markdown
+func TestCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int
+	}{
+		{"Plain text", "One two three", 3},
+		{"Heading marker is not a word", "### Die Vorbereitung", 2},
+		{"Images and shortcodes are skipped", "![image.png](image.png)\n\n{{< video src=\"a.mp4\" >}}\n\nHello", 1},
+		{"Link text counts, target does not", "See [the docs](https://example.com/a/b) now", 4},
+		{"Code blocks are skipped", "Before\n```go\nfunc main() {}\n```\nAfter", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Count(tt.content); got != tt.want {
+				t.Errorf("Count() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestReadingTime tests the reading time estimate
+func TestReadingTime(t *testing.T) {
+	tests := []struct {
+		words, perMinute, want int
+	}{
+		{0, 200, 1},
+		{200, 200, 1},
+		{201, 200, 2},
+		{1000, 250, 4},
+	}
+
+	for _, tt := range tests {
+		if got := ReadingTime(tt.words, tt.perMinute); got != tt.want {
+			t.Errorf("ReadingTime(%d, %d) = %d, want %d", tt.words, tt.perMinute, got, tt.want)
+		}
+	}
+}
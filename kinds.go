@@ -0,0 +1,57 @@
+// This file handles detecting Logseq blocks and files that are not articles.
+// Flashcards and whiteboard shapes sometimes contain "type:: blog" text copied
+// from a post; converting them would produce garbage posts.
+package main
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Kinds of blocks that contain the blog marker but are not articles.
+const (
+	KindArticle    = ""           // A regular blog post
+	KindFlashcard  = "flashcard"  // A block tagged #card or with card-* review properties
+	KindWhiteboard = "whiteboard" // A whiteboard shape or a block referencing a .tldr drawing
+)
+
+var (
+	// cardTagRegex matches the #card tag (also #[[card]] and [[card]]) of Logseq flashcards.
+	cardTagRegex = regexp.MustCompile(`(?i)(^|\s)(#card|#?\[\[card\]\])(\s|$)`)
+
+	// cardPropertyRegex matches the review properties Logseq adds to flashcards (card-last-interval:: 4).
+	cardPropertyRegex = regexp.MustCompile(`(?i)^\s*card-[a-z-]+::`)
+
+	// whiteboardPropertyRegex matches the properties of whiteboard shapes
+	// (ls-type:: whiteboard-shape, logseq.tldraw.shape:: ...).
+	whiteboardPropertyRegex = regexp.MustCompile(`(?i)^\s*(ls-type::\s*whiteboard|logseq\.tldraw\.[a-z.]+::)`)
+)
+
+// blockKind returns the kind of a block with the blog marker from its lines,
+// KindArticle if it looks like a real post.
+func blockKind(lines []string) string {
+	for _, line := range lines {
+		switch {
+		case whiteboardPropertyRegex.MatchString(line) || strings.Contains(strings.ToLower(line), ".tldr"):
+			return KindWhiteboard
+		case cardPropertyRegex.MatchString(line):
+			return KindFlashcard
+		case !blockPropertyRegex.MatchString(line) && cardTagRegex.MatchString(line):
+			// The question of a card; "tags:: card" on a post is just a tag
+			return KindFlashcard
+		}
+	}
+	return KindArticle
+}
+
+// isWhiteboardFile checks if a file belongs to the whiteboards of a graph
+// (in the "whiteboards" or "draws" directory).
+func isWhiteboardFile(path string) bool {
+	for _, dir := range strings.Split(filepath.ToSlash(filepath.Dir(path)), "/") {
+		if dir == "whiteboards" || dir == "draws" {
+			return true
+		}
+	}
+	return false
+}
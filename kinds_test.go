@@ -0,0 +1,51 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestBlockKind tests detecting flashcards and whiteboard shapes with the blog marker
+func TestBlockKind(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  string
+	}{
+		{"Blog post", []string{"type:: blog", "title:: Renan", "tags:: card, sailing"}, KindArticle},
+		{"Tag in a property value", []string{"type:: blog", "summary:: Learning with #card"}, KindArticle},
+		{"Card question", []string{"What is type:: blog? #card", "type:: blog"}, KindFlashcard},
+		{"Card question with page tag", []string{"Question [[card]]"}, KindFlashcard},
+		{"Card review properties", []string{"type:: blog", "card-last-interval:: 4", "card-repeats:: 2"}, KindFlashcard},
+		{"Whiteboard shape", []string{"type:: blog", "ls-type:: whiteboard-shape"}, KindWhiteboard},
+		{"Tldraw properties", []string{"type:: blog", "logseq.tldraw.shape:: {}"}, KindWhiteboard},
+		{"Drawing reference", []string{"type:: blog", "[[draws/2026-01-17.tldr]]"}, KindWhiteboard},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := blockKind(tt.lines); got != tt.want {
+				t.Errorf("blockKind(%q) = %q, want %q", tt.lines, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestIsWhiteboardFile tests detecting the whiteboard files of a graph
+func TestIsWhiteboardFile(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{filepath.Join("graph", "whiteboards", "Trip.md"), true},
+		{filepath.Join("graph", "draws", "sketch.md"), true},
+		{filepath.Join("graph", "pages", "whiteboards.md"), false},
+		{filepath.Join("graph", "journals", "2026_01_17.md"), false},
+	}
+
+	for _, tt := range tests {
+		if got := isWhiteboardFile(tt.path); got != tt.want {
+			t.Errorf("isWhiteboardFile(%q) = %t, want %t", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// This file handles detecting the language of posts without a "language::"
+// property. The words and character trigrams of the post are compared with
+// the most frequent ones of the languages the writer supports.
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// languageProfile holds the most frequent words and character trigrams of a language.
+type languageProfile struct {
+	words    map[string]bool
+	trigrams map[string]bool
+}
+
+// newLanguageProfile creates a profile from space-separated words and trigrams
+// (trigrams at the start or end of a word are written with "_").
+func newLanguageProfile(words, trigrams string) languageProfile {
+	profile := languageProfile{words: make(map[string]bool), trigrams: make(map[string]bool)}
+	for _, word := range strings.Fields(words) {
+		profile.words[word] = true
+	}
+	for _, trigram := range strings.Fields(trigrams) {
+		profile.trigrams[strings.ReplaceAll(trigram, "_", " ")] = true
+	}
+	return profile
+}
+
+// languageProfiles are the languages that can be detected, by their "language::" value.
+var languageProfiles = map[string]languageProfile{
+	"german": newLanguageProfile(
+		"der die und in den von zu das mit sich des auf für ist im dem nicht ein eine als auch es an werden aus er hat "+
+			"dass sie nach wird bei einer um am sind noch wie einem über einen so zum war haben nur oder aber vor zur "+
+			"bis mehr durch man wir ich uns mir dann doch wieder sehr schon hier wo ganz unser unsere habe waren",
+		"_de _di _un und nd_ der er_ ie_ die ich en_ ein sch che cht ung ng_ _ei _ge gen _da _zu den ten ine ver "+
+			"_ve ber _be ach ch_ ßen _fü für ür_ übe ähr lic ige ste _au auf uf_ _mi mit it_ ns_ _wi",
+	),
+	"english": newLanguageProfile(
+		"the of and to in is was it for on with as that he be at by this had not are but from or have an they which "+
+			"one you were her all she there would their we him been has when who will more no if out so said what up "+
+			"its about into than them can only other new some could time these two may then do first any my now our",
+		"_th the he_ _an and nd_ _of of_ _to to_ ing ng_ _in ion tio ed_ _wa was as_ _is is_ hat tha _fo for "+
+			"or_ _wi ith wit ere her ter _be ent re_ _ha _yo you ou_ ly_ all _we thi his _it it_",
+	),
+}
+
+// languageWordRegex matches the words of a text for the language detection.
+var languageWordRegex = regexp.MustCompile(`\p{L}+`)
+
+// detectLanguage detects the language of a text. It returns the language
+// ("german", "english") and how confident the detection is (0 to 1);
+// the language is empty if the text has no words of any known language.
+func detectLanguage(text string) (string, float64) {
+	scores := make(map[string]int)
+	total := 0
+	for _, word := range languageWordRegex.FindAllString(strings.ToLower(text), -1) {
+		padded := []rune(" " + word + " ")
+		for language, profile := range languageProfiles {
+			score := 0
+			if profile.words[word] {
+				score += 3
+			}
+			for i := 0; i+3 <= len(padded); i++ {
+				if profile.trigrams[string(padded[i:i+3])] {
+					score++
+				}
+			}
+			scores[language] += score
+			total += score
+		}
+	}
+	if total == 0 {
+		return "", 0
+	}
+
+	best := ""
+	for language, score := range scores {
+		if best == "" || score > scores[best] || (score == scores[best] && language < best) {
+			best = language
+		}
+	}
+	return best, float64(scores[best]) / float64(total)
+}
+
+// postText returns the title and the content of a post for the language detection.
+func postText(post *BlogPost) string {
+	var builder strings.Builder
+	builder.WriteString(post.Meta.Title)
+	for _, block := range post.Content {
+		builder.WriteString("\n")
+		builder.WriteString(block.Text)
+	}
+	return builder.String()
+}
+
+// applyLanguageDetection sets the language of a post without a "language::"
+// property from its content. Uncertain detections are reported, posts
+// without any known words stay German.
+func (c *Converter) applyLanguageDetection(post *BlogPost) {
+	if !c.config.Language.Detect || strings.TrimSpace(post.Meta.Language) != "" {
+		return
+	}
+	language, confidence := detectLanguage(postText(post))
+	if language == "" {
+		fmt.Printf("Warning: language of '%s' could not be detected, add a language:: property (written as German)\n", post.Meta.Title)
+		return
+	}
+	post.Meta.Language = language
+	if confidence < c.config.Language.MinConfidence {
+		fmt.Printf("Warning: language of '%s' is uncertain (%s with %.0f%% confidence), add a language:: property\n",
+			post.Meta.Title, language, confidence*100)
+	}
+}
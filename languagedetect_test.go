@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+)
+
+// TestDetectLanguage tests detecting German and English texts
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name          string
+		text          string
+		want          string
+		minConfidence float64
+	}{
+		{"german", "Wir sind mit dem Boot über den See gefahren und haben am Abend in der Bucht geankert.", "german", 0.8},
+		{"english", "We sailed the boat across the lake and anchored in the bay when the wind had dropped.", "english", 0.8},
+		{"markdown", "## Tag 1\n\n![Der Hafen](./hafen.jpg) Die Fahrt nach **Renan** war schön, aber sehr kalt.", "german", 0.7},
+		{"no words", "2026-01-17 12:00 ![](./1.jpg)", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, confidence := detectLanguage(tt.text)
+			if got != tt.want || confidence < tt.minConfidence {
+				t.Errorf("detectLanguage() = %q (%.2f), want %q (at least %.2f)", got, confidence, tt.want, tt.minConfidence)
+			}
+		})
+	}
+}
+
+// TestApplyLanguageDetection tests that only posts without a language are detected
+func TestApplyLanguageDetection(t *testing.T) {
+	config := DefaultConfig()
+	config.Language.Detect = true
+	converter := NewConverter(config, NewMemFileSystem(nil))
+
+	english := &BlogPost{Meta: BlogMeta{Title: "Ibiza"}, Content: []ContentBlock{{Text: "We anchored in the bay for the night."}}}
+	converter.applyLanguageDetection(english)
+	if english.Meta.Language != "english" {
+		t.Errorf("Language = %q, want english", english.Meta.Language)
+	}
+
+	set := &BlogPost{Meta: BlogMeta{Title: "Ibiza", Language: "german"}, Content: english.Content}
+	converter.applyLanguageDetection(set)
+	if set.Meta.Language != "german" {
+		t.Errorf("Language = %q, want the language:: property", set.Meta.Language)
+	}
+
+	config.Language.Detect = false
+	off := &BlogPost{Meta: BlogMeta{Title: "Ibiza"}, Content: english.Content}
+	converter.applyLanguageDetection(off)
+	if off.Meta.Language != "" {
+		t.Errorf("Language = %q without detection, want none", off.Meta.Language)
+	}
+}
@@ -0,0 +1,102 @@
+// This file handles lazy loading of the images in photo-heavy posts:
+// every image becomes the configured image shortcode with loading="lazy",
+//
+//	{{< figure src="hafen.jpg" alt="Hafen" loading="lazy" placeholder="hafen.placeholder.jpg" >}}
+//
+// optionally with a small blurred thumbnail the theme can show until the image is loaded.
+package main
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// placeholderQuality is the JPEG quality of the placeholders; they are blurred anyway.
+const placeholderQuality = 60
+
+// lazyImage renders the lazy shortcode of an image copied from ref to filename
+// in the bundle, and writes its placeholder if placeholders are enabled.
+func (p *ImageProcessor) lazyImage(ctx context.Context, ref, filename, alt string, size ImageSize) string {
+	attrs := []string{`loading="lazy"`}
+	if p.placeholderWidth > 0 && hasPlaceholder(filename) && ctx.Err() == nil {
+		name := placeholderName(filename)
+		dst := filepath.Join(p.outputDir, localPath(name))
+		if err := p.writePlaceholder(p.sourcePath(ref), dst); err != nil {
+			fmt.Printf("Warning: Can't write placeholder of %s: %v\n", filename, err)
+		} else {
+			p.recordAsset(dst, "placeholder")
+			attrs = append(attrs, fmt.Sprintf(`placeholder="%s"`, strings.ReplaceAll(markdownPath(name), `"`, `\"`)))
+		}
+	}
+	return imageShortcode(p.lazyShortcode, markdownPath(filename), alt, size, attrs...)
+}
+
+// hasPlaceholder reports whether a placeholder can be written for an image:
+// the formats Go decodes (JPEG, PNG and GIF), not SVG or WebP.
+func hasPlaceholder(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".jpg", ".jpeg", ".png", ".gif":
+		return true
+	}
+	return false
+}
+
+// placeholderName returns the bundle name of the placeholder of an image ("hafen.png" -> "hafen.placeholder.jpg").
+func placeholderName(name string) string {
+	return strings.TrimSuffix(name, path.Ext(name)) + ".placeholder.jpg"
+}
+
+// writePlaceholder writes the blurred thumbnail of the image src to dst.
+// A placeholder that is not older than its image is kept, so photos are not decoded again.
+func (p *ImageProcessor) writePlaceholder(src, dst string) error {
+	srcInfo, err := p.fs.Stat(src)
+	if err != nil {
+		return err
+	}
+	if dstInfo, err := p.fs.Stat(dst); err == nil && !dstInfo.ModTime().Before(srcInfo.ModTime()) {
+		return nil
+	}
+
+	img, err := p.decodeImage(src)
+	if err != nil {
+		return err
+	}
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return fmt.Errorf("empty image")
+	}
+	width := min(p.placeholderWidth, bounds.Dx())
+	height := max(width*bounds.Dy()/bounds.Dx(), 1)
+	return p.writeJPEG(dst, blur(cropAndScale(img, width, height)), placeholderQuality)
+}
+
+// blur smooths an image with a 3x3 box filter, so the placeholder stays
+// blurry instead of blocky when the browser scales it up.
+func blur(img *image.RGBA) *image.RGBA {
+	bounds := img.Bounds()
+	dst := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			var sum [4]int
+			n := 0
+			for sy := max(y-1, bounds.Min.Y); sy <= min(y+1, bounds.Max.Y-1); sy++ {
+				for sx := max(x-1, bounds.Min.X); sx <= min(x+1, bounds.Max.X-1); sx++ {
+					pixel := img.Pix[img.PixOffset(sx, sy):]
+					for i := range sum {
+						sum[i] += int(pixel[i])
+					}
+					n++
+				}
+			}
+			pixel := dst.Pix[dst.PixOffset(x, y):]
+			for i := range sum {
+				pixel[i] = uint8(sum[i] / n)
+			}
+		}
+	}
+	return dst
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"path/filepath"
+	"testing"
+)
+
+// TestProcessContent_LazyImages tests writing the images as lazy shortcode with placeholders
+func TestProcessContent_LazyImages(t *testing.T) {
+	// A 300x200 image: black on the left half, white on the right half
+	img := image.NewRGBA(image.Rect(0, 0, 300, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 300; x++ {
+			if x >= 150 {
+				img.Set(x, y, color.White)
+			} else {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to encode image: %v", err)
+	}
+
+	fsys := NewMemFileSystem(nil)
+	fsys.WriteFile(filepath.Join("graph", "assets", "hafen.png"), buf.Bytes())
+	fsys.WriteFile(filepath.Join("graph", "assets", "logo.svg"), []byte("<svg/>"))
+	fsys.MkdirAll("out", 0755)
+
+	processor := NewImageProcessor(NewCopyManager(fsys), filepath.Join("graph", "journals"), "out")
+	processor.lazyShortcode = "figure"
+	processor.placeholderWidth = 24
+	got := processor.ProcessContent(context.Background(),
+		"![Hafen](../assets/hafen.png){:height 400, :width 600}\n![](../assets/logo.svg)\n![](https://example.com/a.png)\n`![](../assets/hafen.png)`")
+
+	want := `{{< figure src="hafen.png" alt="Hafen" width="600" height="400" loading="lazy" placeholder="hafen.placeholder.jpg" >}}` + "\n" +
+		`{{< figure src="logo.svg" loading="lazy" >}}` + "\n" +
+		"![](https://example.com/a.png)\n`![](../assets/hafen.png)`"
+	if got != want {
+		t.Errorf("ProcessContent() = %q, want %q", got, want)
+	}
+
+	data, err := readFile(fsys, filepath.Join("out", "hafen.placeholder.jpg"))
+	if err != nil {
+		t.Fatalf("Placeholder was not written: %v", err)
+	}
+	decoded, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Placeholder is no JPEG: %v", err)
+	}
+	if size := decoded.Bounds().Size(); size != image.Pt(24, 16) {
+		t.Errorf("Placeholder size = %v, want 24x16", size)
+	}
+	// The edge between black and white is blurred
+	if r, _, _, _ := decoded.At(12, 8).RGBA(); r>>8 < 40 || r>>8 > 215 {
+		t.Errorf("Placeholder is not blurred at the edge: red = %d", r>>8)
+	}
+}
@@ -0,0 +1,53 @@
+// This file handles rewriting Logseq page links between converted posts.
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// internalLinkRegex matches a page link [[Page]] that is not a tag (#[[Page]]).
+var internalLinkRegex = regexp.MustCompile(`(^|[^#])\[\[([^\]]+)\]\]`)
+
+// buildLinkMap maps the names a post can be linked by to its bundle path.
+// A post can be linked by its title and, for pages, by its page (file) name.
+// Names are lowercase because Logseq page names are case-insensitive.
+func buildLinkMap(posts []*BlogPost) map[string]string {
+	links := make(map[string]string)
+	for _, post := range posts {
+		links[strings.ToLower(post.Meta.Title)] = post.BundlePath()
+		if post.SourcePath != "" && filepath.Base(filepath.Dir(post.SourcePath)) == "pages" {
+			links[strings.ToLower(pageNameFromFile(post.SourcePath))] = post.BundlePath()
+		}
+	}
+	return links
+}
+
+// pageNameFromFile returns the Logseq page name of a page file.
+// Logseq stores namespaces "a/b" as "a___b.md".
+func pageNameFromFile(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return strings.ReplaceAll(name, "___", "/")
+}
+
+// rewriteInternalLinks replaces [[Page]] links to converted posts with Hugo relref links:
+// [[Page B]] -> [Page B]({{< relref "2026-01-22_Page_B" >}})
+// Links to pages that are not converted and links in code are left untouched.
+func rewriteInternalLinks(block string, links map[string]string) string {
+	if len(links) == 0 || !strings.Contains(block, "[[") {
+		return block
+	}
+
+	return replaceOutsideCode(block, func(text string) string {
+		return internalLinkRegex.ReplaceAllStringFunc(text, func(match string) string {
+			parts := internalLinkRegex.FindStringSubmatch(match)
+			slug, ok := links[strings.ToLower(strings.TrimSpace(parts[2]))]
+			if !ok {
+				return match
+			}
+			return fmt.Sprintf(`%s[%s]({{< relref "%s" >}})`, parts[1], parts[2], slug)
+		})
+	})
+}
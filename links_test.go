@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+// TestRewriteInternalLinks tests rewriting page links between converted posts
+func TestRewriteInternalLinks(t *testing.T) {
+	links := buildLinkMap([]*BlogPost{
+		{Meta: BlogMeta{Title: "Second Post"}, Slug: "2025-01-22_Second_Post"},
+		{Meta: BlogMeta{Title: "Renan"}, Slug: "2024-06-14_Renan", SourcePath: "graph/pages/Renan.md"},
+		{Meta: BlogMeta{Title: "Trip"}, Slug: "2024-07-01_Trip", SourcePath: "graph/pages/blog___trips___Ibiza.md"},
+	})
+
+	tests := []struct {
+		name  string
+		block string
+		want  string
+	}{
+		{"Link by title", "See [[Second Post]].", `See [Second Post]({{< relref "2025-01-22_Second_Post" >}}).`},
+		{"Case insensitive", "[[second post]]", `[second post]({{< relref "2025-01-22_Second_Post" >}})`},
+		{"Link by namespaced page name", "[[blog/trips/Ibiza]]", `[blog/trips/Ibiza]({{< relref "2024-07-01_Trip" >}})`},
+		{"Unknown page stays", "[[Unknown]]", "[[Unknown]]"},
+		{"Tags are not rewritten", "#[[Renan]]", "#[[Renan]]"},
+		{"Code span stays", "Write `[[Renan]]` for [[Renan]]", "Write `[[Renan]]` for [Renan]({{< relref \"2024-06-14_Renan\" >}})"},
+		{"Code block stays", "```clojure\n[[Second Post]]\n```", "```clojure\n[[Second Post]]\n```"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rewriteInternalLinks(tt.block, links); got != tt.want {
+				t.Errorf("rewriteInternalLinks() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
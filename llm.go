@@ -0,0 +1,158 @@
+// This file handles the optional steps of the conversion that ask a large
+// language model (like writing alt text for images). They use the OpenAI API
+// like the translation tool; the API key is read from OPENAI_API_KEY.
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+
+	"logseq-to-hugo-converter/internal/lang"
+)
+
+// DefaultLLMModel is the model used if none is configured.
+const DefaultLLMModel = openai.ChatModelGPT4oMini
+
+// llmClient sends prompts to the configured model.
+type llmClient struct {
+	client *openai.Client
+	model  string
+}
+
+// newLLMClient creates an llmClient with the API key from the environment.
+func newLLMClient(config LLMConfig) (*llmClient, error) {
+	apiKey := os.Getenv("OPENAI_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("OPENAI_API_KEY environment variable not set")
+	}
+
+	client := openai.NewClient(option.WithAPIKey(apiKey))
+	model := config.Model
+	if model == "" {
+		model = DefaultLLMModel
+	}
+	return &llmClient{client: &client, model: model}, nil
+}
+
+// complete sends the instructions and the user content to the model and returns its answer.
+// Failed requests are retried a few times.
+func (c *llmClient) complete(ctx context.Context, instructions string, content []openai.ChatCompletionContentPartUnionParam) (string, error) {
+	const maxRetries = 3
+
+	var err error
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			case <-time.After(time.Second * time.Duration(attempt)):
+			}
+		}
+
+		var completion *openai.ChatCompletion
+		completion, err = c.client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+			Model: c.model,
+			Messages: []openai.ChatCompletionMessageParamUnion{
+				openai.SystemMessage(instructions),
+				openai.UserMessage(content),
+			},
+			Temperature: openai.Float(0.3),
+		})
+		if err != nil {
+			continue
+		}
+		if len(completion.Choices) == 0 {
+			return "", fmt.Errorf("no answer returned from the API")
+		}
+		return strings.TrimSpace(completion.Choices[0].Message.Content), nil
+	}
+	return "", fmt.Errorf("OpenAI API call failed after %d attempts: %w", maxRetries, err)
+}
+
+// llmLanguage returns the language the model writes in for a post's "language::"
+// property ("de" and "Deutsch" are "german"). Posts without a language are German,
+// like their index file; unknown languages are passed on as they are.
+func llmLanguage(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if language == "" {
+		return "german"
+	}
+	if tag, ok := lang.Parse(language); ok {
+		return strings.ToLower(tag.Name())
+	}
+	return language
+}
+
+// llmCache keeps the answers of the model in a JSON file, so unchanged
+// images and posts aren't sent to the model again in the next conversion.
+type llmCache struct {
+	fs      FileSystem        // File system of the cache file
+	path    string            // Cache file
+	entries map[string]string // Key (see llmCacheKey) -> answer
+	changed bool              // The cache has new entries
+}
+
+// loadLLMCache reads a cache file. A missing file is an empty cache.
+func loadLLMCache(fsys FileSystem, path string) (*llmCache, error) {
+	cache := &llmCache{fs: fsys, path: path, entries: make(map[string]string)}
+	data, err := readFile(fsys, path)
+	if err != nil {
+		return cache, nil
+	}
+	if err := json.Unmarshal(data, &cache.entries); err != nil {
+		return nil, fmt.Errorf("reading cache %s: %w", path, err)
+	}
+	return cache, nil
+}
+
+// llmCacheKey returns the cache key of an answer about data in a language ("<sha256>:german").
+func llmCacheKey(data []byte, language string) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]) + ":" + language
+}
+
+// get returns a cached answer.
+func (c *llmCache) get(key string) (string, bool) {
+	value, ok := c.entries[key]
+	return value, ok
+}
+
+// set adds an answer to the cache.
+func (c *llmCache) set(key, value string) {
+	c.entries[key] = value
+	c.changed = true
+}
+
+// save writes the cache file if it has new entries.
+func (c *llmCache) save() error {
+	if !c.changed {
+		return nil
+	}
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := c.fs.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("writing cache: %w", err)
+	}
+	f, err := c.fs.Create(c.path)
+	if err != nil {
+		return fmt.Errorf("writing cache: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("writing cache: %w", err)
+	}
+	c.changed = false
+	return f.Close()
+}
@@ -0,0 +1,43 @@
+// This file handles the location of a post ("location:: 47.37, 8.54" or "location:: [[Zürich]]").
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// coordinatesRegex matches decimal coordinates "latitude, longitude" like "47.37, 8.54".
+var coordinatesRegex = regexp.MustCompile(`^(-?\d+(?:\.\d+)?)\s*,\s*(-?\d+(?:\.\d+)?)$`)
+
+// parseLocation parses a location property into coordinates or a place name.
+// Values that are no valid coordinates are place names ("[[Zürich]]" becomes "Zürich").
+// An empty value returns nil.
+func parseLocation(value string) *LocationMeta {
+	value = strings.TrimSpace(value)
+	if match := coordinatesRegex.FindStringSubmatch(value); match != nil {
+		lat, _ := strconv.ParseFloat(match[1], 64)
+		lng, _ := strconv.ParseFloat(match[2], 64)
+		if lat >= -90 && lat <= 90 && lng >= -180 && lng <= 180 {
+			return &LocationMeta{Lat: lat, Lng: lng, HasCoordinates: true}
+		}
+	}
+
+	name := strings.TrimPrefix(value, "#")
+	name = strings.TrimPrefix(name, "[[")
+	name = strings.TrimSuffix(name, "]]")
+	if name = strings.TrimSpace(name); name == "" {
+		return nil
+	}
+	return &LocationMeta{Name: name}
+}
+
+// locationShortcode renders the map shortcode of a location:
+// {{< map lat="47.37" lng="8.54" >}} or {{< map name="Zürich" >}}
+func locationShortcode(shortcode string, location *LocationMeta) string {
+	if location.HasCoordinates {
+		return fmt.Sprintf(`{{< %s lat="%g" lng="%g" >}}`, shortcode, location.Lat, location.Lng)
+	}
+	return fmt.Sprintf(`{{< %s name="%s" >}}`, shortcode, strings.ReplaceAll(location.Name, `"`, `\"`))
+}
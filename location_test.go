@@ -0,0 +1,47 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestParseLocation tests parsing coordinates and place names
+func TestParseLocation(t *testing.T) {
+	tests := []struct {
+		value string
+		want  *LocationMeta
+	}{
+		{"47.37, 8.54", &LocationMeta{Lat: 47.37, Lng: 8.54, HasCoordinates: true}},
+		{"-33.87,151.21", &LocationMeta{Lat: -33.87, Lng: 151.21, HasCoordinates: true}},
+		{"[[Zürich]]", &LocationMeta{Name: "Zürich"}},
+		{"#Ibiza", &LocationMeta{Name: "Ibiza"}},
+		{"New York", &LocationMeta{Name: "New York"}},
+		{"147.37, 8.54", &LocationMeta{Name: "147.37, 8.54"}}, // Latitude out of range
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := parseLocation(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseLocation(%q) = %+v, want %+v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLocationShortcode tests rendering the map shortcode
+func TestLocationShortcode(t *testing.T) {
+	tests := []struct {
+		location *LocationMeta
+		want     string
+	}{
+		{&LocationMeta{Lat: 47.37, Lng: 8.54, HasCoordinates: true}, `{{< map lat="47.37" lng="8.54" >}}`},
+		{&LocationMeta{Name: `Café "Zur Post"`}, `{{< map name="Café \"Zur Post\"" >}}`},
+	}
+
+	for _, tt := range tests {
+		if got := locationShortcode("map", tt.location); got != tt.want {
+			t.Errorf("locationShortcode() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+// This file handles reading graphs through the Logseq HTTP API.
+// Newer Logseq versions keep graphs in a database instead of markdown files.
+// With the API server of the desktop app enabled, the pages are exported
+// through the API into a temporary graph directory and converted like files.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LogseqClient calls the methods of the Logseq HTTP API (e.g. "logseq.Editor.getAllPages").
+type LogseqClient struct {
+	url    string // Base URL of the API server (e.g. "http://127.0.0.1:12315")
+	token  string // Authorization token configured in the API server settings
+	client *http.Client
+}
+
+// NewLogseqClient creates a client for the API server at url.
+func NewLogseqClient(url, token string) *LogseqClient {
+	return &LogseqClient{
+		url:    strings.TrimSuffix(url, "/"),
+		token:  token,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// apiPage is a page as returned by the API.
+// File graphs use originalName, database graphs use title.
+type apiPage struct {
+	Name         string `json:"name"`
+	OriginalName string `json:"originalName"`
+	Title        string `json:"title"`
+	Journal      bool   `json:"journal?"`
+	JournalDay   int    `json:"journalDay"` // e.g. 20260117
+	Type         string `json:"type"`       // e.g. "whiteboard"
+}
+
+// apiBlock is a block of a page tree as returned by the API.
+// File graphs have the text with its properties in content,
+// database graphs have it in title and the properties separately.
+type apiBlock struct {
+	Content    string                 `json:"content"`
+	Title      string                 `json:"title"`
+	Properties map[string]interface{} `json:"properties"`
+	Children   []apiBlock             `json:"children"`
+}
+
+// UnmarshalJSON decodes a block. Collapsed children are only references
+// (["uuid", "..."]) instead of blocks, they are decoded as empty blocks.
+func (b *apiBlock) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		*b = apiBlock{}
+		return nil
+	}
+	type plain apiBlock // Without the UnmarshalJSON method
+	return json.Unmarshal(data, (*plain)(b))
+}
+
+// apiGraph is the current graph as returned by the API.
+type apiGraph struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// call calls an API method and decodes its result into result.
+func (c *LogseqClient) call(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	if args == nil {
+		args = []interface{}{}
+	}
+	body, err := json.Marshal(map[string]interface{}{"method": method, "args": args})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+"/api", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("calling %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("calling %s: %w", method, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("calling %s: %s: %s", method, resp.Status, strings.TrimSpace(string(data)))
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("calling %s: invalid response: %w", method, err)
+	}
+	return nil
+}
+
+// Pages returns all pages of the current graph.
+func (c *LogseqClient) Pages(ctx context.Context) ([]apiPage, error) {
+	var pages []apiPage
+	err := c.call(ctx, &pages, "logseq.Editor.getAllPages")
+	return pages, err
+}
+
+// PageBlocks returns the block tree of a page.
+func (c *LogseqClient) PageBlocks(ctx context.Context, page string) ([]apiBlock, error) {
+	var blocks []apiBlock
+	err := c.call(ctx, &blocks, "logseq.Editor.getPageBlocksTree", page)
+	return blocks, err
+}
+
+// CurrentGraph returns the graph that is open in Logseq.
+func (c *LogseqClient) CurrentGraph(ctx context.Context) (apiGraph, error) {
+	var graph apiGraph
+	err := c.call(ctx, &graph, "logseq.App.getCurrentGraph")
+	return graph, err
+}
+
+// exportGraph writes the pages of the current graph as Logseq markdown files
+// into dir ("journals/2026_01_17.md", "pages/blog___Renan.md") and returns
+// the number of written files. Whiteboards and empty pages are left out.
+func exportGraph(ctx context.Context, client *LogseqClient, fsys FileSystem, dir string) (int, error) {
+	pages, err := client.Pages(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	written := 0
+	for _, page := range pages {
+		if page.Type == "whiteboard" {
+			continue
+		}
+		blocks, err := client.PageBlocks(ctx, page.Name)
+		if err != nil {
+			return written, err
+		}
+		if len(blocks) == 0 {
+			continue
+		}
+
+		var builder strings.Builder
+		for _, block := range blocks {
+			writeAPIBlock(&builder, block, 0)
+		}
+
+		path := filepath.Join(dir, exportPath(page))
+		if err := fsys.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return written, err
+		}
+		if err := writeExport(fsys, path, builder.String()); err != nil {
+			return written, err
+		}
+		written++
+	}
+	return written, nil
+}
+
+// writeExport writes an exported page file.
+func writeExport(fsys FileSystem, path, content string) error {
+	f, err := fsys.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(f, content); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// exportPath returns the path of the file of a page in the graph, like Logseq names it.
+func exportPath(page apiPage) string {
+	if page.Journal && page.JournalDay > 0 {
+		day := page.JournalDay
+		return filepath.Join("journals", fmt.Sprintf("%04d_%02d_%02d.md", day/10000, day/100%100, day%100))
+	}
+
+	name := page.OriginalName
+	if name == "" {
+		name = page.Title
+	}
+	if name == "" {
+		name = page.Name
+	}
+	// Namespaces "a/b" are stored as "a___b", other characters files can't have are replaced
+	name = strings.ReplaceAll(name, "/", "___")
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`\:*?"<>|`, r) || r < 0x20 {
+			return '_'
+		}
+		return r
+	}, name)
+	return filepath.Join("pages", name+".md")
+}
+
+// writeAPIBlock writes a block and its children as Logseq markdown bullets.
+// Properties that are not part of the block text (database graphs) are added as "key:: value" lines.
+func writeAPIBlock(builder *strings.Builder, block apiBlock, depth int) {
+	text := block.Content
+	if text == "" {
+		text = block.Title
+	}
+	indent := strings.Repeat("\t", depth)
+
+	lines := strings.Split(text, "\n")
+	builder.WriteString(indent + "- " + lines[0] + "\n")
+	for _, line := range lines[1:] {
+		builder.WriteString(indent + "  " + line + "\n")
+	}
+
+	keys := make([]string, 0, len(block.Properties))
+	for key := range block.Properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !blockPropertyRegex.MatchString(key+"::") || strings.Contains(strings.ToLower(text), strings.ToLower(key)+"::") {
+			continue
+		}
+		builder.WriteString(indent + "  " + key + ":: " + apiPropertyValue(block.Properties[key]) + "\n")
+	}
+
+	for _, child := range block.Children {
+		if child.Content != "" || child.Title != "" || len(child.Children) > 0 {
+			writeAPIBlock(builder, child, depth+1)
+		}
+	}
+}
+
+// apiPropertyValue formats a property value of the API as Logseq writes it ("a, b" for lists).
+func apiPropertyValue(value interface{}) string {
+	switch v := value.(type) {
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = apiPropertyValue(item)
+		}
+		return strings.Join(items, ", ")
+	case nil:
+		return ""
+	default:
+		return strings.ReplaceAll(fmt.Sprint(v), "\n", " ")
+	}
+}
+
+// exportAPIGraph exports the current graph of the Logseq API into a temporary
+// directory. It returns the directory and a function that removes it again.
+// The assets stay in the graph directory, the export links to them.
+func exportAPIGraph(ctx context.Context, url, token string) (string, func(), error) {
+	dir, err := os.MkdirTemp("", "logseq-graph-")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	client := NewLogseqClient(url, token)
+	files, err := exportGraph(ctx, client, OSFileSystem{}, dir)
+	if err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("reading the graph from the Logseq API: %w", err)
+	}
+
+	if graph, err := client.CurrentGraph(ctx); err == nil && graph.Path != "" {
+		assets := filepath.Join(graph.Path, "assets")
+		if info, err := os.Stat(assets); err == nil && info.IsDir() {
+			if err := os.Symlink(assets, filepath.Join(dir, "assets")); err != nil {
+				fmt.Printf("Warning: assets of %s are not available: %v\n", graph.Name, err)
+			}
+		}
+	}
+
+	fmt.Printf("Exported %d pages from the Logseq API\n", files)
+	return dir, cleanup, nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeLogseqAPI serves a small graph like the Logseq HTTP API server
+func fakeLogseqAPI(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+	pages := `[
+		{"name": "blog/renan", "originalName": "blog/Renan"},
+		{"name": "jan 17th, 2026", "journal?": true, "journalDay": 20260117},
+		{"name": "trip", "type": "whiteboard"},
+		{"name": "empty", "originalName": "Empty"}
+	]`
+	blocks := map[string]string{
+		"blog/renan": `[
+			{"content": "type:: blog\nstatus:: online\ndate:: 2026-01-17\ntitle:: Renan", "properties": {"type": "blog", "status": "online"}},
+			{"content": "Hiking", "children": [{"content": "Up the hill"}, ["uuid", "6650f3e2"]]}
+		]`,
+		"jan 17th, 2026": `[
+			{"title": "[[Sailing]]", "children": [
+				{"title": "Boot", "properties": {"type": "blog", "status": "online", "date": "2026-01-17", "tags": ["sailing", "boats"], "title": "Boot"}},
+				{"title": "On the lake"}
+			]}
+		]`,
+		"empty": `[]`,
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var call struct {
+			Method string   `json:"method"`
+			Args   []string `json:"args"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&call); err != nil {
+			t.Errorf("Invalid request: %v", err)
+		}
+		switch call.Method {
+		case "logseq.Editor.getAllPages":
+			w.Write([]byte(pages))
+		case "logseq.Editor.getPageBlocksTree":
+			if call.Args[0] == "trip" {
+				t.Error("Whiteboard blocks requested")
+			}
+			w.Write([]byte(blocks[call.Args[0]]))
+		default:
+			http.Error(w, "unknown method", http.StatusNotFound)
+		}
+	}))
+}
+
+// TestExportGraph tests exporting the pages of the API as markdown files
+func TestExportGraph(t *testing.T) {
+	server := fakeLogseqAPI(t, "secret")
+	defer server.Close()
+
+	fsys := NewMemFileSystem(nil)
+	written, err := exportGraph(context.Background(), NewLogseqClient(server.URL+"/", "secret"), fsys, "graph")
+	if err != nil {
+		t.Fatalf("exportGraph() error = %v", err)
+	}
+	if written != 2 {
+		t.Errorf("exportGraph() wrote %d files, want 2", written)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{
+			path: filepath.Join("graph", "pages", "blog___Renan.md"),
+			want: "- type:: blog\n  status:: online\n  date:: 2026-01-17\n  title:: Renan\n- Hiking\n\t- Up the hill\n",
+		},
+		{
+			path: filepath.Join("graph", "journals", "2026_01_17.md"),
+			want: "- [[Sailing]]\n\t- Boot\n\t  date:: 2026-01-17\n\t  status:: online\n\t  tags:: sailing, boats\n\t  title:: Boot\n\t  type:: blog\n\t- On the lake\n",
+		},
+	}
+	for _, tt := range tests {
+		got, err := readFile(fsys, tt.path)
+		if err != nil {
+			t.Errorf("%s not exported: %v", tt.path, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s =\n%s\nwant\n%s", tt.path, got, tt.want)
+		}
+	}
+
+	// The exported graph converts like a graph of files
+	outputs, err := NewConverter(DefaultConfig(), fsys).ConvertGraph(context.Background(), "graph", "out")
+	if err != nil {
+		t.Fatalf("ConvertGraph() error = %v", err)
+	}
+	if len(outputs) != 2 {
+		t.Errorf("ConvertGraph() of the export = %d outputs, want 2", len(outputs))
+	}
+}
+
+// TestLogseqClientErrors tests that API errors are reported
+func TestLogseqClientErrors(t *testing.T) {
+	server := fakeLogseqAPI(t, "secret")
+	defer server.Close()
+
+	_, err := NewLogseqClient(server.URL, "wrong").Pages(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Pages() with a wrong token error = %v, want 401", err)
+	}
+}
+
+// TestExportPath tests the file names of exported pages
+func TestExportPath(t *testing.T) {
+	tests := []struct {
+		page apiPage
+		want string
+	}{
+		{apiPage{Name: "renan", OriginalName: "Renan"}, filepath.Join("pages", "Renan.md")},
+		{apiPage{Name: "blog/trips/renan", Title: "blog/trips/Renan"}, filepath.Join("pages", "blog___trips___Renan.md")},
+		{apiPage{Name: "what?"}, filepath.Join("pages", "what_.md")},
+		{apiPage{Name: "jan 5th, 2026", Journal: true, JournalDay: 20260105}, filepath.Join("journals", "2026_01_05.md")},
+	}
+
+	for _, tt := range tests {
+		if got := exportPath(tt.page); got != tt.want {
+			t.Errorf("exportPath(%+v) = %q, want %q", tt.page, got, tt.want)
+		}
+	}
+}
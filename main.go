@@ -2,117 +2,273 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
-
-	"github.com/yuin/goldmark"
-	"github.com/yuin/goldmark/text"
+	"time"
 )
 
 func main() {
-	if len(os.Args) < 3 {
-		fmt.Println("Usage: go run . <input_file.md> <output_directory>")
+	// Subcommands
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "check":
+			os.Exit(runCheck(os.Args[2:]))
+		case "import-all":
+			os.Exit(runImportAll(os.Args[2:]))
+		case "suggest-tags":
+			os.Exit(runSuggestTags(os.Args[2:]))
+		case "config":
+			os.Exit(runConfig(os.Args[2:]))
+		case "init":
+			os.Exit(runInit(os.Args[2:]))
+		case "doctor":
+			os.Exit(runDoctor(os.Args[2:]))
+		case "dashboard":
+			os.Exit(runDashboard(os.Args[2:]))
+		case "sync-back":
+			os.Exit(runSyncBack(os.Args[2:]))
+		case "resummarize":
+			os.Exit(runResummarize(os.Args[2:]))
+		case "clean-assets":
+			os.Exit(runCleanAssets(os.Args[2:]))
+		case "preview":
+			os.Exit(runPreview(os.Args[2:]))
+		}
+	}
+
+	configPath := flag.String("config", "", "path to a converter.toml configuration file")
+	dryRun := flag.Bool("dry-run", false, "convert without writing anything to the output directory")
+	diff := flag.Bool("diff", false, "show what the conversion would change in the output directory as a unified diff, without writing anything")
+	interactive := flag.Bool("interactive", false, "show each post and ask whether to convert it")
+	force := flag.Bool("force", false, "overwrite index files that were edited by hand since the last conversion")
+	timeout := flag.Duration("timeout", 0, "stop the conversion after this duration (e.g. 2m, 0 = no limit)")
+	cpuProfile := flag.String("cpuprofile", "", "write a CPU profile of the conversion to this file")
+	memProfile := flag.String("memprofile", "", "write a memory profile after the conversion to this file")
+	generateSummary := flag.Bool("generate-summary", false, "let the language model write the summary of posts without a summary:: property (needs OPENAI_API_KEY)")
+	showVersion := flag.Bool("version", false, "print the version of the converter and exit")
+	archivePath := flag.String("archive", "", "write the generated bundles into this .tar.gz, .tar or .zip file instead of the output directory")
+	apiURL := flag.String("api", "", "read the graph from the Logseq HTTP API at this URL (e.g. http://127.0.0.1:12315), the token is read from LOGSEQ_API_TOKEN")
+	flag.Parse()
+
+	if *showVersion {
+		printVersion(os.Stdout, readVersion())
 		return
 	}
 
-	inputPath := os.Args[1]
-	outputBasePath := os.Args[2]
+	// With the API, the graph comes from Logseq and only the output directory is given
+	args := flag.Args()
+	if *apiURL != "" {
+		args = append([]string{""}, args...)
+	}
 
-	// Convert the file
-	outputs, err := convertFile(inputPath, outputBasePath)
+	if len(args) < 2 {
+		fmt.Println("Usage: go run . [-config converter.toml] [-dry-run] [-diff] [-interactive] [-force] [-timeout 2m] [-generate-summary] [-archive out.tar.gz] <input_file.md|graph_directory|notion_export.zip> <output_directory>")
+		fmt.Println("       go run . -api http://127.0.0.1:12315 [-config converter.toml] [-dry-run] [-interactive] [-force] [-timeout 2m] <output_directory>")
+		return
+	}
+
+	inputPath := args[0]
+	outputBasePath := args[1]
+
+	// Load the configuration (defaults if no file is given)
+	config, err := LoadConfig(*configPath)
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
-
-	// Print success messages
-	for _, output := range outputs {
-		fmt.Printf("Created: %s/%s\n", output.Dir, output.Filename)
+	if *generateSummary {
+		config.Summary.Generate = true
 	}
-}
-
-// OutputInfo contains information about a created output file.
-type OutputInfo struct {
-	Dir      string // The directory path
-	Filename string // The created filename (e.g., "index.de.md")
-}
 
-// convertFile converts a Logseq markdown file to Hugo format.
-// It finds all blog posts in the file and converts each one.
-func convertFile(inputPath, outputBasePath string) ([]OutputInfo, error) {
-	// Read the input file
-	source, err := os.ReadFile(inputPath)
+	// Profile the conversion if requested
+	stopProfiling, err := startProfiling(*cpuProfile, *memProfile)
 	if err != nil {
-		return nil, fmt.Errorf("reading input file: %w", err)
+		fmt.Printf("Error: %v\n", err)
+		return
 	}
+	defer stopProfiling()
 
-	// Parse the markdown
-	doc := goldmark.New().Parser().Parse(text.NewReader(source))
+	// A dry run writes into memory on top of the real files
+	if *diff {
+		*dryRun = true
+	}
+	osfs, err := NewOSFileSystem(config.Output)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	var fsys FileSystem = osfs
+	if *dryRun {
+		fsys = NewMemFileSystem(OSFileSystem{})
+	}
 
-	// Extract all blog posts
-	posts := extractBlogPosts(doc, source)
-	if len(posts) == 0 {
-		return nil, fmt.Errorf("no blog post found with 'type:: blog' marker")
+	// An archive or a remote target gets the files written into memory
+	if *archivePath != "" {
+		if _, err := archiveFormat(*archivePath); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if config.Remote.Target != "" {
+			fmt.Println("Error: -archive can't be used with a remote target")
+			return
+		}
+		if !*dryRun {
+			fsys = NewMemFileSystem(OSFileSystem{})
+		}
+	}
+	var uploader Uploader
+	if config.Remote.Target != "" {
+		if uploader, err = NewUploader(config.Remote); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if !*dryRun {
+			fsys = NewMemFileSystem(OSFileSystem{})
+		}
 	}
 
-	var outputs []OutputInfo
-	inputDir := filepath.Dir(inputPath)
+	// Ctrl+C (and the optional timeout) cancel the conversion
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
 
-	// Convert each blog post
-	for _, post := range posts {
-		// Skip non-online posts
-		if post.Meta.Status != "online" {
-			fmt.Printf("Skipping blog post '%s': status is '%s'\n", post.Meta.Title, post.Meta.Status)
-			continue
+	// Graphs without markdown files are exported through the API first
+	if *apiURL != "" {
+		graphDir, cleanup, err := exportAPIGraph(ctx, *apiURL, os.Getenv("LOGSEQ_API_TOKEN"))
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
 		}
+		defer cleanup()
+		inputPath = graphDir
+	}
 
-		// Create output directory
-		outputDir := createOutputDir(outputBasePath, post.Meta)
-		if err := os.MkdirAll(outputDir, 0755); err != nil {
-			return nil, fmt.Errorf("creating output directory: %w", err)
+	// Notion exports are converted from their ZIP file
+	if strings.EqualFold(filepath.Ext(inputPath), ".zip") {
+		exportDir, cleanup, err := unzipExport(inputPath)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
 		}
+		defer cleanup()
+		inputPath = exportDir
+	}
 
-		// Build content
-		content := buildContent(post.Content)
-
-		// Process images and videos
-		processor := NewImageProcessor(inputDir, outputDir)
-		content = processor.ProcessContent(content)
-		processor.ProcessHeaderImage(post.Meta.Header)
+	// Convert a single file or a whole graph directory
+	converter := NewConverter(config, fsys)
+	converter.force = *force
+	if *interactive {
+		converter.review = NewPostReviewer(os.Stdin, os.Stdout)
+	}
+	var outputs []OutputInfo
+	if info, statErr := fsys.Stat(inputPath); statErr == nil && info.IsDir() {
+		outputs, err = converter.ConvertGraph(ctx, inputPath, outputBasePath)
+	} else {
+		outputs, err = converter.ConvertFile(ctx, inputPath, outputBasePath)
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
 
-		// Write output
-		writer := NewHugoWriter(outputDir)
-		filename, err := writer.Write(post.Meta, content)
+	// Print success messages
+	if *diff {
+		changed, err := writeOutputDiff(os.Stdout, fsys.(*MemFileSystem), outputBasePath, stateFiles(config))
 		if err != nil {
-			return nil, err
+			fmt.Printf("Error: %v\n", err)
+			return
 		}
-
-		outputs = append(outputs, OutputInfo{Dir: outputDir, Filename: filename})
+		fmt.Printf("%d files would change\n", changed)
+		writeConflicts(os.Stdout, converter.stats.Conflicts)
+		return
 	}
+	if *dryRun {
+		for _, path := range fsys.(*MemFileSystem).Removed() {
+			if _, err := os.Stat(path); err == nil {
+				fmt.Printf("Would remove: %s\n", path)
+			}
+		}
+	}
+	for _, output := range outputs {
+		if *dryRun {
+			fmt.Printf("Would create: %s/%s\n", output.Dir, output.Filename)
+			continue
+		}
+		if uploader != nil || *archivePath != "" {
+			// Reported by the upload or the archive
+			continue
+		}
+		fmt.Printf("Created: %s/%s\n", output.Dir, output.Filename)
+	}
+	writeConflicts(os.Stdout, converter.stats.Conflicts)
 
-	return outputs, nil
+	if *archivePath != "" {
+		archiveOutputs(fsys.(*MemFileSystem), outputBasePath, stateFiles(config), *archivePath, osfs, *dryRun)
+	}
+	if uploader != nil {
+		uploadOutputs(ctx, uploader, fsys.(*MemFileSystem), outputBasePath, stateFiles(config), config.Remote.Target, osfs, *dryRun)
+	}
 }
 
-// createOutputDir builds the output directory path from metadata.
-func createOutputDir(basePath string, meta BlogMeta) string {
-	// Replace spaces with underscores in title
-	title := strings.ReplaceAll(meta.Title, " ", "_")
-
-	// Format: YYYY-MM-DD_Title
-	dirName := fmt.Sprintf("%s_%s", meta.Date, title)
-	return filepath.Join(basePath, dirName)
+// archiveOutputs writes the files a conversion wrote into memory below outputDir
+// into an archive. The other files (like caches) and the state files are written
+// to local, dry runs only list the archived files.
+func archiveOutputs(mem *MemFileSystem, outputDir string, state []string, archivePath string, local OSFileSystem, dryRun bool) {
+	var localFS FileSystem = local
+	if dryRun {
+		localFS = nil
+	}
+	files, err := collectUploads(mem, outputDir, state, localFS)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if dryRun {
+		for _, file := range files {
+			fmt.Printf("Would archive: %s\n", file.Name)
+		}
+		return
+	}
+	if err := writeArchive(archivePath, files, local.FileMode, time.Now()); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("Archived %d files to %s\n", len(files), archivePath)
 }
 
-// buildContent combines content blocks into a single string.
-func buildContent(blocks []string) string {
-	var builder strings.Builder
-	for _, block := range blocks {
-		if cleaned := strings.TrimSpace(block); cleaned != "" {
-			builder.WriteString(cleaned)
-			builder.WriteString("\n\n")
+// uploadOutputs uploads the files a conversion wrote into memory below outputDir.
+// The other files (like caches) and the state files are written to local, dry
+// runs only list the uploads.
+func uploadOutputs(ctx context.Context, uploader Uploader, mem *MemFileSystem, outputDir string, state []string, target string, local FileSystem, dryRun bool) {
+	if dryRun {
+		local = nil
+	}
+	files, err := collectUploads(mem, outputDir, state, local)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if dryRun {
+		for _, file := range files {
+			fmt.Printf("Would upload: %s\n", file.Name)
 		}
+		return
+	}
+	if err := uploader.Upload(ctx, files); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	for _, file := range files {
+		fmt.Printf("Uploaded: %s\n", file.Name)
 	}
-	return strings.TrimSpace(builder.String())
+	fmt.Printf("Uploaded %d files to %s\n", len(files), target)
 }
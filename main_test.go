@@ -1,10 +1,15 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestConvertLogseqToHugo(t *testing.T) {
@@ -65,39 +70,9 @@ func TestConvertLogseqToHugo(t *testing.T) {
 		t.Errorf("%s content mismatch.\nExpected:\n%s\n\nActual:\n%s", expectedFilename, expectedStr, actualStr)
 	}
 
-	// Test 3: Verify all expected image files exist
-	expectedImages := []string{
-		"featured.jpeg",
-		"image_1768654728313_0.png",
-		"image_1768655067995_0.png",
-		"image_1768655164867_0.png",
-		"image_1768655591886_0.png",
-		"image_1768656457958_0.png",
-	}
-
-	for _, imgName := range expectedImages {
-		actualImgPath := filepath.Join(output.Dir, imgName)
-		expectedImgPath := filepath.Join(expectedOutputDir, imgName)
-
-		// Check if file exists
-		actualInfo, err := os.Stat(actualImgPath)
-		if os.IsNotExist(err) {
-			t.Errorf("Expected image %s does not exist", imgName)
-			continue
-		}
-
-		// Check if file size matches expected
-		expectedInfo, err := os.Stat(expectedImgPath)
-		if err != nil {
-			t.Logf("Warning: Could not stat expected image %s: %v", imgName, err)
-			continue
-		}
-
-		if actualInfo.Size() != expectedInfo.Size() {
-			t.Errorf("Image %s size mismatch: got %d bytes, want %d bytes",
-				imgName, actualInfo.Size(), expectedInfo.Size())
-		}
-	}
+	// Test 3: Verify the images have the content of the golden bundle (see SHA256SUMS),
+	// so a copy that silently writes the wrong or broken data is found
+	expectedImages := verifyChecksums(t, expectedOutputDir, output.Dir)
 
 	// Test 4: Verify no unexpected files were created
 	entries, err := os.ReadDir(output.Dir)
@@ -105,7 +80,7 @@ func TestConvertLogseqToHugo(t *testing.T) {
 		t.Fatalf("Failed to read output directory: %v", err)
 	}
 
-	expectedFiles := append(expectedImages, expectedFilename)
+	expectedFiles := append(expectedImages, expectedFilename, GeneratedFile)
 	expectedFileMap := make(map[string]bool)
 	for _, f := range expectedFiles {
 		expectedFileMap[f] = true
@@ -416,3 +391,708 @@ func TestConvertLogseqToHugo_MultiplePosts(t *testing.T) {
 		}
 	}
 }
+
+func TestConvertLogseqToHugo_Blockquote(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// Blockquotes must survive extraction instead of being dropped
+	testFile := filepath.Join(tempDir, "test.md")
+	content := []byte(`- [[Blog]]
+	- type:: blog
+	  status:: online
+	  date:: 2026-01-17
+	  title:: Quote Post
+	  author:: test
+	- Intro
+	- > quoted line
+	  > second line
+`)
+	if err := os.WriteFile(testFile, content, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	outputs, err := convertFile(testFile, tempDir)
+	if err != nil {
+		t.Fatalf("convertFile() error = %v", err)
+	}
+
+	actualContent, err := os.ReadFile(filepath.Join(outputs[0].Dir, outputs[0].Filename))
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+
+	if !strings.Contains(string(actualContent), "> quoted line\n> second line") {
+		t.Errorf("Blockquote missing in output:\n%s", actualContent)
+	}
+}
+
+func TestApplyTOC(t *testing.T) {
+	content := "Intro\n\n## One\n\ntext\n\n## Two\n\n```\n# not a heading\n```"
+
+	tests := []struct {
+		name        string
+		toc         string
+		config      TOCConfig
+		wantTOC     string
+		wantContent string
+	}{
+		{"Property enables front matter", "true", TOCConfig{Mode: "front_matter"}, "true", content},
+		{"Disabled by default", "", TOCConfig{Mode: "front_matter"}, "", content},
+		{"Automatic with enough headings", "", TOCConfig{Mode: "front_matter", MinHeadings: 2}, "true", content},
+		{"Automatic ignores code blocks", "", TOCConfig{Mode: "front_matter", MinHeadings: 3}, "", content},
+		{"Property disables automatic", "false", TOCConfig{Mode: "front_matter", MinHeadings: 1}, "false", content},
+		{"Shortcode mode", "true", TOCConfig{Mode: "shortcode", Shortcode: "toc"}, "", "{{< toc >}}\n\n" + content},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.TOC = tt.config
+			meta := BlogMeta{TOC: tt.toc}
+
+			got := NewConverter(config, OSFileSystem{}).applyTOC(&meta, content)
+			if got != tt.wantContent {
+				t.Errorf("applyTOC() content = %q, want %q", got, tt.wantContent)
+			}
+			if meta.TOC != tt.wantTOC {
+				t.Errorf("applyTOC() meta.TOC = %q, want %q", meta.TOC, tt.wantTOC)
+			}
+		})
+	}
+}
+
+func TestConvertGraph_RelatedPosts(t *testing.T) {
+	graphDir := t.TempDir()
+	outputDir := t.TempDir()
+
+	// Two pages sharing a tag and one unrelated page
+	pages := map[string]string{
+		"a.md": "type:: blog\nstatus:: online\ndate:: 2026-01-01\ntitle:: A\nauthor:: me\ntags:: sailing\n\n- Post A\n",
+		"b.md": "type:: blog\nstatus:: online\ndate:: 2026-01-02\ntitle:: B\nauthor:: me\ntags:: [[Sailing]], boats\n\n- Post B\n",
+		"c.md": "type:: blog\nstatus:: online\ndate:: 2026-01-03\ntitle:: C\nauthor:: me\n\n- Post C\n",
+	}
+	if err := os.MkdirAll(filepath.Join(graphDir, "pages"), 0755); err != nil {
+		t.Fatalf("Failed to create pages directory: %v", err)
+	}
+	for name, content := range pages {
+		if err := os.WriteFile(filepath.Join(graphDir, "pages", name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	outputs, err := NewConverter(DefaultConfig(), OSFileSystem{}).ConvertGraph(context.Background(), graphDir, outputDir)
+	if err != nil {
+		t.Fatalf("ConvertGraph() error = %v", err)
+	}
+	if len(outputs) != 3 {
+		t.Fatalf("Expected 3 outputs, got %d", len(outputs))
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "2026-01-01_A", "index.de.md"))
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+	if !strings.Contains(string(content), `related = ["2026-01-02_B"]`) {
+		t.Errorf("Missing related param:\n%s", content)
+	}
+	if !strings.Contains(string(content), `tags = ["sailing"]`) {
+		t.Errorf("Missing tags:\n%s", content)
+	}
+}
+
+// TestConvertGraph_Sections tests writing posts from namespace pages into section directories
+func TestConvertGraph_Sections(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	fsys.WriteFile(filepath.Join("graph", "pages", "blog___trips___Renan.md"), []byte("type:: blog\nstatus:: online\ndate:: 2026-01-17\ntitle:: Renan\n\n- Hiking\n"))
+	fsys.WriteFile(filepath.Join("graph", "pages", "Home.md"), []byte("type:: blog\nstatus:: online\ndate:: 2026-01-18\ntitle:: Home\n\n- See [[blog/trips/Renan]]\n"))
+
+	config := DefaultConfig()
+	config.Sections = SectionsConfig{Enabled: true, Mapping: map[string]string{"blog/trips": "travel"}}
+	if _, err := NewConverter(config, fsys).ConvertGraph(context.Background(), "graph", "out"); err != nil {
+		t.Fatalf("ConvertGraph() error = %v", err)
+	}
+
+	if _, err := readFile(fsys, filepath.Join("out", "travel", "2026-01-17_Renan", "index.de.md")); err != nil {
+		t.Errorf("Namespace page not written to its section: %v", err)
+	}
+	home, err := readFile(fsys, filepath.Join("out", "2026-01-18_Home", "index.de.md"))
+	if err != nil {
+		t.Fatalf("Page without namespace not written to the output directory: %v", err)
+	}
+	if want := `{{< relref "travel/2026-01-17_Renan" >}}`; !strings.Contains(string(home), want) {
+		t.Errorf("Link to the section missing %s:\n%s", want, home)
+	}
+}
+
+// TestConvertGraph_Obsidian tests converting the notes of an Obsidian vault
+func TestConvertGraph_Obsidian(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	fsys.WriteFile(filepath.Join("vault", ".obsidian", "app.json"), []byte(`{"attachmentFolderPath": "attachments"}`))
+	fsys.WriteFile(filepath.Join("vault", "attachments", "beach.jpg"), []byte("jpg"))
+	fsys.WriteFile(filepath.Join("vault", "posts", "Renan.md"), []byte("---\ntype: blog\nstatus: online\ndate: 2026-01-17\ntags:\n  - hiking\n---\nWe walked up.\n\n![[beach.jpg|400]]\n%%not published%%\n"))
+	fsys.WriteFile(filepath.Join("vault", "posts", "Ideas.md"), []byte("Just a note\n"))
+
+	outputs, err := NewConverter(DefaultConfig(), fsys).ConvertGraph(context.Background(), "vault", "out")
+	if err != nil {
+		t.Fatalf("ConvertGraph() error = %v", err)
+	}
+	if len(outputs) != 1 {
+		t.Fatalf("Expected only the marked note, got %d outputs", len(outputs))
+	}
+
+	post, err := readFile(fsys, filepath.Join("out", "2026-01-17_Renan", "index.de.md"))
+	if err != nil {
+		t.Fatalf("Note not written: %v", err)
+	}
+	for _, want := range []string{`title = "Renan"`, `tags = ["hiking"]`, "We walked up.", "![](beach.jpg)"} {
+		if !strings.Contains(string(post), want) {
+			t.Errorf("Post missing %s:\n%s", want, post)
+		}
+	}
+	if strings.Contains(string(post), "not published") {
+		t.Errorf("Comment not removed:\n%s", post)
+	}
+	if _, err := readFile(fsys, filepath.Join("out", "2026-01-17_Renan", "beach.jpg")); err != nil {
+		t.Errorf("Attachment not copied: %v", err)
+	}
+}
+
+// TestConvertGraph_Notion tests converting the pages of a Notion export
+func TestConvertGraph_Notion(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	fsys.WriteFile(filepath.Join("export", "Renan 0123456789abcdef0123456789abcdef.md"), []byte("# Renan\n\nType: blog\nStatus: online\nDate: January 17, 2026\n\nWe walked up.\n\n![Untitled](Renan%200123456789abcdef0123456789abcdef/Untitled.png)\n"))
+	fsys.WriteFile(filepath.Join("export", "Renan 0123456789abcdef0123456789abcdef", "Untitled.png"), []byte("png"))
+
+	if _, err := NewConverter(DefaultConfig(), fsys).ConvertGraph(context.Background(), "export", "out"); err != nil {
+		t.Fatalf("ConvertGraph() error = %v", err)
+	}
+
+	post, err := readFile(fsys, filepath.Join("out", "2026-01-17_Renan", "index.de.md"))
+	if err != nil {
+		t.Fatalf("Page not written: %v", err)
+	}
+	for _, want := range []string{`date = "2026-01-17"`, `title = "Renan"`, "We walked up.", "![Untitled](Untitled.png)"} {
+		if !strings.Contains(string(post), want) {
+			t.Errorf("Post missing %s:\n%s", want, post)
+		}
+	}
+	if _, err := readFile(fsys, filepath.Join("out", "2026-01-17_Renan", "Untitled.png")); err != nil {
+		t.Errorf("Image not copied: %v", err)
+	}
+}
+
+// TestConvertFile_AltText tests writing generated alt text into the bundle
+func TestConvertFile_AltText(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	fsys.WriteFile(filepath.Join("graph", "pages", "Renan.md"), []byte("type:: blog\nstatus:: online\ndate:: 2026-01-17\ntitle:: Renan\nlanguage:: english\n\n- ![image.jpg](../assets/beach.jpg)\n"))
+	fsys.WriteFile(filepath.Join("graph", "assets", "beach.jpg"), []byte("jpg"))
+
+	config := DefaultConfig()
+	config.AltText.Enabled = true
+	converter := NewConverter(config, fsys)
+	converter.altText, _ = NewAltTextGenerator(fsys, "cache.json", func(ctx context.Context, image []byte, language string) (string, error) {
+		return "A beach in " + language, nil
+	})
+	if _, err := converter.ConvertFile(context.Background(), filepath.Join("graph", "pages", "Renan.md"), "out"); err != nil {
+		t.Fatalf("ConvertFile() error = %v", err)
+	}
+
+	post, err := readFile(fsys, filepath.Join("out", "2026-01-17_Renan", "index.en.md"))
+	if err != nil {
+		t.Fatalf("Post not written: %v", err)
+	}
+	if want := "![A beach in english](beach.jpg)"; !strings.Contains(string(post), want) {
+		t.Errorf("Post missing %s:\n%s", want, post)
+	}
+	if _, err := readFile(fsys, "cache.json"); err != nil {
+		t.Errorf("Cache not written: %v", err)
+	}
+}
+
+// TestConvertGraph_GeneratedSummary tests that only posts without a summary get a generated one
+func TestConvertGraph_GeneratedSummary(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	fsys.WriteFile(filepath.Join("graph", "pages", "Renan.md"), []byte("type:: blog\nstatus:: online\ndate:: 2026-01-17\ntitle:: Renan\n\n- We walked up.\n"))
+	fsys.WriteFile(filepath.Join("graph", "pages", "Home.md"), []byte("type:: blog\nstatus:: online\ndate:: 2026-01-18\ntitle:: Home\nsummary:: Written by hand\n\n- Welcome\n"))
+
+	config := DefaultConfig()
+	config.Summary.Generate = true
+	converter := NewConverter(config, fsys)
+	converter.summaries, _ = NewSummaryGenerator(fsys, "cache.json", func(ctx context.Context, title, content, language string) (GeneratedSummary, error) {
+		return GeneratedSummary{Summary: "Generated for " + title, Description: "About " + title}, nil
+	})
+	if _, err := converter.ConvertGraph(context.Background(), "graph", "out"); err != nil {
+		t.Fatalf("ConvertGraph() error = %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{filepath.Join("out", "2026-01-17_Renan", "index.de.md"), []string{`summary = "Generated for Renan"`, `description = "About Renan"`}},
+		{filepath.Join("out", "2026-01-18_Home", "index.de.md"), []string{`summary = "Written by hand"`}},
+	}
+	for _, tt := range tests {
+		post, err := readFile(fsys, tt.path)
+		if err != nil {
+			t.Fatalf("Post not written: %v", err)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(string(post), want) {
+				t.Errorf("%s missing %s:\n%s", tt.path, want, post)
+			}
+		}
+	}
+}
+
+// TestConvertGraph_Proofreading tests reporting unknown words per post and language
+func TestConvertGraph_Proofreading(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	fsys.WriteFile(filepath.Join("graph", "pages", "Renan.md"), []byte("type:: blog\nstatus:: online\ndate:: 2026-01-17\ntitle:: Renan\n\n- Wir sind nach Renan gewandert.\n- Die Wanderug war lang.\n"))
+	fsys.WriteFile(filepath.Join("graph", "pages", "Home.md"), []byte("type:: blog\nstatus:: online\ndate:: 2026-01-18\ntitle:: Home\nlanguage:: english\n\n- Welcome home\n"))
+	fsys.WriteFile(filepath.Join("dict", "de.dic"), []byte("7\nwir\nsind\nnach\ngewandert\ndie\nWanderung\nwar/A\nlang\n"))
+	fsys.WriteFile(filepath.Join("dict", "en.dic"), []byte("welcome\nhome\n"))
+
+	config := DefaultConfig()
+	config.Proofread.Enabled = true
+	config.Proofread.Dictionaries = map[string]string{
+		"german":  filepath.Join("dict", "de.dic"),
+		"english": filepath.Join("dict", "en.dic"),
+	}
+	config.Proofread.Ignore = []string{"Renan"}
+	converter := NewConverter(config, fsys)
+	if _, err := converter.ConvertGraph(context.Background(), "graph", "out"); err != nil {
+		t.Fatalf("ConvertGraph() error = %v", err)
+	}
+
+	// The posts are converted anyway
+	if _, err := readFile(fsys, filepath.Join("out", "2026-01-17_Renan", "index.de.md")); err != nil {
+		t.Errorf("Post with typos not written: %v", err)
+	}
+
+	want := []ProofreadResult{{
+		Title:    "Renan",
+		Language: "german",
+		Issues:   []ProofreadIssue{{Text: "Wanderug", Message: "unknown word"}},
+	}}
+	if !reflect.DeepEqual(converter.stats.Proofreading, want) {
+		t.Errorf("Proofreading = %+v, want %+v", converter.stats.Proofreading, want)
+	}
+	if converter.stats.Proofread["german"] != 1 || converter.stats.Proofread["english"] != 1 {
+		t.Errorf("Proofread = %v, want one post per language", converter.stats.Proofread)
+	}
+}
+
+// TestConvertGraph_Interactive tests that only the posts confirmed in the review are written
+func TestConvertGraph_Interactive(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	for _, name := range []string{"Alpha", "Beta", "Gamma", "Delta"} {
+		fsys.WriteFile(filepath.Join("graph", "pages", name+".md"), []byte("type:: blog\nstatus:: online\ndate:: 2026-01-17\ntitle:: "+name+"\n\n- ![photo](../assets/"+name+".jpg)\n"))
+	}
+
+	converter := NewConverter(DefaultConfig(), fsys)
+	var out bytes.Buffer
+	converter.review = NewPostReviewer(strings.NewReader("c\ne\nBeta Renamed\nc\ns\nq\n"), &out)
+	outputs, err := converter.ConvertGraph(context.Background(), "graph", "out")
+	if err != nil {
+		t.Fatalf("ConvertGraph() error = %v", err)
+	}
+
+	var dirs []string
+	for _, output := range outputs {
+		dirs = append(dirs, filepath.Base(output.Dir))
+	}
+	want := []string{"2026-01-17_Alpha", "2026-01-17_Beta_Renamed"}
+	if !reflect.DeepEqual(dirs, want) {
+		t.Errorf("written bundles = %v, want %v", dirs, want)
+	}
+	if got := converter.stats.Skipped["skipped in review"]; got != 2 {
+		t.Errorf("Skipped in review = %d, want 2", got)
+	}
+	if !strings.Contains(out.String(), "Assets:  Alpha.jpg") {
+		t.Errorf("review doesn't show the assets:\n%s", out.String())
+	}
+}
+
+// TestConvertGraph_PublishLog tests the publish events in the front matter and the history file
+func TestConvertGraph_PublishLog(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	page := filepath.Join("graph", "pages", "Renan.md")
+	fsys.WriteFile(page, []byte("type:: blog\nstatus:: online\ndate:: 2026-01-17\ntitle:: Renan\n\n- Hiking\n"))
+
+	config := DefaultConfig()
+	config.PublishLog.Enabled = true
+	convert := func(day int) string {
+		converter := NewConverter(config, fsys)
+		converter.now = func() time.Time { return time.Date(2026, 1, day, 10, 0, 0, 0, time.UTC) }
+		if _, err := converter.ConvertGraph(context.Background(), "graph", "out"); err != nil {
+			t.Fatalf("ConvertGraph() error = %v", err)
+		}
+		index, _ := readFile(fsys, filepath.Join("out", "2026-01-17_Renan", "index.de.md"))
+		return string(index)
+	}
+
+	first := convert(17)
+	if !strings.Contains(first, "[params.publish]\n  time = \"2026-01-17T10:00:00Z\"\n  source_hash = \"") {
+		t.Errorf("index file has no publish event:\n%s", first)
+	}
+	if again := convert(18); again != first {
+		t.Errorf("converting an unchanged post changed the index file:\n%s\nwant\n%s", again, first)
+	}
+
+	fsys.WriteFile(page, []byte("type:: blog\nstatus:: online\ndate:: 2026-01-17\ntitle:: Renan\n\n- Hiking in the Jura\n"))
+	if changed := convert(19); !strings.Contains(changed, "time = \"2026-01-19T10:00:00Z\"") {
+		t.Errorf("index file of a changed post has the old event:\n%s", changed)
+	}
+
+	events, err := readPublishLog(fsys, filepath.Join("out", "2026-01-17_Renan", DefaultPublishLog))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Time != "2026-01-17T10:00:00Z" || events[1].Time != "2026-01-19T10:00:00Z" || events[0].SourceHash == events[1].SourceHash {
+		t.Errorf("publish log = %+v, want the events of the first and the third conversion", events)
+	}
+}
+
+// TestConvertGraph_Filename tests the configured index file names of blog posts and a content type
+func TestConvertGraph_Filename(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	fsys.WriteFile(filepath.Join("graph", "pages", "Renan.md"), []byte("type:: blog\nstatus:: online\ndate:: 2026-01-17\ntitle:: Renan\nlanguage:: english\n\n- Hiking\n"))
+	fsys.WriteFile(filepath.Join("graph", "pages", "Zopf.md"), []byte("type:: recipe\nstatus:: online\ntitle:: Zopf\n\n- Knead\n"))
+
+	config := DefaultConfig()
+	config.Output.Filename = "{{.Slug}}.{{.Lang}}.md"
+	config.Types = []ContentTypeConfig{{Name: "recipe", Marker: "type:: recipe", Section: "recipes", Filename: "index.md"}}
+	outputs, err := NewConverter(config, fsys).ConvertGraph(context.Background(), "graph", "out")
+	if err != nil {
+		t.Fatalf("ConvertGraph() error = %v", err)
+	}
+	if len(outputs) != 2 {
+		t.Fatalf("Expected the blog post and the recipe, got %d outputs", len(outputs))
+	}
+
+	for _, index := range []string{
+		filepath.Join("out", "2026-01-17_Renan", "2026-01-17_Renan.en.md"),
+		filepath.Join("out", "recipes", "Zopf", "index.md"),
+	} {
+		if _, err := readFile(fsys, index); err != nil {
+			t.Errorf("Index file not written: %v", err)
+		}
+	}
+}
+
+// TestConvertGraph_Monolingual tests writing index.md files for a monolingual site
+func TestConvertGraph_Monolingual(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	fsys.WriteFile(filepath.Join("graph", "pages", "Renan.md"), []byte("type:: blog\nstatus:: online\ndate:: 2026-01-17\ntitle:: Renan\nlanguage:: english\n\n- Hiking\n"))
+
+	config := DefaultConfig()
+	config.Output.Monolingual = true
+	outputs, err := NewConverter(config, fsys).ConvertGraph(context.Background(), "graph", "out")
+	if err != nil {
+		t.Fatalf("ConvertGraph() error = %v", err)
+	}
+	if len(outputs) != 1 || outputs[0].Filename != "index.md" {
+		t.Errorf("ConvertGraph() = %+v, want one index.md file", outputs)
+	}
+	if _, err := readFile(fsys, filepath.Join("out", "2026-01-17_Renan", "index.md")); err != nil {
+		t.Errorf("Index file not written: %v", err)
+	}
+}
+
+// TestConvertGraph_LanguageDetection tests choosing the index file of a post without a language:: property
+func TestConvertGraph_LanguageDetection(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	fsys.WriteFile(filepath.Join("graph", "pages", "Ibiza.md"), []byte("type:: blog\nstatus:: online\ndate:: 2026-02-01\ntitle:: Ibiza\n\n- We sailed to the island and anchored in the bay for the night.\n"))
+
+	config := DefaultConfig()
+	config.Language.Detect = true
+	if _, err := NewConverter(config, fsys).ConvertGraph(context.Background(), "graph", "out"); err != nil {
+		t.Fatalf("ConvertGraph() error = %v", err)
+	}
+	if _, err := readFile(fsys, filepath.Join("out", "2026-02-01_Ibiza", "index.en.md")); err != nil {
+		t.Errorf("English post not written as index.en.md: %v", err)
+	}
+}
+
+// TestConvertGraph_TranslationParams tests writing the opt-outs of the translation tool
+func TestConvertGraph_TranslationParams(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	fsys.WriteFile(filepath.Join("graph", "pages", "Gedicht.md"), []byte("type:: blog\nstatus:: online\ndate:: 2026-01-17\ntitle:: Gedicht\ntranslate:: no\n\n- Reim\n"))
+	fsys.WriteFile(filepath.Join("graph", "pages", "Renan.md"), []byte("type:: blog\nstatus:: online\ndate:: 2026-01-18\ntitle:: Renan\ntranslate-languages:: English, [[fr]], klingon\n\n- Wandern\n"))
+
+	if _, err := NewConverter(DefaultConfig(), fsys).ConvertGraph(context.Background(), "graph", "out"); err != nil {
+		t.Fatalf("ConvertGraph() error = %v", err)
+	}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{filepath.Join("out", "2026-01-17_Gedicht", "index.de.md"), "  translate = false\n"},
+		{filepath.Join("out", "2026-01-18_Renan", "index.de.md"), "  translate_languages = [\"en\", \"fr\"]\n"},
+	}
+	for _, tt := range tests {
+		content, err := readFile(fsys, tt.path)
+		if err != nil {
+			t.Fatalf("Index file not written: %v", err)
+		}
+		if !strings.Contains(string(content), tt.want) {
+			t.Errorf("%s = %q, want it to contain %q", tt.path, content, tt.want)
+		}
+	}
+}
+
+// TestConvertGraph_ContentTypes tests converting blog posts and recipes in one run
+func TestConvertGraph_ContentTypes(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	fsys.WriteFile(filepath.Join("graph", "pages", "Renan.md"), []byte("type:: blog\nstatus:: online\ndate:: 2026-01-17\ntitle:: Renan\n\n- Hiking\n"))
+	fsys.WriteFile(filepath.Join("graph", "pages", "Zopf.md"), []byte("type:: recipe\nstatus:: online\ntitle:: Zopf\nservings:: 4\n\n- Knead\n"))
+	fsys.WriteFile(filepath.Join("graph", "pages", "Brot.md"), []byte("type:: recipe\nstatus:: online\ntitle:: Brot\n\n- Bake\n"))
+
+	config := DefaultConfig()
+	config.Types = []ContentTypeConfig{{
+		Name:       "recipe",
+		Marker:     "type:: recipe",
+		Section:    "recipes",
+		Layout:     "recipe",
+		Properties: []string{"servings"},
+		Required:   []string{"servings"},
+	}}
+	outputs, err := NewConverter(config, fsys).ConvertGraph(context.Background(), "graph", "out")
+	if err != nil {
+		t.Fatalf("ConvertGraph() error = %v", err)
+	}
+	if len(outputs) != 2 {
+		t.Errorf("Expected the blog post and one recipe, got %d outputs", len(outputs))
+	}
+
+	if _, err := readFile(fsys, filepath.Join("out", "2026-01-17_Renan", "index.de.md")); err != nil {
+		t.Errorf("Blog post not written: %v", err)
+	}
+	recipe, err := readFile(fsys, filepath.Join("out", "recipes", "Zopf", "index.de.md"))
+	if err != nil {
+		t.Fatalf("Recipe not written to its section: %v", err)
+	}
+	for _, want := range []string{`layout = "recipe"`, "servings = 4"} {
+		if !strings.Contains(string(recipe), want) {
+			t.Errorf("Recipe front matter missing %s:\n%s", want, recipe)
+		}
+	}
+	if _, err := readFile(fsys, filepath.Join("out", "recipes", "Brot", "index.de.md")); err == nil {
+		t.Error("Recipe without servings:: was written")
+	}
+}
+
+// BenchmarkConvertFile measures converting a large journal file in memory
+func BenchmarkConvertFile(b *testing.B) {
+	source := largeJournal(2000)
+	input := filepath.Join("graph", "journals", "2026_01_01.md")
+	graph := NewMemFileSystem(nil)
+	graph.WriteFile(input, source)
+	for i := 0; i < 2000; i++ {
+		graph.WriteFile(filepath.Join("graph", "assets", fmt.Sprintf("image_%d.png", i)), []byte("png"))
+	}
+	b.SetBytes(int64(len(source)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		// Every run writes into a fresh layer on top of the graph
+		fsys := NewMemFileSystem(graph)
+		if _, err := NewConverter(DefaultConfig(), fsys).ConvertFile(context.Background(), input, "out"); err != nil {
+			b.Fatalf("ConvertFile() error = %v", err)
+		}
+	}
+}
+
+// TestConvertFile_FirstImageAsHeader tests using the first inline image as featured image
+func TestConvertFile_FirstImageAsHeader(t *testing.T) {
+	for _, remove := range []bool{false, true} {
+		t.Run(fmt.Sprintf("remove=%t", remove), func(t *testing.T) {
+			fsys := NewMemFileSystem(nil)
+			input := filepath.Join("graph", "journals", "2026_01_01.md")
+			fsys.WriteFile(input, []byte("- type:: blog\n  status:: online\n  date:: 2026-01-01\n  title:: Photos\n- ![clip](../assets/clip.mp4)\n- Intro\n- ![photo](../assets/photo.png)\n- Text\n"))
+			fsys.WriteFile(filepath.Join("graph", "assets", "photo.png"), []byte("png"))
+			fsys.WriteFile(filepath.Join("graph", "assets", "clip.mp4"), []byte("mp4"))
+
+			config := DefaultConfig()
+			config.Header.FirstImage = true
+			config.Header.RemoveFirstImage = remove
+			if _, err := NewConverter(config, fsys).ConvertFile(context.Background(), input, "out"); err != nil {
+				t.Fatalf("ConvertFile() error = %v", err)
+			}
+
+			bundle := filepath.Join("out", "2026-01-01_Photos")
+			if data, err := readFile(fsys, filepath.Join(bundle, "featured.png")); err != nil || string(data) != "png" {
+				t.Errorf("featured.png = %q, %v; want the first image", data, err)
+			}
+			index, _ := readFile(fsys, filepath.Join(bundle, "index.de.md"))
+			if inline := strings.Contains(string(index), "![photo](photo.png)"); inline == remove {
+				t.Errorf("Image inline = %t, want %t:\n%s", inline, !remove, index)
+			}
+		})
+	}
+}
+
+// TestConvertFile_DateFormats tests the configured date layouts of the front matter and the bundle directory
+func TestConvertFile_DateFormats(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	input := filepath.Join("graph", "journals", "2026_01_17.md")
+	fsys.WriteFile(input, []byte("- type:: blog\n  status:: online\n  date:: 2026-01-17\n  title:: Renan\n- Text\n"))
+
+	config := DefaultConfig()
+	config.Dates = DatesConfig{Layout: "2006-01-02T15:04:05-07:00", FolderLayout: "2006-01", TimeZone: "Europe/Zurich", Time: "08:00"}
+	if _, err := NewConverter(config, fsys).ConvertFile(context.Background(), input, "out"); err != nil {
+		t.Fatalf("ConvertFile() error = %v", err)
+	}
+
+	index, err := readFile(fsys, filepath.Join("out", "2026-01_Renan", "index.de.md"))
+	if err != nil {
+		t.Fatalf("Bundle not written to the formatted folder: %v", err)
+	}
+	for _, want := range []string{`date = "2026-01-17T08:00:00+01:00"`, `lastmod = "2026-01-17T08:00:00+01:00"`} {
+		if !strings.Contains(string(index), want) {
+			t.Errorf("Front matter missing %s:\n%s", want, index)
+		}
+	}
+}
+
+// TestApplySocial tests choosing the social preview image
+func TestApplySocial(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		config     HeaderConfig
+		wantImages []string
+		wantCard   string
+	}{
+		{"No header image", "", HeaderConfig{}, nil, "summary"},
+		{"Featured image", "../assets/photo.png", HeaderConfig{}, []string{"featured.png"}, "summary_large_image"},
+		{"Resized featured image", "../assets/photo.png", HeaderConfig{FeaturedSize: "1600x900"}, []string{"featured.jpeg"}, "summary_large_image"},
+		{"OpenGraph image", "../assets/photo.png", HeaderConfig{OpenGraphSize: "1200x630"}, []string{"og-image.jpeg"}, "summary_large_image"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Header = tt.config
+			config.Social = SocialConfig{Enabled: true, TwitterSite: "@example"}
+			meta := BlogMeta{Title: "T", Summary: "S", Header: tt.header}
+
+			NewConverter(config, OSFileSystem{}).applySocial(&meta)
+
+			if strings.Join(meta.Images, ",") != strings.Join(tt.wantImages, ",") {
+				t.Errorf("Images = %v, want %v", meta.Images, tt.wantImages)
+			}
+			if meta.Social.Card != tt.wantCard || meta.Social.Title != "T" || meta.Social.Description != "S" || meta.Social.Site != "@example" {
+				t.Errorf("Unexpected social metadata: %+v", meta.Social)
+			}
+		})
+	}
+}
+
+// TestApplySocial_BundleImages tests that the preview image goes before the other images of the bundle
+func TestApplySocial_BundleImages(t *testing.T) {
+	config := DefaultConfig()
+	config.Header = HeaderConfig{OpenGraphSize: "1200x630"}
+	config.Social = SocialConfig{Enabled: true}
+	meta := BlogMeta{Title: "T", Header: "../assets/photo.png", Images: []string{"featured.png", "boot.jpg"}}
+
+	NewConverter(config, OSFileSystem{}).applySocial(&meta)
+
+	want := []string{"og-image.jpeg", "featured.png", "boot.jpg"}
+	if strings.Join(meta.Images, ",") != strings.Join(want, ",") {
+		t.Errorf("Images = %v, want %v", meta.Images, want)
+	}
+}
+
+// TestBooleanParams tests mapping boolean properties to param names
+func TestBooleanParams(t *testing.T) {
+	mapping := map[string]string{"comments": "comments", "share": "ShowShareButtons", "math": ""}
+
+	tests := []struct {
+		name       string
+		properties map[string]string
+		want       map[string]bool
+	}{
+		{"No properties", nil, nil},
+		{"Same name", map[string]string{"comments": "false"}, map[string]bool{"comments": false}},
+		{"Renamed", map[string]string{"share": "Yes"}, map[string]bool{"ShowShareButtons": true}},
+		{"Unmapped or disabled", map[string]string{"math": "true", "pinned": "true"}, nil},
+		{"No boolean", map[string]string{"comments": "maybe"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := booleanParams(tt.properties, mapping); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("booleanParams() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestIsExpired tests comparing expiry dates with the current time
+func TestIsExpired(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		expiry string
+		want   bool
+	}{
+		{"", false},
+		{"2026-03-11", false},
+		{"2026-03-10", true},
+		{"2026-03-10 13:00", false},
+		{"2026-03-10T11:00", true},
+		{"2026-03-09T23:00:00+01:00", true},
+		{"next week", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expiry, func(t *testing.T) {
+			if got := isExpired(BlogMeta{ExpiryDate: tt.expiry}, now); got != tt.want {
+				t.Errorf("isExpired(%q) = %v, want %v", tt.expiry, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSortPosts tests the configurable order of the posts
+func TestSortPosts(t *testing.T) {
+	newPosts := func() []*BlogPost {
+		return []*BlogPost{
+			{Meta: BlogMeta{Date: "2026-02-01", Title: "beta"}},
+			{Meta: BlogMeta{Date: "2026-01-01", Title: "Zeta"}},
+			{Meta: BlogMeta{Date: "2026-02-01", Title: "Alpha"}},
+		}
+	}
+
+	tests := []struct {
+		order string
+		want  []string
+	}{
+		{OrderDate, []string{"Zeta", "Alpha", "beta"}},
+		{OrderTitle, []string{"Alpha", "beta", "Zeta"}},
+		{OrderSource, []string{"beta", "Zeta", "Alpha"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			posts := newPosts()
+			if err := sortPosts(posts, tt.order); err != nil {
+				t.Fatalf("sortPosts() error = %v", err)
+			}
+			var got []string
+			for _, post := range posts {
+				got = append(got, post.Meta.Title)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sortPosts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if err := sortPosts(newPosts(), "random"); err == nil {
+		t.Errorf("sortPosts() with an unknown order should fail")
+	}
+}
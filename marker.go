@@ -0,0 +1,84 @@
+// This file handles the marker that selects the Logseq blocks that are blog posts.
+// The default is the property "type:: blog"; other graphs use another property
+// ("publish:: blog") or a tag ("#blog").
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// DefaultMarker is the marker of blog posts if none is configured.
+const DefaultMarker = "type:: blog"
+
+// BlogMarker matches the lines that mark a block as a blog post.
+type BlogMarker struct {
+	text  string         // The marker as configured (for messages)
+	key   string         // Normalized property key ("tags" for tag markers)
+	value string         // Property value or tag (lowercase)
+	tag   *regexp.Regexp // Matches the tag in text (nil for property markers)
+}
+
+// NewBlogMarker creates a BlogMarker from a property ("type:: blog") or a tag ("#blog").
+// Property values and tags are compared case-insensitively, like Logseq page names.
+func NewBlogMarker(marker string) (*BlogMarker, error) {
+	marker = strings.TrimSpace(marker)
+	if tag, ok := strings.CutPrefix(marker, "#"); ok {
+		tag = strings.TrimSuffix(strings.TrimPrefix(tag, "[["), "]]")
+		if strings.TrimSpace(tag) == "" {
+			return nil, fmt.Errorf("invalid blog marker %q: empty tag", marker)
+		}
+		quoted := regexp.QuoteMeta(tag)
+		return &BlogMarker{
+			text:  marker,
+			key:   "tags",
+			value: strings.ToLower(tag),
+			// #blog and #[[blog]] as a whole word (#blogroll is another tag)
+			tag: regexp.MustCompile(`(?i)(^|\s)#(` + quoted + `|\[\[` + quoted + `\]\])($|[\s,.;:!?])`),
+		}, nil
+	}
+
+	key, value, ok := strings.Cut(marker, "::")
+	key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+	if !ok || !blockPropertyRegex.MatchString(key+":: "+value) || value == "" {
+		return nil, fmt.Errorf("invalid blog marker %q (use a property like %q or a tag like %q)", marker, DefaultMarker, "#blog")
+	}
+	return &BlogMarker{
+		text:  marker,
+		key:   normalizeKey(key),
+		value: strings.ToLower(strings.Trim(value, "[]#")),
+	}, nil
+}
+
+// String returns the marker as configured.
+func (m *BlogMarker) String() string {
+	return m.text
+}
+
+// Matches checks if a line marks its block as a blog post: the marker property
+// has the marker value (also in a list like "type:: [[blog]], note"),
+// or the marker tag is in the line or in its "tags::" property.
+func (m *BlogMarker) Matches(line string) bool {
+	if m.tag != nil && m.tag.MatchString(line) {
+		return true
+	}
+	match := blockPropertyRegex.FindStringSubmatch(line)
+	if match == nil || normalizeKey(match[1]) != m.key {
+		return false
+	}
+	return slices.ContainsFunc(parseTagList(match[2]), func(value string) bool {
+		return strings.EqualFold(value, m.value)
+	})
+}
+
+// matchesBytes is Matches for a raw source line, with a quick check that avoids
+// matching most lines of large journals.
+func (m *BlogMarker) matchesBytes(line []byte) bool {
+	if !bytes.Contains(line, propertySeparator) && (m.tag == nil || !bytes.Contains(line, []byte("#"))) {
+		return false
+	}
+	return m.Matches(strings.TrimRight(string(line), "\r\n"))
+}
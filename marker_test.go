@@ -0,0 +1,75 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/text"
+)
+
+// TestBlogMarker tests matching property and tag markers
+func TestBlogMarker(t *testing.T) {
+	tests := []struct {
+		marker string
+		line   string
+		want   bool
+	}{
+		{"type:: blog", "type:: blog", true},
+		{"type:: blog", "  Type:: [[Blog]]", true},
+		{"type:: blog", "type:: note, blog", true},
+		{"type:: blog", "type:: blogroll", false},
+		{"type:: blog", "subtype:: blog", false},
+		{"type:: blog", "What is type:: blog?", false},
+		{"publish:: blog", "publish:: blog", true},
+		{"publish:: blog", "type:: blog", false},
+		{"#blog", "A trip to Renan #blog", true},
+		{"#blog", "#[[Blog]]", true},
+		{"#blog", "tags:: sailing, [[blog]]", true},
+		{"#blog", "#blogroll", false},
+		{"#blog", "type:: blog", false},
+		{"#[[my blog]]", "Renan #[[My Blog]]", true},
+	}
+
+	for _, tt := range tests {
+		marker, err := NewBlogMarker(tt.marker)
+		if err != nil {
+			t.Fatalf("NewBlogMarker(%q) error = %v", tt.marker, err)
+		}
+		if got := marker.Matches(tt.line); got != tt.want {
+			t.Errorf("%q.Matches(%q) = %t, want %t", tt.marker, tt.line, got, tt.want)
+		}
+	}
+}
+
+// TestNewBlogMarkerErrors tests rejecting markers that are neither a property nor a tag
+func TestNewBlogMarkerErrors(t *testing.T) {
+	for _, marker := range []string{"", "blog", "#", "type::", ":: blog"} {
+		if _, err := NewBlogMarker(marker); err == nil {
+			t.Errorf("NewBlogMarker(%q) error = nil, want an error", marker)
+		}
+	}
+}
+
+// TestExtractBlogPosts_Markers tests extracting list and page posts with other markers
+func TestExtractBlogPosts_Markers(t *testing.T) {
+	tests := []struct {
+		marker string
+		source string
+	}{
+		{"publish:: blog", "- Morning\n- Blog\n\t- publish:: blog\n\t  title:: Renan\n\t- Content\n"},
+		{"#blog", "- [[Sailing]]\n\t- Renan #blog\n\t  title:: Renan\n\t- Content\n"},
+		{"#blog", "tags:: blog\ntitle:: Renan\n\n- Content\n"},
+	}
+
+	for _, tt := range tests {
+		marker, err := NewBlogMarker(tt.marker)
+		if err != nil {
+			t.Fatalf("NewBlogMarker(%q) error = %v", tt.marker, err)
+		}
+		source := []byte(tt.source)
+		posts, issues := extractBlogPosts(goldmark.New().Parser().Parse(text.NewReader(source)), source, marker)
+		if len(posts) != 1 || posts[0].Meta.Title != "Renan" || len(issues) != 0 {
+			t.Errorf("marker %q: got %d posts and issues %v, want the post 'Renan'", tt.marker, len(posts), issues)
+		}
+	}
+}
@@ -3,6 +3,7 @@
 package main
 
 import (
+	"fmt"     // Printing warnings
 	"regexp"  // Regular expressions package for pattern matching
 	"strings" // String manipulation functions
 )
@@ -21,23 +22,37 @@ func NewMetadataParser() *MetadataParser {
 	// The & operator gets the memory address (pointer) of the struct
 	return &MetadataParser{
 		// Compile the regex pattern once for better performance
-		// Pattern: (\w+)::\s*(.*)
-		//   (\w+) = capture one or more word characters (the key)
+		// Pattern: ([\p{L}\p{N}_][\p{L}\p{N}_-]*)::\s*(.*)
+		//   ([\p{L}\p{N}_][\p{L}\p{N}_-]*) = capture the key: letters (any language),
+		//                                    digits, "_" and "-" (e.g., "cover-image", "légende")
 		//   ::    = literal double colons
 		//   \s*   = zero or more whitespace characters
 		//   (.*) = capture everything else (the value)
-		regex: regexp.MustCompile(`(\w+)::\s*(.*)`),
+		regex: regexp.MustCompile(`([\p{L}\p{N}_][\p{L}\p{N}_-]*)::\s*(.*)`),
 	}
 }
 
 // Parse extracts metadata from an array of lines and returns a BlogMeta struct.
 // The receiver (p *MetadataParser) means this is a method on MetadataParser.
 // The * makes it a pointer receiver, so we work with the original, not a copy.
+// A line without "::" after a property continues that property's value
+// (long summaries wrap onto several lines), an empty line ends it.
 func (p *MetadataParser) Parse(lines []string) BlogMeta {
 	// Create an empty BlogMeta struct to fill with parsed data
 	// := is short variable declaration (type is inferred)
 	meta := BlogMeta{}
 
+	// The property that is currently read (it may continue on the next lines)
+	key, value := "", ""
+
+	// flush sets the field of the current property once its value is complete
+	flush := func() {
+		if key != "" {
+			p.setField(&meta, key, value) // &meta passes a pointer to meta
+		}
+		key, value = "", ""
+	}
+
 	// Loop through each line in the input slice
 	// range returns index and value for each element
 	// _ (underscore) discards the index since we don't need it
@@ -47,18 +62,57 @@ func (p *MetadataParser) Parse(lines []string) BlogMeta {
 		// match[0] = entire match, match[1] = first capture group, etc.
 		if match := p.regex.FindStringSubmatch(line); match != nil {
 			// nil means no match; if not nil, we found metadata
-			key := match[1]                      // First capture group (the key)
-			value := strings.TrimSpace(match[2]) // Second capture group (the value), trimmed
+			flush()
+			key = normalizeKey(match[1])        // First capture group (the key), normalized
+			value = strings.TrimSpace(match[2]) // Second capture group (the value), trimmed
+			continue
+		}
 
-			// Set the appropriate field in the meta struct
-			p.setField(&meta, key, value) // &meta passes a pointer to meta
+		// Continuation lines are joined with a space, an empty line ends the property
+		if line = strings.TrimSpace(line); line == "" {
+			flush()
+		} else if key != "" {
+			value = strings.TrimSpace(value + " " + line)
 		}
 	}
+	flush()
 
 	// Return the completed metadata struct
 	return meta
 }
 
+// metadataAliases maps alternative property names to the names used by setField.
+// Keys are normalized (see normalizeKey) before they are looked up.
+var metadataAliases = map[string]string{
+	"cover":          "header",
+	"cover-image":    "header",
+	"header-image":   "header",
+	"featured-image": "header",
+	"description":    "summary",
+	"expiry-date":    "expirydate",
+	"expires":        "expirydate",
+	"lang":           "language",
+	"place":          "location",
+	"ort":            "location", // German
+	"tag":            "tags",
+	"titel":          "title",  // German
+	"titre":          "title",  // French
+	"autor":          "author", // German
+	"auteur":         "author", // French
+	"datum":          "date",   // German
+}
+
+// normalizeKey converts a property key to the form used by setField.
+// Keys are case-insensitive and "_" is the same as "-" (Logseq writes "cover-image",
+// other tools write "cover_image"). Aliases like "cover" are mapped to their field name.
+func normalizeKey(key string) string {
+	key = strings.ReplaceAll(strings.ToLower(key), "_", "-")
+	if alias, ok := metadataAliases[key]; ok {
+		return alias
+	}
+	return key
+}
+
 // setField sets a specific field in the BlogMeta struct based on the key name.
 // This is a private method (lowercase first letter) only used internally.
 // Parameters:
@@ -76,6 +130,8 @@ func (p *MetadataParser) setField(meta *BlogMeta, key, value string) {
 		meta.Title = value // Set the Title field
 	case "author":
 		meta.Author = value // Set the Author field
+	case "summary":
+		meta.Summary = value // Set the Summary field (otherwise the first paragraph is used)
 	case "header":
 		// Header contains image syntax, extract just the path
 		meta.Header = extractPath(value)
@@ -83,8 +139,39 @@ func (p *MetadataParser) setField(meta *BlogMeta, key, value string) {
 		meta.Status = value // Set the Status field (e.g., "online")
 	case "language":
 		meta.Language = value // Set the Language field (e.g., "german", "english")
-		// If the key doesn't match any case, do nothing (ignore it)
+	case "tags":
+		meta.Tags = parseTagList(value) // Set the Tags field from a comma separated list
+	case "toc":
+		// Set the TOC field ("true" or "false"), other values leave it to the configuration
+		if toc, ok := parseBool(value); ok {
+			meta.TOC = fmt.Sprint(toc)
+		} else {
+			fmt.Printf("Warning: toc:: must be true or false, not '%s'\n", value)
+		}
+	case "location":
+		meta.Location = parseLocation(value) // Set the Location field from coordinates or a place name
+	case "expirydate":
+		meta.ExpiryDate = value // Set the ExpiryDate field (Hugo hides the post after it)
+	default:
+		// Other properties like "comments:: false" are kept,
+		// the converter decides which of them are used
+		if meta.Properties == nil {
+			meta.Properties = make(map[string]string)
+		}
+		meta.Properties[key] = value
+	}
+}
+
+// parseBool parses a boolean property value ("true"/"false", "yes"/"no", case-insensitive).
+// The second return value is false if the value is no boolean.
+func parseBool(value string) (bool, bool) {
+	switch strings.ToLower(value) {
+	case "true", "yes":
+		return true, true
+	case "false", "no":
+		return false, true
 	}
+	return false, false
 }
 
 // extractPath extracts a file path from markdown image syntax.
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestMetadataParser_Keys tests parsing property keys with hyphens, unicode and aliases
+func TestMetadataParser_Keys(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  BlogMeta
+	}{
+		{
+			name:  "Plain keys",
+			lines: []string{"title:: Hello", "date:: 2026-01-01"},
+			want:  BlogMeta{Title: "Hello", Date: "2026-01-01"},
+		},
+		{
+			name:  "Keys are case-insensitive",
+			lines: []string{"Title:: Hello", "STATUS:: online"},
+			want:  BlogMeta{Title: "Hello", Status: "online"},
+		},
+		{
+			name:  "Hyphenated alias",
+			lines: []string{"cover-image:: ![cover](../assets/cover.jpg)"},
+			want:  BlogMeta{Header: "../assets/cover.jpg"},
+		},
+		{
+			name:  "Underscore is the same as hyphen",
+			lines: []string{"cover_image:: ../assets/cover.jpg"},
+			want:  BlogMeta{Header: "../assets/cover.jpg"},
+		},
+		{
+			name:  "Short alias",
+			lines: []string{"cover:: ../assets/cover.jpg", "lang:: english"},
+			want:  BlogMeta{Header: "../assets/cover.jpg", Language: "english"},
+		},
+		{
+			name:  "Description is the summary",
+			lines: []string{"description:: A short text"},
+			want:  BlogMeta{Summary: "A short text"},
+		},
+		{
+			name:  "Localized aliases",
+			lines: []string{"titre:: Bonjour", "autor:: Benno"},
+			want:  BlogMeta{Title: "Bonjour", Author: "Benno"},
+		},
+		{
+			name:  "Expiry date alias",
+			lines: []string{"expires:: 2026-06-30"},
+			want:  BlogMeta{ExpiryDate: "2026-06-30"},
+		},
+		{
+			name:  "Location",
+			lines: []string{"ort:: [[Zürich]]"},
+			want:  BlogMeta{Location: &LocationMeta{Name: "Zürich"}},
+		},
+		{
+			name:  "Table of contents",
+			lines: []string{"toc:: Yes"},
+			want:  BlogMeta{TOC: "true"},
+		},
+		{
+			name:  "No table of contents",
+			lines: []string{"toc:: no"},
+			want:  BlogMeta{TOC: "false"},
+		},
+		{
+			name:  "Invalid table of contents is left unset",
+			lines: []string{"toc:: maybe"},
+			want:  BlogMeta{},
+		},
+		{
+			name:  "Other properties are kept",
+			lines: []string{"comments:: false", "Share:: Yes", "rain_fall:: 12 mm"},
+			want:  BlogMeta{Properties: map[string]string{"comments": "false", "share": "Yes", "rain-fall": "12 mm"}},
+		},
+		{
+			name:  "Unknown unicode key is kept, not cut",
+			lines: []string{"légende-title:: Nope"},
+			want:  BlogMeta{Properties: map[string]string{"légende-title": "Nope"}},
+		},
+	}
+
+	parser := NewMetadataParser()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parser.Parse(tt.lines); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMetadataParser_MultiLineValues tests property values that continue on the next lines
+func TestMetadataParser_MultiLineValues(t *testing.T) {
+	lines := []string{
+		"title:: A long",
+		"  title on two lines",
+		"summary:: First line",
+		"second line",
+		"",
+		"this line belongs to no property",
+		"tags:: sailing,",
+		"boats",
+	}
+
+	got := NewMetadataParser().Parse(lines)
+	want := BlogMeta{
+		Title:   "A long title on two lines",
+		Summary: "First line second line",
+		Tags:    []string{"sailing", "boats"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %+v, want %+v", got, want)
+	}
+}
+
+// FuzzMetadataParser tests that any property lines can be parsed
+// and that the values never keep surrounding whitespace
+func FuzzMetadataParser(f *testing.F) {
+	f.Add("title:: Hello\ndate:: 2026-01-01\nsummary:: A long\n  wrapped text")
+	f.Add("Cover_Image:: ![a](../assets/a.png)\nlégende:: x\nlocation:: 47.37, 8.54")
+	f.Add("tags:: [[A]], #b, ,\n\n:: no key\nkey::")
+
+	parser := NewMetadataParser()
+	f.Fuzz(func(t *testing.T, input string) {
+		meta := parser.Parse(strings.Split(input, "\n"))
+		for _, value := range []string{meta.Title, meta.Date, meta.Author, meta.Summary, meta.Status} {
+			if value != strings.TrimSpace(value) {
+				t.Errorf("Value %q keeps surrounding whitespace", value)
+			}
+		}
+		for _, tag := range meta.Tags {
+			if tag == "" {
+				t.Errorf("Empty tag in %q", meta.Tags)
+			}
+		}
+	})
+}
@@ -0,0 +1,220 @@
+// This file handles Notion markdown exports as input. Notion writes a page as
+// "Title <id>.md" with the page properties below the title, and the page's
+// images into a folder of the same name. A page is a blog post if one of its
+// properties is the blog marker ("Type: blog").
+package main
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// notionIDRegex matches the id Notion appends to the names of pages and their folders.
+var notionIDRegex = regexp.MustCompile(`\s+[0-9a-f]{32}$`)
+
+// notionPropertyRegex matches a property line below the title ("Status: online").
+var notionPropertyRegex = regexp.MustCompile(`^([^:\s][^:]{0,49}):\s+(.+)$`)
+
+// notionLinkRegex matches a link to another page of the export:
+// [Renan](Trips%20a1b2...%2FRenan%20c3d4....md)
+var notionLinkRegex = regexp.MustCompile(`\[([^\]]*)\]\(([^)\s]*[0-9a-f]{32}\.md)\)`)
+
+// notionImageRegex matches the images of a page (usually in the page's folder).
+var notionImageRegex = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)\)`)
+
+// notionDateLayouts are the date formats of Notion's date properties.
+var notionDateLayouts = []string{"January 2, 2006 3:04 PM", "January 2, 2006"}
+
+// notionExport extracts the blog posts of the pages of a Notion export.
+type notionExport struct{}
+
+// isNotionExport checks if the markdown files below dir are named like Notion pages.
+// Only the first markdown file is checked, so large Logseq graphs aren't scanned twice.
+func isNotionExport(fsys FileSystem, dir string) bool {
+	notion := false
+	walkDir(fsys, dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || !strings.EqualFold(filepath.Ext(path), ".md") {
+			return nil
+		}
+		notion = notionIDRegex.MatchString(strings.TrimSuffix(entry.Name(), filepath.Ext(path)))
+		return filepath.SkipAll
+	})
+	return notion
+}
+
+// Extract extracts the blog post of a Notion page.
+// The properties are the lines after the title, up to the first blank line.
+func (notionExport) Extract(pagePath string, source []byte, marker *BlogMarker) ([]*BlogPost, []ExtractionIssue) {
+	lines := strings.Split(strings.ReplaceAll(string(source), "\r\n", "\n"), "\n")
+	i := skipBlankLines(lines, 0)
+
+	title := ""
+	if i < len(lines) && strings.HasPrefix(lines[i], "# ") {
+		title = strings.TrimSpace(strings.TrimPrefix(lines[i], "# "))
+		i = skipBlankLines(lines, i+1)
+	}
+
+	// The paragraph after the title holds the properties if all of its lines are properties
+	end := i
+	for end < len(lines) && strings.TrimSpace(lines[end]) != "" {
+		end++
+	}
+	var properties []string
+	for _, line := range lines[i:end] {
+		match := notionPropertyRegex.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			properties = nil
+			break
+		}
+		properties = append(properties, strings.Join(strings.Fields(match[1]), "-")+":: "+match[2])
+	}
+	if properties != nil {
+		i = end
+	}
+
+	found := false
+	for _, line := range properties {
+		if marker.Matches(line) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, nil
+	}
+
+	post := &BlogPost{
+		Meta: NewMetadataParser().Parse(properties),
+		Kind: KindArticle,
+	}
+	if post.Meta.Title == "" {
+		post.Meta.Title = title
+	}
+	if post.Meta.Title == "" {
+		post.Meta.Title = notionPageName(pagePath)
+	}
+	post.Meta.Date = notionDate(post.Meta.Date)
+	post.Meta.ExpiryDate = notionDate(post.Meta.ExpiryDate)
+
+	body := strings.Join(lines[i:], "\n")
+	body = notionLinkRegex.ReplaceAllStringFunc(body, func(match string) string {
+		target := notionLinkRegex.FindStringSubmatch(match)[2]
+		if unescaped, err := url.PathUnescape(target); err == nil {
+			target = unescaped
+		}
+		return "[[" + notionPageName(target) + "]]"
+	})
+	body = notionImageRegex.ReplaceAllStringFunc(body, func(match string) string {
+		parts := notionImageRegex.FindStringSubmatch(match)
+		ref := parts[2]
+		if strings.Contains(ref, "://") || strings.HasPrefix(ref, ".") || strings.HasPrefix(ref, "/") {
+			return match
+		}
+		if unescaped, err := url.PathUnescape(ref); err == nil {
+			ref = unescaped
+		}
+		return "![" + parts[1] + "](./" + ref + ")"
+	})
+
+	for _, text := range splitParagraphs(body) {
+		post.Content = append(post.Content, newContentBlock(text))
+	}
+	useFirstParagraph(post)
+	return []*BlogPost{post}, nil
+}
+
+// AssetRegex matches the images relative to the page (in the page's folder).
+func (notionExport) AssetRegex() *regexp.Regexp {
+	return relativeImageRegex
+}
+
+// skipBlankLines returns the index of the first non-blank line from i on.
+func skipBlankLines(lines []string, i int) int {
+	for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+		i++
+	}
+	return i
+}
+
+// notionPageName returns the page name of a Notion file without the id
+// ("Trips/Renan 1a2b....md" -> "Renan").
+func notionPageName(file string) string {
+	name := path.Base(filepath.ToSlash(file))
+	name = strings.TrimSuffix(name, path.Ext(name))
+	return notionIDRegex.ReplaceAllString(name, "")
+}
+
+// notionDate converts a Notion date ("January 20, 2025", also the start of a
+// range "January 20, 2025 → January 22, 2025") to the date format of Logseq properties.
+// Other dates are kept as they are.
+func notionDate(value string) string {
+	start, _, _ := strings.Cut(value, " → ")
+	for _, layout := range notionDateLayouts {
+		if date, err := time.Parse(layout, strings.TrimSpace(start)); err == nil {
+			return date.Format("2006-01-02")
+		}
+	}
+	return value
+}
+
+// unzipExport unpacks a Notion export ZIP into a temporary directory,
+// which is converted like a graph directory. The returned cleanup function removes it.
+func unzipExport(zipPath string) (string, func(), error) {
+	archive, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("opening export: %w", err)
+	}
+	defer archive.Close()
+
+	dir, err := os.MkdirTemp("", "notion-export-")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	for _, file := range archive.File {
+		if err := unzipFile(file, dir); err != nil {
+			cleanup()
+			return "", nil, fmt.Errorf("unpacking export: %w", err)
+		}
+	}
+	return dir, cleanup, nil
+}
+
+// unzipFile writes a file of the export into dir, refusing paths outside of dir.
+func unzipFile(file *zip.File, dir string) error {
+	target := filepath.Join(dir, filepath.FromSlash(file.Name))
+	if !isInsideDir(dir, target) {
+		return fmt.Errorf("%s is outside of the export", file.Name)
+	}
+	if file.FileInfo().IsDir() {
+		return os.MkdirAll(target, 0755)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	in, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestNotionExtract tests reading the title, properties and content of a Notion page
+func TestNotionExtract(t *testing.T) {
+	source := []byte("# Renan\n\nType: blog\nStatus: online\nPublish Date: January 17, 2026\nTags: hiking, alps\n\nWe walked to [Other Trip](Trips%2000112233445566778899aabbccddeeff/Other%20Trip%200123456789abcdef0123456789abcdef.md).\n\n![Untitled](Renan%20fedcba9876543210fedcba9876543210/Untitled.png)\n")
+	posts, issues := notionExport{}.Extract(filepath.Join("export", "Renan fedcba9876543210fedcba9876543210.md"), source, testMarker)
+	if len(posts) != 1 || len(issues) != 0 {
+		t.Fatalf("Extract() = %d posts, %v; want 1 post", len(posts), issues)
+	}
+
+	post := posts[0]
+	if post.Meta.Title != "Renan" || post.Meta.Status != "online" || !reflect.DeepEqual(post.Meta.Tags, []string{"hiking", "alps"}) {
+		t.Errorf("Unexpected metadata: %+v", post.Meta)
+	}
+	if got := post.Meta.Properties["publish-date"]; got != "January 17, 2026" {
+		t.Errorf("Properties[publish-date] = %q, want the property as written", got)
+	}
+	want := []string{
+		"We walked to [[Other Trip]].",
+		"![Untitled](./Renan fedcba9876543210fedcba9876543210/Untitled.png)",
+	}
+	if len(post.Content) != len(want) {
+		t.Fatalf("Content = %+v, want %q", post.Content, want)
+	}
+	for i, block := range post.Content {
+		if block.Text != want[i] {
+			t.Errorf("Content[%d] = %q, want %q", i, block.Text, want[i])
+		}
+	}
+
+	// Pages without the marker and pages whose first paragraph isn't properties aren't posts
+	for _, source := range []string{"# Notes\n\nStatus: online\n", "# Notes\n\nType: blog\nJust text\n"} {
+		if posts, _ := (notionExport{}).Extract("Notes.md", []byte(source), testMarker); len(posts) != 0 {
+			t.Errorf("Extract(%q) found a post", source)
+		}
+	}
+}
+
+// TestNotionDate tests converting Notion dates
+func TestNotionDate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"January 17, 2026", "2026-01-17"},
+		{"January 17, 2026 3:04 PM", "2026-01-17"},
+		{"January 17, 2026 → January 19, 2026", "2026-01-17"},
+		{"2026-01-17", "2026-01-17"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := notionDate(tt.value); got != tt.want {
+			t.Errorf("notionDate(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+// TestUnzipExport tests unpacking an export and refusing paths outside of it
+func TestUnzipExport(t *testing.T) {
+	writeZip := func(names ...string) string {
+		path := filepath.Join(t.TempDir(), "export.zip")
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		archive := zip.NewWriter(f)
+		for _, name := range names {
+			w, _ := archive.Create(name)
+			w.Write([]byte("# " + name))
+		}
+		archive.Close()
+		f.Close()
+		return path
+	}
+
+	dir, cleanup, err := unzipExport(writeZip("Renan 0123456789abcdef0123456789abcdef.md", "Renan 0123456789abcdef0123456789abcdef/Untitled.png"))
+	if err != nil {
+		t.Fatalf("unzipExport() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Renan 0123456789abcdef0123456789abcdef", "Untitled.png")); err != nil {
+		t.Errorf("Image not unpacked: %v", err)
+	}
+	if !isNotionExport(OSFileSystem{}, dir) {
+		t.Errorf("isNotionExport() = false for the unpacked export")
+	}
+	cleanup()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Export directory not removed: %v", err)
+	}
+
+	if _, _, err := unzipExport(writeZip("../evil.md")); err == nil {
+		t.Errorf("unzipExport() accepted a path outside of the export")
+	}
+}
@@ -0,0 +1,354 @@
+// This file handles Obsidian vaults as input. A note is a blog post if its
+// YAML front matter has the blog marker ("type: blog"); the front matter is
+// read like Logseq properties, so aliases, content types and the Hugo writer
+// work the same for both.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// obsidianConfigDir is the directory that makes a folder an Obsidian vault.
+const obsidianConfigDir = ".obsidian"
+
+// obsidianVault is an Obsidian vault whose notes are converted instead of Logseq pages.
+type obsidianVault struct {
+	root        string              // Vault directory (containing .obsidian)
+	attachments string              // Attachment folder ("" = vault root, "./..." = below the note)
+	files       map[string][]string // Lowercase file names -> paths in the vault (for embeds)
+}
+
+// embedRegex matches an Obsidian embed: ![[photo.png]], ![[photo.png|300]] or ![[Note#Heading]].
+var embedRegex = regexp.MustCompile(`!\[\[([^\]|#]+)(?:#[^\]|]*)?(?:\|([^\]]*))?\]\]`)
+
+// embedSizeRegex matches the size of an image embed (![[photo.png|300]] or |300x200).
+var embedSizeRegex = regexp.MustCompile(`^\s*\d+(x\d+)?\s*$`)
+
+// obsidianCommentRegex matches Obsidian comments (%%hidden%%), also across lines.
+var obsidianCommentRegex = regexp.MustCompile(`(?s)%%.*?%%`)
+
+// relativeImageRegex matches images referenced relative to the note (like
+// ![photo](../attachments/photo.png)), for formats that don't keep their images
+// in an "assets" folder. Like the Logseq pattern it captures the alt text,
+// the directory and the filename.
+var relativeImageRegex = regexp.MustCompile(`!\[(.*?)\]\((\.\.?[\\/](?:[^)]*[\\/])?)([^)\\/]*)\)(?:\{[^}]*\})?`)
+
+// openVault finds the Obsidian vault containing dir (dir itself or one of its parents).
+// It returns nil if dir isn't part of a vault.
+// The attachment folder is taken from the configuration or, if not configured,
+// from the vault settings (.obsidian/app.json).
+func openVault(fsys FileSystem, dir string, config ObsidianConfig) (*obsidianVault, error) {
+	root := filepath.Clean(dir)
+	for {
+		if info, err := fsys.Stat(filepath.Join(root, obsidianConfigDir)); err == nil && info.IsDir() {
+			break
+		}
+		parent := filepath.Dir(root)
+		if parent == root {
+			return nil, nil
+		}
+		root = parent
+	}
+
+	vault := &obsidianVault{root: root, attachments: config.Attachments, files: make(map[string][]string)}
+	if vault.attachments == "" {
+		vault.attachments = attachmentFolder(fsys, root)
+	}
+
+	// Embeds name a file without its folder, so all files of the vault are indexed
+	err := walkDir(fsys, root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != root && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		name := strings.ToLower(entry.Name())
+		vault.files[name] = append(vault.files[name], path)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scanning vault %s: %w", root, err)
+	}
+	for _, paths := range vault.files {
+		sort.Strings(paths)
+	}
+	return vault, nil
+}
+
+// attachmentFolder reads the attachment folder from the vault settings.
+// Obsidian writes "/" for the vault root and "./" for the folder of the note.
+func attachmentFolder(fsys FileSystem, root string) string {
+	data, err := readFile(fsys, filepath.Join(root, obsidianConfigDir, "app.json"))
+	if err != nil {
+		return ""
+	}
+	var settings struct {
+		AttachmentFolderPath string `json:"attachmentFolderPath"`
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		fmt.Printf("Warning: reading Obsidian settings: %v\n", err)
+		return ""
+	}
+	return settings.AttachmentFolderPath
+}
+
+// Extract extracts the blog post of an Obsidian note.
+// A note is a single post, marked in its front matter.
+func (v *obsidianVault) Extract(notePath string, source []byte, marker *BlogMarker) ([]*BlogPost, []ExtractionIssue) {
+	properties, body := parseFrontMatter(string(source))
+
+	found := false
+	for _, line := range properties {
+		if marker.Matches(line) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, nil
+	}
+
+	post := &BlogPost{
+		Meta: NewMetadataParser().Parse(properties),
+		Kind: KindArticle,
+	}
+
+	// Notes are named after their title, so the front matter rarely has one
+	if post.Meta.Title == "" {
+		post.Meta.Title = strings.TrimSuffix(filepath.Base(notePath), filepath.Ext(notePath))
+	}
+	if post.Meta.Header != "" {
+		post.Meta.Header = v.resolveHeader(notePath, post.Meta.Header)
+	}
+
+	body = obsidianCommentRegex.ReplaceAllString(body, "")
+	body = v.replaceEmbeds(notePath, body)
+	for _, text := range splitParagraphs(body) {
+		post.Content = append(post.Content, newContentBlock(text))
+	}
+
+	useFirstParagraph(post)
+	return []*BlogPost{post}, nil
+}
+
+// AssetRegex matches the images relative to the note, attachments can be in any folder.
+func (v *obsidianVault) AssetRegex() *regexp.Regexp {
+	return relativeImageRegex
+}
+
+// replaceEmbeds turns the embeds of a note into markdown the converter understands:
+// images become markdown images relative to the note (so they are copied into the
+// bundle), embedded notes become links to the note.
+func (v *obsidianVault) replaceEmbeds(notePath, body string) string {
+	return embedRegex.ReplaceAllStringFunc(body, func(match string) string {
+		parts := embedRegex.FindStringSubmatch(match)
+		target := strings.TrimSpace(parts[1])
+
+		// Embedded notes aren't copied into the post, they are linked
+		if ext := strings.ToLower(path.Ext(target)); ext == "" || ext == ".md" {
+			return "[[" + strings.TrimSuffix(target, path.Ext(target)) + "]]"
+		}
+
+		alt := parts[2]
+		if embedSizeRegex.MatchString(alt) {
+			alt = "" // Sizes are left to the theme
+		}
+		return "![" + alt + "](" + v.resolve(notePath, target) + ")"
+	})
+}
+
+// resolveHeader resolves a header image given as embed ("[[cover.jpg]]") or file name.
+// Paths relative to the note and URLs are kept.
+func (v *obsidianVault) resolveHeader(notePath, header string) string {
+	target := strings.TrimSpace(strings.TrimPrefix(header, "!"))
+	if strings.HasPrefix(target, "[[") && strings.HasSuffix(target, "]]") {
+		target, _, _ = strings.Cut(strings.TrimSuffix(strings.TrimPrefix(target, "[["), "]]"), "|")
+	} else if strings.HasPrefix(target, ".") || strings.Contains(target, "://") {
+		return header
+	}
+	return v.resolve(notePath, strings.TrimSpace(target))
+}
+
+// resolve finds the file an embed refers to like Obsidian does and returns its
+// path relative to the note (always starting with "./" or "../"):
+//   - A path ("photos/beach.jpg") is relative to the vault root
+//   - A file name is looked up in the attachment folder first
+//   - Otherwise the first file with that name anywhere in the vault is used
+//
+// Missing files resolve to the attachment folder, so the warning names the expected place.
+func (v *obsidianVault) resolve(notePath, target string) string {
+	noteDir := filepath.Dir(notePath)
+
+	var folder string
+	switch {
+	case v.attachments == "" || v.attachments == "/":
+		folder = v.root
+	case v.attachments == "." || strings.HasPrefix(v.attachments, "./"):
+		folder = filepath.Join(noteDir, filepath.FromSlash(v.attachments))
+	default:
+		folder = filepath.Join(v.root, filepath.FromSlash(v.attachments))
+	}
+
+	file := filepath.Join(folder, filepath.FromSlash(path.Base(target)))
+	if strings.Contains(target, "/") {
+		file = filepath.Join(v.root, filepath.FromSlash(target))
+	}
+	if !v.has(file) {
+		if paths := v.files[strings.ToLower(path.Base(target))]; len(paths) > 0 {
+			file = paths[0]
+		}
+	}
+
+	rel, err := filepath.Rel(noteDir, file)
+	if err != nil {
+		return target
+	}
+	rel = filepath.ToSlash(rel)
+	if !strings.HasPrefix(rel, "../") {
+		rel = "./" + rel
+	}
+	return rel
+}
+
+// has checks if the vault index has a file at the given path.
+func (v *obsidianVault) has(file string) bool {
+	for _, candidate := range v.files[strings.ToLower(filepath.Base(file))] {
+		if filepath.Clean(candidate) == filepath.Clean(file) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFrontMatter splits a note into its YAML front matter and its body.
+// The front matter is returned as property lines ("tags:: travel, sailing"),
+// so it can be read by the MetadataParser and matched by the blog marker.
+// Only the YAML used in Obsidian properties is supported: plain and quoted values,
+// lists ([a, b] or "- a" lines) and block scalars (| and >).
+func parseFrontMatter(source string) ([]string, string) {
+	source = strings.TrimPrefix(source, "\ufeff")
+	lines := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return nil, source
+	}
+
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		if trimmed := strings.TrimSpace(lines[i]); trimmed == "---" || trimmed == "..." {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return nil, source // Not closed, so it's not front matter
+	}
+
+	var properties []string
+	yaml := lines[1:end]
+	for i := 0; i < len(yaml); i++ {
+		line := strings.TrimRight(yaml[i], " \t")
+		if line == "" || strings.HasPrefix(line, "#") || line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.Join(strings.Fields(unquoteYAML(key)), "-")
+		value = strings.TrimSpace(value)
+
+		// Values on the following lines: a list or a block scalar
+		var nested []string
+		for i+1 < len(yaml) {
+			next := strings.TrimRight(yaml[i+1], " \t")
+			if next != "" && next[0] != ' ' && next[0] != '\t' && !strings.HasPrefix(next, "-") {
+				break
+			}
+			i++
+			if next = strings.TrimSpace(next); next != "" {
+				nested = append(nested, next)
+			}
+		}
+
+		var values []string
+		switch {
+		case value == "":
+			for _, item := range nested {
+				values = append(values, unquoteYAML(strings.TrimSpace(strings.TrimPrefix(item, "-"))))
+			}
+		case strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">"):
+			values = []string{strings.Join(nested, " ")} // Properties have a single line
+		case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+			for _, item := range strings.Split(value[1:len(value)-1], ",") {
+				if item = unquoteYAML(strings.TrimSpace(item)); item != "" {
+					values = append(values, item)
+				}
+			}
+		default:
+			values = []string{unquoteYAML(value)}
+		}
+		if len(values) == 0 {
+			continue
+		}
+		properties = append(properties, key+":: "+strings.Join(values, ", "))
+	}
+
+	return properties, strings.Join(lines[end+1:], "\n")
+}
+
+// unquoteYAML removes the quotes of a quoted YAML value (double or single quotes,
+// where a quote inside single quotes is doubled).
+// Links in properties are quoted in Obsidian ("[[Page]]"), the brackets are kept.
+func unquoteYAML(value string) string {
+	if len(value) < 2 {
+		return value
+	}
+	switch {
+	case value[0] == '"' && value[len(value)-1] == '"':
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			return unquoted
+		}
+		return value[1 : len(value)-1]
+	case value[0] == '\'' && value[len(value)-1] == '\'':
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+	}
+	return value
+}
+
+// splitParagraphs splits the body of a note into blocks at blank lines,
+// keeping fenced code blocks together.
+func splitParagraphs(body string) []string {
+	var blocks, current []string
+	fenced := false
+	flush := func() {
+		if text := strings.TrimSpace(strings.Join(current, "\n")); text != "" {
+			blocks = append(blocks, text)
+		}
+		current = nil
+	}
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if strings.HasPrefix(strings.TrimSpace(line), "```") || strings.HasPrefix(strings.TrimSpace(line), "~~~") {
+			fenced = !fenced
+		}
+		if line == "" && !fenced {
+			flush()
+			continue
+		}
+		current = append(current, line)
+	}
+	flush()
+	return blocks
+}
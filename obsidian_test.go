@@ -0,0 +1,102 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestParseFrontMatter tests reading YAML front matter as property lines
+func TestParseFrontMatter(t *testing.T) {
+	tests := []struct {
+		name      string
+		source    string
+		wantProps []string
+		wantBody  string
+	}{
+		{
+			name:      "Values and lists",
+			source:    "---\ntype: blog\ntitle: \"Sailing: Day 1\"\ntags: [sailing, 'it''s']\naliases:\n  - Day 1\n  - First day\n---\nBody",
+			wantProps: []string{"type:: blog", "title:: Sailing: Day 1", "tags:: sailing, it's", "aliases:: Day 1, First day"},
+			wantBody:  "Body",
+		},
+		{
+			name:      "Block scalar",
+			source:    "---\nsummary: >\n  A summary that\n  wraps\ncover image: beach.jpg\n---\n",
+			wantProps: []string{"summary:: A summary that wraps", "cover-image:: beach.jpg"},
+			wantBody:  "",
+		},
+		{
+			name:     "No front matter",
+			source:   "# Heading\n---\n",
+			wantBody: "# Heading\n---\n",
+		},
+		{
+			name:     "Unclosed front matter",
+			source:   "---\ntype: blog\n",
+			wantBody: "---\ntype: blog\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			props, body := parseFrontMatter(tt.source)
+			if !reflect.DeepEqual(props, tt.wantProps) {
+				t.Errorf("parseFrontMatter() properties = %q, want %q", props, tt.wantProps)
+			}
+			if body != tt.wantBody {
+				t.Errorf("parseFrontMatter() body = %q, want %q", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+// TestReplaceEmbeds tests turning embeds into markdown images and links
+func TestReplaceEmbeds(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	fsys.WriteFile(filepath.Join("vault", ".obsidian", "app.json"), []byte(`{"attachmentFolderPath": "attachments"}`))
+	fsys.WriteFile(filepath.Join("vault", "attachments", "beach.jpg"), []byte("jpg"))
+	fsys.WriteFile(filepath.Join("vault", "photos", "boat.png"), []byte("png"))
+	vault, err := openVault(fsys, "vault", ObsidianConfig{})
+	if err != nil || vault == nil {
+		t.Fatalf("openVault() = %v, %v", vault, err)
+	}
+	note := filepath.Join("vault", "posts", "Sailing.md")
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"Attachment folder", "![[beach.jpg]]", "![](../attachments/beach.jpg)"},
+		{"Size", "![[beach.jpg|300]]", "![](../attachments/beach.jpg)"},
+		{"Alt text", "![[beach.jpg|The beach]]", "![The beach](../attachments/beach.jpg)"},
+		{"Elsewhere in the vault", "![[boat.png]]", "![](../photos/boat.png)"},
+		{"Vault path", "![[photos/boat.png]]", "![](../photos/boat.png)"},
+		{"Missing file", "![[gone.jpg]]", "![](../attachments/gone.jpg)"},
+		{"Embedded note", "![[Other note#Heading]]", "[[Other note]]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := vault.replaceEmbeds(note, tt.body); got != tt.want {
+				t.Errorf("replaceEmbeds() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestOpenVault tests finding the vault of a directory
+func TestOpenVault(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	fsys.WriteFile(filepath.Join("vault", ".obsidian", "app.json"), []byte(`{}`))
+	fsys.WriteFile(filepath.Join("vault", "posts", "Note.md"), []byte(""))
+	fsys.WriteFile(filepath.Join("graph", "pages", "Page.md"), []byte(""))
+
+	if vault, err := openVault(fsys, filepath.Join("vault", "posts"), ObsidianConfig{}); err != nil || vault == nil || vault.root != "vault" {
+		t.Errorf("openVault() of a vault folder = %+v, %v; want the vault", vault, err)
+	}
+	if vault, err := openVault(fsys, "graph", ObsidianConfig{}); err != nil || vault != nil {
+		t.Errorf("openVault() of a Logseq graph = %+v, %v; want nil", vault, err)
+	}
+}
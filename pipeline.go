@@ -0,0 +1,286 @@
+// This file handles the phases a post goes through after it was extracted:
+// the transform phase builds a Document (content, front matter and the media
+// of the bundle) with a pipeline of steps, the write phase writes the
+// Document as index file and the files that go with it.
+//
+//	Extract (extractFile, extractGraph) -> []*BlogPost
+//	Select (selectPosts, preparePosts)  -> the posts to convert
+//	Transform (transformPost)           -> *Document
+//	Write (writeDocument)               -> OutputInfo
+//	Finish (writeRunFiles)              -> search index, snippet queue, redirects
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"logseq-to-hugo-converter/internal/words"
+)
+
+// Document is a post between the transform and the write phase.
+type Document struct {
+	Post      *BlogPost     // Extracted post, its Meta becomes the front matter
+	OutputDir string        // Bundle directory of the post
+	Content   string        // Markdown content of the index file (set by the "content" step)
+	Assets    []BundleAsset // Media written into the bundle (set by the "media" step)
+}
+
+// transformStep is a named step of the transform phase. Steps run in order and
+// change the Document; an error stops the conversion.
+type transformStep struct {
+	name string
+	run  func(ctx context.Context, doc *Document) error
+}
+
+// transformSteps returns the steps of the transform phase for the configuration.
+// Steps of switched-off features are left out.
+func (c *Converter) transformSteps() []transformStep {
+	steps := []transformStep{{"content", c.contentStep}}
+	if c.proofread != nil {
+		steps = append(steps, transformStep{"proofread", c.proofreadStep})
+	}
+	if c.summaries != nil {
+		steps = append(steps, transformStep{"summary", c.summaryStep})
+	}
+	steps = append(steps, transformStep{"data", c.dataStep}, transformStep{"toc", c.tocStep})
+	if c.config.Reading.Enabled {
+		steps = append(steps, transformStep{"reading", c.readingStep})
+	}
+	if c.config.Location.MapShortcode != "" {
+		steps = append(steps, transformStep{"location", c.locationStep})
+	}
+	steps = append(steps, transformStep{"media", c.mediaStep}, transformStep{"params", c.paramsStep})
+	if c.config.Social.Enabled {
+		steps = append(steps, transformStep{"social", c.socialStep})
+	}
+	if c.config.IndieWeb.Enabled {
+		steps = append(steps, transformStep{"indieweb", c.indieWebStep})
+	}
+	return append(steps, transformStep{"dates", c.datesStep})
+}
+
+// transformPost runs the transform phase on a post. The media are copied
+// into the bundle while their references are rewritten, the index file is not written yet.
+func (c *Converter) transformPost(ctx context.Context, post *BlogPost, outputBasePath string) (*Document, error) {
+	doc := &Document{Post: post, OutputDir: createOutputDir(outputBasePath, post)}
+	if err := c.fs.MkdirAll(doc.OutputDir, 0755); err != nil {
+		return nil, fmt.Errorf("creating output directory: %w", err)
+	}
+	for _, step := range c.transformSteps() {
+		if err := step.run(ctx, doc); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// contentStep runs the content filters and combines the blocks into the content.
+func (c *Converter) contentStep(ctx context.Context, doc *Document) error {
+	content, err := c.buildContent(doc.Post)
+	if err != nil {
+		return err
+	}
+	if c.config.Cleanup.ReportMarkup {
+		if found := findLogseqMarkup(content); len(found) > 0 {
+			fmt.Printf("Warning: '%s' still contains Logseq markup: %s\n", doc.Post.Meta.Title, strings.Join(found, ", "))
+		}
+	}
+	doc.Content = content
+	return nil
+}
+
+// proofreadStep checks the spelling of the content, the issues are warnings.
+func (c *Converter) proofreadStep(ctx context.Context, doc *Document) error {
+	c.proofreadPost(ctx, doc.Post.Meta, doc.Content)
+	return nil
+}
+
+// summaryStep lets the language model write the summary of posts without a "summary::" property.
+func (c *Converter) summaryStep(ctx context.Context, doc *Document) error {
+	if doc.Post.SummaryFromContent {
+		c.summaries.Apply(ctx, &doc.Post.Meta, doc.Content)
+	}
+	return nil
+}
+
+// dataStep puts the data properties into the front matter or into a table at the top.
+func (c *Converter) dataStep(ctx context.Context, doc *Document) error {
+	if fields := dataFields(doc.Post.Meta.Properties, c.config.Data); len(fields) > 0 {
+		if c.config.Data.Mode == DataModeTable {
+			doc.Content = strings.TrimSpace(dataTable(fields) + "\n\n" + doc.Content)
+		} else {
+			doc.Post.Meta.Data = fields
+		}
+	}
+	return nil
+}
+
+// tocStep decides whether the post gets a table of contents.
+func (c *Converter) tocStep(ctx context.Context, doc *Document) error {
+	doc.Content = c.applyTOC(&doc.Post.Meta, doc.Content)
+	return nil
+}
+
+// readingStep sets the word count and reading time for themes without built-in support.
+func (c *Converter) readingStep(ctx context.Context, doc *Document) error {
+	doc.Post.Meta.WordCount = words.Count(doc.Content)
+	doc.Post.Meta.ReadingTime = words.ReadingTime(doc.Post.Meta.WordCount, c.config.Reading.WordsPerMinute)
+	return nil
+}
+
+// locationStep puts the map at the end, after the words are counted.
+func (c *Converter) locationStep(ctx context.Context, doc *Document) error {
+	if doc.Post.Meta.Location != nil {
+		doc.Content += "\n\n" + locationShortcode(c.config.Location.MapShortcode, doc.Post.Meta.Location)
+	}
+	return nil
+}
+
+// mediaStep copies the images and videos into the bundle and rewrites their
+// references, reference-style images are written inline first. The media
+// are checked against the size budget of the bundle.
+func (c *Converter) mediaStep(ctx context.Context, doc *Document) error {
+	post := doc.Post
+	content := inlineImageReferences(doc.Content)
+	processor := NewImageProcessor(c.copies, filepath.Dir(post.SourcePath), doc.OutputDir)
+	processor.trackShortcode = c.config.Tracks.Shortcode
+	processor.sizeShortcode = c.config.Images.SizeShortcode
+	processor.lazyShortcode = c.config.Images.LazyShortcode
+	if c.config.Images.Placeholders {
+		processor.placeholderWidth = c.config.Images.PlaceholderWidth
+	}
+	processor.video = c.config.Video
+	processor.transcoder = c.videos
+	processor.assetRegex = c.extractor.AssetRegex()
+	if post.Meta.Header == "" && c.config.Header.FirstImage {
+		content = c.useFirstImageAsHeader(processor, &post.Meta, content)
+	}
+	if c.altText != nil {
+		content = c.altText.Apply(ctx, processor, content, llmLanguage(post.Meta.Language))
+	}
+	doc.Content = processor.ProcessContent(ctx, content)
+	if c.config.Header.FeaturedSize == "" {
+		processor.ProcessHeaderImage(ctx, post.Meta.Header)
+	}
+	processor.ProcessHeaderVariants(ctx, post.Meta.Header, c.config.Header)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	doc.Assets = processor.Assets()
+	for i := range doc.Assets {
+		doc.Assets[i].Post = post.Meta.Title
+		c.stats.Assets = append(c.stats.Assets, doc.Assets[i])
+	}
+	if err := c.checkBundleBudget(post, doc.Assets); err != nil {
+		return err
+	}
+
+	// All images of the bundle go into the front matter, the featured image first
+	if c.config.Images.FrontMatter {
+		post.Meta.Images = processor.Images()
+		if post.Meta.Header != "" {
+			post.Meta.Images = slices.Insert(post.Meta.Images, 0, c.featuredName(post.Meta.Header))
+		}
+	}
+	return nil
+}
+
+// paramsStep turns the mapped boolean properties and the translation opt-outs into params.
+func (c *Converter) paramsStep(ctx context.Context, doc *Document) error {
+	doc.Post.Meta.Flags = booleanParams(doc.Post.Meta.Properties, c.config.BooleanParams)
+	translationParams(&doc.Post.Meta)
+	return nil
+}
+
+// socialStep sets the social media previews, which use the featured image written by the media step.
+func (c *Converter) socialStep(ctx context.Context, doc *Document) error {
+	c.applySocial(&doc.Post.Meta)
+	return nil
+}
+
+// indieWebStep sets the canonical address and syndication metadata.
+func (c *Converter) indieWebStep(ctx context.Context, doc *Document) error {
+	c.applyIndieWeb(doc.Post)
+	return nil
+}
+
+// datesStep formats the date for the front matter, the last step since the other steps read the date as written in Logseq.
+func (c *Converter) datesStep(ctx context.Context, doc *Document) error {
+	doc.Post.Meta.Date = c.dates.FrontMatter(doc.Post.Meta.Date)
+	return nil
+}
+
+// writeDocument runs the write phase: the hooks, the index file and the files
+// written with it (publish log, snippet) and the entries of the run files.
+func (c *Converter) writeDocument(ctx context.Context, doc *Document) (OutputInfo, error) {
+	post, outputDir := doc.Post, doc.OutputDir
+	if err := c.runHooks(ctx, HookStagePre, post, outputDir); err != nil {
+		return OutputInfo{}, err
+	}
+
+	// The publish event goes into the front matter, the history is written with the index file
+	var history []PublishEvent
+	if c.config.PublishLog.Enabled {
+		var err error
+		if post.Meta.Publish, history, err = c.publishEvent(post, outputDir); err != nil {
+			return OutputInfo{}, err
+		}
+	}
+
+	writer := c.indexWriter(post, outputDir)
+	filename, err := writer.Write(post.Meta, doc.Content)
+	if err != nil {
+		return OutputInfo{}, err
+	}
+	if err := c.recordGenerated(outputDir, filename); err != nil {
+		return OutputInfo{}, err
+	}
+	if c.config.Search.Index != "" {
+		c.addSearchEntry(post, doc.Content)
+	}
+	if c.digest != nil {
+		c.addDigestPost(post)
+	}
+	if c.config.Snippets.Enabled {
+		if err := c.writeSnippet(ctx, post, doc.Content, outputDir); err != nil {
+			return OutputInfo{}, err
+		}
+	}
+	if history != nil {
+		if err := c.writePublishLog(history, outputDir); err != nil {
+			return OutputInfo{}, err
+		}
+	}
+
+	if err := c.runHooks(ctx, HookStagePost, post, outputDir); err != nil {
+		return OutputInfo{}, err
+	}
+
+	return OutputInfo{Dir: outputDir, Filename: filename}, nil
+}
+
+// writeRunFiles writes the files of the whole conversion below the output
+// directory, after all posts were written.
+func (c *Converter) writeRunFiles(outputBasePath string) error {
+	if c.videos != nil {
+		c.stats.Transcoded, c.stats.VideoSize, c.stats.TranscodedSize = c.videos.Totals()
+	}
+	if c.config.Search.Index != "" {
+		if err := c.writeSearchIndex(outputBasePath); err != nil {
+			return err
+		}
+	}
+	if c.config.Snippets.Enabled && c.config.Snippets.Queue != "" {
+		if err := c.writeSnippetQueue(outputBasePath); err != nil {
+			return err
+		}
+	}
+	if c.slugs != nil {
+		return c.writeRedirects(outputBasePath)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestTransformSteps tests the steps of the transform phase for a configuration
+func TestTransformSteps(t *testing.T) {
+	names := func(c *Converter) string {
+		var names []string
+		for _, step := range c.transformSteps() {
+			names = append(names, step.name)
+		}
+		return strings.Join(names, ",")
+	}
+
+	c := NewConverter(DefaultConfig(), NewMemFileSystem(nil))
+	if got, want := names(c), "content,data,toc,media,params,dates"; got != want {
+		t.Errorf("transformSteps() = %s, want %s", got, want)
+	}
+
+	config := DefaultConfig()
+	config.Reading.Enabled = true
+	config.Location.MapShortcode = "map"
+	config.Social.Enabled = true
+	config.IndieWeb.Enabled = true
+	c = NewConverter(config, NewMemFileSystem(nil))
+	c.summaries = &SummaryGenerator{}
+	if got, want := names(c), "content,summary,data,toc,reading,location,media,params,social,indieweb,dates"; got != want {
+		t.Errorf("transformSteps() = %s, want %s", got, want)
+	}
+}
+
+// newPipelineConverter returns a converter prepared for the transform and write phases of posts.
+func newPipelineConverter(t *testing.T, fsys FileSystem, config *Config, posts ...*BlogPost) *Converter {
+	t.Helper()
+	c := NewConverter(config, fsys)
+	var err error
+	if c.dates, err = NewDateFormatter(config.Dates); err != nil {
+		t.Fatal(err)
+	}
+	if c.extractor, err = openExtractor(fsys, "journals", config); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.preparePosts(posts); err != nil {
+		t.Fatalf("preparePosts() error: %v", err)
+	}
+	return c
+}
+
+// TestTransformPost tests building a Document without writing the index file
+func TestTransformPost(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	fsys.WriteFile(filepath.Join("assets", "boot.jpg"), []byte("jpeg"))
+	config := DefaultConfig()
+	config.Reading.Enabled = true
+	post := &BlogPost{
+		Meta: BlogMeta{Title: "Ibiza", Date: "2026-01-17", Language: "german", Properties: map[string]string{"comments": "false"}},
+		Content: []ContentBlock{
+			{Text: "Wir segelten nach [[Renan]]."},
+			{Text: "![Boot](../assets/boot.jpg)"},
+		},
+		SourcePath: filepath.Join("journals", "2026_01_17.md"),
+		Slug:       "2026-01-17_Ibiza",
+	}
+	renan := &BlogPost{Meta: BlogMeta{Title: "Renan", Date: "2026-01-10"}, Slug: "2026-01-10_Renan"}
+	config.BooleanParams = map[string]string{"comments": "comments"}
+	c := newPipelineConverter(t, fsys, config, post, renan)
+
+	doc, err := c.transformPost(context.Background(), post, "out")
+	if err != nil {
+		t.Fatalf("transformPost() error: %v", err)
+	}
+	want := "Wir segelten nach [Renan]({{< relref \"2026-01-10_Renan\" >}}).\n\n![Boot](boot.jpg)"
+	if doc.OutputDir != filepath.Join("out", "2026-01-17_Ibiza") || doc.Content != want {
+		t.Errorf("Document = %q in %s, want %q", doc.Content, doc.OutputDir, want)
+	}
+	if len(doc.Assets) != 1 || doc.Assets[0].Name != "boot.jpg" || doc.Assets[0].Post != "Ibiza" {
+		t.Errorf("Document assets = %+v, want boot.jpg of Ibiza", doc.Assets)
+	}
+	if comments, ok := post.Meta.Flags["comments"]; post.Meta.WordCount != 4 || !ok || comments {
+		t.Errorf("Front matter = %+v, want the word count and comments = false", post.Meta)
+	}
+	if _, err := fsys.Stat(filepath.Join(doc.OutputDir, "index.de.md")); err == nil {
+		t.Error("transformPost() wrote the index file")
+	}
+}
+
+// TestWriteDocument tests writing a Document built without the transform phase
+func TestWriteDocument(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	config := DefaultConfig()
+	config.Search.Index = "search.json"
+	c := newPipelineConverter(t, fsys, config)
+	c.now = func() time.Time { return time.Date(2026, 1, 17, 10, 0, 0, 0, time.UTC) }
+
+	doc := &Document{
+		Post:      &BlogPost{Meta: BlogMeta{Title: "Ibiza", Date: "2026-01-17", Language: "english"}, Slug: "2026-01-17_Ibiza"},
+		OutputDir: filepath.Join("out", "2026-01-17_Ibiza"),
+		Content:   "Sailing to Ibiza.",
+	}
+	fsys.MkdirAll(doc.OutputDir, 0755)
+	output, err := c.writeDocument(context.Background(), doc)
+	if err != nil {
+		t.Fatalf("writeDocument() error: %v", err)
+	}
+	if output.Filename != "index.en.md" {
+		t.Errorf("writeDocument() wrote %s, want index.en.md", output.Filename)
+	}
+	index, _ := readFile(fsys, filepath.Join(doc.OutputDir, output.Filename))
+	if !strings.Contains(string(index), "title = \"Ibiza\"") || !strings.HasSuffix(string(index), "+++\n\nSailing to Ibiza.\n") {
+		t.Errorf("Index file =\n%s", index)
+	}
+	if len(c.search) != 1 || c.search[0].Content != "Sailing to Ibiza." {
+		t.Errorf("Search entries = %+v", c.search)
+	}
+}
+
+// TestSelectPosts tests selecting the online posts that are not expired
+func TestSelectPosts(t *testing.T) {
+	config := DefaultConfig()
+	config.Output.PruneExpired = true
+	c := newPipelineConverter(t, NewMemFileSystem(nil), config)
+	c.now = func() time.Time { return time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC) }
+	c.only = func(post *BlogPost) bool { return post.Meta.Title != "Unselected" }
+	posts := []*BlogPost{
+		{Meta: BlogMeta{Title: "Online", Date: "2026-01-17", Status: "online"}},
+		{Meta: BlogMeta{Title: "Draft", Date: "2026-01-17", Status: "draft"}},
+		{Meta: BlogMeta{Title: "Expired", Date: "2026-01-01", Status: "online", ExpiryDate: "2026-01-31"}},
+		{Meta: BlogMeta{Title: "Unselected", Date: "2026-01-17", Status: "online"}},
+	}
+
+	online, err := c.selectPosts(posts, nil, "out")
+	if err != nil {
+		t.Fatalf("selectPosts() error: %v", err)
+	}
+	if len(online) != 1 || online[0].Meta.Title != "Online" {
+		t.Errorf("selectPosts() = %v, want the online post", online)
+	}
+	if c.stats.Skipped["status 'draft'"] != 1 || c.stats.Skipped["expired"] != 1 {
+		t.Errorf("Skipped = %v", c.stats.Skipped)
+	}
+}
@@ -0,0 +1,571 @@
+// This file implements the "preview" subcommand.
+// It renders converted posts to HTML and opens them in the browser, or prints
+// them styled to the terminal, to check a post before it lands in the Hugo
+// repository. Logseq files are converted in memory first, nothing is written.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/BurntSushi/toml"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	goldmarkhtml "github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+)
+
+// previewPost is a converted post to preview.
+type previewPost struct {
+	Name string     // Path shown for the post (e.g. "2026-01-17_Ibiza/index.de.md")
+	Path string     // Path of the index file in fs
+	Data []byte     // Content of the index file
+	fs   FileSystem // File system of the bundle (memory for converted Logseq files)
+}
+
+// runPreview runs the preview subcommand and returns the process exit code.
+// Usage: go run . preview [-config converter.toml] [-lang de] [-terminal] [-o preview.html] <index_file|bundle_directory|input_file.md>
+func runPreview(args []string) int {
+	flags := flag.NewFlagSet("preview", flag.ContinueOnError)
+	configPath := flags.String("config", "", "path to a converter.toml configuration file (for converting Logseq files)")
+	lang := flags.String("lang", "", "only preview the index files of this language (e.g. de)")
+	terminal := flags.Bool("terminal", false, "print the posts to the terminal instead of opening the browser")
+	outputPath := flags.String("o", "", "write the HTML to this file instead of opening the browser")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() < 1 {
+		fmt.Println("Usage: go run . preview [-config converter.toml] [-lang de] [-terminal] [-o preview.html] <index_file|bundle_directory|input_file.md>")
+		return 2
+	}
+
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	posts, err := loadPreviewPosts(ctx, config, flags.Arg(0), *lang)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 1
+	}
+
+	if *terminal {
+		_, noColor := os.LookupEnv("NO_COLOR")
+		for _, post := range posts {
+			if err := renderPreviewTerminal(os.Stdout, post, !noColor); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return 1
+			}
+		}
+		return 0
+	}
+
+	page, err := renderPreviewHTML(posts)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 1
+	}
+	if *outputPath != "" {
+		if err := os.WriteFile(*outputPath, page, 0644); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Wrote %s\n", *outputPath)
+		return 0
+	}
+
+	// The browser reads the file after the command has exited, so it stays in the temp directory
+	f, err := os.CreateTemp("", "logseq-to-hugo-preview-*.html")
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 1
+	}
+	_, err = f.Write(page)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 1
+	}
+	if err := openBrowser(f.Name()); err != nil {
+		fmt.Printf("Error: opening the browser: %v (the preview is in %s)\n", err, f.Name())
+		return 1
+	}
+	fmt.Printf("Opened %s\n", f.Name())
+	return 0
+}
+
+// loadPreviewPosts returns the posts of a generated index file, of the index
+// files below a directory, or of a Logseq (or Obsidian) file converted in memory.
+// With lang, only the index files of that language are returned.
+func loadPreviewPosts(ctx context.Context, config *Config, input, lang string) ([]previewPost, error) {
+	info, err := os.Stat(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var posts []previewPost
+	switch {
+	case info.IsDir():
+		files, err := findIndexFiles(input, config)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			name, err := filepath.Rel(input, file)
+			if err != nil {
+				name = file
+			}
+			posts = append(posts, previewPost{Name: filepath.ToSlash(name), Path: file, Data: data, fs: OSFileSystem{}})
+		}
+
+	default:
+		data, err := os.ReadFile(input)
+		if err != nil {
+			return nil, err
+		}
+		if _, _, ok := splitFrontMatter(string(data)); ok {
+			posts = append(posts, previewPost{Name: filepath.Base(input), Path: input, Data: data, fs: OSFileSystem{}})
+			break
+		}
+
+		// Not converted yet: convert into memory on top of the real files
+		mem := NewMemFileSystem(OSFileSystem{})
+		outputDir := filepath.Join(os.TempDir(), "logseq-to-hugo-preview")
+		outputs, err := NewConverter(config, mem).ConvertFile(ctx, input, outputDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, output := range outputs {
+			path := filepath.Join(output.Dir, output.Filename)
+			data, err := readFile(mem, path)
+			if err != nil {
+				return nil, err
+			}
+			name, err := filepath.Rel(outputDir, path)
+			if err != nil {
+				name = path
+			}
+			posts = append(posts, previewPost{Name: filepath.ToSlash(name), Path: path, Data: data, fs: mem})
+		}
+	}
+
+	if lang != "" {
+		names, err := indexFilenames(config)
+		if err != nil {
+			return nil, err
+		}
+		var filtered []previewPost
+		for _, post := range posts {
+			if language, _ := indexFileLanguage(post.Path, names); strings.EqualFold(language, lang) {
+				filtered = append(filtered, post)
+			}
+		}
+		posts = filtered
+	}
+	if len(posts) == 0 {
+		return nil, fmt.Errorf("no posts to preview in %s", input)
+	}
+	return posts, nil
+}
+
+// previewMeta is the front matter shown above a post.
+type previewMeta struct {
+	Title   string   `toml:"title"`
+	Date    any      `toml:"date"`
+	Draft   bool     `toml:"draft"`
+	Summary string   `toml:"summary"`
+	Tags    []string `toml:"tags"`
+}
+
+// parsePreview splits a post into its front matter and its markdown content.
+func parsePreview(post previewPost) (previewMeta, string, error) {
+	var meta previewMeta
+	frontMatter, content, ok := splitFrontMatter(string(post.Data))
+	if !ok {
+		return meta, string(post.Data), nil
+	}
+	if _, err := toml.Decode(frontMatter, &meta); err != nil {
+		return meta, "", fmt.Errorf("reading front matter of %s: %w", post.Name, err)
+	}
+	return meta, content, nil
+}
+
+// previewDetails returns the line shown below the title: date, draft and tags.
+func previewDetails(post previewPost, meta previewMeta) string {
+	details := []string{post.Name}
+	if meta.Date != nil {
+		details = append(details, fmt.Sprint(meta.Date))
+	}
+	if meta.Draft {
+		details = append(details, "draft")
+	}
+	if len(meta.Tags) > 0 {
+		details = append(details, "#"+strings.Join(meta.Tags, " #"))
+	}
+	return strings.Join(details, " · ")
+}
+
+var (
+	// previewRelrefLinkRegex matches link targets that are Hugo relrefs: ]({{< relref "slug" >}})
+	previewRelrefLinkRegex = regexp.MustCompile(`\]\(\{\{<\s*(?:rel)?ref\s[^>]*>\}\}\)`)
+
+	// previewShortcodeRegex matches Hugo shortcodes: {{< name attr="value" >}} and {{< /name >}}
+	previewShortcodeRegex = regexp.MustCompile(`\{\{[<%]\s*(/?)(\w+)([^}]*?)\s*[%>]\}\}`)
+
+	// previewAttrRegex matches the attributes of a shortcode
+	previewAttrRegex = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+	// previewSrcRegex matches the src attributes of the rendered HTML
+	previewSrcRegex = regexp.MustCompile(`\ssrc="([^"]+)"`)
+)
+
+// previewShortcodes replaces the shortcodes Hugo would render with HTML: videos
+// and figures with their media, links to other posts with "#", others are shown as code.
+func previewShortcodes(content string) string {
+	content = previewRelrefLinkRegex.ReplaceAllString(content, "](#)")
+	return previewShortcodeRegex.ReplaceAllStringFunc(content, func(shortcode string) string {
+		match := previewShortcodeRegex.FindStringSubmatch(shortcode)
+		attrs := make(map[string]string)
+		for _, attr := range previewAttrRegex.FindAllStringSubmatch(match[3], -1) {
+			attrs[attr[1]] = attr[2]
+		}
+		switch {
+		case match[1] == "" && match[2] == "video" && attrs["src"] != "":
+			return fmt.Sprintf(`<video src="%s" controls></video>`, html.EscapeString(attrs["src"]))
+		case match[1] == "" && match[2] == "figure" && attrs["src"] != "":
+			return fmt.Sprintf(`<figure><img src="%s" alt="%s"><figcaption>%s</figcaption></figure>`,
+				html.EscapeString(attrs["src"]), html.EscapeString(attrs["alt"]), html.EscapeString(attrs["caption"]))
+		}
+		return `<code class="shortcode">` + html.EscapeString(shortcode) + `</code>`
+	})
+}
+
+// embedPreviewMedia replaces the sources of images and videos in the bundle
+// with data URLs, so the page works without the bundle (or from memory).
+func embedPreviewMedia(page string, post previewPost) string {
+	bundleDir := filepath.Dir(post.Path)
+	return previewSrcRegex.ReplaceAllStringFunc(page, func(attr string) string {
+		src := html.UnescapeString(previewSrcRegex.FindStringSubmatch(attr)[1])
+		asset, ok := bundleAssetPath(src)
+		if !ok {
+			return attr
+		}
+		data, err := readFile(post.fs, filepath.Join(bundleDir, filepath.FromSlash(asset)))
+		if err != nil {
+			return attr
+		}
+		return fmt.Sprintf(` src="data:%s;base64,%s"`, mediaType(asset), base64.StdEncoding.EncodeToString(data))
+	})
+}
+
+// previewPageTemplate is the HTML page with all previewed posts.
+var previewPageTemplate = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{with index . 0}}{{.Title}}{{end}} – Preview</title>
+<style>
+body { font-family: system-ui, sans-serif; line-height: 1.6; max-width: 46rem; margin: 2rem auto; padding: 0 1rem; color: #222; }
+article + article { border-top: 3px double #ccc; margin-top: 3rem; }
+.details { color: #777; font-size: 0.9rem; }
+.summary { font-style: italic; color: #555; }
+img, video { max-width: 100%; }
+pre { background: #f5f5f5; padding: 0.75rem; overflow-x: auto; }
+blockquote { border-left: 4px solid #ddd; margin-left: 0; padding-left: 1rem; color: #555; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ddd; padding: 0.25rem 0.5rem; }
+code.shortcode { color: #a0a; }
+</style>
+</head>
+<body>
+{{range .}}<article>
+<header>
+<h1>{{.Title}}</h1>
+<p class="details">{{.Details}}</p>
+{{if .Summary}}<p class="summary">{{.Summary}}</p>{{end}}
+</header>
+{{.Content}}
+</article>
+{{end}}</body>
+</html>
+`))
+
+// renderPreviewHTML renders the posts into one HTML page.
+func renderPreviewHTML(posts []previewPost) ([]byte, error) {
+	markdown := goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithRendererOptions(goldmarkhtml.WithUnsafe()),
+	)
+
+	type article struct {
+		Title, Details, Summary string
+		Content                 template.HTML
+	}
+	var articles []article
+	for _, post := range posts {
+		meta, content, err := parsePreview(post)
+		if err != nil {
+			return nil, err
+		}
+		var body bytes.Buffer
+		if err := markdown.Convert([]byte(previewShortcodes(content)), &body); err != nil {
+			return nil, fmt.Errorf("rendering %s: %w", post.Name, err)
+		}
+		articles = append(articles, article{
+			Title:   meta.Title,
+			Details: previewDetails(post, meta),
+			Summary: meta.Summary,
+			Content: template.HTML(embedPreviewMedia(body.String(), post)),
+		})
+	}
+
+	var page bytes.Buffer
+	if err := previewPageTemplate.Execute(&page, articles); err != nil {
+		return nil, err
+	}
+	return page.Bytes(), nil
+}
+
+// openBrowser opens a file in the default browser of the system.
+func openBrowser(path string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", path)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", path)
+	default:
+		cmd = exec.Command("xdg-open", path)
+	}
+	return cmd.Start()
+}
+
+// renderPreviewTerminal prints a post to the terminal, styled with ANSI escape
+// codes if color is set.
+func renderPreviewTerminal(w io.Writer, post previewPost, color bool) error {
+	meta, content, err := parsePreview(post)
+	if err != nil {
+		return err
+	}
+	source := []byte(content)
+	doc := goldmark.New(goldmark.WithExtensions(extension.GFM)).Parser().Parse(text.NewReader(source))
+
+	r := &terminalRenderer{source: source, color: color}
+	r.b.WriteString(r.style("1", meta.Title) + "\n")
+	r.b.WriteString(r.style("2", previewDetails(post, meta)) + "\n")
+	if meta.Summary != "" {
+		r.b.WriteString(r.style("3", meta.Summary) + "\n")
+	}
+	r.b.WriteString(r.style("2", strings.Repeat("═", 60)) + "\n\n")
+	r.blocks(doc)
+	_, err = io.WriteString(w, strings.TrimRight(r.b.String(), "\n")+"\n\n")
+	return err
+}
+
+// terminalRenderer renders a markdown document as styled text.
+// Blocks end with a blank line, container blocks (lists, quotes) prefix the
+// lines of their children.
+type terminalRenderer struct {
+	source []byte
+	color  bool
+	b      strings.Builder
+}
+
+// style wraps s in an ANSI style (e.g. "1" for bold), if colors are enabled.
+func (r *terminalRenderer) style(code, s string) string {
+	if !r.color || s == "" {
+		return s
+	}
+	return "\x1b[" + code + "m" + s + "\x1b[0m"
+}
+
+// blocks renders the child blocks of a node.
+func (r *terminalRenderer) blocks(parent ast.Node) {
+	for n := parent.FirstChild(); n != nil; n = n.NextSibling() {
+		r.block(n)
+	}
+}
+
+// children renders the child blocks of a node into lines, without the blank line at the end.
+func (r *terminalRenderer) children(parent ast.Node) []string {
+	child := &terminalRenderer{source: r.source, color: r.color}
+	child.blocks(parent)
+	return strings.Split(strings.TrimRight(child.b.String(), "\n"), "\n")
+}
+
+// block renders a block and its children.
+func (r *terminalRenderer) block(n ast.Node) {
+	switch n := n.(type) {
+	case *ast.Heading:
+		code := "1"
+		if n.Level == 1 {
+			code = "1;4"
+		}
+		r.b.WriteString(r.style(code, strings.Repeat("#", n.Level)+" "+r.inline(n)) + "\n\n")
+
+	case *ast.Paragraph:
+		r.b.WriteString(r.inline(n) + "\n\n")
+
+	case *ast.TextBlock:
+		r.b.WriteString(r.inline(n) + "\n")
+
+	case *ast.ThematicBreak:
+		r.b.WriteString(r.style("2", strings.Repeat("─", 40)) + "\n\n")
+
+	case *ast.FencedCodeBlock, *ast.CodeBlock, *ast.HTMLBlock:
+		code := "36"
+		if n.Kind() == ast.KindHTMLBlock {
+			code = "2"
+		}
+		lines := n.Lines()
+		for i := 0; i < lines.Len(); i++ {
+			segment := lines.At(i)
+			r.b.WriteString("    " + r.style(code, strings.TrimRight(string(segment.Value(r.source)), "\n")) + "\n")
+		}
+		r.b.WriteString("\n")
+
+	case *ast.Blockquote:
+		for _, line := range r.children(n) {
+			r.b.WriteString(r.style("2", "│ ") + line + "\n")
+		}
+		r.b.WriteString("\n")
+
+	case *ast.List:
+		number := n.Start
+		for item := n.FirstChild(); item != nil; item = item.NextSibling() {
+			marker := "• "
+			if n.IsOrdered() {
+				marker = fmt.Sprintf("%d. ", number)
+				number++
+			}
+			for i, line := range r.children(item) {
+				if i == 0 {
+					r.b.WriteString(marker + line + "\n")
+				} else {
+					r.b.WriteString(strings.Repeat(" ", utf8.RuneCountInString(marker)) + line + "\n")
+				}
+			}
+		}
+		r.b.WriteString("\n")
+
+	case *east.Table:
+		r.table(n)
+
+	default:
+		r.blocks(n)
+	}
+}
+
+// table renders a GFM table with aligned columns and a bold header.
+func (r *terminalRenderer) table(table *east.Table) {
+	plain := &terminalRenderer{source: r.source}
+	var rows [][]string
+	var widths []int
+	for row := table.FirstChild(); row != nil; row = row.NextSibling() {
+		var cells []string
+		for i, cell := 0, row.FirstChild(); cell != nil; i, cell = i+1, cell.NextSibling() {
+			text := plain.inline(cell)
+			cells = append(cells, text)
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], utf8.RuneCountInString(text))
+		}
+		rows = append(rows, cells)
+	}
+
+	for i, cells := range rows {
+		padded := make([]string, len(cells))
+		for j, cell := range cells {
+			padded[j] = cell + strings.Repeat(" ", widths[j]-utf8.RuneCountInString(cell))
+		}
+		line := strings.Join(padded, " │ ")
+		if i == 0 {
+			r.b.WriteString(r.style("1", line) + "\n")
+			separators := make([]string, len(widths))
+			for j, width := range widths {
+				separators[j] = strings.Repeat("─", width)
+			}
+			r.b.WriteString(strings.Join(separators, "─┼─") + "\n")
+			continue
+		}
+		r.b.WriteString(line + "\n")
+	}
+	r.b.WriteString("\n")
+}
+
+// inline renders the inline children of a node.
+func (r *terminalRenderer) inline(n ast.Node) string {
+	var b strings.Builder
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		switch c := c.(type) {
+		case *ast.Text:
+			// Hugo renders the shortcodes, they are only highlighted
+			b.WriteString(previewShortcodeRegex.ReplaceAllStringFunc(string(c.Segment.Value(r.source)), func(shortcode string) string {
+				return r.style("35", shortcode)
+			}))
+			if c.SoftLineBreak() || c.HardLineBreak() {
+				b.WriteString("\n")
+			}
+		case *ast.String:
+			b.Write(c.Value)
+		case *ast.CodeSpan:
+			b.WriteString(r.style("36", r.inline(c)))
+		case *ast.Emphasis:
+			code := "3"
+			if c.Level == 2 {
+				code = "1"
+			}
+			b.WriteString(r.style(code, r.inline(c)))
+		case *ast.Link:
+			b.WriteString(r.style("4", r.inline(c)) + r.style("2", " ("+string(c.Destination)+")"))
+		case *ast.Image:
+			b.WriteString(r.style("35", "[image: "+r.inline(c)+" → "+string(c.Destination)+"]"))
+		case *ast.AutoLink:
+			b.WriteString(r.style("4", string(c.URL(r.source))))
+		case *ast.RawHTML:
+			for i := 0; i < c.Segments.Len(); i++ {
+				segment := c.Segments.At(i)
+				b.WriteString(r.style("2", string(segment.Value(r.source))))
+			}
+		case *east.Strikethrough:
+			b.WriteString(r.style("9", r.inline(c)))
+		case *east.TaskCheckBox:
+			if c.IsChecked {
+				b.WriteString("[x] ")
+			} else {
+				b.WriteString("[ ] ")
+			}
+		default:
+			b.WriteString(r.inline(c))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRenderPreviewTerminal tests the plain terminal rendering of the markdown blocks
+func TestRenderPreviewTerminal(t *testing.T) {
+	post := previewPost{
+		Name: "2026-01-17_Ibiza/index.de.md",
+		Data: []byte("+++\ndate = \"2026-01-17\"\ntitle = \"Ibiza\"\ntags = [\"segeln\", \"ibiza\"]\ndraft = true\n+++\n\n" +
+			"## Hafen\n\n" +
+			"Mit **Renan** nach [Ibiza](https://example.com).\n\n" +
+			"![Hafen](hafen.jpg)\n\n" +
+			"- Erster\n  - Zweiter\n- Dritter\n\n" +
+			"1. Eins\n2. Zwei\n\n" +
+			"> Zitat\n\n" +
+			"```\ncode\n```\n\n" +
+			"| Tag | Meilen |\n|---|---|\n| Montag | 12 |\n\n" +
+			"{{< video src=\"a.mp4\" >}}\n"),
+	}
+
+	want := "Ibiza\n" +
+		"2026-01-17_Ibiza/index.de.md · 2026-01-17 · draft · #segeln #ibiza\n" +
+		strings.Repeat("═", 60) + "\n\n" +
+		"## Hafen\n\n" +
+		"Mit Renan nach Ibiza (https://example.com).\n\n" +
+		"[image: Hafen → hafen.jpg]\n\n" +
+		"• Erster\n  • Zweiter\n• Dritter\n\n" +
+		"1. Eins\n2. Zwei\n\n" +
+		"│ Zitat\n\n" +
+		"    code\n\n" +
+		"Tag    │ Meilen\n───────┼───────\nMontag │ 12    \n\n" +
+		"{{< video src=\"a.mp4\" >}}\n\n"
+
+	var out bytes.Buffer
+	if err := renderPreviewTerminal(&out, post, false); err != nil {
+		t.Fatalf("renderPreviewTerminal() error = %v", err)
+	}
+	if out.String() != want {
+		t.Errorf("renderPreviewTerminal() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+// TestRenderPreviewHTML tests the HTML page with embedded images and rendered shortcodes
+func TestRenderPreviewHTML(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	bundle := filepath.Join("out", "2026-01-17_Ibiza")
+	fsys.WriteFile(filepath.Join(bundle, "hafen 1.png"), []byte("png"))
+	post := previewPost{
+		Name: "2026-01-17_Ibiza/index.de.md",
+		Path: filepath.Join(bundle, "index.de.md"),
+		Data: []byte("+++\ntitle = \"Ibiza <3\"\nsummary = \"Segeln\"\n+++\n\n" +
+			"![Hafen](hafen%201.png) ![Fehlt](fehlt.png)\n\n" +
+			"[Renan]({{< relref \"2024-06-14_Renan\" >}})\n\n" +
+			"{{< video src=\"dinghy.mp4\" >}}\n\n" +
+			"{{< map lat=\"38.9\" >}}\n"),
+		fs: fsys,
+	}
+
+	page, err := renderPreviewHTML([]previewPost{post})
+	if err != nil {
+		t.Fatalf("renderPreviewHTML() error = %v", err)
+	}
+	for _, want := range []string{
+		"<title>Ibiza &lt;3 – Preview</title>",
+		"<h1>Ibiza &lt;3</h1>",
+		`<p class="summary">Segeln</p>`,
+		`<img src="data:image/png;base64,cG5n" alt="Hafen">`,
+		`<img src="fehlt.png" alt="Fehlt">`,
+		`<a href="#">Renan</a>`,
+		`<video src="dinghy.mp4" controls></video>`,
+		`<code class="shortcode">{{&lt; map lat=&quot;38.9&quot; &gt;}}</code>`,
+	} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("page doesn't contain %q:\n%s", want, page)
+		}
+	}
+}
+
+// TestLoadPreviewPosts tests previewing a Logseq file without writing its bundle
+func TestLoadPreviewPosts(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "pages", "Ibiza.md")
+	if err := os.MkdirAll(filepath.Dir(input), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(input, []byte("type:: blog\nstatus:: online\ndate:: 2026-01-17\ntitle:: Ibiza\n\n- Wir segelten nach Ibiza.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	posts, err := loadPreviewPosts(context.Background(), DefaultConfig(), input, "")
+	if err != nil {
+		t.Fatalf("loadPreviewPosts() error = %v", err)
+	}
+	if len(posts) != 1 || posts[0].Name != "2026-01-17_Ibiza/index.de.md" || !strings.Contains(string(posts[0].Data), "Wir segelten nach Ibiza.") {
+		t.Fatalf("loadPreviewPosts() = %+v, want the converted post", posts)
+	}
+	if _, err := os.Stat(filepath.Join(os.TempDir(), "logseq-to-hugo-preview")); !os.IsNotExist(err) {
+		t.Errorf("the preview wrote the bundle: %v", err)
+	}
+
+	if _, err := loadPreviewPosts(context.Background(), DefaultConfig(), input, "en"); err == nil {
+		t.Error("loadPreviewPosts() without a post in the language should fail")
+	}
+}
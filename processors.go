@@ -4,112 +4,295 @@
 package main
 
 import (
+	"context"  // Cancellation of long running copies
+	"errors"   // Checking for missing files
 	"fmt"      // Formatted I/O (printing)
-	"io"       // Input/Output operations
-	"os"       // Operating system functions (file operations)
+	"io/fs"    // File system errors
+	"net/url"  // Decoding percent-encoded file names
 	"path/filepath" // File path manipulation
 	"regexp"   // Regular expressions
+	"slices"   // Checking for images already in the bundle
 	"strings"  // String manipulation for extension checking
 )
 
 // ImageProcessor is responsible for handling all image-related operations.
 // It processes both inline images and header/featured images.
 type ImageProcessor struct {
-	inputDir   string         // Directory where input markdown file is located
-	outputDir  string         // Directory where processed images should be copied
-	assetRegex *regexp.Regexp // Compiled regex to find image references
+	fs         FileSystem        // File system to read the assets from and write them to
+	copies     *CopyManager      // Copies the assets (shared between processors of a conversion)
+	inputDir   string            // Directory where input markdown file is located
+	outputDir  string            // Directory where processed images should be copied
+	assetRegex *regexp.Regexp    // Compiled regex to find image references
+	names      map[string]string // Lowercase bundle filenames -> source path (for collision checks)
+
+	// trackShortcode is the map shortcode for GPX tracks (e.g. "gpx").
+	// If empty, GPX files are treated like any other asset.
+	trackShortcode string
+
+	// sizeShortcode is the image shortcode for images with Logseq size hints (e.g. "figure").
+	// If empty, the size hints are removed.
+	sizeShortcode string
+
+	// lazyShortcode is the image shortcode every image is written with, with
+	// loading="lazy" (e.g. "figure"). If empty, images stay markdown images.
+	lazyShortcode string
+
+	// placeholderWidth is the width of the blurred placeholders written for
+	// the lazy images (0 = no placeholders).
+	placeholderWidth int
+
+	// video configures the markup of videos (DefaultVideoConfig unless set).
+	video VideoConfig
+
+	// transcoder re-encodes the videos while they are copied (nil = copy them as they are).
+	transcoder *VideoTranscoder
+
+	// images are the bundle names of the images copied for the content, in the order they were copied.
+	images []string
+
+	// assets are the files written into the bundle, for the report.
+	assets []BundleAsset
 }
 
+// trackLinkRegex matches a markdown link to a GPX track in the assets:
+// [Route](../assets/track.gpx)
+var trackLinkRegex = regexp.MustCompile(`(?i)\[[^\]]*\]\(([^)]*assets[\\/])([^)]*\.gpx)\)`)
+
+// logseqAssetRegex matches images in the assets folder of a Logseq graph.
+// Pattern breakdown:
+//   !\[(.*?)\]     = Markdown image alt text: ![anything]
+//   \(             = Opening parenthesis
+//   (.*?assets[\\/]) = Capture path including "assets/" (or "assets\" from Windows)
+//   (.*?)          = Capture the filename
+//   \)             = Closing parenthesis
+//   (?:\{[^}]*\})? = Optional non-capturing group for Logseq metadata like {:height 446, :width 778}
+// Example match: ![photo](../assets/image.jpg){:height 100, :width 200}
+var logseqAssetRegex = regexp.MustCompile(`!\[(.*?)\]\((.*?assets[\\/])(.*?)\)(?:\{[^}]*\})?`)
+
 // NewImageProcessor creates a new ImageProcessor instance.
 // Parameters:
+//   copies: The CopyManager that copies the images (see NewCopyManager)
 //   inputDir: The directory containing the source markdown file
 //   outputDir: The directory where images should be copied to
 // Returns:
 //   *ImageProcessor: A pointer to the new processor
-func NewImageProcessor(inputDir, outputDir string) *ImageProcessor {
+func NewImageProcessor(copies *CopyManager, inputDir, outputDir string) *ImageProcessor {
 	// Return a pointer to a new ImageProcessor struct
 	return &ImageProcessor{
+		fs:        copies.fs,
+		copies:    copies,
 		inputDir:  inputDir,
 		outputDir: outputDir,
-		// Compile the regex pattern for finding images
-		// Pattern breakdown:
-		//   !\[(.*?)\]     = Markdown image alt text: ![anything]
-		//   \(             = Opening parenthesis
-		//   (.*?assets\/)  = Capture path including "assets/"
-		//   (.*?)          = Capture the filename
-		//   \)             = Closing parenthesis
-		//   (?:\{[^}]*\})? = Optional non-capturing group for Logseq metadata like {:height 446, :width 778}
-		// Example match: ![photo](../assets/image.jpg){:height 100, :width 200}
-		assetRegex: regexp.MustCompile(`!\[(.*?)\]\((.*?assets\/)(.*?)\)(?:\{[^}]*\})?`),
+		// Images of Logseq graphs by default, other input formats set their own pattern
+		assetRegex: logseqAssetRegex,
+		video:      DefaultVideoConfig,
 	}
 }
 
 // ProcessContent processes all images and videos in the content string.
 // It finds media references, copies the files, and updates the references.
-// Videos are converted to Hugo shortcode format: {{< video src="file.mp4" >}}
+// Videos are converted to the configured markup, by default the Hugo shortcode {{< video src="file.mp4" >}}
+// Once ctx is cancelled, no more files are copied and references are left unchanged.
 // Parameters:
+//   ctx: Context to cancel the copies
 //   content: The markdown content containing media references
 // Returns:
 //   string: Updated content with simplified paths and video shortcodes
-func (p *ImageProcessor) ProcessContent(content string) string {
-	// Find all media references in the content
-	// FindAllStringSubmatch returns a 2D slice:
-	//   - Outer slice: one element per match
-	//   - Inner slice: [full match, capture group 1, capture group 2, ...]
-	// -1 means find all matches (not just the first)
-	matches := p.assetRegex.FindAllStringSubmatch(content, -1)
-
-	// Process each found media file
-	// range iterates over the slice, _ discards the index
-	for _, match := range matches {
-		// match[0] = entire match (e.g., "![photo](../assets/image.jpg)")
-		// match[1] = alt text (e.g., "photo")
-		// match[2] = path to assets (e.g., "../assets/")
-		// match[3] = filename (e.g., "image.jpg")
-		
-		// Build the source path (where the media file currently is)
-		// filepath.Join combines path parts with the correct separator
-		src := filepath.Join(p.inputDir, match[2]+match[3])
-		
-		// Build the destination path (where to copy the media file)
-		dst := filepath.Join(p.outputDir, match[3])
-		
-		// Copy the media file
-		p.copyFile(src, dst)
+func (p *ImageProcessor) ProcessContent(ctx context.Context, content string) string {
+	// Update the content with a custom replacement function
+	// This allows us to copy each media file and decide how to replace its reference
+	// Media references in code blocks and code spans are examples, they are kept as they are
+	result := replaceOutsideCode(content, func(text string) string {
+		return p.assetRegex.ReplaceAllStringFunc(text, func(match string) string {
+			// Extract the parts of this match
+			// parts[0] = entire match (e.g., "![photo](../assets/image.jpg)")
+			// parts[1] = alt text (e.g., "photo")
+			// parts[2] = path to assets (e.g., "../assets/")
+			// parts[3] = filename (e.g., "image.jpg")
+			parts := p.assetRegex.FindStringSubmatch(match)
+			if len(parts) != 4 {
+				return match // If pattern doesn't match, return unchanged
+			}
+
+			// Stop copying when the conversion was cancelled
+			if ctx.Err() != nil {
+				return match
+			}
+			
+			altText := parts[1] // The alt text
+
+			// Copy the media file; the name in the bundle may differ from
+			// the original name if it collides with another file
+			filename := p.copyAsset(ctx, parts[2]+parts[3], slashPath(unescapePath(parts[3])))
+			
+			// GPX tracks are shown on a map
+			if p.trackShortcode != "" && isTrackFile(filename) {
+				return trackShortcode(p.trackShortcode, filename)
+			}
+
+			// Check if this is a video file by extension
+			if isVideoFile(filename) {
+				// Convert to the configured video markup, by default the Hugo video shortcode
+				// {{< video src="filename.mp4" >}}
+				return videoMarkup(p.video, filename)
+			}
+			
+			// For images, use simplified markdown syntax
+			// "![alt](../assets/image.jpg)" -> "![alt](image.jpg)"
+			// Logseq size hints are kept for resizeImages below
+			hints := match[len("!["+altText+"]("+parts[2]+parts[3]+")"):]
+			if !strings.HasPrefix(hints, "{:") {
+				hints = ""
+			}
+			if p.lazyShortcode != "" {
+				return p.lazyImage(ctx, parts[2]+parts[3], filename, altText, parseSizeHints(hints))
+			}
+			return fmt.Sprintf("![%s](%s)", altText, markdownPath(filename)) + hints
+		})
+	})
+
+	// Plain links to GPX tracks are shown on a map as well
+	if p.trackShortcode != "" {
+		result = replaceOutsideCode(result, func(text string) string {
+			return trackLinkRegex.ReplaceAllStringFunc(text, func(match string) string {
+				parts := trackLinkRegex.FindStringSubmatch(match)
+				if ctx.Err() != nil {
+					return match
+				}
+				filename := p.copyAsset(ctx, parts[1]+parts[2], slashPath(unescapePath(parts[2])))
+				return trackShortcode(p.trackShortcode, filename)
+			})
+		})
 	}
 
-	// Update the content with a custom replacement function
-	// This allows us to check each match and decide how to replace it
-	result := p.assetRegex.ReplaceAllStringFunc(content, func(match string) string {
-		// Extract the parts of this match
-		parts := p.assetRegex.FindStringSubmatch(match)
-		if len(parts) != 4 {
-			return match // If pattern doesn't match, return unchanged
-		}
-		
-		altText := parts[1]  // The alt text
-		filename := parts[3]  // The filename
-		
-		// Check if this is a video file by extension
-		if isVideoFile(filename) {
-			// Convert to Hugo video shortcode
-			// {{< video src="filename.mp4" >}}
-			return fmt.Sprintf(`{{< video src="%s" >}}`, filename)
-		}
-		
-		// For images, use simplified markdown syntax
-		// "![alt](../assets/image.jpg)" -> "![alt](image.jpg)"
-		return fmt.Sprintf("![%s](%s)", altText, filename)
+	// Media tags in raw HTML (<img src="../assets/a.png">) get their assets copied as well
+	result = replaceOutsideCode(result, func(text string) string {
+		return p.processHTMLMedia(ctx, text)
 	})
+
+	// Size hints of Logseq ({:height 400, :width 600}) are removed or become the image shortcode
+	result = resizeImages(result, p.sizeShortcode)
 	
 	return result
 }
 
+// FirstImage finds the first inline image (not video or GPX track) in the content.
+// It is used as featured image for posts without a header image.
+// Parameters:
+//   content: The markdown content containing media references
+// Returns:
+//   match: The complete image reference (e.g., "![photo](../assets/image.jpg){:height 100}")
+//   ref: The path of the image (e.g., "../assets/image.jpg"), empty if there is no image
+func (p *ImageProcessor) FirstImage(content string) (match, ref string) {
+	// Images in code blocks and code spans are examples
+	for _, parts := range p.assetRegex.FindAllStringSubmatch(codeRegex.ReplaceAllString(content, ""), -1) {
+		if !isVideoFile(parts[3]) && !isTrackFile(parts[3]) {
+			return parts[0], parts[2] + parts[3]
+		}
+	}
+	return "", ""
+}
+
+// copyAsset copies a referenced media file into the bundle and returns its name in the bundle.
+// It protects the bundle against problems that only show up on some systems:
+//   - Symlinks in the assets folder are resolved, so the real file is copied
+//   - Names differing only in case (Image.png vs image.png) get a numbered suffix,
+//     because they would overwrite each other on macOS and Windows
+//   - Files are never written outside the output directory (e.g. for "../../" references)
+// Parameters:
+//   ctx: Context to cancel the copy
+//   ref: The reference as written in the markdown (e.g., "../assets/image.jpg")
+//   name: The desired filename in the bundle (e.g., "image.jpg")
+// Returns:
+//   string: The filename to reference in the bundle
+func (p *ImageProcessor) copyAsset(ctx context.Context, ref, name string) string {
+	// Build the source path and resolve symlinks (if the file exists)
+	src := p.sourcePath(ref)
+	if resolved, err := p.fs.EvalSymlinks(src); err == nil {
+		src = resolved
+	}
+
+	// Transcoded videos may get another extension ("clip.mov" -> "clip.mp4")
+	if p.transcoder != nil && isVideoFile(name) {
+		name = p.transcoder.Name(name)
+	}
+
+	// Pick a name that doesn't collide with another file in the bundle
+	name = p.uniqueName(name, src)
+
+	// Build the destination path and refuse to leave the output directory
+	dst := filepath.Join(p.outputDir, localPath(name))
+	if !isInsideDir(p.outputDir, dst) {
+		fmt.Printf("Warning: Refusing to copy %s outside of %s\n", ref, p.outputDir)
+		return name
+	}
+
+	// Create subdirectories for references like "../assets/2024/image.jpg"
+	if dir := filepath.Dir(dst); dir != p.outputDir {
+		p.fs.MkdirAll(dir, 0755)
+	}
+
+	if isImageFile(name) && !slices.Contains(p.images, name) {
+		p.images = append(p.images, name)
+	}
+	p.copyFile(ctx, src, dst)
+	if p.transcoder != nil && isVideoFile(name) {
+		p.recordAsset(dst, "transcoded")
+	} else {
+		p.recordAsset(dst, "copied")
+	}
+	return name
+}
+
+// Images returns the bundle names of the images copied for the content: the
+// markdown images in the order they first appear, then the images of HTML tags.
+// Videos, tracks and other files are left out.
+func (p *ImageProcessor) Images() []string {
+	return slices.Clone(p.images)
+}
+
+// uniqueName returns a bundle filename for a source file that is unique
+// even on case-insensitive filesystems. The same source always gets the same name.
+func (p *ImageProcessor) uniqueName(name, src string) string {
+	if p.names == nil {
+		p.names = make(map[string]string)
+	}
+
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	candidate := name
+	for i := 2; ; i++ {
+		key := strings.ToLower(candidate)
+		existing, taken := p.names[key]
+		if !taken {
+			p.names[key] = src
+			if candidate != name {
+				fmt.Printf("Warning: %s collides with another file on case-insensitive filesystems, using %s\n", name, candidate)
+			}
+			return candidate
+		}
+		if existing == src {
+			return candidate // Same file referenced again
+		}
+		candidate = fmt.Sprintf("%s_%d%s", base, i, ext)
+	}
+}
+
+// isInsideDir checks whether path is inside dir (after cleaning both paths).
+func isInsideDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 // ProcessHeaderImage copies the header image and renames it to "featured".
 // Hugo expects the featured/header image to be named "featured.*"
 // Parameters:
+//   ctx: Context to cancel the copy
 //   headerPath: Relative path to the header image (e.g., "../assets/header.jpg")
-func (p *ImageProcessor) ProcessHeaderImage(headerPath string) {
+func (p *ImageProcessor) ProcessHeaderImage(ctx context.Context, headerPath string) {
 	// If no header path is provided, do nothing
 	// Empty string check
 	if headerPath == "" {
@@ -118,11 +301,11 @@ func (p *ImageProcessor) ProcessHeaderImage(headerPath string) {
 
 	// Extract just the filename from the path
 	// filepath.Base returns the last element of the path
-	// e.g., "../assets/photo.jpg" -> "photo.jpg"
-	fileName := filepath.Base(headerPath)
+	// e.g., "../assets/photo.jpg" -> "photo.jpg" (also for "..\assets\photo.jpg")
+	fileName := filepath.Base(localPath(headerPath))
 	
 	// Build the full source path
-	src := filepath.Join(p.inputDir, headerPath)
+	src := p.sourcePath(headerPath)
 	
 	// Get the file extension (e.g., ".jpg", ".png")
 	// filepath.Ext returns the extension including the dot
@@ -132,47 +315,78 @@ func (p *ImageProcessor) ProcessHeaderImage(headerPath string) {
 	dst := filepath.Join(p.outputDir, "featured"+ext)
 	
 	// Copy the file
-	p.copyFile(src, dst)
+	p.copyFile(ctx, src, dst)
+	p.recordAsset(dst, "copied")
 }
 
 // copyFile copies a file from source to destination.
 // This is a helper method used internally by the processor.
+// The copy goes through the processor's CopyManager, so a file that is
+// referenced several times is only copied once.
 // Parameters:
+//   ctx: Context to cancel the copy (large videos are copied in chunks)
 //   src: Source file path
 //   dst: Destination file path
-func (p *ImageProcessor) copyFile(src, dst string) {
-	// Open the source file for reading
-	// os.Open returns a file handle and an error
-	in, err := os.Open(src)
-	
-	// Check if there was an error opening the file
-	if err != nil {
-		// If the file doesn't exist or can't be opened, print a warning
-		// We don't stop the entire conversion for missing images
+func (p *ImageProcessor) copyFile(ctx context.Context, src, dst string) {
+	var err error
+	if p.transcoder != nil && isVideoFile(dst) {
+		// Videos are re-encoded instead of copied
+		err = p.copies.CopyWith(ctx, src, dst, func(ctx context.Context, src, dst string) error {
+			return p.transcoder.Transcode(ctx, p.fs, src, dst)
+		})
+	} else {
+		err = p.copies.Copy(ctx, src, dst)
+	}
+
+	// We don't stop the entire conversion for missing or broken images,
+	// a warning is printed instead
+	if errors.Is(err, fs.ErrNotExist) {
 		fmt.Printf("Warning: Missing image %s\n", src)
-		return // Exit this function early
+	} else if err != nil && ctx.Err() == nil {
+		fmt.Printf("Warning: %v\n", err)
 	}
-	// defer means "run this when the function exits"
-	// This ensures the file is closed even if an error occurs later
-	defer in.Close()
+}
 
-	// Create (or overwrite) the destination file
-	out, err := os.Create(dst)
-	if err != nil {
-		// If we can't create the destination file, just return
-		// (We could log this error too, but we keep it simple)
-		return
-	}
-	// Ensure the output file is also closed when we're done
-	defer out.Close()
-
-	// Copy all data from source to destination
-	// io.Copy reads from 'in' and writes to 'out' until EOF
-	// We ignore the return values (bytes copied and error)
-	// because we're doing basic file copying
-	io.Copy(out, in)
-	
-	// Note: In production code, you might want to check the error from io.Copy
+// sourcePath returns the path of a referenced asset in the graph.
+// Logseq percent-encodes the names in references ("image%20name.png"),
+// a file with the literal name is only used if there is one.
+func (p *ImageProcessor) sourcePath(ref string) string {
+	src := filepath.Join(p.inputDir, localPath(ref))
+	if decoded := unescapePath(ref); decoded != ref {
+		if _, err := p.fs.Stat(src); err != nil {
+			return filepath.Join(p.inputDir, localPath(decoded))
+		}
+	}
+	return src
+}
+
+// unescapePath decodes a percent-encoded path from markdown ("image%20name.png" -> "image name.png").
+// Paths that aren't valid percent-encoding are returned as they are.
+func unescapePath(path string) string {
+	if decoded, err := url.PathUnescape(path); err == nil {
+		return decoded
+	}
+	return path
+}
+
+// markdownPathEscaper encodes the characters that end or break a markdown link destination.
+var markdownPathEscaper = strings.NewReplacer("%", "%25", " ", "%20", "(", "%28", ")", "%29", "<", "%3C", ">", "%3E")
+
+// markdownPath encodes a file name in the bundle for a markdown link ("image name.png" -> "image%20name.png").
+func markdownPath(name string) string {
+	return markdownPathEscaper.Replace(name)
+}
+
+// slashPath normalizes a path from markdown to forward slashes.
+// Logseq on Windows may write references like "..\assets\image.png".
+func slashPath(path string) string {
+	return strings.ReplaceAll(path, `\`, "/")
+}
+
+// localPath converts a path from markdown (with / or \ separators)
+// to the separator of the operating system we run on.
+func localPath(path string) string {
+	return filepath.FromSlash(slashPath(path))
 }
 
 // isVideoFile checks if a filename has a video file extension.
@@ -213,3 +427,23 @@ func isVideoFile(filename string) bool {
 	// Not a video file
 	return false
 }
+
+// isImageFile checks if a filename has the extension of an image browsers show (case-insensitive).
+func isImageFile(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".jpg", ".jpeg", ".png", ".gif", ".webp", ".avif", ".svg", ".bmp":
+		return true
+	}
+	return false
+}
+
+// isTrackFile checks if a filename is a GPX track (case-insensitive).
+func isTrackFile(filename string) bool {
+	return strings.EqualFold(filepath.Ext(filename), ".gpx")
+}
+
+// trackShortcode renders the map shortcode of a GPX track in the bundle.
+// Example: {{< gpx src="track.gpx" >}}
+func trackShortcode(shortcode, filename string) string {
+	return fmt.Sprintf(`{{< %s src="%s" >}}`, shortcode, filename)
+}
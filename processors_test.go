@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupGraph creates a minimal Logseq graph with a journals and an assets directory.
+// It returns the journals directory (the input directory of a journal file).
+func setupGraph(t *testing.T, assets ...string) string {
+	t.Helper()
+	graphDir := t.TempDir()
+	for _, dir := range []string{"journals", "assets"} {
+		if err := os.MkdirAll(filepath.Join(graphDir, dir), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+	for _, asset := range assets {
+		if err := os.WriteFile(filepath.Join(graphDir, "assets", asset), []byte("data of "+asset), 0644); err != nil {
+			t.Fatalf("Failed to create asset %s: %v", asset, err)
+		}
+	}
+	return filepath.Join(graphDir, "journals")
+}
+
+// TestProcessContent_WindowsPaths tests media references with Windows separators
+func TestProcessContent_WindowsPaths(t *testing.T) {
+	inputDir := setupGraph(t, "photo.png", "header.jpg")
+	outputDir := t.TempDir()
+
+	processor := NewImageProcessor(NewCopyManager(OSFileSystem{}), inputDir, outputDir)
+	got := processor.ProcessContent(context.Background(), `![photo](..\assets\photo.png)`)
+	processor.ProcessHeaderImage(context.Background(), `..\assets\header.jpg`)
+
+	if want := "![photo](photo.png)"; got != want {
+		t.Errorf("ProcessContent() = %q, want %q", got, want)
+	}
+
+	for _, name := range []string{"photo.png", "featured.jpg"} {
+		if _, err := os.Stat(filepath.Join(outputDir, name)); err != nil {
+			t.Errorf("Expected %s to be copied: %v", name, err)
+		}
+	}
+}
+
+// TestProcessContent_CaseCollisions tests renaming files that only differ in case
+func TestProcessContent_CaseCollisions(t *testing.T) {
+	inputDir := setupGraph(t, "Photo.png", "photo.png")
+	outputDir := t.TempDir()
+
+	processor := NewImageProcessor(NewCopyManager(OSFileSystem{}), inputDir, outputDir)
+	got := processor.ProcessContent(context.Background(), "![a](../assets/Photo.png)\n![b](../assets/photo.png)\n![c](../assets/Photo.png)")
+
+	want := "![a](Photo.png)\n![b](photo_2.png)\n![c](Photo.png)"
+	if got != want {
+		t.Errorf("ProcessContent() = %q, want %q", got, want)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputDir, "photo_2.png"))
+	if err != nil || string(data) != "data of photo.png" {
+		t.Errorf("photo_2.png = %q, %v; want content of photo.png", data, err)
+	}
+}
+
+// TestProcessContent_PathTraversal tests that files are never copied outside the bundle
+func TestProcessContent_PathTraversal(t *testing.T) {
+	inputDir := setupGraph(t)
+	outputBase := t.TempDir()
+	outputDir := filepath.Join(outputBase, "bundle")
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		t.Fatalf("Failed to create bundle: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(filepath.Dir(inputDir), "secret.png"), []byte("secret"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	NewImageProcessor(NewCopyManager(OSFileSystem{}), inputDir, outputDir).ProcessContent(context.Background(), "![x](../assets/../secret.png)")
+
+	if _, err := os.Stat(filepath.Join(outputBase, "secret.png")); err == nil {
+		t.Error("File was copied outside of the output directory")
+	}
+}
+
+// TestProcessContent_Symlinks tests that symlinked assets are copied as regular files
+func TestProcessContent_Symlinks(t *testing.T) {
+	inputDir := setupGraph(t, "real.png")
+	outputDir := t.TempDir()
+	link := filepath.Join(filepath.Dir(inputDir), "assets", "link.png")
+	if err := os.Symlink(filepath.Join(filepath.Dir(inputDir), "assets", "real.png"), link); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+
+	NewImageProcessor(NewCopyManager(OSFileSystem{}), inputDir, outputDir).ProcessContent(context.Background(), "![x](../assets/link.png)")
+
+	info, err := os.Lstat(filepath.Join(outputDir, "link.png"))
+	if err != nil {
+		t.Fatalf("link.png was not copied: %v", err)
+	}
+	if info.Mode()&os.ModeSymlink != 0 || info.Size() != int64(len("data of real.png")) {
+		t.Errorf("link.png should be a regular copy of real.png")
+	}
+}
+
+// BenchmarkProcessContent measures rewriting and copying the media of an image-heavy post
+func BenchmarkProcessContent(b *testing.B) {
+	graph := NewMemFileSystem(nil)
+	var builder strings.Builder
+	for i := 0; i < 500; i++ {
+		name := fmt.Sprintf("image_%d.png", i)
+		graph.WriteFile(filepath.Join("graph", "assets", name), make([]byte, 64*1024))
+		fmt.Fprintf(&builder, "Paragraph %d\n\n![image %d](../assets/%s){:height 446, :width 778}\n\n", i, i, name)
+	}
+	graph.WriteFile(filepath.Join("graph", "assets", "clip.mp4"), make([]byte, 64*1024))
+	builder.WriteString("![clip](../assets/clip.mp4)\n")
+	content := builder.String()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		fsys := NewMemFileSystem(graph)
+		fsys.MkdirAll("out", 0755)
+		NewImageProcessor(NewCopyManager(fsys), filepath.Join("graph", "journals"), "out").ProcessContent(context.Background(), content)
+	}
+}
+
+// TestProcessContent_Tracks tests copying GPX tracks and replacing them with the map shortcode
+func TestProcessContent_Tracks(t *testing.T) {
+	inputDir := setupGraph(t, "route.gpx", "Day2.GPX", "photo.png")
+	outputDir := t.TempDir()
+
+	processor := NewImageProcessor(NewCopyManager(OSFileSystem{}), inputDir, outputDir)
+	processor.trackShortcode = "gpx"
+	got := processor.ProcessContent(context.Background(), "![route.gpx](../assets/route.gpx)\nSee [day 2](../assets/Day2.GPX) and ![photo](../assets/photo.png)")
+
+	want := "{{< gpx src=\"route.gpx\" >}}\nSee {{< gpx src=\"Day2.GPX\" >}} and ![photo](photo.png)"
+	if got != want {
+		t.Errorf("ProcessContent() = %q, want %q", got, want)
+	}
+	for _, name := range []string{"route.gpx", "Day2.GPX"} {
+		if _, err := os.Stat(filepath.Join(outputDir, name)); err != nil {
+			t.Errorf("Expected track %s in the bundle: %v", name, err)
+		}
+	}
+
+	if match, _ := processor.FirstImage("![route.gpx](../assets/route.gpx) ![photo](../assets/photo.png)"); match != "![photo](../assets/photo.png)" {
+		t.Errorf("FirstImage() = %q, want the photo", match)
+	}
+}
+
+// TestProcessContent_Code tests that media references in code blocks and code spans are kept as examples
+func TestProcessContent_Code(t *testing.T) {
+	inputDir := setupGraph(t, "photo.png", "example.png")
+	outputDir := t.TempDir()
+
+	processor := NewImageProcessor(NewCopyManager(OSFileSystem{}), inputDir, outputDir)
+	processor.trackShortcode = "gpx"
+	content := "Logseq writes `![example](../assets/example.png)` for pasted images:\n\n" +
+		"```markdown\n![example](../assets/example.png)\n[track](../assets/route.gpx)\n```\n\n" +
+		"- In a list\n  ~~~\n  ![example](../assets/example.png)\n  ~~~\n\n" +
+		"![photo](../assets/photo.png)"
+	got := processor.ProcessContent(context.Background(), content)
+
+	want := strings.Replace(content, "![photo](../assets/photo.png)", "![photo](photo.png)", 1)
+	if got != want {
+		t.Errorf("ProcessContent() = %q, want %q", got, want)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "example.png")); !os.IsNotExist(err) {
+		t.Errorf("example.png of the code was copied: %v", err)
+	}
+
+	if match, _ := processor.FirstImage(content); match != "![photo](../assets/photo.png)" {
+		t.Errorf("FirstImage() = %q, want the photo", match)
+	}
+}
+
+// TestProcessContent_EncodedNames tests assets whose names are percent-encoded in the references
+func TestProcessContent_EncodedNames(t *testing.T) {
+	inputDir := setupGraph(t, "image name.png", "Hafen (2).jpg", "50%25.png", "clip 1.mp4")
+	outputDir := t.TempDir()
+
+	processor := NewImageProcessor(NewCopyManager(OSFileSystem{}), inputDir, outputDir)
+	got := processor.ProcessContent(context.Background(),
+		"![a](../assets/image%20name.png) ![b](../assets/Hafen%20%282%29.jpg) ![c](../assets/50%25.png) ![clip 1.mp4](../assets/clip%201.mp4)")
+
+	want := `![a](image%20name.png) ![b](Hafen%20%282%29.jpg) ![c](50%25.png) {{< video src="clip 1.mp4" >}}`
+	if got != want {
+		t.Errorf("ProcessContent() = %q, want %q", got, want)
+	}
+	// A file with the literal name is used before the decoded one ("50%25.png")
+	for _, name := range []string{"image name.png", "Hafen (2).jpg", "50%.png", "clip 1.mp4"} {
+		if _, err := os.Stat(filepath.Join(outputDir, name)); err != nil {
+			t.Errorf("Expected %s to be copied: %v", name, err)
+		}
+	}
+
+	processor.ProcessHeaderImage(context.Background(), "../assets/image%20name.png")
+	if _, err := os.Stat(filepath.Join(outputDir, "featured.png")); err != nil {
+		t.Errorf("Expected the header image to be copied: %v", err)
+	}
+}
+
+// TestProcessContent_Images tests collecting the images copied into the bundle
+func TestProcessContent_Images(t *testing.T) {
+	inputDir := setupGraph(t, "hafen.jpg", "boot.png", "clip.mp4", "route.gpx", "plan.svg")
+
+	processor := NewImageProcessor(NewCopyManager(OSFileSystem{}), inputDir, t.TempDir())
+	processor.trackShortcode = "gpx"
+	processor.ProcessContent(context.Background(),
+		"![](../assets/boot.png) ![](../assets/clip.mp4) [Route](../assets/route.gpx)\n"+
+			"![again](../assets/boot.png) <img src=\"../assets/plan.svg\">\n![](https://example.com/a.png)\n![](../assets/hafen.jpg)")
+
+	want := []string{"boot.png", "hafen.jpg", "plan.svg"}
+	if got := processor.Images(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Images() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,57 @@
+// This file handles CPU and memory profiling of a conversion run.
+// The profiles can be inspected with "go tool pprof".
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+// startProfiling starts a CPU profile (if cpuPath is set) and returns a function
+// that stops it and writes a heap profile (if memPath is set).
+// The returned function must be called once the conversion is done.
+func startProfiling(cpuPath, memPath string) (func(), error) {
+	var cpuFile *os.File
+	if cpuPath != "" {
+		f, err := os.Create(cpuPath)
+		if err != nil {
+			return nil, fmt.Errorf("creating CPU profile: %w", err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("starting CPU profile: %w", err)
+		}
+		cpuFile = f
+	}
+
+	stop := func() {
+		if cpuFile != nil {
+			pprof.StopCPUProfile()
+			cpuFile.Close()
+		}
+		if memPath != "" {
+			if err := writeHeapProfile(memPath); err != nil {
+				fmt.Printf("Warning: %v\n", err)
+			}
+		}
+	}
+	return stop, nil
+}
+
+// writeHeapProfile writes the current heap profile to path.
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating memory profile: %w", err)
+	}
+	defer f.Close()
+
+	// Collect garbage first, so the profile shows the memory that is still in use
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		return fmt.Errorf("writing memory profile: %w", err)
+	}
+	return nil
+}
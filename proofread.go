@@ -0,0 +1,319 @@
+// This file handles proofreading the posts before they are published.
+// The words of a post are looked up in a word list of its language
+// (hunspell-style .dic files), or a large language model checks the spelling
+// and grammar. The issues are printed and listed in the migration report;
+// the posts are converted anyway.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/openai/openai-go"
+)
+
+// Proofreading methods of ProofreadConfig.Method.
+const (
+	ProofreadDictionary = "dictionary" // Look up the words in the word lists of the languages
+	ProofreadLLM        = "llm"        // Ask the language model
+)
+
+// DefaultProofreadCache is the cache file of the proofreading answers of the model if none is configured.
+const DefaultProofreadCache = ".proofread-cache.json"
+
+// ProofreadIssue is a typo or grammar issue found in a post.
+type ProofreadIssue struct {
+	Text       string `json:"text"`       // The wrong word or phrase
+	Suggestion string `json:"suggestion"` // The correction (empty if unknown)
+	Message    string `json:"message"`    // What is wrong ("unknown word", "subject-verb agreement")
+}
+
+// String formats the issue for warnings and the report: "Wanderug" -> "Wanderung": spelling
+func (i ProofreadIssue) String() string {
+	text := strconv.Quote(i.Text)
+	if i.Suggestion != "" {
+		text += " -> " + strconv.Quote(i.Suggestion)
+	}
+	if i.Message != "" {
+		text += ": " + i.Message
+	}
+	return text
+}
+
+// ProofreadResult are the issues found in one post.
+type ProofreadResult struct {
+	Title    string
+	Language string
+	Issues   []ProofreadIssue
+}
+
+// proofreadFunc checks the spelling and grammar of a post in the given language (e.g. "german").
+type proofreadFunc func(ctx context.Context, title, text, language string) ([]ProofreadIssue, error)
+
+// Proofreader checks the spelling (and with the language model the grammar) of posts.
+type Proofreader struct {
+	dictionaries map[string]*dictionary // Word lists by language (dictionary method)
+	ignore       map[string]bool        // Words that are always right, lowercase
+	cache        *llmCache              // Issues by text hash and language (model method)
+	proofread    proofreadFunc          // Asks the model (nil for the dictionary method)
+	warned       map[string]bool        // Languages without a word list that were warned about
+}
+
+// NewProofreader creates a Proofreader for the configured method.
+// The dictionary method reads the word lists, the model method the cache
+// and needs proofread to ask the model.
+func NewProofreader(fsys FileSystem, config ProofreadConfig, proofread proofreadFunc) (*Proofreader, error) {
+	p := &Proofreader{
+		dictionaries: make(map[string]*dictionary),
+		ignore:       make(map[string]bool),
+		warned:       make(map[string]bool),
+	}
+	for _, word := range config.Ignore {
+		p.ignore[strings.ToLower(word)] = true
+	}
+
+	switch config.Method {
+	case ProofreadDictionary, "":
+		for language, path := range config.Dictionaries {
+			data, err := readFile(fsys, path)
+			if err != nil {
+				return nil, fmt.Errorf("proofreading: reading dictionary: %w", err)
+			}
+			p.dictionaries[llmLanguage(language)] = parseDictionary(string(data))
+		}
+	case ProofreadLLM:
+		if proofread == nil {
+			return nil, fmt.Errorf("proofreading: no language model")
+		}
+		cache, err := loadLLMCache(fsys, config.Cache)
+		if err != nil {
+			return nil, fmt.Errorf("proofreading: %w", err)
+		}
+		p.cache, p.proofread = cache, proofread
+	default:
+		return nil, fmt.Errorf("unknown proofreading method %q (use %q or %q)", config.Method, ProofreadDictionary, ProofreadLLM)
+	}
+	return p, nil
+}
+
+// Check returns the issues of a post's content in a language.
+// Code, link targets and shortcodes are not checked.
+func (p *Proofreader) Check(ctx context.Context, title, content, language string) ([]ProofreadIssue, error) {
+	text := proseText(content)
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+
+	var issues []ProofreadIssue
+	if p.proofread != nil {
+		found, err := p.askModel(ctx, title, text, language)
+		if err != nil {
+			return nil, err
+		}
+		issues = found
+	} else {
+		words := p.dictionaries[language]
+		if words == nil {
+			if !p.warned[language] {
+				fmt.Printf("Warning: no dictionary for %s, posts in %s are not proofread\n", language, language)
+				p.warned[language] = true
+			}
+			return nil, nil
+		}
+		issues = words.unknownWords(text)
+	}
+
+	// Names and other words the site uses on purpose
+	var result []ProofreadIssue
+	for _, issue := range issues {
+		if !p.ignore[strings.ToLower(issue.Text)] {
+			result = append(result, issue)
+		}
+	}
+	return result, nil
+}
+
+// askModel returns the issues of a text, from the cache or from the model.
+func (p *Proofreader) askModel(ctx context.Context, title, text, language string) ([]ProofreadIssue, error) {
+	key := llmCacheKey([]byte(title+"\n"+text), language)
+	var issues []ProofreadIssue
+	if cached, ok := p.cache.get(key); ok && json.Unmarshal([]byte(cached), &issues) == nil {
+		return issues, nil
+	}
+
+	issues, err := p.proofread(ctx, title, text, language)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(issues)
+	if err != nil {
+		return nil, err
+	}
+	p.cache.set(key, string(data))
+	return issues, nil
+}
+
+// Save writes the cache file if the model was asked.
+func (p *Proofreader) Save() error {
+	if p.cache == nil {
+		return nil
+	}
+	if err := p.cache.save(); err != nil {
+		return fmt.Errorf("proofreading: %w", err)
+	}
+	return nil
+}
+
+// Patterns of the parts of the content that are no prose.
+var (
+	proseCodeBlockRegex = regexp.MustCompile("(?ms)^\\s*```.*?^\\s*```[^\\n]*$")
+	proseCodeRegex      = regexp.MustCompile("`[^`\\n]*`")
+	proseShortcodeRegex = regexp.MustCompile(`\{\{[<%].*?[%>]\}\}`)
+	proseLinkRegex      = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	prosePageLinkRegex  = regexp.MustCompile(`\[\[([^\]]*)\]\]`)
+	proseURLRegex       = regexp.MustCompile(`\b(?:https?|ftp)://\S+|\bwww\.\S+`)
+	proseHTMLRegex      = regexp.MustCompile(`<[^>\n]+>`)
+	proseTagRegex       = regexp.MustCompile(`(^|\s)#[^\s#]+`)
+)
+
+// proseText returns the text of the content that is read: code, shortcodes,
+// URLs, HTML tags and tags are removed, links and images keep their text.
+func proseText(content string) string {
+	content = proseCodeBlockRegex.ReplaceAllString(content, "")
+	content = proseCodeRegex.ReplaceAllString(content, "")
+	content = proseShortcodeRegex.ReplaceAllString(content, "")
+	content = proseLinkRegex.ReplaceAllString(content, "$1")
+	content = prosePageLinkRegex.ReplaceAllString(content, "$1")
+	content = proseURLRegex.ReplaceAllString(content, "")
+	content = proseHTMLRegex.ReplaceAllString(content, "")
+	return proseTagRegex.ReplaceAllString(content, "$1")
+}
+
+// wordRegex matches a word, including apostrophes and hyphens inside of it.
+var wordRegex = regexp.MustCompile(`[\p{L}\p{N}][\p{L}\p{M}\p{N}'’-]*[\p{L}\p{M}\p{N}]|[\p{L}\p{N}]`)
+
+// dictionary is the word list of a language.
+type dictionary struct {
+	words map[string]bool
+}
+
+// parseDictionary reads a word list: one word per line, like the .dic files of
+// hunspell. The word count in the first line and the affix flags after a "/"
+// are ignored, so the affixes are not expanded: use a list of all word forms
+// (e.g. the output of hunspell's unmunch).
+func parseDictionary(data string) *dictionary {
+	d := &dictionary{words: make(map[string]bool)}
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if i == 0 {
+			if _, err := strconv.Atoi(line); err == nil {
+				continue
+			}
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		word, _, _ := strings.Cut(line, "/")
+		word, _, _ = strings.Cut(word, "\t")
+		d.words[strings.TrimSpace(word)] = true
+	}
+	return d
+}
+
+// unknownWords returns the words of a text that are not in the dictionary,
+// every word once in the order they first appear.
+func (d *dictionary) unknownWords(text string) []ProofreadIssue {
+	var issues []ProofreadIssue
+	seen := make(map[string]bool)
+	for _, word := range wordRegex.FindAllString(text, -1) {
+		if seen[word] || !d.checkable(word) || d.knows(word) {
+			continue
+		}
+		seen[word] = true
+		issues = append(issues, ProofreadIssue{Text: word, Message: "unknown word"})
+	}
+	return issues
+}
+
+// checkable reports whether a word is looked up: numbers, single letters and
+// abbreviations in capitals (like "GPX") are not.
+func (d *dictionary) checkable(word string) bool {
+	if len([]rune(word)) < 2 || strings.IndexFunc(word, unicode.IsDigit) >= 0 {
+		return false
+	}
+	return strings.ToUpper(word) != word
+}
+
+// knows reports whether a word is in the dictionary. A word in lowercase in
+// the dictionary may start with a capital (at the beginning of a sentence),
+// words with hyphens are right if all their parts are, and possessives
+// ("Peter's") if the word without them is.
+func (d *dictionary) knows(word string) bool {
+	if d.words[word] || d.words[lowerFirst(word)] {
+		return true
+	}
+	if before, _, ok := strings.Cut(strings.ReplaceAll(word, "’", "'"), "'"); ok && before != "" && d.knows(before) {
+		return true
+	}
+	if strings.Contains(word, "-") {
+		for _, part := range strings.Split(word, "-") {
+			if part != "" && d.checkable(part) && !d.knows(part) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// lowerFirst returns a word with its first letter in lowercase.
+func lowerFirst(word string) string {
+	for i, r := range word {
+		return string(unicode.ToLower(r)) + word[i+len(string(r)):]
+	}
+	return word
+}
+
+// proofreadPost asks the model for the typos and grammar issues of a post.
+func (c *llmClient) proofreadPost(ctx context.Context, title, text, language string) ([]ProofreadIssue, error) {
+	instructions := fmt.Sprintf(`You proofread blog posts written in %s.
+Report only clear spelling and grammar mistakes, no matters of style, and not the names of people and places.
+For every mistake, "text" is the wrong word or phrase exactly as it appears in the post, "suggestion" the correction,
+and "message" a short explanation in English.
+Return ONLY a JSON array like [{"text": "...", "suggestion": "...", "message": "..."}], or [] if there are no mistakes.`, language)
+
+	answer, err := c.complete(ctx, instructions, []openai.ChatCompletionContentPartUnionParam{
+		openai.TextContentPart("# " + title + "\n\n" + text),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parseProofreadAnswer(answer)
+}
+
+// parseProofreadAnswer reads the JSON array answered by the model,
+// which is sometimes wrapped in a code block or a sentence.
+func parseProofreadAnswer(answer string) ([]ProofreadIssue, error) {
+	start, end := strings.Index(answer, "["), strings.LastIndex(answer, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("unexpected answer %q", answer)
+	}
+	var issues []ProofreadIssue
+	if err := json.Unmarshal([]byte(answer[start:end+1]), &issues); err != nil {
+		return nil, fmt.Errorf("unexpected answer %q: %w", answer, err)
+	}
+
+	// Issues without the wrong text can't be found in the post
+	var result []ProofreadIssue
+	for _, issue := range issues {
+		if issue.Text = strings.TrimSpace(issue.Text); issue.Text != "" {
+			result = append(result, issue)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestProseText tests removing the parts of the content that are no prose
+func TestProseText(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"plain text", "Wir sind gewandert.", "Wir sind gewandert."},
+		{"code block", "Text\n\n```go\nfunc main() {}\n```\n\nMore", "Text\n\n\nMore"},
+		{"inline code", "Run `go test` now", "Run  now"},
+		{"shortcode", "{{< toc >}}\n\nText", "\n\nText"},
+		{"link keeps its text", "See [the trip](https://example.com/trip)", "See the trip"},
+		{"image keeps its alt text", "![Sunset at the lake](./sunset.jpg)", "Sunset at the lake"},
+		{"page link", "See [[Sailing Trips]]", "See Sailing Trips"},
+		{"url", "Visit https://example.com/a?b=c today", "Visit  today"},
+		{"html", "A<br>B <span class=\"x\">C</span>", "AB C"},
+		{"tag", "Hiking #outdoor #fun", "Hiking  "},
+		{"heading", "# Title", "# Title"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := proseText(tt.content); got != tt.want {
+				t.Errorf("proseText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDictionary tests looking up the words of a text in a word list
+func TestDictionary(t *testing.T) {
+	dict := parseDictionary("10\n# comment\nthe/S\nhike\nlake\tpo:noun\nsunset\nBerlin\nwell-known\nday\ntrip\nPeter\nat\n")
+
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"known words", "The hike", nil},
+		{"unknown word", "The hkie at the lake", []string{"hkie"}},
+		{"every word once", "hkie hkie", []string{"hkie"}},
+		{"capital only in the dictionary", "berlin", []string{"berlin"}},
+		{"hyphenated words", "day-trip", nil},
+		{"hyphenated unknown part", "day-tirp", []string{"day-tirp"}},
+		{"possessive", "Peter's hike", nil},
+		{"numbers and abbreviations", "GPX 2025 3km a", nil},
+		{"umlauts", "Wanderüng", []string{"Wanderüng"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, issue := range dict.unknownWords(tt.text) {
+				got = append(got, issue.Text)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("unknownWords() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestProofreader_LLM tests proofreading with the model, its cache and the ignored words
+func TestProofreader_LLM(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	calls := 0
+	proofread := func(ctx context.Context, title, text, language string) ([]ProofreadIssue, error) {
+		calls++
+		if language != "german" {
+			return nil, errors.New("unexpected language " + language)
+		}
+		return []ProofreadIssue{
+			{Text: "Renan", Message: "unknown name"},
+			{Text: "Wir ist", Suggestion: "Wir sind", Message: "subject-verb agreement"},
+		}, nil
+	}
+
+	config := ProofreadConfig{Method: ProofreadLLM, Cache: "cache.json", Ignore: []string{"renan"}}
+	proofreader, err := NewProofreader(fsys, config, proofread)
+	if err != nil {
+		t.Fatalf("NewProofreader() error = %v", err)
+	}
+
+	want := []ProofreadIssue{{Text: "Wir ist", Suggestion: "Wir sind", Message: "subject-verb agreement"}}
+	for i := 0; i < 2; i++ {
+		issues, err := proofreader.Check(context.Background(), "Renan", "Wir ist nach Renan gewandert.", "german")
+		if err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+		if !reflect.DeepEqual(issues, want) {
+			t.Errorf("Check() = %+v, want %+v", issues, want)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Model asked %d times, want 1 (cached)", calls)
+	}
+
+	if err := proofreader.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := readFile(fsys, "cache.json"); err != nil {
+		t.Errorf("Cache not written: %v", err)
+	}
+
+	if _, err := NewProofreader(fsys, ProofreadConfig{Method: "aspell"}, nil); err == nil {
+		t.Error("NewProofreader() with an unknown method should fail")
+	}
+	if _, err := NewProofreader(fsys, ProofreadConfig{Method: ProofreadLLM}, nil); err == nil {
+		t.Error("NewProofreader() with the model method and no model should fail")
+	}
+}
+
+// TestParseProofreadAnswer tests reading the issues answered by the model
+func TestParseProofreadAnswer(t *testing.T) {
+	got, err := parseProofreadAnswer("```json\n[{\"text\": \"Wanderug\", \"suggestion\": \"Wanderung\", \"message\": \"spelling\"}, {\"text\": \" \"}]\n```")
+	if err != nil {
+		t.Fatalf("parseProofreadAnswer() error = %v", err)
+	}
+	want := []ProofreadIssue{{Text: "Wanderug", Suggestion: "Wanderung", Message: "spelling"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseProofreadAnswer() = %+v, want %+v", got, want)
+	}
+
+	if _, err := parseProofreadAnswer("No mistakes found."); err == nil {
+		t.Error("parseProofreadAnswer() without array should fail")
+	}
+}
@@ -0,0 +1,97 @@
+// This file handles the publish log, which records when and from what source
+// a post was generated: a history file in the bundle with every publish event,
+// and the latest event in the front matter.
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"time"
+)
+
+// DefaultPublishLog is the name of the history file in the bundle.
+// Hugo doesn't publish files starting with a dot.
+const DefaultPublishLog = ".publish-log.json"
+
+// PublishEvent is a conversion that changed a post.
+type PublishEvent struct {
+	Time       string `json:"time"`        // When the post was written (RFC 3339)
+	SourceHash string `json:"source_hash"` // SHA-256 of the post as extracted from the graph
+	Version    string `json:"version"`     // Version of the converter
+}
+
+// sourceHash returns the SHA-256 of the properties and blocks of a post as
+// they were extracted, so edits in the graph change the hash and the
+// processing of the converter doesn't.
+func sourceHash(post *BlogPost) string {
+	data, err := json.Marshal(struct {
+		Meta    BlogMeta
+		Content []ContentBlock
+	}{post.Meta, post.Content})
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// readPublishLog reads the history file of a bundle. A missing file is an empty history.
+func readPublishLog(fsys FileSystem, path string) ([]PublishEvent, error) {
+	data, err := readFile(fsys, path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var events []PublishEvent
+	if err := json.Unmarshal(data, &events); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return events, nil
+}
+
+// publishEvent returns the event of a post about to be written to outputDir
+// and the history with it. A post that didn't change since the last event
+// (same source and converter version) keeps the last event, so converting
+// it again writes the same front matter. The history is nil then.
+func (c *Converter) publishEvent(post *BlogPost, outputDir string) (*PublishEvent, []PublishEvent, error) {
+	events, err := readPublishLog(c.fs, filepath.Join(outputDir, c.config.PublishLog.File))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	version := toolVersion()
+	if n := len(events); n > 0 && events[n-1].SourceHash == post.SourceHash && events[n-1].Version == version {
+		return &events[n-1], nil, nil
+	}
+	event := PublishEvent{
+		Time:       c.now().Format(time.RFC3339),
+		SourceHash: post.SourceHash,
+		Version:    version,
+	}
+	events = append(events, event)
+	return &event, events, nil
+}
+
+// writePublishLog writes the history file of a bundle.
+func (c *Converter) writePublishLog(events []PublishEvent, outputDir string) error {
+	data, err := json.MarshalIndent(events, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := c.fs.Create(filepath.Join(outputDir, c.config.PublishLog.File))
+	if err != nil {
+		return fmt.Errorf("writing publish log: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("writing publish log: %w", err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestSourceHash tests that the hash changes with the source of a post
+func TestSourceHash(t *testing.T) {
+	post := &BlogPost{Meta: BlogMeta{Title: "Renan", Date: "2026-01-17"}, Content: []ContentBlock{{Text: "Hiking"}}}
+	hash := sourceHash(post)
+	if len(hash) != 64 {
+		t.Fatalf("sourceHash() = %q, want a SHA-256 in hex", hash)
+	}
+
+	post.Slug = "2026-01-17_Renan"
+	if got := sourceHash(post); got != hash {
+		t.Errorf("sourceHash() changed with the bundle name")
+	}
+	post.Content[0].Text = "Hiking in the Jura"
+	if got := sourceHash(post); got == hash {
+		t.Errorf("sourceHash() didn't change with the content")
+	}
+}
+
+// TestPublishEvent tests when a new event is added to the history of a bundle
+func TestPublishEvent(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	fsys.MkdirAll("bundle", 0755)
+	config := DefaultConfig()
+	converter := NewConverter(config, fsys)
+	converter.now = func() time.Time { return time.Date(2026, 1, 17, 10, 0, 0, 0, time.UTC) }
+	post := &BlogPost{SourceHash: "aaa"}
+	version := toolVersion()
+
+	// First conversion
+	event, history, err := converter.publishEvent(post, "bundle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := PublishEvent{Time: "2026-01-17T10:00:00Z", SourceHash: "aaa", Version: version}
+	if *event != first || !reflect.DeepEqual(history, []PublishEvent{first}) {
+		t.Fatalf("publishEvent() = %+v, %+v, want %+v", event, history, first)
+	}
+	if err := converter.writePublishLog(history, "bundle"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Unchanged post keeps the event
+	converter.now = func() time.Time { return time.Date(2026, 2, 1, 10, 0, 0, 0, time.UTC) }
+	event, history, err = converter.publishEvent(post, "bundle")
+	if err != nil || *event != first || history != nil {
+		t.Errorf("publishEvent() of an unchanged post = %+v, %+v, %v, want the first event and no history", event, history, err)
+	}
+
+	// Changed post gets a new event
+	post.SourceHash = "bbb"
+	event, history, err = converter.publishEvent(post, "bundle")
+	second := PublishEvent{Time: "2026-02-01T10:00:00Z", SourceHash: "bbb", Version: version}
+	if err != nil || *event != second || !reflect.DeepEqual(history, []PublishEvent{first, second}) {
+		t.Errorf("publishEvent() of a changed post = %+v, %+v, %v, want %+v appended", event, history, err, second)
+	}
+
+	// A broken history file is an error, not an empty history
+	fsys.WriteFile(filepath.Join("broken", DefaultPublishLog), []byte("{"))
+	if _, _, err := converter.publishEvent(post, "broken"); err == nil {
+		t.Error("publishEvent() with a broken history file should fail")
+	}
+}
@@ -0,0 +1,115 @@
+// This file handles Logseq queries in the posts:
+//
+//	{{query (and #hiking #alps)}}
+//
+//	#+BEGIN_QUERY
+//	{:query [:find (pull ?b [*]) :where ...]}
+//	#+END_QUERY
+//
+// Their results only exist in Logseq, so they are removed or replaced by a
+// placeholder. Simple tag queries can be executed against the converted posts.
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// queryMacroRegex matches a query macro: {{query (and #hiking #alps)}}.
+var queryMacroRegex = regexp.MustCompile(`(?i)\{\{\s*query(\s[^{}]*)?\}\}`)
+
+// orgQueryRegex matches an advanced query block from #+BEGIN_QUERY to #+END_QUERY.
+var orgQueryRegex = regexp.MustCompile(`(?ims)^[ \t]*#\+BEGIN_QUERY[ \t]*$.*?^[ \t]*#\+END_QUERY[ \t]*$`)
+
+// queryTagRegex matches a tag of a simple query: #hiking, #[[long tag]] or [[hiking]].
+var queryTagRegex = regexp.MustCompile(`#\[\[([^\]]+)\]\]|#([^\s#\[\]()]+)|\[\[([^\]]+)\]\]`)
+
+// parseTagQuery returns the tags of a simple tag query: a single tag or
+// (and ...) with tags only. Other queries (full text, or, dates, ...) are not simple.
+func parseTagQuery(query string) ([]string, bool) {
+	query = strings.TrimSpace(query)
+	if inner, ok := strings.CutPrefix(query, "(and "); ok && strings.HasSuffix(inner, ")") {
+		query = strings.TrimSuffix(inner, ")")
+	}
+
+	var tags []string
+	last := 0
+	for _, loc := range queryTagRegex.FindAllStringSubmatchIndex(query, -1) {
+		if strings.TrimSpace(query[last:loc[0]]) != "" {
+			return nil, false
+		}
+		for i := 2; i < len(loc); i += 2 {
+			if loc[i] >= 0 {
+				tags = append(tags, strings.ToLower(strings.TrimSpace(query[loc[i]:loc[i+1]])))
+			}
+		}
+		last = loc[1]
+	}
+	if len(tags) == 0 || strings.TrimSpace(query[last:]) != "" {
+		return nil, false
+	}
+	return tags, true
+}
+
+// buildTagIndex maps the lowercase tags of the posts to the posts with the tag, in the order of the posts.
+func buildTagIndex(posts []*BlogPost) map[string][]*BlogPost {
+	index := make(map[string][]*BlogPost)
+	for _, post := range posts {
+		for _, tag := range post.Meta.Tags {
+			tag = strings.ToLower(tag)
+			if !slices.Contains(index[tag], post) {
+				index[tag] = append(index[tag], post)
+			}
+		}
+	}
+	return index
+}
+
+// convertQueries replaces the queries of a block of the post: executed tag
+// queries become a list of links, the others the placeholder (or nothing).
+// Queries in code blocks and code spans are examples and are kept.
+func (c *Converter) convertQueries(post *BlogPost, block string) string {
+	if !strings.Contains(block, "{{") && !strings.Contains(strings.ToUpper(block), "#+BEGIN_QUERY") {
+		return block
+	}
+	placeholder := c.config.Queries.Placeholder
+
+	return replaceOutsideCode(block, func(text string) string {
+		text = orgQueryRegex.ReplaceAllLiteralString(text, placeholder)
+		return queryMacroRegex.ReplaceAllStringFunc(text, func(match string) string {
+			if c.config.Queries.Execute {
+				if tags, ok := parseTagQuery(queryMacroRegex.FindStringSubmatch(match)[1]); ok {
+					if list := queryResults(c.taggedPosts(tags, post)); list != "" {
+						return list
+					}
+				}
+			}
+			return placeholder
+		})
+	})
+}
+
+// taggedPosts returns the converted posts with all tags, except the post with the query.
+func (c *Converter) taggedPosts(tags []string, post *BlogPost) []*BlogPost {
+	var results []*BlogPost
+	for _, candidate := range c.tagged[tags[0]] {
+		if candidate == post {
+			continue
+		}
+		if !slices.ContainsFunc(tags[1:], func(tag string) bool { return !slices.Contains(c.tagged[tag], candidate) }) {
+			results = append(results, candidate)
+		}
+	}
+	return results
+}
+
+// queryResults renders the results of a query as a list of links to the posts.
+func queryResults(posts []*BlogPost) string {
+	lines := make([]string, 0, len(posts))
+	for _, post := range posts {
+		lines = append(lines, fmt.Sprintf(`- [%s]({{< relref "%s" >}})`, post.Meta.Title, post.BundlePath()))
+	}
+	return strings.Join(lines, "\n")
+}
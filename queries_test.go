@@ -0,0 +1,103 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParseTagQuery tests recognizing simple tag queries
+func TestParseTagQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"#hiking", []string{"hiking"}},
+		{" [[Hiking]] ", []string{"hiking"}},
+		{"#[[Long Tag]]", []string{"long tag"}},
+		{"(and #hiking [[Alps]])", []string{"hiking", "alps"}},
+		{"(or #hiking #alps)", nil},
+		{"(and #hiking (between -7d today))", nil},
+		{"\"full text\"", nil},
+		{"hiking", nil},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, ok := parseTagQuery(tt.query)
+			if ok != (tt.want != nil) || strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("parseTagQuery(%q) = %v, %v, want %v", tt.query, got, ok, tt.want)
+			}
+		})
+	}
+}
+
+// TestConvertQueries tests removing queries and executing simple tag queries
+func TestConvertQueries(t *testing.T) {
+	post := &BlogPost{Meta: BlogMeta{Title: "Tour", Tags: []string{"hiking"}}, Section: "posts", Slug: "tour"}
+	alps := &BlogPost{Meta: BlogMeta{Title: "Alps", Tags: []string{"Hiking", "alps"}}, Section: "posts", Slug: "alps"}
+	jura := &BlogPost{Meta: BlogMeta{Title: "Jura", Tags: []string{"hiking"}}, Section: "posts", Slug: "jura"}
+	tagged := buildTagIndex([]*BlogPost{post, alps, jura})
+
+	advanced := "#+BEGIN_QUERY\n{:query [:find (pull ?b [*]) :where [?b :block/marker]]}\n#+END_QUERY"
+	tests := []struct {
+		name, block, placeholder string
+		execute                  bool
+		want                     string
+	}{
+		{
+			name:  "removed",
+			block: "Tours: {{query #hiking}}",
+			want:  "Tours: ",
+		},
+		{
+			name:        "placeholder",
+			block:       "Open tasks:\n" + advanced,
+			placeholder: "<!-- Logseq query -->",
+			want:        "Open tasks:\n<!-- Logseq query -->",
+		},
+		{
+			name:    "executed",
+			block:   "{{query [[hiking]]}}",
+			execute: true,
+			want:    "- [Alps]({{< relref \"posts/alps\" >}})\n- [Jura]({{< relref \"posts/jura\" >}})",
+		},
+		{
+			name:    "all tags",
+			block:   "{{query (and #hiking #alps)}}",
+			execute: true,
+			want:    "- [Alps]({{< relref \"posts/alps\" >}})",
+		},
+		{
+			name:        "no results",
+			block:       "{{query #sailing}}",
+			placeholder: "*(empty)*",
+			execute:     true,
+			want:        "*(empty)*",
+		},
+		{
+			name:    "not simple",
+			block:   "{{query (or #hiking #alps)}}",
+			execute: true,
+			want:    "",
+		},
+		{
+			name:  "other macros and code",
+			block: "{{query-table false}} `{{query #hiking}}`\n```\n" + advanced + "\n```",
+			want:  "{{query-table false}} `{{query #hiking}}`\n```\n" + advanced + "\n```",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Queries = QueryConfig{Placeholder: tt.placeholder, Execute: tt.execute}
+			c := NewConverter(config, OSFileSystem{})
+			c.tagged = tagged
+
+			if got := c.convertQueries(post, tt.block); got != tt.want {
+				t.Errorf("convertQueries() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+// This file handles the redirects of posts whose bundle moved (a changed
+// title changes the slug) or was removed (expired posts). The slug history in
+// the output directory records the bundle paths of the posts, the old
+// addresses are written as Hugo aliases or into a Netlify-style _redirects file:
+//
+//	/posts/2026-01-17_ibiza_trip/ /posts/2026-01-17_segeln_vor_ibiza/ 301
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// SlugHistoryFile is the name of the slug history in the output directory.
+// Hugo doesn't publish files starting with a dot.
+const SlugHistoryFile = ".slug-history.json"
+
+// SlugRecord is the bundle path of a post and its earlier bundle paths.
+type SlugRecord struct {
+	Path     string   `json:"path"`               // Current bundle path (e.g. "trips/2026-01-17_Ibiza")
+	Previous []string `json:"previous,omitempty"` // Earlier bundle paths, the oldest first
+	Removed  bool     `json:"removed,omitempty"`  // The bundle was removed (e.g. expired)
+}
+
+// slugKeys returns the keys of the posts in the slug history: the source file
+// (with its folder) and the position of the post in the file, e.g.
+// "journals/2026_01_17.md#1". The key stays the same when the title changes.
+// Posts without source file are not tracked.
+func slugKeys(posts []*BlogPost) map[*BlogPost]string {
+	keys := make(map[*BlogPost]string)
+	counts := make(map[string]int)
+	for _, post := range posts {
+		if post.SourcePath == "" {
+			continue
+		}
+		file := filepath.ToSlash(filepath.Join(filepath.Base(filepath.Dir(post.SourcePath)), filepath.Base(post.SourcePath)))
+		counts[file]++
+		keys[post] = fmt.Sprintf("%s#%d", file, counts[file])
+	}
+	return keys
+}
+
+// readSlugHistory reads the slug history of the output directory. A missing file is an empty history.
+func readSlugHistory(fsys FileSystem, outputBasePath string) (map[string]*SlugRecord, error) {
+	path := filepath.Join(outputBasePath, SlugHistoryFile)
+	history := make(map[string]*SlugRecord)
+	data, err := readFile(fsys, path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return history, nil
+}
+
+// trackSlugs records the bundle paths of the posts in the slug history. If a
+// post moved, the old path is kept and the old addresses become its aliases
+// (if enabled). A post whose path is already recorded keeps its record, so
+// when a post is removed from a file, the posts after it (whose keys shift)
+// don't count as moved. Records of posts that are gone are dropped when their key is reused.
+func (c *Converter) trackSlugs(keys map[*BlogPost]string, posts []*BlogPost) {
+	byPath := make(map[string]*SlugRecord)
+	for _, record := range c.slugs {
+		byPath[record.Path] = record
+	}
+
+	// Posts at a recorded path first, then the records of the keys count as moved posts
+	records := make(map[*BlogPost]*SlugRecord)
+	claimed := make(map[*SlugRecord]bool)
+	for _, post := range posts {
+		if record := byPath[post.BundlePath()]; record != nil && keys[post] != "" {
+			records[post] = record
+			claimed[record] = true
+		}
+	}
+	for _, post := range posts {
+		key := keys[post]
+		if key == "" || records[post] != nil {
+			continue
+		}
+		record := c.slugs[key]
+		if record == nil || claimed[record] {
+			record = &SlugRecord{Path: post.BundlePath()}
+		}
+		records[post] = record
+		claimed[record] = true
+	}
+
+	current := make(map[string]bool)
+	for _, post := range posts {
+		current[post.BundlePath()] = true
+	}
+	for _, post := range posts {
+		record := records[post]
+		if record == nil {
+			continue
+		}
+		c.slugs[keys[post]] = record
+		record.Removed = false
+		if record.Path != post.BundlePath() {
+			fmt.Printf("Moved '%s': %s -> %s\n", post.Meta.Title, record.Path, post.BundlePath())
+			record.Previous = append(record.Previous, record.Path)
+			record.Path = post.BundlePath()
+		}
+		record.Previous = slices.DeleteFunc(record.Previous, func(path string) bool { return current[path] })
+
+		// Slugs that only differ in case have the same address (Hugo writes lowercase paths)
+		if c.config.Redirects.Aliases {
+			own := urlPath(bundleURL(c.config.Redirects.URL, record.Path))
+			post.Meta.Aliases = nil
+			for _, previous := range record.Previous {
+				if alias := urlPath(bundleURL(c.config.Redirects.URL, previous)); alias != own && !slices.Contains(post.Meta.Aliases, alias) {
+					post.Meta.Aliases = append(post.Meta.Aliases, alias)
+				}
+			}
+		}
+	}
+
+	// A record claimed by a post under another key is no longer kept under its old key
+	for key, record := range c.slugs {
+		if claimed[record] && !slices.ContainsFunc(posts, func(post *BlogPost) bool { return keys[post] == key && records[post] == record }) {
+			delete(c.slugs, key)
+		}
+	}
+}
+
+// removeSlug marks the bundle of a post as removed in the slug history.
+func (c *Converter) removeSlug(key string, post *BlogPost) {
+	if key == "" {
+		return
+	}
+	if record := c.slugs[key]; record != nil {
+		record.Removed = true
+		return
+	}
+	c.slugs[key] = &SlugRecord{Path: post.BundlePath(), Removed: true}
+}
+
+// urlPath returns the path of an address ("https://example.com/posts/a/" -> "/posts/a/").
+func urlPath(address string) string {
+	if u, err := url.Parse(address); err == nil && u.Host != "" {
+		return u.EscapedPath()
+	}
+	return address
+}
+
+// redirectLines returns the redirects of the slug history: the old addresses
+// of moved posts to their current address, and the addresses of removed posts
+// to pruned (if not empty). The lines are sorted by the old address.
+func redirectLines(history map[string]*SlugRecord, base, pruned string) []string {
+	targets := make(map[string]string)
+	for _, record := range history {
+		target := bundleURL(base, record.Path)
+		paths := record.Previous
+		if record.Removed {
+			if pruned == "" {
+				continue
+			}
+			target = pruned
+			paths = append(slices.Clone(paths), record.Path)
+		}
+		for _, path := range paths {
+			if from := urlPath(bundleURL(base, path)); from != urlPath(target) {
+				targets[from] = target
+			}
+		}
+	}
+
+	lines := make([]string, 0, len(targets))
+	for from, target := range targets {
+		lines = append(lines, fmt.Sprintf("%s %s 301", from, target))
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+// writeRedirects writes the slug history and, if configured, the redirects file.
+func (c *Converter) writeRedirects(outputBasePath string) error {
+	data, err := json.MarshalIndent(c.slugs, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := c.writeOutputFile(filepath.Join(outputBasePath, SlugHistoryFile), append(data, '\n')); err != nil {
+		return err
+	}
+	if c.config.Redirects.File == "" {
+		return nil
+	}
+
+	var b strings.Builder
+	b.WriteString("# Moved and removed posts, written by logseq-to-hugo-converter from " + SlugHistoryFile + "\n")
+	for _, line := range redirectLines(c.slugs, c.config.Redirects.URL, c.config.Redirects.Pruned) {
+		b.WriteString(line + "\n")
+	}
+	return c.writeOutputFile(filepath.Join(outputBasePath, c.config.Redirects.File), []byte(b.String()))
+}
+
+// writeOutputFile writes a file of the conversion outside of the bundles (e.g. an index or history).
+func (c *Converter) writeOutputFile(path string, data []byte) error {
+	if err := c.fs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	f, err := c.fs.Create(path)
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return f.Close()
+}
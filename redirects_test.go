@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestConvertFile_Redirects tests the aliases and redirects of a post whose title changed
+func TestConvertFile_Redirects(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "journals", "2026_01_17.md")
+	os.MkdirAll(filepath.Dir(input), 0755)
+	outputDir := filepath.Join(dir, "out")
+
+	config := DefaultConfig()
+	config.Redirects = RedirectConfig{File: "_redirects", URL: "/posts/", Aliases: true}
+	convert := func(title string) {
+		t.Helper()
+		post := "type:: blog\nstatus:: online\ndate:: 2026-01-17\ntitle:: " + title + "\nauthor:: me\n\n- Wir segelten nach Ibiza.\n"
+		if err := os.WriteFile(input, []byte(post), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewConverter(config, OSFileSystem{}).ConvertFile(context.Background(), input, outputDir); err != nil {
+			t.Fatalf("ConvertFile() error = %v", err)
+		}
+	}
+
+	convert("Ibiza")
+	convert("Segeln vor Ibiza")
+	convert("Ibiza Reise")
+
+	index, err := os.ReadFile(filepath.Join(outputDir, "2026-01-17_Ibiza_Reise", "index.de.md"))
+	if err != nil {
+		t.Fatalf("Moved post was not written: %v", err)
+	}
+	if !strings.Contains(string(index), `aliases = ["/posts/2026-01-17_ibiza/", "/posts/2026-01-17_segeln_vor_ibiza/"]`) {
+		t.Errorf("Index file without aliases:\n%s", index)
+	}
+
+	redirects, err := os.ReadFile(filepath.Join(outputDir, "_redirects"))
+	if err != nil {
+		t.Fatalf("Redirects were not written: %v", err)
+	}
+	want := "/posts/2026-01-17_ibiza/ /posts/2026-01-17_ibiza_reise/ 301\n" +
+		"/posts/2026-01-17_segeln_vor_ibiza/ /posts/2026-01-17_ibiza_reise/ 301\n"
+	if !strings.HasSuffix(string(redirects), want) {
+		t.Errorf("Redirects = %q, want %q", redirects, want)
+	}
+
+	// Back to an earlier title: the address is the post's own again
+	convert("Ibiza")
+	index, _ = os.ReadFile(filepath.Join(outputDir, "2026-01-17_Ibiza", "index.de.md"))
+	if !strings.Contains(string(index), `aliases = ["/posts/2026-01-17_segeln_vor_ibiza/", "/posts/2026-01-17_ibiza_reise/"]`) {
+		t.Errorf("Index file with wrong aliases:\n%s", index)
+	}
+}
+
+// TestTrackSlugs_ShiftedPosts tests that a post removed from a file doesn't redirect the posts after it
+func TestTrackSlugs_ShiftedPosts(t *testing.T) {
+	config := DefaultConfig()
+	config.Redirects = RedirectConfig{URL: "/", Aliases: true}
+	c := NewConverter(config, OSFileSystem{})
+	c.slugs = map[string]*SlugRecord{
+		"journals/2026_01_17.md#1": {Path: "2026-01-17_Ibiza"},
+		"journals/2026_01_17.md#2": {Path: "2026-01-17_Mallorca"},
+	}
+
+	// Ibiza was removed from the file, Mallorca is the first post now
+	mallorca := &BlogPost{Meta: BlogMeta{Title: "Mallorca"}, Slug: "2026-01-17_Mallorca", SourcePath: "/graph/journals/2026_01_17.md"}
+	c.trackSlugs(slugKeys([]*BlogPost{mallorca}), []*BlogPost{mallorca})
+
+	if record := c.slugs["journals/2026_01_17.md#1"]; record.Path != "2026-01-17_Mallorca" || len(record.Previous) != 0 {
+		t.Errorf("Record = %+v, want Mallorca without previous paths", record)
+	}
+	if len(mallorca.Meta.Aliases) != 0 {
+		t.Errorf("Aliases = %v, want none", mallorca.Meta.Aliases)
+	}
+}
+
+// TestRedirectLines tests the redirects of moved and removed posts
+func TestRedirectLines(t *testing.T) {
+	history := map[string]*SlugRecord{
+		"a": {Path: "trips/2026-01-17_Segeln", Previous: []string{"trips/2026-01-17_Ibiza", "trips/2026-01-17_segeln"}},
+		"b": {Path: "2025-06-01_Alt", Previous: []string{"2025-06-01_Uralt"}, Removed: true},
+		"c": {Path: "2025-07-01_Da"},
+	}
+
+	want := []string{
+		"/posts/2025-06-01_alt/ /posts/ 301",
+		"/posts/2025-06-01_uralt/ /posts/ 301",
+		"/posts/trips/2026-01-17_ibiza/ https://example.com/posts/trips/2026-01-17_segeln/ 301",
+	}
+	got := redirectLines(history, "https://example.com/posts/", "/posts/")
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("redirectLines() = %q, want %q", got, want)
+	}
+
+	if got := redirectLines(history, "/", ""); len(got) != 1 || got[0] != "/trips/2026-01-17_ibiza/ /trips/2026-01-17_segeln/ 301" {
+		t.Errorf("redirectLines() without pruned = %q", got)
+	}
+}
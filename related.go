@@ -0,0 +1,98 @@
+// This file handles finding related posts based on shared tags and page references.
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// pageReferenceRegex matches Logseq page references: [[Page]] and #[[Page]]
+var pageReferenceRegex = regexp.MustCompile(`\[\[([^\]]+)\]\]`)
+
+// parseTagList parses a Logseq list value like "[[Sailing]], Boats, #garden"
+// into a list of tag names.
+func parseTagList(value string) []string {
+	var tags []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		item = strings.TrimPrefix(item, "#")
+		item = strings.TrimPrefix(item, "[[")
+		item = strings.TrimSuffix(item, "]]")
+		if item = strings.TrimSpace(item); item != "" {
+			tags = append(tags, item)
+		}
+	}
+	return tags
+}
+
+// pageReferences returns the pages referenced in the post content (lowercase, without duplicates).
+func pageReferences(post *BlogPost) []string {
+	seen := make(map[string]bool)
+	var pages []string
+	for _, block := range post.Content {
+		for _, match := range pageReferenceRegex.FindAllStringSubmatch(block.Text, -1) {
+			page := strings.ToLower(strings.TrimSpace(match[1]))
+			if !seen[page] {
+				seen[page] = true
+				pages = append(pages, page)
+			}
+		}
+	}
+	return pages
+}
+
+// findRelatedPosts sets meta.Related for every post to the slugs of up to max posts
+// sharing the most tags and page references. A shared tag counts twice as much as
+// a shared page reference. Ties are broken by the newer date, then by slug.
+func findRelatedPosts(posts []*BlogPost, max int) {
+	// Collect the weighted topics of each post
+	topics := make([]map[string]int, len(posts))
+	for i, post := range posts {
+		topics[i] = make(map[string]int)
+		for _, page := range pageReferences(post) {
+			topics[i][page] = 1
+		}
+		for _, tag := range post.Meta.Tags {
+			topics[i][strings.ToLower(tag)] = 2
+		}
+	}
+
+	type candidate struct {
+		post  *BlogPost
+		score int
+	}
+
+	for i, post := range posts {
+		var candidates []candidate
+		for j, other := range posts {
+			if i == j {
+				continue
+			}
+			score := 0
+			for topic, weight := range topics[i] {
+				if otherWeight, ok := topics[j][topic]; ok {
+					score += min(weight, otherWeight)
+				}
+			}
+			if score > 0 {
+				candidates = append(candidates, candidate{post: other, score: score})
+			}
+		}
+
+		sort.Slice(candidates, func(a, b int) bool {
+			if candidates[a].score != candidates[b].score {
+				return candidates[a].score > candidates[b].score
+			}
+			if candidates[a].post.Meta.Date != candidates[b].post.Meta.Date {
+				return candidates[a].post.Meta.Date > candidates[b].post.Meta.Date
+			}
+			return candidates[a].post.Slug < candidates[b].post.Slug
+		})
+
+		post.Meta.Related = nil
+		for k := 0; k < len(candidates) && k < max; k++ {
+			post.Meta.Related = append(post.Meta.Related, candidates[k].post.BundlePath())
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParseTagList tests parsing Logseq tag lists
+func TestParseTagList(t *testing.T) {
+	got := parseTagList("[[Sailing]], Boats, #garden, , [[New York]]")
+	want := []string{"Sailing", "Boats", "garden", "New York"}
+
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("parseTagList() = %v, want %v", got, want)
+	}
+}
+
+// TestFindRelatedPosts tests ranking related posts by shared tags and page references
+func TestFindRelatedPosts(t *testing.T) {
+	newPost := func(date, title string, tags []string, text string) *BlogPost {
+		return &BlogPost{
+			Meta:    BlogMeta{Date: date, Title: title, Tags: tags},
+			Content: []ContentBlock{{Text: text}},
+			Slug:    date + "_" + title,
+		}
+	}
+
+	sailing := newPost("2026-01-01", "Sailing", []string{"sailing", "croatia"}, "Went to [[Split]]")
+	charter := newPost("2026-02-01", "Charter", []string{"sailing"}, "Charter in [[Split]]")
+	croatia := newPost("2026-03-01", "Croatia", []string{"Croatia"}, "Food")
+	garden := newPost("2026-04-01", "Garden", []string{"garden"}, "Tomatoes")
+
+	findRelatedPosts([]*BlogPost{sailing, charter, croatia, garden}, 2)
+
+	// charter shares a tag and a page (3), croatia shares a tag (2)
+	want := []string{"2026-02-01_Charter", "2026-03-01_Croatia"}
+	if strings.Join(sailing.Meta.Related, "|") != strings.Join(want, "|") {
+		t.Errorf("Related = %v, want %v", sailing.Meta.Related, want)
+	}
+
+	if len(garden.Meta.Related) != 0 {
+		t.Errorf("Related = %v, want none", garden.Meta.Related)
+	}
+}
@@ -0,0 +1,388 @@
+// This file handles publishing the generated bundles to a remote target.
+// With a target, the conversion writes into memory like a dry run and the
+// files of the output directory are uploaded afterwards: to S3-compatible
+// storage or over SFTP, so a machine without the Hugo repository can publish.
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RemoteFile is a generated file to upload.
+type RemoteFile struct {
+	Name string // Path relative to the target, slash-separated (e.g. "2026-01-17_Ibiza/index.de.md")
+	Data []byte
+}
+
+// Uploader uploads the generated files to a remote target.
+type Uploader interface {
+	Upload(ctx context.Context, files []RemoteFile) error
+}
+
+// parseRemoteTarget parses and checks a target like "s3://bucket/prefix" or "sftp://user@host/dir".
+func parseRemoteTarget(target string) (*url.URL, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "s3", "sftp":
+	default:
+		return nil, fmt.Errorf("unknown target %q (use s3://bucket/prefix or sftp://user@host/dir)", target)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("target %q has no bucket or host", target)
+	}
+	return u, nil
+}
+
+// NewUploader creates the uploader of the remote configuration.
+// S3 credentials are read from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY
+// and (optional) AWS_SESSION_TOKEN.
+func NewUploader(config RemoteConfig) (Uploader, error) {
+	target, err := parseRemoteTarget(config.Target)
+	if err != nil {
+		return nil, err
+	}
+	dir := strings.Trim(target.Path, "/")
+
+	if target.Scheme == "sftp" {
+		command := config.SFTPCommand
+		if len(command) == 0 {
+			command = []string{"sftp"}
+		}
+		host := target.Hostname()
+		if target.User != nil {
+			host = target.User.Username() + "@" + host
+		}
+		return &SFTPUploader{command: command, host: host, port: target.Port(), dir: "/" + dir}, nil
+	}
+
+	accessKey, secretKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKey == "" || secretKey == "" {
+		return nil, fmt.Errorf("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are needed for %s", config.Target)
+	}
+	region := config.Region
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + region + ".amazonaws.com"
+	}
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil || endpointURL.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint %q", endpoint)
+	}
+
+	return &S3Uploader{
+		endpoint:     endpointURL,
+		bucket:       target.Host,
+		prefix:       dir,
+		region:       region,
+		pathStyle:    config.PathStyle,
+		accessKey:    accessKey,
+		secretKey:    secretKey,
+		sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		client:       http.DefaultClient,
+		now:          time.Now,
+	}, nil
+}
+
+// stateFiles returns the names of the files the converter keeps its state in
+// below the output directory: the hashes of the generated index files, the
+// slug history and the publish logs. They are no part of the site.
+func stateFiles(config *Config) []string {
+	files := []string{GeneratedFile, SlugHistoryFile}
+	if config.PublishLog.File != "" {
+		files = append(files, config.PublishLog.File)
+	}
+	return files
+}
+
+// collectUploads returns the files written into memory below outputDir, in
+// lexical order. Files written elsewhere (like the caches of generated alt
+// text and summaries) and the state files (by name) are written to local
+// instead, unless local is nil.
+func collectUploads(mem *MemFileSystem, outputDir string, state []string, local FileSystem) ([]RemoteFile, error) {
+	var files []RemoteFile
+	for _, name := range mem.Files() {
+		data, err := readFile(mem, name)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(outputDir, name)
+		if err != nil || !isInsideDir(outputDir, name) || slices.Contains(state, filepath.Base(name)) {
+			if local == nil {
+				continue
+			}
+			if err := writeLocalFile(local, name, data); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		files = append(files, RemoteFile{Name: filepath.ToSlash(rel), Data: data})
+	}
+	return files, nil
+}
+
+// writeLocalFile writes a file and its directory to a FileSystem.
+func writeLocalFile(fsys FileSystem, name string, data []byte) error {
+	if err := fsys.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	f, err := fsys.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// S3Uploader uploads files to a bucket of S3 or an S3-compatible service
+// (MinIO, Cloudflare R2, ...) with requests signed by AWS Signature Version 4.
+type S3Uploader struct {
+	endpoint     *url.URL // Service URL, e.g. https://s3.eu-central-1.amazonaws.com
+	bucket       string
+	prefix       string // Key prefix without slashes at the ends (may be empty)
+	region       string
+	pathStyle    bool // Bucket in the path instead of the host name
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	client       *http.Client
+	now          func() time.Time
+}
+
+// Upload puts the files into the bucket, one request per file.
+func (u *S3Uploader) Upload(ctx context.Context, files []RemoteFile) error {
+	for _, file := range files {
+		if err := u.put(ctx, file); err != nil {
+			return fmt.Errorf("uploading %s: %w", file.Name, err)
+		}
+	}
+	return nil
+}
+
+// put uploads one file.
+func (u *S3Uploader) put(ctx context.Context, file RemoteFile) error {
+	key := file.Name
+	if u.prefix != "" {
+		key = u.prefix + "/" + key
+	}
+
+	target := *u.endpoint
+	base := strings.TrimSuffix(u.endpoint.Path, "/")
+	if u.pathStyle {
+		target.Path = base + "/" + u.bucket + "/" + key
+	} else {
+		target.Host = u.bucket + "." + target.Host
+		target.Path = base + "/" + key
+	}
+	target.RawPath = awsURIEscape(target.Path)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target.String(), bytes.NewReader(file.Data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", mediaType(file.Name))
+	if u.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", u.sessionToken)
+	}
+	sum := sha256.Sum256(file.Data)
+	signS3Request(req, hex.EncodeToString(sum[:]), u.accessKey, u.secretKey, u.region, u.now())
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// mediaType returns the media type of a file for the web server of the bucket.
+func mediaType(name string) string {
+	if strings.HasSuffix(name, ".md") {
+		return "text/markdown; charset=utf-8"
+	}
+	if t := mime.TypeByExtension(path.Ext(name)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// signS3Request adds the x-amz-date, x-amz-content-sha256 and Authorization
+// headers of AWS Signature Version 4 to a request. All headers set before are
+// signed, together with the host.
+func signS3Request(req *http.Request, payloadHash, accessKey, secretKey, region string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		awsCanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data with key.
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// awsURIEscape escapes a path like AWS expects: everything except the
+// unreserved characters (A-Z, a-z, 0-9, "-", ".", "_", "~") and "/".
+func awsURIEscape(p string) string {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-._~/", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// awsCanonicalQuery returns the query parameters sorted and escaped like AWS expects.
+func awsCanonicalQuery(query url.Values) string {
+	var params []string
+	for name, values := range query {
+		for _, value := range values {
+			params = append(params, strings.ReplaceAll(awsURIEscape(name), "/", "%2F")+"="+strings.ReplaceAll(awsURIEscape(value), "/", "%2F"))
+		}
+	}
+	sort.Strings(params)
+	return strings.Join(params, "&")
+}
+
+// SFTPUploader uploads files with the sftp command of OpenSSH in batch mode,
+// so the keys, known hosts and ~/.ssh/config of the user are used.
+type SFTPUploader struct {
+	command []string // Program and arguments (e.g. ["sftp", "-i", "~/.ssh/blog"])
+	host    string   // "user@host" or "host"
+	port    string   // Empty for the default (or ~/.ssh/config)
+	dir     string   // Absolute directory on the server
+}
+
+// Upload stages the files in a temporary directory and copies them with one sftp session.
+func (u *SFTPUploader) Upload(ctx context.Context, files []RemoteFile) error {
+	staging, err := os.MkdirTemp("", "logseq-to-hugo-upload-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(staging)
+
+	for _, file := range files {
+		if err := writeLocalFile(OSFileSystem{}, filepath.Join(staging, filepath.FromSlash(file.Name)), file.Data); err != nil {
+			return err
+		}
+	}
+	batch := filepath.Join(staging, ".batch")
+	if err := os.WriteFile(batch, []byte(sftpBatch(u.dir, staging, files)), 0600); err != nil {
+		return err
+	}
+
+	args := append([]string(nil), u.command[1:]...)
+	args = append(args, "-b", batch)
+	if u.port != "" {
+		args = append(args, "-P", u.port)
+	}
+	args = append(args, u.host)
+	cmd := exec.CommandContext(ctx, u.command[0], args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("sftp to %s: %w", u.host, err)
+	}
+	return nil
+}
+
+// sftpBatch returns the sftp commands that create the directories of the
+// files below dir and put the staged files. Existing directories fail
+// "mkdir", which the "-" prefix ignores.
+func sftpBatch(dir, staging string, files []RemoteFile) string {
+	var b strings.Builder
+	created := make(map[string]bool)
+	var mkdir func(string)
+	mkdir = func(d string) {
+		if d == "/" || d == "." || created[d] {
+			return
+		}
+		mkdir(path.Dir(d))
+		created[d] = true
+		fmt.Fprintf(&b, "-mkdir %s\n", strconv.Quote(d))
+	}
+
+	for _, file := range files {
+		remote := path.Join(dir, file.Name)
+		mkdir(path.Dir(remote))
+		local := filepath.Join(staging, filepath.FromSlash(file.Name))
+		fmt.Fprintf(&b, "put %s %s\n", strconv.Quote(local), strconv.Quote(remote))
+	}
+	return b.String()
+}
@@ -0,0 +1,247 @@
+// This file implements the "resummarize" subcommand.
+// It writes the summary of the generated posts again, from their content in the
+// bundle, without touching the content: with the first prose paragraph or,
+// with -llm, by the language model like -generate-summary does.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// maxHeuristicSummary is the number of characters of a summary taken from the content.
+const maxHeuristicSummary = 300
+
+// resummarizeFunc returns the summary of a post in the given language (e.g. "german").
+type resummarizeFunc func(ctx context.Context, title, content, language string) (string, error)
+
+// runResummarize runs the resummarize subcommand and returns the process exit code.
+// Usage: go run . resummarize [-config converter.toml] [-llm] [-only-empty] [-dry-run] <output_directory>
+func runResummarize(args []string) int {
+	flags := flag.NewFlagSet("resummarize", flag.ContinueOnError)
+	configPath := flags.String("config", "", "path to a converter.toml configuration file (for the index file names, the [llm] model and the summary cache)")
+	useLLM := flags.Bool("llm", false, "let the language model write the summaries (needs OPENAI_API_KEY)")
+	onlyEmpty := flags.Bool("only-empty", false, "only write the summary of posts without one")
+	dryRun := flags.Bool("dry-run", false, "print the new summaries without writing them")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() < 1 {
+		fmt.Println("Usage: go run . resummarize [-config converter.toml] [-llm] [-only-empty] [-dry-run] <output_directory>")
+		return 2
+	}
+
+	summarize := func(ctx context.Context, title, content, language string) (string, error) {
+		return heuristicSummary(content), nil
+	}
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 2
+	}
+	var generator *SummaryGenerator
+	if *useLLM {
+		client, err := newLLMClient(config.LLM)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return 2
+		}
+		if generator, err = NewSummaryGenerator(OSFileSystem{}, config.Summary.Cache, client.summarizePost); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return 2
+		}
+		summarize = func(ctx context.Context, title, content, language string) (string, error) {
+			summary, err := generator.Summary(ctx, title, content, language)
+			return summary.Summary, err
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	count, total, err := resummarize(ctx, flags.Arg(0), config, *onlyEmpty, *dryRun, summarize)
+	if generator != nil {
+		if saveErr := generator.Save(); saveErr != nil {
+			fmt.Printf("Warning: %v\n", saveErr)
+		}
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 1
+	}
+	if *dryRun {
+		fmt.Printf("Would update the summary of %d of %d posts\n", count, total)
+	} else {
+		fmt.Printf("Updated the summary of %d of %d posts\n", count, total)
+	}
+	return 0
+}
+
+// resummarize writes the summary of every index file below outputDir again.
+// It returns the number of changed files and the number of index files.
+func resummarize(ctx context.Context, outputDir string, config *Config, onlyEmpty, dryRun bool, summarize resummarizeFunc) (int, int, error) {
+	names, err := indexFilenames(config)
+	if err != nil {
+		return 0, 0, err
+	}
+	files, err := findIndexFiles(outputDir, config)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	count := 0
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return count, len(files), err
+		}
+		post, err := readBundlePost(file)
+		if err != nil {
+			return count, len(files), err
+		}
+		if onlyEmpty && strings.TrimSpace(post.Summary) != "" {
+			continue
+		}
+		rel, err := filepath.Rel(outputDir, file)
+		if err != nil {
+			rel = file
+		}
+
+		language, _ := indexFileLanguage(file, names)
+		summary, err := summarize(ctx, post.Title, summaryContent(post.Content), llmLanguage(language))
+		if err != nil {
+			fmt.Printf("Warning: no summary for '%s': %v\n", post.Title, err)
+			continue
+		}
+		if summary == "" || summary == post.Summary {
+			continue
+		}
+
+		fmt.Printf("%s: %s\n", filepath.ToSlash(rel), summary)
+		count++
+		if dryRun {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return count, len(files), err
+		}
+		updated, err := setFrontMatterSummary(string(data), summary)
+		if err != nil {
+			return count, len(files), fmt.Errorf("%s: %w", rel, err)
+		}
+		if err := os.WriteFile(file, []byte(updated), 0644); err != nil {
+			return count, len(files), err
+		}
+	}
+	return count, len(files), nil
+}
+
+// summaryContent returns the content of a post without the disclaimer
+// the translation tool adds to the end of translations.
+func summaryContent(content string) string {
+	if i := strings.LastIndex(content, "\n---\n\n*"); i >= 0 {
+		return content[:i]
+	}
+	return content
+}
+
+var (
+	// summarySkipRegex matches paragraphs that aren't prose: headings, images,
+	// shortcodes, code, HTML, tables, quotes and rules
+	summarySkipRegex = regexp.MustCompile("^(#|!\\[|\\{\\{|```|<|\\||>|---|\\*\\*\\*)")
+
+	// summaryListRegex matches the marker of a list item
+	summaryListRegex = regexp.MustCompile(`^\s*(?:[-*+]|\d+\.)\s+`)
+
+	// summaryMarkupRegex matches the markup removed from a summary: images,
+	// shortcodes and the targets of links
+	summaryMarkupRegex = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)|\{\{[<%].*?[%>]\}\}|\]\([^)]*\)`)
+)
+
+// heuristicSummary returns the first prose paragraph of the content as plain
+// text, shortened to whole sentences of at most maxHeuristicSummary characters.
+// A list is only used if the post has no paragraph.
+func heuristicSummary(content string) string {
+	var paragraph, firstItem string
+	for _, block := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n\n") {
+		block = strings.TrimSpace(block)
+		if block == "" || summarySkipRegex.MatchString(block) {
+			continue
+		}
+		if summaryListRegex.MatchString(block) {
+			if firstItem == "" {
+				firstItem = summaryListRegex.ReplaceAllString(strings.SplitN(block, "\n", 2)[0], "")
+			}
+			continue
+		}
+		paragraph = block
+		break
+	}
+	if paragraph == "" {
+		paragraph = firstItem
+	}
+
+	// Plain text: links keep their text, emphasis and code marks are removed
+	text := summaryMarkupRegex.ReplaceAllStringFunc(paragraph, func(markup string) string {
+		if strings.HasPrefix(markup, "](") {
+			return ""
+		}
+		return " "
+	})
+	text = strings.NewReplacer("[", "", "]", "", "**", "", "*", "", "__", "", "`", "").Replace(text)
+	text = strings.Join(strings.Fields(text), " ")
+	return shortenSummary(text, maxHeuristicSummary)
+}
+
+// shortenSummary cuts a text to whole sentences of at most limit characters,
+// or at a word with "…" if the first sentence is longer.
+func shortenSummary(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	cut := string(runes[:limit])
+	if end := strings.LastIndexAny(cut, ".!?"); end > 0 && (end+1 == len(cut) || cut[end+1] == ' ') {
+		return cut[:end+1]
+	}
+	if space := strings.LastIndex(cut, " "); space > 0 {
+		cut = cut[:space]
+	}
+	return strings.TrimRight(cut, ",;:") + "…"
+}
+
+// setFrontMatterSummary replaces the summary of an index file. Without a
+// summary, the summary line is added after the title, where the writer puts it.
+func setFrontMatterSummary(data, summary string) (string, error) {
+	frontMatter, content, ok := splitFrontMatter(data)
+	if !ok {
+		return "", fmt.Errorf("no front matter")
+	}
+	summaryLine := "summary = " + tomlValue(summary)
+
+	lines := strings.Split(strings.TrimSuffix(frontMatter, "\n"), "\n")
+	insert := -1
+	for i, line := range lines {
+		if strings.HasPrefix(line, "[") {
+			break
+		}
+		if strings.HasPrefix(line, "summary = ") {
+			lines[i] = summaryLine
+			return "+++\n" + strings.Join(lines, "\n") + "\n+++\n" + content, nil
+		}
+		if strings.HasPrefix(line, "title = ") {
+			insert = i + 1
+		}
+	}
+	if insert < 0 {
+		return "", fmt.Errorf("no title")
+	}
+	lines = append(lines[:insert], append([]string{summaryLine}, lines[insert:]...)...)
+	return "+++\n" + strings.Join(lines, "\n") + "\n+++\n" + content, nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestHeuristicSummary tests taking the first prose paragraph as plain text
+func TestHeuristicSummary(t *testing.T) {
+	long := strings.Repeat("Wir segelten weiter. ", 20)
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"first paragraph", "\nWir segelten nach Ibiza.\n\nZweiter Absatz.\n", "Wir segelten nach Ibiza."},
+		{"headings and images are skipped", "# Ibiza\n\n![Hafen](hafen.jpg)\n\n{{< video src=\"a.mp4\" >}}\n\nIm Hafen.", "Im Hafen."},
+		{"markup is removed", "Mit **Renan** und [Ben](https://example.com)\nim `Hafen`.", "Mit Renan und Ben im Hafen."},
+		{"list without paragraph", "- Erster Punkt\n- Zweiter Punkt", "Erster Punkt"},
+		{"paragraph after list", "- Erster Punkt\n\nDer Text.", "Der Text."},
+		{"whole sentences", long, strings.TrimSpace(strings.Repeat("Wir segelten weiter. ", 14))},
+		{"long sentence", strings.Repeat("segeln ", 60), strings.TrimSpace(strings.Repeat("segeln ", 42)) + "…"},
+		{"no text", "# Ibiza\n\n![Hafen](hafen.jpg)", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := heuristicSummary(tt.content); got != tt.want {
+				t.Errorf("heuristicSummary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSetFrontMatterSummary tests replacing and inserting the summary line
+func TestSetFrontMatterSummary(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "replace summary",
+			input: "+++\ntitle = \"A\"\nsummary = \"Alt\"\n[params]\n  summary = \"no\"\n+++\n\nText\n",
+			want:  "+++\ntitle = \"A\"\nsummary = \"Neu \\\"B\\\"\"\n[params]\n  summary = \"no\"\n+++\n\nText\n",
+		},
+		{
+			name:  "insert after title",
+			input: "+++\ndate = \"2026-01-17\"\ntitle = \"A\"\ndraft = false\n[params]\n  summary = \"no\"\n+++\n\nText\n",
+			want:  "+++\ndate = \"2026-01-17\"\ntitle = \"A\"\nsummary = \"Neu \\\"B\\\"\"\ndraft = false\n[params]\n  summary = \"no\"\n+++\n\nText\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := setFrontMatterSummary(tt.input, `Neu "B"`)
+			if err != nil {
+				t.Fatalf("setFrontMatterSummary() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("setFrontMatterSummary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := setFrontMatterSummary("Text\n", "Neu"); err == nil {
+		t.Error("setFrontMatterSummary() without front matter should fail")
+	}
+}
+
+// TestResummarize tests writing the summaries of existing bundles without changing their content
+func TestResummarize(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		filepath.Join("2026-01-17_Ibiza", "index.de.md"): "+++\ntitle = \"Ibiza\"\nsummary = \"# Ibiza ![x](y.jpg)\"\n+++\n\n# Ibiza\n\nWir segelten nach Ibiza.\n",
+		filepath.Join("2026-01-17_Ibiza", "index.en.md"): "+++\ntitle = \"Ibiza\"\nsummary = \"\"\n+++\n\nWe sailed to Ibiza.\n\n---\n\n*This blog post has been automatically translated.*\n",
+		filepath.Join("2026-01-18_Renan", "index.de.md"): "+++\ntitle = \"Renan\"\nsummary = \"Wandern im Jura.\"\n+++\n\nWandern im Jura.\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	heuristic := func(ctx context.Context, title, content, language string) (string, error) {
+		return heuristicSummary(content), nil
+	}
+
+	// A dry run writes nothing
+	count, total, err := resummarize(context.Background(), dir, DefaultConfig(), false, true, heuristic)
+	if err != nil || count != 2 || total != 3 {
+		t.Fatalf("resummarize() dry run = %d, %d, %v, want 2 of 3 posts", count, total, err)
+	}
+	if got := read(filepath.Join("2026-01-17_Ibiza", "index.de.md")); got != files[filepath.Join("2026-01-17_Ibiza", "index.de.md")] {
+		t.Errorf("dry run changed the file: %q", got)
+	}
+
+	// Only the empty summary with the language of the file
+	var languages []string
+	count, _, err = resummarize(context.Background(), dir, DefaultConfig(), true, false, func(ctx context.Context, title, content, language string) (string, error) {
+		languages = append(languages, language)
+		if strings.Contains(content, "automatically translated") {
+			t.Errorf("content of the summary has the disclaimer: %q", content)
+		}
+		return "Sailing to Ibiza.", nil
+	})
+	if err != nil || count != 1 {
+		t.Fatalf("resummarize() only empty = %d, %v, want 1 post", count, err)
+	}
+	if len(languages) != 1 || languages[0] != "english" {
+		t.Errorf("summaries asked in %v, want [english]", languages)
+	}
+	want := "+++\ntitle = \"Ibiza\"\nsummary = \"Sailing to Ibiza.\"\n+++\n\nWe sailed to Ibiza.\n\n---\n\n*This blog post has been automatically translated.*\n"
+	if got := read(filepath.Join("2026-01-17_Ibiza", "index.en.md")); got != want {
+		t.Errorf("index.en.md = %q, want %q", got, want)
+	}
+
+	// All summaries, the content stays unchanged
+	if _, _, err := resummarize(context.Background(), dir, DefaultConfig(), false, false, heuristic); err != nil {
+		t.Fatalf("resummarize() error = %v", err)
+	}
+	want = "+++\ntitle = \"Ibiza\"\nsummary = \"Wir segelten nach Ibiza.\"\n+++\n\n# Ibiza\n\nWir segelten nach Ibiza.\n"
+	if got := read(filepath.Join("2026-01-17_Ibiza", "index.de.md")); got != want {
+		t.Errorf("index.de.md = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,276 @@
+// This file handles the rules that modify posts based on site-specific conditions.
+// A rule has a condition in a tiny expression language and actions that change
+// the metadata or the content of the matching posts, so users can customize the
+// conversion without recompiling:
+//
+//	[[rules]]
+//	when = 'tags contains "garden" and language == "english"'
+//	set = { author = "Garden Team" }
+//	add_tags = ["outdoor"]
+//	replace = [{ pattern = "\\bTomaten\\b", with = "tomatoes" }]
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// ruleCondition is a single comparison of a condition: tags contains "garden"
+type ruleCondition struct {
+	field    string         // Field name (e.g., "title", "tags" or a property key)
+	operator string         // "==", "!=", "contains" or "matches"
+	value    string         // Value to compare with
+	regex    *regexp.Regexp // Compiled value of "matches"
+}
+
+// compiledRule is a rule with a parsed condition and compiled replacements.
+type compiledRule struct {
+	config  RuleConfig
+	when    [][]ruleCondition // Alternatives ("or") of comparisons that must all match ("and")
+	replace []*regexp.Regexp  // Compiled patterns of config.Replace
+}
+
+// compileRules parses the conditions and patterns of the rules.
+func compileRules(rules []RuleConfig) ([]compiledRule, error) {
+	compiled := make([]compiledRule, 0, len(rules))
+	for i, rule := range rules {
+		when, err := parseCondition(rule.When)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		result := compiledRule{config: rule, when: when}
+		for _, replacement := range rule.Replace {
+			regex, err := regexp.Compile(replacement.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %w", i+1, err)
+			}
+			result.replace = append(result.replace, regex)
+		}
+		compiled = append(compiled, result)
+	}
+	return compiled, nil
+}
+
+// applyRules runs the actions of every rule whose condition matches the post.
+// Rules run in order, so later rules see the changes of earlier ones.
+func applyRules(post *BlogPost, rules []compiledRule) {
+	parser := NewMetadataParser()
+	for _, rule := range rules {
+		if !rule.matches(post) {
+			continue
+		}
+		for key, value := range rule.config.Set {
+			parser.setField(&post.Meta, normalizeKey(key), value)
+		}
+		post.Meta.Tags = addTags(removeTags(post.Meta.Tags, rule.config.RemoveTags), rule.config.AddTags)
+		for i, regex := range rule.replace {
+			for j := range post.Content {
+				post.Content[j].Text = regex.ReplaceAllString(post.Content[j].Text, rule.config.Replace[i].With)
+			}
+		}
+	}
+}
+
+// matches reports whether the condition of the rule matches the post.
+// An empty condition matches every post.
+func (r compiledRule) matches(post *BlogPost) bool {
+	if len(r.when) == 0 {
+		return true
+	}
+	for _, all := range r.when {
+		matched := true
+		for _, condition := range all {
+			if !condition.matches(post) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// matches compares a field of the post. List fields (tags) match if any item matches:
+// "contains" is a case-insensitive item comparison for lists and a substring test otherwise.
+func (c ruleCondition) matches(post *BlogPost) bool {
+	values, isList := ruleField(post, c.field)
+	switch c.operator {
+	case "!=":
+		for _, value := range values {
+			if value == c.value {
+				return false
+			}
+		}
+		return true
+	case "contains":
+		for _, value := range values {
+			if isList && strings.EqualFold(value, c.value) || !isList && strings.Contains(value, c.value) {
+				return true
+			}
+		}
+	case "matches":
+		for _, value := range values {
+			if c.regex.MatchString(value) {
+				return true
+			}
+		}
+	default: // "=="
+		for _, value := range values {
+			if value == c.value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ruleField returns the values of a field of a post and whether the field is a list.
+// Unknown fields are looked up in the other properties of the post.
+func ruleField(post *BlogPost, field string) ([]string, bool) {
+	meta := post.Meta
+	switch field {
+	case "title":
+		return []string{meta.Title}, false
+	case "date":
+		return []string{meta.Date}, false
+	case "author":
+		return []string{meta.Author}, false
+	case "summary":
+		return []string{meta.Summary}, false
+	case "status":
+		return []string{meta.Status}, false
+	case "language":
+		return []string{meta.Language}, false
+	case "header":
+		return []string{meta.Header}, false
+	case "tags":
+		return meta.Tags, true
+	case "content":
+		texts := make([]string, len(post.Content))
+		for i, block := range post.Content {
+			texts[i] = block.Text
+		}
+		return []string{strings.Join(texts, "\n\n")}, false
+	}
+	return []string{meta.Properties[normalizeKey(field)]}, false
+}
+
+// addTags adds the tags a tag list doesn't have yet (case-insensitive).
+func addTags(tags, add []string) []string {
+	for _, tag := range add {
+		if len(removeTags([]string{tag}, tags)) > 0 {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// removeTags removes tags (case-insensitive) from a tag list.
+func removeTags(tags, remove []string) []string {
+	if len(remove) == 0 {
+		return tags
+	}
+	var kept []string
+	for _, tag := range tags {
+		removed := false
+		for _, r := range remove {
+			if strings.EqualFold(tag, r) {
+				removed = true
+				break
+			}
+		}
+		if !removed {
+			kept = append(kept, tag)
+		}
+	}
+	return kept
+}
+
+// parseCondition parses a condition like 'tags contains "garden" and status == "online"'.
+// Comparisons are combined with "and" and "or" ("and" binds stronger), values are
+// double-quoted strings with \" and \\ escapes.
+func parseCondition(condition string) ([][]ruleCondition, error) {
+	tokens, err := tokenizeCondition(condition)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	var alternatives [][]ruleCondition
+	var all []ruleCondition
+	for i := 0; ; {
+		if i+3 > len(tokens) || tokens[i].quoted || tokens[i+1].quoted || !tokens[i+2].quoted {
+			return nil, fmt.Errorf("invalid condition %q: expected field operator \"value\"", condition)
+		}
+		comparison := ruleCondition{field: strings.ToLower(tokens[i].text), operator: tokens[i+1].text, value: tokens[i+2].text}
+		switch comparison.operator {
+		case "==", "!=", "contains":
+		case "matches":
+			if comparison.regex, err = regexp.Compile(comparison.value); err != nil {
+				return nil, fmt.Errorf("invalid condition %q: %w", condition, err)
+			}
+		default:
+			return nil, fmt.Errorf("invalid condition %q: unknown operator %q", condition, comparison.operator)
+		}
+		all = append(all, comparison)
+		i += 3
+
+		if i == len(tokens) {
+			return append(alternatives, all), nil
+		}
+		switch {
+		case !tokens[i].quoted && tokens[i].text == "and":
+		case !tokens[i].quoted && tokens[i].text == "or":
+			alternatives = append(alternatives, all)
+			all = nil
+		default:
+			return nil, fmt.Errorf("invalid condition %q: expected \"and\" or \"or\" before %q", condition, tokens[i].text)
+		}
+		i++
+	}
+}
+
+// conditionToken is a word, operator or quoted string of a condition.
+type conditionToken struct {
+	text   string
+	quoted bool // The token was a quoted string (and is never a keyword)
+}
+
+// tokenizeCondition splits a condition into words, operators and quoted strings.
+func tokenizeCondition(condition string) ([]conditionToken, error) {
+	var tokens []conditionToken
+	runes := []rune(condition)
+	for i := 0; i < len(runes); {
+		switch r := runes[i]; {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"':
+			var text strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				text.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, fmt.Errorf("invalid condition %q: missing closing quote", condition)
+			}
+			tokens = append(tokens, conditionToken{text: text.String(), quoted: true})
+			i++
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '"' {
+				i++
+			}
+			tokens = append(tokens, conditionToken{text: string(runes[start:i])})
+		}
+	}
+	return tokens, nil
+}
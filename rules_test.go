@@ -0,0 +1,107 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestParseCondition tests parsing rule conditions
+func TestParseCondition(t *testing.T) {
+	tests := []struct {
+		condition string
+		want      [][]ruleCondition
+		wantError string
+	}{
+		{``, nil, ""},
+		{
+			`tags contains "garden"`,
+			[][]ruleCondition{{{field: "tags", operator: "contains", value: "garden"}}},
+			"",
+		},
+		{
+			`Language == "english" and title != "Say \"Hi\"" or author == "ben"`,
+			[][]ruleCondition{
+				{{field: "language", operator: "==", value: "english"}, {field: "title", operator: "!=", value: `Say "Hi"`}},
+				{{field: "author", operator: "==", value: "ben"}},
+			},
+			"",
+		},
+		{`title ~ "x"`, nil, `unknown operator "~"`},
+		{`title == english`, nil, "expected field operator"},
+		{`title == "a" "b"`, nil, `expected "and" or "or"`},
+		{`title == "open`, nil, "missing closing quote"},
+		{`title matches "("`, nil, "missing closing )"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			got, err := parseCondition(tt.condition)
+			if tt.wantError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+					t.Fatalf("parseCondition() error = %v, want %q", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseCondition() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseCondition() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestApplyRules tests changing the metadata and content of matching posts
+func TestApplyRules(t *testing.T) {
+	rules, err := compileRules([]RuleConfig{
+		{
+			When:       `tags contains "Garden" and mood matches "^happy"`,
+			Set:        map[string]string{"author": "Garden Team", "lang": "english"},
+			AddTags:    []string{"outdoor", "garden"},
+			RemoveTags: []string{"private"},
+			Replace:    []RuleReplacement{{Pattern: `\bTomaten\b`, With: "tomatoes"}},
+		},
+		{
+			When: `author == "Garden Team"`,
+			Set:  map[string]string{"summary": "From the garden"},
+		},
+		{
+			When: `title == "Other"`,
+			Set:  map[string]string{"title": "Never"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("compileRules() error = %v", err)
+	}
+
+	post := &BlogPost{
+		Meta: BlogMeta{
+			Title:      "Beds",
+			Tags:       []string{"garden", "Private"},
+			Properties: map[string]string{"mood": "happy and tired"},
+		},
+		Content: []ContentBlock{{Text: "Tomaten and Tomatensalat"}},
+	}
+	applyRules(post, rules)
+
+	want := BlogMeta{
+		Title:      "Beds",
+		Author:     "Garden Team",
+		Language:   "english",
+		Summary:    "From the garden",
+		Tags:       []string{"garden", "outdoor"},
+		Properties: map[string]string{"mood": "happy and tired"},
+	}
+	if !reflect.DeepEqual(post.Meta, want) {
+		t.Errorf("Meta = %+v, want %+v", post.Meta, want)
+	}
+	if got := post.Content[0].Text; got != "tomatoes and Tomatensalat" {
+		t.Errorf("Content = %q, want replaced word", got)
+	}
+
+	if _, err := compileRules([]RuleConfig{{Replace: []RuleReplacement{{Pattern: "["}}}}); err == nil || !strings.Contains(err.Error(), "rule 1") {
+		t.Errorf("compileRules() error = %v, want invalid pattern of rule 1", err)
+	}
+}
@@ -0,0 +1,105 @@
+// This file handles the search index of the converted posts: a JSON array
+// with the title, summary, tags, URL, language and plain-text content of every
+// post, which Lunr or Pagefind (as custom records) index without a crawler.
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"logseq-to-hugo-converter/internal/words"
+)
+
+// SearchEntry is a post in the search index.
+type SearchEntry struct {
+	Title   string   `json:"title"`
+	URL     string   `json:"url"`
+	Date    string   `json:"date,omitempty"`
+	Lang    string   `json:"lang"`
+	Summary string   `json:"summary,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+	Content string   `json:"content"`
+	Path    string   `json:"path"` // Bundle path below the output directory (e.g. "trips/2026-01-17_Ibiza")
+}
+
+// searchLineMarkRegex matches the marks at the start of a line: headings, list markers and quotes.
+var searchLineMarkRegex = regexp.MustCompile(`(?m)^\s*(?:#{1,6}|[-*+>]|\d+\.)\s+`)
+
+// searchInlineMarkRegex matches emphasis, code marks and the brackets of links.
+var searchInlineMarkRegex = regexp.MustCompile("\\*\\*|__|[*`\\[\\]]")
+
+// searchText returns the readable text of markdown content as a single line:
+// code blocks, shortcodes, images, HTML and the marks are removed, links keep their text.
+func searchText(content string) string {
+	content = words.ReplaceMarkup(content, func(markup string) string {
+		if strings.HasPrefix(markup, "](") {
+			return ""
+		}
+		return " "
+	})
+	content = searchLineMarkRegex.ReplaceAllString(content, " ")
+	content = searchInlineMarkRegex.ReplaceAllString(content, "")
+	return strings.Join(strings.Fields(content), " ")
+}
+
+// addSearchEntry adds a written post to the search index of the conversion.
+func (c *Converter) addSearchEntry(post *BlogPost, content string) {
+	c.search = append(c.search, SearchEntry{
+		Title:   post.Meta.Title,
+		URL:     publishedURL(c.config.Search.URL, post),
+		Date:    post.Meta.Date,
+		Lang:    languageKey(post.Meta.Language, c.config.Language.Keys),
+		Summary: cmp.Or(post.Meta.Description, post.Meta.Summary),
+		Tags:    post.Meta.Tags,
+		Content: searchText(content),
+		Path:    post.BundlePath(),
+	})
+}
+
+// writeSearchIndex writes the search index below the output directory. Posts
+// that were not converted in this run keep their entries as long as their bundle
+// exists, so converting a single file doesn't drop the other posts.
+func (c *Converter) writeSearchIndex(outputBasePath string) error {
+	path := filepath.Join(outputBasePath, c.config.Search.Index)
+	var entries []SearchEntry
+	data, err := readFile(c.fs, path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("reading search index: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return fmt.Errorf("reading search index %s: %w", path, err)
+		}
+	}
+
+	key := func(entry SearchEntry) string { return entry.Path + "\x00" + entry.Lang }
+	converted := make(map[string]bool)
+	for _, entry := range c.search {
+		converted[key(entry)] = true
+	}
+	entries = slices.DeleteFunc(entries, func(entry SearchEntry) bool {
+		if converted[key(entry)] || entry.Path == "" {
+			return true
+		}
+		_, err := c.fs.Stat(filepath.Join(outputBasePath, filepath.FromSlash(entry.Path)))
+		return err != nil
+	})
+	entries = append(entries, c.search...)
+
+	// Sorted by bundle, so the file only changes where posts change
+	slices.SortStableFunc(entries, func(a, b SearchEntry) int {
+		return cmp.Or(cmp.Compare(a.Path, b.Path), cmp.Compare(a.Lang, b.Lang))
+	})
+
+	if data, err = json.MarshalIndent(entries, "", "  "); err != nil {
+		return err
+	}
+	return c.writeOutputFile(path, append(data, '\n'))
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSearchText tests the plain text of the content for the search index
+func TestSearchText(t *testing.T) {
+	content := "## Hafen\n\nWir segelten **früh** nach [Ibiza]({{< relref \"2026-01-17_Ibiza\" >}}).\n\n" +
+		"![Boot](boot.jpg)\n\n- Wind `4 Bft`\n- 1. Tag\n\n```go\nfmt.Println()\n```\n\n> Ein Zitat <br>\n\n{{< video src=\"boot.mp4\" >}}"
+	want := "Hafen Wir segelten früh nach Ibiza. Wind 4 Bft 1. Tag Ein Zitat"
+	if got := searchText(content); got != want {
+		t.Errorf("searchText() = %q, want %q", got, want)
+	}
+}
+
+// TestWriteSearchIndex tests merging the posts of a conversion into the search index
+func TestWriteSearchIndex(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	fsys.MkdirAll(filepath.Join("out", "2025-12-01_Kept"), 0755)
+	fsys.WriteFile(filepath.Join("out", "search.json"), []byte(`[
+		{"title": "Kept", "url": "/2025-12-01_kept/", "lang": "de", "content": "Alt", "path": "2025-12-01_Kept"},
+		{"title": "Ibiza (old)", "url": "/2026-01-17_ibiza/", "lang": "de", "content": "Alt", "path": "2026-01-17_Ibiza"},
+		{"title": "Removed", "url": "/2025-11-01_removed/", "lang": "de", "content": "Alt", "path": "2025-11-01_Removed"}
+	]`))
+
+	config := DefaultConfig()
+	config.Search = SearchConfig{Index: "search.json", URL: "https://example.com/posts/"}
+	c := NewConverter(config, fsys)
+	post := &BlogPost{
+		Meta: BlogMeta{Title: "Ibiza", Date: "2026-01-17", Language: "german", Summary: "Segeln", Tags: []string{"Segeln"}},
+		Slug: "2026-01-17_Ibiza",
+	}
+	c.addSearchEntry(post, "Wir segelten nach **Ibiza**.")
+	if err := c.writeSearchIndex("out"); err != nil {
+		t.Fatalf("writeSearchIndex() error: %v", err)
+	}
+
+	data, err := readFile(fsys, filepath.Join("out", "search.json"))
+	if err != nil {
+		t.Fatalf("Search index was not written: %v", err)
+	}
+	var entries []SearchEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatalf("Search index is no JSON: %v", err)
+	}
+	var titles []string
+	for _, entry := range entries {
+		titles = append(titles, entry.Title)
+	}
+	if strings.Join(titles, ",") != "Kept,Ibiza" {
+		t.Fatalf("Search index has %v, want the kept post and the converted one", titles)
+	}
+	want := SearchEntry{
+		Title: "Ibiza", URL: "https://example.com/posts/2026-01-17_ibiza/", Date: "2026-01-17", Lang: "de",
+		Summary: "Segeln", Tags: []string{"Segeln"}, Content: "Wir segelten nach Ibiza.", Path: "2026-01-17_Ibiza",
+	}
+	if got := entries[1]; got.Title != want.Title || got.URL != want.URL || got.Date != want.Date || got.Lang != want.Lang ||
+		got.Summary != want.Summary || strings.Join(got.Tags, ",") != "Segeln" || got.Content != want.Content || got.Path != want.Path {
+		t.Errorf("Entry = %+v, want %+v", got, want)
+	}
+}
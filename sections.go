@@ -0,0 +1,91 @@
+// This file handles mapping Logseq namespaces to Hugo sections.
+// A page "blog/trips/Renan" (file "blog___trips___Renan.md") is a page in the namespace
+// "blog/trips"; its bundle is written to the section directory "blog/trips/".
+package main
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// pageNamespace returns the namespace of the page a post comes from ("blog/trips"),
+// or "" for journal pages and pages without a namespace.
+func pageNamespace(post *BlogPost) string {
+	if post.SourcePath == "" || filepath.Base(filepath.Dir(post.SourcePath)) != "pages" {
+		return ""
+	}
+	namespace, _, found := cutLast(pageNameFromFile(post.SourcePath), "/")
+	if !found {
+		return ""
+	}
+	return namespace
+}
+
+// cutLast slices s around the last instance of sep.
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return "", s, false
+}
+
+// postSection returns the section directory of a post below the output directory
+// (with slashes, "" for the output directory itself).
+// The longest namespace prefix in the mapping is replaced by its section,
+// the other namespace segments become lowercase directory names.
+func postSection(namespace string, mapping map[string]string, policy string) string {
+	if namespace == "" {
+		return ""
+	}
+	segments := strings.Split(namespace, "/")
+
+	// Find the longest mapped prefix (whole segments, case-insensitive like Logseq page names)
+	var section []string
+	rest := segments
+	for n := len(segments); n > 0; n-- {
+		if mapped, ok := lookupNamespace(mapping, strings.Join(segments[:n], "/")); ok {
+			if mapped != "" {
+				section = strings.Split(mapped, "/")
+			}
+			rest = segments[n:]
+			break
+		}
+	}
+
+	for _, segment := range rest {
+		if name := strings.ToLower(sanitizeTitle(segment, policy)); name != "" {
+			section = append(section, name)
+		}
+	}
+	return path.Join(section...)
+}
+
+// lookupNamespace returns the section of a namespace in the mapping, ignoring the case.
+func lookupNamespace(mapping map[string]string, namespace string) (string, bool) {
+	for key, section := range mapping {
+		if strings.EqualFold(strings.Trim(key, "/"), namespace) {
+			return strings.Trim(section, "/"), true
+		}
+	}
+	return "", false
+}
+
+// validateSections checks that the mapped sections stay inside the output directory.
+func validateSections(mapping map[string]string) error {
+	keys := make([]string, 0, len(mapping))
+	for key := range mapping {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		section := strings.Trim(mapping[key], "/")
+		if section != "" && !filepath.IsLocal(filepath.FromSlash(section)) {
+			return fmt.Errorf("section %q of namespace %q is outside the output directory", mapping[key], key)
+		}
+	}
+	return nil
+}
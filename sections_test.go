@@ -0,0 +1,69 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestPageNamespace tests reading the namespace from the page file name
+func TestPageNamespace(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{filepath.Join("graph", "pages", "blog___trips___Renan.md"), "blog/trips"},
+		{filepath.Join("graph", "pages", "blog___Renan.md"), "blog"},
+		{filepath.Join("graph", "pages", "Renan.md"), ""},
+		{filepath.Join("graph", "journals", "2026___01.md"), ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := pageNamespace(&BlogPost{SourcePath: tt.source}); got != tt.want {
+			t.Errorf("pageNamespace(%q) = %q, want %q", tt.source, got, tt.want)
+		}
+	}
+}
+
+// TestPostSection tests mapping namespaces to section directories
+func TestPostSection(t *testing.T) {
+	mapping := map[string]string{
+		"Blog":       "",
+		"blog/trips": "/travel/",
+		"projects":   "work/projects",
+	}
+
+	tests := []struct {
+		name      string
+		namespace string
+		mapping   map[string]string
+		want      string
+	}{
+		{"no namespace", "", mapping, ""},
+		{"unmapped namespace keeps the hierarchy", "Garden/Fruit Trees", mapping, "garden/fruit_trees"},
+		{"no mapping", "blog/trips", nil, "blog/trips"},
+		{"mapped to the output directory", "blog", mapping, ""},
+		{"longest prefix wins", "blog/trips/Switzerland", mapping, "travel/switzerland"},
+		{"shorter prefix", "blog/Food", mapping, "food"},
+		{"mapped to a nested section", "projects", mapping, "work/projects"},
+		{"unsafe segments are dropped", "../..", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := postSection(tt.namespace, tt.mapping, SlugPolicyUnicode); got != tt.want {
+				t.Errorf("postSection(%q) = %q, want %q", tt.namespace, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestValidateSections tests rejecting sections outside the output directory
+func TestValidateSections(t *testing.T) {
+	if err := validateSections(map[string]string{"blog": "", "trips": "travel/trips"}); err != nil {
+		t.Errorf("validateSections() error = %v", err)
+	}
+	if err := validateSections(map[string]string{"blog": "../static"}); err == nil {
+		t.Error("validateSections() error = nil, want an error")
+	}
+}
@@ -0,0 +1,81 @@
+// This file handles turning post titles into safe output directory names.
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// Slug policies for non-ASCII characters in titles.
+const (
+	SlugPolicyUnicode = "unicode" // Keep unicode characters (e.g. "Frühlingspläne")
+	SlugPolicyASCII   = "ascii"   // Transliterate to ASCII (e.g. "Fruehlingsplaene")
+	SlugPolicyPercent = "percent" // Percent-encode non-ASCII characters
+)
+
+// transliterations maps non-ASCII letters to ASCII replacements.
+// German umlauts use their conventional two-letter forms.
+var transliterations = map[rune]string{
+	'ä': "ae", 'ö': "oe", 'ü': "ue", 'Ä': "Ae", 'Ö': "Oe", 'Ü': "Ue", 'ß': "ss",
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'å': "a", 'æ': "ae", 'ç': "c",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ì': "i", 'í': "i", 'î': "i", 'ï': "i",
+	'ñ': "n", 'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ø': "o", 'œ': "oe",
+	'ù': "u", 'ú': "u", 'û': "u", 'ý': "y", 'ÿ': "y",
+	'À': "A", 'Á': "A", 'Â': "A", 'Ã': "A", 'Å': "A", 'Æ': "Ae", 'Ç': "C",
+	'È': "E", 'É': "E", 'Ê': "E", 'Ë': "E", 'Ì': "I", 'Í': "I", 'Î': "I", 'Ï': "I",
+	'Ñ': "N", 'Ò': "O", 'Ó': "O", 'Ô': "O", 'Õ': "O", 'Ø': "O", 'Œ': "Oe",
+	'Ù': "U", 'Ú': "U", 'Û': "U", 'Ý': "Y",
+	'č': "c", 'ć': "c", 'š': "s", 'ž': "z", 'đ': "d", 'ł': "l", 'ś': "s", 'ź': "z", 'ż': "z",
+	'Č': "C", 'Ć': "C", 'Š': "S", 'Ž': "Z", 'Đ': "D", 'Ł': "L", 'Ś': "S", 'Ź': "Z", 'Ż': "Z",
+}
+
+// postSlug returns the bundle directory name of a post: YYYY-MM-DD_Title
+// (the date is formatted for directory names already, see DateFormatter.Folder).
+// Pages without a date, like recipes, are named after their title only.
+// Titles that leave nothing ("?", "..", an emoji with the ascii policy) are
+// replaced by a hash of the name of the source file: "post-1a2b3c4d".
+func postSlug(date, title, source, policy string) string {
+	name := sanitizeTitle(title, policy)
+	if name == "" {
+		sum := sha256.Sum256([]byte(filepath.Base(source)))
+		name = "post-" + hex.EncodeToString(sum[:4])
+	}
+	if date == "" {
+		return name
+	}
+	return fmt.Sprintf("%s_%s", sanitizeTitle(date, policy), name)
+}
+
+// sanitizeTitle makes a title safe to use as a directory name on all filesystems.
+// Spaces and characters that are invalid on Windows or macOS (/ \ : * ? " < > |)
+// become underscores, and non-ASCII characters are handled according to the policy.
+func sanitizeTitle(title, policy string) string {
+	var builder strings.Builder
+	for _, r := range title {
+		switch {
+		case r == ' ' || strings.ContainsRune(`/\:*?"<>|`, r) || unicode.IsControl(r):
+			builder.WriteRune('_')
+		case r < unicode.MaxASCII:
+			builder.WriteRune(r)
+		case policy == SlugPolicyASCII:
+			// Unknown characters (e.g. emoji) are dropped
+			builder.WriteString(transliterations[r])
+		case policy == SlugPolicyPercent:
+			builder.WriteString(url.PathEscape(string(r)))
+		default:
+			builder.WriteRune(r)
+		}
+	}
+
+	// Collapse repeated underscores and trim characters Windows doesn't allow at the end
+	slug := builder.String()
+	for strings.Contains(slug, "__") {
+		slug = strings.ReplaceAll(slug, "__", "_")
+	}
+	return strings.TrimRight(strings.Trim(slug, "_"), ". ")
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestSanitizeTitle tests directory name sanitization for all policies
+func TestSanitizeTitle(t *testing.T) {
+	tests := []struct {
+		name   string
+		title  string
+		policy string
+		want   string
+	}{
+		{"Unicode kept", "Frühlingspläne 2026", SlugPolicyUnicode, "Frühlingspläne_2026"},
+		{"ASCII folding", "Frühlingspläne 2026", SlugPolicyASCII, "Fruehlingsplaene_2026"},
+		{"ASCII accents", "Café à Paris", SlugPolicyASCII, "Cafe_a_Paris"},
+		{"ASCII drops emoji", "Sailing ⛵ Trip", SlugPolicyASCII, "Sailing_Trip"},
+		{"Percent encoding", "Grüße", SlugPolicyPercent, "Gr%C3%BC%C3%9Fe"},
+		{"Invalid characters", `Trip: Ibiza/Formentera?`, SlugPolicyUnicode, "Trip_Ibiza_Formentera"},
+		{"Trailing dots", "Wait...", SlugPolicyUnicode, "Wait"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeTitle(tt.title, tt.policy); got != tt.want {
+				t.Errorf("sanitizeTitle(%q, %q) = %q, want %q", tt.title, tt.policy, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestPostSlug tests the bundle directory name format
+func TestPostSlug(t *testing.T) {
+	if got := postSlug("2026-01-17", "Frühlingspläne 2026", "journals/2026_01_17.md", SlugPolicyUnicode); got != "2026-01-17_Frühlingspläne_2026" {
+		t.Errorf("postSlug() = %q", got)
+	}
+	if got := postSlug("", "Zopf", "pages/Zopf.md", SlugPolicyUnicode); got != "Zopf" {
+		t.Errorf("postSlug() without date = %q", got)
+	}
+	// Dates formatted with a folder layout must not contain path separators
+	if got := postSlug("2026/01/17", "Renan", "journals/2026_01_17.md", SlugPolicyUnicode); got != "2026_01_17_Renan" {
+		t.Errorf("postSlug() = %q", got)
+	}
+
+	// Titles without a usable character get a name from the source file
+	for _, title := range []string{"?", "..", "🎉"} {
+		got := postSlug("", title, "/graph/pages/"+title+".md", SlugPolicyASCII)
+		if !strings.HasPrefix(got, "post-") || len(got) != len("post-")+8 {
+			t.Errorf("postSlug(%q) = %q, want post- and a hash", title, got)
+		}
+		if again := postSlug("", title, "/other/graph/pages/"+title+".md", SlugPolicyASCII); again != got {
+			t.Errorf("postSlug(%q) = %q in another graph directory, want %q", title, again, got)
+		}
+	}
+	if a, b := postSlug("", "?", "pages/?.md", SlugPolicyASCII), postSlug("", "??", "pages/??.md", SlugPolicyASCII); a == b {
+		t.Errorf("postSlug() = %q for two pages", a)
+	}
+	if got := postSlug("2026-01-17", "?", "journals/2026_01_17.md", SlugPolicyASCII); !strings.HasPrefix(got, "2026-01-17_post-") {
+		t.Errorf("postSlug() with date = %q", got)
+	}
+}
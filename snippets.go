@@ -0,0 +1,272 @@
+// This file handles the social media snippets of the converted posts: a short
+// post for Mastodon or Bluesky in the language of the post, with hashtags and
+// the link, in a file of the bundle or in a queue file a posting script reads.
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/openai/openai-go"
+)
+
+// DefaultSnippetFile is the snippet file in the bundle if none is configured.
+// The language key is inserted before the extension ("social.de.txt").
+const DefaultSnippetFile = "social.txt"
+
+// DefaultSnippetCache is the cache file of the generated snippets if none is configured.
+const DefaultSnippetCache = ".snippet-cache.json"
+
+// minSnippetLength is the smallest maximum length of a snippet that leaves room for a sentence.
+const minSnippetLength = 50
+
+// SocialSnippet is a snippet in the queue file.
+type SocialSnippet struct {
+	Title  string `json:"title"`
+	URL    string `json:"url,omitempty"`
+	Date   string `json:"date,omitempty"`
+	Lang   string `json:"lang"`
+	Text   string `json:"text"`             // The complete post with hashtags and link
+	Path   string `json:"path"`             // Bundle path below the output directory (e.g. "trips/2026-01-17_Ibiza")
+	Posted bool   `json:"posted,omitempty"` // Set by the posting script, kept by the converter
+}
+
+// snippetFunc writes the text of a snippet with at most limit characters in the given language (e.g. "german").
+type snippetFunc func(ctx context.Context, title, content, language string, limit int) (string, error)
+
+// SnippetGenerator writes the text of the snippets with the language model.
+type SnippetGenerator struct {
+	cache *llmCache   // Snippet texts by content hash, length and language
+	write snippetFunc // Asks the model (replaceable in tests)
+}
+
+// NewSnippetGenerator creates a SnippetGenerator and reads its cache.
+// A missing cache file is an empty cache.
+func NewSnippetGenerator(fsys FileSystem, cachePath string, write snippetFunc) (*SnippetGenerator, error) {
+	cache, err := loadLLMCache(fsys, cachePath)
+	if err != nil {
+		return nil, fmt.Errorf("snippets: %w", err)
+	}
+	return &SnippetGenerator{cache: cache, write: write}, nil
+}
+
+// Snippet returns the text of a snippet with at most limit characters, from the cache or from the model.
+func (g *SnippetGenerator) Snippet(ctx context.Context, title, content, language string, limit int) (string, error) {
+	if len(content) > maxSummaryInput {
+		content = strings.ToValidUTF8(content[:maxSummaryInput], "")
+	}
+
+	key := llmCacheKey([]byte(fmt.Sprintf("%s\n%d\n%s", title, limit, content)), language)
+	if cached, ok := g.cache.get(key); ok {
+		return cached, nil
+	}
+
+	text, err := g.write(ctx, title, content, language, limit)
+	if err != nil {
+		return "", err
+	}
+	text = strings.Trim(strings.TrimSpace(text), `"“”„`)
+	if text == "" {
+		return "", fmt.Errorf("empty answer")
+	}
+	// Models don't count characters well
+	text = shortenSummary(text, limit)
+
+	g.cache.set(key, text)
+	return text, nil
+}
+
+// Save writes the cache file if new snippets were generated.
+func (g *SnippetGenerator) Save() error {
+	if err := g.cache.save(); err != nil {
+		return fmt.Errorf("snippets: %w", err)
+	}
+	return nil
+}
+
+// writeSnippet asks the model for the text of a snippet announcing a post.
+func (c *llmClient) writeSnippet(ctx context.Context, title, content, language string, limit int) (string, error) {
+	instructions := fmt.Sprintf(`You write short social media posts (for Mastodon and Bluesky) announcing blog posts, in %s.
+The social media post has at most %d characters and makes readers want to read the blog post.
+Use the tone of the blog post and at most one emoji. Do not add hashtags or links, they are added later.
+Do not invent facts that are not in the blog post.
+Return ONLY the text of the social media post.`, language, limit)
+
+	return c.complete(ctx, instructions, []openai.ChatCompletionContentPartUnionParam{
+		openai.TextContentPart("# " + title + "\n\n" + content),
+	})
+}
+
+// hashtags returns the tags as hashtags, words are joined in camel case
+// ("road trip" -> "#RoadTrip"). Tags without letters or digits are left out.
+func hashtags(tags []string) []string {
+	var result []string
+	for _, tag := range tags {
+		words := strings.FieldsFunc(tag, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+		if len(words) == 0 {
+			continue
+		}
+		if len(words) > 1 {
+			for i, word := range words {
+				runes := []rune(word)
+				runes[0] = unicode.ToUpper(runes[0])
+				words[i] = string(runes)
+			}
+		}
+		if hashtag := "#" + strings.Join(words, ""); !slices.Contains(result, hashtag) {
+			result = append(result, hashtag)
+		}
+	}
+	return result
+}
+
+// snippetFooter returns the hashtags and the link below the text of a snippet.
+// Hashtags are dropped from the end until the footer takes at most a third of maxLength.
+func snippetFooter(tags []string, link string, maxLength int) string {
+	for {
+		lines := slices.DeleteFunc([]string{strings.Join(tags, " "), link}, func(line string) bool { return line == "" })
+		footer := strings.Join(lines, "\n")
+		if len(tags) == 0 || len([]rune(footer)) <= maxLength/3 {
+			return footer
+		}
+		tags = tags[:len(tags)-1]
+	}
+}
+
+// snippetRoom returns the number of characters left for the text of a snippet
+// with the footer, one is kept for the ellipsis shortenSummary may add.
+func snippetRoom(footer string, maxLength int) int {
+	room := maxLength - 1
+	if footer != "" {
+		room -= len([]rune(footer)) + 2
+	}
+	return max(room, 1)
+}
+
+// composeSnippet joins the text and the footer of a snippet, the text is shortened to fit.
+func composeSnippet(text, footer string, maxLength int) string {
+	text = shortenSummary(strings.TrimSpace(text), snippetRoom(footer, maxLength))
+	if footer == "" {
+		return text
+	}
+	return text + "\n\n" + footer
+}
+
+// snippetFilename returns the name of the snippet file of a language in the
+// bundle: "social.txt" -> "social.de.txt". Monolingual sites use the name as it is.
+func snippetFilename(file, lang string, monolingual bool) string {
+	if monolingual || lang == "" {
+		return file
+	}
+	ext := filepath.Ext(file)
+	return strings.TrimSuffix(file, ext) + "." + lang + ext
+}
+
+// postSnippet returns the snippet of a written post. The text is the title and
+// the summary, or with the language model, a text it wrote from the content.
+// If the model fails, the title and summary are used and a warning is printed.
+func (c *Converter) postSnippet(ctx context.Context, post *BlogPost, content string) SocialSnippet {
+	cfg := c.config.Snippets
+	var tags []string
+	if cfg.Hashtags {
+		tags = hashtags(post.Meta.Tags)
+	}
+	var link string
+	if cfg.URL != "" {
+		link = publishedURL(cfg.URL, post)
+	}
+	footer := snippetFooter(tags, link, cfg.MaxLength)
+
+	text := post.Meta.Title
+	if summary := searchText(cmp.Or(post.Meta.Description, post.Meta.Summary)); summary != "" && summary != text {
+		text += "\n\n" + summary
+	}
+	if c.snippets != nil {
+		generated, err := c.snippets.Snippet(ctx, post.Meta.Title, searchText(content), llmLanguage(post.Meta.Language), snippetRoom(footer, cfg.MaxLength))
+		if err != nil {
+			fmt.Printf("Warning: no snippet generated for '%s': %v\n", post.Meta.Title, err)
+		} else {
+			text = generated
+		}
+	}
+
+	return SocialSnippet{
+		Title: post.Meta.Title,
+		URL:   link,
+		Date:  post.Meta.Date,
+		Lang:  languageKey(post.Meta.Language, c.config.Language.Keys),
+		Text:  composeSnippet(text, footer, cfg.MaxLength),
+		Path:  post.BundlePath(),
+	}
+}
+
+// writeSnippet writes the snippet of a written post into its bundle, or adds
+// it to the queue. An existing snippet file is kept (unless forced), so it can be edited before posting.
+func (c *Converter) writeSnippet(ctx context.Context, post *BlogPost, content, outputDir string) error {
+	if c.config.Snippets.Queue != "" {
+		c.snippetQueue = append(c.snippetQueue, c.postSnippet(ctx, post, content))
+		return nil
+	}
+
+	lang := languageKey(post.Meta.Language, c.config.Language.Keys)
+	path := filepath.Join(outputDir, snippetFilename(c.config.Snippets.File, lang, c.config.Output.Monolingual))
+	if _, err := c.fs.Stat(path); err == nil && !c.force {
+		return nil
+	}
+	snippet := c.postSnippet(ctx, post, content)
+	return c.writeOutputFile(path, []byte(snippet.Text+"\n"))
+}
+
+// writeSnippetQueue writes the snippets of the conversion into the queue file.
+// Snippets already in the queue keep their text (unless forced) and their
+// posted flag, snippets of posts whose bundle is gone are dropped.
+func (c *Converter) writeSnippetQueue(outputBasePath string) error {
+	path := filepath.Join(outputBasePath, c.config.Snippets.Queue)
+	var queue []SocialSnippet
+	data, err := readFile(c.fs, path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("reading snippet queue: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &queue); err != nil {
+			return fmt.Errorf("reading snippet queue %s: %w", path, err)
+		}
+	}
+
+	key := func(snippet SocialSnippet) string { return snippet.Path + "\x00" + snippet.Lang }
+	for _, snippet := range c.snippetQueue {
+		i := slices.IndexFunc(queue, func(queued SocialSnippet) bool { return key(queued) == key(snippet) })
+		switch {
+		case i < 0:
+			queue = append(queue, snippet)
+		case c.force:
+			snippet.Posted = queue[i].Posted
+			queue[i] = snippet
+		}
+	}
+	queue = slices.DeleteFunc(queue, func(snippet SocialSnippet) bool {
+		if snippet.Path == "" {
+			return true
+		}
+		_, err := c.fs.Stat(filepath.Join(outputBasePath, filepath.FromSlash(snippet.Path)))
+		return err != nil
+	})
+
+	// Sorted by bundle, so the file only changes where posts change
+	slices.SortStableFunc(queue, func(a, b SocialSnippet) int {
+		return cmp.Or(cmp.Compare(a.Path, b.Path), cmp.Compare(a.Lang, b.Lang))
+	})
+
+	if data, err = json.MarshalIndent(queue, "", "  "); err != nil {
+		return err
+	}
+	return c.writeOutputFile(path, append(data, '\n'))
+}
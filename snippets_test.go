@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestHashtags tests turning tags into hashtags
+func TestHashtags(t *testing.T) {
+	got := hashtags([]string{"Segeln", "road trip", "Côte d'Azur", "segeln", "Segeln", "---", "2026"})
+	want := "#Segeln #RoadTrip #CôteDAzur #segeln #2026"
+	if strings.Join(got, " ") != want {
+		t.Errorf("hashtags() = %v, want %s", got, want)
+	}
+}
+
+// TestComposeSnippet tests fitting the text, hashtags and link into the maximum length
+func TestComposeSnippet(t *testing.T) {
+	link := "https://example.com/posts/2026-01-17_ibiza/"
+	footer := snippetFooter([]string{"#Segeln", "#Ibiza", "#Mittelmeer", "#Balearen"}, link, 160)
+	if footer != "#Segeln\n"+link {
+		t.Errorf("snippetFooter() = %q, want the hashtags that fit into a third", footer)
+	}
+
+	text := "Segeln vor Ibiza\n\nWir segelten bei starkem Wind nach Ibiza. Der Hafen war voll, wir ankerten in einer Bucht. Am Abend kochten wir Pasta."
+	got := composeSnippet(text, footer, 160)
+	if length := len([]rune(got)); length > 160 {
+		t.Errorf("composeSnippet() has %d characters, want at most 160: %q", length, got)
+	}
+	if !strings.HasPrefix(got, "Segeln vor Ibiza\n\nWir segelten") || !strings.HasSuffix(got, "\n\n"+footer) {
+		t.Errorf("composeSnippet() = %q", got)
+	}
+
+	if got := composeSnippet("Kurz", "", 300); got != "Kurz" {
+		t.Errorf("composeSnippet() without footer = %q", got)
+	}
+}
+
+// TestSnippetFilename tests the language of the snippet files
+func TestSnippetFilename(t *testing.T) {
+	if got := snippetFilename("social.txt", "de", false); got != "social.de.txt" {
+		t.Errorf("snippetFilename() = %q, want social.de.txt", got)
+	}
+	if got := snippetFilename("social.txt", "de", true); got != "social.txt" {
+		t.Errorf("snippetFilename() on a monolingual site = %q, want social.txt", got)
+	}
+}
+
+// TestWriteSnippet tests writing the snippet file into the bundle, with and without the model
+func TestWriteSnippet(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	config := DefaultConfig()
+	config.Snippets = SnippetConfig{Enabled: true, MaxLength: 300, Hashtags: true, File: "social.txt", URL: "https://example.com/posts/"}
+	c := NewConverter(config, fsys)
+	post := &BlogPost{
+		Meta: BlogMeta{Title: "Ibiza", Date: "2026-01-17", Language: "german", Summary: "Wir segelten nach [[Ibiza]].", Tags: []string{"Segeln"}},
+		Slug: "2026-01-17_Ibiza",
+	}
+	dir := filepath.Join("out", "2026-01-17_Ibiza")
+	path := filepath.Join(dir, "social.de.txt")
+
+	if err := c.writeSnippet(context.Background(), post, "Wir segelten nach Ibiza.", dir); err != nil {
+		t.Fatalf("writeSnippet() error: %v", err)
+	}
+	want := "Ibiza\n\nWir segelten nach Ibiza.\n\n#Segeln\nhttps://example.com/posts/2026-01-17_ibiza/\n"
+	if data, _ := readFile(fsys, path); string(data) != want {
+		t.Errorf("Snippet = %q, want %q", data, want)
+	}
+
+	// An existing snippet is kept, so it can be edited before posting
+	fsys.WriteFile(path, []byte("Edited\n"))
+	var limit int
+	c.snippets, _ = NewSnippetGenerator(fsys, "cache.json", func(ctx context.Context, title, content, language string, max int) (string, error) {
+		limit = max
+		return "\"Sturm und Sonne vor " + title + " (" + language + ")\"", nil
+	})
+	c.writeSnippet(context.Background(), post, "Wir segelten nach Ibiza.", dir)
+	if data, _ := readFile(fsys, path); string(data) != "Edited\n" {
+		t.Errorf("Existing snippet was replaced: %q", data)
+	}
+
+	c.force = true
+	if err := c.writeSnippet(context.Background(), post, "Wir segelten nach Ibiza.", dir); err != nil {
+		t.Fatalf("writeSnippet() error: %v", err)
+	}
+	want = "Sturm und Sonne vor Ibiza (german)\n\n#Segeln\nhttps://example.com/posts/2026-01-17_ibiza/\n"
+	if data, _ := readFile(fsys, path); string(data) != want {
+		t.Errorf("Generated snippet = %q, want %q", data, want)
+	}
+	if want := 300 - len("\n\n#Segeln\nhttps://example.com/posts/2026-01-17_ibiza/") - 1; limit != want {
+		t.Errorf("The model got a limit of %d characters, want %d", limit, want)
+	}
+
+	// A failing model falls back to the title and summary
+	c.snippets, _ = NewSnippetGenerator(NewMemFileSystem(nil), "cache.json", func(ctx context.Context, title, content, language string, max int) (string, error) {
+		return "", fmt.Errorf("offline")
+	})
+	c.writeSnippet(context.Background(), post, "Wir segelten nach Ibiza.", dir)
+	if data, _ := readFile(fsys, path); !strings.HasPrefix(string(data), "Ibiza\n\nWir segelten") {
+		t.Errorf("Snippet with a failing model = %q", data)
+	}
+}
+
+// TestWriteSnippetQueue tests merging the snippets of a conversion into the queue file
+func TestWriteSnippetQueue(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	fsys.MkdirAll(filepath.Join("out", "2025-12-01_Kept"), 0755)
+	fsys.MkdirAll(filepath.Join("out", "2026-01-17_Ibiza"), 0755)
+	fsys.WriteFile(filepath.Join("out", "queue.json"), []byte(`[
+		{"title": "Kept", "lang": "de", "text": "Alt", "path": "2025-12-01_Kept", "posted": true},
+		{"title": "Ibiza", "lang": "de", "text": "Edited", "path": "2026-01-17_Ibiza", "posted": true},
+		{"title": "Removed", "lang": "de", "text": "Alt", "path": "2025-11-01_Removed"}
+	]`))
+
+	config := DefaultConfig()
+	config.Snippets = SnippetConfig{Enabled: true, MaxLength: 300, Queue: "queue.json"}
+	c := NewConverter(config, fsys)
+	for _, post := range []*BlogPost{
+		{Meta: BlogMeta{Title: "Ibiza", Language: "german", Summary: "Segeln"}, Slug: "2026-01-17_Ibiza"},
+		{Meta: BlogMeta{Title: "Ibiza", Language: "english", Summary: "Sailing"}, Slug: "2026-01-17_Ibiza"},
+	} {
+		if err := c.writeSnippet(context.Background(), post, "", filepath.Join("out", post.Slug)); err != nil {
+			t.Fatalf("writeSnippet() error: %v", err)
+		}
+	}
+
+	read := func() []SocialSnippet {
+		if err := c.writeSnippetQueue("out"); err != nil {
+			t.Fatalf("writeSnippetQueue() error: %v", err)
+		}
+		data, err := readFile(fsys, filepath.Join("out", "queue.json"))
+		if err != nil {
+			t.Fatalf("Queue was not written: %v", err)
+		}
+		var queue []SocialSnippet
+		if err := json.Unmarshal(data, &queue); err != nil {
+			t.Fatalf("Queue is no JSON: %v", err)
+		}
+		return queue
+	}
+
+	var got []string
+	for _, snippet := range read() {
+		got = append(got, fmt.Sprintf("%s/%s: %q %v", snippet.Path, snippet.Lang, snippet.Text, snippet.Posted))
+	}
+	want := []string{
+		`2025-12-01_Kept/de: "Alt" true`,
+		`2026-01-17_Ibiza/de: "Edited" true`,
+		`2026-01-17_Ibiza/en: "Ibiza\n\nSailing" false`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Queue =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Forced, the text is replaced and the posted flag stays
+	c.force = true
+	if queue := read(); queue[1].Text != "Ibiza\n\nSegeln" || !queue[1].Posted {
+		t.Errorf("Forced snippet = %+v", queue[1])
+	}
+}
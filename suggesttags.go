@@ -0,0 +1,347 @@
+// This file implements the "suggest-tags" subcommand.
+// A language model proposes tags for the generated posts, preferring the tags
+// the site already uses. The proposals are written to a review file; once it is
+// edited, a second run writes the approved tags into the front matter.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/openai/openai-go"
+)
+
+// DefaultTagReview is the review file of the tag suggestions if none is given.
+const DefaultTagReview = "tags-review.toml"
+
+// maxTagInput is the number of characters of a post sent to the model for tag suggestions.
+const maxTagInput = 6000
+
+// TagReview is the review file: the suggested tags of every post.
+type TagReview struct {
+	Posts []TagSuggestion `toml:"posts"`
+}
+
+// TagSuggestion are the tags suggested for one post.
+type TagSuggestion struct {
+	File      string   `toml:"file"`      // Index file, relative to the output directory
+	Title     string   `toml:"title"`     // Title of the post (to find it while reviewing)
+	Tags      []string `toml:"tags"`      // Tags the post had when the tags were suggested
+	Suggested []string `toml:"suggested"` // Tags to add (remove the ones that don't fit)
+	Approved  bool     `toml:"approved"`  // Set to true to add the suggested tags
+}
+
+// suggestFunc suggests tags for a post, given its current tags and the tags of the site.
+type suggestFunc func(ctx context.Context, title, content string, tags, taxonomy []string) ([]string, error)
+
+// tagReviewHeader explains the review file to the person editing it.
+const tagReviewHeader = `# Tags suggested for the posts. Remove the tags that don't fit from "suggested"
+# and set "approved = true" for the posts that should get them, then run
+#   go run . suggest-tags -apply <output_directory>
+
+`
+
+// runSuggestTags runs the suggest-tags subcommand and returns the process exit code.
+// Usage: go run . suggest-tags [-config converter.toml] [-review tags-review.toml] [-max 5] [-apply] <output_directory>
+func runSuggestTags(args []string) int {
+	flags := flag.NewFlagSet("suggest-tags", flag.ContinueOnError)
+	configPath := flags.String("config", "", "path to a converter.toml configuration file (for the [llm] model)")
+	reviewPath := flags.String("review", DefaultTagReview, "review file the suggestions are written to and read from")
+	maxTags := flags.Int("max", 5, "maximum number of tags suggested per post")
+	apply := flags.Bool("apply", false, "write the approved tags of the review file into the front matter")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() < 1 {
+		fmt.Println("Usage: go run . suggest-tags [-config converter.toml] [-review tags-review.toml] [-max 5] [-apply] <output_directory>")
+		return 2
+	}
+	outputDir := flags.Arg(0)
+
+	// Second pass: apply the approved suggestions
+	if *apply {
+		var review TagReview
+		if _, err := toml.DecodeFile(*reviewPath, &review); err != nil {
+			fmt.Printf("Error: reading review file: %v\n", err)
+			return 2
+		}
+		count, err := applyTagReview(outputDir, &review)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Added tags to %d posts\n", count)
+		return 0
+	}
+
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 2
+	}
+	client, err := newLLMClient(config.LLM)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	review, err := suggestTagReview(ctx, outputDir, config, *maxTags, client.suggestTags)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 1
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(tagReviewHeader)
+	if err := toml.NewEncoder(&buf).Encode(review); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 1
+	}
+	if err := os.WriteFile(*reviewPath, buf.Bytes(), 0644); err != nil {
+		fmt.Printf("Error: writing review file: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Suggested tags for %d posts, review them in %s\n", len(review.Posts), *reviewPath)
+	return 0
+}
+
+// bundlePost is the part of a generated index file used for tag suggestions and summaries.
+type bundlePost struct {
+	Title   string   `toml:"title"`
+	Summary string   `toml:"summary"`
+	Tags    []string `toml:"tags"`
+	Content string   `toml:"-"`
+}
+
+// readBundlePost reads the title, summary, tags and content of a generated index file.
+func readBundlePost(path string) (*bundlePost, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	frontMatter, content, ok := splitFrontMatter(string(data))
+	if !ok {
+		return nil, fmt.Errorf("%s has no front matter", path)
+	}
+	post := &bundlePost{Content: content}
+	if _, err := toml.Decode(frontMatter, post); err != nil {
+		return nil, fmt.Errorf("reading front matter of %s: %w", path, err)
+	}
+	return post, nil
+}
+
+// splitFrontMatter splits an index file into its TOML front matter (without the
+// +++ delimiters) and its content.
+func splitFrontMatter(data string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(data, "+++\n")
+	if !ok {
+		return "", "", false
+	}
+	frontMatter, content, ok := strings.Cut(rest, "\n+++\n")
+	return frontMatter + "\n", content, ok
+}
+
+// suggestTagReview asks for tag suggestions for every post below outputDir.
+// The tags of all posts are the site's taxonomy the suggestions should prefer.
+// Posts without new suggestions are left out of the review.
+func suggestTagReview(ctx context.Context, outputDir string, config *Config, maxTags int, suggest suggestFunc) (*TagReview, error) {
+	files, err := findIndexFiles(outputDir, config)
+	if err != nil {
+		return nil, err
+	}
+
+	posts := make([]*bundlePost, len(files))
+	counts := make(map[string]int)
+	for i, file := range files {
+		if posts[i], err = readBundlePost(file); err != nil {
+			return nil, err
+		}
+		for _, tag := range posts[i].Tags {
+			counts[tag]++
+		}
+	}
+
+	// The most used tags come first
+	taxonomy := make([]string, 0, len(counts))
+	for tag := range counts {
+		taxonomy = append(taxonomy, tag)
+	}
+	sort.Slice(taxonomy, func(i, j int) bool {
+		if counts[taxonomy[i]] != counts[taxonomy[j]] {
+			return counts[taxonomy[i]] > counts[taxonomy[j]]
+		}
+		return taxonomy[i] < taxonomy[j]
+	})
+
+	review := &TagReview{}
+	for i, post := range posts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		content := post.Content
+		if len(content) > maxTagInput {
+			content = strings.ToValidUTF8(content[:maxTagInput], "")
+		}
+		suggested, err := suggest(ctx, post.Title, content, post.Tags, taxonomy)
+		if err != nil {
+			fmt.Printf("Warning: no tags suggested for '%s': %v\n", post.Title, err)
+			continue
+		}
+		suggested = newTags(suggested, post.Tags, taxonomy, maxTags)
+		if len(suggested) == 0 {
+			continue
+		}
+		rel, err := filepath.Rel(outputDir, files[i])
+		if err != nil {
+			rel = files[i]
+		}
+		review.Posts = append(review.Posts, TagSuggestion{
+			File:      filepath.ToSlash(rel),
+			Title:     post.Title,
+			Tags:      post.Tags,
+			Suggested: suggested,
+		})
+	}
+	return review, nil
+}
+
+// newTags cleans up suggested tags: tags the post already has and duplicates are
+// removed (case-insensitively), tags of the site keep their spelling,
+// and at most maxTags tags are returned.
+func newTags(suggested, tags, taxonomy []string, maxTags int) []string {
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		seen[strings.ToLower(tag)] = true
+	}
+	spelling := make(map[string]string)
+	for _, tag := range taxonomy {
+		spelling[strings.ToLower(tag)] = tag
+	}
+
+	var result []string
+	for _, tag := range suggested {
+		tag = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+		key := strings.ToLower(tag)
+		if tag == "" || seen[key] || len(result) >= maxTags {
+			continue
+		}
+		seen[key] = true
+		if existing, ok := spelling[key]; ok {
+			tag = existing
+		}
+		result = append(result, tag)
+	}
+	return result
+}
+
+// applyTagReview adds the suggested tags of the approved posts to their front matter.
+// It returns the number of changed posts.
+func applyTagReview(outputDir string, review *TagReview) (int, error) {
+	count := 0
+	for _, suggestion := range review.Posts {
+		if !suggestion.Approved || len(suggestion.Suggested) == 0 {
+			continue
+		}
+		path := filepath.Join(outputDir, filepath.FromSlash(suggestion.File))
+		if !isInsideDir(outputDir, path) {
+			return count, fmt.Errorf("%s is outside of %s", suggestion.File, outputDir)
+		}
+
+		post, err := readBundlePost(path)
+		if err != nil {
+			return count, err
+		}
+		tags := append(post.Tags, newTags(suggestion.Suggested, post.Tags, nil, len(suggestion.Suggested))...)
+		if len(tags) == len(post.Tags) {
+			continue // Added by hand in the meantime
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return count, err
+		}
+		updated, err := setFrontMatterTags(string(data), tags)
+		if err != nil {
+			return count, fmt.Errorf("%s: %w", suggestion.File, err)
+		}
+		if err := os.WriteFile(path, []byte(updated), 0644); err != nil {
+			return count, err
+		}
+		fmt.Printf("Tagged %s: %s\n", suggestion.File, strings.Join(tags[len(post.Tags):], ", "))
+		count++
+	}
+	return count, nil
+}
+
+// setFrontMatterTags replaces the tags of an index file. Without tags, the
+// tags line is added where the writer puts it: before the categories, the
+// images and the [params] section.
+func setFrontMatterTags(data string, tags []string) (string, error) {
+	frontMatter, content, ok := splitFrontMatter(data)
+	if !ok {
+		return "", fmt.Errorf("no front matter")
+	}
+	tagsLine := "tags = " + tomlValue(tags)
+
+	lines := strings.Split(strings.TrimSuffix(frontMatter, "\n"), "\n")
+	insert := len(lines)
+	for i, line := range lines {
+		if strings.HasPrefix(line, "[") {
+			insert = min(insert, i)
+			break
+		}
+		if strings.HasPrefix(line, "tags = ") {
+			lines[i] = tagsLine
+			return "+++\n" + strings.Join(lines, "\n") + "\n+++\n" + content, nil
+		}
+		if strings.HasPrefix(line, "categories = ") || strings.HasPrefix(line, "images = ") {
+			insert = min(insert, i)
+		}
+	}
+	lines = append(lines[:insert], append([]string{tagsLine}, lines[insert:]...)...)
+	return "+++\n" + strings.Join(lines, "\n") + "\n+++\n" + content, nil
+}
+
+// suggestTags asks the model for tags of a post.
+func (c *llmClient) suggestTags(ctx context.Context, title, content string, tags, taxonomy []string) ([]string, error) {
+	instructions := `You suggest tags for the posts of a blog.
+Prefer tags the blog already uses; only suggest a new tag if none of them fits.
+Suggest only tags that describe what the post is about, in the language and spelling of the existing tags.
+Return ONLY a JSON array of strings, like ["sailing", "mallorca"].`
+
+	prompt := fmt.Sprintf("Tags of the blog: %s\nTags of the post: %s\n\n# %s\n\n%s",
+		strings.Join(taxonomy, ", "), strings.Join(tags, ", "), title, content)
+	answer, err := c.complete(ctx, instructions, []openai.ChatCompletionContentPartUnionParam{
+		openai.TextContentPart(prompt),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parseTagAnswer(answer)
+}
+
+// parseTagAnswer reads the JSON array answered by the model,
+// which is sometimes wrapped in a code block or a sentence.
+func parseTagAnswer(answer string) ([]string, error) {
+	start, end := strings.Index(answer, "["), strings.LastIndex(answer, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("unexpected answer %q", answer)
+	}
+	var tags []string
+	if err := json.Unmarshal([]byte(answer[start:end+1]), &tags); err != nil {
+		return nil, fmt.Errorf("unexpected answer %q: %w", answer, err)
+	}
+	return tags, nil
+}
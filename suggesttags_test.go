@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestNewTags tests cleaning up suggested tags
+func TestNewTags(t *testing.T) {
+	tests := []struct {
+		name      string
+		suggested []string
+		tags      []string
+		taxonomy  []string
+		maxTags   int
+		want      []string
+	}{
+		{"new tags", []string{"jura", "switzerland"}, []string{"hiking"}, nil, 5, []string{"jura", "switzerland"}},
+		{"existing tags are dropped", []string{"Hiking", "jura"}, []string{"hiking"}, nil, 5, []string{"jura"}},
+		{"duplicates are dropped", []string{"jura", "Jura", " #jura "}, nil, nil, 5, []string{"jura"}},
+		{"site spelling is kept", []string{"mallorca"}, nil, []string{"Mallorca"}, 5, []string{"Mallorca"}},
+		{"at most max tags", []string{"a", "b", "c"}, nil, nil, 2, []string{"a", "b"}},
+		{"empty tags", []string{"", "  "}, nil, nil, 5, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newTags(tt.suggested, tt.tags, tt.taxonomy, tt.maxTags)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newTags() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSetFrontMatterTags tests replacing and inserting the tags line
+func TestSetFrontMatterTags(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "replace tags",
+			input: "+++\ntitle = \"A\"\ntags = [\"hiking\"]\n[params]\n  author = \"Ben\"\n+++\n\nText\n",
+			want:  "+++\ntitle = \"A\"\ntags = [\"hiking\", \"jura\"]\n[params]\n  author = \"Ben\"\n+++\n\nText\n",
+		},
+		{
+			name:  "insert before categories",
+			input: "+++\ntitle = \"A\"\ncategories = [\"Trips\"]\n[params]\n  author = \"Ben\"\n+++\n\nText\n",
+			want:  "+++\ntitle = \"A\"\ntags = [\"hiking\", \"jura\"]\ncategories = [\"Trips\"]\n[params]\n  author = \"Ben\"\n+++\n\nText\n",
+		},
+		{
+			name:  "insert before params",
+			input: "+++\ntitle = \"A\"\n[params]\n  tags = \"no\"\n+++\n\nText\n",
+			want:  "+++\ntitle = \"A\"\ntags = [\"hiking\", \"jura\"]\n[params]\n  tags = \"no\"\n+++\n\nText\n",
+		},
+		{
+			name:  "insert at the end",
+			input: "+++\ntitle = \"A\"\n+++\n\nText\n",
+			want:  "+++\ntitle = \"A\"\ntags = [\"hiking\", \"jura\"]\n+++\n\nText\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := setFrontMatterTags(tt.input, []string{"hiking", "jura"})
+			if err != nil {
+				t.Fatalf("setFrontMatterTags() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("setFrontMatterTags() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := setFrontMatterTags("Text\n", nil); err == nil {
+		t.Error("setFrontMatterTags() without front matter should fail")
+	}
+}
+
+// TestParseTagAnswer tests reading the tags answered by the model
+func TestParseTagAnswer(t *testing.T) {
+	got, err := parseTagAnswer("```json\n[\"jura\", \"switzerland\"]\n```")
+	if err != nil {
+		t.Fatalf("parseTagAnswer() error = %v", err)
+	}
+	if want := []string{"jura", "switzerland"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseTagAnswer() = %q, want %q", got, want)
+	}
+
+	if _, err := parseTagAnswer("no tags"); err == nil {
+		t.Error("parseTagAnswer() without array should fail")
+	}
+}
+
+// TestSuggestTagReview tests suggesting tags for the posts and applying the approved ones
+func TestSuggestTagReview(t *testing.T) {
+	contentDir := t.TempDir()
+
+	writeFile := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	writeFile(filepath.Join(contentDir, "2025-01-21_A", "index.de.md"),
+		"+++\ntitle = \"A\"\ntags = [\"hiking\", \"Jura\"]\n[params]\n  author = \"Ben\"\n+++\n\nA hike in the Jura.\n")
+	writeFile(filepath.Join(contentDir, "2025-01-22_B", "index.de.md"),
+		"+++\ntitle = \"B\"\ntags = [\"hiking\"]\n+++\n\nAnother hike.\n")
+	writeFile(filepath.Join(contentDir, "2025-01-23_C", "index.en.md"),
+		"+++\ntitle = \"C\"\n+++\n\nSailing.\n")
+
+	var taxonomies [][]string
+	suggest := func(ctx context.Context, title, content string, tags, taxonomy []string) ([]string, error) {
+		taxonomies = append(taxonomies, taxonomy)
+		switch title {
+		case "A":
+			return []string{"hiking"}, nil // Nothing new
+		case "B":
+			return []string{"jura", "winter"}, nil
+		default:
+			return []string{"sailing"}, nil
+		}
+	}
+
+	review, err := suggestTagReview(context.Background(), contentDir, DefaultConfig(), 5, suggest)
+	if err != nil {
+		t.Fatalf("suggestTagReview() error = %v", err)
+	}
+
+	if want := []string{"hiking", "Jura"}; !reflect.DeepEqual(taxonomies[0], want) {
+		t.Errorf("taxonomy = %q, want %q", taxonomies[0], want)
+	}
+	want := []TagSuggestion{
+		{File: "2025-01-22_B/index.de.md", Title: "B", Tags: []string{"hiking"}, Suggested: []string{"Jura", "winter"}},
+		{File: "2025-01-23_C/index.en.md", Title: "C", Suggested: []string{"sailing"}},
+	}
+	if !reflect.DeepEqual(review.Posts, want) {
+		t.Fatalf("suggestTagReview() = %+v, want %+v", review.Posts, want)
+	}
+
+	// Only the approved post gets its tags
+	review.Posts[0].Approved = true
+	count, err := applyTagReview(contentDir, review)
+	if err != nil {
+		t.Fatalf("applyTagReview() error = %v", err)
+	}
+	if count != 1 {
+		t.Errorf("applyTagReview() changed %d posts, want 1", count)
+	}
+
+	data, err := os.ReadFile(filepath.Join(contentDir, "2025-01-22_B", "index.de.md"))
+	if err != nil {
+		t.Fatalf("Failed to read post: %v", err)
+	}
+	if !strings.Contains(string(data), "tags = [\"hiking\", \"Jura\", \"winter\"]\n") {
+		t.Errorf("approved tags not applied:\n%s", data)
+	}
+	data, err = os.ReadFile(filepath.Join(contentDir, "2025-01-23_C", "index.en.md"))
+	if err != nil {
+		t.Fatalf("Failed to read post: %v", err)
+	}
+	if strings.Contains(string(data), "tags") {
+		t.Errorf("tags of a post that isn't approved applied:\n%s", data)
+	}
+
+	// Files outside of the content directory are refused
+	review.Posts[0].File = "../outside/index.de.md"
+	if _, err := applyTagReview(contentDir, review); err == nil {
+		t.Error("applyTagReview() outside of the content directory should fail")
+	}
+}
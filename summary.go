@@ -0,0 +1,130 @@
+// This file handles generating the summary and meta description of posts
+// without a "summary::" property. Instead of the first paragraph, a large
+// language model writes a short summary in the language of the post.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/openai/openai-go"
+)
+
+// DefaultSummaryCache is the cache file of the generated summaries if none is configured.
+const DefaultSummaryCache = ".summary-cache.json"
+
+// maxSummaryInput is the number of characters of the content sent to the model.
+const maxSummaryInput = 12000
+
+// GeneratedSummary is the summary and meta description of a post written by the model.
+type GeneratedSummary struct {
+	Summary     string `json:"summary"`     // One or two sentences for the post lists
+	Description string `json:"description"` // Meta description for search engines
+}
+
+// summarizeFunc summarizes the content of a post in the given language (e.g. "german").
+type summarizeFunc func(ctx context.Context, title, content, language string) (GeneratedSummary, error)
+
+// SummaryGenerator writes the summary and meta description of posts without a "summary::" property.
+type SummaryGenerator struct {
+	cache     *llmCache     // Summaries by content hash and language (as JSON)
+	summarize summarizeFunc // Asks the model (replaceable in tests)
+}
+
+// NewSummaryGenerator creates a SummaryGenerator and reads its cache.
+// A missing cache file is an empty cache.
+func NewSummaryGenerator(fsys FileSystem, cachePath string, summarize summarizeFunc) (*SummaryGenerator, error) {
+	cache, err := loadLLMCache(fsys, cachePath)
+	if err != nil {
+		return nil, fmt.Errorf("summaries: %w", err)
+	}
+	return &SummaryGenerator{cache: cache, summarize: summarize}, nil
+}
+
+// Apply sets the summary and meta description of a post from its content.
+// If the model fails, the first paragraph stays the summary and a warning is printed.
+func (g *SummaryGenerator) Apply(ctx context.Context, meta *BlogMeta, content string) {
+	if strings.TrimSpace(content) == "" {
+		return
+	}
+	summary, err := g.Summary(ctx, meta.Title, content, llmLanguage(meta.Language))
+	if err != nil {
+		fmt.Printf("Warning: no summary generated for '%s': %v\n", meta.Title, err)
+		return
+	}
+	meta.Summary = summary.Summary
+	if meta.Description == "" {
+		meta.Description = summary.Description
+	}
+}
+
+// Summary returns the summary of a post, from the cache or from the model.
+func (g *SummaryGenerator) Summary(ctx context.Context, title, content, language string) (GeneratedSummary, error) {
+	if len(content) > maxSummaryInput {
+		content = strings.ToValidUTF8(content[:maxSummaryInput], "")
+	}
+
+	key := llmCacheKey([]byte(title+"\n"+content), language)
+	var summary GeneratedSummary
+	if cached, ok := g.cache.get(key); ok && json.Unmarshal([]byte(cached), &summary) == nil {
+		return summary, nil
+	}
+
+	summary, err := g.summarize(ctx, title, content, language)
+	if err != nil {
+		return GeneratedSummary{}, err
+	}
+	summary.Summary = strings.Join(strings.Fields(summary.Summary), " ")
+	summary.Description = strings.Join(strings.Fields(summary.Description), " ")
+	if summary.Summary == "" {
+		return GeneratedSummary{}, fmt.Errorf("empty answer")
+	}
+
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return GeneratedSummary{}, err
+	}
+	g.cache.set(key, string(data))
+	return summary, nil
+}
+
+// Save writes the cache file if new summaries were generated.
+func (g *SummaryGenerator) Save() error {
+	if err := g.cache.save(); err != nil {
+		return fmt.Errorf("summaries: %w", err)
+	}
+	return nil
+}
+
+// summarizePost asks the model for the summary and meta description of a post.
+func (c *llmClient) summarizePost(ctx context.Context, title, content, language string) (GeneratedSummary, error) {
+	instructions := fmt.Sprintf(`You write the summary and the meta description of blog posts, in %s.
+The summary has one or two sentences and makes readers want to read the post.
+The meta description is for search engines and has at most 155 characters.
+Use the tone of the post. Do not invent facts that are not in the post.
+Return ONLY a JSON object like {"summary": "...", "description": "..."}.`, language)
+
+	answer, err := c.complete(ctx, instructions, []openai.ChatCompletionContentPartUnionParam{
+		openai.TextContentPart("# " + title + "\n\n" + content),
+	})
+	if err != nil {
+		return GeneratedSummary{}, err
+	}
+	return parseGeneratedSummary(answer)
+}
+
+// parseGeneratedSummary reads the JSON answer of the model,
+// which is sometimes wrapped in a code block or a sentence.
+func parseGeneratedSummary(answer string) (GeneratedSummary, error) {
+	start, end := strings.Index(answer, "{"), strings.LastIndex(answer, "}")
+	if start < 0 || end < start {
+		return GeneratedSummary{}, fmt.Errorf("unexpected answer %q", answer)
+	}
+	var summary GeneratedSummary
+	if err := json.Unmarshal([]byte(answer[start:end+1]), &summary); err != nil {
+		return GeneratedSummary{}, fmt.Errorf("unexpected answer %q: %w", answer, err)
+	}
+	return summary, nil
+}
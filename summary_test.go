@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// TestSummaryGenerator tests generating, caching and applying summaries
+func TestSummaryGenerator(t *testing.T) {
+	fsys := NewMemFileSystem(nil)
+	calls := 0
+	g, err := NewSummaryGenerator(fsys, "cache.json", func(ctx context.Context, title, content, language string) (GeneratedSummary, error) {
+		calls++
+		return GeneratedSummary{Summary: "A trip to " + title + "\nin " + language + ".", Description: "Hiking on " + title}, nil
+	})
+	if err != nil {
+		t.Fatalf("NewSummaryGenerator() error = %v", err)
+	}
+
+	meta := BlogMeta{Title: "Renan", Language: "English", Summary: "First paragraph"}
+	g.Apply(context.Background(), &meta, "First paragraph\n\nMore")
+	if meta.Summary != "A trip to Renan in english." || meta.Description != "Hiking on Renan" {
+		t.Errorf("Apply() = %q, %q", meta.Summary, meta.Description)
+	}
+
+	// A description from the post is kept
+	meta = BlogMeta{Title: "Renan", Language: "english", Description: "Own description"}
+	g.Apply(context.Background(), &meta, "First paragraph\n\nMore")
+	if meta.Description != "Own description" {
+		t.Errorf("Apply() replaced the description: %q", meta.Description)
+	}
+	if calls != 1 {
+		t.Errorf("The model was asked %d times, want 1 (the second summary is cached)", calls)
+	}
+
+	// The cache is written and read again
+	if err := g.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	cached, err := NewSummaryGenerator(fsys, "cache.json", func(ctx context.Context, title, content, language string) (GeneratedSummary, error) {
+		return GeneratedSummary{}, fmt.Errorf("not cached")
+	})
+	if err != nil {
+		t.Fatalf("NewSummaryGenerator() error = %v", err)
+	}
+	if summary, err := cached.Summary(context.Background(), "Renan", "First paragraph\n\nMore", "english"); err != nil || summary.Description != "Hiking on Renan" {
+		t.Errorf("Summary() from the cache = %+v, %v", summary, err)
+	}
+
+	// A failing model keeps the first paragraph
+	meta = BlogMeta{Title: "Other", Summary: "First paragraph"}
+	cached.Apply(context.Background(), &meta, "First paragraph")
+	if meta.Summary != "First paragraph" || meta.Description != "" {
+		t.Errorf("Apply() with a failing model = %q, %q", meta.Summary, meta.Description)
+	}
+}
+
+// TestParseGeneratedSummary tests reading the answers of the model
+func TestParseGeneratedSummary(t *testing.T) {
+	tests := []struct {
+		name    string
+		answer  string
+		want    GeneratedSummary
+		wantErr bool
+	}{
+		{"JSON", `{"summary": "S", "description": "D"}`, GeneratedSummary{"S", "D"}, false},
+		{"Code block", "```json\n{\"summary\": \"S\", \"description\": \"D\"}\n```", GeneratedSummary{"S", "D"}, false},
+		{"No JSON", "Here is a summary", GeneratedSummary{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseGeneratedSummary(tt.answer)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("parseGeneratedSummary() = %+v, %v; want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+// This file implements the "sync-back" subcommand. It writes the publication
+// state of the converted posts back into their Logseq blocks, like the URL of
+// the published post, so the graph shows which posts are online. Only the
+// properties of the post's block are changed, the rest of the file is kept as it is.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// PublishedURLProperty is the property sync-back writes the address of the published post to.
+const PublishedURLProperty = "published_url"
+
+// bulletRegex matches the bullet of a Logseq block ("\t- ").
+var bulletRegex = regexp.MustCompile(`^(\s*)- `)
+
+// propertyBlock is the part of a file with the properties of a post:
+// the marker line and the property lines around it.
+type propertyBlock struct {
+	start, end int      // First and last line of the properties (end inclusive)
+	indent     string   // Indentation of the property lines below the bullet
+	meta       BlogMeta // The properties of the block
+}
+
+// propertyChange is a property set on a post.
+type propertyChange struct {
+	Key   string
+	Value string
+}
+
+// runSyncBack runs the sync-back subcommand and returns the process exit code.
+// Usage: go run . sync-back [-config converter.toml] [-dry-run] <graph_directory> <output_directory>
+func runSyncBack(args []string) int {
+	flags := flag.NewFlagSet("sync-back", flag.ContinueOnError)
+	configPath := flags.String("config", "", "path to a converter.toml configuration file")
+	dryRun := flags.Bool("dry-run", false, "print the changes without writing them into the graph")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() < 2 {
+		fmt.Println("Usage: go run . sync-back [-config converter.toml] [-dry-run] <graph_directory> <output_directory>")
+		return 2
+	}
+	graphDir, outputDir := flags.Arg(0), flags.Arg(1)
+
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 2
+	}
+	if config.SyncBack.URL == "" && len(config.SyncBack.Properties) == 0 {
+		fmt.Println("Error: nothing to write back, set the url or the properties of [sync_back] in the configuration")
+		return 2
+	}
+	if extractor, err := openExtractor(OSFileSystem{}, graphDir, config); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 2
+	} else if _, ok := extractor.(logseqGraph); !ok {
+		fmt.Println("Error: sync-back only writes into Logseq graphs")
+		return 2
+	}
+
+	posts, err := loadPostStatus(config, graphDir, outputDir)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 1
+	}
+	updated, failed := syncBack(config, posts, *dryRun)
+
+	verb := "Updated"
+	if *dryRun {
+		verb = "Would update"
+	}
+	fmt.Printf("%s %d posts\n", verb, updated)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// syncBack sets the properties on the online posts that are converted.
+// The files are changed one after another; a post whose block can't be
+// found is reported and skipped. It returns the number of updated and failed posts.
+func syncBack(config *Config, posts []postStatus, dryRun bool) (int, int) {
+	marker, err := NewBlogMarker(config.Marker)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 0, len(posts)
+	}
+
+	// Posts of the same file are changed together, in a stable order
+	byFile := make(map[string][]postStatus)
+	var files []string
+	for _, post := range posts {
+		if !post.Converted() || post.Post.Meta.Status != "online" || post.Post.Type != nil {
+			continue
+		}
+		if _, ok := byFile[post.Post.SourcePath]; !ok {
+			files = append(files, post.Post.SourcePath)
+		}
+		byFile[post.Post.SourcePath] = append(byFile[post.Post.SourcePath], post)
+	}
+	sort.Strings(files)
+
+	updated, failed := 0, 0
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			failed += len(byFile[file])
+			continue
+		}
+		lines := strings.Split(string(data), "\n")
+
+		changedFile := false
+		for _, post := range byFile[file] {
+			block, ok := findPostBlock(lines, marker, post.Post)
+			if !ok {
+				fmt.Printf("Warning: %s: the block of '%s' wasn't found\n", file, post.Post.Meta.Title)
+				failed++
+				continue
+			}
+
+			var changes []string
+			for _, change := range syncBackChanges(config.SyncBack, post.Post) {
+				var changed bool
+				if lines, changed = setBlockProperty(lines, &block, change.Key, change.Value); changed {
+					changes = append(changes, change.Key+":: "+change.Value)
+				}
+			}
+			if len(changes) > 0 {
+				fmt.Printf("%s '%s': %s\n", file, post.Post.Meta.Title, strings.Join(changes, ", "))
+				changedFile = true
+				updated++
+			}
+		}
+
+		if changedFile && !dryRun {
+			if err := os.WriteFile(file, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				failed++
+			}
+		}
+	}
+	return updated, failed
+}
+
+// syncBackChanges returns the properties set on a post: the published URL
+// and the configured properties, sorted by key.
+func syncBackChanges(config SyncBackConfig, post *BlogPost) []propertyChange {
+	var changes []propertyChange
+	if config.URL != "" {
+		changes = append(changes, propertyChange{PublishedURLProperty, publishedURL(config.URL, post)})
+	}
+
+	keys := make([]string, 0, len(config.Properties))
+	for key := range config.Properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		changes = append(changes, propertyChange{key, config.Properties[key]})
+	}
+	return changes
+}
+
+// publishedURL returns the address of a post below the base URL of the output directory.
+// Hugo writes the paths of pages in lowercase (unless disablePathToLower is set).
+func publishedURL(base string, post *BlogPost) string {
+	return bundleURL(base, post.BundlePath())
+}
+
+// bundleURL returns the address of a bundle path ("trips/2026-01-17_Ibiza") below the base URL.
+func bundleURL(base, bundlePath string) string {
+	segments := strings.Split(strings.ToLower(bundlePath), "/")
+	address, err := url.JoinPath(base, segments...)
+	if err != nil {
+		return strings.TrimSuffix(base, "/") + "/" + path.Join(segments...) + "/"
+	}
+	return address + "/"
+}
+
+// findPostBlock finds the property block of a post in the lines of its file.
+// Blocks are told apart by their title; a block without a title
+// (e.g. of a page titled by its name) is only used if it is the only one.
+func findPostBlock(lines []string, marker *BlogMarker, post *BlogPost) (propertyBlock, bool) {
+	blocks := findPropertyBlocks(lines, marker)
+	for _, block := range blocks {
+		if block.meta.Title != "" && block.meta.Title == post.Meta.Title {
+			return block, true
+		}
+	}
+	if len(blocks) == 1 && blocks[0].meta.Title == "" {
+		return blocks[0], true
+	}
+	return propertyBlock{}, false
+}
+
+// findPropertyBlocks finds the property blocks with the marker: the block
+// starts at the bullet of the marker line (or the first property of a page)
+// and ends at the last property line or continuation of a property value.
+func findPropertyBlocks(lines []string, marker *BlogMarker) []propertyBlock {
+	var blocks []propertyBlock
+	for i := 0; i < len(lines); i++ {
+		if !marker.Matches(bulletRegex.ReplaceAllString(lines[i], "$1")) {
+			continue
+		}
+
+		// Back to the bullet of the block, or the first property of the page
+		start := i
+		for start > 0 && !bulletRegex.MatchString(lines[start]) && isPropertyLine(lines[start-1]) {
+			start--
+		}
+		block := propertyBlock{start: start}
+		if match := bulletRegex.FindStringSubmatch(lines[start]); match != nil {
+			block.indent = match[1] + "  "
+		}
+
+		// Forward over the properties and wrapped values, up to a blank line or the next bullet
+		end := i
+		for end+1 < len(lines) && strings.TrimSpace(lines[end+1]) != "" && !bulletRegex.MatchString(lines[end+1]) {
+			end++
+		}
+		block.end = end
+
+		properties := make([]string, 0, end-start+1)
+		for _, line := range lines[start : end+1] {
+			properties = append(properties, bulletRegex.ReplaceAllString(line, "$1"))
+		}
+		block.meta = NewMetadataParser().Parse(properties)
+		blocks = append(blocks, block)
+		i = end
+	}
+	return blocks
+}
+
+// isPropertyLine reports whether a line is a property ("status:: online", also with a bullet).
+func isPropertyLine(line string) bool {
+	return blockPropertyRegex.MatchString(bulletRegex.ReplaceAllString(line, "$1"))
+}
+
+// setBlockProperty sets a property of a block: an existing property line is
+// replaced, a new one is added after the last line of the block. It returns
+// the changed lines and whether anything changed.
+func setBlockProperty(lines []string, block *propertyBlock, key, value string) ([]string, bool) {
+	for i := block.start; i <= block.end; i++ {
+		line := lines[i]
+		prefix := ""
+		if match := bulletRegex.FindStringSubmatch(line); match != nil {
+			prefix, line = match[0], line[len(match[0]):]
+		}
+		match := blockPropertyRegex.FindStringSubmatch(line)
+		if match == nil || normalizeKey(match[1]) != normalizeKey(key) {
+			continue
+		}
+		if strings.TrimSpace(match[2]) == value {
+			return lines, false
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		lines[i] = prefix + indent + match[1] + ":: " + value
+		return lines, true
+	}
+
+	lines = append(lines[:block.end+1], append([]string{block.indent + key + ":: " + value}, lines[block.end+1:]...)...)
+	block.end++
+	return lines, true
+}
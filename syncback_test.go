@@ -0,0 +1,163 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"logseq-to-hugo-converter/internal/testgraph"
+)
+
+// TestSetBlockProperty tests changing the properties of the blocks of journals and pages
+func TestSetBlockProperty(t *testing.T) {
+	tests := []struct {
+		name   string
+		marker string
+		title  string
+		source string
+		key    string
+		value  string
+		want   string
+	}{
+		{
+			name:   "journal adds a property",
+			title:  "Renan",
+			source: "- [[Blog]]\n\t- type:: blog\n\t  status:: online\n\t  title:: Renan\n\t- Content\n",
+			key:    "published_url",
+			value:  "https://example.com/posts/renan/",
+			want:   "- [[Blog]]\n\t- type:: blog\n\t  status:: online\n\t  title:: Renan\n\t  published_url:: https://example.com/posts/renan/\n\t- Content\n",
+		},
+		{
+			name:   "page replaces a property",
+			title:  "Renan",
+			source: "type:: blog\nstatus:: draft\ntitle:: Renan\n\n- Content\n",
+			key:    "status",
+			value:  "online",
+			want:   "type:: blog\nstatus:: online\ntitle:: Renan\n\n- Content\n",
+		},
+		{
+			name:   "marker line with a bullet",
+			title:  "Renan",
+			source: "- type:: blog\n  title:: Renan\n  status:: draft\n",
+			key:    "type",
+			value:  "blog, published",
+			want:   "- type:: blog, published\n  title:: Renan\n  status:: draft\n",
+		},
+		{
+			name:   "second post of a journal",
+			title:  "Ibiza",
+			source: "- type:: blog\n  title:: Renan\n- type:: blog\n  title:: Ibiza\n  summary:: Sailing\n  and swimming\n- Content\n",
+			key:    "published",
+			value:  "true",
+			want:   "- type:: blog\n  title:: Renan\n- type:: blog\n  title:: Ibiza\n  summary:: Sailing\n  and swimming\n  published:: true\n- Content\n",
+		},
+		{
+			name:   "tag marker",
+			marker: "#blog",
+			title:  "Renan",
+			source: "- Trip #blog\n  title:: Renan\n\t- Content\n",
+			key:    "published",
+			value:  "true",
+			want:   "- Trip #blog\n  title:: Renan\n  published:: true\n\t- Content\n",
+		},
+		{
+			name:   "key spelled differently",
+			title:  "Renan",
+			source: "type:: blog\ntitle:: Renan\npublished-URL:: old\n",
+			key:    "published_url",
+			value:  "new",
+			want:   "type:: blog\ntitle:: Renan\npublished-URL:: new\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			markerText := tt.marker
+			if markerText == "" {
+				markerText = DefaultMarker
+			}
+			marker, err := NewBlogMarker(markerText)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			lines := strings.Split(tt.source, "\n")
+			block, ok := findPostBlock(lines, marker, &BlogPost{Meta: BlogMeta{Title: tt.title}})
+			if !ok {
+				t.Fatalf("findPostBlock() found no block of %q", tt.title)
+			}
+			lines, changed := setBlockProperty(lines, &block, tt.key, tt.value)
+			if got := strings.Join(lines, "\n"); got != tt.want || !changed {
+				t.Errorf("setBlockProperty() = %v\n%s\nwant\n%s", changed, got, tt.want)
+			}
+
+			// Setting the same value again changes nothing
+			if _, changed := setBlockProperty(lines, &block, tt.key, tt.value); changed {
+				t.Error("setBlockProperty() changed an unchanged value")
+			}
+		})
+	}
+}
+
+// TestPublishedURL tests the addresses of bundles on the site
+func TestPublishedURL(t *testing.T) {
+	tests := []struct {
+		base string
+		post BlogPost
+		want string
+	}{
+		{"https://example.com/posts/", BlogPost{Slug: "2026-01-17_Renan"}, "https://example.com/posts/2026-01-17_renan/"},
+		{"https://example.com/posts", BlogPost{Slug: "2026-01-17_Renan", Section: "trips/jura"}, "https://example.com/posts/trips/jura/2026-01-17_renan/"},
+		{"https://example.com/", BlogPost{Slug: "2026-01-17_Frühling"}, "https://example.com/2026-01-17_fr%C3%BChling/"},
+	}
+	for _, tt := range tests {
+		if got := publishedURL(tt.base, &tt.post); got != tt.want {
+			t.Errorf("publishedURL(%q, %q) = %q, want %q", tt.base, tt.post.BundlePath(), got, tt.want)
+		}
+	}
+}
+
+// TestSyncBack tests writing the published URL into the graph for the converted posts only
+func TestSyncBack(t *testing.T) {
+	dir := t.TempDir()
+	converted := testgraph.Post{Title: "Renan", Date: "2026-01-17", Blocks: []string{"Hiking."}}
+	other := testgraph.Post{Title: "Ibiza", Date: "2026-02-01", Blocks: []string{"Sailing."}}
+	graph := testgraph.New().
+		Journal("2026_01_17", converted.JournalBlock("[[Blog]]")+other.JournalBlock("[[Blog]]")).
+		Page("Draft", testgraph.Post{Title: "Draft", Date: "2026-03-01", Status: "draft"}.PageContent())
+	graphDir, outputDir := filepath.Join(dir, "graph"), filepath.Join(dir, "posts")
+	if err := graph.WriteDir(graphDir); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(outputDir, "2026-01-17_Renan", "index.de.md"), "+++\n+++\n")
+	writeTestFile(t, filepath.Join(outputDir, "2026-03-01_Draft", "index.de.md"), "+++\n+++\n")
+
+	config := DefaultConfig()
+	config.SyncBack = SyncBackConfig{URL: "https://example.com/posts/", Properties: map[string]string{"published": "true"}}
+	journal := filepath.Join(graphDir, "journals", "2026_01_17.md")
+	before, _ := os.ReadFile(journal)
+
+	posts, err := loadPostStatus(config, graphDir, outputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated, failed := syncBack(config, posts, true); updated != 1 || failed != 0 {
+		t.Errorf("syncBack() dry run = %d updated, %d failed, want 1 and 0", updated, failed)
+	}
+	if after, _ := os.ReadFile(journal); string(after) != string(before) {
+		t.Error("syncBack() dry run changed the graph")
+	}
+
+	if updated, failed := syncBack(config, posts, false); updated != 1 || failed != 0 {
+		t.Errorf("syncBack() = %d updated, %d failed, want 1 and 0", updated, failed)
+	}
+	after, _ := os.ReadFile(journal)
+	want := strings.Replace(string(before), "title:: Renan\n", "title:: Renan\n\t  published_url:: https://example.com/posts/2026-01-17_renan/\n\t  published:: true\n", 1)
+	if string(after) != want {
+		t.Errorf("journal after syncBack() =\n%s\nwant\n%s", after, want)
+	}
+	if page, _ := os.ReadFile(filepath.Join(graphDir, "pages", "Draft.md")); strings.Contains(string(page), "published") {
+		t.Errorf("syncBack() changed a draft:\n%s", page)
+	}
+}
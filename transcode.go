@@ -0,0 +1,153 @@
+// This file handles re-encoding the videos of the posts with ffmpeg.
+// Phone videos are often 100 MB and more; with transcoding enabled they are
+// written into the bundle as web-friendly H.264 (.mp4) or VP9 (.webm) at the
+// configured bitrate and height, and the size savings are reported.
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Video codecs of the transcoding.
+const (
+	VideoCodecH264 = "h264" // H.264 and AAC in an .mp4 file
+	VideoCodecVP9  = "vp9"  // VP9 and Opus in a .webm file
+)
+
+// videoBitrateRegex matches a bitrate in ffmpeg notation: "2M", "1500k", "800000".
+var videoBitrateRegex = regexp.MustCompile(`^\d+(\.\d+)?[kKM]?$`)
+
+// VideoTranscoder re-encodes videos with ffmpeg and counts the size savings.
+// It is safe to use from several goroutines.
+type VideoTranscoder struct {
+	ffmpeg string // Path of the ffmpeg program
+	config VideoTranscodeConfig
+
+	mu     sync.Mutex
+	count  int   // Transcoded videos
+	before int64 // Size of the original videos
+	after  int64 // Size of the transcoded videos
+}
+
+// NewVideoTranscoder creates a transcoder with the ffmpeg program of the
+// configuration, which is looked up with lookPath (exec.LookPath outside of tests).
+func NewVideoTranscoder(config VideoTranscodeConfig, lookPath func(string) (string, error)) (*VideoTranscoder, error) {
+	program := config.FFmpeg
+	if program == "" {
+		program = "ffmpeg"
+	}
+	ffmpeg, err := lookPath(program)
+	if err != nil {
+		return nil, fmt.Errorf("%s not found: %w", program, err)
+	}
+	return &VideoTranscoder{ffmpeg: ffmpeg, config: config}, nil
+}
+
+// Name returns the name of a transcoded video in the bundle ("clip.mov" -> "clip.mp4").
+func (t *VideoTranscoder) Name(name string) string {
+	ext := ".mp4"
+	if t.config.Codec == VideoCodecVP9 {
+		ext = ".webm"
+	}
+	return strings.TrimSuffix(name, path.Ext(name)) + ext
+}
+
+// ffmpegArgs returns the arguments of ffmpeg to transcode src into dst.
+// The height is only reduced (never enlarged), the width keeps the aspect ratio.
+func (t *VideoTranscoder) ffmpegArgs(src, dst string) []string {
+	args := []string{"-y", "-v", "error", "-i", src}
+	if t.config.MaxHeight > 0 {
+		args = append(args, "-vf", fmt.Sprintf("scale=-2:'min(%d,ih)'", t.config.MaxHeight))
+	}
+	if t.config.Codec == VideoCodecVP9 {
+		args = append(args, "-c:v", "libvpx-vp9", "-b:v", t.config.Bitrate, "-c:a", "libopus", "-b:a", "128k")
+	} else {
+		args = append(args, "-c:v", "libx264", "-preset", "medium", "-b:v", t.config.Bitrate, "-pix_fmt", "yuv420p",
+			"-c:a", "aac", "-b:a", "128k", "-movflags", "+faststart")
+	}
+	return append(args, dst)
+}
+
+// Transcode re-encodes the video src into dst on fsys. ffmpeg writes into a
+// temporary file first, so dry runs and archives get the transcoded video too.
+// A video that is already transcoded (dst is not older than src) is kept.
+func (t *VideoTranscoder) Transcode(ctx context.Context, fsys FileSystem, src, dst string) error {
+	srcInfo, err := fsys.Stat(src)
+	if err != nil {
+		return err
+	}
+	if dstInfo, err := fsys.Stat(dst); err == nil && !dstInfo.ModTime().Before(srcInfo.ModTime()) {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp("", "logseq-to-hugo-*"+filepath.Ext(dst))
+	if err != nil {
+		return err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, t.ffmpeg, t.ffmpegArgs(src, tmp.Name())...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("transcoding %s: %w: %s", src, err, strings.TrimSpace(stderr.String()))
+	}
+
+	// The temporary file is on disk, which fsys reads through (also in dry runs)
+	if err := copyFile(ctx, fsys, tmp.Name(), dst); err != nil {
+		return err
+	}
+	info, err := os.Stat(tmp.Name())
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	t.count++
+	t.before += srcInfo.Size()
+	t.after += info.Size()
+	t.mu.Unlock()
+	fmt.Printf("Transcoded %s: %s\n", filepath.Base(dst), sizeChange(srcInfo.Size(), info.Size()))
+	return nil
+}
+
+// Totals returns the number of transcoded videos and their sizes before and after.
+func (t *VideoTranscoder) Totals() (count int, before, after int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.count, t.before, t.after
+}
+
+// sizeChange formats the change of a file size: "120.5 MB -> 18.2 MB, 85% smaller".
+func sizeChange(before, after int64) string {
+	change := ""
+	switch {
+	case before > 0 && after < before:
+		change = fmt.Sprintf(", %d%% smaller", (before-after)*100/before)
+	case after > before:
+		change = ", larger"
+	}
+	return formatSize(before) + " -> " + formatSize(after) + change
+}
+
+// formatSize formats a file size in bytes, kB or MB.
+func formatSize(size int64) string {
+	switch {
+	case size >= 1000*1000:
+		return strconv.FormatFloat(float64(size)/1e6, 'f', 1, 64) + " MB"
+	case size >= 1000:
+		return strconv.FormatFloat(float64(size)/1e3, 'f', 1, 64) + " kB"
+	}
+	return fmt.Sprintf("%d B", size)
+}
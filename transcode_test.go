@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+// TestFFmpegArgs tests the ffmpeg arguments of both codecs
+func TestFFmpegArgs(t *testing.T) {
+	h264 := &VideoTranscoder{config: VideoTranscodeConfig{Codec: VideoCodecH264, Bitrate: "2M", MaxHeight: 720}}
+	want := []string{"-y", "-v", "error", "-i", "in.mov", "-vf", "scale=-2:'min(720,ih)'",
+		"-c:v", "libx264", "-preset", "medium", "-b:v", "2M", "-pix_fmt", "yuv420p",
+		"-c:a", "aac", "-b:a", "128k", "-movflags", "+faststart", "out.mp4"}
+	if got := h264.ffmpegArgs("in.mov", "out.mp4"); !reflect.DeepEqual(got, want) {
+		t.Errorf("ffmpegArgs() = %q, want %q", got, want)
+	}
+
+	vp9 := &VideoTranscoder{config: VideoTranscodeConfig{Codec: VideoCodecVP9, Bitrate: "1500k"}}
+	want = []string{"-y", "-v", "error", "-i", "in.mov", "-c:v", "libvpx-vp9", "-b:v", "1500k", "-c:a", "libopus", "-b:a", "128k", "out.webm"}
+	if got := vp9.ffmpegArgs("in.mov", "out.webm"); !reflect.DeepEqual(got, want) {
+		t.Errorf("ffmpegArgs() = %q, want %q", got, want)
+	}
+	if name := vp9.Name("2024/clip.MOV"); name != "2024/clip.webm" {
+		t.Errorf("Name() = %q, want 2024/clip.webm", name)
+	}
+}
+
+// TestSizeChange tests formatting the size savings
+func TestSizeChange(t *testing.T) {
+	tests := []struct {
+		before, after int64
+		want          string
+	}{
+		{120_500_000, 18_200_000, "120.5 MB -> 18.2 MB, 84% smaller"},
+		{900, 1500, "900 B -> 1.5 kB, larger"},
+		{1000, 1000, "1.0 kB -> 1.0 kB"},
+	}
+	for _, tt := range tests {
+		if got := sizeChange(tt.before, tt.after); got != tt.want {
+			t.Errorf("sizeChange(%d, %d) = %q, want %q", tt.before, tt.after, got, tt.want)
+		}
+	}
+}
+
+// TestProcessContent_Transcode tests re-encoding videos with a fake ffmpeg that writes a smaller file
+func TestProcessContent_Transcode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake ffmpeg is a shell script")
+	}
+	ffmpeg := filepath.Join(t.TempDir(), "ffmpeg")
+	if err := os.WriteFile(ffmpeg, []byte("#!/bin/sh\nfor last; do :; done\nprintf small > \"$last\"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewVideoTranscoder(VideoTranscodeConfig{}, func(string) (string, error) { return "", errors.New("not found") }); err == nil {
+		t.Error("NewVideoTranscoder() without ffmpeg should fail")
+	}
+	transcoder, err := NewVideoTranscoder(VideoTranscodeConfig{FFmpeg: ffmpeg, Codec: VideoCodecH264, Bitrate: "2M"}, func(path string) (string, error) { return path, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inputDir := setupGraph(t, "clip.mov")
+	outputDir := t.TempDir()
+	for run := 1; run <= 2; run++ {
+		processor := NewImageProcessor(NewCopyManager(OSFileSystem{}), inputDir, outputDir)
+		processor.transcoder = transcoder
+		got := processor.ProcessContent(context.Background(), "![clip.mov](../assets/clip.mov)")
+		if want := `{{< video src="clip.mp4" >}}`; got != want {
+			t.Errorf("ProcessContent() = %q, want %q", got, want)
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(outputDir, "clip.mp4")); err != nil || string(data) != "small" {
+		t.Errorf("clip.mp4 = %q, %v, want the transcoded video", data, err)
+	}
+	// The second run keeps the transcoded video
+	if count, before, after := transcoder.Totals(); count != 1 || before != int64(len("data of clip.mov")) || after != 5 {
+		t.Errorf("Totals() = %d, %d, %d, want 1 video of 16 -> 5 bytes", count, before, after)
+	}
+}
@@ -2,6 +2,8 @@
 // This file defines the core data types used throughout the application.
 package main
 
+import "path"
+
 // BlogMeta represents the metadata (information about) of a blog post.
 // In Go, a struct is a collection of fields grouped together.
 // The fields use uppercase first letters, which makes them "exported" (publicly accessible).
@@ -13,11 +15,92 @@ type BlogMeta struct {
 	Summary  string // Short summary or excerpt of the post
 	Status   string // Publication status (e.g., "online", "draft")
 	Language string // Language of the post (e.g., "german", "english")
+	TOC      string // Table of contents: "true" or "false" (empty = decided automatically)
+
+	ExpiryDate  string // Date after which the post is no longer published (empty = never)
+	Description string // Meta description for search engines (empty = not written)
+
+	AuthorEmail string // Email address of the author (from the author registry)
+	AuthorURL   string // Profile URL of the author (from the author registry)
+
+	Tags       []string          // Tags from the "tags::" property
+	Categories []string          // Categories for Hugo's categories taxonomy
+	Layout     string            // Hugo layout of the content type (empty for the default layout)
+	Params     map[string]string // Params of the content type (fixed values and properties)
+	Related    []string          // Bundle paths of related posts (set when converting several posts)
+
+	WordCount   int // Number of words in the content (0 = not written)
+	ReadingTime int // Estimated reading time in minutes (0 = not written)
+
+	Properties map[string]string // Other properties by normalized key (e.g., "comments" -> "false")
+	Flags      map[string]bool   // Boolean params by param name (from the mapped properties)
+
+	Translate          *bool    // Whether the translation tool translates the post ("translate::" property, nil = not written)
+	TranslateLanguages []string // Hugo language keys the translation tool translates the post to (nil = all)
+
+	Location *LocationMeta // Place of the post from the "location::" property (nil = none)
+
+	Data []DataField // Data properties for the [params.data] section (nil = not written)
+
+	Images  []string    // Bundle images for social previews (Hugo's "images" front matter)
+	Aliases []string    // Earlier URL paths of a moved post (Hugo writes redirect pages for them)
+	Social  *SocialMeta // Social media preview metadata (nil = not written)
+
+	IndieWeb *IndieWebMeta // IndieWeb metadata for the [params.indieweb] section (nil = not written)
+
+	Publish *PublishEvent // Latest publish event for the [params.publish] section (nil = not written)
+}
+
+// SocialMeta contains the metadata for social media previews (OpenGraph and Twitter cards).
+type SocialMeta struct {
+	Title       string // Preview title
+	Description string // Preview text
+	Image       string // Preview image in the bundle (e.g., "og-image.jpeg")
+	Card        string // Twitter card type ("summary" or "summary_large_image")
+	Site        string // Twitter/X handle of the site
+}
+
+// LocationMeta is the place a post is about, given as coordinates or as a place name.
+type LocationMeta struct {
+	Name           string  // Place name (e.g., "Zürich")
+	Lat            float64 // Latitude in decimal degrees
+	Lng            float64 // Longitude in decimal degrees
+	HasCoordinates bool    // Lat and Lng are set
+}
+
+// DataField is a structured data property of a post (e.g., "temperature:: 18").
+type DataField struct {
+	Key   string // Normalized property key (e.g., "temperature")
+	Label string // Label for the table header (e.g., "Temperature (°C)")
+	Value string // Property value as written in Logseq
 }
 
 // BlogPost represents a complete blog post with both metadata and content.
 // This struct combines the BlogMeta with the actual content blocks.
 type BlogPost struct {
-	Meta    BlogMeta // The metadata about the post (embedded struct)
-	Content []string // A slice (dynamic array) of content blocks/paragraphs
+	Meta       BlogMeta           // The metadata about the post (embedded struct)
+	Content    []ContentBlock     // A slice (dynamic array) of content blocks/paragraphs
+	SourcePath string             // Path of the Logseq file the post was extracted from
+	Ancestors  []string           // Pages referenced by the bullets the post is nested under (outermost first)
+	Kind       string             // KindArticle, or the kind of a block that only looks like a post (e.g. KindFlashcard)
+	Type       *ContentTypeConfig // Configured content type (nil for blog posts)
+	Slug       string             // Bundle directory name (e.g., "2026-01-17_Title")
+	Section    string             // Section directory of the bundle (e.g., "blog/trips", "" if none)
+
+	SummaryFromContent bool   // The summary is the first paragraph, there is no "summary::" property
+	SourceHash         string // Hash of the post as extracted (set if the publish log is enabled)
+}
+
+// BundlePath returns the path of the bundle below the output directory
+// (e.g., "blog/trips/2026-01-17_Title"), used for links between posts.
+func (p *BlogPost) BundlePath() string {
+	return path.Join(p.Section, p.Slug)
+}
+
+// ContentBlock represents a single Logseq block (bullet) of the post content.
+// Block properties like "id:: ..." or "collapsed:: true" are removed from the text
+// but kept in Properties so later processing steps can use them.
+type ContentBlock struct {
+	Text       string            // Markdown text of the block without property lines
+	Properties map[string]string // Block properties by key (nil if the block has none)
 }
@@ -0,0 +1,105 @@
+// This file handles the version of the converter, which is printed with
+// -version and recorded in the migration report and the publish log.
+// Release builds stamp it with
+//
+//	go build -ldflags "-X main.version=v1.4.0 -X main.commit=$(git rev-parse HEAD)"
+//
+// Other builds take it from the build information Go embeds: the module
+// version of "go install ...@v1.4.0", or the revision of the checkout.
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"runtime/debug"
+	"strings"
+)
+
+// pseudoVersionRegex matches the versions Go makes up for untagged commits
+// ("v0.0.0-20260117093000-1a2b3c4d5e6f"), the commit is reported on its own.
+var pseudoVersionRegex = regexp.MustCompile(`^v\d+\.\d+\.\d+-(?:\w+\.)?(?:0\.)?\d{14}-[0-9a-f]{12}$`)
+
+// Set with -ldflags "-X main.version=... -X main.commit=... -X main.date=...".
+var (
+	version = "" // Release version ("v1.4.0")
+	commit  = "" // Commit the release was built from
+	date    = "" // Build time
+)
+
+// buildVersion is the version of the running converter.
+type buildVersion struct {
+	Version   string // Release or module version, "devel" if there is none
+	Commit    string // Commit (first 12 characters), "" if unknown
+	Modified  bool   // The checkout had uncommitted changes
+	Date      string // Build time, or the time of the commit, "" if unknown
+	GoVersion string // Go version the converter was built with
+}
+
+// readVersion combines the stamped version with the embedded build information.
+// Stamped values win, so release builds report what the release says.
+func readVersion() buildVersion {
+	v := buildVersion{Version: version, Commit: commit, Date: date}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		v.GoVersion = info.GoVersion
+		if module := strings.TrimSuffix(info.Main.Version, "+dirty"); v.Version == "" && module != "(devel)" && !pseudoVersionRegex.MatchString(module) {
+			v.Version = module
+		}
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if v.Commit == "" {
+					v.Commit = setting.Value
+				}
+			case "vcs.modified":
+				v.Modified = setting.Value == "true" && commit == ""
+			case "vcs.time":
+				if v.Date == "" {
+					v.Date = setting.Value
+				}
+			}
+		}
+	}
+	if v.Version == "" {
+		v.Version = "devel"
+	}
+	if len(v.Commit) > 12 {
+		v.Commit = v.Commit[:12]
+	}
+	return v
+}
+
+// String returns the version in one line ("v1.4.0 (1a2b3c4d5e6f)", "devel (1a2b3c4d5e6f-dirty)").
+func (v buildVersion) String() string {
+	if v.Commit == "" {
+		return v.Version
+	}
+	commit := v.Commit
+	if v.Modified {
+		commit += "-dirty"
+	}
+	return fmt.Sprintf("%s (%s)", v.Version, commit)
+}
+
+// toolVersion returns the version of the converter in one line.
+func toolVersion() string {
+	return readVersion().String()
+}
+
+// printVersion writes the version with the details of the build.
+func printVersion(w io.Writer, v buildVersion) {
+	fmt.Fprintf(w, "logseq-to-hugo-converter %s\n", v.Version)
+	if v.Commit != "" {
+		modified := ""
+		if v.Modified {
+			modified = " (uncommitted changes)"
+		}
+		fmt.Fprintf(w, "  Commit: %s%s\n", v.Commit, modified)
+	}
+	if v.Date != "" {
+		fmt.Fprintf(w, "  Date:   %s\n", v.Date)
+	}
+	if v.GoVersion != "" {
+		fmt.Fprintf(w, "  Go:     %s\n", v.GoVersion)
+	}
+}
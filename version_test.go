@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestBuildVersion_String tests the one-line version of stamped and development builds
+func TestBuildVersion_String(t *testing.T) {
+	tests := []struct {
+		version buildVersion
+		want    string
+	}{
+		{buildVersion{Version: "v1.4.0", Commit: "1a2b3c4d5e6f"}, "v1.4.0 (1a2b3c4d5e6f)"},
+		{buildVersion{Version: "devel", Commit: "1a2b3c4d5e6f", Modified: true}, "devel (1a2b3c4d5e6f-dirty)"},
+		{buildVersion{Version: "v1.4.0"}, "v1.4.0"},
+	}
+	for _, tt := range tests {
+		if got := tt.version.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+// TestReadVersion tests that stamped values win over the build information
+func TestReadVersion(t *testing.T) {
+	defer func(v, c, d string) { version, commit, date = v, c, d }(version, commit, date)
+	version, commit, date = "v1.4.0", "0123456789abcdef0123", "2026-01-17T10:00:00Z"
+
+	v := readVersion()
+	if v.Version != "v1.4.0" || v.Commit != "0123456789ab" || v.Modified || v.Date != "2026-01-17T10:00:00Z" {
+		t.Errorf("readVersion() = %+v, want the stamped version", v)
+	}
+	if got := toolVersion(); got != "v1.4.0 (0123456789ab)" {
+		t.Errorf("toolVersion() = %q", got)
+	}
+
+	version, commit, date = "", "", ""
+	if v := readVersion(); v.Version == "" {
+		t.Error("readVersion() without a stamp has no version")
+	}
+}
+
+// TestPseudoVersionRegex tests telling versions Go made up from release versions
+func TestPseudoVersionRegex(t *testing.T) {
+	tests := map[string]bool{
+		"v0.0.0-20260117093000-1a2b3c4d5e6f":        true,
+		"v1.4.1-0.20260117093000-1a2b3c4d5e6f":      true,
+		"v1.5.0-beta.0.20260117093000-1a2b3c4d5e6f": true,
+		"v1.4.0":      false,
+		"v1.5.0-rc.1": false,
+	}
+	for v, want := range tests {
+		if got := pseudoVersionRegex.MatchString(v); got != want {
+			t.Errorf("pseudoVersionRegex.MatchString(%q) = %v, want %v", v, got, want)
+		}
+	}
+}
+
+// TestPrintVersion tests the details printed with -version
+func TestPrintVersion(t *testing.T) {
+	var out strings.Builder
+	printVersion(&out, buildVersion{Version: "devel", Commit: "1a2b3c4d5e6f", Modified: true, Date: "2026-01-17T10:00:00Z", GoVersion: "go1.25.6"})
+	want := "logseq-to-hugo-converter devel\n" +
+		"  Commit: 1a2b3c4d5e6f (uncommitted changes)\n" +
+		"  Date:   2026-01-17T10:00:00Z\n" +
+		"  Go:     go1.25.6\n"
+	if out.String() != want {
+		t.Errorf("printVersion() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
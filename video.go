@@ -0,0 +1,60 @@
+// This file handles writing the videos of the posts: as the theme's video
+// shortcode with configurable attributes, or as an HTML5 <video> tag for
+// themes without a video shortcode.
+package main
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Video modes of the configuration.
+const (
+	VideoModeShortcode = "shortcode" // {{< video src="clip.mp4" >}}
+	VideoModeHTML      = "html"      // <video src="clip.mp4" controls></video>
+)
+
+// DefaultVideoConfig writes videos as {{< video src="clip.mp4" >}}.
+// Transcoding is off, enabling it writes H.264 videos of at most 1080p at 2 Mbit/s.
+var DefaultVideoConfig = VideoConfig{
+	Mode:      VideoModeShortcode,
+	Shortcode: "video",
+	Source:    "src",
+	Transcode: VideoTranscodeConfig{Codec: VideoCodecH264, Bitrate: "2M", MaxHeight: 1080},
+}
+
+// videoAttributeRegex matches the names of shortcode and HTML attributes ("src", "data-loop").
+var videoAttributeRegex = regexp.MustCompile(`^[A-Za-z_][\w-]*$`)
+
+// videoMarkup returns the markup of a video in the bundle.
+func videoMarkup(config VideoConfig, filename string) string {
+	names := make([]string, 0, len(config.Attributes))
+	for name := range config.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	if config.Mode == VideoModeHTML {
+		fmt.Fprintf(&b, `<video src="%s" controls`, html.EscapeString(markdownPath(filename)))
+		for _, name := range names {
+			if value := config.Attributes[name]; value != "" {
+				fmt.Fprintf(&b, ` %s="%s"`, name, html.EscapeString(value))
+			} else {
+				b.WriteString(" " + name)
+			}
+		}
+		b.WriteString("></video>")
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, `{{< %s %s="%s"`, config.Shortcode, config.Source, strings.ReplaceAll(filename, `"`, `\"`))
+	for _, name := range names {
+		fmt.Fprintf(&b, ` %s="%s"`, name, strings.ReplaceAll(config.Attributes[name], `"`, `\"`))
+	}
+	b.WriteString(" >}}")
+	return b.String()
+}
@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+// TestVideoMarkup tests writing videos as shortcodes with mapped attributes and as HTML5 tags
+func TestVideoMarkup(t *testing.T) {
+	tests := []struct {
+		name   string
+		config VideoConfig
+		want   string
+	}{
+		{
+			name:   "default",
+			config: DefaultVideoConfig,
+			want:   `{{< video src="clip 1.mp4" >}}`,
+		},
+		{
+			name:   "other shortcode",
+			config: VideoConfig{Mode: VideoModeShortcode, Shortcode: "mp4", Source: "file", Attributes: map[string]string{"loop": "true", "autoplay": "false"}},
+			want:   `{{< mp4 file="clip 1.mp4" autoplay="false" loop="true" >}}`,
+		},
+		{
+			name:   "html",
+			config: VideoConfig{Mode: VideoModeHTML, Attributes: map[string]string{"muted": "", "preload": "metadata"}},
+			want:   `<video src="clip%201.mp4" controls muted preload="metadata"></video>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := videoMarkup(tt.config, "clip 1.mp4"); got != tt.want {
+				t.Errorf("videoMarkup() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,37 +4,70 @@
 package main
 
 import (
+	"bufio"         // Buffered writing
 	"fmt"           // Formatted I/O
-	"os"            // Operating system functions
 	"path/filepath" // File path manipulation
+	"regexp"        // Checking TOML keys
+	"sort"          // Sorting the boolean params
 	"strings"       // String manipulation for escaping
+
+	"logseq-to-hugo-converter/internal/indexfile" // Templates of the index file names
+	"logseq-to-hugo-converter/internal/lang"      // Language codes of the index files
 )
 
+// DefaultFilename is the template of the index file name: one file per language.
+const DefaultFilename = indexfile.Default
+
+// MonolingualFilename is the index file name of monolingual sites.
+const MonolingualFilename = indexfile.Monolingual
+
+// defaultFilename is the parsed DefaultFilename, used by writers without a template.
+var defaultFilename = indexfile.MustParse(DefaultFilename)
+
 // HugoWriter is responsible for writing blog posts in Hugo format.
 // Hugo expects:
 //   - An index.md file in each post's directory
 //   - TOML front matter (between +++ markers) with metadata
 //   - Content after the front matter
 type HugoWriter struct {
-	outputDir string // Directory where the index.md file should be created
+	fs        FileSystem      // File system to write the index file to
+	outputDir string          // Directory where the index.md file should be created
+	filename  *indexfile.Name // Template of the index file name (nil = DefaultFilename)
+	languages []string        // Hugo language keys of the site (nil = any language code)
 }
 
 // NewHugoWriter creates a new HugoWriter instance.
 // This is a constructor function that initializes the writer.
 // Parameters:
 //
+//	fsys: The file system to write to (e.g., OSFileSystem{})
 //	outputDir: The directory where Hugo files should be written
 //
 // Returns:
 //
 //	*HugoWriter: A pointer to the new writer instance
-func NewHugoWriter(outputDir string) *HugoWriter {
+func NewHugoWriter(fsys FileSystem, outputDir string) *HugoWriter {
 	// Return a pointer to a new HugoWriter struct
 	// The & operator creates a pointer to the struct
-	return &HugoWriter{outputDir: outputDir}
+	return &HugoWriter{fs: fsys, outputDir: outputDir}
 }
 
-// getFilename determines the correct filename based on the language.
+// languageKey returns the Hugo language key of a language from the metadata
+// ("german", "Deutsch" and "de" -> "de", "pt-BR" -> "pt-br"). With the keys of
+// the site, the best matching key is used ("de-CH" -> "de" if the site only has "de").
+// German is the default for posts without a language and unknown languages.
+func languageKey(language string, keys []string) string {
+	tag, ok := lang.Parse(language)
+	if !ok {
+		tag = lang.Tag{Base: "de"}
+	}
+	if key, ok := tag.Match(keys); ok {
+		return key
+	}
+	return tag.Key()
+}
+
+// getFilename determines the filename from the template and the language.
 // Parameters:
 //
 //	language: The language code from metadata (e.g., "german", "english")
@@ -42,24 +75,23 @@ func NewHugoWriter(outputDir string) *HugoWriter {
 // Returns:
 //
 //	string: The filename to use (e.g., "index.de.md", "index.en.md")
-func (w *HugoWriter) getFilename(language string) string {
-	// Normalize language to lowercase for case-insensitive comparison
-	language = strings.ToLower(strings.TrimSpace(language))
-
-	switch language {
-	case "german":
-		return "index.de.md"
-	case "english":
-		return "index.en.md"
-	default:
-		// Default to German if no language is specified
-		return "index.de.md"
+//	error: An error if the template can't be executed
+func (w *HugoWriter) getFilename(language string) (string, error) {
+	tmpl := w.filename
+	if tmpl == nil {
+		tmpl = defaultFilename
 	}
+
+	return tmpl.Execute(indexfile.Data{
+		Lang:     languageKey(language, w.languages),
+		Language: llmLanguage(language),
+		Slug:     filepath.Base(w.outputDir),
+	})
 }
 
 // Write creates an index file with Hugo-formatted content.
 // This method generates the front matter and writes the complete file.
-// The filename is determined by the language metadata.
+// The filename is determined by the filename template and the language metadata.
 // Parameters:
 //
 //	meta: BlogMeta struct containing all the metadata
@@ -72,15 +104,18 @@ func (w *HugoWriter) getFilename(language string) string {
 func (w *HugoWriter) Write(meta BlogMeta, content string) (string, error) {
 	// Determine the filename based on the language
 	// Default to index.de.md if no language is set
-	filename := w.getFilename(meta.Language)
+	filename, err := w.getFilename(meta.Language)
+	if err != nil {
+		return "", fmt.Errorf("index file name: %w", err)
+	}
 
 	// Build the full path to the index file
 	// filepath.Join combines directory and filename with correct separator
 	indexPath := filepath.Join(w.outputDir, filename)
 
 	// Create (or overwrite) the index file
-	// os.Create creates a new file or truncates an existing one
-	f, err := os.Create(indexPath)
+	// Create creates a new file or truncates an existing one
+	f, err := w.fs.Create(indexPath)
 
 	// Check if file creation failed
 	if err != nil {
@@ -95,31 +130,169 @@ func (w *HugoWriter) Write(meta BlogMeta, content string) (string, error) {
 
 	// Build the Hugo front matter in TOML format
 	// TOML uses +++ delimiters and key = "value" syntax (with double quotes)
-	// We must escape any double quotes in the values with \"
-	// fmt.Sprintf formats a string with variables substituted
-	// The %s placeholders are replaced with the actual values
-	frontMatter := fmt.Sprintf(
-		// Each line in this string becomes part of the front matter
-		"+++\n"+ // Opening delimiter
-			"date = \"%s\"\n"+ // Publication date (double quotes)
-			"lastmod = \"%s\"\n"+ // Last modified date (same as date)
-			"draft = false\n"+ // Not a draft (published)
-			"title = \"%s\"\n"+ // Post title (escaped)
-			"summary = \"%s\"\n"+ // Post summary/excerpt (escaped)
-			"[params]\n"+ // Custom parameters section
-			"  author = \"%s\"\n"+ // Author name (indented under params)
-			"+++\n\n", // Closing delimiter + blank line
-		escapeTomlString(meta.Date),    // Escape date
-		escapeTomlString(meta.Date),    // Escape lastmod
-		escapeTomlString(meta.Title),   // Escape title
-		escapeTomlString(meta.Summary), // Escape summary
-		escapeTomlString(meta.Author),  // Escape author
-	)
+	// The keys are written in the order they are added, so the output is stable
+	fm := &frontMatter{}
+	fm.Set("date", meta.Date)       // Publication date
+	fm.Set("lastmod", meta.Date)    // Last modified date (same as date)
+	fm.Set("draft", false)          // Not a draft (published)
+	fm.Set("title", meta.Title)     // Post title
+	fm.Set("summary", meta.Summary) // Post summary/excerpt
+
+	// Meta description for search engines, only written if there is one
+	if meta.Description != "" {
+		fm.Set("description", meta.Description)
+	}
+
+	// Hugo stops publishing the post after its expiry date
+	if meta.ExpiryDate != "" {
+		fm.Set("expiryDate", meta.ExpiryDate)
+	}
+
+	// Hugo layout of a content type like recipes
+	if meta.Layout != "" {
+		fm.Set("layout", meta.Layout)
+	}
+
+	// Table of contents, only written if explicitly enabled or disabled
+	if meta.TOC != "" {
+		fm.Set("toc", meta.TOC == "true")
+	}
+
+	// Tags for Hugo's tags taxonomy
+	if len(meta.Tags) > 0 {
+		fm.Set("tags", meta.Tags)
+	}
+
+	// Categories for Hugo's categories taxonomy
+	if len(meta.Categories) > 0 {
+		fm.Set("categories", meta.Categories)
+	}
+
+	// Images for Hugo's OpenGraph and Twitter card templates
+	if len(meta.Images) > 0 {
+		fm.Set("images", meta.Images)
+	}
+
+	// Earlier addresses of a moved post, Hugo redirects them to the post
+	if len(meta.Aliases) > 0 {
+		fm.Set("aliases", meta.Aliases)
+	}
+
+	fm.SetParam("author", meta.Author) // Author name (indented under params)
+
+	// Author contact details, only written if the author registry has them
+	if meta.AuthorEmail != "" {
+		fm.SetParam("authoremail", meta.AuthorEmail)
+	}
+	if meta.AuthorURL != "" {
+		fm.SetParam("authorurl", meta.AuthorURL)
+	}
+
+	// Related posts (bundle names), only written if there are any
+	if len(meta.Related) > 0 {
+		fm.SetParam("related", meta.Related)
+	}
+
+	// Word count and reading time, only written if computed
+	if meta.WordCount > 0 {
+		fm.SetParam("wordcount", meta.WordCount)
+		fm.SetParam("readingtime", meta.ReadingTime)
+	}
+
+	// Boolean params like comments = false, sorted for a stable output
+	flags := make([]string, 0, len(meta.Flags))
+	for param := range meta.Flags {
+		flags = append(flags, param)
+	}
+	sort.Strings(flags)
+	for _, param := range flags {
+		fm.SetParam(param, meta.Flags[param])
+	}
+
+	// Opt-outs of the translation tool
+	if meta.Translate != nil {
+		fm.SetParam("translate", *meta.Translate)
+	}
+	if meta.TranslateLanguages != nil {
+		fm.SetParam("translate_languages", meta.TranslateLanguages)
+	}
+
+	// Params of the content type, sorted for a stable output
+	params := make([]string, 0, len(meta.Params))
+	for param := range meta.Params {
+		params = append(params, param)
+	}
+	sort.Strings(params)
+	for _, param := range params {
+		fm.SetParam(param, dataValue(meta.Params[param]))
+	}
+
+	// Data properties in their own [params.data] section
+	for _, field := range meta.Data {
+		fm.SetIn("params.data", field.Key, dataValue(field.Value))
+	}
+
+	// Location in its own [params.location] section for map templates
+	if location := meta.Location; location != nil {
+		if location.HasCoordinates {
+			fm.SetIn("params.location", "lat", location.Lat)
+			fm.SetIn("params.location", "lng", location.Lng)
+		}
+		if location.Name != "" {
+			fm.SetIn("params.location", "name", location.Name)
+		}
+	}
+
+	// Social media previews in their own [params.social] section (empty values are left out)
+	if social := meta.Social; social != nil {
+		for _, field := range []struct{ key, value string }{
+			{"title", social.Title},
+			{"description", social.Description},
+			{"image", social.Image},
+			{"card", social.Card},
+			{"site", social.Site},
+		} {
+			if field.value != "" {
+				fm.SetIn("params.social", field.key, field.value)
+			}
+		}
+	}
+
+	// IndieWeb metadata in its own [params.indieweb] section (empty values are left out)
+	if indieWeb := meta.IndieWeb; indieWeb != nil {
+		if indieWeb.Canonical != "" {
+			fm.SetIn("params.indieweb", "canonical", indieWeb.Canonical)
+		}
+		if len(indieWeb.Syndication) > 0 {
+			fm.SetIn("params.indieweb", "syndication", indieWeb.Syndication)
+		}
+		if len(indieWeb.SyndicateTo) > 0 {
+			fm.SetIn("params.indieweb", "syndicate_to", indieWeb.SyndicateTo)
+		}
+		if indieWeb.InReplyTo != "" {
+			fm.SetIn("params.indieweb", "in_reply_to", indieWeb.InReplyTo)
+		}
+	}
+
+	// When and from what source the post was generated, in its own [params.publish] section
+	if publish := meta.Publish; publish != nil {
+		fm.SetIn("params.publish", "time", publish.Time)
+		fm.SetIn("params.publish", "source_hash", publish.SourceHash)
+		fm.SetIn("params.publish", "version", publish.Version)
+	}
 
 	// Write the complete file content
-	// f.WriteString writes a string to the file
-	// We concatenate the front matter, content, and a final newline
-	_, err = f.WriteString(frontMatter + content + "\n")
+	// The front matter, a blank line, content, and a final newline are written
+	// one after another through a buffer instead of being concatenated, which
+	// saves one copy of the file. The content itself is still a single string.
+	out := bufio.NewWriter(f)
+	out.WriteString(fm.String())
+	out.WriteString("\n")
+	out.WriteString(content)
+	out.WriteString("\n")
+
+	// Flush writes the buffered data and reports any error that happened while writing
+	err = out.Flush()
 
 	// Check if writing failed
 	if err != nil {
@@ -135,7 +308,8 @@ func (w *HugoWriter) Write(meta BlogMeta, content string) (string, error) {
 
 // escapeTomlString escapes special characters for TOML string values.
 // TOML requires double quotes to be escaped with a backslash.
-// It also escapes backslashes themselves to avoid ambiguity.
+// It also escapes backslashes themselves to avoid ambiguity, and control
+// characters (like line breaks), which TOML doesn't allow in strings.
 // Parameters:
 //
 //	s: The string to escape
@@ -152,6 +326,130 @@ func escapeTomlString(s string) string {
 	// \" becomes \\\" in the TOML (backslash + escaped quote)
 	s = strings.ReplaceAll(s, `"`, `\"`)
 
+	// Control characters are only allowed as escape sequences (tabs are fine as they are)
+	if strings.IndexFunc(s, isTomlControl) >= 0 {
+		var builder strings.Builder
+		for _, r := range s {
+			switch {
+			case r == '\n':
+				builder.WriteString(`\n`)
+			case r == '\r':
+				builder.WriteString(`\r`)
+			case isTomlControl(r):
+				fmt.Fprintf(&builder, `\u%04X`, r)
+			default:
+				builder.WriteRune(r)
+			}
+		}
+		s = builder.String()
+	}
+
 	// Return the escaped string
 	return s
 }
+
+// isTomlControl reports whether r is a control character that must be escaped in a TOML string.
+func isTomlControl(r rune) bool {
+	return r != '\t' && (r < 0x20 || r == 0x7f)
+}
+
+// bareKeyRegex matches the keys TOML allows without quotes.
+var bareKeyRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// tomlKey formats a key for the front matter, quoting keys that
+// aren't allowed bare in TOML (e.g. "légende" from a property).
+func tomlKey(key string) string {
+	if bareKeyRegex.MatchString(key) {
+		return key
+	}
+	return `"` + escapeTomlString(key) + `"`
+}
+
+// frontMatter builds TOML front matter while keeping the order in which keys are added.
+// Top-level keys are written first, followed by the [params] section
+// and further sections like [params.social].
+type frontMatter struct {
+	fields []tomlField // Top-level keys (date, title, ...)
+	params []tomlField // Keys of the [params] section
+	tables []tomlTable // Further sections in the order they were added
+}
+
+// tomlTable is a section of the front matter with its keys.
+type tomlTable struct {
+	name   string
+	fields []tomlField
+}
+
+// tomlField is a single key with its already formatted TOML value.
+type tomlField struct {
+	key   string
+	value string
+}
+
+// Set adds a top-level key to the front matter.
+func (f *frontMatter) Set(key string, value interface{}) {
+	f.fields = append(f.fields, tomlField{key: key, value: tomlValue(value)})
+}
+
+// SetParam adds a key to the [params] section of the front matter.
+func (f *frontMatter) SetParam(key string, value interface{}) {
+	f.params = append(f.params, tomlField{key: key, value: tomlValue(value)})
+}
+
+// SetIn adds a key to a section of the front matter (e.g. "params.social").
+func (f *frontMatter) SetIn(table, key string, value interface{}) {
+	field := tomlField{key: key, value: tomlValue(value)}
+	for i := range f.tables {
+		if f.tables[i].name == table {
+			f.tables[i].fields = append(f.tables[i].fields, field)
+			return
+		}
+	}
+	f.tables = append(f.tables, tomlTable{name: table, fields: []tomlField{field}})
+}
+
+// String renders the front matter including the +++ delimiters.
+func (f *frontMatter) String() string {
+	var builder strings.Builder
+	builder.WriteString("+++\n")
+	for _, field := range f.fields {
+		builder.WriteString(tomlKey(field.key) + " = " + field.value + "\n")
+	}
+	if len(f.params) > 0 {
+		builder.WriteString("[params]\n")
+		for _, field := range f.params {
+			builder.WriteString("  " + tomlKey(field.key) + " = " + field.value + "\n")
+		}
+	}
+	for _, table := range f.tables {
+		builder.WriteString("[" + table.name + "]\n")
+		for _, field := range table.fields {
+			builder.WriteString("  " + tomlKey(field.key) + " = " + field.value + "\n")
+		}
+	}
+	builder.WriteString("+++\n")
+	return builder.String()
+}
+
+// tomlValue formats a Go value as a TOML value.
+// Strings are quoted and escaped, slices become arrays.
+func tomlValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return `"` + escapeTomlString(v) + `"`
+	case bool:
+		return fmt.Sprintf("%t", v)
+	case int:
+		return fmt.Sprintf("%d", v)
+	case float64:
+		return fmt.Sprintf("%g", v)
+	case []string:
+		quoted := make([]string, len(v))
+		for i, item := range v {
+			quoted[i] = tomlValue(item)
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	default:
+		return tomlValue(fmt.Sprint(v))
+	}
+}
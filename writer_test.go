@@ -0,0 +1,183 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/BurntSushi/toml"
+
+	"logseq-to-hugo-converter/internal/indexfile"
+)
+
+// TestGetFilename tests the index file names of the filename templates
+func TestGetFilename(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		language string
+		want     string
+		wantErr  bool
+	}{
+		{"default german", DefaultFilename, "German", "index.de.md", false},
+		{"default english", DefaultFilename, "english", "index.en.md", false},
+		{"default without language", DefaultFilename, "", "index.de.md", false},
+		{"slug", "{{.Slug}}.{{.Lang}}.md", "english", "2025-01-20_Post.en.md", false},
+		{"monolingual", "index.md", "english", "index.md", false},
+		{"language name", "index.{{.Language}}.md", "english", "index.english.md", false},
+		{"language name only", "{{.Language}}.md", "Deutsch", "german.md", false},
+		{"iso code", DefaultFilename, "de", "index.de.md", false},
+		{"regional variant", DefaultFilename, "pt_BR", "index.pt-br.md", false},
+		{"unknown language", DefaultFilename, "klingon", "index.de.md", false},
+		{"empty name", "{{if false}}index{{end}}.md", "english", "", true},
+		{"unknown field", "index.{{.Locale}}.md", "english", "", true},
+		{"directory", "{{.Lang}}/index.md", "english", "", true},
+		{"not markdown", "index.{{.Lang}}.html", "english", "", true},
+		{"syntax error", "index.{{.Lang}.md", "english", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := indexfile.Parse(tt.template)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("indexfile.Parse(%q) error = %v, wantErr %v", tt.template, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			writer := NewHugoWriter(nil, "blog/2025-01-20_Post")
+			writer.filename = tmpl
+			got, err := writer.getFilename(tt.language)
+			if err != nil || got != tt.want {
+				t.Errorf("getFilename(%q) = %q, %v, want %q", tt.language, got, err, tt.want)
+			}
+		})
+	}
+}
+
+// TestLanguageKey tests matching the languages of posts with the language keys of a site
+func TestLanguageKey(t *testing.T) {
+	tests := []struct {
+		language string
+		keys     []string
+		want     string
+	}{
+		{"german", nil, "de"},
+		{"de-CH", nil, "de-ch"},
+		{"de-CH", []string{"en", "de"}, "de"},
+		{"de", []string{"de-ch", "de-de"}, "de-ch"},
+		{"pt-BR", []string{"pt-pt", "pt-br"}, "pt-br"},
+		{"Español", []string{"de", "en"}, "es"},
+		{"", []string{"en", "de"}, "de"},
+	}
+
+	for _, tt := range tests {
+		if got := languageKey(tt.language, tt.keys); got != tt.want {
+			t.Errorf("languageKey(%q, %v) = %q, want %q", tt.language, tt.keys, got, tt.want)
+		}
+	}
+}
+
+// TestFrontMatterString tests that front matter keeps the insertion order and formats values
+func TestFrontMatterString(t *testing.T) {
+	fm := &frontMatter{}
+	fm.Set("title", `Say "Hi"`)
+	fm.Set("draft", false)
+	fm.Set("toc", true)
+	fm.SetParam("author", "benno")
+	fm.SetParam("tags", []string{"sailing", "boats"})
+
+	want := "+++\n" +
+		"title = \"Say \\\"Hi\\\"\"\n" +
+		"draft = false\n" +
+		"toc = true\n" +
+		"[params]\n" +
+		"  author = \"benno\"\n" +
+		"  tags = [\"sailing\", \"boats\"]\n" +
+		"+++\n"
+
+	if got := fm.String(); got != want {
+		t.Errorf("frontMatter.String() = %q, want %q", got, want)
+	}
+}
+
+// TestFrontMatterString_Tables tests writing nested sections after the params
+func TestFrontMatterString_Tables(t *testing.T) {
+	fm := &frontMatter{}
+	fm.Set("title", "T")
+	fm.SetIn("params.social", "title", "T")
+	fm.SetParam("author", "benno")
+	fm.SetIn("params.social", "card", "summary")
+
+	want := "+++\n" +
+		"title = \"T\"\n" +
+		"[params]\n" +
+		"  author = \"benno\"\n" +
+		"[params.social]\n" +
+		"  title = \"T\"\n" +
+		"  card = \"summary\"\n" +
+		"+++\n"
+
+	if got := fm.String(); got != want {
+		t.Errorf("frontMatter.String() = %q, want %q", got, want)
+	}
+}
+
+// TestFrontMatterString_Escaping tests quoting keys and escaping control characters
+func TestFrontMatterString_Escaping(t *testing.T) {
+	fm := &frontMatter{}
+	fm.Set("title", "Line\nbreak\x01")
+	fm.SetIn("params.data", "légende", "x")
+
+	want := "+++\n" +
+		"title = \"Line\\nbreak\\u0001\"\n" +
+		"[params.data]\n" +
+		"  \"légende\" = \"x\"\n" +
+		"+++\n"
+
+	if got := fm.String(); got != want {
+		t.Errorf("frontMatter.String() = %q, want %q", got, want)
+	}
+}
+
+// FuzzFrontMatterString tests that any key and value give valid TOML that reads back the same
+func FuzzFrontMatterString(f *testing.F) {
+	f.Add("title", `Say "Hi" \ there`)
+	f.Add("légende", "tab\tnew\nline\r\x00\x7f")
+
+	f.Fuzz(func(t *testing.T, key, value string) {
+		if key == "" || !utf8.ValidString(key) || !utf8.ValidString(value) {
+			return // Keys are never empty, strings come from UTF-8 files
+		}
+		fm := &frontMatter{}
+		fm.SetParam(key, value)
+
+		var decoded struct {
+			Params map[string]string `toml:"params"`
+		}
+		text := strings.Trim(fm.String(), "+\n")
+		if _, err := toml.Decode(text, &decoded); err != nil {
+			t.Fatalf("Invalid TOML %q: %v", text, err)
+		}
+		if got := decoded.Params[key]; got != value {
+			t.Errorf("Read back %q, want %q", got, value)
+		}
+	})
+}
+
+// BenchmarkHugoWriter_Write measures writing the index file of a large post
+func BenchmarkHugoWriter_Write(b *testing.B) {
+	content := strings.Repeat("A paragraph of a long post with [a link](https://example.com) and **bold** text.\n\n", 50000)
+	meta := BlogMeta{Title: "Large", Date: "2026-01-01", Language: "en"}
+	b.SetBytes(int64(len(content)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		fsys := NewMemFileSystem(nil)
+		fsys.MkdirAll("out", 0755)
+		if _, err := NewHugoWriter(fsys, "out").Write(meta, content); err != nil {
+			b.Fatalf("Write() error = %v", err)
+		}
+	}
+}